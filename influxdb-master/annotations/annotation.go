@@ -0,0 +1,186 @@
+// Package annotations records events, such as maneuvers, safe-mode entries
+// or software uploads, on the timelines of an organization. An annotation
+// marks either an instant or a time range, belongs to a stream grouping
+// related annotations, and carries free-form tags, so that annotations can be
+// overlaid on charts and queried alongside data.
+package annotations
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+)
+
+// DefaultStream is the stream of annotations created without one.
+const DefaultStream = "default"
+
+var (
+	// ErrAnnotationNotFound is used when the annotation is not found.
+	ErrAnnotationNotFound = &errors.Error{
+		Code: errors.ENotFound,
+		Msg:  "annotation not found",
+	}
+
+	// ErrInvalidOrgID is used when an annotation is not of a valid
+	// organization ID.
+	ErrInvalidOrgID = &errors.Error{
+		Code: errors.EInvalid,
+		Msg:  "provided organization ID is missing or invalid",
+	}
+
+	// ErrSummaryRequired is used when an annotation has no summary.
+	ErrSummaryRequired = &errors.Error{
+		Code: errors.EInvalid,
+		Msg:  "annotation summary is required",
+	}
+
+	// ErrStartTimeRequired is used when an annotation has no start time.
+	ErrStartTimeRequired = &errors.Error{
+		Code: errors.EInvalid,
+		Msg:  "annotation start time is required",
+	}
+
+	// ErrInvalidTimeRange is used when the end time of an annotation is
+	// before its start time.
+	ErrInvalidTimeRange = &errors.Error{
+		Code: errors.EInvalid,
+		Msg:  "annotation end time must not be before its start time",
+	}
+)
+
+// Annotation marks an event on the timelines of an organization. An
+// annotation of an instant has the same start and end time.
+type Annotation struct {
+	ID             platform.ID       `json:"id"`
+	OrganizationID platform.ID       `json:"orgID"`
+	Stream         string            `json:"stream"`
+	Summary        string            `json:"summary"`
+	Message        string            `json:"message,omitempty"`
+	Tags           map[string]string `json:"tags,omitempty"`
+	StartTime      time.Time         `json:"startTime"`
+	EndTime        time.Time         `json:"endTime"`
+	influxdb.CRUDLog
+}
+
+// Validate defaults the stream and end time of the annotation, and validates
+// it.
+func (a *Annotation) Validate() error {
+	if !a.OrganizationID.Valid() {
+		return ErrInvalidOrgID
+	}
+	if a.Summary == "" {
+		return ErrSummaryRequired
+	}
+	if a.Stream == "" {
+		a.Stream = DefaultStream
+	}
+	if a.StartTime.IsZero() {
+		return ErrStartTimeRequired
+	}
+	if a.EndTime.IsZero() {
+		a.EndTime = a.StartTime
+	}
+	if a.EndTime.Before(a.StartTime) {
+		return ErrInvalidTimeRange
+	}
+	return nil
+}
+
+// AnnotationUpdate is the update of an annotation. Unset fields are left
+// unchanged, and set tags replace all the tags of the annotation.
+type AnnotationUpdate struct {
+	Stream    *string            `json:"stream,omitempty"`
+	Summary   *string            `json:"summary,omitempty"`
+	Message   *string            `json:"message,omitempty"`
+	Tags      *map[string]string `json:"tags,omitempty"`
+	StartTime *time.Time         `json:"startTime,omitempty"`
+	EndTime   *time.Time         `json:"endTime,omitempty"`
+}
+
+// Apply applies the update to an annotation and validates the result.
+func (u AnnotationUpdate) Apply(a *Annotation) error {
+	if u.Stream != nil {
+		a.Stream = *u.Stream
+	}
+	if u.Summary != nil {
+		a.Summary = *u.Summary
+	}
+	if u.Message != nil {
+		a.Message = *u.Message
+	}
+	if u.Tags != nil {
+		a.Tags = *u.Tags
+	}
+	if u.StartTime != nil {
+		a.StartTime = *u.StartTime
+		// An instant stays an instant unless its end time is updated too.
+		if u.EndTime == nil && a.EndTime.Before(a.StartTime) {
+			a.EndTime = a.StartTime
+		}
+	}
+	if u.EndTime != nil {
+		a.EndTime = *u.EndTime
+	}
+	return a.Validate()
+}
+
+// AnnotationFilter selects the annotations of an organization. An annotation
+// matches if its time range overlaps the range from StartTime to EndTime, it
+// is of one of Streams, and it has all of Tags. Unset fields match all
+// annotations.
+type AnnotationFilter struct {
+	OrganizationID platform.ID
+	StartTime      *time.Time
+	EndTime        *time.Time
+	Streams        []string
+	Tags           map[string]string
+}
+
+func (f AnnotationFilter) matches(a *Annotation) bool {
+	if f.StartTime != nil && a.EndTime.Before(*f.StartTime) {
+		return false
+	}
+	if f.EndTime != nil && a.StartTime.After(*f.EndTime) {
+		return false
+	}
+	if len(f.Streams) > 0 {
+		found := false
+		for _, s := range f.Streams {
+			if s == a.Stream {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for k, v := range f.Tags {
+		if tv, ok := a.Tags[k]; !ok || tv != v {
+			return false
+		}
+	}
+	return true
+}
+
+// AnnotationService manages annotations.
+type AnnotationService interface {
+	// CreateAnnotation creates an annotation, setting its ID.
+	CreateAnnotation(ctx context.Context, a *Annotation) error
+
+	// FindAnnotationByID returns a single annotation by ID.
+	FindAnnotationByID(ctx context.Context, id platform.ID) (*Annotation, error)
+
+	// FindAnnotations returns the annotations matching filter, ordered by
+	// start time, and the total number of matching annotations.
+	FindAnnotations(ctx context.Context, filter AnnotationFilter, opts ...influxdb.FindOptions) ([]*Annotation, int, error)
+
+	// UpdateAnnotation updates a single annotation and returns the result.
+	UpdateAnnotation(ctx context.Context, id platform.ID, upd AnnotationUpdate) (*Annotation, error)
+
+	// DeleteAnnotation deletes a single annotation by ID.
+	DeleteAnnotation(ctx context.Context, id platform.ID) error
+}
@@ -0,0 +1,122 @@
+package annotations
+
+import (
+	"context"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+)
+
+var _ AnnotationService = (*Client)(nil)
+
+// Client connects to Influx via HTTP using tokens to manage annotations.
+type Client struct {
+	Client *httpc.Client
+	Prefix string
+}
+
+// NewClient returns a Client of the annotations API.
+func NewClient(client *httpc.Client) *Client {
+	return &Client{
+		Client: client,
+		Prefix: PrefixAnnotations,
+	}
+}
+
+func (c *Client) annotationURL(id platform.ID) string {
+	return path.Join(c.Prefix, id.String())
+}
+
+func (c *Client) CreateAnnotation(ctx context.Context, a *Annotation) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var res Annotation
+	if err := c.Client.
+		PostJSON(a, c.Prefix).
+		DecodeJSON(&res).
+		Do(ctx); err != nil {
+		return err
+	}
+	*a = res
+	return nil
+}
+
+func (c *Client) FindAnnotationByID(ctx context.Context, id platform.ID) (*Annotation, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var res Annotation
+	if err := c.Client.
+		Get(c.annotationURL(id)).
+		DecodeJSON(&res).
+		Do(ctx); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (c *Client) FindAnnotations(ctx context.Context, filter AnnotationFilter, opts ...influxdb.FindOptions) ([]*Annotation, int, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	params := influxdb.FindOptionParams(opts...)
+	params = append(params, [2]string{"orgID", filter.OrganizationID.String()})
+	if filter.StartTime != nil {
+		params = append(params, [2]string{"startTime", filter.StartTime.Format(time.RFC3339Nano)})
+	}
+	if filter.EndTime != nil {
+		params = append(params, [2]string{"endTime", filter.EndTime.Format(time.RFC3339Nano)})
+	}
+	for _, s := range filter.Streams {
+		params = append(params, [2]string{"stream", s})
+	}
+	keys := make([]string, 0, len(filter.Tags))
+	for k := range filter.Tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		params = append(params, [2]string{"tag", k + ":" + filter.Tags[k]})
+	}
+
+	var res struct {
+		Annotations []*Annotation `json:"annotations"`
+	}
+	if err := c.Client.
+		Get(c.Prefix).
+		QueryParams(params...).
+		DecodeJSON(&res).
+		Do(ctx); err != nil {
+		return nil, 0, err
+	}
+	return res.Annotations, len(res.Annotations), nil
+}
+
+func (c *Client) UpdateAnnotation(ctx context.Context, id platform.ID, upd AnnotationUpdate) (*Annotation, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var res Annotation
+	if err := c.Client.
+		PatchJSON(upd, c.annotationURL(id)).
+		DecodeJSON(&res).
+		Do(ctx); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (c *Client) DeleteAnnotation(ctx context.Context, id platform.ID) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	return c.Client.
+		Delete(c.annotationURL(id)).
+		Do(ctx)
+}
@@ -0,0 +1,311 @@
+package annotations
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap"
+)
+
+// PrefixAnnotations is the prefix of the annotations API.
+const PrefixAnnotations = "/api/v2/annotations"
+
+// AnnotationHandler is the handler of the annotations API.
+type AnnotationHandler struct {
+	chi.Router
+
+	api *kithttp.API
+	log *zap.Logger
+
+	annotationService AnnotationService
+	orgService        influxdb.OrganizationService
+}
+
+// NewAnnotationHandler returns a new instance of AnnotationHandler.
+func NewAnnotationHandler(log *zap.Logger, annotationService AnnotationService, orgService influxdb.OrganizationService) *AnnotationHandler {
+	h := &AnnotationHandler{
+		api:               kithttp.NewAPI(kithttp.WithLog(log)),
+		log:               log,
+		annotationService: annotationService,
+		orgService:        orgService,
+	}
+
+	r := chi.NewRouter()
+	r.Use(
+		middleware.Recoverer,
+		middleware.RequestID,
+		middleware.RealIP,
+	)
+
+	r.Route("/", func(r chi.Router) {
+		r.Post("/", h.handlePostAnnotation)
+		r.Get("/", h.handleGetAnnotations)
+
+		r.Route("/{id}", func(r chi.Router) {
+			r.Get("/", h.handleGetAnnotation)
+			r.Patch("/", h.handlePatchAnnotation)
+			r.Delete("/", h.handleDeleteAnnotation)
+		})
+	})
+
+	h.Router = r
+	return h
+}
+
+// Prefix returns the mounting prefix for the handler.
+func (h *AnnotationHandler) Prefix() string {
+	return PrefixAnnotations
+}
+
+type annotationLinks struct {
+	Self string `json:"self"`
+	Org  string `json:"org"`
+}
+
+type annotationResponse struct {
+	*Annotation
+	Links annotationLinks `json:"links"`
+}
+
+func newAnnotationResponse(a *Annotation) annotationResponse {
+	return annotationResponse{
+		Annotation: a,
+		Links: annotationLinks{
+			Self: fmt.Sprintf("%s/%s", PrefixAnnotations, a.ID),
+			Org:  fmt.Sprintf("/api/v2/orgs/%s", a.OrganizationID),
+		},
+	}
+}
+
+type annotationsResponse struct {
+	Annotations []annotationResponse  `json:"annotations"`
+	Links       *influxdb.PagingLinks `json:"links"`
+}
+
+func (h *AnnotationHandler) handlePostAnnotation(w http.ResponseWriter, r *http.Request) {
+	var a Annotation
+	if err := h.api.DecodeJSON(r.Body, &a); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	if !a.OrganizationID.Valid() {
+		orgID, err := h.orgIDFromRequest(r)
+		if err != nil {
+			h.api.Err(w, r, err)
+			return
+		}
+		a.OrganizationID = orgID
+	}
+
+	if err := h.annotationService.CreateAnnotation(r.Context(), &a); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Annotation created", zap.Stringer("annotation_id", a.ID), zap.String("stream", a.Stream))
+
+	h.api.Respond(w, r, http.StatusCreated, newAnnotationResponse(&a))
+}
+
+func (h *AnnotationHandler) handleGetAnnotations(w http.ResponseWriter, r *http.Request) {
+	opts, err := influxdb.DecodeFindOptions(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	filter, err := h.decodeAnnotationFilter(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	as, _, err := h.annotationService.FindAnnotations(r.Context(), filter, *opts)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	res := annotationsResponse{
+		Annotations: make([]annotationResponse, 0, len(as)),
+		Links:       influxdb.NewPagingLinks(PrefixAnnotations, *opts, filterParams(r.URL.Query()), len(as)),
+	}
+	for _, a := range as {
+		res.Annotations = append(res.Annotations, newAnnotationResponse(a))
+	}
+	h.api.Respond(w, r, http.StatusOK, res)
+}
+
+// decodeAnnotationFilter decodes the filter of a request listing annotations.
+// Tags are given as repeated tag parameters of the form key:value.
+func (h *AnnotationHandler) decodeAnnotationFilter(r *http.Request) (AnnotationFilter, error) {
+	q := r.URL.Query()
+
+	var (
+		filter AnnotationFilter
+		err    error
+	)
+	if filter.OrganizationID, err = h.orgIDFromRequest(r); err != nil {
+		return filter, err
+	}
+	if filter.StartTime, err = optionalTime(q, "startTime"); err != nil {
+		return filter, err
+	}
+	if filter.EndTime, err = optionalTime(q, "endTime"); err != nil {
+		return filter, err
+	}
+	filter.Streams = q["stream"]
+	for _, t := range q["tag"] {
+		kv := strings.SplitN(t, ":", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return filter, &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  fmt.Sprintf("tag %q is invalid, it must be of the form key:value", t),
+			}
+		}
+		if filter.Tags == nil {
+			filter.Tags = map[string]string{}
+		}
+		filter.Tags[kv[0]] = kv[1]
+	}
+	return filter, nil
+}
+
+func (h *AnnotationHandler) handleGetAnnotation(w http.ResponseWriter, r *http.Request) {
+	id, err := urlID(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	a, err := h.annotationService.FindAnnotationByID(r.Context(), id)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.api.Respond(w, r, http.StatusOK, newAnnotationResponse(a))
+}
+
+func (h *AnnotationHandler) handlePatchAnnotation(w http.ResponseWriter, r *http.Request) {
+	id, err := urlID(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	var upd AnnotationUpdate
+	if err := h.api.DecodeJSON(r.Body, &upd); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	a, err := h.annotationService.UpdateAnnotation(r.Context(), id, upd)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Annotation updated", zap.Stringer("annotation_id", a.ID))
+
+	h.api.Respond(w, r, http.StatusOK, newAnnotationResponse(a))
+}
+
+func (h *AnnotationHandler) handleDeleteAnnotation(w http.ResponseWriter, r *http.Request) {
+	id, err := urlID(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	if err := h.annotationService.DeleteAnnotation(r.Context(), id); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Annotation deleted", zap.Stringer("annotation_id", id))
+
+	h.api.Respond(w, r, http.StatusNoContent, nil)
+}
+
+// orgIDFromRequest returns the orgID parameter of the request, falling back
+// to looking up the organization by the org parameter if it is not present.
+func (h *AnnotationHandler) orgIDFromRequest(r *http.Request) (platform.ID, error) {
+	q := r.URL.Query()
+	if s := q.Get("orgID"); s != "" {
+		id, err := platform.IDFromString(s)
+		if err != nil {
+			return 0, &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "orgID is invalid",
+				Err:  err,
+			}
+		}
+		return *id, nil
+	}
+
+	name := q.Get("org")
+	if name == "" {
+		return 0, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "either orgID or org is required",
+		}
+	}
+	org, err := h.orgService.FindOrganization(r.Context(), influxdb.OrganizationFilter{Name: &name})
+	if err != nil {
+		return 0, err
+	}
+	return org.ID, nil
+}
+
+func urlID(r *http.Request) (platform.ID, error) {
+	var id platform.ID
+	if err := id.DecodeFromString(chi.URLParam(r, "id")); err != nil {
+		return 0, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "annotation ID is invalid",
+			Err:  err,
+		}
+	}
+	return id, nil
+}
+
+func optionalTime(q url.Values, param string) (*time.Time, error) {
+	s := q.Get(param)
+	if s == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return nil, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  fmt.Sprintf("%s is invalid", param),
+			Err:  err,
+		}
+	}
+	return &t, nil
+}
+
+// filterParams returns the filter query parameters of a request listing
+// annotations, to carry over to the paging links of the response.
+func filterParams(q url.Values) filterParamsMap {
+	f := filterParamsMap{}
+	for _, p := range []string{"orgID", "org", "startTime", "endTime", "stream", "tag"} {
+		if vs, ok := q[p]; ok {
+			f[p] = vs
+		}
+	}
+	return f
+}
+
+// filterParamsMap implements influxdb.PagingFilter.
+type filterParamsMap map[string][]string
+
+func (f filterParamsMap) QueryParams() map[string][]string {
+	return f
+}
@@ -0,0 +1,68 @@
+package annotations_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/annotations"
+	"github.com/influxdata/influxdb/v2/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestAnnotationHandler(t *testing.T) {
+	orgSvc := mock.NewOrganizationService()
+	orgSvc.FindOrganizationF = func(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+		return &influxdb.Organization{ID: 1, Name: *filter.Name}, nil
+	}
+	h := annotations.NewAnnotationHandler(zaptest.NewLogger(t), newTestService(t), orgSvc)
+
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return w
+	}
+
+	for _, body := range []string{
+		`{"summary": "burn", "stream": "maneuvers", "startTime": "2021-01-01T10:00:00Z", "endTime": "2021-01-01T11:00:00Z", "tags": {"craft": "voyager1"}}`,
+		`{"summary": "upload", "startTime": "2021-01-01T12:00:00Z", "tags": {"craft": "voyager2"}}`,
+	} {
+		if w := do(http.MethodPost, "/?org=deep-space", body); w.Code != http.StatusCreated {
+			t.Fatalf("unexpected status creating annotation: %d %s", w.Code, w.Body)
+		}
+	}
+
+	w := do(http.MethodGet, "/?orgID=0000000000000001&endTime=2021-01-01T11:30:00Z&tag=craft:voyager1", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status listing annotations: %d %s", w.Code, w.Body)
+	}
+	var res struct {
+		Annotations []annotations.Annotation `json:"annotations"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+		t.Fatalf("failed to decode annotations: %v", err)
+	}
+	if len(res.Annotations) != 1 || res.Annotations[0].Summary != "burn" {
+		t.Fatalf("unexpected annotations: %+v", res.Annotations)
+	}
+
+	id := res.Annotations[0].ID.String()
+	if w := do(http.MethodPatch, "/"+id, `{"message": "trajectory correction"}`); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "trajectory correction") {
+		t.Fatalf("unexpected response updating annotation: %d %s", w.Code, w.Body)
+	}
+	if w := do(http.MethodDelete, "/"+id, ""); w.Code != http.StatusNoContent {
+		t.Fatalf("unexpected status deleting annotation: %d %s", w.Code, w.Body)
+	}
+	if w := do(http.MethodGet, "/"+id, ""); w.Code != http.StatusNotFound {
+		t.Fatalf("unexpected status finding deleted annotation: %d %s", w.Code, w.Body)
+	}
+
+	if w := do(http.MethodGet, "/?orgID=0000000000000001&tag=craft", ""); w.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status listing with invalid tag: %d %s", w.Code, w.Body)
+	}
+}
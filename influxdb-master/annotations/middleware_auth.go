@@ -0,0 +1,71 @@
+package annotations
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+)
+
+var _ AnnotationService = (*AuthorizedService)(nil)
+
+// AuthorizedService is an AnnotationService authorizing access to annotations
+// with the annotations resource type. Listing annotations requires read
+// access to all the annotations of the organization.
+type AuthorizedService struct {
+	AnnotationService
+}
+
+// NewAuthorizedService returns an AuthorizedService authorizing access to the
+// annotations of s.
+func NewAuthorizedService(s AnnotationService) *AuthorizedService {
+	return &AuthorizedService{AnnotationService: s}
+}
+
+func (s *AuthorizedService) CreateAnnotation(ctx context.Context, a *Annotation) error {
+	if _, _, err := authorizer.AuthorizeCreate(ctx, influxdb.AnnotationsResourceType, a.OrganizationID); err != nil {
+		return err
+	}
+	return s.AnnotationService.CreateAnnotation(ctx, a)
+}
+
+func (s *AuthorizedService) FindAnnotationByID(ctx context.Context, id platform.ID) (*Annotation, error) {
+	a, err := s.AnnotationService.FindAnnotationByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := authorizer.AuthorizeRead(ctx, influxdb.AnnotationsResourceType, a.ID, a.OrganizationID); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (s *AuthorizedService) FindAnnotations(ctx context.Context, filter AnnotationFilter, opts ...influxdb.FindOptions) ([]*Annotation, int, error) {
+	if _, _, err := authorizer.AuthorizeOrgReadResource(ctx, influxdb.AnnotationsResourceType, filter.OrganizationID); err != nil {
+		return nil, 0, err
+	}
+	return s.AnnotationService.FindAnnotations(ctx, filter, opts...)
+}
+
+func (s *AuthorizedService) UpdateAnnotation(ctx context.Context, id platform.ID, upd AnnotationUpdate) (*Annotation, error) {
+	a, err := s.AnnotationService.FindAnnotationByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := authorizer.AuthorizeWrite(ctx, influxdb.AnnotationsResourceType, a.ID, a.OrganizationID); err != nil {
+		return nil, err
+	}
+	return s.AnnotationService.UpdateAnnotation(ctx, id, upd)
+}
+
+func (s *AuthorizedService) DeleteAnnotation(ctx context.Context, id platform.ID) error {
+	a, err := s.AnnotationService.FindAnnotationByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, _, err := authorizer.AuthorizeWrite(ctx, influxdb.AnnotationsResourceType, a.ID, a.OrganizationID); err != nil {
+		return err
+	}
+	return s.AnnotationService.DeleteAnnotation(ctx, id)
+}
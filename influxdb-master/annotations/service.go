@@ -0,0 +1,301 @@
+package annotations
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/internal/queryutil"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/snowflake"
+)
+
+var (
+	annotationBucket   = []byte("annotationsv1")
+	byOrgAndTimeBucket = []byte("annotationsbyorgtimev1")
+)
+
+// InternalAnnotationServiceError is used when the error comes from an
+// internal system.
+func InternalAnnotationServiceError(err error) *errors.Error {
+	return &errors.Error{
+		Code: errors.EInternal,
+		Msg:  fmt.Sprintf("Unknown internal annotation data error; Err: %v", err),
+		Op:   "kv/annotation",
+	}
+}
+
+var _ AnnotationService = (*Service)(nil)
+
+// Service is an AnnotationService storing annotations in a kv store.
+// Annotations are indexed by organization and start time, so that the
+// annotations of a time range are found without reading those starting after
+// it.
+type Service struct {
+	kv kv.Store
+
+	IDGenerator   platform.IDGenerator
+	TimeGenerator influxdb.TimeGenerator
+}
+
+// NewService constructs and configures a new annotation service.
+func NewService(store kv.Store) *Service {
+	return &Service{
+		kv:            store,
+		IDGenerator:   snowflake.NewIDGenerator(),
+		TimeGenerator: influxdb.RealTimeGenerator{},
+	}
+}
+
+// CreateAnnotation creates an annotation, setting its ID.
+func (s *Service) CreateAnnotation(ctx context.Context, a *Annotation) error {
+	if err := a.Validate(); err != nil {
+		return err
+	}
+	a.ID = s.IDGenerator.ID()
+	now := s.TimeGenerator.Now()
+	a.SetCreatedAt(now)
+	a.SetUpdatedAt(now)
+
+	return s.kv.Update(ctx, func(tx kv.Tx) error {
+		return putAnnotation(tx, a)
+	})
+}
+
+// FindAnnotationByID returns a single annotation by ID.
+func (s *Service) FindAnnotationByID(ctx context.Context, id platform.ID) (*Annotation, error) {
+	var a *Annotation
+	err := s.kv.View(ctx, func(tx kv.Tx) error {
+		var err error
+		a, err = findAnnotationByID(tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// FindAnnotations returns the annotations matching filter, ordered by start
+// time. The organization of filter is required.
+func (s *Service) FindAnnotations(ctx context.Context, filter AnnotationFilter, opts ...influxdb.FindOptions) ([]*Annotation, int, error) {
+	if !filter.OrganizationID.Valid() {
+		return nil, 0, ErrInvalidOrgID
+	}
+	prefix, err := filter.OrganizationID.Encode()
+	if err != nil {
+		return nil, 0, ErrInvalidOrgID
+	}
+
+	var as []*Annotation
+	err = s.kv.View(ctx, func(tx kv.Tx) error {
+		idx, err := tx.Bucket(byOrgAndTimeBucket)
+		if err != nil {
+			return InternalAnnotationServiceError(err)
+		}
+		cur, err := idx.ForwardCursor(prefix, kv.WithCursorPrefix(prefix))
+		if err != nil {
+			return InternalAnnotationServiceError(err)
+		}
+		defer cur.Close()
+
+		var end []byte
+		if filter.EndTime != nil {
+			end = indexKeyPrefix(prefix, *filter.EndTime)
+		}
+		for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+			// Annotations starting after the end of the filter are past
+			// the time range, as are all those after them.
+			if end != nil && bytes.Compare(k[:len(end)], end) > 0 {
+				break
+			}
+
+			var id platform.ID
+			if err := id.Decode(v); err != nil {
+				return InternalAnnotationServiceError(err)
+			}
+			a, err := findAnnotationByID(tx, id)
+			if err != nil {
+				return err
+			}
+			if filter.matches(a) {
+				as = append(as, a)
+			}
+		}
+		return cur.Err()
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total := len(as)
+	if len(opts) > 0 {
+		if opts[0].Descending {
+			for i, j := 0, len(as)-1; i < j; i, j = i+1, j-1 {
+				as[i], as[j] = as[j], as[i]
+			}
+		}
+		lo, hi := queryutil.PageBounds(len(as), opts[0], nil)
+		as = as[lo:hi]
+	}
+	return as, total, nil
+}
+
+// UpdateAnnotation updates a single annotation and returns the result.
+func (s *Service) UpdateAnnotation(ctx context.Context, id platform.ID, upd AnnotationUpdate) (*Annotation, error) {
+	var a *Annotation
+	err := s.kv.Update(ctx, func(tx kv.Tx) error {
+		var err error
+		if a, err = findAnnotationByID(tx, id); err != nil {
+			return err
+		}
+		// The start time may change, moving the annotation in the index.
+		if err := deleteIndexKey(tx, a); err != nil {
+			return err
+		}
+		if err := upd.Apply(a); err != nil {
+			return err
+		}
+		a.SetUpdatedAt(s.TimeGenerator.Now())
+		return putAnnotation(tx, a)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// DeleteAnnotation deletes a single annotation by ID.
+func (s *Service) DeleteAnnotation(ctx context.Context, id platform.ID) error {
+	return s.kv.Update(ctx, func(tx kv.Tx) error {
+		a, err := findAnnotationByID(tx, id)
+		if err != nil {
+			return err
+		}
+		if err := deleteIndexKey(tx, a); err != nil {
+			return err
+		}
+		key, err := id.Encode()
+		if err != nil {
+			return InternalAnnotationServiceError(err)
+		}
+		b, err := tx.Bucket(annotationBucket)
+		if err != nil {
+			return InternalAnnotationServiceError(err)
+		}
+		if err := b.Delete(key); err != nil {
+			return InternalAnnotationServiceError(err)
+		}
+		return nil
+	})
+}
+
+func findAnnotationByID(tx kv.Tx, id platform.ID) (*Annotation, error) {
+	key, err := id.Encode()
+	if err != nil {
+		return nil, &errors.Error{
+			Code: errors.EInvalid,
+			Err:  err,
+		}
+	}
+	b, err := tx.Bucket(annotationBucket)
+	if err != nil {
+		return nil, InternalAnnotationServiceError(err)
+	}
+	data, err := b.Get(key)
+	if kv.IsNotFound(err) {
+		return nil, ErrAnnotationNotFound
+	}
+	if err != nil {
+		return nil, InternalAnnotationServiceError(err)
+	}
+
+	var a Annotation
+	if err := json.Unmarshal(data, &a); err != nil {
+		return nil, InternalAnnotationServiceError(err)
+	}
+	return &a, nil
+}
+
+// putAnnotation stores an annotation and its index key.
+func putAnnotation(tx kv.Tx, a *Annotation) error {
+	key, err := a.ID.Encode()
+	if err != nil {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Err:  err,
+		}
+	}
+	data, err := json.Marshal(a)
+	if err != nil {
+		return InternalAnnotationServiceError(err)
+	}
+	b, err := tx.Bucket(annotationBucket)
+	if err != nil {
+		return InternalAnnotationServiceError(err)
+	}
+	if err := b.Put(key, data); err != nil {
+		return InternalAnnotationServiceError(err)
+	}
+
+	indexKey, err := annotationIndexKey(a)
+	if err != nil {
+		return err
+	}
+	idx, err := tx.Bucket(byOrgAndTimeBucket)
+	if err != nil {
+		return InternalAnnotationServiceError(err)
+	}
+	if err := idx.Put(indexKey, key); err != nil {
+		return InternalAnnotationServiceError(err)
+	}
+	return nil
+}
+
+func deleteIndexKey(tx kv.Tx, a *Annotation) error {
+	indexKey, err := annotationIndexKey(a)
+	if err != nil {
+		return err
+	}
+	idx, err := tx.Bucket(byOrgAndTimeBucket)
+	if err != nil {
+		return InternalAnnotationServiceError(err)
+	}
+	if err := idx.Delete(indexKey); err != nil {
+		return InternalAnnotationServiceError(err)
+	}
+	return nil
+}
+
+// annotationIndexKey returns the key of an annotation in the index by
+// organization and time: its organization ID, its start time and its ID.
+func annotationIndexKey(a *Annotation) ([]byte, error) {
+	orgID, err := a.OrganizationID.Encode()
+	if err != nil {
+		return nil, ErrInvalidOrgID
+	}
+	id, err := a.ID.Encode()
+	if err != nil {
+		return nil, &errors.Error{
+			Code: errors.EInvalid,
+			Err:  err,
+		}
+	}
+	return append(indexKeyPrefix(orgID, a.StartTime), id...), nil
+}
+
+// indexKeyPrefix returns the prefix of the index keys of the annotations of
+// an organization starting at t. The time is encoded so that the keys sort in
+// time order, including times before the epoch.
+func indexKeyPrefix(orgID []byte, t time.Time) []byte {
+	key := make([]byte, len(orgID)+8)
+	copy(key, orgID)
+	binary.BigEndian.PutUint64(key[len(orgID):], uint64(t.UnixNano())^(1<<63))
+	return key
+}
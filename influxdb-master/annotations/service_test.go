@@ -0,0 +1,203 @@
+package annotations_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/annotations"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/inmem"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/kv/migration/all"
+	"github.com/influxdata/influxdb/v2/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+var now = time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestService(t *testing.T) *annotations.Service {
+	t.Helper()
+
+	s := inmem.NewKVStore()
+	if err := all.Up(context.Background(), zaptest.NewLogger(t), s); err != nil {
+		t.Fatalf("failed to apply migrations: %v", err)
+	}
+
+	svc := annotations.NewService(s)
+	svc.IDGenerator = mock.NewMockIDGenerator()
+	svc.TimeGenerator = mock.TimeGenerator{FakeValue: now}
+	return svc
+}
+
+func summaries(as []*annotations.Annotation) []string {
+	s := make([]string, 0, len(as))
+	for _, a := range as {
+		s = append(s, a.Summary)
+	}
+	return s
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestService_FindAnnotations(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t)
+
+	at := func(h int) time.Time { return now.Add(time.Duration(h) * time.Hour) }
+	for _, a := range []*annotations.Annotation{
+		{OrganizationID: 1, Summary: "upload", StartTime: at(-2), Tags: map[string]string{"craft": "voyager1"}},
+		{OrganizationID: 1, Summary: "safe mode", Stream: "faults", StartTime: at(-30), EndTime: at(-1), Tags: map[string]string{"craft": "voyager2"}},
+		{OrganizationID: 1, Summary: "burn", Stream: "maneuvers", StartTime: at(-5), EndTime: at(-4), Tags: map[string]string{"craft": "voyager1"}},
+		{OrganizationID: 1, Summary: "before epoch", StartTime: time.Unix(-10, 0)},
+		{OrganizationID: 2, Summary: "other org", StartTime: at(-2)},
+	} {
+		if err := svc.CreateAnnotation(ctx, a); err != nil {
+			t.Fatalf("failed to create annotation: %v", err)
+		}
+	}
+
+	start, end := at(-3), at(0)
+	tests := []struct {
+		name   string
+		filter annotations.AnnotationFilter
+		opts   []influxdb.FindOptions
+		want   []string
+	}{
+		{
+			name:   "all of organization ordered by start time",
+			filter: annotations.AnnotationFilter{OrganizationID: 1},
+			want:   []string{"before epoch", "safe mode", "burn", "upload"},
+		},
+		{
+			name:   "overlapping time range",
+			filter: annotations.AnnotationFilter{OrganizationID: 1, StartTime: &start, EndTime: &end},
+			want:   []string{"safe mode", "upload"},
+		},
+		{
+			name:   "by streams",
+			filter: annotations.AnnotationFilter{OrganizationID: 1, Streams: []string{"faults", annotations.DefaultStream}},
+			want:   []string{"before epoch", "safe mode", "upload"},
+		},
+		{
+			name:   "by tags",
+			filter: annotations.AnnotationFilter{OrganizationID: 1, Tags: map[string]string{"craft": "voyager1"}},
+			want:   []string{"burn", "upload"},
+		},
+		{
+			name:   "descending with limit and offset",
+			filter: annotations.AnnotationFilter{OrganizationID: 1},
+			opts:   []influxdb.FindOptions{{Descending: true, Offset: 1, Limit: 2}},
+			want:   []string{"burn", "safe mode"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			as, _, err := svc.FindAnnotations(ctx, tt.filter, tt.opts...)
+			if err != nil {
+				t.Fatalf("failed to find annotations: %v", err)
+			}
+			if got := summaries(as); !equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, _, err := svc.FindAnnotations(ctx, annotations.AnnotationFilter{}); errors.ErrorCode(err) != errors.EInvalid {
+		t.Errorf("expected invalid error without organization, got %v", err)
+	}
+}
+
+func TestService_UpdateAnnotation(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t)
+
+	a := &annotations.Annotation{OrganizationID: 1, Summary: "burn", StartTime: now}
+	if err := svc.CreateAnnotation(ctx, a); err != nil {
+		t.Fatalf("failed to create annotation: %v", err)
+	}
+	if a.Stream != annotations.DefaultStream || !a.EndTime.Equal(now) || !a.CreatedAt.Equal(now) {
+		t.Fatalf("annotation not defaulted: %+v", a)
+	}
+
+	start, summary := now.Add(-time.Hour), "trajectory correction burn"
+	upd, err := svc.UpdateAnnotation(ctx, a.ID, annotations.AnnotationUpdate{StartTime: &start, Summary: &summary})
+	if err != nil {
+		t.Fatalf("failed to update annotation: %v", err)
+	}
+	if upd.Summary != summary || !upd.StartTime.Equal(start) || !upd.EndTime.Equal(now) {
+		t.Fatalf("unexpected updated annotation: %+v", upd)
+	}
+
+	// The annotation moved in the index, and is found once at its new time.
+	end := now.Add(-30 * time.Minute)
+	as, _, err := svc.FindAnnotations(ctx, annotations.AnnotationFilter{OrganizationID: 1, EndTime: &end})
+	if err != nil {
+		t.Fatalf("failed to find annotations: %v", err)
+	}
+	if got := summaries(as); !equal(got, []string{summary}) {
+		t.Fatalf("got %v after update", got)
+	}
+
+	stop := now.Add(-2 * time.Hour)
+	if _, err := svc.UpdateAnnotation(ctx, a.ID, annotations.AnnotationUpdate{EndTime: &stop}); errors.ErrorCode(err) != errors.EInvalid {
+		t.Fatalf("expected invalid error for end before start, got %v", err)
+	}
+
+	if err := svc.DeleteAnnotation(ctx, a.ID); err != nil {
+		t.Fatalf("failed to delete annotation: %v", err)
+	}
+	if _, err := svc.FindAnnotationByID(ctx, a.ID); errors.ErrorCode(err) != errors.ENotFound {
+		t.Fatalf("expected not found error after delete, got %v", err)
+	}
+	as, _, err = svc.FindAnnotations(ctx, annotations.AnnotationFilter{OrganizationID: 1})
+	if err != nil || len(as) != 0 {
+		t.Fatalf("expected no annotations after delete, got %v %v", as, err)
+	}
+}
+
+func TestAuthorizedService(t *testing.T) {
+	svc := annotations.NewAuthorizedService(newTestService(t))
+
+	orgID := platform.ID(1)
+	readOnly := icontext.SetAuthorizer(context.Background(), mock.NewMockAuthorizer(false, []influxdb.Permission{
+		{Action: influxdb.ReadAction, Resource: influxdb.Resource{Type: influxdb.AnnotationsResourceType, OrgID: &orgID}},
+	}))
+	readWrite := icontext.SetAuthorizer(context.Background(), mock.NewMockAuthorizer(false, []influxdb.Permission{
+		{Action: influxdb.ReadAction, Resource: influxdb.Resource{Type: influxdb.AnnotationsResourceType, OrgID: &orgID}},
+		{Action: influxdb.WriteAction, Resource: influxdb.Resource{Type: influxdb.AnnotationsResourceType, OrgID: &orgID}},
+	}))
+
+	a := &annotations.Annotation{OrganizationID: orgID, Summary: "burn", StartTime: now}
+	if err := svc.CreateAnnotation(readOnly, a); errors.ErrorCode(err) != errors.EUnauthorized {
+		t.Fatalf("expected unauthorized error creating with read permission, got %v", err)
+	}
+	if err := svc.CreateAnnotation(readWrite, a); err != nil {
+		t.Fatalf("failed to create annotation: %v", err)
+	}
+
+	if _, err := svc.FindAnnotationByID(readOnly, a.ID); err != nil {
+		t.Fatalf("failed to find annotation: %v", err)
+	}
+	if _, _, err := svc.FindAnnotations(readOnly, annotations.AnnotationFilter{OrganizationID: 2}); errors.ErrorCode(err) != errors.EUnauthorized {
+		t.Fatalf("expected unauthorized error listing other organization, got %v", err)
+	}
+	if err := svc.DeleteAnnotation(readOnly, a.ID); errors.ErrorCode(err) != errors.EUnauthorized {
+		t.Fatalf("expected unauthorized error deleting with read permission, got %v", err)
+	}
+	if err := svc.DeleteAnnotation(readWrite, a.ID); err != nil {
+		t.Fatalf("failed to delete annotation: %v", err)
+	}
+}
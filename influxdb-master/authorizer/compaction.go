@@ -0,0 +1,64 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+)
+
+var _ influxdb.CompactionService = (*CompactionService)(nil)
+
+// CompactionService wraps a influxdb.CompactionService and authorizes actions
+// against it appropriately.
+type CompactionService struct {
+	s influxdb.CompactionService
+}
+
+// NewCompactionService constructs an instance of an authorizing compaction service.
+func NewCompactionService(s influxdb.CompactionService) *CompactionService {
+	return &CompactionService{
+		s: s,
+	}
+}
+
+func (c CompactionService) FindShardCompactions(ctx context.Context, bucketID *platform.ID) ([]*influxdb.ShardCompaction, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.OperPermissions()); err != nil {
+		return nil, err
+	}
+	return c.s.FindShardCompactions(ctx, bucketID)
+}
+
+func (c CompactionService) PauseShardCompactions(ctx context.Context, shardID uint64) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.OperPermissions()); err != nil {
+		return err
+	}
+	return c.s.PauseShardCompactions(ctx, shardID)
+}
+
+func (c CompactionService) ResumeShardCompactions(ctx context.Context, shardID uint64) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.OperPermissions()); err != nil {
+		return err
+	}
+	return c.s.ResumeShardCompactions(ctx, shardID)
+}
+
+func (c CompactionService) TriggerShardCompaction(ctx context.Context, shardID uint64) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.OperPermissions()); err != nil {
+		return err
+	}
+	return c.s.TriggerShardCompaction(ctx, shardID)
+}
@@ -0,0 +1,55 @@
+package authorizer
+
+import (
+	"context"
+	"io"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+)
+
+var _ influxdb.DigestService = (*DigestService)(nil)
+
+// DigestService wraps a influxdb.DigestService and authorizes actions
+// against it appropriately.
+type DigestService struct {
+	s influxdb.DigestService
+}
+
+// NewDigestService constructs an instance of an authorizing digest service.
+func NewDigestService(s influxdb.DigestService) *DigestService {
+	return &DigestService{
+		s: s,
+	}
+}
+
+func (d DigestService) BucketDigest(ctx context.Context, bucketID platform.ID, opts influxdb.DigestOptions, w io.Writer) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.OperPermissions()); err != nil {
+		return err
+	}
+	return d.s.BucketDigest(ctx, bucketID, opts, w)
+}
+
+func (d DigestService) ExportDigestRanges(ctx context.Context, bucketID platform.ID, ranges []influxdb.DigestRange, w io.Writer) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.OperPermissions()); err != nil {
+		return err
+	}
+	return d.s.ExportDigestRanges(ctx, bucketID, ranges, w)
+}
+
+func (d DigestService) DeleteDigestRanges(ctx context.Context, bucketID platform.ID, ranges []influxdb.DigestRange) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.OperPermissions()); err != nil {
+		return err
+	}
+	return d.s.DeleteDigestRanges(ctx, bucketID, ranges)
+}
@@ -0,0 +1,43 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+)
+
+var _ influxdb.StorageUsageService = (*StorageUsageService)(nil)
+
+// StorageUsageService wraps a influxdb.StorageUsageService and authorizes
+// actions against it appropriately.
+type StorageUsageService struct {
+	s             influxdb.StorageUsageService
+	bucketService influxdb.BucketService
+}
+
+// NewStorageUsageService constructs an instance of an authorizing storage
+// usage service. The bucket service finds the organization of buckets.
+func NewStorageUsageService(s influxdb.StorageUsageService, bucketService influxdb.BucketService) *StorageUsageService {
+	return &StorageUsageService{
+		s:             s,
+		bucketService: bucketService,
+	}
+}
+
+// FindBucketStorageUsage checks to see if the authorizer on context has read
+// access to the bucket.
+func (s *StorageUsageService) FindBucketStorageUsage(ctx context.Context, bucketID platform.ID, tagKey string) (*influxdb.BucketStorageUsage, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	b, err := s.bucketService.FindBucketByID(ctx, bucketID)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := AuthorizeReadBucket(ctx, b.Type, b.ID, b.OrgID); err != nil {
+		return nil, err
+	}
+	return s.s.FindBucketStorageUsage(ctx, bucketID, tagKey)
+}
@@ -135,6 +135,10 @@ const (
 	ChecksResourceType = ResourceType("checks") // 16
 	// DBRPType gives permission to one or more DBRPs.
 	DBRPResourceType = ResourceType("dbrp") // 17
+	// AnnotationsResourceType gives permission to one or more annotations.
+	AnnotationsResourceType = ResourceType("annotations") // 18
+	// NotebooksResourceType gives permission to one or more notebooks.
+	NotebooksResourceType = ResourceType("notebooks") // 19
 )
 
 // AllResourceTypes is the list of all known resource types.
@@ -157,6 +161,8 @@ var AllResourceTypes = []ResourceType{
 	NotificationEndpointResourceType, // 15
 	ChecksResourceType,               // 16
 	DBRPResourceType,                 // 17
+	AnnotationsResourceType,          // 18
+	NotebooksResourceType,            // 19
 	// NOTE: when modifying this list, please update the swagger for components.schemas.Permission resource enum.
 }
 
@@ -175,6 +181,8 @@ var OrgResourceTypes = []ResourceType{
 	NotificationEndpointResourceType, // 15
 	ChecksResourceType,               // 16
 	DBRPResourceType,                 // 17
+	AnnotationsResourceType,          // 18
+	NotebooksResourceType,            // 19
 }
 
 // Valid checks if the resource type is a member of the ResourceType enum.
@@ -203,6 +211,8 @@ func (t ResourceType) Valid() (err error) {
 	case NotificationEndpointResourceType: // 15
 	case ChecksResourceType: // 16
 	case DBRPResourceType: // 17
+	case AnnotationsResourceType: // 18
+	case NotebooksResourceType: // 19
 	default:
 		err = ErrInvalidResourceType
 	}
@@ -0,0 +1,205 @@
+package backup
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/pkg/tar"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/influxdata/influxdb/v2/tsdb/engine/tsm1"
+)
+
+var _ influxdb.DigestService = (*DigestService)(nil)
+
+// DigestService implements influxdb.DigestService over the shards of a backup
+// directory, so that a backup can be compared with a bucket or another backup.
+type DigestService struct {
+	path   string
+	shards []*influxdb.ManifestEntry
+}
+
+// NewDigestService returns a DigestService of the most recent backup of every
+// shard of the backup directory at path.
+func NewDigestService(path string) (*DigestService, error) {
+	manifests, err := filepath.Glob(filepath.Join(path, "*.manifest"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find backup manifests at %q: %w", path, err)
+	} else if len(manifests) == 0 {
+		return nil, fmt.Errorf("no backup manifest found at %q", path)
+	}
+
+	latest := make(map[uint64]*influxdb.ManifestEntry)
+	for _, filename := range manifests {
+		var manifest influxdb.Manifest
+		if buf, err := ioutil.ReadFile(filename); err != nil {
+			return nil, fmt.Errorf("failed to read local manifest at %q: %w", filename, err)
+		} else if err := json.Unmarshal(buf, &manifest); err != nil {
+			return nil, fmt.Errorf("read manifest: %v", err)
+		}
+
+		for i := range manifest.Files {
+			sh := manifest.Files[i]
+			if _, err := os.Stat(filepath.Join(path, sh.FileName)); err != nil {
+				continue
+			}
+			if entry := latest[sh.ShardID]; entry == nil || sh.LastModified.After(entry.LastModified) {
+				latest[sh.ShardID] = &sh
+			}
+		}
+	}
+
+	s := &DigestService{path: path}
+	for _, sh := range latest {
+		s.shards = append(s.shards, sh)
+	}
+	sort.Slice(s.shards, func(i, j int) bool { return s.shards[i].ShardID < s.shards[j].ShardID })
+	return s, nil
+}
+
+// BucketDigest writes the windowed digest of the points of the backed up
+// shards of a bucket to w.
+func (s *DigestService) BucketDigest(ctx context.Context, bucketID platform.ID, opts influxdb.DigestOptions, w io.Writer) error {
+	var readers []*tsm1.DigestReader
+	var paths []string
+	defer func() {
+		for _, r := range readers {
+			r.Close()
+		}
+		for _, path := range paths {
+			os.Remove(path)
+		}
+	}()
+
+	tsdbOpts := tsdb.DigestOptions{MinTime: opts.Start, MaxTime: opts.Stop, Window: opts.Window}
+	for _, sh := range s.bucketShards(bucketID) {
+		f, err := ioutil.TempFile("", "backup_digest")
+		if err != nil {
+			return err
+		}
+		paths = append(paths, f.Name())
+
+		err = s.withShard(sh, func(tsmReaders []*tsm1.TSMReader) error {
+			return tsm1.WindowDigest(tsmReaders, tsdbOpts, f)
+		})
+		if err == nil {
+			_, err = f.Seek(0, io.SeekStart)
+		}
+		if err != nil {
+			f.Close()
+			return err
+		}
+
+		r, err := tsm1.NewDigestReader(f)
+		if err != nil {
+			f.Close()
+			return err
+		}
+		readers = append(readers, r)
+	}
+
+	return tsm1.MergeWindowDigests(w, readers...)
+}
+
+// ExportDigestRanges writes the points of the ranges of the backed up shards
+// of a bucket to w as line protocol.
+func (s *DigestService) ExportDigestRanges(ctx context.Context, bucketID platform.ID, ranges []influxdb.DigestRange, w io.Writer) error {
+	keyRanges := make([]tsdb.KeyRange, 0, len(ranges))
+	for _, r := range ranges {
+		keyRanges = append(keyRanges, tsdb.KeyRange{Key: []byte(r.Key), Min: r.Start, Max: r.Stop})
+	}
+
+	for _, sh := range s.bucketShards(bucketID) {
+		err := s.withShard(sh, func(tsmReaders []*tsm1.TSMReader) error {
+			return tsm1.ExportKeyRanges(tsmReaders, keyRanges, w)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteDigestRanges returns an error, as a backup cannot be repaired. The
+// backup of a repaired bucket is repaired instead.
+func (s *DigestService) DeleteDigestRanges(ctx context.Context, bucketID platform.ID, ranges []influxdb.DigestRange) error {
+	return &errors.Error{
+		Code: errors.ENotImplemented,
+		Msg:  "the points of a backup cannot be deleted",
+	}
+}
+
+func (s *DigestService) bucketShards(bucketID platform.ID) []*influxdb.ManifestEntry {
+	var shards []*influxdb.ManifestEntry
+	for _, sh := range s.shards {
+		if sh.BucketID == bucketID.String() {
+			shards = append(shards, sh)
+		}
+	}
+	return shards
+}
+
+// withShard extracts the files of a backed up shard to a temporary directory
+// and calls fn with the readers of its TSM files, ordered by generation.
+func (s *DigestService) withShard(sh *influxdb.ManifestEntry, fn func([]*tsm1.TSMReader) error) error {
+	dir, err := ioutil.TempDir("", "backup_digest_shard")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	if err := extractShard(filepath.Join(s.path, sh.FileName), dir); err != nil {
+		return fmt.Errorf("failed to extract shard %d: %w", sh.ShardID, err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*."+tsm1.TSMFileExtension))
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+
+	readers := make([]*tsm1.TSMReader, 0, len(files))
+	defer func() {
+		for _, r := range readers {
+			r.Close()
+		}
+	}()
+	for _, path := range files {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		r, err := tsm1.NewTSMReader(f)
+		if err != nil {
+			f.Close()
+			return fmt.Errorf("failed to open %q of shard %d: %w", filepath.Base(path), sh.ShardID, err)
+		}
+		readers = append(readers, r)
+	}
+	return fn(readers)
+}
+
+func extractShard(path, dir string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gr.Close()
+
+	return tar.Restore(gr, dir)
+}
@@ -40,6 +40,8 @@ type Bucket struct {
 	RetentionPolicyName string        `json:"rp,omitempty"` // This to support v1 sources
 	RetentionPeriod     time.Duration `json:"retentionPeriod"`
 	ShardGroupDuration  time.Duration `json:"shardGroupDuration"`
+	MaxSeries           int64         `json:"maxSeries,omitempty"`       // 0 is unlimited
+	MaxValuesPerTag     int64         `json:"maxValuesPerTag,omitempty"` // 0 is unlimited
 	CRUDLog
 }
 
@@ -110,6 +112,8 @@ type BucketUpdate struct {
 	Description        *string
 	RetentionPeriod    *time.Duration
 	ShardGroupDuration *time.Duration
+	MaxSeries          *int64
+	MaxValuesPerTag    *int64
 }
 
 // BucketFilter represents a set of filter that restrict the returned results.
@@ -0,0 +1,422 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/annotations"
+	"github.com/influxdata/influxdb/v2/cmd/influx/internal"
+	"github.com/influxdata/influxdb/v2/kit/cli"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/spf13/cobra"
+)
+
+func cmdAnnotation(f *globalFlags, opt genericCLIOpts) *cobra.Command {
+	cmd := opt.newCmd("annotation", nil, false)
+	cmd.Short = "Annotation management commands"
+	cmd.Run = seeHelp
+
+	cmd.AddCommand(
+		annotationCreateCmd(f, opt),
+		annotationFindCmd(f, opt),
+		annotationUpdateCmd(f, opt),
+		annotationDeleteCmd(f, opt),
+	)
+
+	return cmd
+}
+
+var annotationCRUDFlags struct {
+	json        bool
+	hideHeaders bool
+}
+
+var annotationCreateFlags struct {
+	Org     organization
+	Stream  string
+	Summary string
+	Message string
+	Tags    []string
+	Start   string
+	End     string
+}
+
+func annotationCreateCmd(f *globalFlags, opt genericCLIOpts) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create an annotation",
+		RunE:  checkSetupRunEMiddleware(&flags)(annotationCreateF),
+		Args:  cobra.NoArgs,
+	}
+
+	f.registerFlags(opt.viper, cmd)
+	registerPrintOptions(opt.viper, cmd, &annotationCRUDFlags.hideHeaders, &annotationCRUDFlags.json)
+	annotationCreateFlags.Org.register(opt.viper, cmd, false)
+	cmd.Flags().StringVar(&annotationCreateFlags.Summary, "summary", "", "The summary of the annotation")
+	_ = cmd.MarkFlagRequired("summary")
+	cmd.Flags().StringVar(&annotationCreateFlags.Stream, "stream", "", "The stream of the annotation; defaults to the default stream")
+	cmd.Flags().StringVar(&annotationCreateFlags.Message, "message", "", "The message of the annotation")
+	cmd.Flags().StringArrayVar(&annotationCreateFlags.Tags, "tag", nil, "A tag of the annotation in the form key=value; may be repeated")
+	cmd.Flags().StringVar(&annotationCreateFlags.Start, "start", "", "The start time in RFC3339Nano format, exp 2009-01-02T23:00:00Z; defaults to now")
+	cmd.Flags().StringVar(&annotationCreateFlags.End, "end", "", "The end time in RFC3339Nano format; defaults to the start time")
+
+	return cmd
+}
+
+func annotationCreateF(cmd *cobra.Command, _ []string) error {
+	if err := annotationCreateFlags.Org.validOrgFlags(&flags); err != nil {
+		return err
+	}
+	orgSvc, err := newOrganizationService()
+	if err != nil {
+		return err
+	}
+	orgID, err := annotationCreateFlags.Org.getID(orgSvc)
+	if err != nil {
+		return err
+	}
+
+	a := &annotations.Annotation{
+		OrganizationID: orgID,
+		Stream:         annotationCreateFlags.Stream,
+		Summary:        annotationCreateFlags.Summary,
+		Message:        annotationCreateFlags.Message,
+		StartTime:      time.Now().UTC(),
+	}
+	if a.Tags, err = parseAnnotationTags(annotationCreateFlags.Tags); err != nil {
+		return err
+	}
+	if annotationCreateFlags.Start != "" {
+		if a.StartTime, err = parseAnnotationTime("start", annotationCreateFlags.Start); err != nil {
+			return err
+		}
+	}
+	if annotationCreateFlags.End != "" {
+		if a.EndTime, err = parseAnnotationTime("end", annotationCreateFlags.End); err != nil {
+			return err
+		}
+	}
+
+	s, err := newAnnotationService()
+	if err != nil {
+		return err
+	}
+	if err := s.CreateAnnotation(context.Background(), a); err != nil {
+		return err
+	}
+	return writeAnnotations(cmd.OutOrStdout(), annotationPrintOpt{
+		jsonOut:     annotationCRUDFlags.json,
+		hideHeaders: annotationCRUDFlags.hideHeaders,
+		annotation:  a,
+	})
+}
+
+var annotationFindFlags struct {
+	ID      platform.ID
+	Org     organization
+	Streams []string
+	Tags    []string
+	Start   string
+	End     string
+	Limit   int
+}
+
+func annotationFindCmd(f *globalFlags, opt genericCLIOpts) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "list",
+		Short:   "List annotations",
+		Aliases: []string{"find", "ls"},
+		RunE:    checkSetupRunEMiddleware(&flags)(annotationFindF),
+		Args:    cobra.NoArgs,
+	}
+
+	f.registerFlags(opt.viper, cmd)
+	registerPrintOptions(opt.viper, cmd, &annotationCRUDFlags.hideHeaders, &annotationCRUDFlags.json)
+	annotationFindFlags.Org.register(opt.viper, cmd, false)
+	cli.IDVar(cmd.Flags(), &annotationFindFlags.ID, "id", 0, "Limit results to a single annotation")
+	cmd.Flags().StringArrayVar(&annotationFindFlags.Streams, "stream", nil, "Limit results to the matching stream; may be repeated")
+	cmd.Flags().StringArrayVar(&annotationFindFlags.Tags, "tag", nil, "Limit results to annotations with the tag in the form key=value; may be repeated")
+	cmd.Flags().StringVar(&annotationFindFlags.Start, "start", "", "Limit results to annotations ending after the start time in RFC3339Nano format")
+	cmd.Flags().StringVar(&annotationFindFlags.End, "end", "", "Limit results to annotations starting before the end time in RFC3339Nano format")
+	cmd.Flags().IntVar(&annotationFindFlags.Limit, "limit", 0, "The maximum number of annotations to list")
+
+	return cmd
+}
+
+func annotationFindF(cmd *cobra.Command, _ []string) error {
+	s, err := newAnnotationService()
+	if err != nil {
+		return err
+	}
+
+	if annotationFindFlags.ID.Valid() {
+		a, err := s.FindAnnotationByID(context.Background(), annotationFindFlags.ID)
+		if err != nil {
+			return err
+		}
+		return writeAnnotations(cmd.OutOrStdout(), annotationPrintOpt{
+			jsonOut:     annotationCRUDFlags.json,
+			hideHeaders: annotationCRUDFlags.hideHeaders,
+			annotation:  a,
+		})
+	}
+
+	if err := annotationFindFlags.Org.validOrgFlags(&flags); err != nil {
+		return err
+	}
+	orgSvc, err := newOrganizationService()
+	if err != nil {
+		return err
+	}
+	orgID, err := annotationFindFlags.Org.getID(orgSvc)
+	if err != nil {
+		return err
+	}
+
+	filter := annotations.AnnotationFilter{
+		OrganizationID: orgID,
+		Streams:        annotationFindFlags.Streams,
+	}
+	if filter.Tags, err = parseAnnotationTags(annotationFindFlags.Tags); err != nil {
+		return err
+	}
+	if annotationFindFlags.Start != "" {
+		t, err := parseAnnotationTime("start", annotationFindFlags.Start)
+		if err != nil {
+			return err
+		}
+		filter.StartTime = &t
+	}
+	if annotationFindFlags.End != "" {
+		t, err := parseAnnotationTime("end", annotationFindFlags.End)
+		if err != nil {
+			return err
+		}
+		filter.EndTime = &t
+	}
+
+	as, _, err := s.FindAnnotations(context.Background(), filter, influxdb.FindOptions{Limit: annotationFindFlags.Limit})
+	if err != nil {
+		return err
+	}
+	return writeAnnotations(cmd.OutOrStdout(), annotationPrintOpt{
+		jsonOut:     annotationCRUDFlags.json,
+		hideHeaders: annotationCRUDFlags.hideHeaders,
+		annotations: as,
+	})
+}
+
+var annotationUpdateFlags struct {
+	ID      platform.ID
+	Stream  string
+	Summary string
+	Message string
+	Tags    []string
+	Start   string
+	End     string
+}
+
+func annotationUpdateCmd(f *globalFlags, opt genericCLIOpts) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "update",
+		Short: "Update an annotation",
+		RunE:  checkSetupRunEMiddleware(&flags)(annotationUpdateF),
+		Args:  cobra.NoArgs,
+	}
+
+	f.registerFlags(opt.viper, cmd)
+	registerPrintOptions(opt.viper, cmd, &annotationCRUDFlags.hideHeaders, &annotationCRUDFlags.json)
+	cli.IDVar(cmd.Flags(), &annotationUpdateFlags.ID, "id", 0, "The ID of the annotation to update")
+	_ = cmd.MarkFlagRequired("id")
+	// note for update we only care about update flags that the user set
+	cmd.Flags().StringVar(&annotationUpdateFlags.Stream, "stream", "", "The updated stream of the annotation")
+	cmd.Flags().StringVar(&annotationUpdateFlags.Summary, "summary", "", "The updated summary of the annotation")
+	cmd.Flags().StringVar(&annotationUpdateFlags.Message, "message", "", "The updated message of the annotation")
+	cmd.Flags().StringArrayVar(&annotationUpdateFlags.Tags, "tag", nil, "A tag of the annotation in the form key=value, replacing all its tags; may be repeated")
+	cmd.Flags().StringVar(&annotationUpdateFlags.Start, "start", "", "The updated start time in RFC3339Nano format")
+	cmd.Flags().StringVar(&annotationUpdateFlags.End, "end", "", "The updated end time in RFC3339Nano format")
+
+	return cmd
+}
+
+func annotationUpdateF(cmd *cobra.Command, _ []string) error {
+	var upd annotations.AnnotationUpdate
+	changed := func(name string) bool { return cmd.Flags().Lookup(name).Changed }
+	if changed("stream") {
+		upd.Stream = &annotationUpdateFlags.Stream
+	}
+	if changed("summary") {
+		upd.Summary = &annotationUpdateFlags.Summary
+	}
+	if changed("message") {
+		upd.Message = &annotationUpdateFlags.Message
+	}
+	if changed("tag") {
+		tags, err := parseAnnotationTags(annotationUpdateFlags.Tags)
+		if err != nil {
+			return err
+		}
+		upd.Tags = &tags
+	}
+	if changed("start") {
+		t, err := parseAnnotationTime("start", annotationUpdateFlags.Start)
+		if err != nil {
+			return err
+		}
+		upd.StartTime = &t
+	}
+	if changed("end") {
+		t, err := parseAnnotationTime("end", annotationUpdateFlags.End)
+		if err != nil {
+			return err
+		}
+		upd.EndTime = &t
+	}
+
+	s, err := newAnnotationService()
+	if err != nil {
+		return err
+	}
+	a, err := s.UpdateAnnotation(context.Background(), annotationUpdateFlags.ID, upd)
+	if err != nil {
+		return err
+	}
+	return writeAnnotations(cmd.OutOrStdout(), annotationPrintOpt{
+		jsonOut:     annotationCRUDFlags.json,
+		hideHeaders: annotationCRUDFlags.hideHeaders,
+		annotation:  a,
+	})
+}
+
+var annotationDeleteFlags struct {
+	ID platform.ID
+}
+
+func annotationDeleteCmd(f *globalFlags, opt genericCLIOpts) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delete",
+		Short: "Delete an annotation",
+		RunE:  checkSetupRunEMiddleware(&flags)(annotationDeleteF),
+		Args:  cobra.NoArgs,
+	}
+
+	f.registerFlags(opt.viper, cmd)
+	registerPrintOptions(opt.viper, cmd, &annotationCRUDFlags.hideHeaders, &annotationCRUDFlags.json)
+	cli.IDVar(cmd.Flags(), &annotationDeleteFlags.ID, "id", 0, "The ID of the annotation to delete")
+	_ = cmd.MarkFlagRequired("id")
+
+	return cmd
+}
+
+func annotationDeleteF(cmd *cobra.Command, _ []string) error {
+	s, err := newAnnotationService()
+	if err != nil {
+		return err
+	}
+
+	a, err := s.FindAnnotationByID(context.Background(), annotationDeleteFlags.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.DeleteAnnotation(context.Background(), annotationDeleteFlags.ID); err != nil {
+		return err
+	}
+	return writeAnnotations(cmd.OutOrStdout(), annotationPrintOpt{
+		jsonOut:     annotationCRUDFlags.json,
+		hideHeaders: annotationCRUDFlags.hideHeaders,
+		annotation:  a,
+	})
+}
+
+type annotationPrintOpt struct {
+	jsonOut     bool
+	hideHeaders bool
+	annotation  *annotations.Annotation
+	annotations []*annotations.Annotation
+}
+
+func writeAnnotations(w io.Writer, printOpts annotationPrintOpt) error {
+	if printOpts.jsonOut {
+		var v interface{} = printOpts.annotations
+		if printOpts.annotations == nil {
+			v = printOpts.annotation
+		}
+		return writeJSON(w, v)
+	}
+
+	tabW := internal.NewTabWriter(w)
+	defer tabW.Flush()
+
+	tabW.HideHeaders(printOpts.hideHeaders)
+
+	headers := []string{
+		"ID",
+		"Stream",
+		"Summary",
+		"Start Time",
+		"End Time",
+		"Tags",
+	}
+	tabW.WriteHeaders(headers...)
+
+	if printOpts.annotations == nil && printOpts.annotation != nil {
+		printOpts.annotations = append(printOpts.annotations, printOpts.annotation)
+	}
+
+	for _, a := range printOpts.annotations {
+		tags := make([]string, 0, len(a.Tags))
+		for k, v := range a.Tags {
+			tags = append(tags, k+"="+v)
+		}
+		sort.Strings(tags)
+
+		tabW.Write(map[string]interface{}{
+			"ID":         a.ID.String(),
+			"Stream":     a.Stream,
+			"Summary":    a.Summary,
+			"Start Time": a.StartTime.Format(time.RFC3339Nano),
+			"End Time":   a.EndTime.Format(time.RFC3339Nano),
+			"Tags":       strings.Join(tags, ","),
+		})
+	}
+
+	return nil
+}
+
+// parseAnnotationTags parses tags in the form key=value.
+func parseAnnotationTags(tags []string) (map[string]string, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+	m := make(map[string]string, len(tags))
+	for _, t := range tags {
+		kv := strings.SplitN(t, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("tag %q must be in the form key=value", t)
+		}
+		m[kv[0]] = kv[1]
+	}
+	return m, nil
+}
+
+func parseAnnotationTime(name, s string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s time %q: %v", name, s, err)
+	}
+	return t, nil
+}
+
+func newAnnotationService() (*annotations.Client, error) {
+	httpClient, err := newHTTPClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return annotations.NewClient(httpClient), nil
+}
@@ -82,6 +82,12 @@ var authCreateFlags struct {
 
 	writeDBRPPermission bool
 	readDBRPPermission  bool
+
+	writeAnnotationsPermission bool
+	readAnnotationsPermission  bool
+
+	writeNotebooksPermission bool
+	readNotebooksPermission  bool
 }
 
 func authCreateCmd(f *globalFlags, opt genericCLIOpts) *cobra.Command {
@@ -131,6 +137,12 @@ func authCreateCmd(f *globalFlags, opt genericCLIOpts) *cobra.Command {
 	cmd.Flags().BoolVarP(&authCreateFlags.writeDBRPPermission, "write-dbrps", "", false, "Grants the permission to create database retention policy mappings")
 	cmd.Flags().BoolVarP(&authCreateFlags.readDBRPPermission, "read-dbrps", "", false, "Grants the permission to read database retention policy mappings")
 
+	cmd.Flags().BoolVarP(&authCreateFlags.writeAnnotationsPermission, "write-annotations", "", false, "Grants the permission to create annotations")
+	cmd.Flags().BoolVarP(&authCreateFlags.readAnnotationsPermission, "read-annotations", "", false, "Grants the permission to read annotations")
+
+	cmd.Flags().BoolVarP(&authCreateFlags.writeNotebooksPermission, "write-notebooks", "", false, "Grants the permission to create notebooks")
+	cmd.Flags().BoolVarP(&authCreateFlags.readNotebooksPermission, "read-notebooks", "", false, "Grants the permission to read notebooks")
+
 	return cmd
 }
 
@@ -234,6 +246,16 @@ func authorizationCreateF(cmd *cobra.Command, args []string) error {
 			writePerm:    authCreateFlags.writeDBRPPermission,
 			ResourceType: platform.DBRPResourceType,
 		},
+		{
+			readPerm:     authCreateFlags.readAnnotationsPermission,
+			writePerm:    authCreateFlags.writeAnnotationsPermission,
+			ResourceType: platform.AnnotationsResourceType,
+		},
+		{
+			readPerm:     authCreateFlags.readNotebooksPermission,
+			writePerm:    authCreateFlags.writeNotebooksPermission,
+			ResourceType: platform.NotebooksResourceType,
+		},
 	}
 
 	for _, provided := range providedPerm {
@@ -8,12 +8,15 @@ import (
 
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/cmd/internal"
+	"github.com/influxdata/influxdb/v2/http"
 	"github.com/influxdata/influxdb/v2/tenant"
 	"github.com/spf13/cobra"
 )
 
 type bucketSVCsFn func() (influxdb.BucketService, influxdb.OrganizationService, error)
 
+type storageUsageSVCFn func() (influxdb.StorageUsageService, error)
+
 func cmdBucket(f *globalFlags, opt genericCLIOpts) *cobra.Command {
 	builder := newCmdBucketBuilder(newBucketSVCs, f, opt)
 	return builder.cmd()
@@ -23,7 +26,8 @@ type cmdBucketBuilder struct {
 	genericCLIOpts
 	*globalFlags
 
-	svcFn bucketSVCsFn
+	svcFn      bucketSVCsFn
+	usageSVCFn storageUsageSVCFn
 
 	id                 string
 	hideHeaders        bool
@@ -33,6 +37,17 @@ type cmdBucketBuilder struct {
 	org                organization
 	retention          string
 	shardGroupDuration string
+	maxSeries          int64
+	maxValuesPerTag    int64
+
+	// usage has its own options so its flags do not reset the options of the
+	// other subcommands that share the builder
+	usage struct {
+		id     string
+		name   string
+		org    organization
+		tagKey string
+	}
 }
 
 func newCmdBucketBuilder(svcsFn bucketSVCsFn, f *globalFlags, opts genericCLIOpts) *cmdBucketBuilder {
@@ -40,6 +55,7 @@ func newCmdBucketBuilder(svcsFn bucketSVCsFn, f *globalFlags, opts genericCLIOpt
 		globalFlags:    f,
 		genericCLIOpts: opts,
 		svcFn:          svcsFn,
+		usageSVCFn:     newStorageUsageSVC,
 	}
 }
 
@@ -53,6 +69,7 @@ func (b *cmdBucketBuilder) cmd() *cobra.Command {
 		b.cmdDelete(),
 		b.cmdList(),
 		b.cmdUpdate(),
+		b.cmdUsage(),
 	)
 
 	return cmd
@@ -78,6 +95,10 @@ func (b *cmdBucketBuilder) cmdCreate() *cobra.Command {
 	cmd.Flags().StringVarP(&b.retention, "retention", "r", "", "Duration bucket will retain data. 0 is infinite. Default is 0.")
 	cmd.Flags().StringVarP(&b.shardGroupDuration, "shard-group-duration", "", "",
 		"Shard group duration used internally by the storage engine. Not supported by InfluxDB Cloud.")
+	cmd.Flags().Int64Var(&b.maxSeries, "max-series", 0,
+		"Maximum number of series of the bucket; points of new series beyond it are dropped. 0 is unlimited.")
+	cmd.Flags().Int64Var(&b.maxValuesPerTag, "max-values-per-tag", 0,
+		"Maximum number of values of a tag key of a measurement; points with new values beyond it are dropped. 0 is unlimited.")
 	b.org.register(b.viper, cmd, false)
 	b.registerPrintFlags(cmd)
 
@@ -109,6 +130,8 @@ func (b *cmdBucketBuilder) cmdCreateRunEFn(*cobra.Command, []string) error {
 		Description:        b.description,
 		RetentionPeriod:    dur,
 		ShardGroupDuration: shardGroupDuration,
+		MaxSeries:          b.maxSeries,
+		MaxValuesPerTag:    b.maxValuesPerTag,
 	}
 	bkt.OrgID, err = b.org.getID(orgSVC)
 	if err != nil {
@@ -264,6 +287,8 @@ func (b *cmdBucketBuilder) cmdUpdate() *cobra.Command {
 	cmd.Flags().StringVarP(&b.retention, "retention", "r", "", "New retention duration to set on the bucket. 0 is infinite.")
 	cmd.Flags().StringVarP(&b.shardGroupDuration, "shard-group-duration", "", "",
 		"New shard group duration to set on the bucket. 0 will tell the server to pick a value. Not supported by InfluxDB Cloud.")
+	cmd.Flags().Int64Var(&b.maxSeries, "max-series", 0, "New maximum number of series to set on the bucket. 0 is unlimited.")
+	cmd.Flags().Int64Var(&b.maxValuesPerTag, "max-values-per-tag", 0, "New maximum number of values per tag key to set on the bucket. 0 is unlimited.")
 
 	return cmd
 }
@@ -303,6 +328,13 @@ func (b *cmdBucketBuilder) cmdUpdateRunEFn(cmd *cobra.Command, args []string) er
 		update.ShardGroupDuration = &sgDur
 	}
 
+	if cmd.Flags().Changed("max-series") {
+		update.MaxSeries = &b.maxSeries
+	}
+	if cmd.Flags().Changed("max-values-per-tag") {
+		update.MaxValuesPerTag = &b.maxValuesPerTag
+	}
+
 	bkt, err := bktSVC.UpdateBucket(context.Background(), id, update)
 	if err != nil {
 		return fmt.Errorf("failed to update bucket: %v", err)
@@ -311,6 +343,122 @@ func (b *cmdBucketBuilder) cmdUpdateRunEFn(cmd *cobra.Command, args []string) er
 	return b.printBuckets(bucketPrintOpt{bucket: bkt})
 }
 
+func (b *cmdBucketBuilder) cmdUsage() *cobra.Command {
+	cmd := b.newCmd("usage", b.cmdUsageRunEFn)
+	cmd.Short = "Show the disk space used by the series of a bucket"
+	cmd.Long = `
+Shows the disk space used by the TSM files of the series of a bucket by
+measurement, and by value of a tag key with --tag-key. Points that are not yet
+written to TSM files are not accounted.
+
+Examples:
+	# show the disk space used by each spacecraft
+	influx bucket usage --name telemetry --tag-key spacecraft
+`
+
+	opts := flagOpts{
+		{
+			DestP:  &b.usage.id,
+			Flag:   "id",
+			Short:  'i',
+			EnvVar: "BUCKET_ID",
+			Desc:   "The bucket ID, required if name isn't provided",
+		},
+		{
+			DestP:  &b.usage.name,
+			Flag:   "name",
+			Short:  'n',
+			EnvVar: "BUCKET_NAME",
+			Desc:   "The bucket name, org or org-id will be required by choosing this",
+		},
+		{
+			DestP: &b.usage.tagKey,
+			Flag:  "tag-key",
+			Desc:  "The tag key by which to account the series of each measurement",
+		},
+	}
+	opts.mustRegister(b.viper, cmd)
+
+	b.usage.org.register(b.viper, cmd, false)
+	b.registerPrintFlags(cmd)
+
+	return cmd
+}
+
+func (b *cmdBucketBuilder) cmdUsageRunEFn(cmd *cobra.Command, args []string) error {
+	bktSVC, _, err := b.svcFn()
+	if err != nil {
+		return err
+	}
+	usageSVC, err := b.usageSVCFn()
+	if err != nil {
+		return err
+	}
+
+	var filter influxdb.BucketFilter
+	if b.usage.id == "" && b.usage.name != "" {
+		if err := b.usage.org.validOrgFlags(b.globalFlags); err != nil {
+			return err
+		}
+		filter.Name = &b.usage.name
+		if b.usage.org.id != "" {
+			if filter.OrganizationID, err = platform.IDFromString(b.usage.org.id); err != nil {
+				return err
+			}
+		} else if b.usage.org.name != "" {
+			filter.Org = &b.usage.org.name
+		}
+	} else {
+		if filter.ID, err = platform.IDFromString(b.usage.id); err != nil {
+			return fmt.Errorf("failed to decode bucket id %q: %v", b.usage.id, err)
+		}
+	}
+
+	ctx := context.Background()
+	bkt, err := bktSVC.FindBucket(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("failed to find bucket: %v", err)
+	}
+	usage, err := usageSVC.FindBucketStorageUsage(ctx, bkt.ID, b.usage.tagKey)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve the usage of bucket %q: %v", bkt.Name, err)
+	}
+
+	if b.json {
+		return b.writeJSON(usage)
+	}
+
+	w := b.newTabWriter()
+	defer w.Flush()
+
+	w.HideHeaders(b.hideHeaders)
+
+	headers := []string{"Measurement", "Bytes"}
+	if b.usage.tagKey != "" {
+		headers = []string{"Measurement", "Tag Value", "Bytes"}
+	}
+	w.WriteHeaders(headers...)
+
+	for _, m := range usage.Measurements {
+		if b.usage.tagKey == "" {
+			w.Write(map[string]interface{}{
+				"Measurement": m.Measurement,
+				"Bytes":       m.Bytes,
+			})
+			continue
+		}
+		for _, v := range m.TagValues {
+			w.Write(map[string]interface{}{
+				"Measurement": m.Measurement,
+				"Tag Value":   v.TagValue,
+				"Bytes":       v.Bytes,
+			})
+		}
+	}
+
+	return nil
+}
+
 func (b *cmdBucketBuilder) newCmd(use string, runE func(*cobra.Command, []string) error) *cobra.Command {
 	cmd := b.genericCLIOpts.newCmd(use, runE, true)
 	b.globalFlags.registerFlags(b.viper, cmd)
@@ -389,3 +537,11 @@ func newBucketSVCs() (influxdb.BucketService, influxdb.OrganizationService, erro
 
 	return &tenant.BucketClientService{Client: httpClient}, orgSvc, nil
 }
+
+func newStorageUsageSVC() (influxdb.StorageUsageService, error) {
+	httpClient, err := newHTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	return &http.StorageUsageService{Client: httpClient}, nil
+}
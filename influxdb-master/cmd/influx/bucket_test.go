@@ -472,6 +472,130 @@ func TestCmdBucket(t *testing.T) {
 			t.Run(tt.name, fn)
 		}
 	})
+
+	t.Run("usage", func(t *testing.T) {
+		type called struct {
+			name   string
+			id     platform.ID
+			orgID  platform.ID
+			org    string
+			tagKey string
+		}
+
+		tests := []struct {
+			name     string
+			expected called
+			flags    []string
+			envVars  map[string]string
+			output   string
+		}{
+			{
+				name:     "id",
+				flags:    []string{"--id=" + platform.ID(2).String()},
+				envVars:  envVarsZeroMap,
+				expected: called{id: 2},
+				output:   "mem\t\t100",
+			},
+			{
+				name:     "name and org",
+				flags:    []string{"--name=telemetry", "--org=rg"},
+				envVars:  envVarsZeroMap,
+				expected: called{name: "telemetry", org: "rg"},
+				output:   "mem\t\t100",
+			},
+			{
+				name: "shorts with tag key",
+				flags: []string{
+					"-n=telemetry",
+					"-o=rg",
+					"--tag-key=host",
+				},
+				envVars:  envVarsZeroMap,
+				expected: called{name: "telemetry", org: "rg", tagKey: "host"},
+				output:   "mem\t\tA\t\t60",
+			},
+			{
+				name: "env vars",
+				envVars: map[string]string{
+					"INFLUX_ORG":         "",
+					"INFLUX_ORG_ID":      platform.ID(3).String(),
+					"INFLUX_BUCKET_NAME": "telemetry",
+				},
+				expected: called{name: "telemetry", orgID: 3},
+				output:   "mem\t\t100",
+			},
+		}
+
+		cmdFn := func() (func(*globalFlags, genericCLIOpts) *cobra.Command, *called) {
+			calls := new(called)
+
+			svc := mock.NewBucketService()
+			svc.FindBucketFn = func(ctx context.Context, f influxdb.BucketFilter) (*influxdb.Bucket, error) {
+				if f.ID != nil {
+					calls.id = *f.ID
+				}
+				if f.OrganizationID != nil {
+					calls.orgID = *f.OrganizationID
+				}
+				if f.Name != nil {
+					calls.name = *f.Name
+				}
+				if f.Org != nil {
+					calls.org = *f.Org
+				}
+				return &influxdb.Bucket{ID: 2, Name: "telemetry"}, nil
+			}
+			usageSVC := fakeStorageUsageSVC(func(ctx context.Context, bucketID platform.ID, tagKey string) (*influxdb.BucketStorageUsage, error) {
+				if bucketID != 2 {
+					return nil, fmt.Errorf("unexpected bucket id %s", bucketID)
+				}
+				calls.tagKey = tagKey
+				return &influxdb.BucketStorageUsage{
+					BucketID: bucketID,
+					TagKey:   tagKey,
+					Bytes:    100,
+					Measurements: []*influxdb.MeasurementStorageUsage{{
+						Measurement: "mem",
+						Bytes:       100,
+						TagValues: []*influxdb.TagValueStorageUsage{
+							{TagValue: "A", Bytes: 60},
+							{TagValue: "B", Bytes: 40},
+						},
+					}},
+				}, nil
+			})
+
+			return func(g *globalFlags, opt genericCLIOpts) *cobra.Command {
+				builder := newCmdBucketBuilder(fakeSVCFn(svc), g, opt)
+				builder.usageSVCFn = func() (influxdb.StorageUsageService, error) {
+					return usageSVC, nil
+				}
+				return builder.cmd()
+			}, calls
+		}
+
+		for _, tt := range tests {
+			fn := func(t *testing.T) {
+				defer addEnvVars(t, tt.envVars)()
+
+				outBuf := new(bytes.Buffer)
+				builder := newInfluxCmdBuilder(
+					in(new(bytes.Buffer)),
+					out(outBuf),
+				)
+
+				cmdFn, calls := cmdFn()
+				cmd := builder.cmd(cmdFn)
+				cmd.SetArgs(append([]string{"bucket", "usage"}, tt.flags...))
+
+				require.NoError(t, cmd.Execute())
+				assert.Equal(t, tt.expected, *calls)
+				assert.Contains(t, outBuf.String(), tt.output)
+			}
+
+			t.Run(tt.name, fn)
+		}
+	})
 }
 
 func strPtr(s string) *string {
@@ -506,3 +630,9 @@ func addEnvVars(t *testing.T, envVars map[string]string) func() {
 		}
 	}
 }
+
+type fakeStorageUsageSVC func(ctx context.Context, bucketID platform.ID, tagKey string) (*influxdb.BucketStorageUsage, error)
+
+func (f fakeStorageUsageSVC) FindBucketStorageUsage(ctx context.Context, bucketID platform.ID, tagKey string) (*influxdb.BucketStorageUsage, error) {
+	return f(ctx, bucketID, tagKey)
+}
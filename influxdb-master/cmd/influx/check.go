@@ -0,0 +1,437 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/cmd/influx/internal"
+	"github.com/influxdata/influxdb/v2/http"
+	"github.com/influxdata/influxdb/v2/kit/cli"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/notification"
+	"github.com/influxdata/influxdb/v2/notification/check"
+	"github.com/influxdata/influxdb/v2/task/options"
+	"github.com/spf13/cobra"
+)
+
+func cmdCheck(f *globalFlags, opt genericCLIOpts) *cobra.Command {
+	cmd := opt.newCmd("check", nil, false)
+	cmd.Short = "Check management commands"
+	cmd.Run = seeHelp
+
+	cmd.AddCommand(
+		checkCreateCmd(f, opt),
+		checkFindCmd(f, opt),
+		checkQueryCmd(f, opt),
+		checkDeleteCmd(f, opt),
+	)
+
+	return cmd
+}
+
+var checkCRUDFlags struct {
+	json        bool
+	hideHeaders bool
+}
+
+var checkCreateFlags struct {
+	Org                   organization
+	Name                  string
+	Description           string
+	Query                 string
+	Every                 string
+	Offset                string
+	StatusMessageTemplate string
+	Tags                  []string
+	Status                string
+	Method                string
+	Baseline              string
+	Period                string
+	Thresholds            []string
+}
+
+func checkCreateCmd(f *globalFlags, opt genericCLIOpts) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create an anomaly check",
+		Long: `Create an anomaly check, scoring the latest window of the query against a
+baseline learned from the windows preceding it.
+
+The method learning the baseline is one of:
+	zscore: the mean and standard deviation of the windows of the baseline
+	mad:    the median and median absolute deviation of the windows at the
+	        same point of the previous periods of the baseline
+	period: the window one period earlier
+
+The level of the status is the level of the thresholds exceeded by the
+absolute score.
+
+Examples:
+	# score the altitude against the previous orbits of the last week
+	influx check create \
+		--name perigee \
+		--every 1m \
+		--query 'from(bucket: "orbits") |> range(start: -1m) |> filter(fn: (r) => r._field == "altitude") |> aggregateWindow(every: 1m, fn: mean)' \
+		--method mad \
+		--baseline 7d \
+		--period 92m \
+		--threshold crit=5 \
+		--threshold warn=3.5
+`,
+		RunE: checkSetupRunEMiddleware(&flags)(checkCreateF),
+		Args: cobra.NoArgs,
+	}
+
+	f.registerFlags(opt.viper, cmd)
+	registerPrintOptions(opt.viper, cmd, &checkCRUDFlags.hideHeaders, &checkCRUDFlags.json)
+	checkCreateFlags.Org.register(opt.viper, cmd, false)
+	cmd.Flags().StringVarP(&checkCreateFlags.Name, "name", "n", "", "The name of the check")
+	_ = cmd.MarkFlagRequired("name")
+	cmd.Flags().StringVarP(&checkCreateFlags.Description, "description", "d", "", "The description of the check")
+	cmd.Flags().StringVarP(&checkCreateFlags.Query, "query", "q", "", "The query of the check, filtering a single field")
+	_ = cmd.MarkFlagRequired("query")
+	cmd.Flags().StringVar(&checkCreateFlags.Every, "every", "", "The interval of the check and duration of its windows, exp 1m")
+	_ = cmd.MarkFlagRequired("every")
+	cmd.Flags().StringVar(&checkCreateFlags.Offset, "offset", "", "The delay after the interval before running the check")
+	cmd.Flags().StringVar(&checkCreateFlags.StatusMessageTemplate, "status-message-template", "Check: ${ r._check_name } is: ${ r._level }", "The template of the status messages")
+	cmd.Flags().StringArrayVar(&checkCreateFlags.Tags, "tag", nil, "A tag written to each status in the form key=value; may be repeated")
+	cmd.Flags().StringVar(&checkCreateFlags.Status, "status", string(influxdb.Active), "The status of the check, active or inactive")
+	cmd.Flags().StringVar(&checkCreateFlags.Method, "method", "", "The method learning the baseline, one of zscore, mad or period")
+	_ = cmd.MarkFlagRequired("method")
+	cmd.Flags().StringVar(&checkCreateFlags.Baseline, "baseline", "", "The duration of the windows learned by the zscore and mad methods, exp 7d")
+	cmd.Flags().StringVar(&checkCreateFlags.Period, "period", "", "The duration of a period for the mad and period methods, exp 92m")
+	cmd.Flags().StringArrayVar(&checkCreateFlags.Thresholds, "threshold", nil, "A level and the absolute score it is recorded above in the form level=value; may be repeated")
+	_ = cmd.MarkFlagRequired("threshold")
+
+	return cmd
+}
+
+func checkCreateF(cmd *cobra.Command, _ []string) error {
+	if err := checkCreateFlags.Org.validOrgFlags(&flags); err != nil {
+		return err
+	}
+	orgSvc, err := newOrganizationService()
+	if err != nil {
+		return err
+	}
+	orgID, err := checkCreateFlags.Org.getID(orgSvc)
+	if err != nil {
+		return err
+	}
+
+	status := influxdb.Status(checkCreateFlags.Status)
+	if err := status.Valid(); err != nil {
+		return err
+	}
+
+	chk := &check.Anomaly{
+		Base: check.Base{
+			Name:                  checkCreateFlags.Name,
+			Description:           checkCreateFlags.Description,
+			OrgID:                 orgID,
+			StatusMessageTemplate: checkCreateFlags.StatusMessageTemplate,
+		},
+		Method: strings.ToLower(checkCreateFlags.Method),
+	}
+	chk.Query.Text = checkCreateFlags.Query
+	if chk.Every, err = parseCheckDuration("every", checkCreateFlags.Every); err != nil {
+		return err
+	}
+	if chk.Offset, err = parseCheckDuration("offset", checkCreateFlags.Offset); err != nil {
+		return err
+	}
+	if chk.Baseline, err = parseCheckDuration("baseline", checkCreateFlags.Baseline); err != nil {
+		return err
+	}
+	if chk.Period, err = parseCheckDuration("period", checkCreateFlags.Period); err != nil {
+		return err
+	}
+	tags, err := parseAnnotationTags(checkCreateFlags.Tags)
+	if err != nil {
+		return err
+	}
+	for k, v := range tags {
+		chk.Tags = append(chk.Tags, influxdb.Tag{Key: k, Value: v})
+	}
+	sort.Slice(chk.Tags, func(i, j int) bool { return chk.Tags[i].Key < chk.Tags[j].Key })
+	if chk.Thresholds, err = parseAnomalyThresholds(checkCreateFlags.Thresholds); err != nil {
+		return err
+	}
+
+	// The body of the request is the check with its status, which is not a
+	// field of the check.
+	b, err := json.Marshal(chk)
+	if err != nil {
+		return err
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(b, &body); err != nil {
+		return err
+	}
+	body["status"] = status
+
+	client, err := newHTTPClient()
+	if err != nil {
+		return err
+	}
+	var created http.Check
+	err = client.
+		PostJSON(body, "/api/v2/checks").
+		DecodeJSON(&created).
+		Do(context.Background())
+	if err != nil {
+		return err
+	}
+	return writeChecks(cmd.OutOrStdout(), checkPrintOpt{
+		jsonOut:     checkCRUDFlags.json,
+		hideHeaders: checkCRUDFlags.hideHeaders,
+		check:       &created,
+	})
+}
+
+var checkFindFlags struct {
+	ID    platform.ID
+	Org   organization
+	Name  string
+	Limit int
+}
+
+func checkFindCmd(f *globalFlags, opt genericCLIOpts) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "list",
+		Short:   "List checks",
+		Aliases: []string{"find", "ls"},
+		RunE:    checkSetupRunEMiddleware(&flags)(checkFindF),
+		Args:    cobra.NoArgs,
+	}
+
+	f.registerFlags(opt.viper, cmd)
+	registerPrintOptions(opt.viper, cmd, &checkCRUDFlags.hideHeaders, &checkCRUDFlags.json)
+	checkFindFlags.Org.register(opt.viper, cmd, false)
+	cli.IDVar(cmd.Flags(), &checkFindFlags.ID, "id", 0, "Limit results to a single check")
+	cmd.Flags().StringVarP(&checkFindFlags.Name, "name", "n", "", "Limit results to the checks with the name")
+	cmd.Flags().IntVar(&checkFindFlags.Limit, "limit", 0, "The maximum number of checks to list")
+
+	return cmd
+}
+
+func checkFindF(cmd *cobra.Command, _ []string) error {
+	s, err := newCheckService()
+	if err != nil {
+		return err
+	}
+
+	if checkFindFlags.ID.Valid() {
+		c, err := s.FindCheckByID(context.Background(), checkFindFlags.ID)
+		if err != nil {
+			return err
+		}
+		return writeChecks(cmd.OutOrStdout(), checkPrintOpt{
+			jsonOut:     checkCRUDFlags.json,
+			hideHeaders: checkCRUDFlags.hideHeaders,
+			check:       c,
+		})
+	}
+
+	if err := checkFindFlags.Org.validOrgFlags(&flags); err != nil {
+		return err
+	}
+	orgSvc, err := newOrganizationService()
+	if err != nil {
+		return err
+	}
+	orgID, err := checkFindFlags.Org.getID(orgSvc)
+	if err != nil {
+		return err
+	}
+
+	filter := influxdb.CheckFilter{OrgID: &orgID}
+	if checkFindFlags.Name != "" {
+		filter.Name = &checkFindFlags.Name
+	}
+	cs, _, err := s.FindChecks(context.Background(), filter, influxdb.FindOptions{Limit: checkFindFlags.Limit})
+	if err != nil {
+		return err
+	}
+	return writeChecks(cmd.OutOrStdout(), checkPrintOpt{
+		jsonOut:     checkCRUDFlags.json,
+		hideHeaders: checkCRUDFlags.hideHeaders,
+		checks:      cs,
+	})
+}
+
+var checkQueryFlags struct {
+	ID platform.ID
+}
+
+func checkQueryCmd(f *globalFlags, opt genericCLIOpts) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "query",
+		Short: "Print the flux script generated for a check",
+		RunE:  checkSetupRunEMiddleware(&flags)(checkQueryF),
+		Args:  cobra.NoArgs,
+	}
+
+	f.registerFlags(opt.viper, cmd)
+	cli.IDVar(cmd.Flags(), &checkQueryFlags.ID, "id", 0, "The ID of the check")
+	_ = cmd.MarkFlagRequired("id")
+
+	return cmd
+}
+
+func checkQueryF(cmd *cobra.Command, _ []string) error {
+	client, err := newHTTPClient()
+	if err != nil {
+		return err
+	}
+
+	var resp struct {
+		Flux string `json:"flux"`
+	}
+	err = client.
+		Get("/api/v2/checks", checkQueryFlags.ID.String(), "query").
+		DecodeJSON(&resp).
+		Do(context.Background())
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(cmd.OutOrStdout(), resp.Flux)
+	return err
+}
+
+var checkDeleteFlags struct {
+	ID platform.ID
+}
+
+func checkDeleteCmd(f *globalFlags, opt genericCLIOpts) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delete",
+		Short: "Delete a check",
+		RunE:  checkSetupRunEMiddleware(&flags)(checkDeleteF),
+		Args:  cobra.NoArgs,
+	}
+
+	f.registerFlags(opt.viper, cmd)
+	registerPrintOptions(opt.viper, cmd, &checkCRUDFlags.hideHeaders, &checkCRUDFlags.json)
+	cli.IDVar(cmd.Flags(), &checkDeleteFlags.ID, "id", 0, "The ID of the check to delete")
+	_ = cmd.MarkFlagRequired("id")
+
+	return cmd
+}
+
+func checkDeleteF(cmd *cobra.Command, _ []string) error {
+	s, err := newCheckService()
+	if err != nil {
+		return err
+	}
+
+	c, err := s.FindCheckByID(context.Background(), checkDeleteFlags.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.DeleteCheck(context.Background(), checkDeleteFlags.ID); err != nil {
+		return err
+	}
+	return writeChecks(cmd.OutOrStdout(), checkPrintOpt{
+		jsonOut:     checkCRUDFlags.json,
+		hideHeaders: checkCRUDFlags.hideHeaders,
+		check:       c,
+	})
+}
+
+type checkPrintOpt struct {
+	jsonOut     bool
+	hideHeaders bool
+	check       *http.Check
+	checks      []*http.Check
+}
+
+func writeChecks(w io.Writer, printOpts checkPrintOpt) error {
+	if printOpts.jsonOut {
+		var v interface{} = printOpts.checks
+		if printOpts.checks == nil {
+			v = printOpts.check
+		}
+		return writeJSON(w, v)
+	}
+
+	tabW := internal.NewTabWriter(w)
+	defer tabW.Flush()
+
+	tabW.HideHeaders(printOpts.hideHeaders)
+
+	headers := []string{
+		"ID",
+		"Name",
+		"Type",
+		"Status",
+		"Every",
+		"Method",
+	}
+	tabW.WriteHeaders(headers...)
+
+	if printOpts.checks == nil && printOpts.check != nil {
+		printOpts.checks = append(printOpts.checks, printOpts.check)
+	}
+
+	for _, c := range printOpts.checks {
+		tabW.Write(map[string]interface{}{
+			"ID":     c.ID.String(),
+			"Name":   c.Name,
+			"Type":   c.Type,
+			"Status": c.Status,
+			"Every":  c.Every,
+			"Method": c.Method,
+		})
+	}
+
+	return nil
+}
+
+// parseCheckDuration parses a flux duration, returning nil for an empty one.
+func parseCheckDuration(name, s string) (*notification.Duration, error) {
+	if s == "" {
+		return nil, nil
+	}
+	d, err := options.ParseSignedDuration(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s duration %q: %v", name, s, err)
+	}
+	return (*notification.Duration)(d), nil
+}
+
+// parseAnomalyThresholds parses thresholds in the form level=value.
+func parseAnomalyThresholds(thresholds []string) ([]check.AnomalyThreshold, error) {
+	var out []check.AnomalyThreshold
+	for _, t := range thresholds {
+		kv := strings.SplitN(t, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("threshold %q must be in the form level=value", t)
+		}
+		level := notification.ParseCheckLevel(strings.ToUpper(kv[0]))
+		if level == notification.Unknown {
+			return nil, fmt.Errorf("threshold %q must have a level of crit, warn, info or ok", t)
+		}
+		value, err := strconv.ParseFloat(kv[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("threshold %q must have a numeric value: %v", t, err)
+		}
+		out = append(out, check.AnomalyThreshold{Level: level, Value: value})
+	}
+	return out, nil
+}
+
+func newCheckService() (*http.CheckService, error) {
+	httpClient, err := newHTTPClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.CheckService{Client: httpClient}, nil
+}
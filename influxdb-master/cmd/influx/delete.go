@@ -3,7 +3,10 @@ package main
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 
+	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/http"
 	"github.com/influxdata/influxdb/v2/kit/signals"
 	"github.com/spf13/cobra"
@@ -21,7 +24,8 @@ type cmdDeleteBuilder struct {
 	genericCLIOpts
 	*globalFlags
 
-	flags http.DeleteRequest
+	flags  http.DeleteRequest
+	dryRun bool
 }
 
 func (b *cmdDeleteBuilder) cmd() *cobra.Command {
@@ -59,10 +63,12 @@ func (b *cmdDeleteBuilder) cmd() *cobra.Command {
 		},
 	}
 	opts.mustRegister(b.viper, cmd)
+	b.genericCLIOpts.registerPrintOptions(cmd)
 
 	cmd.PersistentFlags().StringVar(&b.flags.Start, "start", "", "the start time in RFC3339Nano format, exp 2009-01-02T23:00:00Z")
 	cmd.PersistentFlags().StringVar(&b.flags.Stop, "stop", "", "the stop time in RFC3339Nano format, exp 2009-01-02T23:00:00Z")
 	cmd.PersistentFlags().StringVarP(&b.flags.Predicate, "predicate", "p", "", "sql like predicate string, exp 'tag1=\"v1\" and (tag2=123)'")
+	cmd.PersistentFlags().BoolVar(&b.dryRun, "dry-run", false, "list the series of which points would be deleted, without deleting them")
 
 	return cmd
 }
@@ -93,6 +99,17 @@ func (b *cmdDeleteBuilder) fluxDeleteF(cmd *cobra.Command, args []string) error
 	}
 
 	ctx := signals.WithStandardSignals(context.Background())
+	if b.dryRun {
+		res, err := s.DeleteBucketRangePredicateDryRun(ctx, b.flags)
+		if err != nil {
+			if err == context.Canceled {
+				return nil
+			}
+			return fmt.Errorf("failed to find data to delete: %v", err)
+		}
+		return b.printDryRun(res)
+	}
+
 	if err := s.DeleteBucketRangePredicate(ctx, b.flags); err != nil && err != context.Canceled {
 		return fmt.Errorf("failed to delete data: %v", err)
 	}
@@ -100,6 +117,37 @@ func (b *cmdDeleteBuilder) fluxDeleteF(cmd *cobra.Command, args []string) error
 	return nil
 }
 
+func (b *cmdDeleteBuilder) printDryRun(res *influxdb.DeleteDryRun) error {
+	if b.json {
+		return b.writeJSON(res)
+	}
+
+	tw := b.newTabWriter()
+	tw.WriteHeaders("Measurement", "Tags", "Fields")
+	for _, s := range res.Series {
+		keys := make([]string, 0, len(s.Tags))
+		for k := range s.Tags {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		tags := make([]string, 0, len(keys))
+		for _, k := range keys {
+			tags = append(tags, k+"="+s.Tags[k])
+		}
+		tw.Write(map[string]interface{}{
+			"Measurement": s.Measurement,
+			"Tags":        strings.Join(tags, ","),
+			"Fields":      strings.Join(s.Fields, ","),
+		})
+	}
+	tw.Flush()
+
+	if res.SeriesN > len(res.Series) {
+		fmt.Fprintf(b.w, "%d of %d series listed\n", len(res.Series), res.SeriesN)
+	}
+	return nil
+}
+
 func (b *cmdDeleteBuilder) newCmd(use string, runE func(*cobra.Command, []string) error) *cobra.Command {
 	cmd := b.genericCLIOpts.newCmd(use, runE, true)
 	b.globalFlags.registerFlags(b.viper, cmd)
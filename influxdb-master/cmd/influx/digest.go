@@ -0,0 +1,381 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/backup"
+	"github.com/influxdata/influxdb/v2/http"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/tsdb/engine/tsm1"
+	"github.com/spf13/cobra"
+)
+
+func cmdDigest(f *globalFlags, opts genericCLIOpts) *cobra.Command {
+	return newCmdDigestBuilder(f, opts).cmd()
+}
+
+type cmdDigestBuilder struct {
+	genericCLIOpts
+	*globalFlags
+
+	bucketID       string
+	sourceBucketID string
+	sourceHost     string
+	sourceToken    string
+	sourceBackup   string
+	targetBackup   string
+	window         time.Duration
+	start          string
+	stop           string
+	exportPath     string
+	repair         bool
+}
+
+func newCmdDigestBuilder(f *globalFlags, opts genericCLIOpts) *cmdDigestBuilder {
+	return &cmdDigestBuilder{
+		genericCLIOpts: opts,
+		globalFlags:    f,
+	}
+}
+
+func (b *cmdDigestBuilder) cmd() *cobra.Command {
+	cmd := b.genericCLIOpts.newCmd("digest", nil, false)
+	cmd.Short = "Compare the data of two copies of a bucket"
+	cmd.Run = seeHelp
+	cmd.AddCommand(b.cmdDiff())
+	return cmd
+}
+
+func (b *cmdDigestBuilder) cmdDiff() *cobra.Command {
+	cmd := b.genericCLIOpts.newCmd("diff", b.diffRunE, true)
+	b.genericCLIOpts.registerPrintOptions(cmd)
+	b.globalFlags.registerFlags(b.viper, cmd)
+
+	cmd.Short = "Report the series and time ranges that differ between two copies of a bucket"
+	cmd.Long = `
+Computes digests of the points of a bucket on a source and a target, and
+reports the series fields and time ranges whose points differ. Points are
+digested by time window, so a reported range spans whole windows.
+
+The target is the configured InfluxDB instance, or a backup directory with
+--target-backup. The source is another instance, with --source-host and
+--source-token, or a backup directory with --source-backup.
+
+With --export, the points of the source in the ranges that differ are written
+as line protocol to a file. Writing the file to the target does not remove the
+points of the target in these ranges that are not in the source or that have
+other values, so the ranges of the target with points are reported.
+
+With --repair, the points of the target in the ranges that differ are deleted
+and the points of the source in these ranges are written to the target, after
+which the digests of the source and the target match. The target must be an
+instance, not a backup.
+
+Examples:
+	# compare a bucket with its replica on a ground station
+	influx digest diff --bucket-id 0123456789abcdef --host http://ground-station:8086 \
+		--source-host http://central:8086 --source-token $CENTRAL_TOKEN \
+		--source-bucket-id fedcba9876543210 --repair
+
+	# compare two backups of a bucket
+	influx digest diff --bucket-id 0123456789abcdef \
+		--source-backup /backups/central --target-backup /backups/ground-station
+`
+
+	cmd.Flags().StringVar(&b.bucketID, "bucket-id", "", "The ID of the bucket of the target")
+	cmd.MarkFlagRequired("bucket-id")
+	cmd.Flags().StringVar(&b.sourceBucketID, "source-bucket-id", "", "The ID of the bucket of the source, if it differs from --bucket-id")
+	cmd.Flags().StringVar(&b.sourceHost, "source-host", "", "The HTTP address of the source instance")
+	cmd.Flags().StringVar(&b.sourceToken, "source-token", "", "The token of the source instance")
+	cmd.Flags().StringVar(&b.sourceBackup, "source-backup", "", "A backup directory to use as the source")
+	cmd.Flags().StringVar(&b.targetBackup, "target-backup", "", "A backup directory to use as the target")
+	cmd.Flags().DurationVar(&b.window, "window", time.Hour, "The duration of the time windows of the digests")
+	cmd.Flags().StringVar(&b.start, "start", "", "The start time in RFC3339Nano format, exp 2009-01-02T23:00:00Z")
+	cmd.Flags().StringVar(&b.stop, "stop", "", "The stop time in RFC3339Nano format, exp 2009-01-02T23:00:00Z")
+	cmd.Flags().StringVar(&b.exportPath, "export", "", "Write the points of the source in the ranges that differ to this file as line protocol")
+	cmd.Flags().BoolVar(&b.repair, "repair", false, "Replace the points of the target in the ranges that differ by the points of the source")
+
+	return cmd
+}
+
+func (b *cmdDigestBuilder) diffRunE(cmd *cobra.Command, _ []string) error {
+	ctx := context.Background()
+
+	targetBucketID, err := platform.IDFromString(b.bucketID)
+	if err != nil {
+		return fmt.Errorf("invalid bucket ID %q: %w", b.bucketID, err)
+	}
+	sourceBucketID := targetBucketID
+	if b.sourceBucketID != "" {
+		if sourceBucketID, err = platform.IDFromString(b.sourceBucketID); err != nil {
+			return fmt.Errorf("invalid source bucket ID %q: %w", b.sourceBucketID, err)
+		}
+	}
+
+	if b.repair && b.targetBackup != "" {
+		return fmt.Errorf("--repair requires a target instance, not --target-backup")
+	}
+
+	opts := influxdb.DigestOptions{Start: math.MinInt64, Stop: math.MaxInt64, Window: b.window}
+	if opts.Window <= 0 {
+		return fmt.Errorf("--window must be positive")
+	}
+	for _, t := range []struct {
+		flag, value string
+		ts          *int64
+	}{{"start", b.start, &opts.Start}, {"stop", b.stop, &opts.Stop}} {
+		if t.value == "" {
+			continue
+		}
+		v, err := time.Parse(time.RFC3339Nano, t.value)
+		if err != nil {
+			return fmt.Errorf("invalid --%s: %w", t.flag, err)
+		}
+		*t.ts = v.UnixNano()
+	}
+
+	source, err := b.sourceService()
+	if err != nil {
+		return err
+	}
+	target, err := b.targetService()
+	if err != nil {
+		return err
+	}
+
+	sourceDigest, err := fetchDigest(ctx, source, *sourceBucketID, opts)
+	if err != nil {
+		return fmt.Errorf("failed to compute the digest of the source: %w", err)
+	}
+	defer sourceDigest.Close()
+	targetDigest, err := fetchDigest(ctx, target, *targetBucketID, opts)
+	if err != nil {
+		return fmt.Errorf("failed to compute the digest of the target: %w", err)
+	}
+	defer targetDigest.Close()
+
+	var diffs []tsm1.DigestDiff
+	if err := tsm1.DiffWindowDigests(sourceDigest, targetDigest, func(d tsm1.DigestDiff) error {
+		diffs = append(diffs, d)
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to compare digests: %w", err)
+	}
+
+	if b.exportPath != "" || b.repair {
+		exportPath := b.exportPath
+		if exportPath == "" {
+			f, err := ioutil.TempFile("", "influx_digest_export")
+			if err != nil {
+				return err
+			}
+			f.Close()
+			exportPath = f.Name()
+			defer os.Remove(exportPath)
+		}
+
+		if err := exportDigestDiffs(ctx, source, *sourceBucketID, diffs, exportPath); err != nil {
+			return fmt.Errorf("failed to export the ranges that differ: %w", err)
+		}
+		if b.repair {
+			if err := b.repairTarget(ctx, target, *targetBucketID, diffs, exportPath); err != nil {
+				return fmt.Errorf("failed to repair the target: %w", err)
+			}
+		} else if n := countTargetDiffs(diffs); n > 0 {
+			fmt.Fprintf(b.errW, "%d of the ranges that differ have points in the target that the export does not replace, use --repair to delete them\n", n)
+		}
+	}
+
+	return b.printDiffs(diffs)
+}
+
+func (b *cmdDigestBuilder) sourceService() (influxdb.DigestService, error) {
+	if b.sourceBackup != "" {
+		return backup.NewDigestService(b.sourceBackup)
+	}
+	if b.sourceHost == "" {
+		return nil, fmt.Errorf("please specify one of --source-host or --source-backup")
+	}
+	return &http.DigestService{
+		Addr:               b.sourceHost,
+		Token:              b.sourceToken,
+		InsecureSkipVerify: b.skipVerify,
+	}, nil
+}
+
+func (b *cmdDigestBuilder) targetService() (influxdb.DigestService, error) {
+	if b.targetBackup != "" {
+		return backup.NewDigestService(b.targetBackup)
+	}
+	ac := b.config()
+	return &http.DigestService{
+		Addr:               ac.Host,
+		Token:              ac.Token,
+		InsecureSkipVerify: b.skipVerify,
+	}, nil
+}
+
+// fetchDigest spools the digest of a bucket to a temporary file and returns a
+// reader of it, which removes the file when it is closed.
+func fetchDigest(ctx context.Context, svc influxdb.DigestService, bucketID platform.ID, opts influxdb.DigestOptions) (*tsm1.DigestReader, error) {
+	f, err := ioutil.TempFile("", "influx_digest")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := svc.BucketDigest(ctx, bucketID, opts, f); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, err
+	}
+	return tsm1.NewDigestReader(removeOnClose{f})
+}
+
+// removeOnClose removes a temporary file when it is closed.
+type removeOnClose struct {
+	*os.File
+}
+
+func (f removeOnClose) Close() error {
+	err := f.File.Close()
+	if rerr := os.Remove(f.Name()); err == nil {
+		err = rerr
+	}
+	return err
+}
+
+// exportDigestDiffs writes the points of the source in the ranges of diffs to
+// a file at path as line protocol.
+func exportDigestDiffs(ctx context.Context, source influxdb.DigestService, bucketID platform.ID, diffs []tsm1.DigestDiff, path string) error {
+	var ranges []influxdb.DigestRange
+	for _, d := range diffs {
+		if d.SourceN > 0 {
+			ranges = append(ranges, digestRange(d))
+		}
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if len(ranges) > 0 {
+		if err := source.ExportDigestRanges(ctx, bucketID, ranges, f); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return f.Close()
+}
+
+// repairTarget deletes the points of the target in the ranges of diffs and
+// writes the points of the source exported to the file at path to it.
+func (b *cmdDigestBuilder) repairTarget(ctx context.Context, target influxdb.DigestService, bucketID platform.ID, diffs []tsm1.DigestDiff, path string) error {
+	if len(diffs) == 0 {
+		return nil
+	}
+
+	bucketSvc, _, err := newBucketSVCs()
+	if err != nil {
+		return err
+	}
+	bkt, err := bucketSvc.FindBucketByID(ctx, bucketID)
+	if err != nil {
+		return err
+	}
+
+	ranges := make([]influxdb.DigestRange, 0, len(diffs))
+	for _, d := range diffs {
+		ranges = append(ranges, digestRange(d))
+	}
+	if err := target.DeleteDigestRanges(ctx, bucketID, ranges); err != nil {
+		return err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if fi, err := f.Stat(); err != nil || fi.Size() == 0 {
+		return err
+	}
+
+	ac := b.config()
+	w := &http.WriteService{
+		Addr:               ac.Host,
+		Token:              ac.Token,
+		InsecureSkipVerify: b.skipVerify,
+	}
+	return w.WriteTo(ctx, influxdb.BucketFilter{ID: &bucketID, OrganizationID: &bkt.OrgID}, f)
+}
+
+// countTargetDiffs returns the number of ranges of diffs with points in the
+// target, which are not replaced by writing the points of the source.
+func countTargetDiffs(diffs []tsm1.DigestDiff) int {
+	var n int
+	for _, d := range diffs {
+		if d.TargetN > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+func digestRange(d tsm1.DigestDiff) influxdb.DigestRange {
+	return influxdb.DigestRange{Key: d.Key, Start: d.Min, Stop: d.Max}
+}
+
+func (b *cmdDigestBuilder) printDiffs(diffs []tsm1.DigestDiff) error {
+	if b.json {
+		type diffJSON struct {
+			Series       string    `json:"series"`
+			Field        string    `json:"field"`
+			Start        time.Time `json:"start"`
+			Stop         time.Time `json:"stop"`
+			SourcePoints int       `json:"sourcePoints"`
+			TargetPoints int       `json:"targetPoints"`
+		}
+		out := make([]diffJSON, 0, len(diffs))
+		for _, d := range diffs {
+			series, field := tsm1.SeriesAndFieldFromCompositeKey([]byte(d.Key))
+			out = append(out, diffJSON{
+				Series:       string(series),
+				Field:        string(field),
+				Start:        time.Unix(0, d.Min).UTC(),
+				Stop:         time.Unix(0, d.Max).UTC(),
+				SourcePoints: d.SourceN,
+				TargetPoints: d.TargetN,
+			})
+		}
+		return b.writeJSON(out)
+	}
+
+	w := b.newTabWriter()
+	defer w.Flush()
+
+	w.WriteHeaders("Series", "Field", "Start", "Stop", "Source Points", "Target Points")
+	for _, d := range diffs {
+		series, field := tsm1.SeriesAndFieldFromCompositeKey([]byte(d.Key))
+		w.Write(map[string]interface{}{
+			"Series":        string(series),
+			"Field":         string(field),
+			"Start":         time.Unix(0, d.Min).UTC().Format(time.RFC3339Nano),
+			"Stop":          time.Unix(0, d.Max).UTC().Format(time.RFC3339Nano),
+			"Source Points": d.SourceN,
+			"Target Points": d.TargetN,
+		})
+	}
+	return nil
+}
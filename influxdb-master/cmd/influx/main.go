@@ -324,12 +324,15 @@ func cmdVersion() *cobra.Command {
 func influxCmd(opts ...genericCLIOptFn) *cobra.Command {
 	builder := newInfluxCmdBuilder(opts...)
 	return builder.cmd(
+		cmdAnnotation,
 		cmdAuth,
 		cmdBackup,
 		cmdBucket,
+		cmdCheck,
 		cmdConfig,
 		cmdDashboard,
 		cmdDelete,
+		cmdDigest,
 		cmdExport,
 		cmdOrganization,
 		cmdPing,
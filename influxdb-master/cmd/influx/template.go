@@ -8,13 +8,16 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net/http"
 	"net/url"
 	"os"
+	"os/signal"
 	"path"
 	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/influxdata/influxdb/v2/kit/platform"
@@ -24,11 +27,13 @@ import (
 	"github.com/influxdata/influxdb/v2/cmd/influx/internal"
 	internal2 "github.com/influxdata/influxdb/v2/cmd/internal"
 	ierror "github.com/influxdata/influxdb/v2/kit/errors"
+	"github.com/influxdata/influxdb/v2/kit/prom"
 	"github.com/influxdata/influxdb/v2/pkger"
 	"github.com/influxdata/influxdb/v2/tenant"
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 	input "github.com/tcnksm/go-input"
+	"go.uber.org/zap"
 )
 
 type templateSVCsFn func() (pkger.SVC, influxdb.OrganizationService, error)
@@ -77,6 +82,7 @@ type cmdTemplateBuilder struct {
 	applyOpts struct {
 		envRefs []string
 		force   string
+		params  []string
 		secrets []string
 	}
 
@@ -91,6 +97,9 @@ type cmdTemplateBuilder struct {
 		tasks          string
 		telegrafs      string
 		variables      string
+		dbrps          string
+		scrapers       string
+		v1Auths        string
 		bucketNames    string
 		checkNames     string
 		dashboardNames string
@@ -100,11 +109,19 @@ type cmdTemplateBuilder struct {
 		taskNames      string
 		telegrafNames  string
 		variableNames  string
+		scraperNames   string
 	}
 
 	updateStackOpts struct {
 		addResources []string
 	}
+
+	reconcileOpts struct {
+		dryRun      bool
+		exitCode    bool
+		interval    time.Duration
+		metricsAddr string
+	}
 }
 
 func newCmdPkgerBuilder(svcFn templateSVCsFn, f *globalFlags, opts genericCLIOpts) *cmdTemplateBuilder {
@@ -167,6 +184,14 @@ func (b *cmdTemplateBuilder) cmdApply() *cobra.Command {
 			--filter kind=Bucket \
 			--filter resource=Dashboard:$DASHBOARD_TMPL_NAME
 
+		# Applying a template with values for the parameters it declares.
+		# List parameters take a comma separated list of values.
+		influx apply \
+			-f $PATH_TO_TEMPLATE/template.yml \
+			--param spacecraft-id=sat-7 \
+			--param retention=720h \
+			--param channels=pos_eci_x,pos_eci_y,pos_eci_z
+
 	For information about finding and using InfluxDB templates, see
 	https://docs.influxdata.com/influxdb/latest/reference/cli/influx/apply/.
 
@@ -184,6 +209,7 @@ func (b *cmdTemplateBuilder) cmdApply() *cobra.Command {
 	b.applyOpts.secrets = []string{}
 	cmd.Flags().StringSliceVar(&b.applyOpts.secrets, "secret", nil, "Secrets to provide alongside the template; format should --secret=SECRET_KEY=SECRET_VALUE --secret=SECRET_KEY_2=SECRET_VALUE_2")
 	cmd.Flags().StringSliceVar(&b.applyOpts.envRefs, "env-ref", nil, "Environment references to provide alongside the template; format should --env-ref=REF_KEY=REF_VALUE --env-ref=REF_KEY_2=REF_VALUE_2")
+	cmd.Flags().StringArrayVar(&b.applyOpts.params, "param", nil, "Parameter values to provide alongside the template; format should --param=PARAM_NAME=PARAM_VALUE --param=PARAM_NAME_2=PARAM_VALUE_2")
 	cmd.Flags().StringSliceVar(&b.filters, "filter", nil, "Resources to skip when applying the template. Filter out by ‘kind’ or by ‘resource’")
 
 	return cmd
@@ -218,6 +244,23 @@ func (b *cmdTemplateBuilder) applyRunEFn(cmd *cobra.Command, args []string) erro
 		}
 	}
 
+	providedParams := make(map[string]string)
+	for _, pair := range b.applyOpts.params {
+		pieces := strings.SplitN(pair, "=", 2)
+		if len(pieces) < 2 {
+			return fmt.Errorf("invalid param provided: %q; expected format --param=PARAM_NAME=PARAM_VALUE", pair)
+		}
+		providedParams[pieces[0]] = pieces[1]
+	}
+	if !isTTY {
+		for _, param := range template.Summary().MissingParams {
+			if _, ok := providedParams[param]; ok {
+				continue
+			}
+			providedParams[param] = b.getInput("Please provide value for parameter "+param, "")
+		}
+	}
+
 	var stackID platform.ID
 	if b.stackID != "" {
 		if err := stackID.DecodeFromString(b.stackID); err != nil {
@@ -228,6 +271,7 @@ func (b *cmdTemplateBuilder) applyRunEFn(cmd *cobra.Command, args []string) erro
 	opts := []pkger.ApplyOptFn{
 		pkger.ApplyWithTemplate(template),
 		pkger.ApplyWithEnvRefs(toMapInterface(providedEnvRefs)),
+		pkger.ApplyWithParams(toMapInterface(providedParams)),
 		pkger.ApplyWithStackID(stackID),
 	}
 
@@ -364,6 +408,9 @@ func (b *cmdTemplateBuilder) cmdExport() *cobra.Command {
 	cmd.Flags().StringVar(&b.exportOpts.tasks, "tasks", "", "List of task ids comma separated")
 	cmd.Flags().StringVar(&b.exportOpts.telegrafs, "telegraf-configs", "", "List of telegraf config ids comma separated")
 	cmd.Flags().StringVar(&b.exportOpts.variables, "variables", "", "List of variable ids comma separated")
+	cmd.Flags().StringVar(&b.exportOpts.dbrps, "dbrps", "", "List of dbrp mapping ids comma separated")
+	cmd.Flags().StringVar(&b.exportOpts.scrapers, "scrapers", "", "List of scraper ids comma separated")
+	cmd.Flags().StringVar(&b.exportOpts.v1Auths, "v1-authorizations", "", "List of v1 authorization ids comma separated")
 	cmd.Flags().StringVar(&b.exportOpts.bucketNames, "bucket-names", "", "List of bucket names comma separated")
 	cmd.Flags().StringVar(&b.exportOpts.checkNames, "check-names", "", "List of check names comma separated")
 	cmd.Flags().StringVar(&b.exportOpts.dashboardNames, "dashboard-names", "", "List of dashboard names comma separated")
@@ -373,6 +420,7 @@ func (b *cmdTemplateBuilder) cmdExport() *cobra.Command {
 	cmd.Flags().StringVar(&b.exportOpts.taskNames, "task-names", "", "List of task names comma separated")
 	cmd.Flags().StringVar(&b.exportOpts.telegrafNames, "telegraf-config-names", "", "List of telegraf config names comma separated")
 	cmd.Flags().StringVar(&b.exportOpts.variableNames, "variable-names", "", "List of variable names comma separated")
+	cmd.Flags().StringVar(&b.exportOpts.scraperNames, "scraper-names", "", "List of scraper names comma separated")
 
 	return cmd
 }
@@ -397,6 +445,9 @@ func (b *cmdTemplateBuilder) exportRunEFn(cmd *cobra.Command, args []string) err
 		{kind: pkger.KindTask, idStrs: strings.Split(b.exportOpts.tasks, ","), names: strings.Split(b.exportOpts.taskNames, ",")},
 		{kind: pkger.KindTelegraf, idStrs: strings.Split(b.exportOpts.telegrafs, ","), names: strings.Split(b.exportOpts.telegrafNames, ",")},
 		{kind: pkger.KindVariable, idStrs: strings.Split(b.exportOpts.variables, ","), names: strings.Split(b.exportOpts.variableNames, ",")},
+		{kind: pkger.KindDBRPMapping, idStrs: strings.Split(b.exportOpts.dbrps, ",")},
+		{kind: pkger.KindScraper, idStrs: strings.Split(b.exportOpts.scrapers, ","), names: strings.Split(b.exportOpts.scraperNames, ",")},
+		{kind: pkger.KindV1Authorization, idStrs: strings.Split(b.exportOpts.v1Auths, ",")},
 	}
 
 	var opts []pkger.ExportOptFn
@@ -644,7 +695,9 @@ func (b *cmdTemplateBuilder) cmdStacks() *cobra.Command {
 `
 
 	cmd.AddCommand(
+		b.cmdStackDrift(),
 		b.cmdStackInit(),
+		b.cmdStackReconcile(),
 		b.cmdStackRemove(),
 		b.cmdStackUpdate(),
 	)
@@ -963,6 +1016,227 @@ these updates. Export a new template with these updates to prevent accidental ch
 	return b.exportTemplate(cmd.OutOrStdout(), templateSVC, b.file, pkger.ExportWithStackID(*stackID))
 }
 
+func (b *cmdTemplateBuilder) cmdStackDrift() *cobra.Command {
+	cmd := b.newCmd("drift", b.stackDriftRunEFn)
+	cmd.Short = "Report resources that have drifted from a stack's template(s)"
+	cmd.Long = `
+	The stack drift command compares the live resources of a stack against the
+	template(s) last applied to it and reports every resource that was modified,
+	deleted, or otherwise changed outside of the template(s). The template urls
+	associated with the stack are always included. Templates applied from local
+	files must be provided via the --file flag.
+
+	Examples:
+		# Report drift of a stack with associated template urls
+		influx stacks drift --stack-id $STACK_ID
+
+		# Report drift of a stack applied from a local template
+		influx stacks drift --stack-id $STACK_ID -f $PATH_TO_TEMPLATE/template.yml
+
+		# Fail when the stack has drifted, useful in CI pipelines
+		influx stacks drift --stack-id $STACK_ID --exit-code
+
+	For information about how stacks work with InfluxDB templates, see
+	https://docs.influxdata.com/influxdb/latest/reference/cli/influx/stacks/
+`
+
+	b.registerStackReconcileFlags(cmd)
+	cmd.Flags().BoolVar(&b.reconcileOpts.exitCode, "exit-code", false, "Exit with a non-zero status when the stack has drifted")
+	registerPrintOptions(b.viper, cmd, &b.hideHeaders, &b.json)
+
+	return cmd
+}
+
+func (b *cmdTemplateBuilder) stackDriftRunEFn(cmd *cobra.Command, args []string) error {
+	b.reconcileOpts.dryRun = true
+	reconciler, err := b.newStackReconciler()
+	if err != nil {
+		return err
+	}
+
+	event, err := reconciler.Reconcile(context.Background())
+	if err != nil {
+		return err
+	}
+
+	if b.json {
+		if err := b.writeJSON(event.Report); err != nil {
+			return err
+		}
+	} else {
+		tabW := b.newTabWriter()
+		tabW.HideHeaders(b.hideHeaders)
+		writeDriftRows(tabW, event.Report)
+		tabW.Flush()
+	}
+
+	if b.reconcileOpts.exitCode && event.Report.HasDrift() {
+		return fmt.Errorf("stack %s has %d drifted resource(s)", event.Report.StackID, len(event.Report.Resources))
+	}
+	return nil
+}
+
+func (b *cmdTemplateBuilder) cmdStackReconcile() *cobra.Command {
+	cmd := b.newCmd("reconcile", b.stackReconcileRunEFn)
+	cmd.Short = "Continuously reapply a stack's template(s) when the stack drifts"
+	cmd.Long = `
+	The stack reconcile command periodically compares the live resources of a
+	stack against its template(s), and reapplies the template(s) whenever a
+	resource has drifted. Templates provided via the --file flag are read anew
+	on every reconciliation, so changes pulled into a local checkout are applied
+	as well. Every reconciliation is printed as an event, and metrics can be
+	exposed for scraping via the --metrics-addr flag.
+
+	Examples:
+		# Reconcile a stack every 5 minutes against its associated template urls
+		influx stacks reconcile --stack-id $STACK_ID
+
+		# Reconcile a stack every minute against a local template
+		influx stacks reconcile --stack-id $STACK_ID -f $PATH_TO_TEMPLATE/template.yml --interval 1m
+
+		# Only report drift, never apply, and expose metrics at :9100/metrics
+		influx stacks reconcile --stack-id $STACK_ID --dry-run --metrics-addr :9100
+
+	For information about how stacks work with InfluxDB templates, see
+	https://docs.influxdata.com/influxdb/latest/reference/cli/influx/stacks/
+`
+
+	b.registerStackReconcileFlags(cmd)
+	cmd.Flags().DurationVar(&b.reconcileOpts.interval, "interval", 5*time.Minute, "Interval between reconciliations")
+	cmd.Flags().BoolVar(&b.reconcileOpts.dryRun, "dry-run", false, "Only report drift, never apply the template(s)")
+	cmd.Flags().StringVar(&b.reconcileOpts.metricsAddr, "metrics-addr", "", "Address to expose prometheus metrics on at /metrics; disabled when empty")
+	b.applyOpts.secrets = []string{}
+	cmd.Flags().StringSliceVar(&b.applyOpts.secrets, "secret", nil, "Secrets to provide alongside the template; format should --secret=SECRET_KEY=SECRET_VALUE --secret=SECRET_KEY_2=SECRET_VALUE_2")
+	registerPrintOptions(b.viper, cmd, &b.hideHeaders, &b.json)
+
+	return cmd
+}
+
+func (b *cmdTemplateBuilder) stackReconcileRunEFn(cmd *cobra.Command, args []string) error {
+	if b.reconcileOpts.interval <= 0 {
+		return errors.New("interval must be greater than 0")
+	}
+
+	reconciler, err := b.newStackReconciler(pkger.WithReconcileNotifier(b.printReconcileEvent))
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+	go func() {
+		select {
+		case <-sigCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	if b.reconcileOpts.metricsAddr != "" {
+		reg := prom.NewRegistry(zap.NewNop())
+		reg.MustRegister(reconciler.PrometheusCollectors()...)
+
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", reg.HTTPHandler())
+		srv := &http.Server{Addr: b.reconcileOpts.metricsAddr, Handler: mux}
+		go func() {
+			if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				fmt.Fprintf(b.w, "failed to serve metrics: %v\n", err)
+				cancel()
+			}
+		}()
+		defer srv.Close()
+	}
+
+	return reconciler.Run(ctx)
+}
+
+func (b *cmdTemplateBuilder) registerStackReconcileFlags(cmd *cobra.Command) {
+	b.org.register(b.viper, cmd, false)
+	cmd.Flags().StringVarP(&b.stackID, "stack-id", "i", "", "ID of stack")
+	cmd.MarkFlagRequired("stack-id")
+
+	cmd.Flags().StringSliceVarP(&b.files, "file", "f", nil, "Path to template file applied to the stack; Supports HTTP(S) URLs or file paths.")
+	cmd.MarkFlagFilename("file", "yaml", "yml", "json", "jsonnet")
+	cmd.Flags().BoolVarP(&b.recurse, "recurse", "R", false, "Process the directory used in -f, --file recursively.")
+	cmd.Flags().StringSliceVar(&b.applyOpts.envRefs, "env-ref", nil, "Environment references to provide alongside the template; format should --env-ref=REF_KEY=REF_VALUE --env-ref=REF_KEY_2=REF_VALUE_2")
+	cmd.Flags().StringArrayVar(&b.applyOpts.params, "param", nil, "Parameter values to provide alongside the template; format should --param=PARAM_NAME=PARAM_VALUE --param=PARAM_NAME_2=PARAM_VALUE_2")
+}
+
+func (b *cmdTemplateBuilder) newStackReconciler(opts ...pkger.ReconcilerOptFn) (*pkger.Reconciler, error) {
+	if err := b.org.validOrgFlags(&flags); err != nil {
+		return nil, err
+	}
+
+	templateSVC, orgSVC, err := b.svcFn()
+	if err != nil {
+		return nil, err
+	}
+
+	orgID, err := b.org.getID(orgSVC)
+	if err != nil {
+		return nil, err
+	}
+
+	stackID, err := platform.IDFromString(b.stackID)
+	if err != nil {
+		return nil, ierror.Wrap(err, "required stack id is invalid")
+	}
+
+	envRefs, err := parseKVPairs("env-ref", b.applyOpts.envRefs)
+	if err != nil {
+		return nil, err
+	}
+	params, err := parseKVPairs("param", b.applyOpts.params)
+	if err != nil {
+		return nil, err
+	}
+	secrets, err := parseKVPairs("secret", b.applyOpts.secrets)
+	if err != nil {
+		return nil, err
+	}
+
+	opts = append(opts,
+		pkger.WithReconcileDryRun(b.reconcileOpts.dryRun),
+		pkger.WithReconcileInterval(b.reconcileOpts.interval),
+		pkger.WithReconcileApplyOpts(
+			pkger.ApplyWithEnvRefs(toMapInterface(envRefs)),
+			pkger.ApplyWithParams(toMapInterface(params)),
+			pkger.ApplyWithSecrets(secrets),
+		),
+	)
+	if len(b.files) > 0 {
+		opts = append(opts, pkger.WithReconcileTemplates(b.readTemplatesFromFlags))
+	}
+
+	return pkger.NewReconciler(templateSVC, orgID, *stackID, opts...), nil
+}
+
+func (b *cmdTemplateBuilder) printReconcileEvent(event pkger.ReconcileEvent) {
+	if b.json {
+		b.writeJSON(event)
+		return
+	}
+
+	report := event.Report
+	switch {
+	case event.Err != "":
+		fmt.Fprintf(b.w, "%s\tstack %s failed to reconcile: %s\n", time.Now().UTC().Format(time.RFC3339), b.stackID, event.Err)
+	case !report.HasDrift():
+		fmt.Fprintf(b.w, "%s\tstack %s has not drifted\n", report.CheckedAt.UTC().Format(time.RFC3339), report.StackID)
+	default:
+		fmt.Fprintf(b.w, "%s\tstack %s has %d drifted resource(s); applied=%t\n", report.CheckedAt.UTC().Format(time.RFC3339), report.StackID, len(report.Resources), event.Applied)
+		tabW := b.newTabWriter()
+		tabW.HideHeaders(b.hideHeaders)
+		writeDriftRows(tabW, report)
+		tabW.Flush()
+	}
+}
+
 func (b *cmdTemplateBuilder) writeStack(stack pkger.Stack) error {
 	if b.json {
 		return b.writeJSON(stack)
@@ -1065,12 +1339,12 @@ func (b *cmdTemplateBuilder) readRawTemplatesFromURLs(urls []string) ([]*pkger.T
 	return rawTemplates, nil
 }
 
-func (b *cmdTemplateBuilder) readTemplate() (*pkger.Template, bool, error) {
+func (b *cmdTemplateBuilder) readTemplatesFromFlags() ([]*pkger.Template, error) {
 	var remotes, files []string
 	for _, rawURL := range append(b.files, b.urls...) {
 		u, err := url.Parse(rawURL)
 		if err != nil {
-			return nil, false, ierror.Wrap(err, fmt.Sprintf("failed to parse url[%s]", rawURL))
+			return nil, ierror.Wrap(err, fmt.Sprintf("failed to parse url[%s]", rawURL))
 		}
 		if strings.HasPrefix(u.Scheme, "http") {
 			remotes = append(remotes, u.String())
@@ -1081,14 +1355,21 @@ func (b *cmdTemplateBuilder) readTemplate() (*pkger.Template, bool, error) {
 
 	templates, err := b.readRawTemplatesFromFiles(files, b.recurse)
 	if err != nil {
-		return nil, false, err
+		return nil, err
 	}
 
 	urlTemplates, err := b.readRawTemplatesFromURLs(remotes)
+	if err != nil {
+		return nil, err
+	}
+	return append(templates, urlTemplates...), nil
+}
+
+func (b *cmdTemplateBuilder) readTemplate() (*pkger.Template, bool, error) {
+	templates, err := b.readTemplatesFromFlags()
 	if err != nil {
 		return nil, false, err
 	}
-	templates = append(templates, urlTemplates...)
 
 	// the pkger.ValidSkipParseError option allows our server to be the one to validate the
 	// the template is accurate. If a user has an older version of the CLI and cloud gets updated
@@ -1539,6 +1820,131 @@ func (b *cmdTemplateBuilder) printTemplateDiff(diff pkger.Diff) error {
 		printer.Render()
 	}
 
+	if mappings := diff.DBRPMappings; len(mappings) > 0 {
+		printer := diffPrinterGen("DBRP Mappings", []string{"Default", "Bucket ID"})
+
+		appendValues := func(id pkger.SafeID, metaName string, v pkger.DiffDBRPMappingValues) []string {
+			return []string{
+				metaName,
+				id.String(),
+				v.Database + "/" + v.RetentionPolicy,
+				strconv.FormatBool(v.Default),
+				v.BucketID.String(),
+			}
+		}
+
+		for _, m := range mappings {
+			var oldRow []string
+			if m.Old != nil {
+				oldRow = appendValues(m.ID, m.MetaName, *m.Old)
+			}
+
+			newRow := appendValues(m.ID, m.MetaName, m.New)
+			switch {
+			case pkger.IsNew(m.StateStatus):
+				printer.AppendDiff(nil, newRow)
+			case pkger.IsRemoval(m.StateStatus):
+				printer.AppendDiff(oldRow, nil)
+			default:
+				printer.AppendDiff(oldRow, newRow)
+			}
+		}
+		printer.Render()
+	}
+
+	if notebooks := diff.Notebooks; len(notebooks) > 0 {
+		printer := diffPrinterGen("Notebooks", nil)
+
+		appendValues := func(id pkger.SafeID, metaName string, v pkger.DiffNotebookValues) []string {
+			return []string{metaName, id.String(), v.Name}
+		}
+
+		for _, n := range notebooks {
+			var oldRow []string
+			if n.Old != nil {
+				oldRow = appendValues(n.ID, n.MetaName, *n.Old)
+			}
+
+			newRow := appendValues(n.ID, n.MetaName, n.New)
+			switch {
+			case pkger.IsNew(n.StateStatus):
+				printer.AppendDiff(nil, newRow)
+			case pkger.IsRemoval(n.StateStatus):
+				printer.AppendDiff(oldRow, nil)
+			default:
+				printer.AppendDiff(oldRow, newRow)
+			}
+		}
+		printer.Render()
+	}
+
+	if scrapers := diff.Scrapers; len(scrapers) > 0 {
+		printer := diffPrinterGen("Scrapers", []string{"Type", "URL", "Bucket ID", "Allow Insecure"})
+
+		appendValues := func(id pkger.SafeID, metaName string, v pkger.DiffScraperValues) []string {
+			return []string{
+				metaName,
+				id.String(),
+				v.Name,
+				string(v.Type),
+				v.URL,
+				v.BucketID.String(),
+				strconv.FormatBool(v.AllowInsecure),
+			}
+		}
+
+		for _, sc := range scrapers {
+			var oldRow []string
+			if sc.Old != nil {
+				oldRow = appendValues(sc.ID, sc.MetaName, *sc.Old)
+			}
+
+			newRow := appendValues(sc.ID, sc.MetaName, sc.New)
+			switch {
+			case pkger.IsNew(sc.StateStatus):
+				printer.AppendDiff(nil, newRow)
+			case pkger.IsRemoval(sc.StateStatus):
+				printer.AppendDiff(oldRow, nil)
+			default:
+				printer.AppendDiff(oldRow, newRow)
+			}
+		}
+		printer.Render()
+	}
+
+	if auths := diff.V1Authorizations; len(auths) > 0 {
+		printer := diffPrinterGen("V1 Authorizations", []string{"Description", "Status", "Permissions"})
+
+		appendValues := func(id pkger.SafeID, metaName string, v pkger.DiffV1AuthorizationValues) []string {
+			return []string{
+				metaName,
+				id.String(),
+				v.Token,
+				v.Description,
+				string(v.Status),
+				printV1Permissions(v.Permissions),
+			}
+		}
+
+		for _, a := range auths {
+			var oldRow []string
+			if a.Old != nil {
+				oldRow = appendValues(a.ID, a.MetaName, *a.Old)
+			}
+
+			newRow := appendValues(a.ID, a.MetaName, a.New)
+			switch {
+			case pkger.IsNew(a.StateStatus):
+				printer.AppendDiff(nil, newRow)
+			case pkger.IsRemoval(a.StateStatus):
+				printer.AppendDiff(oldRow, nil)
+			default:
+				printer.AppendDiff(oldRow, newRow)
+			}
+		}
+		printer.Render()
+	}
+
 	if len(diff.LabelMappings) > 0 {
 		printer := newDiffPrinter(b.w, !b.disableColor, !b.disableTableBorders)
 		printer.
@@ -1716,6 +2122,59 @@ func (b *cmdTemplateBuilder) printTemplateSummary(stackID platform.ID, sum pkger
 		})
 	}
 
+	if mappings := sum.DBRPMappings; len(mappings) > 0 {
+		headers := append(commonHeaders, "Default", "Bucket Name", "Bucket ID")
+		tablePrintFn("DBRP MAPPINGS", headers, len(mappings), func(i int) []string {
+			m := mappings[i]
+			return []string{
+				m.MetaName,
+				m.ID.String(),
+				m.Database + "/" + m.RetentionPolicy,
+				strconv.FormatBool(m.Default),
+				m.BucketName,
+				m.BucketID.String(),
+			}
+		})
+	}
+
+	if notebooks := sum.Notebooks; len(notebooks) > 0 {
+		tablePrintFn("NOTEBOOKS", commonHeaders, len(notebooks), func(i int) []string {
+			n := notebooks[i]
+			return []string{n.MetaName, n.ID.String(), n.Name}
+		})
+	}
+
+	if scrapers := sum.Scrapers; len(scrapers) > 0 {
+		headers := append(commonHeaders, "Type", "URL", "Bucket Name", "Bucket ID")
+		tablePrintFn("SCRAPERS", headers, len(scrapers), func(i int) []string {
+			sc := scrapers[i]
+			return []string{
+				sc.MetaName,
+				sc.ID.String(),
+				sc.Name,
+				string(sc.Type),
+				sc.URL,
+				sc.BucketName,
+				sc.BucketID.String(),
+			}
+		})
+	}
+
+	if auths := sum.V1Authorizations; len(auths) > 0 {
+		headers := append(commonHeaders, "Description", "Status", "Permissions")
+		tablePrintFn("V1 AUTHORIZATIONS", headers, len(auths), func(i int) []string {
+			a := auths[i]
+			return []string{
+				a.MetaName,
+				a.ID.String(),
+				a.Token,
+				a.Description,
+				string(a.Status),
+				printV1Permissions(a.Permissions),
+			}
+		})
+	}
+
 	if mappings := sum.LabelMappings; len(mappings) > 0 {
 		headers := []string{"Resource Type", "Resource Name", "Resource ID", "Label Name", "Label ID"}
 		tablePrintFn("LABEL ASSOCIATIONS", headers, len(mappings), func(i int) []string {
@@ -1730,6 +2189,23 @@ func (b *cmdTemplateBuilder) printTemplateSummary(stackID platform.ID, sum pkger
 		})
 	}
 
+	if params := sum.Parameters; len(params) > 0 {
+		headers := []string{"Template Name", "Type", "Description", "Value"}
+		tablePrintFn("PARAMETERS", headers, len(params), func(i int) []string {
+			p := params[i]
+			val := ""
+			if p.Value != nil {
+				val = fmt.Sprint(p.Value)
+			}
+			return []string{
+				p.MetaName,
+				p.Type,
+				p.Description,
+				val,
+			}
+		})
+	}
+
 	if secrets := sum.MissingSecrets; len(secrets) > 0 {
 		headers := []string{"Secret Key"}
 		tablePrintFn("MISSING SECRETS", headers, len(secrets), func(i int) []string {
@@ -1769,6 +2245,23 @@ func writeStackRows(tabW *internal.TabWriter, stacks ...pkger.Stack) {
 	}
 }
 
+func writeDriftRows(tabW *internal.TabWriter, report pkger.DriftReport) {
+	tabW.WriteHeaders("Kind", "Meta Name", "ID", "Status", "Fields")
+	for _, res := range report.Resources {
+		id := ""
+		if res.ID != 0 {
+			id = res.ID.String()
+		}
+		tabW.Write(map[string]interface{}{
+			"Kind":      res.Kind,
+			"Meta Name": res.MetaName,
+			"ID":        id,
+			"Status":    res.Status,
+			"Fields":    strings.Join(res.Fields, ","),
+		})
+	}
+}
+
 type diffPrinter struct {
 	w      io.Writer
 	writer *tablewriter.Table
@@ -2024,6 +2517,18 @@ func printVarArgs(a *influxdb.VariableArguments) string {
 	return "unknown variable argument"
 }
 
+func printV1Permissions(perms []pkger.SummaryV1Permission) string {
+	out := make([]string, 0, len(perms))
+	for _, p := range perms {
+		bkt := p.BucketName
+		if bkt == "" {
+			bkt = p.BucketID.String()
+		}
+		out = append(out, fmt.Sprintf("%s:%s", p.Action, bkt))
+	}
+	return strings.Join(out, " ")
+}
+
 func formatDuration(d time.Duration) string {
 	if d == 0 {
 		return "inf"
@@ -2095,6 +2600,18 @@ func mapKeys(provided, kvPairs []string) map[string]string {
 	return out
 }
 
+func parseKVPairs(flagName string, pairs []string) (map[string]string, error) {
+	out := make(map[string]string)
+	for _, pair := range pairs {
+		pieces := strings.SplitN(pair, "=", 2)
+		if len(pieces) < 2 {
+			return nil, fmt.Errorf("invalid %s provided: %q; expected format --%s=KEY=VALUE", flagName, pair, flagName)
+		}
+		out[pieces[0]] = pieces[1]
+	}
+	return out, nil
+}
+
 func toMapInterface(m map[string]string) map[string]interface{} {
 	out := make(map[string]interface{})
 	for k, v := range m {
@@ -21,6 +21,7 @@ import (
 	ihttp "github.com/influxdata/influxdb/v2/http"
 	"github.com/influxdata/influxdb/v2/kit/signals"
 	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/pkg/ccsds"
 	"github.com/influxdata/influxdb/v2/pkg/csv2lp"
 	"github.com/influxdata/influxdb/v2/write"
 	"github.com/spf13/cobra"
@@ -29,6 +30,7 @@ import (
 const (
 	inputFormatCsv          = "csv"
 	inputFormatLineProtocol = "lp"
+	inputFormatCcsds        = "ccsds"
 	inputCompressionNone    = "none"
 	inputCompressionGzip    = "gzip"
 )
@@ -46,6 +48,7 @@ type writeFlagsBuilder struct {
 	Bucket                     string
 	Precision                  string
 	Format                     string
+	PacketDef                  string
 	Headers                    []string
 	Files                      []string
 	URLs                       []string
@@ -119,7 +122,8 @@ func (b *writeFlagsBuilder) cmd() *cobra.Command {
 		},
 	}
 	opts.mustRegister(b.viper, cmd)
-	cmd.PersistentFlags().StringVar(&b.Format, "format", "", "Input format, either lp (Line Protocol) or csv (Comma Separated Values). Defaults to lp unless '.csv' extension")
+	cmd.PersistentFlags().StringVar(&b.Format, "format", "", "Input format, either lp (Line Protocol), csv (Comma Separated Values) or ccsds (CCSDS space packets). Defaults to lp unless '.csv' extension")
+	cmd.PersistentFlags().StringVar(&b.PacketDef, "packet-def", "", "The path to the packet definition file (YAML or JSON) used to decode ccsds input")
 	cmd.PersistentFlags().StringArrayVar(&b.Headers, "header", []string{}, "Header prepends lines to input data; Example --header HEADER1 --header HEADER2")
 	cmd.PersistentFlags().StringArrayVarP(&b.Files, "file", "f", []string{}, "The path to the file to import")
 	cmd.PersistentFlags().StringArrayVarP(&b.URLs, "url", "u", []string{}, "The URL to import data from")
@@ -163,9 +167,17 @@ func (b *writeFlagsBuilder) createLineReader(ctx context.Context, cmd *cobra.Com
 	closers := make([]io.Closer, 0, len(files)+len(b.URLs))
 
 	// validate input format
-	if len(b.Format) > 0 && b.Format != inputFormatLineProtocol && b.Format != inputFormatCsv {
+	if len(b.Format) > 0 && b.Format != inputFormatLineProtocol && b.Format != inputFormatCsv && b.Format != inputFormatCcsds {
 		return nil, csv2lp.MultiCloser(closers...), fmt.Errorf("unsupported input format: %s", b.Format)
 	}
+	// binary space packets are neither character decoded nor separated by new lines
+	binaryInput := b.Format == inputFormatCcsds
+	if binaryInput && len(b.Headers) > 0 {
+		return nil, csv2lp.MultiCloser(closers...), fmt.Errorf("headers are not supported with %s input", b.Format)
+	}
+	if binaryInput != (b.PacketDef != "") {
+		return nil, csv2lp.MultiCloser(closers...), fmt.Errorf("--packet-def must be used with, and only with, --format %s", inputFormatCcsds)
+	}
 	// validate input compression
 	if len(b.Compression) > 0 && b.Compression != inputCompressionNone && b.Compression != inputCompressionGzip {
 		return nil, csv2lp.MultiCloser(closers...), fmt.Errorf("unsupported input compression: %s", b.Compression)
@@ -188,6 +200,10 @@ func (b *writeFlagsBuilder) createLineReader(ctx context.Context, cmd *cobra.Com
 			closers = append(closers, rcz)
 			r = rcz
 		}
+		if binaryInput {
+			readers = append(readers, r)
+			return nil
+		}
 		readers = append(readers, decode(r), strings.NewReader("\n"))
 		return nil
 	}
@@ -330,6 +346,24 @@ func (b *writeFlagsBuilder) createLineReader(ctx context.Context, cmd *cobra.Com
 		csvReader.RowSkipped = rowSkippedListener
 		r = csvReader
 	}
+	if b.Format == inputFormatCcsds {
+		f, err := os.Open(b.PacketDef)
+		if err != nil {
+			return nil, csv2lp.MultiCloser(closers...), fmt.Errorf("failed to open %q: %v", b.PacketDef, err)
+		}
+		def, err := ccsds.ParseDefinition(f)
+		f.Close()
+		if err != nil {
+			return nil, csv2lp.MultiCloser(closers...), fmt.Errorf("invalid packet definition %q: %v", b.PacketDef, err)
+		}
+		packetReader := ccsds.ToLineProtocol(r, def, b.Precision)
+		if b.Debug {
+			packetReader.Decoder.PacketSkipped = func(header ccsds.PrimaryHeader, offset int64) {
+				log.Printf("skipped packet with undefined apid %d at offset %d", header.APID, offset)
+			}
+		}
+		r = packetReader
+	}
 	// throttle reader if requested
 	rateLimit, err := ToBytesPerSecond(b.RateLimit)
 	if err != nil {
@@ -97,6 +97,17 @@ func Test_writeFlags_dump(t *testing.T) {
 
 // Test_writeFlags_createLineReader validates the way of how headers, files, stdin and arguments
 // are combined and transformed to provide a reader of protocol lines
+const ccsdsPacketDef = `
+packets:
+  - apid: 5
+    measurement: obc
+    time: {offset: 0, epoch: 1970-01-01T00:00:00Z}
+    fields: [{name: cpu, offset: 4, bits: 8}]
+`
+
+// ccsdsPacket is a space packet of apid 5 at 1s since the unix epoch with cpu 42
+var ccsdsPacket = []byte{0x08, 0x05, 0xC0, 0x00, 0x00, 0x04, 0, 0, 0, 1, 42}
+
 func Test_writeFlags_createLineReader(t *testing.T) {
 	defer removeTempFiles()
 
@@ -121,6 +132,10 @@ func Test_writeFlags_createLineReader(t *testing.T) {
 	gzipCsvFileNoExt := createTempFile(t, "csv", []byte(csvContents), true)
 	stdInCsvContents := "i,j,_measurement,k\nstdin1,stdin2,stdin3,stdin4"
 
+	packetDefFile := createTempFile(t, "yml", []byte(ccsdsPacketDef), false)
+	packetFile := createTempFile(t, "bin", ccsdsPacket, false)
+	gzipPacketFile := createTempFile(t, "bin.gz", ccsdsPacket, true)
+
 	// use a test HTTP server to provide CSV data
 	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		// fmt.Println(req.URL.String())
@@ -501,6 +516,30 @@ func Test_writeFlags_createLineReader(t *testing.T) {
 				lpContents,
 			},
 		},
+		{
+			name: "read CCSDS packets from files + transform to line protocol",
+			flags: writeFlagsBuilder{
+				Files:     []string{packetFile, gzipPacketFile},
+				Format:    inputFormatCcsds,
+				PacketDef: packetDefFile,
+				Precision: "s",
+			},
+			lines: []string{
+				"obc cpu=42u 1",
+				"obc cpu=42u 1",
+			},
+		},
+		{
+			name: "read CCSDS packets from stdin + transform to line protocol",
+			flags: writeFlagsBuilder{
+				Format:    inputFormatCcsds,
+				PacketDef: packetDefFile,
+			},
+			stdIn: bytes.NewReader(ccsdsPacket),
+			lines: []string{
+				"obc cpu=42u 1000000000",
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -521,6 +560,8 @@ func Test_writeFlags_createLineReader(t *testing.T) {
 func Test_writeFlags_createLineReader_errors(t *testing.T) {
 	defer removeTempFiles()
 	csvFile1 := createTempFile(t, "csv", []byte("_measurement,b,c,d\nf1,f2,f3,f4"), false)
+	packetDefFile := createTempFile(t, "yml", []byte(ccsdsPacketDef), false)
+	invalidPacketDefFile := createTempFile(t, "yml", []byte("packets: []"), false)
 	// use a test HTTP server to server errors
 	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		rw.WriteHeader(http.StatusInternalServerError)
@@ -576,6 +617,37 @@ func Test_writeFlags_createLineReader_errors(t *testing.T) {
 			},
 			message: server.URL,
 		},
+		{
+			name: "ccsds without packet definition",
+			flags: writeFlagsBuilder{
+				Format: inputFormatCcsds,
+			},
+			message: "--packet-def",
+		},
+		{
+			name: "packet definition without ccsds",
+			flags: writeFlagsBuilder{
+				PacketDef: packetDefFile,
+			},
+			message: "--packet-def",
+		},
+		{
+			name: "ccsds with headers",
+			flags: writeFlagsBuilder{
+				Format:    inputFormatCcsds,
+				PacketDef: packetDefFile,
+				Headers:   []string{"a,b"},
+			},
+			message: "headers are not supported",
+		},
+		{
+			name: "invalid packet definition",
+			flags: writeFlagsBuilder{
+				Format:    inputFormatCcsds,
+				PacketDef: invalidPacketDefFile,
+			},
+			message: "invalid packet definition",
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -0,0 +1,256 @@
+package inspect
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/influxdata/influxdb/v2/tsdb/engine/tsm1"
+	"github.com/influxdata/influxdb/v2/tsdb/index/tsi1"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// NewBuildTSICommand builds the `build-tsi` subcommand of `influxd inspect`.
+func NewBuildTSICommand() *cobra.Command {
+	b := &tsiBuilder{}
+	cmd := &cobra.Command{
+		Use:   "build-tsi",
+		Short: "Rebuild the TSI index of shards from their TSM files and WAL",
+		Long: `
+This command rebuilds the TSI index of the shards of a stopped engine from the
+series keys of their TSM files and WAL segments. Shards that already have an
+index are skipped unless --force is set, in which case the existing index is
+replaced once the new one has been built.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return b.run(cmd.OutOrStdout())
+		},
+	}
+
+	registerEnginePathFlag(cmd, &b.enginePath)
+	b.filter.registerFlags(cmd)
+	cmd.Flags().IntVar(&b.batchSize, "batch-size", 10000, "Number of series keys added to the index at once")
+	cmd.Flags().Int64Var(&b.maxLogFileSize, "max-log-file-size", tsdb.DefaultMaxIndexLogFileSize, "Maximum size in bytes of the log files of the index before they are compacted")
+	cmd.Flags().BoolVar(&b.force, "force", false, "Replace the existing index of shards")
+	cmd.Flags().BoolVarP(&b.verbose, "verbose", "v", false, "Log the progress of every shard")
+
+	return cmd
+}
+
+type tsiBuilder struct {
+	enginePath     string
+	filter         shardFilter
+	batchSize      int
+	maxLogFileSize int64
+	force          bool
+	verbose        bool
+}
+
+func (b *tsiBuilder) run(out io.Writer) error {
+	if b.batchSize <= 0 {
+		return fmt.Errorf("--batch-size must be positive, got %d", b.batchSize)
+	}
+
+	shards, err := loadShards(b.enginePath, b.filter)
+	if err != nil {
+		return err
+	}
+
+	log := zap.NewNop()
+	if b.verbose {
+		if log, err = zap.NewDevelopment(); err != nil {
+			return err
+		}
+	}
+
+	// shards of a bucket share the bucket's series file
+	sfiles := make(map[string]*tsdb.SeriesFile)
+	defer func() {
+		for _, sfile := range sfiles {
+			sfile.Close()
+		}
+	}()
+
+	for _, sh := range shards {
+		indexPath := filepath.Join(sh.path, "index")
+		if _, err := os.Stat(indexPath); err == nil && !b.force {
+			fmt.Fprintf(out, "%s: index already exists, skipping\n", sh.path)
+			continue
+		} else if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		sfile, ok := sfiles[sh.bucketID]
+		if !ok {
+			sfile = tsdb.NewSeriesFile(sh.seriesFilePath(b.enginePath))
+			sfile.Logger = log
+			if err := sfile.Open(); err != nil {
+				return fmt.Errorf("opening series file of bucket %s: %w", sh.bucketID, err)
+			}
+			sfiles[sh.bucketID] = sfile
+		}
+
+		n, err := b.buildShard(sfile, sh, log.With(zap.Uint64("shard_id", sh.id)))
+		if err != nil {
+			return fmt.Errorf("%s: %w", sh.path, err)
+		}
+		fmt.Fprintf(out, "%s: indexed %d series\n", sh.path, n)
+	}
+	return nil
+}
+
+// buildShard builds the index of a shard in a temporary directory and moves
+// it in place of the shard's index once it is complete.
+func (b *tsiBuilder) buildShard(sfile *tsdb.SeriesFile, sh shardDir, log *zap.Logger) (int, error) {
+	indexPath := filepath.Join(sh.path, "index")
+	tmpPath := indexPath + ".tmp"
+	if err := os.RemoveAll(tmpPath); err != nil {
+		return 0, err
+	}
+
+	idx := tsi1.NewIndex(sfile, sh.bucketID,
+		tsi1.WithPath(tmpPath),
+		tsi1.WithMaximumLogFileSize(b.maxLogFileSize),
+		tsi1.WithLogger(*log),
+	)
+	if err := idx.Open(); err != nil {
+		return 0, err
+	}
+
+	n, err := b.indexSeries(idx, sh, log)
+	if err != nil {
+		idx.Close()
+		os.RemoveAll(tmpPath)
+		return 0, err
+	}
+
+	log.Info("Compacting index")
+	idx.Compact()
+	idx.Wait()
+	if err := idx.Close(); err != nil {
+		return 0, err
+	}
+
+	if err := os.RemoveAll(indexPath); err != nil {
+		return 0, err
+	}
+	return n, os.Rename(tmpPath, indexPath)
+}
+
+// indexSeries adds the series of the TSM files and WAL segments of the shard
+// to the index and returns the number of series of the index.
+func (b *tsiBuilder) indexSeries(idx *tsi1.Index, sh shardDir, log *zap.Logger) (int, error) {
+	var (
+		batch seriesBatch
+		flush = func() error {
+			if len(batch.keys) == 0 {
+				return nil
+			}
+			if err := idx.CreateSeriesListIfNotExists(batch.keys, batch.names, batch.tags); err != nil {
+				return err
+			}
+			batch = seriesBatch{}
+			return nil
+		}
+		add = func(seriesKey []byte) error {
+			batch.add(seriesKey)
+			if len(batch.keys) >= b.batchSize {
+				return flush()
+			}
+			return nil
+		}
+	)
+
+	tsmFiles, err := sh.tsmFiles()
+	if err != nil {
+		return 0, err
+	}
+	for _, f := range tsmFiles {
+		log.Info("Indexing TSM file", zap.String("path", f))
+		r, err := openTSMFile(f)
+		if err != nil {
+			return 0, err
+		}
+		// the fields of a series are adjacent in the index of a TSM file
+		var prev []byte
+		for i := 0; i < r.KeyCount(); i++ {
+			key, _ := r.KeyAt(i)
+			seriesKey, _ := tsm1.SeriesAndFieldFromCompositeKey(key)
+			if bytes.Equal(seriesKey, prev) {
+				continue
+			}
+			prev = seriesKey
+			if err := add(seriesKey); err != nil {
+				r.Close()
+				return 0, err
+			}
+		}
+		// the batch references the memory mapped file, flush it before closing the file
+		if err := flush(); err != nil {
+			r.Close()
+			return 0, err
+		}
+		if err := r.Close(); err != nil {
+			return 0, err
+		}
+	}
+
+	walFiles, err := sh.walFiles()
+	if err != nil {
+		return 0, err
+	}
+	for _, f := range walFiles {
+		log.Info("Indexing WAL segment", zap.String("path", f))
+		fd, err := os.Open(f)
+		if err != nil {
+			return 0, err
+		}
+		r := tsm1.NewWALSegmentReader(fd)
+		for r.Next() {
+			entry, err := r.Read()
+			if err != nil {
+				// the engine truncates a segment at its first corrupt entry
+				log.Warn("Skipping corrupt end of WAL segment", zap.String("path", f), zap.Int64("offset", r.Count()), zap.Error(err))
+				break
+			}
+			w, ok := entry.(*tsm1.WriteWALEntry)
+			if !ok {
+				continue
+			}
+			for key := range w.Values {
+				seriesKey, _ := tsm1.SeriesAndFieldFromCompositeKey([]byte(key))
+				if err := add(seriesKey); err != nil {
+					r.Close()
+					return 0, err
+				}
+			}
+		}
+		if err := r.Close(); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := flush(); err != nil {
+		return 0, err
+	}
+	return int(idx.SeriesN()), nil
+}
+
+// seriesBatch is a batch of series keys to add to an index.
+type seriesBatch struct {
+	keys  [][]byte
+	names [][]byte
+	tags  []models.Tags
+}
+
+func (b *seriesBatch) add(seriesKey []byte) {
+	name, tags := models.ParseKeyBytes(seriesKey)
+	b.keys = append(b.keys, seriesKey)
+	b.names = append(b.names, name)
+	b.tags = append(b.tags, tags)
+}
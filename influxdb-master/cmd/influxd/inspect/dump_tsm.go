@@ -0,0 +1,216 @@
+package inspect
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/tsdb/engine/tsm1"
+	"github.com/spf13/cobra"
+)
+
+// NewDumpTSMCommand builds the `dump-tsm` subcommand of `influxd inspect`.
+func NewDumpTSMCommand() *cobra.Command {
+	d := &tsmDumper{}
+	cmd := &cobra.Command{
+		Use:   "dump-tsm",
+		Short: "Dump the index entries and blocks of a TSM file",
+		Long: `
+This command dumps the summary, the index entries and the blocks of a single
+TSM file. Use --all to also dump the values of every block.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return d.run(cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().StringVar(&d.path, "file-path", "", "Path to the TSM file")
+	cmd.Flags().BoolVar(&d.index, "index", false, "Dump the index entries")
+	cmd.Flags().BoolVar(&d.blocks, "blocks", false, "Dump the blocks")
+	cmd.Flags().BoolVar(&d.all, "all", false, "Dump the index entries, the blocks and their values")
+	cmd.Flags().StringVar(&d.filterKey, "filter-key", "", "Only dump index entries and blocks of keys containing this string")
+	_ = cmd.MarkFlagRequired("file-path")
+
+	return cmd
+}
+
+type tsmDumper struct {
+	path      string
+	index     bool
+	blocks    bool
+	all       bool
+	filterKey string
+}
+
+// Names of the timestamp and value encodings, by encoding id, of every block type
+var (
+	timeEncodings  = []string{"none", "s8b", "rle"}
+	valueEncodings = map[byte][]string{
+		tsm1.BlockFloat64:  {"none", "gor", "bss", "qnt"},
+		tsm1.BlockInteger:  {"none", "s8b", "rle"},
+		tsm1.BlockBoolean:  {"none", "bp"},
+		tsm1.BlockString:   {"none", "snpy"},
+		tsm1.BlockUnsigned: {"none", "s8b", "rle"},
+	}
+)
+
+func encodingName(names []string, b []byte) string {
+	if len(b) == 0 {
+		return "-"
+	}
+	enc := int(b[0] >> 4)
+	if enc < len(names) {
+		return names[enc]
+	}
+	return fmt.Sprintf("unknown(%d)", enc)
+}
+
+func (d *tsmDumper) matches(key []byte) bool {
+	return d.filterKey == "" || strings.Contains(string(key), d.filterKey)
+}
+
+func (d *tsmDumper) run(out io.Writer) error {
+	r, err := openTSMFile(d.path)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	minTime, maxTime := r.TimeRange()
+	keyCount := r.KeyCount()
+
+	fmt.Fprintf(out, "Summary:\n  File: %s\n  Time Range: %s - %s\n  Duration: %s\n  Series: %d\n  File Size: %d\n\n",
+		d.path,
+		time.Unix(0, minTime).UTC().Format(time.RFC3339Nano),
+		time.Unix(0, maxTime).UTC().Format(time.RFC3339Nano),
+		time.Duration(maxTime-minTime),
+		keyCount,
+		r.Size(),
+	)
+
+	tw := tabwriter.NewWriter(out, 8, 8, 1, '\t', 0)
+
+	if d.index || d.all {
+		fmt.Fprintln(out, "Index:")
+		fmt.Fprintln(tw, "  Pos\tMin Time\tMax Time\tOfs\tSize\tKey\tField")
+		var pos int
+		for i := 0; i < keyCount; i++ {
+			key, _ := r.KeyAt(i)
+			if !d.matches(key) {
+				continue
+			}
+			seriesKey, field := tsm1.SeriesAndFieldFromCompositeKey(key)
+			for _, e := range r.Entries(key) {
+				pos++
+				fmt.Fprintf(tw, "  %d\t%d\t%d\t%d\t%d\t%s\t%s\n", pos, e.MinTime, e.MaxTime, e.Offset, e.Size, seriesKey, field)
+			}
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+		fmt.Fprintln(out)
+	}
+
+	if !d.blocks && !d.all {
+		return nil
+	}
+
+	fmt.Fprintln(out, "Blocks:")
+	fmt.Fprintln(tw, "  Blk\tChk\tLen\tType\tMin Time\tPoints\tEnc [T/V]\tLen [T/V]\tKey")
+
+	var (
+		blockCount, pointCount, corrupt int
+		blockSize                       int64
+		values                          []tsm1.Value
+	)
+	iter := r.BlockIterator()
+	for iter.Next() {
+		key, blockMin, _, typ, checksum, buf, err := iter.Read()
+		if err != nil {
+			return err
+		}
+		blockCount++
+		// every block is prefixed by its 4 byte checksum
+		blockSize += int64(4 + len(buf))
+
+		if !d.matches(key) {
+			continue
+		}
+
+		status := "ok"
+		if crc32.ChecksumIEEE(buf) != checksum {
+			status = "bad"
+			corrupt++
+		}
+
+		ts, vs, err := splitBlock(buf)
+		if err != nil {
+			fmt.Fprintf(tw, "  %d\t%s\t%d\t%s\t%d\t-\t-\t-\t%s (%v)\n", blockCount, status, len(buf), blockTypeName(typ), blockMin, key, err)
+			if status == "ok" {
+				corrupt++
+			}
+			continue
+		}
+		n, err := tsm1.BlockCount(buf)
+		if err != nil {
+			return err
+		}
+		pointCount += n
+
+		fmt.Fprintf(tw, "  %d\t%s\t%d\t%s\t%d\t%d\t%s/%s\t%d/%d\t%s\n",
+			blockCount, status, len(buf), blockTypeName(typ), blockMin, n,
+			encodingName(timeEncodings, ts), encodingName(valueEncodings[typ], vs),
+			len(ts), len(vs), key,
+		)
+
+		if d.all {
+			values, err = tsm1.DecodeBlock(buf, values[:0])
+			if err != nil {
+				fmt.Fprintf(tw, "  \t\t\t\t\t\t\t\tfailed to decode block: %v\n", err)
+				continue
+			}
+			for _, v := range values {
+				fmt.Fprintf(tw, "  \t\t\t\t\t\t\t\t%s\n", v.String())
+			}
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "\nStatistics:\n  Blocks: %d (%d bytes), Points: %d, Corrupt Blocks: %d\n", blockCount, blockSize, pointCount, corrupt)
+	fmt.Fprintf(out, "  Index: %d entries for %d keys (%d bytes)\n", indexEntryCount(r), keyCount, r.IndexSize())
+	return nil
+}
+
+func indexEntryCount(r *tsm1.TSMReader) int {
+	var n int
+	var entries []tsm1.IndexEntry
+	for i := 0; i < r.KeyCount(); i++ {
+		key, _ := r.KeyAt(i)
+		entries = r.ReadEntries(key, &entries)
+		n += len(entries)
+	}
+	return n
+}
+
+// splitBlock splits a block into its timestamp and value sections.
+func splitBlock(block []byte) (ts, values []byte, err error) {
+	if len(block) < 2 {
+		return nil, nil, errors.New("block is too short")
+	}
+	buf := block[1:]
+	tsLen, i := binary.Uvarint(buf)
+	if i <= 0 || uint64(len(buf)-i) < tsLen {
+		return nil, nil, errors.New("timestamp section exceeds the block")
+	}
+	return buf[i : i+int(tsLen)], buf[i+int(tsLen):], nil
+}
@@ -22,6 +22,14 @@ func NewCommand(v *viper.Viper) (*cobra.Command, error) {
 	}
 	base.AddCommand(exportLp)
 	base.AddCommand(NewExportIndexCommand())
+	base.AddCommand(NewReportTSMCommand())
+	base.AddCommand(NewDumpTSMCommand())
+	base.AddCommand(NewVerifyWALCommand())
+	base.AddCommand(NewVerifyTSMCommand())
+	base.AddCommand(NewVerifyTombstoneCommand())
+	base.AddCommand(NewVerifySeriesFileCommand())
+	base.AddCommand(NewBuildTSICommand())
+	base.AddCommand(NewRewriteShardCommand())
 
 	return base, nil
 }
@@ -0,0 +1,284 @@
+package inspect
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/pkg/estimator/hll"
+	"github.com/influxdata/influxdb/v2/tsdb/engine/tsm1"
+	"github.com/spf13/cobra"
+)
+
+// NewReportTSMCommand builds the `report-tsm` subcommand of `influxd inspect`.
+func NewReportTSMCommand() *cobra.Command {
+	r := &tsmReport{}
+	cmd := &cobra.Command{
+		Use:   "report-tsm",
+		Short: "Report series, field and block statistics of TSM files",
+		Long: `
+This command reports the number of series and fields, the number of blocks
+and points by block type, and the time range of the TSM files of every shard
+of a stopped engine. Series and field counts are estimates.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return r.run(cmd.OutOrStdout())
+		},
+	}
+
+	registerEnginePathFlag(cmd, &r.enginePath)
+	r.filter.registerFlags(cmd)
+	cmd.Flags().BoolVar(&r.detailed, "detailed", false, "Report series and field counts per measurement")
+
+	return cmd
+}
+
+type tsmReport struct {
+	enginePath string
+	filter     shardFilter
+	detailed   bool
+}
+
+// blockStats are the statistics of blocks of one type.
+type blockStats struct {
+	blocks int
+	points int
+	bytes  int64
+}
+
+// tsmStats are the statistics of a set of TSM files.
+type tsmStats struct {
+	files            int
+	size             int64
+	minTime, maxTime int64
+	series, fields   *hll.Plus
+	blocks           map[byte]*blockStats
+	measurements     map[string]*measurementStats
+}
+
+type measurementStats struct {
+	series, fields *hll.Plus
+}
+
+func newTSMStats() *tsmStats {
+	return &tsmStats{
+		minTime:      math.MaxInt64,
+		maxTime:      math.MinInt64,
+		series:       hll.NewDefaultPlus(),
+		fields:       hll.NewDefaultPlus(),
+		blocks:       make(map[byte]*blockStats),
+		measurements: make(map[string]*measurementStats),
+	}
+}
+
+func (s *tsmStats) merge(o *tsmStats) error {
+	s.files += o.files
+	s.size += o.size
+	if o.minTime < s.minTime {
+		s.minTime = o.minTime
+	}
+	if o.maxTime > s.maxTime {
+		s.maxTime = o.maxTime
+	}
+	if err := s.series.Merge(o.series); err != nil {
+		return err
+	}
+	if err := s.fields.Merge(o.fields); err != nil {
+		return err
+	}
+	for typ, b := range o.blocks {
+		sb := s.blockStats(typ)
+		sb.blocks += b.blocks
+		sb.points += b.points
+		sb.bytes += b.bytes
+	}
+	for name, m := range o.measurements {
+		sm := s.measurement(name)
+		if err := sm.series.Merge(m.series); err != nil {
+			return err
+		}
+		if err := sm.fields.Merge(m.fields); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *tsmStats) blockStats(typ byte) *blockStats {
+	b, ok := s.blocks[typ]
+	if !ok {
+		b = &blockStats{}
+		s.blocks[typ] = b
+	}
+	return b
+}
+
+func (s *tsmStats) measurement(name string) *measurementStats {
+	m, ok := s.measurements[name]
+	if !ok {
+		m = &measurementStats{series: hll.NewDefaultPlus(), fields: hll.NewDefaultPlus()}
+		s.measurements[name] = m
+	}
+	return m
+}
+
+// addFile adds the statistics of a TSM file.
+func (s *tsmStats) addFile(path string, detailed bool) error {
+	r, err := openTSMFile(path)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	s.files++
+	s.size += int64(r.Size())
+	if min, max := r.TimeRange(); r.KeyCount() > 0 {
+		if min < s.minTime {
+			s.minTime = min
+		}
+		if max > s.maxTime {
+			s.maxTime = max
+		}
+	}
+
+	for i := 0; i < r.KeyCount(); i++ {
+		key, _ := r.KeyAt(i)
+		seriesKey, field := tsm1.SeriesAndFieldFromCompositeKey(key)
+		s.series.Add(seriesKey)
+		s.fields.Add(key)
+		if detailed {
+			name, _ := models.ParseKeyBytes(seriesKey)
+			m := s.measurement(string(name))
+			m.series.Add(seriesKey)
+			m.fields.Add(field)
+		}
+	}
+
+	iter := r.BlockIterator()
+	for iter.Next() {
+		_, _, _, typ, _, buf, err := iter.Read()
+		if err != nil {
+			return err
+		}
+		b := s.blockStats(typ)
+		b.blocks++
+		b.bytes += int64(len(buf))
+		n, err := tsm1.BlockCount(buf)
+		if err != nil {
+			return err
+		}
+		b.points += n
+	}
+	return iter.Err()
+}
+
+func (r *tsmReport) run(out io.Writer) error {
+	shards, err := loadShards(r.enginePath, r.filter)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(out, 8, 8, 1, '\t', 0)
+	fmt.Fprintln(tw, "Bucket\tRP\tShard\tFiles\tSeries\tFields\tBlocks\tPoints\tSize\tMin Time\tMax Time")
+
+	total := newTSMStats()
+	for _, sh := range shards {
+		files, err := sh.tsmFiles()
+		if err != nil {
+			return err
+		}
+
+		stats := newTSMStats()
+		for _, f := range files {
+			if err := stats.addFile(f, r.detailed); err != nil {
+				return fmt.Errorf("%s: %w", f, err)
+			}
+		}
+
+		var blocks, points int
+		for _, b := range stats.blocks {
+			blocks += b.blocks
+			points += b.points
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%s\t%s\n",
+			sh.bucketID, sh.rp, sh.id, stats.files,
+			stats.series.Count(), stats.fields.Count(),
+			blocks, points, stats.size,
+			formatTime(stats.minTime, stats.minTime <= stats.maxTime), formatTime(stats.maxTime, stats.minTime <= stats.maxTime),
+		)
+
+		if err := total.merge(stats); err != nil {
+			return err
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "\nShards: %d, Files: %d, Series: %d, Fields: %d, Size: %d\n",
+		len(shards), total.files, total.series.Count(), total.fields.Count(), total.size)
+
+	fmt.Fprintln(out, "\nBlocks by type:")
+	fmt.Fprintln(tw, "Type\tBlocks\tPoints\tBytes\tBytes/Point")
+	types := make([]byte, 0, len(total.blocks))
+	for typ := range total.blocks {
+		types = append(types, typ)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	for _, typ := range types {
+		b := total.blocks[typ]
+		perPoint := 0.0
+		if b.points > 0 {
+			perPoint = float64(b.bytes) / float64(b.points)
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%.2f\n", blockTypeName(typ), b.blocks, b.points, b.bytes, perPoint)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if r.detailed {
+		fmt.Fprintln(out, "\nMeasurements:")
+		fmt.Fprintln(tw, "Measurement\tSeries\tFields")
+		names := make([]string, 0, len(total.measurements))
+		for name := range total.measurements {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			m := total.measurements[name]
+			fmt.Fprintf(tw, "%s\t%d\t%d\n", name, m.series.Count(), m.fields.Count())
+		}
+		return tw.Flush()
+	}
+	return nil
+}
+
+// blockTypeName returns the name of a TSM block type.
+func blockTypeName(typ byte) string {
+	switch typ {
+	case tsm1.BlockFloat64:
+		return "float"
+	case tsm1.BlockInteger:
+		return "integer"
+	case tsm1.BlockBoolean:
+		return "boolean"
+	case tsm1.BlockString:
+		return "string"
+	case tsm1.BlockUnsigned:
+		return "unsigned"
+	default:
+		return fmt.Sprintf("unknown(%d)", typ)
+	}
+}
+
+func formatTime(ns int64, ok bool) string {
+	if !ok {
+		return "-"
+	}
+	return time.Unix(0, ns).UTC().Format(time.RFC3339Nano)
+}
@@ -0,0 +1,342 @@
+package inspect
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"text/tabwriter"
+
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/influxdata/influxdb/v2/tsdb/engine/tsm1"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// NewRewriteShardCommand builds the `rewrite-shard` subcommand of `influxd inspect`.
+func NewRewriteShardCommand() *cobra.Command {
+	r := &shardRewriter{}
+	cmd := &cobra.Command{
+		Use:   "rewrite-shard",
+		Short: "Rewrite shards into a single generation of TSM files and rebuild their index",
+		Long: `
+This command rewrites the shards of a stopped engine. The WAL segments of a
+shard are written to a TSM file, the series fields matching --drop-measurement
+and --drop-field are deleted, and the TSM files are fully compacted, which
+applies their tombstones and merges their generations. The TSI index of the
+shard is then rebuilt and the bytes reclaimed are reported.
+
+--drop-measurement and --drop-field are regular expressions. If both are set,
+the fields matching --drop-field of the measurements matching
+--drop-measurement are dropped; if only one is set, it drops every matching
+measurement or every matching field of any measurement.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return r.run(cmd.OutOrStdout())
+		},
+	}
+
+	registerEnginePathFlag(cmd, &r.enginePath)
+	r.filter.registerFlags(cmd)
+	cmd.Flags().StringVar(&r.dropMeasurement, "drop-measurement", "", "optional: drop the measurements matching this regular expression")
+	cmd.Flags().StringVar(&r.dropField, "drop-field", "", "optional: drop the fields matching this regular expression")
+	cmd.Flags().BoolVarP(&r.verbose, "verbose", "v", false, "Log the progress of every shard")
+
+	return cmd
+}
+
+type shardRewriter struct {
+	enginePath      string
+	filter          shardFilter
+	dropMeasurement string
+	dropField       string
+	verbose         bool
+
+	drop *dropPredicate
+}
+
+// shardRewrite is the outcome of the rewrite of a shard.
+type shardRewrite struct {
+	filesBefore, filesAfter int
+	sizeBefore, sizeAfter   int64
+	droppedKeys             int
+	series                  int
+}
+
+func (r *shardRewriter) run(out io.Writer) error {
+	drop, err := newDropPredicate(r.dropMeasurement, r.dropField)
+	if err != nil {
+		return err
+	}
+	r.drop = drop
+
+	shards, err := loadShards(r.enginePath, r.filter)
+	if err != nil {
+		return err
+	}
+
+	log := zap.NewNop()
+	if r.verbose {
+		if log, err = zap.NewDevelopment(); err != nil {
+			return err
+		}
+	}
+
+	// shards of a bucket share the bucket's series file
+	sfiles := make(map[string]*tsdb.SeriesFile)
+	defer func() {
+		for _, sfile := range sfiles {
+			sfile.Close()
+		}
+	}()
+
+	tw := tabwriter.NewWriter(out, 8, 8, 1, '\t', 0)
+	fmt.Fprintln(tw, "Bucket\tRP\tShard\tFiles Before\tFiles After\tSize Before\tSize After\tReclaimed\tDropped Keys\tSeries")
+
+	var total shardRewrite
+	for _, sh := range shards {
+		sfile, ok := sfiles[sh.bucketID]
+		if !ok {
+			sfile = tsdb.NewSeriesFile(sh.seriesFilePath(r.enginePath))
+			sfile.Logger = log
+			if err := sfile.Open(); err != nil {
+				return fmt.Errorf("opening series file of bucket %s: %w", sh.bucketID, err)
+			}
+			sfiles[sh.bucketID] = sfile
+		}
+
+		s, err := r.rewriteShard(sfile, sh, log.With(zap.Uint64("shard_id", sh.id)))
+		if err != nil {
+			tw.Flush()
+			return fmt.Errorf("%s: %w", sh.path, err)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\n",
+			sh.bucketID, sh.rp, sh.id, s.filesBefore, s.filesAfter, s.sizeBefore, s.sizeAfter, s.sizeBefore-s.sizeAfter, s.droppedKeys, s.series)
+
+		total.filesBefore += s.filesBefore
+		total.filesAfter += s.filesAfter
+		total.sizeBefore += s.sizeBefore
+		total.sizeAfter += s.sizeAfter
+		total.droppedKeys += s.droppedKeys
+		total.series += s.series
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "\nShards: %d, Files: %d -> %d, Size: %d -> %d, Reclaimed: %d bytes, Dropped Keys: %d, Series: %d\n",
+		len(shards), total.filesBefore, total.filesAfter, total.sizeBefore, total.sizeAfter, total.sizeBefore-total.sizeAfter, total.droppedKeys, total.series)
+	return nil
+}
+
+// rewriteShard writes the WAL of a shard to TSM, drops the keys matching the
+// predicate, fully compacts its TSM files and rebuilds its index.
+func (r *shardRewriter) rewriteShard(sfile *tsdb.SeriesFile, sh shardDir, log *zap.Logger) (shardRewrite, error) {
+	var s shardRewrite
+
+	var err error
+	if s.filesBefore, s.sizeBefore, err = shardUsage(sh); err != nil {
+		return s, err
+	}
+
+	if s.droppedKeys, err = r.rewriteTSM(sh, log); err != nil {
+		return s, err
+	}
+
+	b := &tsiBuilder{batchSize: 10000, maxLogFileSize: tsdb.DefaultMaxIndexLogFileSize}
+	if s.series, err = b.buildShard(sfile, sh, log); err != nil {
+		return s, fmt.Errorf("rebuilding index: %w", err)
+	}
+
+	if s.filesAfter, s.sizeAfter, err = shardUsage(sh); err != nil {
+		return s, err
+	}
+	return s, nil
+}
+
+// rewriteTSM rewrites the TSM files of a shard and returns the number of
+// keys dropped.
+func (r *shardRewriter) rewriteTSM(sh shardDir, log *zap.Logger) (int, error) {
+	fs := tsm1.NewFileStore(sh.path)
+	fs.WithLogger(log)
+	if err := fs.Open(); err != nil {
+		return 0, err
+	}
+	defer fs.Close()
+
+	c := tsm1.NewCompactor()
+	c.Dir = sh.path
+	c.FileStore = fs
+	c.Open()
+	defer c.Close()
+
+	if err := flushWAL(fs, c, sh, log); err != nil {
+		return 0, fmt.Errorf("writing WAL to TSM: %w", err)
+	}
+
+	dropped, err := r.dropKeys(fs)
+	if err != nil {
+		return 0, fmt.Errorf("dropping keys: %w", err)
+	}
+
+	stats := fs.Stats()
+	var (
+		files         = make([]string, 0, len(stats))
+		hasTombstones bool
+	)
+	for _, f := range stats {
+		files = append(files, f.Path)
+		hasTombstones = hasTombstones || f.HasTombstone
+	}
+	if len(files) == 0 || (len(files) == 1 && !hasTombstones) {
+		return dropped, nil
+	}
+
+	log.Info("Compacting TSM files", zap.Int("files", len(files)))
+	newFiles, err := c.CompactFull(files)
+	if err != nil {
+		return 0, fmt.Errorf("compacting: %w", err)
+	}
+	if err := fs.Replace(files, newFiles); err != nil {
+		return 0, fmt.Errorf("replacing compacted files: %w", err)
+	}
+	return dropped, nil
+}
+
+// flushWAL writes the WAL segments of a shard to a TSM file and removes them,
+// as the engine would snapshot them once it is opened.
+func flushWAL(fs *tsm1.FileStore, c *tsm1.Compactor, sh shardDir, log *zap.Logger) error {
+	walFiles, err := sh.walFiles()
+	if err != nil || len(walFiles) == 0 {
+		return err
+	}
+
+	cache := tsm1.NewCache(0)
+	loader := tsm1.NewCacheLoader(walFiles)
+	loader.Logger = log
+	if err := loader.Load(cache); err != nil {
+		return err
+	}
+
+	if cache.Size() > 0 {
+		log.Info("Writing WAL to TSM", zap.Int("segments", len(walFiles)))
+		files, err := c.WriteSnapshot(cache)
+		if err != nil {
+			return err
+		}
+		if err := fs.Replace(nil, files); err != nil {
+			return err
+		}
+	}
+
+	for _, f := range walFiles {
+		if err := os.Remove(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropKeys tombstones the keys of the file store matching the drop predicate
+// and returns their number.
+func (r *shardRewriter) dropKeys(fs *tsm1.FileStore) (int, error) {
+	if r.drop == nil {
+		return 0, nil
+	}
+
+	var keys [][]byte
+	if err := fs.WalkKeys(nil, func(key []byte, _ byte) error {
+		// a key is walked once per file holding it
+		if len(keys) > 0 && string(keys[len(keys)-1]) == string(key) {
+			return nil
+		}
+		if r.drop.matches(key) {
+			keys = append(keys, append([]byte(nil), key...))
+		}
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	const batchSize = 10000
+	for i := 0; i < len(keys); i += batchSize {
+		j := i + batchSize
+		if j > len(keys) {
+			j = len(keys)
+		}
+		if err := fs.Delete(keys[i:j]); err != nil {
+			return 0, err
+		}
+	}
+	return len(keys), nil
+}
+
+// dropPredicate matches the TSM keys of series fields to drop.
+type dropPredicate struct {
+	measurement *regexp.Regexp
+	field       *regexp.Regexp
+}
+
+// newDropPredicate returns the predicate of the regular expressions, or nil
+// if both are empty.
+func newDropPredicate(measurement, field string) (*dropPredicate, error) {
+	if measurement == "" && field == "" {
+		return nil, nil
+	}
+
+	var (
+		p   dropPredicate
+		err error
+	)
+	if measurement != "" {
+		if p.measurement, err = regexp.Compile(measurement); err != nil {
+			return nil, fmt.Errorf("invalid --drop-measurement: %w", err)
+		}
+	}
+	if field != "" {
+		if p.field, err = regexp.Compile(field); err != nil {
+			return nil, fmt.Errorf("invalid --drop-field: %w", err)
+		}
+	}
+	return &p, nil
+}
+
+func (p *dropPredicate) matches(key []byte) bool {
+	seriesKey, field := tsm1.SeriesAndFieldFromCompositeKey(key)
+	if p.measurement != nil && !p.measurement.Match(models.ParseName(seriesKey)) {
+		return false
+	}
+	if p.field != nil && !p.field.Match(field) {
+		return false
+	}
+	return true
+}
+
+// shardUsage returns the number of TSM files of a shard and the bytes used by
+// its TSM, tombstone and WAL files and its index.
+func shardUsage(sh shardDir) (int, int64, error) {
+	tsmFiles, err := sh.tsmFiles()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var size int64
+	for _, dir := range []string{sh.path, sh.walPath} {
+		err := filepath.Walk(dir, func(path string, f os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !f.IsDir() {
+				size += f.Size()
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return 0, 0, err
+		}
+	}
+	return len(tsmFiles), size, nil
+}
@@ -0,0 +1,96 @@
+package inspect
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2/tsdb/engine/tsm1"
+)
+
+func Test_rewriteShard(t *testing.T) {
+	c := corpus{}
+	for _, cc := range []corpus{floatCorpus, intCorpus} {
+		for k, v := range cc {
+			c[k] = v
+		}
+	}
+	enginePath, _, walPath := newTestEngine(t, c)
+
+	var out bytes.Buffer
+	r := &shardRewriter{enginePath: enginePath, dropMeasurement: "^ints$"}
+	if err := r.run(&out); err != nil {
+		t.Fatalf("unexpected error: %v\n%s", err, out.String())
+	}
+
+	if !strings.Contains(out.String(), "Shards: 1, Files: 1 -> 1,") || !strings.Contains(out.String(), "Dropped Keys: 1, Series: 1\n") {
+		t.Fatalf("unexpected summary:\n%s", out.String())
+	}
+
+	if _, err := os.Stat(walPath); !os.IsNotExist(err) {
+		t.Fatalf("expected the WAL segment to be removed, got %v", err)
+	}
+
+	shards, err := loadShards(enginePath, shardFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := shards[0].tsmFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("expected a single TSM file, got %v", files)
+	}
+	if _, err := os.Stat(filepath.Join(shards[0].path, "index")); err != nil {
+		t.Fatalf("expected a rebuilt index: %v", err)
+	}
+
+	tr, err := openTSMFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tr.Close()
+
+	var keys []string
+	for i := 0; i < tr.KeyCount(); i++ {
+		key, _ := tr.KeyAt(i)
+		keys = append(keys, string(key))
+	}
+	if exp := []string{tsm1.SeriesFieldKey("floats,k=f", "f")}; !reflect.DeepEqual(keys, exp) {
+		t.Fatalf("unexpected keys: got %q, exp %q", keys, exp)
+	}
+}
+
+func Test_dropPredicate(t *testing.T) {
+	for _, tt := range []struct {
+		measurement, field string
+		key                string
+		exp                bool
+	}{
+		{measurement: "^cpu$", key: tsm1.SeriesFieldKey("cpu,host=a", "usage"), exp: true},
+		{measurement: "^cpu$", key: tsm1.SeriesFieldKey("cpu2,host=a", "usage"), exp: false},
+		{field: "^usage", key: tsm1.SeriesFieldKey("mem,host=a", "usage_idle"), exp: true},
+		{measurement: "^cpu$", field: "^usage$", key: tsm1.SeriesFieldKey("cpu,host=a", "usage"), exp: true},
+		{measurement: "^cpu$", field: "^usage$", key: tsm1.SeriesFieldKey("mem,host=a", "usage"), exp: false},
+		{measurement: "^cpu$", field: "^usage$", key: tsm1.SeriesFieldKey("cpu,host=a", "idle"), exp: false},
+	} {
+		p, err := newDropPredicate(tt.measurement, tt.field)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := p.matches([]byte(tt.key)); got != tt.exp {
+			t.Errorf("%s / %s: %q: got %v, exp %v", tt.measurement, tt.field, tt.key, got, tt.exp)
+		}
+	}
+
+	if p, err := newDropPredicate("", ""); p != nil || err != nil {
+		t.Fatalf("expected no predicate, got %v, %v", p, err)
+	}
+	if _, err := newDropPredicate("(", ""); err == nil {
+		t.Fatal("expected an invalid regular expression")
+	}
+}
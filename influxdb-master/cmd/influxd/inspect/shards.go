@@ -0,0 +1,148 @@
+package inspect
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/influxdata/influxdb/v2/internal/fs"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/influxdata/influxdb/v2/tsdb/engine/tsm1"
+	"github.com/spf13/cobra"
+)
+
+// shardDir is the location of a shard in a stopped engine.
+//
+// Shards are stored under `<engine>/data/<bucket-id>/<rp>/<shard-id>`, their
+// WAL under `<engine>/wal/<bucket-id>/<rp>/<shard-id>` and the series file of
+// a bucket under `<engine>/data/<bucket-id>/_series`.
+type shardDir struct {
+	bucketID string
+	rp       string
+	id       uint64
+	path     string
+	walPath  string
+}
+
+// seriesFilePath returns the path of the series file of the shard's bucket.
+func (s shardDir) seriesFilePath(enginePath string) string {
+	return filepath.Join(enginePath, "data", s.bucketID, tsdb.SeriesFileDirectory)
+}
+
+// tsmFiles returns the TSM files of the shard in the order the file store opens them.
+func (s shardDir) tsmFiles() ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(s.path, "*."+tsm1.TSMFileExtension))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// walFiles returns the WAL segments of the shard in the order the WAL replays them.
+func (s shardDir) walFiles() ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(s.walPath, "*."+tsm1.WALFileExtension))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// shardFilter restricts the shards of an engine to a bucket and / or a shard.
+type shardFilter struct {
+	bucketID string
+	shardID  uint64
+}
+
+func (f *shardFilter) registerFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.bucketID, "bucket-id", "", "optional: only inspect the shards of this bucket")
+	cmd.Flags().Uint64Var(&f.shardID, "shard-id", 0, "optional: only inspect this shard")
+}
+
+// loadShards returns the shards of the engine that match the filter, ordered
+// by bucket, retention policy and shard id.
+func loadShards(enginePath string, filter shardFilter) ([]shardDir, error) {
+	dataPath := filepath.Join(enginePath, "data")
+	buckets, err := ioutil.ReadDir(dataPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("engine data directory %q does not exist (use --engine-path to set the engine path)", dataPath)
+		}
+		return nil, err
+	}
+
+	var shards []shardDir
+	for _, b := range buckets {
+		if !b.IsDir() || (filter.bucketID != "" && b.Name() != filter.bucketID) {
+			continue
+		}
+		rps, err := ioutil.ReadDir(filepath.Join(dataPath, b.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, rp := range rps {
+			if !rp.IsDir() || rp.Name() == tsdb.SeriesFileDirectory {
+				continue
+			}
+			ids, err := ioutil.ReadDir(filepath.Join(dataPath, b.Name(), rp.Name()))
+			if err != nil {
+				return nil, err
+			}
+			for _, sh := range ids {
+				id, err := strconv.ParseUint(sh.Name(), 10, 64)
+				if !sh.IsDir() || err != nil || (filter.shardID != 0 && id != filter.shardID) {
+					continue
+				}
+				shards = append(shards, shardDir{
+					bucketID: b.Name(),
+					rp:       rp.Name(),
+					id:       id,
+					path:     filepath.Join(dataPath, b.Name(), rp.Name(), sh.Name()),
+					walPath:  filepath.Join(enginePath, "wal", b.Name(), rp.Name(), sh.Name()),
+				})
+			}
+		}
+	}
+
+	sort.Slice(shards, func(i, j int) bool {
+		if shards[i].bucketID != shards[j].bucketID {
+			return shards[i].bucketID < shards[j].bucketID
+		}
+		if shards[i].rp != shards[j].rp {
+			return shards[i].rp < shards[j].rp
+		}
+		return shards[i].id < shards[j].id
+	})
+	return shards, nil
+}
+
+// defaultEnginePath is the engine path of influxd when it runs with its defaults.
+func defaultEnginePath() string {
+	dir, err := fs.InfluxDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "engine")
+}
+
+func registerEnginePathFlag(cmd *cobra.Command, enginePath *string) {
+	cmd.Flags().StringVar(enginePath, "engine-path", defaultEnginePath(), "Path to the engine files of a stopped influxd")
+}
+
+// openTSMFile opens a TSM file for reading.
+func openTSMFile(path string) (*tsm1.TSMReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	r, err := tsm1.NewTSMReader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return r, nil
+}
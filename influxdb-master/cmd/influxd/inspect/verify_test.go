@@ -0,0 +1,261 @@
+package inspect
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/influxdata/influxdb/v2/tsdb/engine/tsm1"
+	"github.com/spf13/cobra"
+)
+
+// newTestEngine creates an engine directory with one shard of bucket
+// 0000000000000001 holding a TSM file and a WAL segment of the corpus.
+func newTestEngine(t *testing.T, c corpus) (enginePath string, tsmPath string, walPath string) {
+	t.Helper()
+
+	enginePath, err := ioutil.TempDir("", "inspect_engine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(enginePath) })
+
+	shardPath := filepath.Join(enginePath, "data", "0000000000000001", "autogen", "1")
+	walShardPath := filepath.Join(enginePath, "wal", "0000000000000001", "autogen", "1")
+	for _, p := range []string{shardPath, walShardPath} {
+		if err := os.MkdirAll(p, 0777); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tsmFile, err := writeCorpusToTSMFile(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tsmFile.Close()
+	tsmPath = filepath.Join(shardPath, "000000001-000000001.tsm")
+	if err := os.Rename(tsmFile.Name(), tsmPath); err != nil {
+		t.Fatal(err)
+	}
+
+	walFile, err := writeCorpusToWALFile(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	walFile.Close()
+	walPath = filepath.Join(walShardPath, "_00001.wal")
+	if err := os.Rename(walFile.Name(), walPath); err != nil {
+		t.Fatal(err)
+	}
+
+	return enginePath, tsmPath, walPath
+}
+
+func Test_loadShards(t *testing.T) {
+	enginePath, _, _ := newTestEngine(t, floatCorpus)
+
+	shards, err := loadShards(enginePath, shardFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shards) != 1 || shards[0].bucketID != "0000000000000001" || shards[0].rp != "autogen" || shards[0].id != 1 {
+		t.Fatalf("unexpected shards: %+v", shards)
+	}
+
+	shards, err = loadShards(enginePath, shardFilter{shardID: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shards) != 0 {
+		t.Fatalf("expected no shards, got %+v", shards)
+	}
+
+	if _, err := loadShards(filepath.Join(enginePath, "missing"), shardFilter{}); err == nil {
+		t.Fatal("expected an error for a missing engine")
+	}
+}
+
+func Test_verifyTSM(t *testing.T) {
+	enginePath, tsmPath, _ := newTestEngine(t, intCorpus)
+
+	var out bytes.Buffer
+	v := &tsmVerifier{enginePath: enginePath}
+	if err := v.run(&out); err != nil {
+		t.Fatalf("unexpected error: %v\n%s", err, out.String())
+	}
+
+	// flip a byte of the first block, after the 5 byte header and its checksum
+	buf, err := ioutil.ReadFile(tsmPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf[10] ^= 0xff
+	if err := ioutil.WriteFile(tsmPath, buf, 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	out.Reset()
+	if err := v.run(&out); err == nil {
+		t.Fatalf("expected a broken block\n%s", out.String())
+	}
+}
+
+func Test_verifyWAL(t *testing.T) {
+	enginePath, _, walPath := newTestEngine(t, boolCorpus)
+
+	var out bytes.Buffer
+	v := &walVerifier{enginePath: enginePath}
+	if err := v.run(&out); err != nil {
+		t.Fatalf("unexpected error: %v\n%s", err, out.String())
+	}
+
+	stats, err := verifyWALSegment(walPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.writes != 1 || stats.points != 2 || stats.minTime != 100 || stats.maxTime != 200 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+
+	// truncate the last entry
+	if err := os.Truncate(walPath, stats.size-1); err != nil {
+		t.Fatal(err)
+	}
+
+	out.Reset()
+	if err := v.run(&out); err == nil {
+		t.Fatalf("expected a corrupt segment\n%s", out.String())
+	}
+}
+
+// executeCommand runs cmd with args and returns its output.
+func executeCommand(cmd *cobra.Command, args ...string) (string, error) {
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func Test_verifyCommands(t *testing.T) {
+	enginePath, tsmPath, walPath := newTestEngine(t, floatCorpus)
+
+	tombstoner := tsm1.NewTombstoner(tsmPath, nil)
+	// a deleted series that is no longer part of the TSM file
+	if err := tombstoner.Add([][]byte{[]byte("floats,k=g#!~#f")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tombstoner.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	seriesPath := filepath.Join(enginePath, "data", "0000000000000001", tsdb.SeriesFileDirectory)
+	sfile := tsdb.NewSeriesFile(seriesPath)
+	if err := sfile.Open(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sfile.CreateSeriesListIfNotExists([][]byte{[]byte("floats")}, []models.Tags{models.NewTags(map[string]string{"k": "f"})}); err != nil {
+		t.Fatal(err)
+	}
+	if err := sfile.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		cmd  func() *cobra.Command
+		args []string
+		want []string
+	}{
+		{
+			name: "verify-tsm",
+			cmd:  NewVerifyTSMCommand,
+			want: []string{tsmPath + ": healthy", "Broken Blocks: 0 / 1"},
+		},
+		{
+			name: "verify-tsm with utf8 check",
+			cmd:  NewVerifyTSMCommand,
+			args: []string{"--check-utf8"},
+			want: []string{tsmPath + ": healthy", "Invalid Keys: 0 / 1"},
+		},
+		{
+			name: "verify-wal",
+			cmd:  NewVerifyWALCommand,
+			args: []string{"-v"},
+			want: []string{walPath + ": healthy", "writes: 1, deletes: 0, delete ranges: 0, points: 2", "Corrupt Segments: 0 / 1"},
+		},
+		{
+			name: "verify-tombstone",
+			cmd:  NewVerifyTombstoneCommand,
+			want: []string{"Verified 1 entries"},
+		},
+		{
+			name: "verify-seriesfile",
+			cmd:  NewVerifySeriesFileCommand,
+			want: []string{seriesPath + ": healthy"},
+		},
+		{
+			name: "verify-seriesfile of another bucket",
+			cmd:  NewVerifySeriesFileCommand,
+			args: []string{"--bucket-id", "0000000000000002"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := executeCommand(tt.cmd(), append([]string{"--engine-path", enginePath}, tt.args...)...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v\n%s", err, out)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("expected output to contain %q\n%s", want, out)
+				}
+			}
+		})
+	}
+
+	t.Run("corrupt files", func(t *testing.T) {
+		tombstonePath := strings.TrimSuffix(tsmPath, ".tsm") + "." + tsm1.TombstoneFileExtension
+		buf, err := ioutil.ReadFile(tombstonePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// keep the header, replace the compressed entries
+		if err := ioutil.WriteFile(tombstonePath, append(buf[:4], "BOGUS"...), 0666); err != nil {
+			t.Fatal(err)
+		}
+		out, err := executeCommand(NewVerifyTombstoneCommand(), "--engine-path", enginePath)
+		if err == nil {
+			t.Fatalf("expected a corrupt tombstone file\n%s", out)
+		}
+
+		segmentPath := filepath.Join(seriesPath, "00", "0000")
+		if err := ioutil.WriteFile(segmentPath, []byte("BOGUS"), 0666); err != nil {
+			t.Fatal(err)
+		}
+		out, err = executeCommand(NewVerifySeriesFileCommand(), "--engine-path", enginePath)
+		if err == nil || !strings.Contains(out, seriesPath+": corrupt") {
+			t.Fatalf("expected a corrupt series file, got %v\n%s", err, out)
+		}
+	})
+
+	t.Run("missing engine", func(t *testing.T) {
+		missing := filepath.Join(enginePath, "missing")
+		for _, cmd := range []*cobra.Command{
+			NewVerifyTSMCommand(),
+			NewVerifyWALCommand(),
+			NewVerifySeriesFileCommand(),
+		} {
+			if out, err := executeCommand(cmd, "--engine-path", missing); err == nil {
+				t.Errorf("%s: expected an error for a missing engine\n%s", cmd.Name(), out)
+			}
+		}
+	})
+}
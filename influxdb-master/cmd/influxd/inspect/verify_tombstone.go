@@ -0,0 +1,107 @@
+package inspect
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/tsdb/engine/tsm1"
+	"github.com/spf13/cobra"
+)
+
+// NewVerifyTombstoneCommand builds the `verify-tombstone` subcommand of `influxd inspect`.
+func NewVerifyTombstoneCommand() *cobra.Command {
+	v := &tombstoneVerifier{}
+	cmd := &cobra.Command{
+		Use:   "verify-tombstone",
+		Short: "Verify the integrity of tombstone files",
+		Long: `
+This command verifies that every entry of the tombstone files of a stopped
+engine can be read. Repeat -v to increase the verbosity: -v emits periodic
+progress, -vv every entry's key and time range and -vvv the time range in
+RFC3339Nano format.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return v.run(cmd.OutOrStdout())
+		},
+	}
+
+	registerEnginePathFlag(cmd, &v.enginePath)
+	cmd.Flags().CountVarP(&v.verbosity, "verbose", "v", "Increase the verbosity, may be repeated")
+
+	return cmd
+}
+
+const (
+	quiet = iota
+	verbose
+	veryVerbose
+	veryVeryVerbose
+)
+
+type tombstoneVerifier struct {
+	enginePath string
+	verbosity  int
+}
+
+func (v *tombstoneVerifier) run(out io.Writer) error {
+	var files []string
+	err := filepath.Walk(filepath.Join(v.enginePath, "data"), func(path string, f os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if filepath.Ext(path) == "."+tsm1.TombstoneFileExtension {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var failed bool
+	start := time.Now()
+	for _, f := range files {
+		if v.verbosity > quiet {
+			fmt.Fprintf(out, "Verifying: %q\n", f)
+		}
+
+		tombstoner := tsm1.NewTombstoner(f, nil)
+		if !tombstoner.HasTombstones() {
+			fmt.Fprintf(out, "%s has no tombstone entries\n", f)
+			continue
+		}
+
+		var totalEntries int64
+		err := tombstoner.Walk(func(t tsm1.Tombstone) error {
+			totalEntries++
+			if v.verbosity > quiet && totalEntries%(10*1e6) == 0 {
+				fmt.Fprintf(out, "Verified %d tombstone entries\n", totalEntries)
+			} else if v.verbosity > verbose {
+				var min interface{} = t.Min
+				var max interface{} = t.Max
+				if v.verbosity > veryVerbose {
+					min = time.Unix(0, t.Min).UTC().Format(time.RFC3339Nano)
+					max = time.Unix(0, t.Max).UTC().Format(time.RFC3339Nano)
+				}
+				fmt.Fprintf(out, "key: %q, min: %v, max: %v\n", t.Key, min, max)
+			}
+			return nil
+		})
+		if err != nil {
+			fmt.Fprintf(out, "%q failed to walk tombstone entries: %v. Last okay entry: %d\n", f, err, totalEntries)
+			failed = true
+			continue
+		}
+
+		fmt.Fprintf(out, "Completed verification for %q in %v.\nVerified %d entries\n\n", f, time.Since(start), totalEntries)
+	}
+
+	if failed {
+		return errors.New("failed tombstone verification")
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package inspect
+
+import (
+	"fmt"
+	"hash/crc32"
+	"io"
+	"time"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+)
+
+// NewVerifyTSMCommand builds the `verify-tsm` subcommand of `influxd inspect`.
+func NewVerifyTSMCommand() *cobra.Command {
+	v := &tsmVerifier{}
+	cmd := &cobra.Command{
+		Use:   "verify-tsm",
+		Short: "Verify the integrity of TSM files",
+		Long: `
+This command verifies the checksum of every block of the TSM files of a
+stopped engine. Use --check-utf8 to verify that the series keys are valid
+UTF-8 instead.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return v.run(cmd.OutOrStdout())
+		},
+	}
+
+	registerEnginePathFlag(cmd, &v.enginePath)
+	v.filter.registerFlags(cmd)
+	cmd.Flags().BoolVar(&v.checkUTF8, "check-utf8", false, "Verify series keys are valid UTF-8, this check skips verification of block checksums")
+
+	return cmd
+}
+
+type tsmVerifier struct {
+	enginePath string
+	filter     shardFilter
+	checkUTF8  bool
+}
+
+func (v *tsmVerifier) run(out io.Writer) error {
+	shards, err := loadShards(v.enginePath, v.filter)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	var total, broken int
+	for _, sh := range shards {
+		files, err := sh.tsmFiles()
+		if err != nil {
+			return err
+		}
+		for _, f := range files {
+			var n, errs int
+			if v.checkUTF8 {
+				n, errs, err = verifyTSMKeys(out, f)
+			} else {
+				n, errs, err = verifyTSMChecksums(out, f)
+			}
+			total += n
+			broken += errs
+			if err != nil {
+				fmt.Fprintf(out, "%s: %v\n", f, err)
+				broken++
+				continue
+			}
+			if errs == 0 {
+				fmt.Fprintf(out, "%s: healthy\n", f)
+			}
+		}
+	}
+
+	if v.checkUTF8 {
+		fmt.Fprintf(out, "Invalid Keys: %d / %d, in %vs\n", broken, total, time.Since(start).Seconds())
+	} else {
+		fmt.Fprintf(out, "Broken Blocks: %d / %d, in %vs\n", broken, total, time.Since(start).Seconds())
+	}
+	if broken > 0 {
+		return fmt.Errorf("verify-tsm: %d problems found", broken)
+	}
+	return nil
+}
+
+// verifyTSMChecksums verifies the checksums of the blocks of a TSM file,
+// it returns the number of blocks and the number of broken blocks.
+func verifyTSMChecksums(out io.Writer, path string) (total, broken int, err error) {
+	r, err := openTSMFile(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer r.Close()
+
+	iter := r.BlockIterator()
+	for iter.Next() {
+		total++
+		key, _, _, _, checksum, buf, err := iter.Read()
+		if err != nil {
+			broken++
+			fmt.Fprintf(out, "%s: could not get checksum for key %q block %d due to error: %q\n", path, key, total-1, err)
+			// the iterator stops at the first read error
+			break
+		}
+		if expected := crc32.ChecksumIEEE(buf); checksum != expected {
+			broken++
+			fmt.Fprintf(out, "%s: got %d but expected %d for key %q, block %d\n", path, checksum, expected, key, total-1)
+		}
+	}
+	return total, broken, nil
+}
+
+// verifyTSMKeys verifies the keys of a TSM file are valid UTF-8, it returns
+// the number of keys and the number of invalid keys.
+func verifyTSMKeys(out io.Writer, path string) (total, invalid int, err error) {
+	r, err := openTSMFile(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer r.Close()
+
+	total = r.KeyCount()
+	for i := 0; i < total; i++ {
+		key, _ := r.KeyAt(i)
+		if !utf8.Valid(key) {
+			invalid++
+			fmt.Fprintf(out, "%s: key #%d is not valid UTF-8\n", path, i)
+		}
+	}
+	return total, invalid, nil
+}
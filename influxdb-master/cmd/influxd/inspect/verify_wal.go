@@ -0,0 +1,182 @@
+package inspect
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/tsdb/engine/tsm1"
+	"github.com/spf13/cobra"
+)
+
+// NewVerifyWALCommand builds the `verify-wal` subcommand of `influxd inspect`.
+func NewVerifyWALCommand() *cobra.Command {
+	v := &walVerifier{}
+	cmd := &cobra.Command{
+		Use:   "verify-wal",
+		Short: "Verify the integrity of WAL segments",
+		Long: `
+This command verifies that every entry of the WAL segments of a stopped engine
+can be read, and reports the number of entries, points and the time range of
+every segment. A corrupt segment is reported along with the offset up to
+which it can be replayed.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return v.run(cmd.OutOrStdout())
+		},
+	}
+
+	registerEnginePathFlag(cmd, &v.enginePath)
+	v.filter.registerFlags(cmd)
+	cmd.Flags().BoolVarP(&v.verbose, "verbose", "v", false, "Report the statistics of every segment")
+
+	return cmd
+}
+
+type walVerifier struct {
+	enginePath string
+	filter     shardFilter
+	verbose    bool
+}
+
+// walSegmentStats are the statistics of a WAL segment.
+type walSegmentStats struct {
+	writes, deletes, deleteRanges int
+	points                        int
+	minTime, maxTime              int64
+	size                          int64
+	// valid is the number of bytes of the segment that can be replayed
+	valid int64
+	err   error
+}
+
+// walSegmentFiles returns the WAL segments of the engine that match the filter.
+func walSegmentFiles(enginePath string, filter shardFilter) ([]string, error) {
+	walPath := filepath.Join(enginePath, "wal")
+	var files []string
+	err := filepath.Walk(walPath, func(path string, f os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if f.IsDir() || filepath.Ext(path) != "."+tsm1.WALFileExtension {
+			return nil
+		}
+		// <bucket-id>/<rp>/<shard-id>/<segment>
+		rel, err := filepath.Rel(walPath, path)
+		if err != nil {
+			return err
+		}
+		parts := strings.Split(filepath.ToSlash(rel), "/")
+		if len(parts) == 4 {
+			if filter.bucketID != "" && parts[0] != filter.bucketID {
+				return nil
+			}
+			if id, err := strconv.ParseUint(parts[2], 10, 64); filter.shardID != 0 && (err != nil || id != filter.shardID) {
+				return nil
+			}
+		}
+		files = append(files, path)
+		return nil
+	})
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("engine WAL directory %q does not exist (use --engine-path to set the engine path)", walPath)
+		}
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func verifyWALSegment(path string) (*walSegmentStats, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	stats := &walSegmentStats{
+		minTime: math.MaxInt64,
+		maxTime: math.MinInt64,
+		size:    fi.Size(),
+	}
+	r := tsm1.NewWALSegmentReader(f)
+	defer r.Close()
+
+	for r.Next() {
+		entry, err := r.Read()
+		if err != nil {
+			stats.err = err
+			break
+		}
+		switch e := entry.(type) {
+		case *tsm1.WriteWALEntry:
+			stats.writes++
+			for _, values := range e.Values {
+				stats.points += len(values)
+				for _, v := range values {
+					ts := v.UnixNano()
+					if ts < stats.minTime {
+						stats.minTime = ts
+					}
+					if ts > stats.maxTime {
+						stats.maxTime = ts
+					}
+				}
+			}
+		case *tsm1.DeleteWALEntry:
+			stats.deletes++
+		case *tsm1.DeleteRangeWALEntry:
+			stats.deleteRanges++
+		}
+	}
+	stats.valid = r.Count()
+	return stats, nil
+}
+
+func (v *walVerifier) run(out io.Writer) error {
+	files, err := walSegmentFiles(v.enginePath, v.filter)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	var corrupt, entries, points int
+	for _, f := range files {
+		stats, err := verifyWALSegment(f)
+		if err != nil {
+			return err
+		}
+		entries += stats.writes + stats.deletes + stats.deleteRanges
+		points += stats.points
+
+		if stats.err != nil {
+			corrupt++
+			fmt.Fprintf(out, "%s: corrupt entry after %d of %d bytes: %v\n", f, stats.valid, stats.size, stats.err)
+		} else {
+			fmt.Fprintf(out, "%s: healthy\n", f)
+		}
+		if v.verbose {
+			fmt.Fprintf(out, "  writes: %d, deletes: %d, delete ranges: %d, points: %d, time range: %s - %s\n",
+				stats.writes, stats.deletes, stats.deleteRanges, stats.points,
+				formatTime(stats.minTime, stats.points > 0), formatTime(stats.maxTime, stats.points > 0),
+			)
+		}
+	}
+
+	fmt.Fprintf(out, "Corrupt Segments: %d / %d, Entries: %d, Points: %d, in %vs\n", corrupt, len(files), entries, points, time.Since(start).Seconds())
+	if corrupt > 0 {
+		return fmt.Errorf("verify-wal: %d corrupt segments", corrupt)
+	}
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/influxdata/influxdb/v2/bolt"
 	"github.com/influxdata/influxdb/v2/fluxinit"
+	"github.com/influxdata/influxdb/v2/http"
 	"github.com/influxdata/influxdb/v2/internal/fs"
 	"github.com/influxdata/influxdb/v2/kit/cli"
 	"github.com/influxdata/influxdb/v2/kit/signals"
@@ -145,12 +146,16 @@ type InfluxdOpts struct {
 	SessionLength         int // in minutes
 	SessionRenewDisabled  bool
 
+	PrometheusRemoteMaxBodyBytes int64
+
 	ProfilingDisabled bool
 	MetricsDisabled   bool
 
 	NatsPort            int
 	NatsMaxPayloadBytes int
 
+	Listeners []string
+
 	NoTasks      bool
 	FeatureFlags map[string]string
 
@@ -194,6 +199,8 @@ func NewOpts(viper *viper.Viper) *InfluxdOpts {
 		SessionLength:         60, // 60 minutes
 		SessionRenewDisabled:  false,
 
+		PrometheusRemoteMaxBodyBytes: http.DefaultPrometheusRemoteMaxBodyBytes,
+
 		ProfilingDisabled: false,
 		MetricsDisabled:   false,
 
@@ -290,6 +297,12 @@ func (o *InfluxdOpts) BindCliOpts() []cli.Opt {
 			Default: o.SessionRenewDisabled,
 			Desc:    "disables automatically extending session ttl on request",
 		},
+		{
+			DestP:   &o.PrometheusRemoteMaxBodyBytes,
+			Flag:    "prometheus-remote-max-body-bytes",
+			Default: o.PrometheusRemoteMaxBodyBytes,
+			Desc:    "maximum number of bytes of the body of a Prometheus remote write or read request, before and after it is decompressed",
+		},
 		{
 			DestP: &o.VaultConfig.Address,
 			Flag:  "vault-addr",
@@ -436,6 +449,11 @@ func (o *InfluxdOpts) BindCliOpts() []cli.Opt {
 			Flag:  "feature-flags",
 			Desc:  "feature flag overrides",
 		},
+		{
+			DestP: &o.Listeners,
+			Flag:  "listeners",
+			Desc:  "Listeners that write the points they receive to a bucket, as <udp|mqtt|mqtts|graphite|opentsdb>://[<user>:<password>@]<address>?org=<org>&bucket=<bucket>&token=<token>[&<option>=<value>...]. The address is the address to listen on, or of the MQTT broker. Options are name, batch-size, batch-timeout, precision (udp, mqtt), topic, qos, client-id, topic-tag (mqtt), separator and template (graphite).",
+		},
 
 		// storage configuration
 		{
@@ -443,6 +461,11 @@ func (o *InfluxdOpts) BindCliOpts() []cli.Opt {
 			Flag:  "storage-wal-fsync-delay",
 			Desc:  "The amount of time that a write will wait before fsyncing. A duration greater than 0 can be used to batch up multiple fsync calls. This is useful for slower disks or when WAL write contention is seen.",
 		},
+		{
+			DestP: &o.StorageConfig.Data.WALDisabledBuckets,
+			Flag:  "storage-wal-disabled-buckets",
+			Desc:  "IDs of the buckets whose shards do not write a WAL, where * matches any bucket. Writes to them are only held by the cache until it is snapshotted to a TSM file, at least every storage-cache-snapshot-interval, and writes received since the last snapshot are lost on a crash. Lost writes are reported on startup.",
+		},
 		{
 			DestP: &o.StorageConfig.Data.ValidateKeys,
 			Flag:  "storage-validate-keys",
@@ -463,6 +486,26 @@ func (o *InfluxdOpts) BindCliOpts() []cli.Opt {
 			Flag:  "storage-cache-snapshot-write-cold-duration",
 			Desc:  "The length of time at which the engine will snapshot the cache and write it to a new TSM file if the shard hasn't received writes or deletes.",
 		},
+		{
+			DestP: &o.StorageConfig.Data.CacheSnapshotInterval,
+			Flag:  "storage-cache-snapshot-interval",
+			Desc:  "The maximum age of a write held by the cache before the engine snapshots the cache to a TSM file when the WAL is disabled. 0 disables the interval.",
+		},
+		{
+			DestP: &o.StorageConfig.Data.FloatEncodings,
+			Flag:  "storage-float-encodings",
+			Desc:  "Compressions of the values of float fields, as <bucket-id>[/<measurement>[/<field>]]=<gorilla|bss|quantized:<precision>>, where * matches any bucket, measurement or field. The most specific rule applies. Existing TSM blocks are re-encoded when they are compacted.",
+		},
+		{
+			DestP: &o.StorageConfig.Data.CompactionPlanners,
+			Flag:  "storage-compaction-planners",
+			Desc:  "Compaction planners of the shards of buckets, as <bucket-id>=<planner>, where * matches any bucket and the planner is leveled[:<cold-duration>], time-window:<window> or aggressive-optimize[:<cold-duration>]. Shards of other buckets use the leveled planner.",
+		},
+		{
+			DestP: &o.StorageConfig.Data.UsageTagKey,
+			Flag:  "storage-usage-tag-key",
+			Desc:  "The tag key by which the disk usage of the series of a measurement is accounted as TSM files are compacted, and reported by bucket usage and the storage_bucket_usage_bytes metric.",
+		},
 		{
 			DestP: &o.StorageConfig.Data.CompactFullWriteColdDuration,
 			Flag:  "storage-compact-full-write-cold-duration",
@@ -30,6 +30,9 @@ type Engine interface {
 	prom.PrometheusCollector
 	influxdb.BackupService
 	influxdb.RestoreService
+	influxdb.CompactionService
+	influxdb.DigestService
+	influxdb.StorageUsageService
 
 	SeriesCardinality(orgID, bucketID platform.ID) int64
 
@@ -125,6 +128,11 @@ func (t *TemporaryEngine) DeleteBucketRangePredicate(ctx context.Context, orgID,
 	return t.engine.DeleteBucketRangePredicate(ctx, orgID, bucketID, min, max, pred)
 }
 
+// DeleteBucketRangePredicateDryRun returns the series that a delete from the range and predicate would affect.
+func (t *TemporaryEngine) DeleteBucketRangePredicateDryRun(ctx context.Context, orgID, bucketID platform.ID, min, max int64, pred influxdb.Predicate, limit int) (*influxdb.DeleteDryRun, error) {
+	return t.engine.DeleteBucketRangePredicateDryRun(ctx, orgID, bucketID, min, max, pred, limit)
+}
+
 func (t *TemporaryEngine) CreateBucket(ctx context.Context, b *influxdb.Bucket) error {
 	return t.engine.CreateBucket(ctx, b)
 }
@@ -133,6 +141,11 @@ func (t *TemporaryEngine) UpdateBucketRetentionPolicy(ctx context.Context, bucke
 	return t.engine.UpdateBucketRetentionPolicy(ctx, bucketID, upd)
 }
 
+// SetBucketCardinalityLimits sets the cardinality limits of a bucket.
+func (t *TemporaryEngine) SetBucketCardinalityLimits(ctx context.Context, b *influxdb.Bucket) error {
+	return t.engine.SetBucketCardinalityLimits(ctx, b)
+}
+
 // DeleteBucket deletes a bucket from the time-series data.
 func (t *TemporaryEngine) DeleteBucket(ctx context.Context, orgID, bucketID platform.ID) error {
 	return t.engine.DeleteBucket(ctx, orgID, bucketID)
@@ -180,6 +193,38 @@ func (t *TemporaryEngine) RestoreShard(ctx context.Context, shardID uint64, r io
 	return t.engine.RestoreShard(ctx, shardID, r)
 }
 
+func (t *TemporaryEngine) FindShardCompactions(ctx context.Context, bucketID *platform.ID) ([]*influxdb.ShardCompaction, error) {
+	return t.engine.FindShardCompactions(ctx, bucketID)
+}
+
+func (t *TemporaryEngine) PauseShardCompactions(ctx context.Context, shardID uint64) error {
+	return t.engine.PauseShardCompactions(ctx, shardID)
+}
+
+func (t *TemporaryEngine) ResumeShardCompactions(ctx context.Context, shardID uint64) error {
+	return t.engine.ResumeShardCompactions(ctx, shardID)
+}
+
+func (t *TemporaryEngine) TriggerShardCompaction(ctx context.Context, shardID uint64) error {
+	return t.engine.TriggerShardCompaction(ctx, shardID)
+}
+
+func (t *TemporaryEngine) BucketDigest(ctx context.Context, bucketID platform.ID, opts influxdb.DigestOptions, w io.Writer) error {
+	return t.engine.BucketDigest(ctx, bucketID, opts, w)
+}
+
+func (t *TemporaryEngine) ExportDigestRanges(ctx context.Context, bucketID platform.ID, ranges []influxdb.DigestRange, w io.Writer) error {
+	return t.engine.ExportDigestRanges(ctx, bucketID, ranges, w)
+}
+
+func (t *TemporaryEngine) DeleteDigestRanges(ctx context.Context, bucketID platform.ID, ranges []influxdb.DigestRange) error {
+	return t.engine.DeleteDigestRanges(ctx, bucketID, ranges)
+}
+
+func (t *TemporaryEngine) FindBucketStorageUsage(ctx context.Context, bucketID platform.ID, tagKey string) (*influxdb.BucketStorageUsage, error) {
+	return t.engine.FindBucketStorageUsage(ctx, bucketID, tagKey)
+}
+
 func (t *TemporaryEngine) TSDBStore() storage.TSDBStore {
 	return &t.tsdbStore
 }
@@ -17,12 +17,14 @@ import (
 	"github.com/influxdata/flux"
 	"github.com/influxdata/flux/dependencies/testing"
 	platform "github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/annotations"
 	"github.com/influxdata/influxdb/v2/authorization"
 	"github.com/influxdata/influxdb/v2/authorizer"
 	"github.com/influxdata/influxdb/v2/bolt"
 	"github.com/influxdata/influxdb/v2/checks"
 	"github.com/influxdata/influxdb/v2/chronograf/server"
 	"github.com/influxdata/influxdb/v2/dashboards"
+	"github.com/influxdata/influxdb/v2/dashboards/snapshot"
 	dashboardTransport "github.com/influxdata/influxdb/v2/dashboards/transport"
 	"github.com/influxdata/influxdb/v2/dbrp"
 	"github.com/influxdata/influxdb/v2/gather"
@@ -42,7 +44,9 @@ import (
 	"github.com/influxdata/influxdb/v2/kv/migration"
 	"github.com/influxdata/influxdb/v2/kv/migration/all"
 	"github.com/influxdata/influxdb/v2/label"
+	"github.com/influxdata/influxdb/v2/listener"
 	"github.com/influxdata/influxdb/v2/nats"
+	"github.com/influxdata/influxdb/v2/notebooks"
 	notebookTransport "github.com/influxdata/influxdb/v2/notebooks/transport"
 	endpointservice "github.com/influxdata/influxdb/v2/notification/endpoint/service"
 	ruleservice "github.com/influxdata/influxdb/v2/notification/rule/service"
@@ -58,6 +62,7 @@ import (
 	"github.com/influxdata/influxdb/v2/source"
 	"github.com/influxdata/influxdb/v2/storage"
 	storageflux "github.com/influxdata/influxdb/v2/storage/flux"
+	"github.com/influxdata/influxdb/v2/storage/reads"
 	"github.com/influxdata/influxdb/v2/storage/readservice"
 	taskbackend "github.com/influxdata/influxdb/v2/task/backend"
 	"github.com/influxdata/influxdb/v2/task/backend/coordinator"
@@ -78,6 +83,7 @@ import (
 	iqlcoordinator "github.com/influxdata/influxdb/v2/v1/coordinator"
 	"github.com/influxdata/influxdb/v2/v1/services/meta"
 	storage2 "github.com/influxdata/influxdb/v2/v1/services/storage"
+	"github.com/influxdata/influxdb/v2/variable"
 	"github.com/influxdata/influxdb/v2/vault"
 	pzap "github.com/influxdata/influxdb/v2/zap"
 	"github.com/opentracing/opentracing-go"
@@ -123,8 +129,11 @@ type Launcher struct {
 	natsServer *nats.Server
 	natsPort   int
 
+	listeners *listener.Service
+
 	scheduler          stoppingScheduler
 	executor           *executor.Executor
+	reportScheduler    stoppingScheduler
 	taskControlService taskbackend.TaskControlService
 
 	jaegerTracerCloser io.Closer
@@ -175,6 +184,17 @@ func (m *Launcher) Shutdown(ctx context.Context) error {
 
 	m.scheduler.Stop()
 
+	m.log.Info("Stopping", zap.String("service", "snapshot-reports"))
+	m.reportScheduler.Stop()
+
+	if m.listeners != nil {
+		m.log.Info("Stopping", zap.String("service", "listeners"))
+		if err := m.listeners.Close(); err != nil {
+			m.log.Error("Failed closing listeners", zap.Error(err))
+			errs = append(errs, err.Error())
+		}
+	}
+
 	m.log.Info("Stopping", zap.String("service", "nats"))
 	m.natsServer.Close()
 
@@ -414,10 +434,11 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 		pointsWriter   storage.PointsWriter    = m.engine
 		backupService  platform.BackupService  = m.engine
 		restoreService platform.RestoreService = m.engine
+		readsStore     reads.Store             = storage2.NewStore(m.engine.TSDBStore(), m.engine.MetaClient())
 	)
 
 	deps, err := influxdb.NewDependencies(
-		storageflux.NewReader(storage2.NewStore(m.engine.TSDBStore(), m.engine.MetaClient())),
+		storageflux.NewReader(readsStore),
 		m.engine,
 		authorizer.NewBucketService(ts.BucketService),
 		authorizer.NewOrgService(ts.OrganizationService),
@@ -610,6 +631,7 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 		m.log.Error("Failed to create scraper subscriber", zap.Error(err))
 		return err
 	}
+	m.reg.MustRegister(scraperScheduler.Health.PrometheusCollectors()...)
 
 	m.wg.Add(1)
 	go func(log *zap.Logger) {
@@ -658,8 +680,26 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 		labelSvc = label.NewService(labelsStore)
 	}
 
-	ts.BucketService = storage.NewBucketService(m.log, ts.BucketService, m.engine)
-	ts.BucketService = dbrp.NewBucketService(m.log, ts.BucketService, dbrpSvc)
+	storageBucketSvc := storage.NewBucketService(m.log, ts.BucketService, m.engine)
+	if err := storageBucketSvc.LoadCardinalityLimits(ctx); err != nil {
+		m.log.Error("Failed to load bucket cardinality limits", zap.Error(err))
+		return err
+	}
+	ts.BucketService = dbrp.NewBucketService(m.log, storageBucketSvc, dbrpSvc)
+
+	if len(opts.Listeners) > 0 {
+		configs, err := listener.ParseConfigs(opts.Listeners)
+		if err != nil {
+			m.log.Error("Failed to parse listeners", zap.Error(err))
+			return err
+		}
+		m.listeners = listener.NewService(m.log.With(zap.String("service", "listeners")), configs, pointsWriter, authSvc, ts.OrganizationService, ts.BucketService)
+		if err := m.listeners.Open(); err != nil {
+			m.log.Error("Failed to open listeners", zap.Error(err))
+			return err
+		}
+		m.reg.MustRegister(m.listeners.PrometheusCollectors()...)
+	}
 
 	onboardingLogger := m.log.With(zap.String("handler", "onboard"))
 	onboardOpts := []tenant.OnboardServiceOptionFn{tenant.WithOnboardingLogger(onboardingLogger)}
@@ -697,6 +737,65 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 		dashboardLogSvc = dashboardService
 	}
 
+	notebookStore := notebooks.NewService(m.kvStore)
+	notebookSvc := notebooks.NewAuthorizedService(notebookStore, notebookStore)
+	notebookRunSvc := notebooks.NewAuthorizedRunService(notebooks.NewRunner(notebookStore, notebookStore, storageQueryService))
+
+	var (
+		snapshotSvc   snapshot.SnapshotService
+		snapshotTaker *snapshot.Taker
+	)
+	{
+		snapshotStore := snapshot.NewService(m.kvStore)
+		snapshotTaker = snapshot.NewTaker(
+			authorizer.NewDashboardService(dashboardSvc),
+			snapshot.NewAuthorizedService(snapshotStore),
+			storageQueryService,
+		)
+		reportExecutor := snapshot.NewReportExecutor(
+			m.log.With(zap.String("service", "snapshot-report-executor")),
+			snapshotStore,
+			snapshotTaker,
+			ts.UserService,
+		)
+
+		reportLogger := m.log.With(zap.String("service", "snapshot-report-scheduler"))
+		var sch stoppingScheduler = &scheduler.NoopScheduler{}
+		if !opts.NoTasks {
+			var err error
+			// The metrics of this scheduler are not registered, as they
+			// would collide with those of the task scheduler.
+			sch, _, err = scheduler.NewScheduler(
+				reportExecutor,
+				snapshotStore,
+				scheduler.WithOnErrorFn(func(ctx context.Context, reportID scheduler.ID, scheduledAt time.Time, err error) {
+					reportLogger.Info(
+						"error in scheduler run",
+						zap.String("reportID", platform2.ID(reportID).String()),
+						zap.Time("scheduledAt", scheduledAt),
+						zap.Error(err))
+				}),
+			)
+			if err != nil {
+				m.log.Fatal("could not start snapshot report scheduler", zap.Error(err))
+			}
+		}
+		m.reportScheduler = sch
+
+		if err := snapshot.ScheduleReports(ctx, reportLogger, snapshotStore, sch); err != nil {
+			m.log.Error("Failed to schedule existing snapshot reports", zap.Error(err))
+		}
+		snapshotSvc = snapshot.NewAuthorizedService(snapshot.NewSchedulingService(snapshotStore, sch))
+	}
+
+	// Variables are evaluated with the tag values of the storage engine, or
+	// by executing their queries.
+	variableValuesSvc := variable.NewValuesService(
+		authorizer.NewVariableService(variableSvc),
+		readsStore,
+		query.QueryServiceBridge{AsyncQueryService: m.queryController},
+	)
+
 	// resourceResolver is a deprecated type which combines the lookups
 	// of multiple resources into one type, used to resolve the resources
 	// associated org ID or name . It is a stop-gap while we move this
@@ -728,9 +827,12 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 			BucketFinder:  ts.BucketService,
 			LogBucketName: platform.MonitoringSystemBucketName,
 		},
+		ReadsStore:             readsStore,
 		DeleteService:          deleteService,
 		BackupService:          backupService,
 		RestoreService:         restoreService,
+		CompactionService:      m.engine,
+		DigestService:          m.engine,
 		AuthorizationService:   authSvc,
 		AuthorizationV1Service: authSvcV1,
 		PasswordV1Service:      passwordV1,
@@ -757,7 +859,9 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 		OrganizationOperationLogService: orgLogSvc,
 		SourceService:                   sourceSvc,
 		VariableService:                 variableSvc,
+		VariableValuesService:           variableValuesSvc,
 		PasswordsService:                ts.PasswordsService,
+		PrometheusRemoteMaxBodyBytes:    opts.PrometheusRemoteMaxBodyBytes,
 		InfluxQLService:                 storageQueryService,
 		InfluxqldService:                iqlquery.NewProxyExecutor(m.log, qe),
 		FluxService:                     storageQueryService,
@@ -768,6 +872,7 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 		NotificationEndpointService:     notificationEndpointSvc,
 		CheckService:                    checkSvc,
 		ScraperTargetStoreService:       scraperTargetSvc,
+		ScraperHealthService:            scraperScheduler.Health,
 		ChronografService:               chronografSvc,
 		SecretService:                   secretSvc,
 		LookupService:                   resourceResolver,
@@ -795,13 +900,18 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 			pkger.WithBucketSVC(authorizer.NewBucketService(b.BucketService)),
 			pkger.WithCheckSVC(authorizer.NewCheckService(b.CheckService, authedUrmSVC, authedOrgSVC)),
 			pkger.WithDashboardSVC(authorizer.NewDashboardService(b.DashboardService)),
+			pkger.WithDBRPMappingSVC(dbrpSvc),
 			pkger.WithLabelSVC(label.NewAuthedLabelService(labelSvc, b.OrgLookupService)),
+			pkger.WithNotebookSVC(notebookSvc),
 			pkger.WithNotificationEndpointSVC(authorizer.NewNotificationEndpointService(b.NotificationEndpointService, authedUrmSVC, authedOrgSVC)),
 			pkger.WithNotificationRuleSVC(authorizer.NewNotificationRuleStore(b.NotificationRuleStore, authedUrmSVC, authedOrgSVC)),
 			pkger.WithOrganizationService(authorizer.NewOrgService(b.OrganizationService)),
+			pkger.WithScraperTargetSVC(authorizer.NewScraperTargetStoreService(b.ScraperTargetStoreService, b.UserResourceMappingService, b.OrganizationService)),
 			pkger.WithSecretSVC(authorizer.NewSecretService(b.SecretService)),
 			pkger.WithTaskSVC(authorizer.NewTaskService(pkgerLogger, b.TaskService)),
 			pkger.WithTelegrafSVC(authorizer.NewTelegrafConfigService(b.TelegrafService, b.UserResourceMappingService)),
+			pkger.WithV1AuthorizationSVC(authorization.NewAuthedAuthorizationService(authSvcV1, ts)),
+			pkger.WithV1PasswordSVC(authv1.NewAuthedPasswordService(authv1.AuthFinder(authSvcV1), passwordV1)),
 			pkger.WithVariableSVC(authorizer.NewVariableService(b.VariableService)),
 		)
 		pkgSVC = pkger.MWTracing()(pkgSVC)
@@ -869,6 +979,10 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 	orgHTTPServer := ts.NewOrgHTTPHandler(m.log, secret.NewAuthedService(secretSvc))
 
 	bucketHTTPServer := ts.NewBucketHTTPHandler(m.log, labelSvc)
+	bucketHTTPServer.MountUsageHandler(http.NewStorageUsageHandler(
+		m.log.With(zap.String("handler", "storage_usage")),
+		authorizer.NewStorageUsageService(m.engine, ts.BucketService),
+	))
 
 	var dashboardServer *dashboardTransport.DashboardHandler
 	{
@@ -897,7 +1011,12 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 		)
 	}
 
-	notebookServer := notebookTransport.NewNotebookHandler(m.log.With(zap.String("handler", "notebooks")))
+	notebookServer := notebookTransport.NewNotebookHandler(m.log.With(zap.String("handler", "notebooks")), notebookSvc, notebookSvc, notebookRunSvc)
+
+	snapshotServer := snapshot.NewSnapshotHandler(m.log.With(zap.String("handler", "snapshots")), snapshotSvc, snapshotTaker)
+
+	annotationSvc := annotations.NewAuthorizedService(annotations.NewService(m.kvStore))
+	annotationServer := annotations.NewAnnotationHandler(m.log.With(zap.String("handler", "annotations")), annotationSvc, ts.OrganizationService)
 
 	platformHandler := http.NewPlatformHandler(
 		m.apibackend,
@@ -915,6 +1034,8 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 		http.WithResourceHandler(v1AuthHTTPServer),
 		http.WithResourceHandler(dashboardServer),
 		http.WithResourceHandler(notebookServer),
+		http.WithResourceHandler(snapshotServer),
+		http.WithResourceHandler(annotationServer),
 	)
 
 	httpLogger := m.log.With(zap.String("service", "http"))
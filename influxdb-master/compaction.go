@@ -0,0 +1,47 @@
+package influxdb
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2/kit/platform"
+)
+
+// ShardCompaction is the state of the compactions of the TSM files of a shard.
+// The level compactions are 1 to 3, the full and optimize compactions "full".
+type ShardCompaction struct {
+	ShardID        uint64                     `json:"shardID"`
+	BucketID       platform.ID                `json:"bucketID"`
+	Planner        string                     `json:"planner"`
+	Paused         bool                       `json:"paused"`
+	FullyCompacted bool                       `json:"fullyCompacted"`
+	Files          int                        `json:"files"`
+	Levels         map[string]CompactionLevel `json:"levels"`
+}
+
+// CompactionLevel are the compactions of a level of a shard. Compactions and
+// Errors count since the shard was opened.
+type CompactionLevel struct {
+	Active      int64 `json:"active"`
+	Queued      int64 `json:"queued"`
+	Compactions int64 `json:"compactions"`
+	Errors      int64 `json:"errors"`
+}
+
+// CompactionService inspects and controls the compactions of shards.
+type CompactionService interface {
+	// FindShardCompactions returns the compaction state of the shards of a
+	// bucket, or of every shard if bucketID is nil.
+	FindShardCompactions(ctx context.Context, bucketID *platform.ID) ([]*ShardCompaction, error)
+
+	// PauseShardCompactions pauses the level and full compactions of a shard
+	// until they are resumed or the server restarts. Cache snapshots continue.
+	PauseShardCompactions(ctx context.Context, shardID uint64) error
+
+	// ResumeShardCompactions resumes the compactions of a shard.
+	ResumeShardCompactions(ctx context.Context, shardID uint64) error
+
+	// TriggerShardCompaction snapshots the cache of a shard and schedules a
+	// full compaction of its TSM files, which runs once compactions of a
+	// paused shard are resumed.
+	TriggerShardCompaction(ctx context.Context, shardID uint64) error
+}
@@ -0,0 +1,427 @@
+package snapshot
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap"
+)
+
+const (
+	// PrefixSnapshots is the prefix of the snapshot API.
+	PrefixSnapshots = "/api/v2/snapshots"
+
+	// SharedPath is the path, below PrefixSnapshots, of the read-only links
+	// of snapshots. They require no authentication.
+	SharedPath = "/shared"
+)
+
+// SnapshotHandler is the handler of the snapshot API. It takes snapshots of
+// dashboards, serves them, including through their read-only links, and
+// manages the reports taking them on a schedule.
+type SnapshotHandler struct {
+	chi.Router
+
+	api *kithttp.API
+	log *zap.Logger
+
+	snapshotService SnapshotService
+	taker           *Taker
+}
+
+// NewSnapshotHandler returns a new instance of SnapshotHandler.
+func NewSnapshotHandler(log *zap.Logger, snapshotService SnapshotService, taker *Taker) *SnapshotHandler {
+	h := &SnapshotHandler{
+		api:             kithttp.NewAPI(kithttp.WithLog(log)),
+		log:             log,
+		snapshotService: snapshotService,
+		taker:           taker,
+	}
+
+	r := chi.NewRouter()
+	r.Use(
+		middleware.Recoverer,
+		middleware.RequestID,
+		middleware.RealIP,
+	)
+
+	r.Route("/", func(r chi.Router) {
+		r.Post("/", h.handlePostSnapshot)
+		r.Get("/", h.handleGetSnapshots)
+		r.Get(SharedPath+"/{token}", h.handleGetSharedSnapshot)
+
+		r.Route("/reports", func(r chi.Router) {
+			r.Post("/", h.handlePostReport)
+			r.Get("/", h.handleGetReports)
+
+			r.Route("/{id}", func(r chi.Router) {
+				r.Get("/", h.handleGetReport)
+				r.Patch("/", h.handlePatchReport)
+				r.Delete("/", h.handleDeleteReport)
+			})
+		})
+
+		r.Route("/{id}", func(r chi.Router) {
+			r.Get("/", h.handleGetSnapshot)
+			r.Delete("/", h.handleDeleteSnapshot)
+		})
+	})
+
+	h.Router = r
+	return h
+}
+
+// Prefix returns the mounting prefix for the handler.
+func (h *SnapshotHandler) Prefix() string {
+	return PrefixSnapshots
+}
+
+type snapshotLinks struct {
+	Self      string `json:"self"`
+	Share     string `json:"share"`
+	Dashboard string `json:"dashboard"`
+	Report    string `json:"report,omitempty"`
+}
+
+type snapshotResponse struct {
+	*Snapshot
+	Links snapshotLinks `json:"links"`
+}
+
+func newSnapshotResponse(s *Snapshot) snapshotResponse {
+	res := snapshotResponse{
+		Snapshot: s,
+		Links: snapshotLinks{
+			Self:      fmt.Sprintf("%s/%s", PrefixSnapshots, s.ID),
+			Share:     fmt.Sprintf("%s%s/%s", PrefixSnapshots, SharedPath, s.ShareToken),
+			Dashboard: fmt.Sprintf("/api/v2/dashboards/%s", s.DashboardID),
+		},
+	}
+	if s.ReportID != nil {
+		res.Links.Report = fmt.Sprintf("%s/reports/%s", PrefixSnapshots, *s.ReportID)
+	}
+	return res
+}
+
+type snapshotsResponse struct {
+	Snapshots []snapshotResponse    `json:"snapshots"`
+	Links     *influxdb.PagingLinks `json:"links"`
+}
+
+type postSnapshotRequest struct {
+	SnapshotCreate
+}
+
+// OK defaults the stop of the time range of the snapshot to now, and
+// validates the request.
+func (r *postSnapshotRequest) OK() error {
+	if r.Stop.IsZero() {
+		r.Stop = time.Now().UTC()
+	}
+	return r.Validate()
+}
+
+func (h *SnapshotHandler) handlePostSnapshot(w http.ResponseWriter, r *http.Request) {
+	var req postSnapshotRequest
+	if err := h.api.DecodeJSON(r.Body, &req); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	snap, err := h.taker.TakeSnapshot(r.Context(), req.SnapshotCreate)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Snapshot taken", zap.Stringer("snapshot_id", snap.ID), zap.Stringer("dashboard_id", snap.DashboardID))
+
+	h.api.Respond(w, r, http.StatusCreated, newSnapshotResponse(snap))
+}
+
+func (h *SnapshotHandler) handleGetSnapshots(w http.ResponseWriter, r *http.Request) {
+	opts, err := influxdb.DecodeFindOptions(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	var filter SnapshotFilter
+	if filter.OrganizationID, err = optionalID(r, "orgID"); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	if filter.DashboardID, err = optionalID(r, "dashboardID"); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	if filter.ReportID, err = optionalID(r, "reportID"); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	snaps, _, err := h.snapshotService.FindSnapshots(r.Context(), filter, *opts)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	res := snapshotsResponse{
+		Snapshots: make([]snapshotResponse, 0, len(snaps)),
+		Links:     influxdb.NewPagingLinks(PrefixSnapshots, *opts, filterParams(r, "orgID", "dashboardID", "reportID"), len(snaps)),
+	}
+	for _, s := range snaps {
+		res.Snapshots = append(res.Snapshots, newSnapshotResponse(s))
+	}
+	h.api.Respond(w, r, http.StatusOK, res)
+}
+
+func (h *SnapshotHandler) handleGetSnapshot(w http.ResponseWriter, r *http.Request) {
+	id, err := urlID(r, "id")
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	snap, err := h.snapshotService.FindSnapshotByID(r.Context(), id)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.api.Respond(w, r, http.StatusOK, newSnapshotResponse(snap))
+}
+
+func (h *SnapshotHandler) handleGetSharedSnapshot(w http.ResponseWriter, r *http.Request) {
+	snap, err := h.snapshotService.FindSnapshotByShareToken(r.Context(), chi.URLParam(r, "token"))
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.api.Respond(w, r, http.StatusOK, newSnapshotResponse(snap))
+}
+
+func (h *SnapshotHandler) handleDeleteSnapshot(w http.ResponseWriter, r *http.Request) {
+	id, err := urlID(r, "id")
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	if err := h.snapshotService.DeleteSnapshot(r.Context(), id); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Snapshot deleted", zap.Stringer("snapshot_id", id))
+
+	h.api.Respond(w, r, http.StatusNoContent, nil)
+}
+
+type reportLinks struct {
+	Self      string `json:"self"`
+	Dashboard string `json:"dashboard"`
+	Snapshots string `json:"snapshots"`
+}
+
+type reportResponse struct {
+	*Report
+	Links reportLinks `json:"links"`
+}
+
+func newReportResponse(rep *Report) reportResponse {
+	return reportResponse{
+		Report: rep,
+		Links: reportLinks{
+			Self:      fmt.Sprintf("%s/reports/%s", PrefixSnapshots, rep.ID),
+			Dashboard: fmt.Sprintf("/api/v2/dashboards/%s", rep.DashboardID),
+			Snapshots: fmt.Sprintf("%s?reportID=%s", PrefixSnapshots, rep.ID),
+		},
+	}
+}
+
+type reportsResponse struct {
+	Reports []reportResponse      `json:"reports"`
+	Links   *influxdb.PagingLinks `json:"links"`
+}
+
+func (h *SnapshotHandler) handlePostReport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var rep Report
+	if err := h.api.DecodeJSON(r.Body, &rep); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	if !rep.DashboardID.Valid() {
+		h.api.Err(w, r, ErrInvalidDashboardID)
+		return
+	}
+
+	a, err := icontext.GetAuthorizer(ctx)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	d, err := h.taker.DashboardService.FindDashboardByID(ctx, rep.DashboardID)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	// The report takes its snapshots with the permissions of the user
+	// creating it, of the organization of its dashboard.
+	rep.OwnerID = a.GetUserID()
+	rep.OrganizationID = d.OrganizationID
+
+	if err := h.snapshotService.CreateReport(ctx, &rep); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Report created", zap.Stringer("report_id", rep.ID))
+
+	h.api.Respond(w, r, http.StatusCreated, newReportResponse(&rep))
+}
+
+func (h *SnapshotHandler) handleGetReports(w http.ResponseWriter, r *http.Request) {
+	opts, err := influxdb.DecodeFindOptions(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	var filter ReportFilter
+	if filter.OrganizationID, err = optionalID(r, "orgID"); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	if filter.DashboardID, err = optionalID(r, "dashboardID"); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	reports, _, err := h.snapshotService.FindReports(r.Context(), filter, *opts)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	res := reportsResponse{
+		Reports: make([]reportResponse, 0, len(reports)),
+		Links:   influxdb.NewPagingLinks(PrefixSnapshots+"/reports", *opts, filterParams(r, "orgID", "dashboardID"), len(reports)),
+	}
+	for _, rep := range reports {
+		res.Reports = append(res.Reports, newReportResponse(rep))
+	}
+	h.api.Respond(w, r, http.StatusOK, res)
+}
+
+func (h *SnapshotHandler) handleGetReport(w http.ResponseWriter, r *http.Request) {
+	id, err := urlID(r, "id")
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	rep, err := h.snapshotService.FindReportByID(r.Context(), id)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.api.Respond(w, r, http.StatusOK, newReportResponse(rep))
+}
+
+func (h *SnapshotHandler) handlePatchReport(w http.ResponseWriter, r *http.Request) {
+	id, err := urlID(r, "id")
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	var upd ReportUpdate
+	if err := h.api.DecodeJSON(r.Body, &upd); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	rep, err := h.snapshotService.UpdateReport(r.Context(), id, upd)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Report updated", zap.Stringer("report_id", id))
+
+	h.api.Respond(w, r, http.StatusOK, newReportResponse(rep))
+}
+
+func (h *SnapshotHandler) handleDeleteReport(w http.ResponseWriter, r *http.Request) {
+	id, err := urlID(r, "id")
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	if err := h.snapshotService.DeleteReport(r.Context(), id); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Report deleted", zap.Stringer("report_id", id))
+
+	h.api.Respond(w, r, http.StatusNoContent, nil)
+}
+
+func urlID(r *http.Request, param string) (platform.ID, error) {
+	s := chi.URLParam(r, param)
+	if s == "" {
+		return 0, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "url missing " + param,
+		}
+	}
+
+	var id platform.ID
+	if err := id.DecodeFromString(s); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func optionalID(r *http.Request, param string) (*platform.ID, error) {
+	s := r.URL.Query().Get(param)
+	if s == "" {
+		return nil, nil
+	}
+
+	id, err := platform.IDFromString(s)
+	if err != nil {
+		return nil, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  fmt.Sprintf("%s is invalid", param),
+			Err:  err,
+		}
+	}
+	return id, nil
+}
+
+// filterParams returns the filter query parameters of r to carry over to the
+// paging links of a response.
+func filterParams(r *http.Request, params ...string) filterParamsMap {
+	f := filterParamsMap{}
+	for _, p := range params {
+		if v := r.URL.Query().Get(p); v != "" {
+			f[p] = []string{v}
+		}
+	}
+	return f
+}
+
+// filterParamsMap implements influxdb.PagingFilter.
+type filterParamsMap map[string][]string
+
+func (f filterParamsMap) QueryParams() map[string][]string {
+	return f
+}
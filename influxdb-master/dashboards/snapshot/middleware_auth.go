@@ -0,0 +1,154 @@
+package snapshot
+
+import (
+	"context"
+	"sort"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+	"github.com/influxdata/influxdb/v2/internal/queryutil"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+)
+
+var _ SnapshotService = (*AuthorizedService)(nil)
+
+// AuthorizedService is a SnapshotService authorizing access to snapshots and
+// reports with the permissions to their dashboards: reading them requires
+// read access to the dashboard, and creating, updating or deleting them
+// requires write access. Finding a snapshot by its share token requires no
+// permission, the token being the credential.
+type AuthorizedService struct {
+	SnapshotService
+}
+
+// NewAuthorizedService returns an AuthorizedService authorizing access to the
+// snapshots and reports of s.
+func NewAuthorizedService(s SnapshotService) *AuthorizedService {
+	return &AuthorizedService{SnapshotService: s}
+}
+
+func (s *AuthorizedService) CreateSnapshot(ctx context.Context, snap *Snapshot) error {
+	if _, _, err := authorizer.AuthorizeWrite(ctx, influxdb.DashboardsResourceType, snap.DashboardID, snap.OrganizationID); err != nil {
+		return err
+	}
+	return s.SnapshotService.CreateSnapshot(ctx, snap)
+}
+
+func (s *AuthorizedService) FindSnapshotByID(ctx context.Context, id platform.ID) (*Snapshot, error) {
+	snap, err := s.SnapshotService.FindSnapshotByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := authorizer.AuthorizeRead(ctx, influxdb.DashboardsResourceType, snap.DashboardID, snap.OrganizationID); err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+func (s *AuthorizedService) FindSnapshots(ctx context.Context, filter SnapshotFilter, opts ...influxdb.FindOptions) ([]*Snapshot, int, error) {
+	snaps, _, err := s.SnapshotService.FindSnapshots(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	authorized := snaps[:0]
+	for _, snap := range snaps {
+		_, _, err := authorizer.AuthorizeRead(ctx, influxdb.DashboardsResourceType, snap.DashboardID, snap.OrganizationID)
+		if err != nil && errors.ErrorCode(err) != errors.EUnauthorized {
+			return nil, 0, err
+		}
+		if err == nil {
+			authorized = append(authorized, snap)
+		}
+	}
+
+	total := len(authorized)
+	if len(opts) > 0 {
+		if opts[0].Descending {
+			sort.Slice(authorized, func(i, j int) bool { return authorized[i].ID > authorized[j].ID })
+		}
+		lo, hi := queryutil.PageBounds(len(authorized), opts[0], func(i int) platform.ID { return authorized[i].ID })
+		authorized = authorized[lo:hi]
+	}
+	return authorized, total, nil
+}
+
+func (s *AuthorizedService) DeleteSnapshot(ctx context.Context, id platform.ID) error {
+	snap, err := s.SnapshotService.FindSnapshotByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, _, err := authorizer.AuthorizeWrite(ctx, influxdb.DashboardsResourceType, snap.DashboardID, snap.OrganizationID); err != nil {
+		return err
+	}
+	return s.SnapshotService.DeleteSnapshot(ctx, id)
+}
+
+func (s *AuthorizedService) CreateReport(ctx context.Context, r *Report) error {
+	if _, _, err := authorizer.AuthorizeWrite(ctx, influxdb.DashboardsResourceType, r.DashboardID, r.OrganizationID); err != nil {
+		return err
+	}
+	return s.SnapshotService.CreateReport(ctx, r)
+}
+
+func (s *AuthorizedService) FindReportByID(ctx context.Context, id platform.ID) (*Report, error) {
+	r, err := s.SnapshotService.FindReportByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := authorizer.AuthorizeRead(ctx, influxdb.DashboardsResourceType, r.DashboardID, r.OrganizationID); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (s *AuthorizedService) FindReports(ctx context.Context, filter ReportFilter, opts ...influxdb.FindOptions) ([]*Report, int, error) {
+	reports, _, err := s.SnapshotService.FindReports(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	authorized := reports[:0]
+	for _, r := range reports {
+		_, _, err := authorizer.AuthorizeRead(ctx, influxdb.DashboardsResourceType, r.DashboardID, r.OrganizationID)
+		if err != nil && errors.ErrorCode(err) != errors.EUnauthorized {
+			return nil, 0, err
+		}
+		if err == nil {
+			authorized = append(authorized, r)
+		}
+	}
+
+	total := len(authorized)
+	if len(opts) > 0 {
+		if opts[0].Descending {
+			sort.Slice(authorized, func(i, j int) bool { return authorized[i].ID > authorized[j].ID })
+		}
+		lo, hi := queryutil.PageBounds(len(authorized), opts[0], func(i int) platform.ID { return authorized[i].ID })
+		authorized = authorized[lo:hi]
+	}
+	return authorized, total, nil
+}
+
+func (s *AuthorizedService) UpdateReport(ctx context.Context, id platform.ID, upd ReportUpdate) (*Report, error) {
+	r, err := s.SnapshotService.FindReportByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := authorizer.AuthorizeWrite(ctx, influxdb.DashboardsResourceType, r.DashboardID, r.OrganizationID); err != nil {
+		return nil, err
+	}
+	return s.SnapshotService.UpdateReport(ctx, id, upd)
+}
+
+func (s *AuthorizedService) DeleteReport(ctx context.Context, id platform.ID) error {
+	r, err := s.SnapshotService.FindReportByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, _, err := authorizer.AuthorizeWrite(ctx, influxdb.DashboardsResourceType, r.DashboardID, r.OrganizationID); err != nil {
+		return err
+	}
+	return s.SnapshotService.DeleteReport(ctx, id)
+}
@@ -0,0 +1,217 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/task/backend/scheduler"
+	"go.uber.org/zap"
+)
+
+// PermissionService finds the permissions of the owners of reports.
+type PermissionService interface {
+	FindPermissionForUser(ctx context.Context, userID platform.ID) (influxdb.PermissionSet, error)
+}
+
+// ReportStore finds reports and records the outcome of their runs.
+type ReportStore interface {
+	FindReportByID(ctx context.Context, id platform.ID) (*Report, error)
+	RecordReportRun(ctx context.Context, id platform.ID, err error) error
+}
+
+var _ scheduler.Executor = (*ReportExecutor)(nil)
+
+// ReportExecutor executes the runs of reports scheduled by a scheduler, taking
+// a snapshot with the permissions of the owner of the report for each of them.
+type ReportExecutor struct {
+	log     *zap.Logger
+	reports ReportStore
+	taker   *Taker
+	ps      PermissionService
+}
+
+// NewReportExecutor returns a ReportExecutor finding reports in reports and
+// taking their snapshots with taker.
+func NewReportExecutor(log *zap.Logger, reports ReportStore, taker *Taker, ps PermissionService) *ReportExecutor {
+	return &ReportExecutor{
+		log:     log,
+		reports: reports,
+		taker:   taker,
+		ps:      ps,
+	}
+}
+
+// Execute takes the snapshot of the run of a report scheduled for a time.
+func (e *ReportExecutor) Execute(ctx context.Context, id scheduler.ID, scheduledFor time.Time, runAt time.Time) error {
+	r, err := e.reports.FindReportByID(ctx, platform.ID(id))
+	if err != nil {
+		return err
+	}
+	if r.Status != ReportActive {
+		return nil
+	}
+
+	runErr := e.run(ctx, r, scheduledFor.UTC())
+	if err := e.reports.RecordReportRun(ctx, r.ID, runErr); err != nil {
+		e.log.Info("Failed to record report run", zap.Stringer("report_id", r.ID), zap.Error(err))
+	}
+	return runErr
+}
+
+func (e *ReportExecutor) run(ctx context.Context, r *Report, scheduledFor time.Time) error {
+	perms, err := e.ps.FindPermissionForUser(ctx, r.OwnerID)
+	if err != nil {
+		return err
+	}
+	ctx = icontext.SetAuthorizer(ctx, &influxdb.Authorization{
+		Status:      influxdb.Active,
+		UserID:      r.OwnerID,
+		ID:          platform.ID(1),
+		OrgID:       r.OrganizationID,
+		Permissions: perms,
+	})
+
+	d, err := r.Range.DurationFrom(scheduledFor)
+	if err != nil {
+		return err
+	}
+	if _, err := e.taker.TakeSnapshot(ctx, SnapshotCreate{
+		DashboardID: r.DashboardID,
+		ReportID:    &r.ID,
+		Name:        fmt.Sprintf("%s %s", r.Name, scheduledFor.Format(time.RFC3339)),
+		Description: r.Description,
+		Start:       scheduledFor.Add(-d),
+		Stop:        scheduledFor,
+	}); err != nil {
+		return err
+	}
+
+	if r.Retain == 0 {
+		return nil
+	}
+	// Snapshot IDs increase with time, so the snapshots past the most recent
+	// ones to retain are the oldest.
+	old, _, err := e.taker.SnapshotService.FindSnapshots(ctx, SnapshotFilter{ReportID: &r.ID}, influxdb.FindOptions{
+		Offset:     r.Retain,
+		Descending: true,
+	})
+	if err != nil {
+		return err
+	}
+	for _, s := range old {
+		if err := e.taker.SnapshotService.DeleteSnapshot(ctx, s.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// schedulableReport is the scheduler.Schedulable of a report.
+type schedulableReport struct {
+	id            scheduler.ID
+	schedule      scheduler.Schedule
+	lastScheduled time.Time
+}
+
+func (s schedulableReport) ID() scheduler.ID             { return s.id }
+func (s schedulableReport) Schedule() scheduler.Schedule { return s.schedule }
+func (s schedulableReport) Offset() time.Duration        { return 0 }
+func (s schedulableReport) LastScheduled() time.Time     { return s.lastScheduled }
+
+// NewSchedulable returns the scheduler.Schedulable of a report. A report never
+// scheduled before is scheduled from its creation.
+func NewSchedulable(r *Report) (scheduler.Schedulable, error) {
+	last := r.LastScheduled
+	if last.IsZero() {
+		last = r.CreatedAt
+	}
+	sch, last, err := scheduler.NewSchedule(r.EffectiveCron(), last)
+	if err != nil {
+		return nil, err
+	}
+	return schedulableReport{
+		id:            scheduler.ID(r.ID),
+		schedule:      sch,
+		lastScheduled: last,
+	}, nil
+}
+
+var _ SnapshotService = (*SchedulingService)(nil)
+
+// SchedulingService is a SnapshotService keeping the active reports it
+// creates, updates and deletes scheduled on a scheduler.
+type SchedulingService struct {
+	SnapshotService
+	sch scheduler.Scheduler
+}
+
+// NewSchedulingService returns a SchedulingService scheduling the reports of
+// svc on sch.
+func NewSchedulingService(svc SnapshotService, sch scheduler.Scheduler) *SchedulingService {
+	return &SchedulingService{
+		SnapshotService: svc,
+		sch:             sch,
+	}
+}
+
+// CreateReport creates a report and schedules it if it is active.
+func (s *SchedulingService) CreateReport(ctx context.Context, r *Report) error {
+	if err := s.SnapshotService.CreateReport(ctx, r); err != nil {
+		return err
+	}
+	return schedule(s.sch, r)
+}
+
+// UpdateReport updates a report, and schedules it if it is active, or
+// releases it otherwise.
+func (s *SchedulingService) UpdateReport(ctx context.Context, id platform.ID, upd ReportUpdate) (*Report, error) {
+	r, err := s.SnapshotService.UpdateReport(ctx, id, upd)
+	if err != nil {
+		return nil, err
+	}
+	if err := schedule(s.sch, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// DeleteReport deletes a report and releases it.
+func (s *SchedulingService) DeleteReport(ctx context.Context, id platform.ID) error {
+	if err := s.SnapshotService.DeleteReport(ctx, id); err != nil {
+		return err
+	}
+	return s.sch.Release(scheduler.ID(id))
+}
+
+func schedule(sch scheduler.Scheduler, r *Report) error {
+	if r.Status != ReportActive {
+		return sch.Release(scheduler.ID(r.ID))
+	}
+	s, err := NewSchedulable(r)
+	if err != nil {
+		return err
+	}
+	return sch.Schedule(s)
+}
+
+// ScheduleReports schedules the active reports of svc on sch, as when
+// starting. Reports failing to be scheduled are logged and skipped.
+func ScheduleReports(ctx context.Context, log *zap.Logger, svc SnapshotService, sch scheduler.Scheduler) error {
+	reports, _, err := svc.FindReports(ctx, ReportFilter{})
+	if err != nil {
+		return err
+	}
+	for _, r := range reports {
+		if r.Status != ReportActive {
+			continue
+		}
+		if err := schedule(sch, r); err != nil {
+			log.Error("Failed to schedule report", zap.Stringer("report_id", r.ID), zap.Error(err))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,436 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/internal/queryutil"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/rand"
+	"github.com/influxdata/influxdb/v2/snowflake"
+	"github.com/influxdata/influxdb/v2/task/backend/scheduler"
+)
+
+var (
+	snapshotBucket   = []byte("dashboardsnapshotsv1")
+	shareTokenBucket = []byte("dashboardsnapshotsharetokensv1")
+	reportBucket     = []byte("dashboardreportsv1")
+)
+
+// shareTokenSize is the number of random bytes of a share token.
+const shareTokenSize = 32
+
+// Report run statuses.
+const (
+	runSuccess = "success"
+	runFailed  = "failed"
+)
+
+// InternalSnapshotServiceError is used when the error comes from an internal
+// system.
+func InternalSnapshotServiceError(err error) *errors.Error {
+	return &errors.Error{
+		Code: errors.EInternal,
+		Msg:  fmt.Sprintf("Unknown internal snapshot data error; Err: %v", err),
+		Op:   "kv/snapshot",
+	}
+}
+
+var _ SnapshotService = (*Service)(nil)
+var _ scheduler.SchedulableService = (*Service)(nil)
+
+// Service is a SnapshotService storing snapshots and reports in a kv store.
+// Snapshots are indexed by their share token.
+type Service struct {
+	kv kv.Store
+
+	IDGenerator    platform.IDGenerator
+	TokenGenerator influxdb.TokenGenerator
+	TimeGenerator  influxdb.TimeGenerator
+}
+
+// NewService constructs and configures a new snapshot service.
+func NewService(store kv.Store) *Service {
+	return &Service{
+		kv:             store,
+		IDGenerator:    snowflake.NewIDGenerator(),
+		TokenGenerator: rand.NewTokenGenerator(shareTokenSize),
+		TimeGenerator:  influxdb.RealTimeGenerator{},
+	}
+}
+
+// CreateSnapshot stores a snapshot.
+func (s *Service) CreateSnapshot(ctx context.Context, snap *Snapshot) error {
+	token, err := s.TokenGenerator.Token()
+	if err != nil {
+		return InternalSnapshotServiceError(err)
+	}
+	snap.ID = s.IDGenerator.ID()
+	snap.ShareToken = token
+	snap.CreatedAt = s.TimeGenerator.Now()
+
+	return s.kv.Update(ctx, func(tx kv.Tx) error {
+		if err := put(tx, snapshotBucket, snap.ID, snap); err != nil {
+			return err
+		}
+		id, err := snap.ID.Encode()
+		if err != nil {
+			return err
+		}
+		b, err := tx.Bucket(shareTokenBucket)
+		if err != nil {
+			return InternalSnapshotServiceError(err)
+		}
+		if err := b.Put([]byte(snap.ShareToken), id); err != nil {
+			return InternalSnapshotServiceError(err)
+		}
+		return nil
+	})
+}
+
+// FindSnapshotByID returns a single snapshot by ID.
+func (s *Service) FindSnapshotByID(ctx context.Context, id platform.ID) (*Snapshot, error) {
+	var snap *Snapshot
+	err := s.kv.View(ctx, func(tx kv.Tx) error {
+		var err error
+		snap, err = findSnapshotByID(tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+// FindSnapshotByShareToken returns the snapshot shared with a token.
+func (s *Service) FindSnapshotByShareToken(ctx context.Context, token string) (*Snapshot, error) {
+	var snap *Snapshot
+	err := s.kv.View(ctx, func(tx kv.Tx) error {
+		b, err := tx.Bucket(shareTokenBucket)
+		if err != nil {
+			return InternalSnapshotServiceError(err)
+		}
+		v, err := b.Get([]byte(token))
+		if kv.IsNotFound(err) {
+			return ErrSnapshotNotFound
+		}
+		if err != nil {
+			return InternalSnapshotServiceError(err)
+		}
+
+		var id platform.ID
+		if err := id.Decode(v); err != nil {
+			return InternalSnapshotServiceError(err)
+		}
+		snap, err = findSnapshotByID(tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+func findSnapshotByID(tx kv.Tx, id platform.ID) (*Snapshot, error) {
+	var snap Snapshot
+	if err := get(tx, snapshotBucket, id, &snap, ErrSnapshotNotFound); err != nil {
+		return nil, err
+	}
+	return &snap, nil
+}
+
+// FindSnapshots returns the snapshots matching filter, without their cells,
+// ordered by ID.
+func (s *Service) FindSnapshots(ctx context.Context, filter SnapshotFilter, opts ...influxdb.FindOptions) ([]*Snapshot, int, error) {
+	var snaps []*Snapshot
+	err := s.kv.View(ctx, func(tx kv.Tx) error {
+		return forEach(tx, snapshotBucket, func(v []byte) error {
+			var snap Snapshot
+			if err := json.Unmarshal(v, &snap); err != nil {
+				return InternalSnapshotServiceError(err)
+			}
+			if !filter.matches(&snap) {
+				return nil
+			}
+			snap.Cells = nil
+			snaps = append(snaps, &snap)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total := len(snaps)
+	if len(opts) > 0 {
+		if opts[0].Descending {
+			sort.Slice(snaps, func(i, j int) bool { return snaps[i].ID > snaps[j].ID })
+		}
+		lo, hi := queryutil.PageBounds(len(snaps), opts[0], func(i int) platform.ID { return snaps[i].ID })
+		snaps = snaps[lo:hi]
+	}
+	return snaps, total, nil
+}
+
+func (f SnapshotFilter) matches(s *Snapshot) bool {
+	if f.OrganizationID != nil && *f.OrganizationID != s.OrganizationID {
+		return false
+	}
+	if f.DashboardID != nil && *f.DashboardID != s.DashboardID {
+		return false
+	}
+	if f.ReportID != nil && (s.ReportID == nil || *f.ReportID != *s.ReportID) {
+		return false
+	}
+	return true
+}
+
+// DeleteSnapshot deletes a snapshot and its share token.
+func (s *Service) DeleteSnapshot(ctx context.Context, id platform.ID) error {
+	return s.kv.Update(ctx, func(tx kv.Tx) error {
+		snap, err := findSnapshotByID(tx, id)
+		if err != nil {
+			return err
+		}
+		if err := del(tx, snapshotBucket, id); err != nil {
+			return err
+		}
+		b, err := tx.Bucket(shareTokenBucket)
+		if err != nil {
+			return InternalSnapshotServiceError(err)
+		}
+		if err := b.Delete([]byte(snap.ShareToken)); err != nil {
+			return InternalSnapshotServiceError(err)
+		}
+		return nil
+	})
+}
+
+// CreateReport creates a report.
+func (s *Service) CreateReport(ctx context.Context, r *Report) error {
+	if r.Status == "" {
+		r.Status = ReportActive
+	}
+	if err := r.Validate(); err != nil {
+		return err
+	}
+	r.ID = s.IDGenerator.ID()
+	r.CreatedAt = s.TimeGenerator.Now()
+	r.UpdatedAt = r.CreatedAt
+	r.LastScheduled, r.LastRunStatus, r.LastRunError = time.Time{}, "", ""
+
+	return s.kv.Update(ctx, func(tx kv.Tx) error {
+		return put(tx, reportBucket, r.ID, r)
+	})
+}
+
+// FindReportByID returns a single report by ID.
+func (s *Service) FindReportByID(ctx context.Context, id platform.ID) (*Report, error) {
+	var r *Report
+	err := s.kv.View(ctx, func(tx kv.Tx) error {
+		var err error
+		r, err = findReportByID(tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func findReportByID(tx kv.Tx, id platform.ID) (*Report, error) {
+	var r Report
+	if err := get(tx, reportBucket, id, &r, ErrReportNotFound); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// FindReports returns the reports matching filter, ordered by ID.
+func (s *Service) FindReports(ctx context.Context, filter ReportFilter, opts ...influxdb.FindOptions) ([]*Report, int, error) {
+	var reports []*Report
+	err := s.kv.View(ctx, func(tx kv.Tx) error {
+		return forEach(tx, reportBucket, func(v []byte) error {
+			var r Report
+			if err := json.Unmarshal(v, &r); err != nil {
+				return InternalSnapshotServiceError(err)
+			}
+			if filter.OrganizationID != nil && *filter.OrganizationID != r.OrganizationID {
+				return nil
+			}
+			if filter.DashboardID != nil && *filter.DashboardID != r.DashboardID {
+				return nil
+			}
+			reports = append(reports, &r)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total := len(reports)
+	if len(opts) > 0 {
+		if opts[0].Descending {
+			sort.Slice(reports, func(i, j int) bool { return reports[i].ID > reports[j].ID })
+		}
+		lo, hi := queryutil.PageBounds(len(reports), opts[0], func(i int) platform.ID { return reports[i].ID })
+		reports = reports[lo:hi]
+	}
+	return reports, total, nil
+}
+
+// UpdateReport updates a report.
+func (s *Service) UpdateReport(ctx context.Context, id platform.ID, upd ReportUpdate) (*Report, error) {
+	var r *Report
+	err := s.kv.Update(ctx, func(tx kv.Tx) error {
+		var err error
+		if r, err = findReportByID(tx, id); err != nil {
+			return err
+		}
+		wasActive := r.Status == ReportActive
+		upd.Apply(r)
+		if err := r.Validate(); err != nil {
+			return err
+		}
+		r.UpdatedAt = s.TimeGenerator.Now()
+		if !wasActive && r.Status == ReportActive {
+			// Resume from now rather than catching up on the runs missed
+			// while the report was inactive.
+			r.LastScheduled = r.UpdatedAt
+		}
+		return put(tx, reportBucket, id, r)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// DeleteReport deletes a report.
+func (s *Service) DeleteReport(ctx context.Context, id platform.ID) error {
+	return s.kv.Update(ctx, func(tx kv.Tx) error {
+		if _, err := findReportByID(tx, id); err != nil {
+			return err
+		}
+		return del(tx, reportBucket, id)
+	})
+}
+
+// UpdateLastScheduled records the time a report was last scheduled for.
+func (s *Service) UpdateLastScheduled(ctx context.Context, id scheduler.ID, t time.Time) error {
+	return s.updateReport(ctx, platform.ID(id), func(r *Report) {
+		r.LastScheduled = t
+	})
+}
+
+// RecordReportRun records the outcome of a run of a report.
+func (s *Service) RecordReportRun(ctx context.Context, id platform.ID, runErr error) error {
+	return s.updateReport(ctx, id, func(r *Report) {
+		r.LastRunStatus, r.LastRunError = runSuccess, ""
+		if runErr != nil {
+			r.LastRunStatus, r.LastRunError = runFailed, runErr.Error()
+		}
+	})
+}
+
+func (s *Service) updateReport(ctx context.Context, id platform.ID, fn func(*Report)) error {
+	return s.kv.Update(ctx, func(tx kv.Tx) error {
+		r, err := findReportByID(tx, id)
+		if err != nil {
+			return err
+		}
+		fn(r)
+		return put(tx, reportBucket, id, r)
+	})
+}
+
+func get(tx kv.Tx, bucket []byte, id platform.ID, v interface{}, notFound error) error {
+	key, err := id.Encode()
+	if err != nil {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Err:  err,
+		}
+	}
+	b, err := tx.Bucket(bucket)
+	if err != nil {
+		return InternalSnapshotServiceError(err)
+	}
+	data, err := b.Get(key)
+	if kv.IsNotFound(err) {
+		return notFound
+	}
+	if err != nil {
+		return InternalSnapshotServiceError(err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return InternalSnapshotServiceError(err)
+	}
+	return nil
+}
+
+func put(tx kv.Tx, bucket []byte, id platform.ID, v interface{}) error {
+	key, err := id.Encode()
+	if err != nil {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Err:  err,
+		}
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return InternalSnapshotServiceError(err)
+	}
+	b, err := tx.Bucket(bucket)
+	if err != nil {
+		return InternalSnapshotServiceError(err)
+	}
+	if err := b.Put(key, data); err != nil {
+		return InternalSnapshotServiceError(err)
+	}
+	return nil
+}
+
+func del(tx kv.Tx, bucket []byte, id platform.ID) error {
+	key, err := id.Encode()
+	if err != nil {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Err:  err,
+		}
+	}
+	b, err := tx.Bucket(bucket)
+	if err != nil {
+		return InternalSnapshotServiceError(err)
+	}
+	if err := b.Delete(key); err != nil {
+		return InternalSnapshotServiceError(err)
+	}
+	return nil
+}
+
+func forEach(tx kv.Tx, bucket []byte, fn func(v []byte) error) error {
+	b, err := tx.Bucket(bucket)
+	if err != nil {
+		return InternalSnapshotServiceError(err)
+	}
+	cur, err := b.ForwardCursor(nil)
+	if err != nil {
+		return InternalSnapshotServiceError(err)
+	}
+	defer cur.Close()
+
+	for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+		if err := fn(v); err != nil {
+			return err
+		}
+	}
+	return cur.Err()
+}
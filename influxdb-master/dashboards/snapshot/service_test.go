@@ -0,0 +1,176 @@
+package snapshot_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/dashboards/snapshot"
+	"github.com/influxdata/influxdb/v2/inmem"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	ierrors "github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/kv/migration/all"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/task/options"
+	"go.uber.org/zap/zaptest"
+)
+
+var now = time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestService(t *testing.T) *snapshot.Service {
+	t.Helper()
+
+	s := inmem.NewKVStore()
+	if err := all.Up(context.Background(), zaptest.NewLogger(t), s); err != nil {
+		t.Fatalf("failed to apply migrations: %v", err)
+	}
+
+	svc := snapshot.NewService(s)
+	svc.IDGenerator = mock.NewMockIDGenerator()
+	svc.TimeGenerator = mock.TimeGenerator{FakeValue: now}
+	return svc
+}
+
+func TestService_Snapshots(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t)
+
+	reportID := platform.ID(10)
+	for _, snap := range []*snapshot.Snapshot{
+		{OrganizationID: 1, DashboardID: 2, Name: "a"},
+		{OrganizationID: 1, DashboardID: 2, Name: "b", ReportID: &reportID},
+		{OrganizationID: 1, DashboardID: 3, Name: "c", ReportID: &reportID},
+	} {
+		if err := svc.CreateSnapshot(ctx, snap); err != nil {
+			t.Fatalf("failed to create snapshot: %v", err)
+		}
+		if !snap.ID.Valid() || snap.ShareToken == "" || !snap.CreatedAt.Equal(now) {
+			t.Fatalf("snapshot not initialized: %+v", snap)
+		}
+	}
+
+	snaps, n, err := svc.FindSnapshots(ctx, snapshot.SnapshotFilter{ReportID: &reportID})
+	if err != nil {
+		t.Fatalf("failed to find snapshots: %v", err)
+	}
+	if n != 2 || snaps[0].Name != "b" || snaps[1].Name != "c" {
+		t.Fatalf("unexpected snapshots of report: %d %+v", n, snaps)
+	}
+
+	snaps, n, err = svc.FindSnapshots(ctx, snapshot.SnapshotFilter{}, influxdb.FindOptions{Offset: 1, Limit: 1, Descending: true})
+	if err != nil {
+		t.Fatalf("failed to find snapshots: %v", err)
+	}
+	if n != 3 || len(snaps) != 1 || snaps[0].Name != "b" {
+		t.Fatalf("unexpected page of snapshots: %d %+v", n, snaps)
+	}
+
+	shared, err := svc.FindSnapshotByShareToken(ctx, snaps[0].ShareToken)
+	if err != nil {
+		t.Fatalf("failed to find snapshot by share token: %v", err)
+	}
+	if shared.ID != snaps[0].ID {
+		t.Fatalf("expected snapshot %s, got %s", snaps[0].ID, shared.ID)
+	}
+
+	if err := svc.DeleteSnapshot(ctx, shared.ID); err != nil {
+		t.Fatalf("failed to delete snapshot: %v", err)
+	}
+	if _, err := svc.FindSnapshotByID(ctx, shared.ID); ierrors.ErrorCode(err) != ierrors.ENotFound {
+		t.Fatalf("expected deleted snapshot not to be found, got %v", err)
+	}
+	if _, err := svc.FindSnapshotByShareToken(ctx, shared.ShareToken); ierrors.ErrorCode(err) != ierrors.ENotFound {
+		t.Fatalf("expected share token of deleted snapshot not to be found, got %v", err)
+	}
+}
+
+func TestService_Reports(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t)
+
+	r := &snapshot.Report{
+		OrganizationID: 1,
+		DashboardID:    2,
+		OwnerID:        3,
+		Name:           "daily",
+		Cron:           "0 0 * * *",
+		Range:          *options.MustParseDuration("1d"),
+	}
+	if err := svc.CreateReport(ctx, r); err != nil {
+		t.Fatalf("failed to create report: %v", err)
+	}
+	if r.Status != snapshot.ReportActive {
+		t.Fatalf("expected report to be active by default, got %q", r.Status)
+	}
+
+	every := "1h"
+	inactive := snapshot.ReportInactive
+	r, err := svc.UpdateReport(ctx, r.ID, snapshot.ReportUpdate{Every: &every, Status: &inactive})
+	if err != nil {
+		t.Fatalf("failed to update report: %v", err)
+	}
+	if r.Cron != "" || r.EffectiveCron() != "@every 1h" || r.Status != snapshot.ReportInactive {
+		t.Fatalf("unexpected updated report: %+v", r)
+	}
+
+	if err := svc.RecordReportRun(ctx, r.ID, errors.New("query failed")); err != nil {
+		t.Fatalf("failed to record report run: %v", err)
+	}
+	r, err = svc.FindReportByID(ctx, r.ID)
+	if err != nil {
+		t.Fatalf("failed to find report: %v", err)
+	}
+	if r.LastRunStatus != "failed" || r.LastRunError != "query failed" {
+		t.Fatalf("unexpected report run: %+v", r)
+	}
+
+	if err := svc.DeleteReport(ctx, r.ID); err != nil {
+		t.Fatalf("failed to delete report: %v", err)
+	}
+	if _, err := svc.FindReportByID(ctx, r.ID); ierrors.ErrorCode(err) != ierrors.ENotFound {
+		t.Fatalf("expected deleted report not to be found, got %v", err)
+	}
+}
+
+func TestReport_Validate(t *testing.T) {
+	valid := func() *snapshot.Report {
+		return &snapshot.Report{
+			DashboardID: 1,
+			Name:        "r",
+			Status:      snapshot.ReportActive,
+			Every:       "1h",
+			Range:       *options.MustParseDuration("1h"),
+		}
+	}
+
+	tests := []struct {
+		name   string
+		update func(r *snapshot.Report)
+		valid  bool
+	}{
+		{name: "valid", update: func(r *snapshot.Report) {}, valid: true},
+		{name: "missing dashboard", update: func(r *snapshot.Report) { r.DashboardID = 0 }},
+		{name: "missing name", update: func(r *snapshot.Report) { r.Name = "" }},
+		{name: "bad status", update: func(r *snapshot.Report) { r.Status = "paused" }},
+		{name: "cron and every", update: func(r *snapshot.Report) { r.Cron = "* * * * *" }},
+		{name: "no schedule", update: func(r *snapshot.Report) { r.Every = "" }},
+		{name: "bad cron", update: func(r *snapshot.Report) { r.Every, r.Cron = "", "not a cron" }},
+		{name: "zero range", update: func(r *snapshot.Report) { r.Range = options.Duration{} }},
+		{name: "negative retain", update: func(r *snapshot.Report) { r.Retain = -1 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := valid()
+			tt.update(r)
+			err := r.Validate()
+			if tt.valid && err != nil {
+				t.Fatalf("expected report to be valid, got %v", err)
+			}
+			if !tt.valid && ierrors.ErrorCode(err) != ierrors.EInvalid {
+				t.Fatalf("expected invalid error, got %v", err)
+			}
+		})
+	}
+}
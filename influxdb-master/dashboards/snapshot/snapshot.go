@@ -0,0 +1,288 @@
+// Package snapshot takes snapshots of dashboards. A snapshot holds the
+// annotated CSV results of the queries of every cell of a dashboard for a time
+// range, together with the properties of the views of the cells, so that the
+// dashboard can be shown as it was when the snapshot was taken, whatever
+// happens to its data or cells afterwards. Snapshots are immutable, and can be
+// shared with a read-only link holding their share token.
+//
+// Reports take snapshots of a dashboard on a schedule, for the time range
+// ending at each time they are scheduled for.
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/task/backend/scheduler"
+	"github.com/influxdata/influxdb/v2/task/options"
+)
+
+var (
+	// ErrSnapshotNotFound is used when the snapshot is not found.
+	ErrSnapshotNotFound = &errors.Error{
+		Code: errors.ENotFound,
+		Msg:  "snapshot not found",
+	}
+
+	// ErrReportNotFound is used when the report is not found.
+	ErrReportNotFound = &errors.Error{
+		Code: errors.ENotFound,
+		Msg:  "report not found",
+	}
+
+	// ErrInvalidDashboardID is used when a snapshot or report is not of a
+	// valid dashboard ID.
+	ErrInvalidDashboardID = &errors.Error{
+		Code: errors.EInvalid,
+		Msg:  "provided dashboard ID is missing or invalid",
+	}
+
+	// ErrInvalidTimeRange is used when the start of the time range of a
+	// snapshot is not before its stop.
+	ErrInvalidTimeRange = &errors.Error{
+		Code: errors.EInvalid,
+		Msg:  "start of time range must be before its stop",
+	}
+)
+
+// Snapshot is the results of the queries of the cells of a dashboard for a
+// time range, taken at a point in time.
+type Snapshot struct {
+	ID             platform.ID  `json:"id"`
+	OrganizationID platform.ID  `json:"orgID"`
+	DashboardID    platform.ID  `json:"dashboardID"`
+	ReportID       *platform.ID `json:"reportID,omitempty"`
+	Name           string       `json:"name"`
+	Description    string       `json:"description,omitempty"`
+	Start          time.Time    `json:"start"`
+	Stop           time.Time    `json:"stop"`
+	Cells          []Cell       `json:"cells,omitempty"`
+	ShareToken     string       `json:"shareToken"`
+	CreatedAt      time.Time    `json:"createdAt"`
+}
+
+// Cell is a cell of the dashboard of a snapshot, with the properties of its
+// view and the results of its queries.
+type Cell struct {
+	ID platform.ID `json:"id"`
+	influxdb.CellProperty
+	Name       string          `json:"name"`
+	Properties json.RawMessage `json:"properties,omitempty"`
+	Results    []Result        `json:"results"`
+}
+
+// Result is the result of a query of a cell. Error is set instead of CSV if
+// the query failed.
+type Result struct {
+	Name  string `json:"name,omitempty"`
+	Query string `json:"query"`
+	CSV   string `json:"csv,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// SnapshotCreate is a request to take a snapshot of a dashboard.
+type SnapshotCreate struct {
+	DashboardID platform.ID  `json:"dashboardID"`
+	ReportID    *platform.ID `json:"-"`
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Start       time.Time    `json:"start"`
+	Stop        time.Time    `json:"stop"`
+}
+
+// Validate validates the request to take a snapshot.
+func (c SnapshotCreate) Validate() error {
+	if !c.DashboardID.Valid() {
+		return ErrInvalidDashboardID
+	}
+	if !c.Start.Before(c.Stop) {
+		return ErrInvalidTimeRange
+	}
+	return nil
+}
+
+// SnapshotFilter selects snapshots. Unset fields match all snapshots.
+type SnapshotFilter struct {
+	OrganizationID *platform.ID
+	DashboardID    *platform.ID
+	ReportID       *platform.ID
+}
+
+// Report statuses.
+const (
+	ReportActive   = "active"
+	ReportInactive = "inactive"
+)
+
+// Report takes snapshots of a dashboard on a schedule. Each snapshot is of the
+// time range of length Range ending at the time the run is scheduled for, and
+// is taken with the permissions of the owner of the report.
+type Report struct {
+	ID             platform.ID      `json:"id"`
+	OrganizationID platform.ID      `json:"orgID"`
+	DashboardID    platform.ID      `json:"dashboardID"`
+	OwnerID        platform.ID      `json:"ownerID"`
+	Name           string           `json:"name"`
+	Description    string           `json:"description,omitempty"`
+	Status         string           `json:"status"`
+	Cron           string           `json:"cron,omitempty"`
+	Every          string           `json:"every,omitempty"`
+	Range          options.Duration `json:"range"`
+	// Retain is the number of the most recent snapshots of the report to
+	// keep. Zero keeps all of them.
+	Retain        int       `json:"retain,omitempty"`
+	LastScheduled time.Time `json:"lastScheduled,omitempty"`
+	LastRunStatus string    `json:"lastRunStatus,omitempty"`
+	LastRunError  string    `json:"lastRunError,omitempty"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+// EffectiveCron returns the cron string of the schedule of the report. As for
+// tasks, every is converted to a cron string using "@every".
+func (r *Report) EffectiveCron() string {
+	if r.Cron != "" {
+		return r.Cron
+	}
+	if r.Every != "" {
+		return "@every " + r.Every
+	}
+	return ""
+}
+
+// Validate validates the report.
+func (r *Report) Validate() error {
+	if !r.DashboardID.Valid() {
+		return ErrInvalidDashboardID
+	}
+	if r.Name == "" {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "report name is required",
+		}
+	}
+	switch r.Status {
+	case ReportActive, ReportInactive:
+	default:
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "report status must be active or inactive",
+		}
+	}
+	if (r.Cron == "") == (r.Every == "") {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "report must have exactly one of cron or every",
+		}
+	}
+	if err := scheduler.ValidateSchedule(r.EffectiveCron()); err != nil {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "invalid report schedule",
+			Err:  err,
+		}
+	}
+	if d, err := r.Range.DurationFrom(time.Now()); err != nil || d <= 0 {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "report range must be a positive duration",
+			Err:  err,
+		}
+	}
+	if r.Retain < 0 {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "report retain must not be negative",
+		}
+	}
+	return nil
+}
+
+// ReportFilter selects reports. Unset fields match all reports.
+type ReportFilter struct {
+	OrganizationID *platform.ID
+	DashboardID    *platform.ID
+}
+
+// ReportUpdate is an update of a report. Nil fields are not updated.
+type ReportUpdate struct {
+	Name        *string           `json:"name,omitempty"`
+	Description *string           `json:"description,omitempty"`
+	Status      *string           `json:"status,omitempty"`
+	Cron        *string           `json:"cron,omitempty"`
+	Every       *string           `json:"every,omitempty"`
+	Range       *options.Duration `json:"range,omitempty"`
+	Retain      *int              `json:"retain,omitempty"`
+}
+
+// Apply applies the update to r. Setting one of cron or every unsets the
+// other.
+func (u ReportUpdate) Apply(r *Report) {
+	if u.Name != nil {
+		r.Name = *u.Name
+	}
+	if u.Description != nil {
+		r.Description = *u.Description
+	}
+	if u.Status != nil {
+		r.Status = *u.Status
+	}
+	if u.Cron != nil {
+		r.Cron = *u.Cron
+		if r.Cron != "" {
+			r.Every = ""
+		}
+	}
+	if u.Every != nil {
+		r.Every = *u.Every
+		if r.Every != "" {
+			r.Cron = ""
+		}
+	}
+	if u.Range != nil {
+		r.Range = *u.Range
+	}
+	if u.Retain != nil {
+		r.Retain = *u.Retain
+	}
+}
+
+// SnapshotService stores snapshots and reports.
+type SnapshotService interface {
+	// CreateSnapshot stores a snapshot, setting its ID, share token and
+	// creation time.
+	CreateSnapshot(ctx context.Context, s *Snapshot) error
+
+	// FindSnapshotByID returns a single snapshot by ID.
+	FindSnapshotByID(ctx context.Context, id platform.ID) (*Snapshot, error)
+
+	// FindSnapshotByShareToken returns the snapshot shared with a token.
+	FindSnapshotByShareToken(ctx context.Context, token string) (*Snapshot, error)
+
+	// FindSnapshots returns the snapshots matching filter, without their cells,
+	// and the total count of matching snapshots.
+	FindSnapshots(ctx context.Context, filter SnapshotFilter, opts ...influxdb.FindOptions) ([]*Snapshot, int, error)
+
+	// DeleteSnapshot deletes a snapshot.
+	DeleteSnapshot(ctx context.Context, id platform.ID) error
+
+	// CreateReport creates a report, setting its ID and creation time.
+	CreateReport(ctx context.Context, r *Report) error
+
+	// FindReportByID returns a single report by ID.
+	FindReportByID(ctx context.Context, id platform.ID) (*Report, error)
+
+	// FindReports returns the reports matching filter and the total count of
+	// matching reports.
+	FindReports(ctx context.Context, filter ReportFilter, opts ...influxdb.FindOptions) ([]*Report, int, error)
+
+	// UpdateReport updates a report.
+	UpdateReport(ctx context.Context, id platform.ID, upd ReportUpdate) (*Report, error)
+
+	// DeleteReport deletes a report. The snapshots it took are kept.
+	DeleteReport(ctx context.Context, id platform.ID) error
+}
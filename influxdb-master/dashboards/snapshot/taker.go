@@ -0,0 +1,170 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/influxdata/flux/csv"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/internal/queryutil"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/query"
+)
+
+const (
+	// DefaultMaxResultBytes is the default limit of the size of the annotated
+	// CSV of a result of a snapshot.
+	DefaultMaxResultBytes = 10 << 20
+)
+
+// Taker takes snapshots of dashboards, executing the queries of their cells
+// with the authorization of the context it is given.
+type Taker struct {
+	DashboardService influxdb.DashboardService
+	SnapshotService  SnapshotService
+	QueryService     query.ProxyQueryService
+
+	// MaxResultBytes limits the size of the annotated CSV of a result. A
+	// result exceeding it is replaced with an error.
+	MaxResultBytes int
+}
+
+// NewTaker returns a Taker reading dashboards from dashboards, executing their
+// queries with qs, and storing their snapshots in snapshots.
+func NewTaker(dashboards influxdb.DashboardService, snapshots SnapshotService, qs query.ProxyQueryService) *Taker {
+	return &Taker{
+		DashboardService: dashboards,
+		SnapshotService:  snapshots,
+		QueryService:     qs,
+		MaxResultBytes:   DefaultMaxResultBytes,
+	}
+}
+
+// TakeSnapshot takes and stores a snapshot of a dashboard. The failure of a
+// query is recorded in its result rather than failing the snapshot.
+func (t *Taker) TakeSnapshot(ctx context.Context, create SnapshotCreate) (*Snapshot, error) {
+	if err := create.Validate(); err != nil {
+		return nil, err
+	}
+
+	d, err := t.DashboardService.FindDashboardByID(ctx, create.DashboardID)
+	if err != nil {
+		return nil, err
+	}
+
+	auth, err := queryutil.Authorization(ctx, d.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	snap := &Snapshot{
+		OrganizationID: d.OrganizationID,
+		DashboardID:    d.ID,
+		ReportID:       create.ReportID,
+		Name:           create.Name,
+		Description:    create.Description,
+		Start:          create.Start.UTC(),
+		Stop:           create.Stop.UTC(),
+		Cells:          make([]Cell, 0, len(d.Cells)),
+	}
+	if snap.Name == "" {
+		snap.Name = d.Name
+	}
+
+	extern, err := json.Marshal(queryutil.TimeRangeExtern(snap.Start, snap.Stop))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range d.Cells {
+		cell := Cell{
+			ID:           c.ID,
+			CellProperty: c.CellProperty,
+			Results:      []Result{},
+		}
+
+		view, err := t.DashboardService.GetDashboardCellView(ctx, d.ID, c.ID)
+		if err != nil && errors.ErrorCode(err) != errors.ENotFound {
+			return nil, err
+		}
+		if view != nil {
+			cell.Name = view.Name
+			if cell.Properties, err = influxdb.MarshalViewPropertiesJSON(view.Properties); err != nil {
+				return nil, err
+			}
+			for _, q := range viewQueries(view.Properties) {
+				if q.Text == "" {
+					continue
+				}
+				cell.Results = append(cell.Results, t.execute(ctx, auth, snap, extern, q))
+			}
+		}
+		snap.Cells = append(snap.Cells, cell)
+	}
+
+	if err := t.SnapshotService.CreateSnapshot(ctx, snap); err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+// execute executes a query of a cell of a snapshot, returning its result.
+func (t *Taker) execute(ctx context.Context, auth *influxdb.Authorization, snap *Snapshot, extern json.RawMessage, q influxdb.DashboardQuery) Result {
+	res := Result{Name: q.Name, Query: q.Text}
+
+	req := &query.ProxyRequest{
+		Request: query.Request{
+			Authorization:  auth,
+			OrganizationID: snap.OrganizationID,
+			Compiler: lang.FluxCompiler{
+				Now:    snap.Stop,
+				Extern: extern,
+				Query:  q.Text,
+			},
+			Source: "snapshot",
+		},
+		Dialect: csv.Dialect{ResultEncoderConfig: csv.DefaultEncoderConfig()},
+	}
+
+	w := queryutil.NewLimitedBuffer(t.MaxResultBytes)
+	if _, err := t.QueryService.Query(icontext.SetAuthorizer(ctx, auth), w, req); err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	res.CSV = w.String()
+	return res
+}
+
+// viewQueries returns the queries of view properties.
+func viewQueries(props influxdb.ViewProperties) []influxdb.DashboardQuery {
+	switch p := props.(type) {
+	case influxdb.LinePlusSingleStatProperties:
+		return p.Queries
+	case influxdb.XYViewProperties:
+		return p.Queries
+	case influxdb.BandViewProperties:
+		return p.Queries
+	case influxdb.CheckViewProperties:
+		return p.Queries
+	case influxdb.SingleStatViewProperties:
+		return p.Queries
+	case influxdb.HistogramViewProperties:
+		return p.Queries
+	case influxdb.HeatmapViewProperties:
+		return p.Queries
+	case influxdb.ScatterViewProperties:
+		return p.Queries
+	case influxdb.MosaicViewProperties:
+		return p.Queries
+	case influxdb.GaugeViewProperties:
+		return p.Queries
+	case influxdb.GeoViewProperties:
+		return p.Queries
+	case influxdb.TableViewProperties:
+		return p.Queries
+	default:
+		return nil
+	}
+}
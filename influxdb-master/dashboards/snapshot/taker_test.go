@@ -0,0 +1,145 @@
+package snapshot_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/dashboards/snapshot"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/query"
+	querymock "github.com/influxdata/influxdb/v2/query/mock"
+)
+
+func TestTaker_TakeSnapshot(t *testing.T) {
+	dashboards := mock.NewDashboardService()
+	dashboards.FindDashboardByIDF = func(ctx context.Context, id platform.ID) (*influxdb.Dashboard, error) {
+		return &influxdb.Dashboard{
+			ID:             id,
+			OrganizationID: 1,
+			Name:           "dash",
+			Cells: []*influxdb.Cell{
+				{ID: 10, CellProperty: influxdb.CellProperty{W: 4, H: 4}},
+				{ID: 11, CellProperty: influxdb.CellProperty{X: 4, W: 4, H: 4}},
+			},
+		}, nil
+	}
+	dashboards.GetDashboardCellViewF = func(ctx context.Context, dashboardID, cellID platform.ID) (*influxdb.View, error) {
+		return &influxdb.View{
+			ViewContents: influxdb.ViewContents{ID: cellID, Name: "cell " + cellID.String()},
+			Properties: influxdb.XYViewProperties{
+				Type: influxdb.ViewPropertyTypeXY,
+				Queries: []influxdb.DashboardQuery{
+					{Name: "ok", Text: "from(bucket: \"b\") |> range(start: v.timeRangeStart, stop: v.timeRangeStop)"},
+					{Name: "bad", Text: "fail"},
+				},
+			},
+		}, nil
+	}
+
+	var externs []string
+	qs := &querymock.ProxyQueryService{
+		QueryF: func(ctx context.Context, w io.Writer, req *query.ProxyRequest) (flux.Statistics, error) {
+			c := req.Request.Compiler.(lang.FluxCompiler)
+			externs = append(externs, string(c.Extern))
+			if c.Query == "fail" {
+				return flux.Statistics{}, errors.New("compilation failed")
+			}
+			_, err := io.WriteString(w, "#datatype,string\n,result\n,_result\n")
+			return flux.Statistics{}, err
+		},
+	}
+
+	svc := newTestService(t)
+	taker := snapshot.NewTaker(dashboards, svc, qs)
+
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	ctx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{ID: 5, OrgID: 1, UserID: 6, Status: influxdb.Active})
+	snap, err := taker.TakeSnapshot(ctx, snapshot.SnapshotCreate{
+		DashboardID: 2,
+		Start:       start,
+		Stop:        start.Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("failed to take snapshot: %v", err)
+	}
+
+	if snap.Name != "dash" || snap.OrganizationID != 1 {
+		t.Fatalf("unexpected snapshot: %+v", snap)
+	}
+	if len(snap.Cells) != 2 {
+		t.Fatalf("expected 2 cells, got %d", len(snap.Cells))
+	}
+	cell := snap.Cells[1]
+	if cell.X != 4 || cell.Name != "cell "+cell.ID.String() || len(cell.Properties) == 0 {
+		t.Fatalf("unexpected cell: %+v", cell)
+	}
+	if len(cell.Results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(cell.Results))
+	}
+	if res := cell.Results[0]; res.Error != "" || !strings.HasPrefix(res.CSV, "#datatype") {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if res := cell.Results[1]; res.Error != "compilation failed" || res.CSV != "" {
+		t.Fatalf("unexpected failed result: %+v", res)
+	}
+
+	if len(externs) != 4 {
+		t.Fatalf("expected 4 queries, got %d", len(externs))
+	}
+	for _, want := range []string{"timeRangeStart", "2021-01-01T01:00:00Z", "windowPeriod"} {
+		if !strings.Contains(externs[0], want) {
+			t.Fatalf("expected extern to contain %q, got %s", want, externs[0])
+		}
+	}
+
+	stored, err := svc.FindSnapshotByShareToken(context.Background(), snap.ShareToken)
+	if err != nil {
+		t.Fatalf("failed to find stored snapshot: %v", err)
+	}
+	if len(stored.Cells) != 2 || stored.Cells[0].Results[0].CSV != snap.Cells[0].Results[0].CSV {
+		t.Fatalf("unexpected stored snapshot: %+v", stored)
+	}
+}
+
+func TestTaker_TakeSnapshot_MaxResultBytes(t *testing.T) {
+	dashboards := mock.NewDashboardService()
+	dashboards.FindDashboardByIDF = func(ctx context.Context, id platform.ID) (*influxdb.Dashboard, error) {
+		return &influxdb.Dashboard{ID: id, OrganizationID: 1, Cells: []*influxdb.Cell{{ID: 10}}}, nil
+	}
+	dashboards.GetDashboardCellViewF = func(ctx context.Context, dashboardID, cellID platform.ID) (*influxdb.View, error) {
+		return &influxdb.View{
+			Properties: influxdb.TableViewProperties{
+				Type:    influxdb.ViewPropertyTypeTable,
+				Queries: []influxdb.DashboardQuery{{Text: "big"}},
+			},
+		}, nil
+	}
+	qs := &querymock.ProxyQueryService{
+		QueryF: func(ctx context.Context, w io.Writer, req *query.ProxyRequest) (flux.Statistics, error) {
+			_, err := io.WriteString(w, strings.Repeat("x", 100))
+			return flux.Statistics{}, err
+		},
+	}
+
+	taker := snapshot.NewTaker(dashboards, newTestService(t), qs)
+	taker.MaxResultBytes = 10
+
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	ctx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{ID: 5, OrgID: 1, Status: influxdb.Active})
+	snap, err := taker.TakeSnapshot(ctx, snapshot.SnapshotCreate{DashboardID: 2, Start: start, Stop: start.Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("failed to take snapshot: %v", err)
+	}
+	if res := snap.Cells[0].Results[0]; res.CSV != "" || !strings.Contains(res.Error, "maximum size") {
+		t.Fatalf("expected result exceeding maximum size to fail, got %+v", res)
+	}
+}
@@ -16,4 +16,24 @@ type Predicate interface {
 // DeleteService will delete a bucket from the range and predict.
 type DeleteService interface {
 	DeleteBucketRangePredicate(ctx context.Context, orgID, bucketID platform.ID, min, max int64, pred Predicate) error
+
+	// DeleteBucketRangePredicateDryRun returns the series, with at most limit
+	// series listed, that DeleteBucketRangePredicate would delete points of.
+	DeleteBucketRangePredicateDryRun(ctx context.Context, orgID, bucketID platform.ID, min, max int64, pred Predicate, limit int) (*DeleteDryRun, error)
+}
+
+// DeleteDryRun is the result of a delete dry run.
+type DeleteDryRun struct {
+	// SeriesN is the number of series with points to delete, which may be
+	// more than are listed in Series.
+	SeriesN int                  `json:"seriesN"`
+	Series  []DeleteDryRunSeries `json:"series"`
+}
+
+// DeleteDryRunSeries is a series with points to delete, and the fields of
+// those points.
+type DeleteDryRunSeries struct {
+	Measurement string            `json:"measurement"`
+	Tags        map[string]string `json:"tags"`
+	Fields      []string          `json:"fields"`
 }
@@ -0,0 +1,48 @@
+package influxdb
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/kit/platform"
+)
+
+// DigestOptions are the options of the digest of a bucket.
+type DigestOptions struct {
+	// Start and Stop bound the timestamps, in nanoseconds, of the points
+	// digested. Both are inclusive.
+	Start, Stop int64
+
+	// Window is the duration of the time windows of a series field that are
+	// digested independently. The digests of two buckets can only be compared
+	// if they have the same window.
+	Window time.Duration
+}
+
+// DigestRange is a time range of the points of a series field, identified by
+// its TSM key, that differs between the digests of two buckets.
+type DigestRange struct {
+	Key string `json:"key"`
+	// Start and Stop are inclusive, in nanoseconds.
+	Start int64 `json:"start"`
+	Stop  int64 `json:"stop"`
+}
+
+// DigestService computes digests of the data of buckets, which locate the
+// series and time ranges whose points differ between two copies of a bucket,
+// and exports and deletes the points of these ranges to repair a copy.
+type DigestService interface {
+	// BucketDigest writes the windowed digest of the points of a bucket to w,
+	// in the format of the windowed digests of the tsm1 engine.
+	BucketDigest(ctx context.Context, bucketID platform.ID, opts DigestOptions, w io.Writer) error
+
+	// ExportDigestRanges writes the points of the ranges of a bucket to w as
+	// line protocol.
+	ExportDigestRanges(ctx context.Context, bucketID platform.ID, ranges []DigestRange, w io.Writer) error
+
+	// DeleteDigestRanges deletes the points of the ranges of a bucket, which
+	// repairs a copy once the points exported from the other copy for the
+	// same ranges are written to it.
+	DeleteDigestRanges(ctx context.Context, bucketID platform.ID, ranges []DigestRange) error
+}
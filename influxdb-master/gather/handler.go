@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"time"
 
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/nats"
@@ -15,6 +16,7 @@ type handler struct {
 	Scraper   Scraper
 	Publisher nats.Publisher
 	log       *zap.Logger
+	health    *HealthTracker
 }
 
 // Process consumes scraper target from scraper target queue,
@@ -29,10 +31,25 @@ func (h *handler) Process(s nats.Subscription, m nats.Message) {
 		return
 	}
 
-	ms, err := h.Scraper.Gather(context.TODO(), *req)
+	timeout := req.Timeout.Duration
+	if timeout == 0 {
+		timeout = influxdb.DefaultScraperTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	ms, err := h.Scraper.Gather(ctx, *req)
+	health := h.health.record(*req, start, time.Since(start), ms.MetricsSlice.samples(), err)
 	if err != nil {
-		h.log.Error("Unable to gather", zap.Error(err))
-		return
+		h.log.Error("Unable to gather", zap.String("scraper", req.Name), zap.Error(err))
+		if !req.WriteHealth {
+			return
+		}
+		ms = MetricsCollection{OrgID: req.OrgID, BucketID: req.BucketID}
+	}
+	if req.WriteHealth {
+		ms.MetricsSlice = append(ms.MetricsSlice, healthMetrics(*req, health))
 	}
 
 	// send metrics to recorder queue
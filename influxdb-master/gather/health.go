@@ -0,0 +1,174 @@
+package gather
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// HealthTracker records the health of the last scrape of each target.
+// implements influxdb.ScraperHealthService.
+type HealthTracker struct {
+	mu      sync.RWMutex
+	targets map[platform.ID]*targetHealth
+
+	up        *prometheus.GaugeVec
+	timestamp *prometheus.GaugeVec
+	duration  *prometheus.GaugeVec
+	samples   *prometheus.GaugeVec
+	scrapes   *prometheus.CounterVec
+}
+
+type targetHealth struct {
+	name   string
+	health influxdb.ScraperTargetHealth
+}
+
+// NewHealthTracker returns a tracker without any scraped target.
+func NewHealthTracker() *HealthTracker {
+	const namespace = "scraper"
+	labels := []string{"scraper_id", "scraper"}
+	return &HealthTracker{
+		targets: make(map[platform.ID]*targetHealth),
+		up: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "up",
+			Help:      "Whether the last scrape of a target succeeded.",
+		}, labels),
+		timestamp: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "last_scrape_timestamp_seconds",
+			Help:      "Unix time of the last scrape of a target.",
+		}, labels),
+		duration: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "last_scrape_duration_seconds",
+			Help:      "Duration of the last scrape of a target.",
+		}, labels),
+		samples: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "last_scrape_samples",
+			Help:      "Number of samples of the last scrape of a target.",
+		}, labels),
+		scrapes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "scrapes_total",
+			Help:      "Number of scrapes of a target by status.",
+		}, append(labels, "status")),
+	}
+}
+
+// PrometheusCollectors returns the metrics of the health of the targets.
+func (h *HealthTracker) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{h.up, h.timestamp, h.duration, h.samples, h.scrapes}
+}
+
+// ScraperTargetHealth returns the health of the last scrape of a target.
+func (h *HealthTracker) ScraperTargetHealth(ctx context.Context, id platform.ID) (*influxdb.ScraperTargetHealth, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	t, ok := h.targets[id]
+	if !ok {
+		return &influxdb.ScraperTargetHealth{Status: influxdb.ScraperHealthUnknown}, nil
+	}
+	health := t.health
+	return &health, nil
+}
+
+// record records a scrape of a target, which started at start and took
+// duration, and returns its health.
+func (h *HealthTracker) record(target influxdb.ScraperTarget, start time.Time, duration time.Duration, samples int, err error) influxdb.ScraperTargetHealth {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	t, ok := h.targets[target.ID]
+	if !ok {
+		t = &targetHealth{name: target.Name}
+		h.targets[target.ID] = t
+	} else if t.name != target.Name {
+		h.deleteMetrics(target.ID, t.name)
+		t.name = target.Name
+	}
+
+	t.health.LastScrape = &start
+	t.health.LastScrapeDuration = influxdb.Duration{Duration: duration}
+	t.health.Samples = samples
+	status, up := "success", 1.0
+	if err != nil {
+		t.health.Status = influxdb.ScraperHealthDown
+		t.health.LastError = err.Error()
+		status, up = "error", 0
+	} else {
+		t.health.Status = influxdb.ScraperHealthUp
+		t.health.LastError = ""
+		t.health.LastSuccess = &start
+	}
+	labels := prometheus.Labels{"scraper_id": target.ID.String(), "scraper": target.Name}
+	h.up.With(labels).Set(up)
+	h.timestamp.With(labels).Set(float64(start.UnixNano()) / 1e9)
+	h.duration.With(labels).Set(duration.Seconds())
+	h.samples.With(labels).Set(float64(samples))
+	labels["status"] = status
+	h.scrapes.With(labels).Inc()
+	return t.health
+}
+
+// prune forgets the targets that are not in targets.
+func (h *HealthTracker) prune(targets []influxdb.ScraperTarget) {
+	ids := make(map[platform.ID]bool, len(targets))
+	for _, t := range targets {
+		ids[t.ID] = true
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for id, t := range h.targets {
+		if !ids[id] {
+			h.deleteMetrics(id, t.name)
+			delete(h.targets, id)
+		}
+	}
+}
+
+func (h *HealthTracker) deleteMetrics(id platform.ID, name string) {
+	labels := prometheus.Labels{"scraper_id": id.String(), "scraper": name}
+	h.up.Delete(labels)
+	h.timestamp.Delete(labels)
+	h.duration.Delete(labels)
+	h.samples.Delete(labels)
+	for _, status := range []string{"success", "error"} {
+		labels["status"] = status
+		h.scrapes.Delete(labels)
+	}
+}
+
+// healthMetrics returns the metrics of the ScraperHealthMeasurement of a
+// scrape of a target.
+func healthMetrics(target influxdb.ScraperTarget, health influxdb.ScraperTargetHealth) Metrics {
+	m := Metrics{
+		Name: influxdb.ScraperHealthMeasurement,
+		Tags: map[string]string{
+			"scraper":    target.Name,
+			"scraper_id": target.ID.String(),
+		},
+		Fields: map[string]interface{}{
+			"up":               0.0,
+			"duration_seconds": health.LastScrapeDuration.Seconds(),
+			"samples":          float64(health.Samples),
+		},
+		Timestamp: *health.LastScrape,
+		Type:      MetricTypeGauge,
+	}
+	if health.Status == influxdb.ScraperHealthUp {
+		m.Fields["up"] = 1.0
+	} else {
+		m.Fields["error"] = health.LastError
+	}
+	if target.Name == "" {
+		delete(m.Tags, "scraper")
+	}
+	return m
+}
@@ -0,0 +1,136 @@
+package gather
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/mock"
+	influxdbtesting "github.com/influxdata/influxdb/v2/testing"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/zap/zaptest"
+)
+
+// collectingRecorder passes the metrics it records to a channel.
+type collectingRecorder chan MetricsCollection
+
+func (r collectingRecorder) Record(collected MetricsCollection) error {
+	r <- collected
+	return nil
+}
+
+func TestScheduler_Health(t *testing.T) {
+	publisher, subscriber := mock.NewNats()
+	logger := zaptest.NewLogger(t)
+	ts := httptest.NewServer(&mockHTTPHandler{
+		responseMap: map[string]string{
+			"/metrics": sampleRespSmall,
+		},
+	})
+	defer ts.Close()
+
+	upID := influxdbtesting.MustIDBase16("3a0d0a6365646120")
+	downID := influxdbtesting.MustIDBase16("3a0d0a6365646121")
+	storage := &mockStorage{
+		Targets: []influxdb.ScraperTarget{
+			{
+				ID:          upID,
+				Name:        "up",
+				Type:        influxdb.PrometheusScraperType,
+				URL:         ts.URL + "/metrics",
+				OrgID:       *orgID,
+				BucketID:    *bucketID,
+				WriteHealth: true,
+			},
+			{
+				ID:          downID,
+				Name:        "down",
+				Type:        influxdb.PrometheusScraperType,
+				URL:         ts.URL + "/missing",
+				OrgID:       *orgID,
+				BucketID:    *bucketID,
+				WriteHealth: true,
+			},
+		},
+	}
+
+	recorder := make(collectingRecorder, 10)
+	subscriber.Subscribe(MetricsSubject, "", &RecorderHandler{
+		log:      logger,
+		Recorder: recorder,
+	})
+
+	scheduler, err := NewScheduler(logger, 1, storage, publisher, subscriber, time.Millisecond, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go scheduler.run(ctx)
+	scheduler.gather <- struct{}{}
+
+	healths := make(map[string]Metrics)
+	for i := 0; i < 2; i++ {
+		select {
+		case collected := <-recorder:
+			for _, m := range collected.MetricsSlice {
+				if m.Name == influxdb.ScraperHealthMeasurement {
+					healths[m.Tags["scraper"]] = m
+				}
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for scrapes")
+		}
+	}
+
+	if m := healths["up"]; m.Fields["up"] != 1.0 || m.Fields["samples"] != 1.0 || m.Tags["scraper_id"] != upID.String() {
+		t.Fatalf("unexpected health of up target: %+v", m)
+	}
+	if m := healths["down"]; m.Fields["up"] != 0.0 || !strings.Contains(m.Fields["error"].(string), "404 Not Found") {
+		t.Fatalf("unexpected health of down target: %+v", m)
+	}
+
+	up, err := scheduler.Health.ScraperTargetHealth(ctx, upID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if up.Status != influxdb.ScraperHealthUp || up.Samples != 1 || up.LastScrape == nil || up.LastSuccess == nil || up.LastError != "" {
+		t.Fatalf("unexpected health of up target: %+v", up)
+	}
+	down, err := scheduler.Health.ScraperTargetHealth(ctx, downID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if down.Status != influxdb.ScraperHealthDown || down.LastSuccess != nil || !strings.Contains(down.LastError, "404 Not Found") {
+		t.Fatalf("unexpected health of down target: %+v", down)
+	}
+
+	if got := testutil.ToFloat64(scheduler.Health.up.WithLabelValues(upID.String(), "up")); got != 1 {
+		t.Fatalf("unexpected up metric of up target: %v", got)
+	}
+	if got := testutil.ToFloat64(scheduler.Health.scrapes.WithLabelValues(downID.String(), "down", "error")); got != 1 {
+		t.Fatalf("unexpected error scrapes of down target: %v", got)
+	}
+
+	// A removed target is forgotten.
+	storage.RemoveTarget(ctx, downID)
+	scheduler.gather <- struct{}{}
+	select {
+	case <-recorder:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for scrape")
+	}
+	down, err = scheduler.Health.ScraperTargetHealth(ctx, downID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if down.Status != influxdb.ScraperHealthUnknown {
+		t.Fatalf("unexpected health of removed target: %+v", down)
+	}
+	if n := testutil.CollectAndCount(scheduler.Health.up); n != 1 {
+		t.Fatalf("unexpected number of up metrics: %d", n)
+	}
+}
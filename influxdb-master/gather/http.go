@@ -0,0 +1,105 @@
+package gather
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+// maxErrorBodySize is the size of the start of the body of an error response
+// a scraper reports.
+const maxErrorBodySize = 512
+
+// httpClients are the clients of the targets without TLS options, which
+// share connections, by whether they allow insecure connections.
+var httpClients = func() map[bool]*http.Client {
+	insecure := http.DefaultTransport.(*http.Transport).Clone()
+	insecure.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	return map[bool]*http.Client{
+		false: {Transport: http.DefaultTransport},
+		true:  {Transport: insecure},
+	}
+}()
+
+// get requests the target with its headers and TLS options, and returns the
+// response if it is successful.
+func get(ctx context.Context, target influxdb.ScraperTarget, accept string) (*http.Response, error) {
+	client, shared, err := newHTTPClient(target)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	for k, v := range target.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if !shared {
+		resp.Body = &closeIdleBody{ReadCloser: resp.Body, client: client}
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		return nil, fmt.Errorf("unexpected status %s: %s", resp.Status, body)
+	}
+	return resp, nil
+}
+
+// closeIdleBody closes the connections of the client of a target with TLS
+// options when its response is closed.
+type closeIdleBody struct {
+	io.ReadCloser
+	client *http.Client
+}
+
+func (b *closeIdleBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.client.CloseIdleConnections()
+	return err
+}
+
+// newHTTPClient returns the client of a target, and whether it is shared
+// with other targets.
+func newHTTPClient(target influxdb.ScraperTarget) (*http.Client, bool, error) {
+	if target.TLS == nil {
+		return httpClients[target.AllowInsecure], true, nil
+	}
+
+	config := &tls.Config{
+		InsecureSkipVerify: target.AllowInsecure,
+		ServerName:         target.TLS.ServerName,
+	}
+	if target.TLS.CACert != "" {
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM([]byte(target.TLS.CACert)) {
+			return nil, false, fmt.Errorf("invalid tls ca certificate")
+		}
+	}
+	if target.TLS.Cert != "" || target.TLS.Key != "" {
+		cert, err := tls.X509KeyPair([]byte(target.TLS.Cert), []byte(target.TLS.Key))
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid tls certificate: %v", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = config
+	return &http.Client{Transport: transport}, false, nil
+}
@@ -0,0 +1,226 @@
+package gather
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/pkg/jsonpath"
+)
+
+// jsonScraper extracts metrics from the JSON response of a target, as
+// configured by its influxdb.ScraperJSONConfig.
+// implements Scraper interfaces.
+type jsonScraper struct{}
+
+func newJSONScraper() *jsonScraper {
+	return &jsonScraper{}
+}
+
+// Gather parse metrics from a scraper target url.
+func (s *jsonScraper) Gather(ctx context.Context, target influxdb.ScraperTarget) (collected MetricsCollection, err error) {
+	if target.JSON == nil {
+		return collected, fmt.Errorf("json scraper target %q has no json config", target.Name)
+	}
+
+	resp, err := get(ctx, target, "application/json")
+	if err != nil {
+		return collected, err
+	}
+	defer resp.Body.Close()
+
+	ms, err := parseJSONMetrics(resp.Body, *target.JSON, target.Name, time.Now())
+	if err != nil {
+		return collected, err
+	}
+	return MetricsCollection{
+		MetricsSlice: ms,
+		OrgID:        target.OrgID,
+		BucketID:     target.BucketID,
+	}, nil
+}
+
+// jsonSelectors are the parsed selectors of an influxdb.ScraperJSONConfig.
+type jsonSelectors struct {
+	path   *jsonpath.Path
+	tags   map[string]*jsonpath.Path
+	fields map[string]*jsonpath.Path
+	time   *jsonpath.Path
+}
+
+func parseJSONSelectors(c influxdb.ScraperJSONConfig) (*jsonSelectors, error) {
+	if err := c.Valid(); err != nil {
+		return nil, err
+	}
+
+	s := &jsonSelectors{
+		tags:   make(map[string]*jsonpath.Path, len(c.Tags)),
+		fields: make(map[string]*jsonpath.Path, len(c.Fields)),
+	}
+	var err error
+	if c.Path != "" {
+		if s.path, err = jsonpath.Parse(c.Path); err != nil {
+			return nil, err
+		}
+	}
+	for k, sel := range c.Tags {
+		if s.tags[k], err = jsonpath.Parse(sel); err != nil {
+			return nil, err
+		}
+	}
+	for k, sel := range c.Fields {
+		if s.fields[k], err = jsonpath.Parse(sel); err != nil {
+			return nil, err
+		}
+	}
+	if c.Time != "" {
+		if s.time, err = jsonpath.Parse(c.Time); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// parseJSONMetrics returns a metric of each object of a JSON document the
+// config selects, of the measurement of the config or else the name of the
+// target. Numbers become float fields, and strings and booleans fields of
+// their type. Objects without any field are skipped.
+func parseJSONMetrics(r io.Reader, c influxdb.ScraperJSONConfig, name string, now time.Time) ([]Metrics, error) {
+	sel, err := parseJSONSelectors(c)
+	if err != nil {
+		return nil, err
+	}
+
+	measurement := c.Measurement
+	if measurement == "" {
+		measurement = name
+	}
+	if measurement == "" {
+		measurement = influxdb.JSONScraperType
+	}
+
+	var root interface{}
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	if err := dec.Decode(&root); err != nil {
+		return nil, fmt.Errorf("reading json failed: %s", err)
+	}
+
+	objects := []interface{}{root}
+	if sel.path != nil {
+		objects = sel.path.Select(root, root)
+	}
+
+	ms := make([]Metrics, 0, len(objects))
+	for _, obj := range objects {
+		m := Metrics{
+			Name:      measurement,
+			Tags:      make(map[string]string, len(sel.tags)),
+			Fields:    make(map[string]interface{}, len(sel.fields)),
+			Timestamp: now,
+			Type:      MetricTypeUntyped,
+		}
+		for k, p := range sel.fields {
+			v, ok := p.Get(root, obj)
+			if !ok {
+				continue
+			}
+			if fv, ok := jsonFieldValue(v); ok {
+				m.Fields[k] = fv
+			}
+		}
+		if len(m.Fields) == 0 {
+			continue
+		}
+
+		for k, p := range sel.tags {
+			v, ok := p.Get(root, obj)
+			if !ok {
+				continue
+			}
+			if tv, ok := jsonTagValue(v); ok && tv != "" {
+				m.Tags[k] = tv
+			}
+		}
+
+		if sel.time != nil {
+			if v, ok := sel.time.Get(root, obj); ok && v != nil {
+				ts, err := parseJSONTime(v, c.TimeFormat)
+				if err != nil {
+					return nil, err
+				}
+				m.Timestamp = ts
+			}
+		}
+		ms = append(ms, m)
+	}
+	return ms, nil
+}
+
+func jsonFieldValue(v interface{}) (interface{}, bool) {
+	switch v := v.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case string, bool:
+		return v, true
+	default:
+		return nil, false
+	}
+}
+
+func jsonTagValue(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case json.Number:
+		return v.String(), true
+	case string:
+		return v, true
+	case bool:
+		return strconv.FormatBool(v), true
+	default:
+		return "", false
+	}
+}
+
+// parseJSONTime parses a timestamp of a format of influxdb.ScraperJSONConfig.
+func parseJSONTime(v interface{}, format string) (time.Time, error) {
+	var s string
+	switch v := v.(type) {
+	case json.Number:
+		s = v.String()
+	case string:
+		s = v
+	default:
+		return time.Time{}, fmt.Errorf("invalid json timestamp %v", v)
+	}
+
+	var unit time.Duration
+	switch format {
+	case "", influxdb.ScraperTimeFormatRFC3339:
+		return time.Parse(time.RFC3339Nano, s)
+	case influxdb.ScraperTimeFormatUnix:
+		sec, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid json unix timestamp %q", s)
+		}
+		return time.Unix(0, int64(sec*float64(time.Second))), nil
+	case influxdb.ScraperTimeFormatUnixMs:
+		unit = time.Millisecond
+	case influxdb.ScraperTimeFormatUnixUs:
+		unit = time.Microsecond
+	case influxdb.ScraperTimeFormatUnixNs:
+		unit = time.Nanosecond
+	default:
+		return time.Parse(format, s)
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid json %s timestamp %q", format, s)
+	}
+	return time.Unix(0, n*int64(unit)), nil
+}
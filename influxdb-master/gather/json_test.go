@@ -0,0 +1,170 @@
+package gather
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/v2"
+)
+
+const sampleJSON = `{
+	"station": "gs1",
+	"updated": "2020-11-13T15:30:00Z",
+	"antennas": [
+		{"name": "a1", "azimuth": 120.5, "locked": true, "mode": "track", "time": 1605281400123},
+		{"name": "a2", "azimuth": 240, "locked": false, "mode": null, "time": 1605281400456},
+		{"name": "a3", "status": "offline"}
+	]
+}`
+
+func TestParseJSONMetrics(t *testing.T) {
+	now := time.Unix(1605281400, 0)
+	tests := []struct {
+		name   string
+		config influxdb.ScraperJSONConfig
+		exp    []Metrics
+		err    string
+	}{
+		{
+			name: "root",
+			config: influxdb.ScraperJSONConfig{
+				Tags:   map[string]string{"station": "$.station"},
+				Fields: map[string]string{"azimuth": "$.antennas[0].azimuth", "antennas": "$.antennas[-1].name"},
+				Time:   "$.updated",
+			},
+			exp: []Metrics{
+				{
+					Name:      "simulator",
+					Type:      MetricTypeUntyped,
+					Tags:      map[string]string{"station": "gs1"},
+					Fields:    map[string]interface{}{"azimuth": 120.5, "antennas": "a3"},
+					Timestamp: time.Date(2020, 11, 13, 15, 30, 0, 0, time.UTC),
+				},
+			},
+		},
+		{
+			name: "path",
+			config: influxdb.ScraperJSONConfig{
+				Measurement: "antenna",
+				Path:        "$.antennas[*]",
+				Tags:        map[string]string{"station": "$.station", "name": "@.name", "mode": "@.mode"},
+				Fields:      map[string]string{"azimuth": "@.azimuth", "locked": "@.locked"},
+				Time:        "@.time",
+				TimeFormat:  influxdb.ScraperTimeFormatUnixMs,
+			},
+			exp: []Metrics{
+				{
+					Name:      "antenna",
+					Type:      MetricTypeUntyped,
+					Tags:      map[string]string{"station": "gs1", "name": "a1", "mode": "track"},
+					Fields:    map[string]interface{}{"azimuth": 120.5, "locked": true},
+					Timestamp: time.Unix(0, 1605281400123000000),
+				},
+				{
+					Name:      "antenna",
+					Type:      MetricTypeUntyped,
+					Tags:      map[string]string{"station": "gs1", "name": "a2"},
+					Fields:    map[string]interface{}{"azimuth": 240.0, "locked": false},
+					Timestamp: time.Unix(0, 1605281400456000000),
+				},
+			},
+		},
+		{
+			name: "invalid time",
+			config: influxdb.ScraperJSONConfig{
+				Fields:     map[string]string{"station": "$.station"},
+				Time:       "$.station",
+				TimeFormat: influxdb.ScraperTimeFormatUnix,
+			},
+			err: `invalid json unix timestamp "gs1"`,
+		},
+		{
+			name: "wildcard field",
+			config: influxdb.ScraperJSONConfig{
+				Fields: map[string]string{"azimuth": "$.antennas[*].azimuth"},
+			},
+			err: `selector of field "azimuth" must select a single value`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms, err := parseJSONMetrics(strings.NewReader(sampleJSON), tt.config, "simulator", now)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("unexpected error: got=%v exp=%s", err, tt.err)
+				}
+				return
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.exp, ms); diff != "" {
+				t.Fatalf("unexpected metrics: -want/+got\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestJSONScraper_TLS(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(sampleJSON))
+	}))
+	defer ts.Close()
+	caCert := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw}))
+
+	target := influxdb.ScraperTarget{
+		Name:     "simulator",
+		Type:     influxdb.JSONScraperType,
+		URL:      ts.URL,
+		OrgID:    *orgID,
+		BucketID: *bucketID,
+		Headers:  map[string]string{"Authorization": "Bearer secret"},
+		TLS:      &influxdb.ScraperTLSConfig{CACert: caCert},
+		JSON: &influxdb.ScraperJSONConfig{
+			Fields: map[string]string{"azimuth": "$.antennas[0].azimuth"},
+		},
+	}
+	if err := target.Valid(); err != nil {
+		t.Fatal(err)
+	}
+
+	collected, err := newJSONScraper().Gather(context.Background(), target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Metrics{
+		Name:   "simulator",
+		Type:   MetricTypeUntyped,
+		Tags:   map[string]string{},
+		Fields: map[string]interface{}{"azimuth": 120.5},
+	}
+	if len(collected.MetricsSlice) != 1 {
+		t.Fatalf("unexpected metrics: %v", collected.MetricsSlice)
+	}
+	if diff := cmp.Diff(collected.MetricsSlice[0], want, metricsCmpOption); diff != "" {
+		t.Fatalf("scraper parse metrics want %v, got %v", want, collected.MetricsSlice[0])
+	}
+
+	// The certificate of the server is not trusted without its CA.
+	target.TLS = nil
+	if _, err := newJSONScraper().Gather(context.Background(), target); err == nil {
+		t.Fatal("expected certificate error")
+	}
+
+	target.AllowInsecure = true
+	target.Headers = nil
+	if _, err := newJSONScraper().Gather(context.Background(), target); err == nil || !strings.Contains(err.Error(), "401 Unauthorized") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -44,6 +44,16 @@ func (ms MetricsSlice) Points() (models.Points, error) {
 	return ps, nil
 }
 
+// samples returns the number of samples of the metrics, which is the number
+// of their fields.
+func (ms MetricsSlice) samples() int {
+	var n int
+	for _, m := range ms {
+		n += len(m.Fields)
+	}
+	return n
+}
+
 // Reader returns an io.Reader that enumerates the metrics.
 // All metrics are allocated into the underlying buffer.
 func (ms MetricsSlice) Reader() (io.Reader, error) {
@@ -0,0 +1,414 @@
+package gather
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+// openMetricsAccept prefers the OpenMetrics text format, and accepts the
+// Prometheus text format of targets that do not support it.
+const openMetricsAccept = "application/openmetrics-text; version=1.0.0,text/plain;version=0.0.4;q=0.5,*/*;q=0.1"
+
+// openMetricsScraper parses metrics of the OpenMetrics text format, or of
+// the Prometheus formats if a target responds with them.
+// implements Scraper interfaces.
+type openMetricsScraper struct {
+	prometheus *prometheusScraper
+}
+
+func newOpenMetricsScraper() *openMetricsScraper {
+	return &openMetricsScraper{prometheus: newPrometheusScraper()}
+}
+
+// Gather parse metrics from a scraper target url.
+func (s *openMetricsScraper) Gather(ctx context.Context, target influxdb.ScraperTarget) (collected MetricsCollection, err error) {
+	resp, err := get(ctx, target, openMetricsAccept)
+	if err != nil {
+		return collected, err
+	}
+	defer resp.Body.Close()
+
+	mediatype, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || mediatype != "application/openmetrics-text" {
+		return s.prometheus.parse(resp.Body, resp.Header, target)
+	}
+
+	ms, err := parseOpenMetrics(resp.Body, time.Now())
+	if err != nil {
+		return collected, err
+	}
+	return MetricsCollection{
+		MetricsSlice: ms,
+		OrgID:        target.OrgID,
+		BucketID:     target.BucketID,
+	}, nil
+}
+
+// parseOpenMetrics parses the OpenMetrics text format. Like the prometheus
+// scraper, each metric family becomes a measurement, and the samples of a
+// metric with the same labels and timestamp become the fields of a metric:
+//
+//   - counter: counter, and created of the _created sample
+//   - gauge: gauge
+//   - unknown: value
+//   - stateset: state, with the state as tag
+//   - info: info
+//   - summary: a field per quantile, count, sum and created
+//   - histogram: a field per bucket upper bound, count, sum and created
+//   - gaugehistogram: a field per bucket upper bound, count and sum
+//
+// The unit of a metric family is added as the unit tag. Exemplars become
+// metrics of the <family>_exemplar measurement, tagged with the labels of
+// their sample, with the value field and a string field per label.
+func parseOpenMetrics(r io.Reader, now time.Time) ([]Metrics, error) {
+	p := &openMetricsParser{now: now, index: make(map[string]int)}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	var eof bool
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		if eof {
+			return nil, fmt.Errorf("openmetrics line %d: content after # EOF", n)
+		}
+
+		var err error
+		switch {
+		case line == "# EOF":
+			eof = true
+		case line == "":
+		case strings.HasPrefix(line, "#"):
+			err = p.parseMetadata(line)
+		default:
+			err = p.parseSample(line)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("openmetrics line %d: %v", n, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if !eof {
+		return nil, fmt.Errorf("openmetrics exposition is missing # EOF")
+	}
+	return p.metrics, nil
+}
+
+type openMetricsFamily struct {
+	name string
+	typ  string
+	unit string
+}
+
+// openMetricsSuffixes are the suffixes of the sample names of each metric
+// type, with the field they become.
+var openMetricsSuffixes = map[string]map[string]string{
+	"counter":        {"_total": "counter", "_created": "created"},
+	"gauge":          {"": "gauge"},
+	"unknown":        {"": "value"},
+	"stateset":       {"": "state"},
+	"info":           {"_info": "info"},
+	"summary":        {"": "", "_count": "count", "_sum": "sum", "_created": "created"},
+	"histogram":      {"_bucket": "", "_count": "count", "_sum": "sum", "_created": "created"},
+	"gaugehistogram": {"_bucket": "", "_gcount": "count", "_gsum": "sum"},
+}
+
+var openMetricsTypes = map[string]MetricType{
+	"counter":        MetricTypeCounter,
+	"gauge":          MetricTypeGauge,
+	"unknown":        MetricTypeUntyped,
+	"stateset":       MetricTypeGauge,
+	"info":           MetricTypeGauge,
+	"summary":        MetricTypeSummary,
+	"histogram":      MetricTypeHistogrm,
+	"gaugehistogram": MetricTypeHistogrm,
+}
+
+type openMetricsParser struct {
+	now     time.Time
+	family  *openMetricsFamily
+	metrics []Metrics
+	// index is the index of the metric of a family, tags and timestamp.
+	index map[string]int
+}
+
+func (p *openMetricsParser) parseMetadata(line string) error {
+	parts := strings.SplitN(line, " ", 4)
+	if len(parts) < 3 || parts[0] != "#" {
+		return fmt.Errorf("invalid metadata %q", line)
+	}
+	keyword, name := parts[1], parts[2]
+	var value string
+	if len(parts) == 4 {
+		value = parts[3]
+	}
+
+	if p.family == nil || p.family.name != name {
+		p.family = &openMetricsFamily{name: name, typ: "unknown"}
+	}
+	switch keyword {
+	case "TYPE":
+		if _, ok := openMetricsSuffixes[value]; !ok {
+			return fmt.Errorf("unknown type %q of metric family %q", value, name)
+		}
+		p.family.typ = value
+	case "UNIT":
+		p.family.unit = value
+	case "HELP":
+	default:
+		return fmt.Errorf("unknown metadata %q", keyword)
+	}
+	return nil
+}
+
+// familyOf returns the family of a sample name, and the field the sample
+// becomes.
+func (p *openMetricsParser) familyOf(name string) (*openMetricsFamily, string) {
+	if f := p.family; f != nil && strings.HasPrefix(name, f.name) {
+		if field, ok := openMetricsSuffixes[f.typ][name[len(f.name):]]; ok {
+			return f, field
+		}
+	}
+	p.family = &openMetricsFamily{name: name, typ: "unknown"}
+	return p.family, "value"
+}
+
+type openMetricsLabel struct {
+	name, value string
+}
+
+func (p *openMetricsParser) parseSample(line string) error {
+	i := strings.IndexAny(line, "{ ")
+	if i <= 0 {
+		return fmt.Errorf("invalid sample %q", line)
+	}
+	name := line[:i]
+
+	var labels []openMetricsLabel
+	rest := line[i:]
+	if rest[0] == '{' {
+		var err error
+		if labels, rest, err = parseOpenMetricsLabels(rest); err != nil {
+			return err
+		}
+	}
+
+	var exemplar string
+	if j := strings.Index(rest, " # "); j >= 0 {
+		rest, exemplar = rest[:j], rest[j+3:]
+	}
+	value, ts, err := parseOpenMetricsValue(rest, p.now)
+	if err != nil {
+		return err
+	}
+
+	family, field := p.familyOf(name)
+	tags := make(map[string]string, len(labels)+1)
+	for _, l := range labels {
+		tags[l.name] = l.value
+	}
+	if family.unit != "" {
+		tags["unit"] = family.unit
+	}
+
+	if exemplar != "" {
+		if err := p.addExemplar(family, tags, exemplar, ts); err != nil {
+			return err
+		}
+	}
+
+	if field == "" {
+		bound := "le"
+		if family.typ == "summary" {
+			bound = "quantile"
+		}
+		v, ok := tags[bound]
+		if !ok {
+			return fmt.Errorf("sample %q of %s %q has no %s label", name, family.typ, family.name, bound)
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return fmt.Errorf("invalid %s label %q of sample %q", bound, v, name)
+		}
+		delete(tags, bound)
+		field = fmt.Sprint(f)
+	}
+	if math.IsNaN(value) {
+		return nil
+	}
+
+	key := metricKey(family.name, tags, ts)
+	i, ok := p.index[key]
+	if !ok {
+		i = len(p.metrics)
+		p.index[key] = i
+		p.metrics = append(p.metrics, Metrics{
+			Name:      family.name,
+			Tags:      tags,
+			Fields:    make(map[string]interface{}),
+			Timestamp: ts,
+			Type:      openMetricsTypes[family.typ],
+		})
+	}
+	p.metrics[i].Fields[field] = value
+	return nil
+}
+
+func (p *openMetricsParser) addExemplar(family *openMetricsFamily, tags map[string]string, exemplar string, ts time.Time) error {
+	if exemplar[0] != '{' {
+		return fmt.Errorf("invalid exemplar %q", exemplar)
+	}
+	labels, rest, err := parseOpenMetricsLabels(exemplar)
+	if err != nil {
+		return fmt.Errorf("invalid exemplar %q: %v", exemplar, err)
+	}
+	value, ets, err := parseOpenMetricsValue(rest, ts)
+	if err != nil {
+		return fmt.Errorf("invalid exemplar %q: %v", exemplar, err)
+	}
+
+	m := Metrics{
+		Name:      family.name + "_exemplar",
+		Tags:      make(map[string]string, len(tags)),
+		Fields:    make(map[string]interface{}, len(labels)+1),
+		Timestamp: ets,
+		Type:      MetricTypeUntyped,
+	}
+	for k, v := range tags {
+		m.Tags[k] = v
+	}
+	for _, l := range labels {
+		m.Fields[l.name] = l.value
+	}
+	m.Fields["value"] = value
+	p.metrics = append(p.metrics, m)
+	return nil
+}
+
+// parseOpenMetricsLabels parses the labels at the start of s, and returns the
+// rest of s.
+func parseOpenMetricsLabels(s string) ([]openMetricsLabel, string, error) {
+	var labels []openMetricsLabel
+	i := 1
+	for {
+		if i >= len(s) {
+			return nil, "", fmt.Errorf("unterminated labels %q", s)
+		} else if s[i] == '}' {
+			return labels, s[i+1:], nil
+		}
+
+		eq := strings.IndexByte(s[i:], '=')
+		if eq <= 0 || i+eq+1 >= len(s) || s[i+eq+1] != '"' {
+			return nil, "", fmt.Errorf("invalid labels %q", s)
+		}
+		name := s[i : i+eq]
+		i += eq + 2
+
+		var value strings.Builder
+		for ; i < len(s) && s[i] != '"'; i++ {
+			if s[i] != '\\' {
+				value.WriteByte(s[i])
+				continue
+			}
+			if i++; i >= len(s) {
+				break
+			}
+			switch s[i] {
+			case 'n':
+				value.WriteByte('\n')
+			case '\\', '"':
+				value.WriteByte(s[i])
+			default:
+				return nil, "", fmt.Errorf("invalid escape \\%c in labels %q", s[i], s)
+			}
+		}
+		if i >= len(s) {
+			return nil, "", fmt.Errorf("unterminated label value in %q", s)
+		}
+		labels = append(labels, openMetricsLabel{name: name, value: value.String()})
+
+		if i++; i < len(s) && s[i] == ',' {
+			i++
+		}
+	}
+}
+
+// parseOpenMetricsValue parses the value and optional timestamp, in seconds,
+// of a sample or exemplar. The timestamp defaults to def.
+func parseOpenMetricsValue(s string, def time.Time) (float64, time.Time, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 1 && len(fields) != 2 {
+		return 0, def, fmt.Errorf("invalid value %q", s)
+	}
+	value, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, def, fmt.Errorf("invalid value %q", fields[0])
+	}
+	if len(fields) == 1 {
+		return value, def, nil
+	}
+
+	ts, err := parseOpenMetricsTimestamp(fields[1])
+	if err != nil {
+		return 0, def, err
+	}
+	return value, ts, nil
+}
+
+// parseOpenMetricsTimestamp parses a timestamp in seconds, of which the
+// decimal fraction is parsed as is to not lose precision to floats.
+func parseOpenMetricsTimestamp(s string) (time.Time, error) {
+	if i := strings.IndexByte(s, '.'); i >= 0 && !strings.ContainsAny(s, "eE") {
+		sec, err := strconv.ParseInt(s[:i], 10, 64)
+		frac := s[i+1:]
+		if len(frac) > 9 {
+			frac = frac[:9]
+		}
+		ns, ferr := strconv.ParseUint(frac+strings.Repeat("0", 9-len(frac)), 10, 64)
+		if err != nil || ferr != nil {
+			return time.Time{}, fmt.Errorf("invalid timestamp %q", s)
+		}
+		if strings.HasPrefix(s, "-") {
+			return time.Unix(sec, -int64(ns)), nil
+		}
+		return time.Unix(sec, int64(ns)), nil
+	}
+
+	sec, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(sec) || math.IsInf(sec, 0) {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q", s)
+	}
+	return time.Unix(0, int64(sec*float64(time.Second))), nil
+}
+
+// metricKey returns the key of the metric of a family, tags and timestamp.
+func metricKey(name string, tags map[string]string, ts time.Time) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(name)
+	for _, k := range keys {
+		b.WriteByte(0)
+		b.WriteString(k)
+		b.WriteByte(0)
+		b.WriteString(tags[k])
+	}
+	b.WriteByte(0)
+	b.WriteString(strconv.FormatInt(ts.UnixNano(), 10))
+	return b.String()
+}
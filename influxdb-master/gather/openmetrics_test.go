@@ -0,0 +1,209 @@
+package gather
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/v2"
+)
+
+const sampleOpenMetrics = `# TYPE acme_http_router_request_seconds summary
+# UNIT acme_http_router_request_seconds seconds
+# HELP acme_http_router_request_seconds Latency though all of ACME's HTTP request router.
+acme_http_router_request_seconds_sum{path="/api/v1",method="GET"} 9036.32
+acme_http_router_request_seconds_count{path="/api/v1",method="GET"} 807283.0
+acme_http_router_request_seconds_created{path="/api/v1",method="GET"} 1605281325.0
+# TYPE go_goroutines gauge
+go_goroutines 69
+# TYPE process_cpu_seconds counter
+# UNIT process_cpu_seconds seconds
+process_cpu_seconds_total 4.20072246e+06 # {trace_id="KOO5S4vxi0o"} 0.67
+process_cpu_seconds_created 1605281325.5
+# TYPE foo histogram
+foo_bucket{le="0.01"} 0
+foo_bucket{le="0.1"} 8 # {} 0.054
+foo_bucket{le="+Inf"} 17 # {trace_id="oHg5SJYRHA0"} 9.8 1520879607.789
+foo_count 17
+foo_sum 324789.3
+# TYPE build info
+build_info{version="1.2.3"} 1
+# TYPE door stateset
+door{door="open",site="gs1"} 1
+door{door="closed",site="gs1"} 0
+# TYPE temp gauge
+temp{site="gs1"} 21.5 1605281325.25
+temp{site="gs2"} NaN
+untyped_metric{a="b\"c\\d\ne"} 3
+# EOF
+`
+
+func TestParseOpenMetrics(t *testing.T) {
+	now := time.Unix(1605281400, 0)
+	ms, err := parseOpenMetrics(strings.NewReader(sampleOpenMetrics), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []Metrics{
+		{
+			Name:      "acme_http_router_request_seconds",
+			Type:      MetricTypeSummary,
+			Tags:      map[string]string{"path": "/api/v1", "method": "GET", "unit": "seconds"},
+			Fields:    map[string]interface{}{"sum": 9036.32, "count": 807283.0, "created": 1605281325.0},
+			Timestamp: now,
+		},
+		{
+			Name:      "go_goroutines",
+			Type:      MetricTypeGauge,
+			Tags:      map[string]string{},
+			Fields:    map[string]interface{}{"gauge": 69.0},
+			Timestamp: now,
+		},
+		{
+			Name:      "process_cpu_seconds_exemplar",
+			Type:      MetricTypeUntyped,
+			Tags:      map[string]string{"unit": "seconds"},
+			Fields:    map[string]interface{}{"trace_id": "KOO5S4vxi0o", "value": 0.67},
+			Timestamp: now,
+		},
+		{
+			Name:      "process_cpu_seconds",
+			Type:      MetricTypeCounter,
+			Tags:      map[string]string{"unit": "seconds"},
+			Fields:    map[string]interface{}{"counter": 4.20072246e+06, "created": 1605281325.5},
+			Timestamp: now,
+		},
+		{
+			Name:      "foo",
+			Type:      MetricTypeHistogrm,
+			Tags:      map[string]string{},
+			Fields:    map[string]interface{}{"0.01": 0.0, "0.1": 8.0, "+Inf": 17.0, "count": 17.0, "sum": 324789.3},
+			Timestamp: now,
+		},
+		{
+			Name:      "foo_exemplar",
+			Type:      MetricTypeUntyped,
+			Tags:      map[string]string{"le": "0.1"},
+			Fields:    map[string]interface{}{"value": 0.054},
+			Timestamp: now,
+		},
+		{
+			Name:      "foo_exemplar",
+			Type:      MetricTypeUntyped,
+			Tags:      map[string]string{"le": "+Inf"},
+			Fields:    map[string]interface{}{"trace_id": "oHg5SJYRHA0", "value": 9.8},
+			Timestamp: time.Unix(1520879607, 789000000),
+		},
+		{
+			Name:      "build",
+			Type:      MetricTypeGauge,
+			Tags:      map[string]string{"version": "1.2.3"},
+			Fields:    map[string]interface{}{"info": 1.0},
+			Timestamp: now,
+		},
+		{
+			Name:      "door",
+			Type:      MetricTypeGauge,
+			Tags:      map[string]string{"door": "open", "site": "gs1"},
+			Fields:    map[string]interface{}{"state": 1.0},
+			Timestamp: now,
+		},
+		{
+			Name:      "door",
+			Type:      MetricTypeGauge,
+			Tags:      map[string]string{"door": "closed", "site": "gs1"},
+			Fields:    map[string]interface{}{"state": 0.0},
+			Timestamp: now,
+		},
+		{
+			Name:      "temp",
+			Type:      MetricTypeGauge,
+			Tags:      map[string]string{"site": "gs1"},
+			Fields:    map[string]interface{}{"gauge": 21.5},
+			Timestamp: time.Unix(1605281325, 250000000),
+		},
+		{
+			Name:      "untyped_metric",
+			Type:      MetricTypeUntyped,
+			Tags:      map[string]string{"a": "b\"c\\d\ne"},
+			Fields:    map[string]interface{}{"value": 3.0},
+			Timestamp: now,
+		},
+	}
+	if diff := cmp.Diff(exp, ms); diff != "" {
+		t.Fatalf("unexpected metrics: -want/+got\n%s", diff)
+	}
+}
+
+func TestParseOpenMetrics_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		err  string
+	}{
+		{name: "missing eof", text: "foo 1\n", err: "missing # EOF"},
+		{name: "content after eof", text: "# EOF\nfoo 1\n", err: "content after # EOF"},
+		{name: "unknown type", text: "# TYPE foo bar\n# EOF\n", err: `unknown type "bar"`},
+		{name: "invalid value", text: "foo one\n# EOF\n", err: `invalid value "one"`},
+		{name: "invalid timestamp", text: "foo 1 now\n# EOF\n", err: `invalid timestamp "now"`},
+		{name: "unterminated labels", text: "foo{a=\"b\" 1\n# EOF\n", err: "invalid labels"},
+		{name: "missing bucket bound", text: "# TYPE foo histogram\nfoo_bucket 1\n# EOF\n", err: "has no le label"},
+		{name: "invalid exemplar", text: "# TYPE foo counter\nfoo_total 1 # 1\n# EOF\n", err: "invalid exemplar"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseOpenMetrics(strings.NewReader(tt.text), time.Now())
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("unexpected error: got=%v exp=%s", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestOpenMetricsScraper(t *testing.T) {
+	var accept string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accept = r.Header.Get("Accept")
+		if r.URL.Path == "/prometheus" {
+			w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+			w.Write([]byte(sampleRespSmall))
+			return
+		}
+		w.Header().Set("Content-Type", "application/openmetrics-text; version=1.0.0; charset=utf-8")
+		w.Write([]byte("# TYPE go_goroutines gauge\ngo_goroutines 36\n# EOF\n"))
+	}))
+	defer ts.Close()
+
+	want := Metrics{
+		Name:   "go_goroutines",
+		Type:   MetricTypeGauge,
+		Tags:   map[string]string{},
+		Fields: map[string]interface{}{"gauge": float64(36)},
+	}
+	for _, path := range []string{"/metrics", "/prometheus"} {
+		collected, err := newOpenMetricsScraper().Gather(context.Background(), influxdb.ScraperTarget{
+			Type:     influxdb.OpenMetricsScraperType,
+			URL:      ts.URL + path,
+			OrgID:    *orgID,
+			BucketID: *bucketID,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(accept, "application/openmetrics-text") {
+			t.Fatalf("unexpected accept header: %s", accept)
+		}
+		if len(collected.MetricsSlice) != 1 {
+			t.Fatalf("unexpected metrics of %s: %v", path, collected.MetricsSlice)
+		}
+		if diff := cmp.Diff(collected.MetricsSlice[0], want, metricsCmpOption); diff != "" {
+			t.Fatalf("scraper parse metrics of %s want %v, got %v", path, want, collected.MetricsSlice[0])
+		}
+	}
+}
@@ -2,7 +2,6 @@ package gather
 
 import (
 	"context"
-	"crypto/tls"
 	"fmt"
 	"io"
 	"math"
@@ -18,31 +17,16 @@ import (
 
 // prometheusScraper handles parsing prometheus metrics.
 // implements Scraper interfaces.
-type prometheusScraper struct {
-	insecureHttp *http.Client
-}
+type prometheusScraper struct{}
 
 // newPrometheusScraper create a new prometheusScraper.
 func newPrometheusScraper() *prometheusScraper {
-	customTransport := http.DefaultTransport.(*http.Transport).Clone()
-	customTransport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
-	client := &http.Client{Transport: customTransport}
-
-	return &prometheusScraper{insecureHttp: client}
+	return &prometheusScraper{}
 }
 
 // Gather parse metrics from a scraper target url.
 func (p *prometheusScraper) Gather(ctx context.Context, target influxdb.ScraperTarget) (collected MetricsCollection, err error) {
-	var (
-		resp *http.Response
-	)
-
-	if target.AllowInsecure {
-		resp, err = p.insecureHttp.Get(target.URL)
-	} else {
-		resp, err = http.Get(target.URL)
-	}
-
+	resp, err := get(ctx, target, "")
 	if err != nil {
 		return collected, err
 	}
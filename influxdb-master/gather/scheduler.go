@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
 	"github.com/influxdata/influxdb/v2/kit/tracing"
 	"github.com/influxdata/influxdb/v2/nats"
 	"go.uber.org/zap"
@@ -15,14 +16,21 @@ import (
 
 // nats subjects
 const (
-	MetricsSubject    = "metrics"
-	promTargetSubject = "promTarget"
+	MetricsSubject           = "metrics"
+	promTargetSubject        = "promTarget"
+	openMetricsTargetSubject = "openMetricsTarget"
+	jsonTargetSubject        = "jsonTarget"
 )
 
+// minSchedulerTick is the minimum time between the checks of the scheduler
+// for targets to scrape.
+const minSchedulerTick = time.Second
+
 // Scheduler is struct to run scrape jobs.
 type Scheduler struct {
 	Targets influxdb.ScraperTargetStoreService
-	// Interval is between each metrics gathering event.
+	// Interval is between each metrics gathering event of the targets
+	// without an interval of their own.
 	Interval time.Duration
 	// Timeout is the maximum time duration allowed by each TCP request
 	Timeout time.Duration
@@ -30,9 +38,14 @@ type Scheduler struct {
 	// Publisher will send the gather requests and gathered metrics to the queue.
 	Publisher nats.Publisher
 
+	// Health records the health of the scrapes of the targets.
+	Health *HealthTracker
+
 	log *zap.Logger
 
 	gather chan struct{}
+	// scraped is the time each target was last requested to be scraped.
+	scraped map[platform.ID]time.Time
 }
 
 // NewScheduler creates a new Scheduler and subscriptions for scraper jobs.
@@ -45,10 +58,10 @@ func NewScheduler(
 	interval time.Duration,
 	timeout time.Duration,
 ) (*Scheduler, error) {
-	if interval == 0 {
+	if interval <= 0 {
 		interval = 60 * time.Second
 	}
-	if timeout == 0 {
+	if timeout <= 0 {
 		timeout = 30 * time.Second
 	}
 	scheduler := &Scheduler{
@@ -56,18 +69,28 @@ func NewScheduler(
 		Interval:  interval,
 		Timeout:   timeout,
 		Publisher: p,
+		Health:    NewHealthTracker(),
 		log:       log,
 		gather:    make(chan struct{}, 100),
+		scraped:   make(map[platform.ID]time.Time),
 	}
 
-	for i := 0; i < numScrapers; i++ {
-		err := s.Subscribe(promTargetSubject, "metrics", &handler{
-			Scraper:   newPrometheusScraper(),
-			Publisher: p,
-			log:       log,
-		})
-		if err != nil {
-			return nil, err
+	scrapers := map[string]func() Scraper{
+		promTargetSubject:        func() Scraper { return newPrometheusScraper() },
+		openMetricsTargetSubject: func() Scraper { return newOpenMetricsScraper() },
+		jsonTargetSubject:        func() Scraper { return newJSONScraper() },
+	}
+	for subject, newScraper := range scrapers {
+		for i := 0; i < numScrapers; i++ {
+			err := s.Subscribe(subject, "metrics", &handler{
+				Scraper:   newScraper(),
+				Publisher: p,
+				log:       log,
+				health:    scheduler.Health,
+			})
+			if err != nil {
+				return nil, err
+			}
 		}
 	}
 
@@ -77,31 +100,49 @@ func NewScheduler(
 // Run will retrieve scraper targets from the target storage,
 // and publish them to nats job queue for gather.
 func (s *Scheduler) Run(ctx context.Context) error {
-	go func(s *Scheduler, ctx context.Context) {
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case <-time.After(s.Interval): // TODO: change to ticker because of garbage collection
-				s.gather <- struct{}{}
-			}
-		}
-	}(s, ctx)
+	s.gather <- struct{}{}
 	return s.run(ctx)
 }
 
+// run gathers the targets when requested, and when the next of them is due.
 func (s *Scheduler) run(ctx context.Context) error {
+	// the timer is set by the first gather
+	timer := time.NewTimer(0)
+	<-timer.C
+	defer timer.Stop()
 	for {
 		select {
 		case <-ctx.Done():
 			return nil
 		case <-s.gather:
-			s.doGather(ctx)
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+		case <-timer.C:
 		}
+		timer.Reset(s.doGather(ctx))
 	}
 }
 
-func (s *Scheduler) doGather(ctx context.Context) {
+// tick returns the time until the next check for targets to scrape, given
+// the time until the next of them is due. The targets are checked at least
+// every interval of the scheduler, which finds the targets added since.
+func (s *Scheduler) tick(next time.Duration) time.Duration {
+	if next > s.Interval {
+		next = s.Interval
+	}
+	if next < minSchedulerTick {
+		next = minSchedulerTick
+	}
+	return next
+}
+
+// doGather requests the scrapes of the targets that are due, and returns the
+// time until the next check.
+func (s *Scheduler) doGather(ctx context.Context) time.Duration {
 	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
 	defer cancel()
 	span, ctx := tracing.StartSpanFromContext(ctx)
@@ -111,14 +152,37 @@ func (s *Scheduler) doGather(ctx context.Context) {
 	if err != nil {
 		s.log.Error("Cannot list targets", zap.Error(err))
 		tracing.LogError(span, err)
-		return
+		return s.tick(s.Interval)
 	}
+	s.Health.prune(targets)
+
+	now := time.Now()
+	next := s.Interval
+	scraped := make(map[platform.ID]time.Time, len(targets))
 	for _, target := range targets {
+		interval := target.Interval.Duration
+		if interval <= 0 {
+			interval = s.Interval
+		}
+		if last, ok := s.scraped[target.ID]; ok && now.Sub(last) < interval {
+			scraped[target.ID] = last
+			if d := last.Add(interval).Sub(now); d < next {
+				next = d
+			}
+			continue
+		}
+
+		scraped[target.ID] = now
+		if interval < next {
+			next = interval
+		}
 		if err := requestScrape(target, s.Publisher); err != nil {
 			s.log.Error("JSON encoding error", zap.Error(err))
 			tracing.LogError(span, err)
 		}
 	}
+	s.scraped = scraped
+	return s.tick(next)
 }
 
 func requestScrape(t influxdb.ScraperTarget, publisher nats.Publisher) error {
@@ -130,6 +194,10 @@ func requestScrape(t influxdb.ScraperTarget, publisher nats.Publisher) error {
 	switch t.Type {
 	case influxdb.PrometheusScraperType:
 		return publisher.Publish(promTargetSubject, buf)
+	case influxdb.OpenMetricsScraperType:
+		return publisher.Publish(openMetricsTargetSubject, buf)
+	case influxdb.JSONScraperType:
+		return publisher.Publish(jsonTargetSubject, buf)
 	}
 	return fmt.Errorf("unsupported target scrape type: %s", t.Type)
 }
@@ -2,12 +2,14 @@ package gather
 
 import (
 	"context"
+	"io"
 	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
 	"github.com/influxdata/influxdb/v2/mock"
 	influxdbtesting "github.com/influxdata/influxdb/v2/testing"
 	"go.uber.org/zap/zaptest"
@@ -90,6 +92,63 @@ func TestScheduler(t *testing.T) {
 	ts.Close()
 }
 
+// discardPublisher counts the messages published, and discards them.
+type discardPublisher struct {
+	published int
+}
+
+func (p *discardPublisher) Publish(subject string, r io.Reader) error {
+	p.published++
+	return nil
+}
+
+func TestScheduler_doGather(t *testing.T) {
+	publisher := &discardPublisher{}
+	storage := &mockStorage{
+		Targets: []influxdb.ScraperTarget{
+			{
+				ID:       influxdbtesting.MustIDBase16("3a0d0a6365646120"),
+				Type:     influxdb.PrometheusScraperType,
+				Interval: influxdb.Duration{Duration: 5 * time.Second},
+			},
+			{
+				ID:   influxdbtesting.MustIDBase16("3a0d0a6365646121"),
+				Type: influxdb.PrometheusScraperType,
+			},
+		},
+	}
+	s := &Scheduler{
+		Targets:   storage,
+		Interval:  time.Minute,
+		Timeout:   time.Second,
+		Publisher: publisher,
+		Health:    NewHealthTracker(),
+		log:       zaptest.NewLogger(t),
+		scraped:   make(map[platform.ID]time.Time),
+	}
+
+	// the next check is when the target with the shortest interval is due
+	if got, exp := s.doGather(context.Background()), 5*time.Second; got != exp {
+		t.Fatalf("unexpected time until the next check: got %v, exp %v", got, exp)
+	}
+	if publisher.published != 2 {
+		t.Fatalf("unexpected scrapes: got %d, exp 2", publisher.published)
+	}
+	if got := s.doGather(context.Background()); got <= 4*time.Second || got > 5*time.Second {
+		t.Fatalf("unexpected time until the next check: got %v", got)
+	}
+	if publisher.published != 2 {
+		t.Fatalf("unexpected scrapes of targets that are not due: got %d, exp 2", publisher.published)
+	}
+
+	// the time between the checks is at least minSchedulerTick
+	storage.Targets[0].Interval.Duration = time.Millisecond
+	s.scraped = make(map[platform.ID]time.Time)
+	if got, exp := s.doGather(context.Background()), minSchedulerTick; got != exp {
+		t.Fatalf("unexpected time until the next check: got %v, exp %v", got, exp)
+	}
+}
+
 const sampleRespSmall = `
 # HELP go_goroutines Number of goroutines that currently exist.
 # TYPE go_goroutines gauge
@@ -20,6 +20,7 @@ import (
 	"github.com/influxdata/influxdb/v2/query"
 	"github.com/influxdata/influxdb/v2/query/fluxlang"
 	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/influxdata/influxdb/v2/storage/reads"
 	"github.com/influxdata/influxdb/v2/task/taskmodel"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
@@ -41,6 +42,11 @@ type APIBackend struct {
 	// in a single points batch
 	MaxBatchSizeBytes int64
 
+	// PrometheusRemoteMaxBodyBytes is the maximum size of the body of a
+	// Prometheus remote write or read request, before and after it is
+	// decompressed. DefaultPrometheusRemoteMaxBodyBytes is used if zero.
+	PrometheusRemoteMaxBodyBytes int64
+
 	// WriteParserMaxBytes specifies the maximum number of bytes that may be allocated when processing a single
 	// write request. A value of zero specifies there is no limit.
 	WriteParserMaxBytes int
@@ -62,9 +68,12 @@ type APIBackend struct {
 	AlgoWProxy FeatureProxyHandler
 
 	PointsWriter                    storage.PointsWriter
+	ReadsStore                      reads.Store
 	DeleteService                   influxdb.DeleteService
 	BackupService                   influxdb.BackupService
 	RestoreService                  influxdb.RestoreService
+	CompactionService               influxdb.CompactionService
+	DigestService                   influxdb.DigestService
 	AuthorizationService            influxdb.AuthorizationService
 	AuthorizationV1Service          influxdb.AuthorizationService
 	PasswordV1Service               influxdb.PasswordsService
@@ -84,6 +93,7 @@ type APIBackend struct {
 	OrganizationOperationLogService influxdb.OrganizationOperationLogService
 	SourceService                   influxdb.SourceService
 	VariableService                 influxdb.VariableService
+	VariableValuesService           influxdb.VariableValuesService
 	PasswordsService                influxdb.PasswordsService
 	InfluxQLService                 query.ProxyQueryService
 	InfluxqldService                influxql.ProxyQueryService
@@ -93,6 +103,7 @@ type APIBackend struct {
 	CheckService                    influxdb.CheckService
 	TelegrafService                 influxdb.TelegrafConfigStore
 	ScraperTargetStoreService       influxdb.ScraperTargetStoreService
+	ScraperHealthService            influxdb.ScraperHealthService
 	SecretService                   influxdb.SecretService
 	LookupService                   influxdb.LookupService
 	ChronografService               *server.Service
@@ -205,6 +216,14 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 	restoreBackend.RestoreService = authorizer.NewRestoreService(restoreBackend.RestoreService)
 	h.Mount(prefixRestore, NewRestoreHandler(restoreBackend))
 
+	compactionBackend := NewCompactionBackend(b)
+	compactionBackend.CompactionService = authorizer.NewCompactionService(compactionBackend.CompactionService)
+	h.Mount(prefixCompactions, NewCompactionHandler(compactionBackend))
+
+	digestBackend := NewDigestBackend(b)
+	digestBackend.DigestService = authorizer.NewDigestService(digestBackend.DigestService)
+	h.Mount(prefixDigest, NewDigestHandler(digestBackend))
+
 	h.Mount(dbrp.PrefixDBRP, dbrp.NewHTTPHandler(b.Logger, b.DBRPService, b.OrganizationService))
 
 	writeBackend := NewWriteBackend(b.Logger.With(zap.String("handler", "write")), b)
@@ -217,6 +236,9 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 		//),
 	))
 
+	promRemoteBackend := NewPrometheusRemoteBackend(b.Logger.With(zap.String("handler", "prometheus_remote")), b)
+	h.Mount(prefixPrometheusRemote, NewPrometheusRemoteHandler(b.Logger, promRemoteBackend))
+
 	for _, o := range opts {
 		o(h)
 	}
@@ -872,6 +872,9 @@ type Check struct {
 	Tags                  []*influxdb.Tag   `json:"tags"`
 	StatusMessageTemplate string            `json:"statusMessageTemplate"`
 	Thresholds            []*CheckThreshold `json:"thresholds"`
+	Method                string            `json:"method,omitempty"`
+	Baseline              string            `json:"baseline,omitempty"`
+	Period                string            `json:"period,omitempty"`
 }
 
 type CheckQuery struct {
@@ -0,0 +1,144 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"go.uber.org/zap"
+)
+
+// CompactionBackend is all services and associated parameters required to construct the CompactionHandler.
+type CompactionBackend struct {
+	Logger *zap.Logger
+	errors.HTTPErrorHandler
+
+	CompactionService influxdb.CompactionService
+}
+
+// NewCompactionBackend returns a new instance of CompactionBackend.
+func NewCompactionBackend(b *APIBackend) *CompactionBackend {
+	return &CompactionBackend{
+		Logger: b.Logger.With(zap.String("handler", "compaction")),
+
+		HTTPErrorHandler:  b.HTTPErrorHandler,
+		CompactionService: b.CompactionService,
+	}
+}
+
+// CompactionHandler is http handler for compaction service.
+type CompactionHandler struct {
+	*httprouter.Router
+	errors.HTTPErrorHandler
+	Logger *zap.Logger
+
+	CompactionService influxdb.CompactionService
+}
+
+const (
+	prefixCompactions      = "/api/v2/compactions"
+	compactionsShardPath   = prefixCompactions + "/shards/:shardID"
+	compactionsPausePath   = compactionsShardPath + "/pause"
+	compactionsResumePath  = compactionsShardPath + "/resume"
+	compactionsTriggerPath = compactionsShardPath + "/trigger"
+)
+
+// NewCompactionHandler creates a new handler at /api/v2/compactions to inspect
+// and control the compactions of shards.
+func NewCompactionHandler(b *CompactionBackend) *CompactionHandler {
+	h := &CompactionHandler{
+		HTTPErrorHandler:  b.HTTPErrorHandler,
+		Router:            NewRouter(b.HTTPErrorHandler),
+		Logger:            b.Logger,
+		CompactionService: b.CompactionService,
+	}
+
+	h.HandlerFunc(http.MethodGet, prefixCompactions, h.handleGetCompactions)
+	h.HandlerFunc(http.MethodPost, compactionsPausePath, h.handlePostPause)
+	h.HandlerFunc(http.MethodPost, compactionsResumePath, h.handlePostResume)
+	h.HandlerFunc(http.MethodPost, compactionsTriggerPath, h.handlePostTrigger)
+
+	return h
+}
+
+type compactionsResponse struct {
+	Shards []*influxdb.ShardCompaction `json:"shards"`
+}
+
+func (h *CompactionHandler) handleGetCompactions(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "CompactionHandler.handleGetCompactions")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	var bucketID *platform.ID
+	if s := r.URL.Query().Get("bucketID"); s != "" {
+		id, err := platform.IDFromString(s)
+		if err != nil {
+			h.HandleHTTPError(ctx, &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "invalid bucketID",
+				Err:  err,
+			}, w)
+			return
+		}
+		bucketID = id
+	}
+
+	shards, err := h.CompactionService.FindShardCompactions(ctx, bucketID)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if shards == nil {
+		shards = []*influxdb.ShardCompaction{}
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, compactionsResponse{Shards: shards}); err != nil {
+		logEncodingError(h.Logger, r, err)
+	}
+}
+
+func (h *CompactionHandler) handlePostPause(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "CompactionHandler.handlePostPause")
+	defer span.Finish()
+	h.handleShardAction(w, r, h.CompactionService.PauseShardCompactions)
+}
+
+func (h *CompactionHandler) handlePostResume(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "CompactionHandler.handlePostResume")
+	defer span.Finish()
+	h.handleShardAction(w, r, h.CompactionService.ResumeShardCompactions)
+}
+
+func (h *CompactionHandler) handlePostTrigger(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "CompactionHandler.handlePostTrigger")
+	defer span.Finish()
+	h.handleShardAction(w, r, h.CompactionService.TriggerShardCompaction)
+}
+
+func (h *CompactionHandler) handleShardAction(w http.ResponseWriter, r *http.Request, action func(ctx context.Context, shardID uint64) error) {
+	ctx := r.Context()
+
+	params := httprouter.ParamsFromContext(ctx)
+	shardID, err := strconv.ParseUint(params.ByName("shardID"), 10, 64)
+	if err != nil {
+		h.HandleHTTPError(ctx, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "invalid shardID",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	if err := action(ctx, shardID); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -55,6 +55,10 @@ type DeleteHandler struct {
 
 const (
 	prefixDelete = "/api/v2/delete"
+
+	// deleteDryRunMaxSeries is the maximum number of series listed by a
+	// delete dry run.
+	deleteDryRunMaxSeries = 1000
 )
 
 // NewDeleteHandler creates a new handler at /api/v2/delete to receive delete requests.
@@ -116,6 +120,43 @@ func (h *DeleteHandler) handleDelete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if dr.DryRun {
+		// the dry run reveals the series of the bucket, which requires read access
+		rp, err := influxdb.NewPermissionAtID(dr.Bucket.ID, influxdb.ReadAction, influxdb.BucketsResourceType, dr.Org.ID)
+		if err != nil {
+			h.HandleHTTPError(ctx, &errors.Error{
+				Code: errors.EInternal,
+				Op:   "http/handleDelete",
+				Msg:  fmt.Sprintf("unable to create permission for bucket: %v", err),
+				Err:  err,
+			}, w)
+			return
+		}
+		if pset, err := a.PermissionSet(); err != nil || !pset.Allowed(*rp) {
+			h.HandleHTTPError(ctx, &errors.Error{
+				Code: errors.EForbidden,
+				Op:   "http/handleDelete",
+				Msg:  "insufficient permissions to read the series matched by the delete",
+			}, w)
+			return
+		}
+
+		res, err := h.DeleteService.DeleteBucketRangePredicateDryRun(r.Context(), dr.Org.ID, dr.Bucket.ID, dr.Start, dr.Stop, dr.Predicate, deleteDryRunMaxSeries)
+		if err != nil {
+			h.HandleHTTPError(ctx, &errors.Error{
+				Code: errors.EInternal,
+				Op:   "http/handleDelete",
+				Msg:  fmt.Sprintf("unable to find series to delete: %v", err),
+				Err:  err,
+			}, w)
+			return
+		}
+		if err := encodeResponse(ctx, w, http.StatusOK, res); err != nil {
+			logEncodingError(h.log, r, err)
+		}
+		return
+	}
+
 	if err := h.DeleteService.DeleteBucketRangePredicate(r.Context(), dr.Org.ID, dr.Bucket.ID, dr.Start, dr.Stop, dr.Predicate); err != nil {
 		h.HandleHTTPError(ctx, &errors.Error{
 			Code: errors.EInternal,
@@ -160,12 +201,14 @@ type deleteRequest struct {
 	Start     int64
 	Stop      int64
 	Predicate influxdb.Predicate
+	DryRun    bool
 }
 
 type deleteRequestDecode struct {
 	Start     string `json:"start"`
 	Stop      string `json:"stop"`
 	Predicate string `json:"predicate"`
+	DryRun    bool   `json:"dryRun"`
 }
 
 // DeleteRequest is the request send over http to delete points.
@@ -177,6 +220,7 @@ type DeleteRequest struct {
 	Start     string `json:"start"`
 	Stop      string `json:"stop"`
 	Predicate string `json:"predicate"`
+	DryRun    bool   `json:"dryRun,omitempty"`
 }
 
 func (dr *deleteRequest) UnmarshalJSON(b []byte) error {
@@ -188,7 +232,7 @@ func (dr *deleteRequest) UnmarshalJSON(b []byte) error {
 			Err:  err,
 		}
 	}
-	*dr = deleteRequest{}
+	*dr = deleteRequest{DryRun: drd.DryRun}
 	start, err := time.Parse(time.RFC3339Nano, drd.Start)
 	if err != nil {
 		return &errors.Error{
@@ -225,17 +269,48 @@ type DeleteService struct {
 
 // DeleteBucketRangePredicate send delete request over http to delete points.
 func (s *DeleteService) DeleteBucketRangePredicate(ctx context.Context, dr DeleteRequest) error {
-	u, err := NewURL(s.Addr, prefixDelete)
+	dr.DryRun = false
+	resp, err := s.do(ctx, dr)
 	if err != nil {
 		return err
 	}
+	defer resp.Body.Close()
+
+	return CheckError(resp)
+}
+
+// DeleteBucketRangePredicateDryRun sends a delete dry run request over http
+// and returns the series of which points would be deleted.
+func (s *DeleteService) DeleteBucketRangePredicateDryRun(ctx context.Context, dr DeleteRequest) (*influxdb.DeleteDryRun, error) {
+	dr.DryRun = true
+	resp, err := s.do(ctx, dr)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+	var res influxdb.DeleteDryRun
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (s *DeleteService) do(ctx context.Context, dr DeleteRequest) (*http.Response, error) {
+	u, err := NewURL(s.Addr, prefixDelete)
+	if err != nil {
+		return nil, err
+	}
 	buf := new(bytes.Buffer)
 	if err := json.NewEncoder(buf).Encode(dr); err != nil {
-		return err
+		return nil, err
 	}
-	req, err := http.NewRequest("POST", u.String(), buf)
+	req, err := http.NewRequestWithContext(ctx, "POST", u.String(), buf)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	req.Header.Set("Content-Type", "application/json; charset=utf-8")
@@ -256,12 +331,5 @@ func (s *DeleteService) DeleteBucketRangePredicate(ctx context.Context, dr Delet
 	req.URL.RawQuery = params.Encode()
 
 	hc := NewClient(u.Scheme, s.InsecureSkipVerify)
-
-	resp, err := hc.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	return CheckError(resp)
+	return hc.Do(req)
 }
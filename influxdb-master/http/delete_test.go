@@ -236,7 +236,7 @@ func TestDelete(t *testing.T) {
 			},
 		},
 		{
-			name: "unsupported delete",
+			name: "delete with or",
 			args: args{
 				queryParams: map[string][]string{
 					"org":    {"org1"},
@@ -282,10 +282,145 @@ func TestDelete(t *testing.T) {
 				},
 			},
 			wants: wants{
-				statusCode: http.StatusBadRequest,
+				statusCode: http.StatusNoContent,
+				body:       ``,
+			},
+		},
+		{
+			name: "dry run delete without read access",
+			args: args{
+				queryParams: map[string][]string{
+					"org":    {"org1"},
+					"bucket": {"buck1"},
+				},
+				body: []byte(`{
+					"start":"2009-01-01T23:00:00Z",
+					"stop":"2019-11-10T01:00:00Z",
+					"predicate": "_measurement=\"cpu\" and _field=\"temp\" and host=~/^server[0-9]+$/",
+					"dryRun": true
+				}`),
+				authorizer: &influxdb.Authorization{
+					UserID: user1ID,
+					Status: influxdb.Active,
+					Permissions: []influxdb.Permission{
+						{
+							Action: influxdb.WriteAction,
+							Resource: influxdb.Resource{
+								Type:  influxdb.BucketsResourceType,
+								ID:    influxtesting.IDPtr(platform.ID(2)),
+								OrgID: influxtesting.IDPtr(platform.ID(1)),
+							},
+						},
+					},
+				},
+			},
+			fields: fields{
+				DeleteService: &mock.DeleteService{
+					DeleteBucketRangePredicateDryRunF: func(ctx context.Context, orgID, bucketID platform.ID, min, max int64, pred influxdb.Predicate, limit int) (*influxdb.DeleteDryRun, error) {
+						return &influxdb.DeleteDryRun{
+							SeriesN: 1,
+							Series: []influxdb.DeleteDryRunSeries{
+								{Measurement: "cpu", Tags: map[string]string{"host": "server1"}, Fields: []string{"temp"}},
+							},
+						}, nil
+					},
+				},
+				BucketService: &mock.BucketService{
+					FindBucketFn: func(ctx context.Context, f influxdb.BucketFilter) (*influxdb.Bucket, error) {
+						return &influxdb.Bucket{
+							ID:   platform.ID(2),
+							Name: "bucket1",
+						}, nil
+					},
+				},
+				OrganizationService: &mock.OrganizationService{
+					FindOrganizationF: func(ctx context.Context, f influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+						return &influxdb.Organization{
+							ID:   platform.ID(1),
+							Name: "org1",
+						}, nil
+					},
+				},
+			},
+			wants: wants{
+				statusCode:  http.StatusForbidden,
+				contentType: "application/json; charset=utf-8",
 				body: `{
-					"code": "invalid",
-					"message": "invalid request; error parsing request json: the logical operator OR is not supported yet at position 25"
+					"code": "forbidden",
+					"message": "insufficient permissions to read the series matched by the delete"
+				  }`,
+			},
+		},
+		{
+			name: "dry run delete",
+			args: args{
+				queryParams: map[string][]string{
+					"org":    {"org1"},
+					"bucket": {"buck1"},
+				},
+				body: []byte(`{
+					"start":"2009-01-01T23:00:00Z",
+					"stop":"2019-11-10T01:00:00Z",
+					"predicate": "_measurement=\"cpu\" and _field=\"temp\" and host=~/^server[0-9]+$/",
+					"dryRun": true
+				}`),
+				authorizer: &influxdb.Authorization{
+					UserID: user1ID,
+					Status: influxdb.Active,
+					Permissions: []influxdb.Permission{
+						{
+							Action: influxdb.ReadAction,
+							Resource: influxdb.Resource{
+								Type:  influxdb.BucketsResourceType,
+								ID:    influxtesting.IDPtr(platform.ID(2)),
+								OrgID: influxtesting.IDPtr(platform.ID(1)),
+							},
+						},
+						{
+							Action: influxdb.WriteAction,
+							Resource: influxdb.Resource{
+								Type:  influxdb.BucketsResourceType,
+								ID:    influxtesting.IDPtr(platform.ID(2)),
+								OrgID: influxtesting.IDPtr(platform.ID(1)),
+							},
+						},
+					},
+				},
+			},
+			fields: fields{
+				DeleteService: &mock.DeleteService{
+					DeleteBucketRangePredicateDryRunF: func(ctx context.Context, orgID, bucketID platform.ID, min, max int64, pred influxdb.Predicate, limit int) (*influxdb.DeleteDryRun, error) {
+						return &influxdb.DeleteDryRun{
+							SeriesN: 1,
+							Series: []influxdb.DeleteDryRunSeries{
+								{Measurement: "cpu", Tags: map[string]string{"host": "server1"}, Fields: []string{"temp"}},
+							},
+						}, nil
+					},
+				},
+				BucketService: &mock.BucketService{
+					FindBucketFn: func(ctx context.Context, f influxdb.BucketFilter) (*influxdb.Bucket, error) {
+						return &influxdb.Bucket{
+							ID:   platform.ID(2),
+							Name: "bucket1",
+						}, nil
+					},
+				},
+				OrganizationService: &mock.OrganizationService{
+					FindOrganizationF: func(ctx context.Context, f influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+						return &influxdb.Organization{
+							ID:   platform.ID(1),
+							Name: "org1",
+						}, nil
+					},
+				},
+			},
+			wants: wants{
+				statusCode:  http.StatusOK,
+				contentType: "application/json; charset=utf-8",
+				body: `{
+					"seriesN": 1,
+					"series": [{"measurement": "cpu", "tags": {"host": "server1"}, "fields": ["temp"]}]
 				  }`,
 			},
 		},
@@ -0,0 +1,289 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"go.uber.org/zap"
+)
+
+// DigestBackend is all services and associated parameters required to construct the DigestHandler.
+type DigestBackend struct {
+	Logger *zap.Logger
+	errors.HTTPErrorHandler
+
+	DigestService influxdb.DigestService
+}
+
+// NewDigestBackend returns a new instance of DigestBackend.
+func NewDigestBackend(b *APIBackend) *DigestBackend {
+	return &DigestBackend{
+		Logger: b.Logger.With(zap.String("handler", "digest")),
+
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		DigestService:    b.DigestService,
+	}
+}
+
+// DigestHandler is http handler for digest service.
+type DigestHandler struct {
+	*httprouter.Router
+	errors.HTTPErrorHandler
+	Logger *zap.Logger
+
+	DigestService influxdb.DigestService
+}
+
+const (
+	prefixDigest     = "/api/v2/digest"
+	digestExportPath = prefixDigest + "/export"
+	digestDeletePath = prefixDigest + "/delete"
+)
+
+// NewDigestHandler creates a new handler at /api/v2/digest to compute the
+// digests of buckets, and export and delete the ranges that differ between two
+// digests.
+func NewDigestHandler(b *DigestBackend) *DigestHandler {
+	h := &DigestHandler{
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		Router:           NewRouter(b.HTTPErrorHandler),
+		Logger:           b.Logger,
+		DigestService:    b.DigestService,
+	}
+
+	h.HandlerFunc(http.MethodGet, prefixDigest, h.handleGetDigest)
+	h.HandlerFunc(http.MethodPost, digestExportPath, h.handlePostExport)
+	h.HandlerFunc(http.MethodPost, digestDeletePath, h.handlePostDelete)
+
+	return h
+}
+
+func (h *DigestHandler) handleGetDigest(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "DigestHandler.handleGetDigest")
+	defer span.Finish()
+
+	ctx := r.Context()
+	q := r.URL.Query()
+
+	bucketID, err := decodeDigestBucketID(q)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	opts := influxdb.DigestOptions{Start: math.MinInt64, Stop: math.MaxInt64}
+	if opts.Window, err = time.ParseDuration(q.Get("window")); err != nil || opts.Window <= 0 {
+		h.HandleHTTPError(ctx, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "window must be a positive duration",
+			Err:  err,
+		}, w)
+		return
+	}
+	for _, p := range []struct {
+		name string
+		ts   *int64
+	}{{"start", &opts.Start}, {"stop", &opts.Stop}} {
+		s := q.Get(p.name)
+		if s == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			h.HandleHTTPError(ctx, &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "invalid " + p.name,
+				Err:  err,
+			}, w)
+			return
+		}
+		*p.ts = t.UnixNano()
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	if err := h.DigestService.BucketDigest(ctx, *bucketID, opts, w); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+}
+
+type digestExportRequest struct {
+	Ranges []influxdb.DigestRange `json:"ranges"`
+}
+
+func (h *DigestHandler) handlePostExport(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "DigestHandler.handlePostExport")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	bucketID, err := decodeDigestBucketID(r.URL.Query())
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	var req digestExportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.HandleHTTPError(ctx, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "invalid export request",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if err := h.DigestService.ExportDigestRanges(ctx, *bucketID, req.Ranges, w); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+}
+
+func (h *DigestHandler) handlePostDelete(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "DigestHandler.handlePostDelete")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	bucketID, err := decodeDigestBucketID(r.URL.Query())
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	var req digestExportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.HandleHTTPError(ctx, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "invalid delete request",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	if err := h.DigestService.DeleteDigestRanges(ctx, *bucketID, req.Ranges); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func decodeDigestBucketID(q url.Values) (*platform.ID, error) {
+	id, err := platform.IDFromString(q.Get("bucketID"))
+	if err != nil {
+		return nil, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "invalid bucketID",
+			Err:  err,
+		}
+	}
+	return id, nil
+}
+
+// DigestService is the client implementation of influxdb.DigestService.
+type DigestService struct {
+	Addr               string
+	Token              string
+	InsecureSkipVerify bool
+}
+
+func (s *DigestService) BucketDigest(ctx context.Context, bucketID platform.ID, opts influxdb.DigestOptions, w io.Writer) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	u, err := NewURL(s.Addr, prefixDigest)
+	if err != nil {
+		return err
+	}
+	u.RawQuery = url.Values{
+		"bucketID": {bucketID.String()},
+		"window":   {opts.Window.String()},
+		"start":    {time.Unix(0, opts.Start).UTC().Format(time.RFC3339Nano)},
+		"stop":     {time.Unix(0, opts.Stop).UTC().Format(time.RFC3339Nano)},
+	}.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	return s.do(ctx, req, w)
+}
+
+func (s *DigestService) ExportDigestRanges(ctx context.Context, bucketID platform.ID, ranges []influxdb.DigestRange, w io.Writer) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	u, err := NewURL(s.Addr, digestExportPath)
+	if err != nil {
+		return err
+	}
+	u.RawQuery = url.Values{"bucketID": {bucketID.String()}}.Encode()
+
+	b, err := json.Marshal(digestExportRequest{Ranges: ranges})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, u.String(), bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return s.do(ctx, req, w)
+}
+
+func (s *DigestService) DeleteDigestRanges(ctx context.Context, bucketID platform.ID, ranges []influxdb.DigestRange) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	u, err := NewURL(s.Addr, digestDeletePath)
+	if err != nil {
+		return err
+	}
+	u.RawQuery = url.Values{"bucketID": {bucketID.String()}}.Encode()
+
+	b, err := json.Marshal(digestExportRequest{Ranges: ranges})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, u.String(), bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return s.do(ctx, req, ioutil.Discard)
+}
+
+func (s *DigestService) do(ctx context.Context, req *http.Request, w io.Writer) error {
+	SetToken(s.Token, req)
+	req = req.WithContext(ctx)
+
+	hc := NewClient(req.URL.Scheme, s.InsecureSkipVerify)
+	hc.Timeout = httpClientTimeout
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
@@ -32,6 +32,7 @@ func NewPlatformHandler(b *APIBackend, opts ...APIHandlerOptFn) *PlatformHandler
 	h.RegisterNoAuthRoute("POST", "/api/v2/setup")
 	h.RegisterNoAuthRoute("GET", "/api/v2/setup")
 	h.RegisterNoAuthRoute("GET", "/api/v2/swagger.json")
+	h.RegisterNoAuthRoute("GET", "/api/v2/snapshots/shared/:token")
 
 	assetHandler := NewAssetHandler()
 	assetHandler.Path = b.AssetsPath
@@ -69,6 +70,7 @@ func (h *PlatformHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// of the platform API.
 	if !strings.HasPrefix(r.URL.Path, "/v1") &&
 		!strings.HasPrefix(r.URL.Path, "/api/v2") &&
+		!strings.HasPrefix(r.URL.Path, prefixPrometheusRemote) &&
 		!strings.HasPrefix(r.URL.Path, "/chronograf/") &&
 		!strings.HasPrefix(r.URL.Path, "/private/") {
 		h.AssetHandler.ServeHTTP(w, r)
@@ -0,0 +1,98 @@
+package points
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+
+	errors2 "github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/influxdata/influxdb/v2/pkg/ccsds"
+	"github.com/opentracing/opentracing-go"
+)
+
+const (
+	// CCSDSContentType is the media type of a write of CCSDS space packets. The
+	// body is a multipart body whose first part, named "packet-def", is the packet
+	// definition, and whose second part, named "packets", are the space packets.
+	CCSDSContentType = "multipart/vnd.influx.ccsds"
+
+	ccsdsPartPacketDef = "packet-def"
+	ccsdsPartPackets   = "packets"
+)
+
+// ParseCCSDS parses the points from a multipart body of a packet definition and
+// the space packets it describes. The boundary is the boundary parameter of the
+// body's media type.
+func ParseCCSDS(ctx context.Context, boundary string, rc io.ReadCloser) (*ParsedPoints, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "write ccsds packets")
+	defer span.Finish()
+
+	data, err := readAll(ctx, rc)
+	if err != nil {
+		return nil, readErr(err)
+	}
+
+	mr := multipart.NewReader(bytes.NewReader(data), boundary)
+	defPart, err := nextCCSDSPart(mr, ccsdsPartPacketDef)
+	if err != nil {
+		return nil, err
+	}
+	def, err := ccsds.ParseDefinition(defPart)
+	if err != nil {
+		return nil, &errors2.Error{
+			Code: errors2.EInvalid,
+			Op:   opPointsWriter,
+			Msg:  "invalid packet definition",
+			Err:  err,
+		}
+	}
+
+	packetsPart, err := nextCCSDSPart(mr, ccsdsPartPackets)
+	if err != nil {
+		return nil, err
+	}
+
+	span, _ = tracing.StartSpanFromContextWithOperationName(ctx, "decoding packets")
+	dec := ccsds.NewDecoder(packetsPart, def)
+	points, err := dec.DecodeAll()
+	span.LogKV("values_total", len(points), "packets_total", dec.PacketCount)
+	span.Finish()
+	if err != nil {
+		return nil, &errors2.Error{
+			Code: errors2.EInvalid,
+			Op:   opPointsWriter,
+			Err:  err,
+		}
+	}
+	if len(points) == 0 {
+		return nil, &errors2.Error{
+			Op:   opPointsWriter,
+			Code: errors2.EInvalid,
+			Msg:  msgWritingRequiresPoints,
+		}
+	}
+
+	return &ParsedPoints{
+		Points:  points,
+		RawSize: len(data),
+	}, nil
+}
+
+func nextCCSDSPart(mr *multipart.Reader, name string) (*multipart.Part, error) {
+	part, err := mr.NextPart()
+	if err == nil && part.FormName() != name {
+		err = fmt.Errorf("expected part %q, got %q", name, part.FormName())
+	}
+	if err != nil {
+		return nil, &errors2.Error{
+			Code: errors2.EInvalid,
+			Op:   opPointsWriter,
+			Msg:  fmt.Sprintf("invalid %s body", CCSDSContentType),
+			Err:  err,
+		}
+	}
+	return part, nil
+}
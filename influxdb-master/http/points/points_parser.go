@@ -55,18 +55,7 @@ func (pw *Parser) Parse(ctx context.Context, orgID, bucketID platform.ID, rc io.
 func (pw *Parser) parsePoints(ctx context.Context, orgID, bucketID platform.ID, rc io.ReadCloser) (*ParsedPoints, error) {
 	data, err := readAll(ctx, rc)
 	if err != nil {
-		code := errors2.EInternal
-		if errors.Is(err, ErrMaxBatchSizeExceeded) {
-			code = errors2.ETooLarge
-		} else if errors.Is(err, gzip.ErrHeader) || errors.Is(err, gzip.ErrChecksum) {
-			code = errors2.EInvalid
-		}
-		return nil, &errors2.Error{
-			Code: code,
-			Op:   opPointsWriter,
-			Msg:  msgUnableToReadData,
-			Err:  err,
-		}
+		return nil, readErr(err)
 	}
 
 	requestBytes := len(data)
@@ -108,6 +97,21 @@ func (pw *Parser) parsePoints(ctx context.Context, orgID, bucketID platform.ID,
 	}, nil
 }
 
+func readErr(err error) error {
+	code := errors2.EInternal
+	if errors.Is(err, ErrMaxBatchSizeExceeded) {
+		code = errors2.ETooLarge
+	} else if errors.Is(err, gzip.ErrHeader) || errors.Is(err, gzip.ErrChecksum) {
+		code = errors2.EInvalid
+	}
+	return &errors2.Error{
+		Code: code,
+		Op:   opPointsWriter,
+		Msg:  msgUnableToReadData,
+		Err:  err,
+	}
+}
+
 func readAll(ctx context.Context, rc io.ReadCloser) (data []byte, err error) {
 	defer func() {
 		if cerr := rc.Close(); cerr != nil && err == nil {
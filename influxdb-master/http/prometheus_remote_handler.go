@@ -0,0 +1,362 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/golang/snappy"
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	pcontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/http/metric"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"github.com/influxdata/influxdb/v2/prometheus/remote"
+	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/influxdata/influxdb/v2/storage/reads"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"go.uber.org/zap"
+)
+
+// PrometheusRemoteBackend is all services and associated parameters required
+// to construct the PrometheusRemoteHandler.
+type PrometheusRemoteBackend struct {
+	errors.HTTPErrorHandler
+	log                *zap.Logger
+	WriteEventRecorder metric.EventRecorder
+	MaxBodyBytes       int64
+
+	PointsWriter        storage.PointsWriter
+	ReadsStore          reads.Store
+	BucketService       influxdb.BucketService
+	OrganizationService influxdb.OrganizationService
+}
+
+// NewPrometheusRemoteBackend returns a new instance of PrometheusRemoteBackend.
+func NewPrometheusRemoteBackend(log *zap.Logger, b *APIBackend) *PrometheusRemoteBackend {
+	return &PrometheusRemoteBackend{
+		HTTPErrorHandler:   b.HTTPErrorHandler,
+		log:                log,
+		WriteEventRecorder: b.WriteEventRecorder,
+		MaxBodyBytes:       b.PrometheusRemoteMaxBodyBytes,
+
+		PointsWriter:        b.PointsWriter,
+		ReadsStore:          b.ReadsStore,
+		BucketService:       b.BucketService,
+		OrganizationService: b.OrganizationService,
+	}
+}
+
+// PrometheusRemoteHandler receives the samples of Prometheus remote write
+// requests, and answers Prometheus remote read requests, for a bucket.
+type PrometheusRemoteHandler struct {
+	errors.HTTPErrorHandler
+	BucketService       influxdb.BucketService
+	OrganizationService influxdb.OrganizationService
+	PointsWriter        storage.PointsWriter
+	EventRecorder       metric.EventRecorder
+	Reader              *remote.Reader
+
+	router       *httprouter.Router
+	log          *zap.Logger
+	maxBodyBytes int64
+}
+
+const (
+	prefixPrometheusRemote = "/api/v1/prom"
+
+	opPrometheusRemoteHandler = "http/prometheusRemoteHandler"
+
+	// DefaultPrometheusRemoteMaxBodyBytes is the maximum size of the
+	// compressed and of the uncompressed body of a remote write or read
+	// request, unless configured otherwise.
+	DefaultPrometheusRemoteMaxBodyBytes = 32 << 20
+)
+
+// NewPrometheusRemoteHandler creates a new handler at /api/v1/prom to receive
+// Prometheus remote write requests, and, if b has a store to read series from,
+// to answer Prometheus remote read requests.
+func NewPrometheusRemoteHandler(log *zap.Logger, b *PrometheusRemoteBackend) *PrometheusRemoteHandler {
+	h := &PrometheusRemoteHandler{
+		HTTPErrorHandler:    b.HTTPErrorHandler,
+		BucketService:       b.BucketService,
+		OrganizationService: b.OrganizationService,
+		PointsWriter:        b.PointsWriter,
+		EventRecorder:       b.WriteEventRecorder,
+
+		router:       NewRouter(b.HTTPErrorHandler),
+		log:          log,
+		maxBodyBytes: b.MaxBodyBytes,
+	}
+	if h.maxBodyBytes <= 0 {
+		h.maxBodyBytes = DefaultPrometheusRemoteMaxBodyBytes
+	}
+	if b.ReadsStore != nil {
+		h.Reader = remote.NewReader(b.ReadsStore)
+	}
+
+	h.router.HandlerFunc(http.MethodPost, prefixPrometheusRemote+"/write", h.handleWrite)
+	h.router.HandlerFunc(http.MethodPost, prefixPrometheusRemote+"/read", h.handleRead)
+	return h
+}
+
+// Prefix provides the route prefix.
+func (*PrometheusRemoteHandler) Prefix() string {
+	return prefixPrometheusRemote
+}
+
+func (h *PrometheusRemoteHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.router.ServeHTTP(w, r)
+}
+
+func (h *PrometheusRemoteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "PrometheusRemoteHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	auth, err := pcontext.GetAuthorizer(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	org, bucket, err := h.findOrgBucket(ctx, r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	span.LogKV("org_id", org.ID, "bucket_id", bucket.ID)
+
+	sw := kithttp.NewStatusResponseWriter(w)
+	recorder := NewWriteUsageRecorder(sw, h.EventRecorder)
+	var requestBytes int
+	defer func() {
+		// Close around the requestBytes variable to placate the linter.
+		recorder.Record(ctx, requestBytes, org.ID, r.URL.Path)
+	}()
+
+	if err := checkBucketWritePermissions(auth, org.ID, bucket.ID); err != nil {
+		h.HandleHTTPError(ctx, err, sw)
+		return
+	}
+
+	var req remote.WriteRequest
+	n, err := decodeSnappyProto(r.Body, h.maxBodyBytes, &req)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, sw)
+		return
+	}
+	requestBytes = n
+
+	points, dropped, err := remote.PointsFromWriteRequest(&req)
+	if err != nil {
+		h.HandleHTTPError(ctx, &errors.Error{
+			Code: errors.EInvalid,
+			Op:   opPrometheusRemoteHandler,
+			Msg:  "invalid remote write request",
+			Err:  err,
+		}, sw)
+		return
+	}
+	if dropped > 0 {
+		h.log.Debug("Dropped samples without a number value", zap.Int("dropped", dropped), zap.Stringer("bucket_id", bucket.ID))
+	}
+
+	if len(points) > 0 {
+		if err := h.PointsWriter.WritePoints(ctx, org.ID, bucket.ID, points); err != nil {
+			if partialErr, ok := err.(tsdb.PartialWriteError); ok {
+				h.HandleHTTPError(ctx, &errors.Error{
+					Code: errors.EUnprocessableEntity,
+					Op:   opPrometheusRemoteHandler,
+					Msg:  "failure writing points to database",
+					Err:  partialErr,
+				}, sw)
+				return
+			}
+
+			h.HandleHTTPError(ctx, &errors.Error{
+				Code: errors.EInternal,
+				Op:   opPrometheusRemoteHandler,
+				Msg:  "unexpected error writing points to database",
+				Err:  err,
+			}, sw)
+			return
+		}
+	}
+
+	sw.WriteHeader(http.StatusNoContent)
+}
+
+func (h *PrometheusRemoteHandler) handleRead(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "PrometheusRemoteHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	if h.Reader == nil {
+		h.HandleHTTPError(ctx, &errors.Error{
+			Code: errors.ENotImplemented,
+			Op:   opPrometheusRemoteHandler,
+			Msg:  "remote read is not supported",
+		}, w)
+		return
+	}
+
+	auth, err := pcontext.GetAuthorizer(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	org, bucket, err := h.findOrgBucket(ctx, r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	span.LogKV("org_id", org.ID, "bucket_id", bucket.ID)
+
+	if err := checkBucketReadPermissions(auth, org.ID, bucket.ID); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	var req remote.ReadRequest
+	if _, err := decodeSnappyProto(r.Body, h.maxBodyBytes, &req); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	resp, err := h.Reader.Read(ctx, org.ID, bucket.ID, &req)
+	if err != nil {
+		h.HandleHTTPError(ctx, &errors.Error{
+			Code: errors.EInvalid,
+			Op:   opPrometheusRemoteHandler,
+			Msg:  "failed to read series",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	data, err := resp.Marshal()
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.Header().Set("Content-Encoding", "snappy")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(snappy.Encode(nil, data)); err != nil {
+		h.log.Debug("Failed to write remote read response", zap.Error(err))
+	}
+}
+
+// findOrgBucket finds the org and the bucket of the org, by ID or name, of a
+// request.
+func (h *PrometheusRemoteHandler) findOrgBucket(ctx context.Context, r *http.Request) (*influxdb.Organization, *influxdb.Bucket, error) {
+	bucketParam := r.URL.Query().Get("bucket")
+	if bucketParam == "" {
+		return nil, nil, &errors.Error{
+			Code: errors.ENotFound,
+			Op:   opPrometheusRemoteHandler,
+			Msg:  "bucket not found",
+		}
+	}
+
+	org, err := queryOrganization(ctx, r, h.OrganizationService)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	bucket, err := findBucket(ctx, h.BucketService, org.ID, bucketParam)
+	if err != nil {
+		return nil, nil, err
+	}
+	return org, bucket, nil
+}
+
+// decodeSnappyProto decodes the snappy compressed protobuf message of a body
+// into msg, returning the size of the uncompressed message. Both the body and
+// the uncompressed message must not exceed maxBodyBytes.
+func decodeSnappyProto(body io.Reader, maxBodyBytes int64, msg interface{ Unmarshal([]byte) error }) (int, error) {
+	compressed, err := ioutil.ReadAll(io.LimitReader(body, maxBodyBytes+1))
+	if err != nil {
+		return 0, &errors.Error{
+			Code: errors.EInvalid,
+			Op:   opPrometheusRemoteHandler,
+			Msg:  "unable to read request body",
+			Err:  err,
+		}
+	}
+	if int64(len(compressed)) > maxBodyBytes {
+		return 0, &errors.Error{
+			Code: errors.ETooLarge,
+			Op:   opPrometheusRemoteHandler,
+			Msg:  fmt.Sprintf("request body exceeds the maximum of %d bytes", maxBodyBytes),
+		}
+	}
+
+	n, err := snappy.DecodedLen(compressed)
+	if err != nil {
+		return 0, &errors.Error{
+			Code: errors.EInvalid,
+			Op:   opPrometheusRemoteHandler,
+			Msg:  "request body is not snappy compressed",
+			Err:  err,
+		}
+	}
+	if int64(n) > maxBodyBytes {
+		return 0, &errors.Error{
+			Code: errors.ETooLarge,
+			Op:   opPrometheusRemoteHandler,
+			Msg:  fmt.Sprintf("uncompressed request body of %d bytes exceeds the maximum of %d bytes", n, maxBodyBytes),
+		}
+	}
+
+	data, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		return 0, &errors.Error{
+			Code: errors.EInvalid,
+			Op:   opPrometheusRemoteHandler,
+			Msg:  "request body is not snappy compressed",
+			Err:  err,
+		}
+	}
+
+	if err := msg.Unmarshal(data); err != nil {
+		return 0, &errors.Error{
+			Code: errors.EInvalid,
+			Op:   opPrometheusRemoteHandler,
+			Msg:  "request body is not a protobuf message",
+			Err:  err,
+		}
+	}
+	return len(data), nil
+}
+
+// checkBucketReadPermissions checks an Authorizer for read permissions to a
+// specific Bucket.
+func checkBucketReadPermissions(auth influxdb.Authorizer, orgID, bucketID platform.ID) error {
+	p, err := influxdb.NewPermissionAtID(bucketID, influxdb.ReadAction, influxdb.BucketsResourceType, orgID)
+	if err != nil {
+		return &errors.Error{
+			Code: errors.EInternal,
+			Op:   opPrometheusRemoteHandler,
+			Msg:  fmt.Sprintf("unable to create permission for bucket: %v", err),
+			Err:  err,
+		}
+	}
+	if pset, err := auth.PermissionSet(); err != nil || !pset.Allowed(*p) {
+		return &errors.Error{
+			Code: errors.EForbidden,
+			Op:   opPrometheusRemoteHandler,
+			Msg:  "insufficient permissions for read",
+			Err:  err,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,264 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/http/metric"
+	httpmock "github.com/influxdata/influxdb/v2/http/mock"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/prometheus/remote"
+	"github.com/influxdata/influxdb/v2/storage/reads"
+	"github.com/influxdata/influxdb/v2/storage/reads/datatypes"
+	"github.com/influxdata/influxdb/v2/tsdb/cursors"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+const (
+	promOrgID    = "043e0780ee2b1000"
+	promBucketID = "04504b356e23b000"
+)
+
+func newPrometheusRemoteHandler(t *testing.T, pointsWriter *mock.PointsWriter, store reads.Store, auth *influxdb.Authorization) http.Handler {
+	orgs := mock.NewOrganizationService()
+	orgs.FindOrganizationF = func(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+		return testOrg(promOrgID), nil
+	}
+	buckets := mock.NewBucketService()
+	buckets.FindBucketFn = func(context.Context, influxdb.BucketFilter) (*influxdb.Bucket, error) {
+		return testBucket(promOrgID, promBucketID), nil
+	}
+
+	b := &APIBackend{
+		HTTPErrorHandler:    DefaultErrorHandler,
+		Logger:              zaptest.NewLogger(t),
+		OrganizationService: orgs,
+		BucketService:       buckets,
+		PointsWriter:        pointsWriter,
+		ReadsStore:          store,
+		WriteEventRecorder:  &metric.NopEventRecorder{},
+	}
+	h := NewPrometheusRemoteHandler(zaptest.NewLogger(t), NewPrometheusRemoteBackend(zaptest.NewLogger(t), b))
+	return httpmock.NewAuthMiddlewareHandler(h, auth)
+}
+
+func snappyProto(t *testing.T, msg proto.Message) *bytes.Reader {
+	data, err := proto.Marshal(msg)
+	require.NoError(t, err)
+	return bytes.NewReader(snappy.Encode(nil, data))
+}
+
+func TestPrometheusRemoteHandler_handleWrite(t *testing.T) {
+	req := &remote.WriteRequest{
+		Timeseries: []remote.TimeSeries{{
+			Labels:  []remote.Label{{Name: "__name__", Value: "up"}, {Name: "job", Value: "node"}},
+			Samples: []remote.Sample{{Value: 1, Timestamp: 1600000000000}},
+		}},
+	}
+
+	tests := []struct {
+		name string
+		body func(t *testing.T) *bytes.Reader
+		auth *influxdb.Authorization
+		code int
+		resp string
+	}{
+		{
+			name: "samples are written as points",
+			body: func(t *testing.T) *bytes.Reader { return snappyProto(t, req) },
+			auth: bucketWritePermission(promOrgID, promBucketID),
+			code: 204,
+		},
+		{
+			name: "body must be snappy compressed",
+			body: func(t *testing.T) *bytes.Reader { return bytes.NewReader([]byte("up 1")) },
+			auth: bucketWritePermission(promOrgID, promBucketID),
+			code: 400,
+			resp: `{"code":"invalid","message":"request body is not snappy compressed: snappy: corrupt input"}`,
+		},
+		{
+			name: "write permission is required",
+			body: func(t *testing.T) *bytes.Reader { return snappyProto(t, req) },
+			auth: bucketReadPermission(promOrgID, promBucketID),
+			code: 403,
+			resp: `{"code":"forbidden","message":"insufficient permissions for write"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pointsWriter := &mock.PointsWriter{}
+			handler := newPrometheusRemoteHandler(t, pointsWriter, nil, tt.auth)
+
+			r := httptest.NewRequest("POST", "http://localhost:8086/api/v1/prom/write?org="+promOrgID+"&bucket="+promBucketID, tt.body(t))
+			r.Header.Set("Content-Encoding", "snappy")
+			r.Header.Set("Content-Type", "application/x-protobuf")
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			require.Equal(t, tt.code, w.Code)
+			require.Equal(t, tt.resp, w.Body.String())
+
+			if tt.code != 204 {
+				return
+			}
+			require.Len(t, pointsWriter.Points, 1)
+			require.Equal(t, "up,job=node value=1 1600000000000000000", pointsWriter.Points[0].String())
+		})
+	}
+}
+
+func TestDecodeSnappyProto(t *testing.T) {
+	data, err := proto.Marshal(&remote.WriteRequest{
+		Timeseries: []remote.TimeSeries{{
+			Labels:  []remote.Label{{Name: "__name__", Value: "up"}},
+			Samples: []remote.Sample{{Value: 1, Timestamp: 1600000000000}},
+		}},
+	})
+	require.NoError(t, err)
+	compressed := snappy.Encode(nil, data)
+
+	tests := []struct {
+		name string
+		body []byte
+		max  int64
+		code string
+	}{
+		{
+			name: "body within the maximum",
+			body: compressed,
+			max:  int64(len(data)),
+		},
+		{
+			name: "body exceeds the maximum",
+			body: append(compressed, make([]byte, 64)...),
+			max:  int64(len(compressed)),
+			code: errors.ETooLarge,
+		},
+		{
+			name: "uncompressed body exceeds the maximum",
+			body: snappy.Encode(nil, make([]byte, 1024)),
+			max:  512,
+			code: errors.ETooLarge,
+		},
+		{
+			name: "invalid decoded length",
+			body: []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+			max:  1024,
+			code: errors.EInvalid,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req remote.WriteRequest
+			n, err := decodeSnappyProto(bytes.NewReader(tt.body), tt.max, &req)
+			if tt.code == "" {
+				require.NoError(t, err)
+				require.Equal(t, len(data), n)
+				require.Len(t, req.Timeseries, 1)
+				return
+			}
+			require.Error(t, err)
+			require.Equal(t, tt.code, errors.ErrorCode(err))
+		})
+	}
+}
+
+// promStore is a store of a series of up with a sample at 1s.
+type promStore struct {
+	reads.Store
+}
+
+func (promStore) ReadFilter(context.Context, *datatypes.ReadFilterRequest) (reads.ResultSet, error) {
+	return &promResultSet{}, nil
+}
+
+func (promStore) GetSource(orgID, bucketID uint64) proto.Message {
+	return &datatypes.Tag{}
+}
+
+type promResultSet struct {
+	reads.ResultSet
+	done bool
+}
+
+func (rs *promResultSet) Next() bool {
+	next := !rs.done
+	rs.done = true
+	return next
+}
+
+func (rs *promResultSet) Tags() models.Tags {
+	return models.Tags{
+		models.NewTag(models.MeasurementTagKeyBytes, []byte("up")),
+		models.NewTag([]byte("job"), []byte("node")),
+		models.NewTag(models.FieldKeyTagKeyBytes, []byte("value")),
+	}
+}
+
+func (rs *promResultSet) Cursor() cursors.Cursor {
+	return &promCursor{a: &cursors.FloatArray{Timestamps: []int64{1000000000}, Values: []float64{1}}}
+}
+
+func (rs *promResultSet) Close()     {}
+func (rs *promResultSet) Err() error { return nil }
+
+type promCursor struct {
+	cursors.FloatArrayCursor
+	a *cursors.FloatArray
+}
+
+func (c *promCursor) Next() *cursors.FloatArray {
+	a := c.a
+	c.a = cursors.NewFloatArrayLen(0)
+	return a
+}
+
+func (c *promCursor) Close()     {}
+func (c *promCursor) Err() error { return nil }
+
+func TestPrometheusRemoteHandler_handleRead(t *testing.T) {
+	handler := newPrometheusRemoteHandler(t, &mock.PointsWriter{}, promStore{}, bucketReadPermission(promOrgID, promBucketID))
+
+	body := snappyProto(t, &remote.ReadRequest{
+		Queries: []*remote.Query{{
+			StartTimestampMs: 0,
+			EndTimestampMs:   2000,
+			Matchers:         []*remote.LabelMatcher{{Type: remote.LabelMatcher_EQ, Name: "__name__", Value: "up"}},
+		}},
+	})
+	r := httptest.NewRequest("POST", "http://localhost:8086/api/v1/prom/read?org="+promOrgID+"&bucket="+promBucketID, body)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	require.Equal(t, 200, w.Code, w.Body.String())
+	require.Equal(t, "snappy", w.Header().Get("Content-Encoding"))
+
+	compressed, err := ioutil.ReadAll(w.Body)
+	require.NoError(t, err)
+	data, err := snappy.Decode(nil, compressed)
+	require.NoError(t, err)
+	var resp remote.ReadResponse
+	require.NoError(t, proto.Unmarshal(data, &resp))
+	require.Equal(t, remote.ReadResponse{
+		Results: []*remote.QueryResult{{
+			Timeseries: []*remote.TimeSeries{{
+				Labels:  []remote.Label{{Name: "__name__", Value: "up"}, {Name: "job", Value: "node"}},
+				Samples: []remote.Sample{{Value: 1, Timestamp: 1000}},
+			}},
+		}},
+	}, resp)
+}
+
+func bucketReadPermission(org, bucket string) *influxdb.Authorization {
+	auth := bucketWritePermission(org, bucket)
+	auth.Permissions[0].Action = influxdb.ReadAction
+	return auth
+}
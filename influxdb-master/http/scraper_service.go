@@ -29,6 +29,7 @@ type ScraperBackend struct {
 	log *zap.Logger
 
 	ScraperStorageService      influxdb.ScraperTargetStoreService
+	ScraperHealthService       influxdb.ScraperHealthService
 	BucketService              influxdb.BucketService
 	OrganizationService        influxdb.OrganizationService
 	UserService                influxdb.UserService
@@ -43,6 +44,7 @@ func NewScraperBackend(log *zap.Logger, b *APIBackend) *ScraperBackend {
 		log:              log,
 
 		ScraperStorageService:      b.ScraperTargetStoreService,
+		ScraperHealthService:       b.ScraperHealthService,
 		BucketService:              b.BucketService,
 		OrganizationService:        b.OrganizationService,
 		UserService:                b.UserService,
@@ -60,6 +62,7 @@ type ScraperHandler struct {
 	UserResourceMappingService influxdb.UserResourceMappingService
 	LabelService               influxdb.LabelService
 	ScraperStorageService      influxdb.ScraperTargetStoreService
+	ScraperHealthService       influxdb.ScraperHealthService
 	BucketService              influxdb.BucketService
 	OrganizationService        influxdb.OrganizationService
 }
@@ -84,6 +87,7 @@ func NewScraperHandler(log *zap.Logger, b *ScraperBackend) *ScraperHandler {
 		UserResourceMappingService: b.UserResourceMappingService,
 		LabelService:               b.LabelService,
 		ScraperStorageService:      b.ScraperStorageService,
+		ScraperHealthService:       b.ScraperHealthService,
 		BucketService:              b.BucketService,
 		OrganizationService:        b.OrganizationService,
 	}
@@ -149,7 +153,7 @@ func (h *ScraperHandler) handlePostScraperTarget(w http.ResponseWriter, r *http.
 		h.HandleHTTPError(ctx, err, w)
 		return
 	}
-	h.log.Debug("Scraper created", zap.String("scraper", fmt.Sprint(req)))
+	h.log.Debug("Scraper created", zap.String("scraper", fmt.Sprint(req.Redacted())))
 
 	resp, err := h.newTargetResponse(ctx, *req)
 	if err != nil {
@@ -200,7 +204,7 @@ func (h *ScraperHandler) handlePatchScraperTarget(w http.ResponseWriter, r *http
 		h.HandleHTTPError(ctx, err, w)
 		return
 	}
-	h.log.Debug("Scraper updated", zap.String("scraper", fmt.Sprint(target)))
+	h.log.Debug("Scraper updated", zap.String("scraper", fmt.Sprint(target.Redacted())))
 
 	resp, err := h.newTargetResponse(ctx, *target)
 	if err != nil {
@@ -226,7 +230,7 @@ func (h *ScraperHandler) handleGetScraperTarget(w http.ResponseWriter, r *http.R
 		h.HandleHTTPError(ctx, err, w)
 		return
 	}
-	h.log.Debug("Scraper retrieved", zap.String("scraper", fmt.Sprint(target)))
+	h.log.Debug("Scraper retrieved", zap.String("scraper", fmt.Sprint(target.Redacted())))
 
 	resp, err := h.newTargetResponse(ctx, *target)
 	if err != nil {
@@ -288,7 +292,7 @@ func (h *ScraperHandler) handleGetScraperTargets(w http.ResponseWriter, r *http.
 		h.HandleHTTPError(ctx, err, w)
 		return
 	}
-	h.log.Debug("Scrapers retrieved", zap.String("scrapers", fmt.Sprint(targets)))
+	h.log.Debug("Scrapers retrieved", zap.Int("scrapers", len(targets)))
 
 	resp, err := h.newListTargetsResponse(ctx, targets)
 	if err != nil {
@@ -583,9 +587,10 @@ type targetLinks struct {
 
 type targetResponse struct {
 	influxdb.ScraperTarget
-	Org    string      `json:"org,omitempty"`
-	Bucket string      `json:"bucket,omitempty"`
-	Links  targetLinks `json:"links"`
+	Org    string                        `json:"org,omitempty"`
+	Bucket string                        `json:"bucket,omitempty"`
+	Health *influxdb.ScraperTargetHealth `json:"health,omitempty"`
+	Links  targetLinks                   `json:"links"`
 }
 
 func (h *ScraperHandler) newListTargetsResponse(ctx context.Context, targets []influxdb.ScraperTarget) (getTargetsResponse, error) {
@@ -614,7 +619,7 @@ func (h *ScraperHandler) newTargetResponse(ctx context.Context, target influxdb.
 			Members: fmt.Sprintf("/api/v2/scrapers/%s/members", target.ID),
 			Owners:  fmt.Sprintf("/api/v2/scrapers/%s/owners", target.ID),
 		},
-		ScraperTarget: target,
+		ScraperTarget: target.Redacted(),
 	}
 	bucket, err := h.BucketService.FindBucketByID(ctx, target.BucketID)
 	if err == nil {
@@ -634,6 +639,14 @@ func (h *ScraperHandler) newTargetResponse(ctx context.Context, target influxdb.
 		res.OrgID = platform.InvalidID()
 	}
 
+	if h.ScraperHealthService != nil {
+		health, err := h.ScraperHealthService.ScraperTargetHealth(ctx, target.ID)
+		if err != nil {
+			return res, err
+		}
+		res.Health = health
+	}
+
 	return res, nil
 }
 
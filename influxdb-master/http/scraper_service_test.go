@@ -134,6 +134,8 @@ func TestService_handleGetScraperTargets(t *testing.T) {
 						  "orgID": "0000000000000211",
 						  "type": "prometheus",
 						  "url": "www.one.url",
+						  "interval": "0s",
+						  "timeout": "0s",
 						  "links": {
 						    "bucket": "/api/v2/buckets/0000000000000212",
 						    "organization": "/api/v2/orgs/0000000000000211",
@@ -151,6 +153,8 @@ func TestService_handleGetScraperTargets(t *testing.T) {
 						  "org": "org1",
 						  "type": "prometheus",
 						  "url": "www.two.url",
+						  "interval": "0s",
+						  "timeout": "0s",
 						  "links": {
 						    "bucket": "/api/v2/buckets/0000000000000212",
 						    "organization": "/api/v2/orgs/0000000000000211",
@@ -326,6 +330,8 @@ func TestService_handleGetScraperTarget(t *testing.T) {
                       "name": "target-1",
                       "type": "prometheus",
 					  "url": "www.some.url",
+					  "interval": "0s",
+					  "timeout": "0s",
 					  "bucket": "bucket1",
                       "bucketID": "0000000000000212",
 					  "orgID": "0000000000000211",
@@ -343,6 +349,77 @@ func TestService_handleGetScraperTarget(t *testing.T) {
 				),
 			},
 		},
+		{
+			name: "get a scraper target with redacted headers and tls key",
+			fields: fields{
+				OrganizationService: &mock.OrganizationService{
+					FindOrganizationByIDF: func(ctx context.Context, id platform.ID) (*influxdb.Organization, error) {
+						return &influxdb.Organization{
+							ID:   platformtesting.MustIDBase16("0000000000000211"),
+							Name: "org1",
+						}, nil
+					},
+				},
+				BucketService: &mock.BucketService{
+					FindBucketByIDFn: func(ctx context.Context, id platform.ID) (*influxdb.Bucket, error) {
+						return &influxdb.Bucket{
+							ID:   platformtesting.MustIDBase16("0000000000000212"),
+							Name: "bucket1",
+						}, nil
+					},
+				},
+				ScraperTargetStoreService: &mock.ScraperTargetStoreService{
+					GetTargetByIDF: func(ctx context.Context, id platform.ID) (*influxdb.ScraperTarget, error) {
+						return &influxdb.ScraperTarget{
+							ID:       targetOneID,
+							Name:     "target-1",
+							Type:     influxdb.PrometheusScraperType,
+							URL:      "https://www.some.url",
+							OrgID:    platformtesting.MustIDBase16("0000000000000211"),
+							BucketID: platformtesting.MustIDBase16("0000000000000212"),
+							Headers:  map[string]string{"Authorization": "Bearer secret"},
+							TLS: &influxdb.ScraperTLSConfig{
+								Cert: "cert",
+								Key:  "key",
+							},
+						}, nil
+					},
+				},
+			},
+			args: args{
+				id: targetOneIDString,
+			},
+			wants: wants{
+				statusCode:  http.StatusOK,
+				contentType: "application/json; charset=utf-8",
+				body: fmt.Sprintf(
+					`
+                    {
+                      "id": "%s",
+                      "name": "target-1",
+                      "type": "prometheus",
+                      "url": "https://www.some.url",
+                      "bucket": "bucket1",
+                      "bucketID": "0000000000000212",
+                      "orgID": "0000000000000211",
+                      "org": "org1",
+                      "interval": "0s",
+                      "timeout": "0s",
+                      "headers": {"Authorization": "<redacted>"},
+                      "tls": {"cert": "cert", "key": "<redacted>"},
+                      "links": {
+                        "bucket": "/api/v2/buckets/0000000000000212",
+                        "organization": "/api/v2/orgs/0000000000000211",
+                        "self": "/api/v2/scrapers/%s",
+                        "members": "/api/v2/scrapers/%s/members",
+                        "owners": "/api/v2/scrapers/%s/owners"
+                      }
+                    }
+                    `,
+					targetOneIDString, targetOneIDString, targetOneIDString, targetOneIDString,
+				),
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -565,6 +642,8 @@ func TestService_handlePostScraperTarget(t *testing.T) {
                       "name": "hello",
                       "type": "prometheus",
                       "url": "www.some.url",
+                      "interval": "0s",
+                      "timeout": "0s",
 					  "orgID": "0000000000000211",
 					  "org": "org1",
 					  "bucket": "bucket1",
@@ -701,6 +780,8 @@ func TestService_handlePatchScraperTarget(t *testing.T) {
 		              "name":"name",
 		              "type":"prometheus",
 					  "url":"www.example.url",
+					  "interval":"0s",
+					  "timeout":"0s",
 					  "org": "org1",
 					  "orgID":"0000000000000211",
 					  "bucket": "bucket1",
@@ -0,0 +1,108 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+	"go.uber.org/zap"
+)
+
+// StorageUsageHandler is the http handler of the storage usage of a bucket,
+// embedded in the bucket API at /api/v2/buckets/{id}/usage.
+type StorageUsageHandler struct {
+	chi.Router
+	api *kithttp.API
+	log *zap.Logger
+
+	StorageUsageService influxdb.StorageUsageService
+}
+
+// NewStorageUsageHandler creates a storage usage handler for embedding in the
+// bucket API.
+func NewStorageUsageHandler(log *zap.Logger, s influxdb.StorageUsageService) *StorageUsageHandler {
+	h := &StorageUsageHandler{
+		api:                 kithttp.NewAPI(kithttp.WithLog(log)),
+		log:                 log,
+		StorageUsageService: s,
+	}
+
+	r := chi.NewRouter()
+	r.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		h.api.Err(w, r, &errors.Error{
+			Code: errors.ENotFound,
+			Msg:  "path not found",
+		})
+	})
+	r.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+		h.api.Err(w, r, &errors.Error{
+			Code: errors.EMethodNotAllowed,
+			Msg:  fmt.Sprintf("allow: %s", w.Header().Get("Allow")),
+		})
+	})
+	r.Use(
+		kithttp.SkipOptions,
+		middleware.StripSlashes,
+		kithttp.SetCORS,
+		middleware.Recoverer,
+		middleware.RequestID,
+		middleware.RealIP,
+	)
+
+	r.Get("/", h.handleGetStorageUsage)
+
+	h.Router = r
+	return h
+}
+
+func (h *StorageUsageHandler) handleGetStorageUsage(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "StorageUsageHandler.handleGetStorageUsage")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	bucketID, err := platform.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	usage, err := h.StorageUsageService.FindBucketStorageUsage(ctx, *bucketID, r.URL.Query().Get("tagKey"))
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.api.Respond(w, r, http.StatusOK, usage)
+}
+
+// StorageUsageService is the client implementation of
+// influxdb.StorageUsageService.
+type StorageUsageService struct {
+	Client *httpc.Client
+}
+
+// FindBucketStorageUsage returns the storage usage of a bucket.
+func (s *StorageUsageService) FindBucketStorageUsage(ctx context.Context, bucketID platform.ID, tagKey string) (*influxdb.BucketStorageUsage, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var usage influxdb.BucketStorageUsage
+	err := s.Client.
+		Get(path.Join(prefixBuckets, bucketID.String(), "usage")).
+		QueryParams([2]string{"tagKey", tagKey}).
+		DecodeJSON(&usage).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &usage, nil
+}
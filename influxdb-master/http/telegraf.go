@@ -71,6 +71,8 @@ const (
 
 	prefixTelegrafPlugins = "/api/v2/telegraf"
 	telegrafPluginsPath   = "/api/v2/telegraf/plugins"
+	telegrafSchemasPath   = "/api/v2/telegraf/plugins/schemas"
+	telegrafRenderPath    = "/api/v2/telegraf/plugins/render"
 )
 
 // NewTelegrafHandler returns a new instance of TelegrafHandler.
@@ -93,6 +95,8 @@ func NewTelegrafHandler(log *zap.Logger, b *TelegrafBackend) *TelegrafHandler {
 	h.HandlerFunc("PUT", telegrafsIDPath, h.handlePutTelegraf)
 
 	h.HandlerFunc("GET", telegrafPluginsPath, h.handleGetTelegrafPlugins)
+	h.HandlerFunc("GET", telegrafSchemasPath, h.handleGetTelegrafSchemas)
+	h.HandlerFunc("POST", telegrafRenderPath, h.handlePostTelegrafRender)
 
 	memberBackend := MemberBackend{
 		HTTPErrorHandler:           b.HTTPErrorHandler,
@@ -171,6 +175,73 @@ func (h *TelegrafHandler) handleGetTelegrafPlugins(w http.ResponseWriter, r *htt
 	}
 }
 
+// handleGetTelegrafSchemas is the HTTP handler for the GET /api/v2/telegraf/plugins/schemas route.
+func (h *TelegrafHandler) handleGetTelegrafSchemas(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	schemas, err := plugins.ListSchemas(r.URL.Query().Get("type"))
+	if err != nil {
+		h.HandleHTTPError(ctx, &errors.Error{
+			Code: errors.EInvalid,
+			Err:  err,
+		}, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, schemas); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+type telegrafRenderRequest struct {
+	Plugins []plugins.PluginDefinition `json:"plugins"`
+}
+
+type telegrafRenderResponse struct {
+	Config string `json:"config"`
+}
+
+// handlePostTelegrafRender is the HTTP handler for the POST /api/v2/telegraf/plugins/render route.
+// It renders the config of the plugins of the request, following the default agent config.
+func (h *TelegrafHandler) handlePostTelegrafRender(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req telegrafRenderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.HandleHTTPError(ctx, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "failed to decode request body",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	cfg, err := plugins.Render(req.Plugins)
+	if err != nil {
+		h.HandleHTTPError(ctx, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "invalid telegraf plugins",
+			Err:  err,
+		}, w)
+		return
+	}
+	cfg = plugins.AgentConfig + cfg
+
+	offers := []string{"application/toml", "application/json"}
+	switch httputil.NegotiateContentType(r, offers, "application/toml") {
+	case "application/json":
+		if err := encodeResponse(ctx, w, http.StatusOK, telegrafRenderResponse{Config: cfg}); err != nil {
+			logEncodingError(h.log, r, err)
+			return
+		}
+	default:
+		w.Header().Set("Content-Type", "application/toml; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(cfg))
+	}
+}
+
 func newTelegrafResponse(tc *influxdb.TelegrafConfig, labels []*influxdb.Label) *telegrafResponse {
 	res := &telegrafResponse{
 		TelegrafConfig: tc,
@@ -13,7 +13,9 @@ import (
 	platform2 "github.com/influxdata/influxdb/v2/kit/platform"
 
 	platform "github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
 	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/telegraf/plugins"
 	"go.uber.org/zap/zaptest"
 )
 
@@ -842,6 +844,86 @@ func TestTelegrafHandler_handleGetTelegraf(t *testing.T) {
 	}
 }
 
+func TestTelegrafHandler_handlePostTelegrafRender(t *testing.T) {
+	type wants struct {
+		statusCode  int
+		contentType string
+		body        string
+	}
+	tests := []struct {
+		name         string
+		body         string
+		acceptHeader string
+		wants        wants
+	}{
+		{
+			name:         "render plugins as json",
+			body:         `{"plugins": [{"type": "input", "name": "cpu", "config": {"percpu": false}}]}`,
+			acceptHeader: "application/json",
+			wants: wants{
+				statusCode:  http.StatusOK,
+				contentType: "application/json; charset=utf-8",
+				body:        fmt.Sprintf(`{"config": %q}`, plugins.AgentConfig+"# Read metrics about cpu usage\n[[inputs.cpu]]\n  percpu = false\n"),
+			},
+		},
+		{
+			name:         "render plugins as toml",
+			body:         `{"plugins": [{"type": "output", "name": "influxdb_v2", "config": {"urls": ["http://localhost:8086"], "bucket": "b1"}}]}`,
+			acceptHeader: "application/toml",
+			wants: wants{
+				statusCode:  http.StatusOK,
+				contentType: "application/toml; charset=utf-8",
+				body:        plugins.AgentConfig + "# Configuration for sending metrics to InfluxDB\n[[outputs.influxdb_v2]]\n  bucket = \"b1\"\n  urls = [\"http://localhost:8086\"]\n",
+			},
+		},
+		{
+			name:         "invalid option",
+			body:         `{"plugins": [{"type": "input", "name": "cpu", "config": {"percpu": "yes"}}]}`,
+			acceptHeader: "application/json",
+			wants: wants{
+				statusCode:  http.StatusBadRequest,
+				contentType: "application/json; charset=utf-8",
+				body: `{
+  "code": "invalid",
+  "message": "invalid telegraf plugins: plugin 0: inputs.cpu: option \"percpu\" must be a bool, got a string"
+}`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "http://any.url/api/v2/telegraf/plugins/render", strings.NewReader(tt.body))
+			r.Header.Set("Accept", tt.acceptHeader)
+			w := httptest.NewRecorder()
+			telegrafBackend := NewMockTelegrafBackend(t)
+			telegrafBackend.HTTPErrorHandler = kithttp.ErrorHandler(0)
+			h := NewTelegrafHandler(zaptest.NewLogger(t), telegrafBackend)
+
+			h.ServeHTTP(w, r)
+
+			res := w.Result()
+			content := res.Header.Get("Content-Type")
+			body, _ := ioutil.ReadAll(res.Body)
+
+			if res.StatusCode != tt.wants.statusCode {
+				t.Errorf("%q. handlePostTelegrafRender() = %v, want %v", tt.name, res.StatusCode, tt.wants.statusCode)
+			}
+			if content != tt.wants.contentType {
+				t.Errorf("%q. handlePostTelegrafRender() = %v, want %v", tt.name, content, tt.wants.contentType)
+				return
+			}
+
+			if strings.Contains(tt.wants.contentType, "application/json") {
+				if eq, diff, _ := jsonEqual(string(body), tt.wants.body); !eq {
+					t.Errorf("%q. handlePostTelegrafRender() = ***%s***", tt.name, diff)
+				}
+			} else if string(body) != tt.wants.body {
+				t.Errorf("%q. handlePostTelegrafRender() = \n***%v***\nwant\n***%v***", tt.name, string(body), tt.wants.body)
+			}
+		})
+	}
+}
+
 func Test_newTelegrafResponses(t *testing.T) {
 	type args struct {
 		tcs []*platform.TelegrafConfig
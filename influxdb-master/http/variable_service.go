@@ -24,18 +24,20 @@ const (
 // the VariableHandler.
 type VariableBackend struct {
 	errors.HTTPErrorHandler
-	log             *zap.Logger
-	VariableService influxdb.VariableService
-	LabelService    influxdb.LabelService
+	log                   *zap.Logger
+	VariableService       influxdb.VariableService
+	VariableValuesService influxdb.VariableValuesService
+	LabelService          influxdb.LabelService
 }
 
 // NewVariableBackend creates a backend used by the variable handler.
 func NewVariableBackend(log *zap.Logger, b *APIBackend) *VariableBackend {
 	return &VariableBackend{
-		HTTPErrorHandler: b.HTTPErrorHandler,
-		log:              log,
-		VariableService:  b.VariableService,
-		LabelService:     b.LabelService,
+		HTTPErrorHandler:      b.HTTPErrorHandler,
+		log:                   log,
+		VariableService:       b.VariableService,
+		VariableValuesService: b.VariableValuesService,
+		LabelService:          b.LabelService,
 	}
 }
 
@@ -46,8 +48,9 @@ type VariableHandler struct {
 	errors.HTTPErrorHandler
 	log *zap.Logger
 
-	VariableService influxdb.VariableService
-	LabelService    influxdb.LabelService
+	VariableService       influxdb.VariableService
+	VariableValuesService influxdb.VariableValuesService
+	LabelService          influxdb.LabelService
 }
 
 // NewVariableHandler creates a new VariableHandler
@@ -57,12 +60,14 @@ func NewVariableHandler(log *zap.Logger, b *VariableBackend) *VariableHandler {
 		HTTPErrorHandler: b.HTTPErrorHandler,
 		log:              log,
 
-		VariableService: b.VariableService,
-		LabelService:    b.LabelService,
+		VariableService:       b.VariableService,
+		VariableValuesService: b.VariableValuesService,
+		LabelService:          b.LabelService,
 	}
 
 	entityPath := fmt.Sprintf("%s/:id", prefixVariables)
 	entityLabelsPath := fmt.Sprintf("%s/labels", entityPath)
+	entityValuesPath := fmt.Sprintf("%s/values", entityPath)
 	entityLabelsIDPath := fmt.Sprintf("%s/:lid", entityLabelsPath)
 
 	h.HandlerFunc("GET", prefixVariables, h.handleGetVariables)
@@ -71,6 +76,7 @@ func NewVariableHandler(log *zap.Logger, b *VariableBackend) *VariableHandler {
 	h.HandlerFunc("PATCH", entityPath, h.handlePatchVariable)
 	h.HandlerFunc("PUT", entityPath, h.handlePutVariable)
 	h.HandlerFunc("DELETE", entityPath, h.handleDeleteVariable)
+	h.HandlerFunc("POST", entityValuesPath, h.handlePostVariableValues)
 
 	labelBackend := &LabelBackend{
 		HTTPErrorHandler: b.HTTPErrorHandler,
@@ -242,6 +248,38 @@ func newVariableResponse(m *influxdb.Variable, labels []*influxdb.Label) variabl
 	return res
 }
 
+// handlePostVariableValues is the HTTP handler for the POST /api/v2/variables/:id/values route.
+func (h *VariableHandler) handlePostVariableValues(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := requestVariableID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	var req influxdb.VariableValuesRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.HandleHTTPError(ctx, &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  err.Error(),
+			}, w)
+			return
+		}
+	}
+
+	values, err := h.VariableValuesService.FindVariableValues(ctx, id, req)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Variable values evaluated", zap.String("id", id.String()), zap.Int("values", len(values.Values)))
+	if err := encodeResponse(ctx, w, http.StatusOK, values); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
 func (h *VariableHandler) handlePostVariable(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	req, err := decodePostVariableRequest(r)
@@ -539,3 +577,17 @@ func (s *VariableService) DeleteVariable(ctx context.Context, id platform.ID) er
 		Delete(prefixVariables, id.String()).
 		Do(ctx)
 }
+
+// FindVariableValues evaluates the values of a variable
+func (s *VariableService) FindVariableValues(ctx context.Context, id platform.ID, req influxdb.VariableValuesRequest) (*influxdb.VariableValues, error) {
+	var values influxdb.VariableValues
+	err := s.Client.
+		PostJSON(req, prefixVariables, id.String(), "values").
+		DecodeJSON(&values).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &values, nil
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
 
 	"github.com/influxdata/influxdb/v2/kit/platform"
@@ -113,9 +114,10 @@ func NewWriteHandler(log *zap.Logger, b *WriteBackend, opts ...WriteHandlerOptio
 	return h
 }
 
-func (h *WriteHandler) findBucket(ctx context.Context, orgID platform.ID, bucket string) (*influxdb.Bucket, error) {
+// findBucket finds the bucket of an org by ID, or else by name.
+func findBucket(ctx context.Context, svc influxdb.BucketService, orgID platform.ID, bucket string) (*influxdb.Bucket, error) {
 	if id, err := platform.IDFromString(bucket); err == nil {
-		b, err := h.BucketService.FindBucket(ctx, influxdb.BucketFilter{
+		b, err := svc.FindBucket(ctx, influxdb.BucketFilter{
 			OrganizationID: &orgID,
 			ID:             id,
 		})
@@ -126,7 +128,7 @@ func (h *WriteHandler) findBucket(ctx context.Context, orgID platform.ID, bucket
 		}
 	}
 
-	return h.BucketService.FindBucket(ctx, influxdb.BucketFilter{
+	return svc.FindBucket(ctx, influxdb.BucketFilter{
 		OrganizationID: &orgID,
 		Name:           &bucket,
 	})
@@ -168,7 +170,7 @@ func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
 		recorder.Record(ctx, requestBytes, org.ID, r.URL.Path)
 	}()
 
-	bucket, err := h.findBucket(ctx, org.ID, req.Bucket)
+	bucket, err := findBucket(ctx, h.BucketService, org.ID, req.Bucket)
 	if err != nil {
 		h.HandleHTTPError(ctx, err, sw)
 		return
@@ -183,7 +185,12 @@ func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
 	// TODO: Backport?
 	//opts := append([]models.ParserOption{}, h.parserOptions...)
 	//opts = append(opts, models.WithParserPrecision(req.Precision))
-	parsed, err := points.NewParser(req.Precision).Parse(ctx, org.ID, bucket.ID, req.Body)
+	var parsed *points.ParsedPoints
+	if req.PacketBoundary != "" {
+		parsed, err = points.ParseCCSDS(ctx, req.PacketBoundary, req.Body)
+	} else {
+		parsed, err = points.NewParser(req.Precision).Parse(ctx, org.ID, bucket.ID, req.Body)
+	}
 	if err != nil {
 		h.HandleHTTPError(ctx, err, sw)
 		return
@@ -243,6 +250,9 @@ type writeRequest struct {
 	Bucket    string
 	Precision string
 	Body      io.ReadCloser
+	// PacketBoundary is the multipart boundary of a body of CCSDS space packets,
+	// empty for line protocol bodies.
+	PacketBoundary string
 }
 
 // decodeWriteRequest extracts information from an http.Request object to
@@ -271,6 +281,18 @@ func decodeWriteRequest(ctx context.Context, r *http.Request, maxBatchSizeBytes
 		}
 	}
 
+	var boundary string
+	if mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err == nil && mediaType == points.CCSDSContentType {
+		boundary = params["boundary"]
+		if boundary == "" {
+			return nil, &errors.Error{
+				Code: errors.EInvalid,
+				Op:   "http/newWriteRequest",
+				Msg:  "multipart boundary is required for " + points.CCSDSContentType,
+			}
+		}
+	}
+
 	encoding := r.Header.Get("Content-Encoding")
 	body, err := points.BatchReadCloser(r.Body, encoding, maxBatchSizeBytes)
 	if err != nil {
@@ -278,10 +300,11 @@ func decodeWriteRequest(ctx context.Context, r *http.Request, maxBatchSizeBytes
 	}
 
 	return &writeRequest{
-		Bucket:    qp.Get("bucket"),
-		Org:       qp.Get("org"),
-		Precision: precision,
-		Body:      body,
+		Bucket:         qp.Get("bucket"),
+		Org:            qp.Get("org"),
+		Precision:      precision,
+		Body:           body,
+		PacketBoundary: boundary,
 	}, nil
 }
 
@@ -1,11 +1,13 @@
 package http
 
 import (
+	"bytes"
 	"compress/gzip"
 	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -16,6 +18,7 @@ import (
 
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/http/metric"
+	"github.com/influxdata/influxdb/v2/http/points"
 	httpmock "github.com/influxdata/influxdb/v2/http/mock"
 	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
 	"github.com/influxdata/influxdb/v2/mock"
@@ -423,6 +426,113 @@ func TestWriteHandler_handleWrite(t *testing.T) {
 	}
 }
 
+func TestWriteHandler_handleWrite_CCSDS(t *testing.T) {
+	const (
+		orgID    = "043e0780ee2b1000"
+		bucketID = "04504b356e23b000"
+	)
+
+	const packetDef = `
+packets:
+  - apid: 5
+    measurement: obc
+    time: {offset: 0, epoch: 1970-01-01T00:00:00Z}
+    fields: [{name: cpu, offset: 4, bits: 8}]
+`
+	// a space packet of apid 5 at 1s since the unix epoch with cpu 42
+	packet := []byte{0x08, 0x05, 0xC0, 0x00, 0x00, 0x04, 0, 0, 0, 1, 42}
+
+	newBody := func(t *testing.T, parts ...string) (*bytes.Buffer, string) {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		for i := 0; i < len(parts); i += 2 {
+			w, err := mw.CreateFormFile(parts[i], parts[i])
+			require.NoError(t, err)
+			_, err = w.Write([]byte(parts[i+1]))
+			require.NoError(t, err)
+		}
+		require.NoError(t, mw.Close())
+		return &body, points.CCSDSContentType + "; boundary=" + mw.Boundary()
+	}
+
+	tests := []struct {
+		name  string
+		parts []string
+		code  int
+		body  string
+	}{
+		{
+			name:  "packets are written as points",
+			parts: []string{"packet-def", packetDef, "packets", string(packet) + string(packet)},
+			code:  204,
+		},
+		{
+			name:  "invalid packet definition returns 400",
+			parts: []string{"packet-def", "packets: []", "packets", string(packet)},
+			code:  400,
+			body:  `{"code":"invalid","message":"invalid packet definition: packet definition requires at least one packet"}`,
+		},
+		{
+			name:  "packet definition must be the first part",
+			parts: []string{"packets", string(packet), "packet-def", packetDef},
+			code:  400,
+			body:  `{"code":"invalid","message":"invalid multipart/vnd.influx.ccsds body: expected part \"packet-def\", got \"packets\""}`,
+		},
+		{
+			name:  "truncated packets return 400",
+			parts: []string{"packet-def", packetDef, "packets", string(packet[:8])},
+			code:  400,
+			body:  `{"code":"invalid","message":"packet 1 (apid 5, offset 0): truncated packet data field: unexpected EOF"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgs := mock.NewOrganizationService()
+			orgs.FindOrganizationF = func(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+				return testOrg(orgID), nil
+			}
+			buckets := mock.NewBucketService()
+			buckets.FindBucketFn = func(context.Context, influxdb.BucketFilter) (*influxdb.Bucket, error) {
+				return testBucket(orgID, bucketID), nil
+			}
+			pointsWriter := &mock.PointsWriter{}
+
+			b := &APIBackend{
+				HTTPErrorHandler:    DefaultErrorHandler,
+				Logger:              zaptest.NewLogger(t),
+				OrganizationService: orgs,
+				BucketService:       buckets,
+				PointsWriter:        pointsWriter,
+				WriteEventRecorder:  &metric.NopEventRecorder{},
+			}
+			writeHandler := NewWriteHandler(zaptest.NewLogger(t), NewWriteBackend(zaptest.NewLogger(t), b))
+			handler := httpmock.NewAuthMiddlewareHandler(writeHandler, bucketWritePermission(orgID, bucketID))
+
+			body, contentType := newBody(t, tt.parts...)
+			r := httptest.NewRequest("POST", "http://localhost:8086/api/v2/write", body)
+			r.Header.Set("Content-Type", contentType)
+
+			params := r.URL.Query()
+			params.Set("org", orgID)
+			params.Set("bucket", bucketID)
+			r.URL.RawQuery = params.Encode()
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			require.Equal(t, tt.code, w.Code)
+			require.Equal(t, tt.body, w.Body.String())
+
+			if tt.code != 204 {
+				return
+			}
+			require.Len(t, pointsWriter.Points, 2)
+			for _, pt := range pointsWriter.Points {
+				require.Equal(t, "obc cpu=42u 1000000000", pt.String())
+			}
+		})
+	}
+}
+
 var DefaultErrorHandler = kithttp.ErrorHandler(0)
 
 func bucketWritePermission(org, bucket string) *influxdb.Authorization {
@@ -0,0 +1,133 @@
+// Package queryutil holds the helpers shared by the features executing Flux
+// queries on behalf of a user, such as dashboard snapshots, notebook runs and
+// variable values, and by the services paging through items they filter in
+// memory.
+package queryutil
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/jsonweb"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+)
+
+// PointsPerWindow is the number of points per series the window period of
+// TimeRangeExtern yields, like the window period of the UI.
+const PointsPerWindow = 360
+
+// Authorization returns the authorization to execute queries of an
+// organization with, derived from the authorizer of ctx as for the queries of
+// the query endpoint.
+func Authorization(ctx context.Context, orgID platform.ID) (*influxdb.Authorization, error) {
+	a, err := icontext.GetAuthorizer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	switch a := a.(type) {
+	case *influxdb.Authorization:
+		return a, nil
+	case *influxdb.Session:
+		return a.EphemeralAuth(orgID), nil
+	case *jsonweb.Token:
+		return a.EphemeralAuth(orgID), nil
+	default:
+		return nil, influxdb.ErrAuthorizerNotSupported
+	}
+}
+
+// TimeRangeExtern returns the extern declaring the v option the UI declares
+// for the queries of dashboards and notebooks, with the time range from start
+// to stop and a window period of PointsPerWindow points.
+func TimeRangeExtern(start, stop time.Time) *ast.File {
+	window := stop.Sub(start) / PointsPerWindow / time.Millisecond
+	if window < 1 {
+		window = 1
+	}
+
+	return &ast.File{
+		Body: []ast.Statement{
+			&ast.OptionStatement{
+				Assignment: &ast.VariableAssignment{
+					ID: &ast.Identifier{Name: "v"},
+					Init: &ast.ObjectExpression{
+						Properties: []*ast.Property{
+							{
+								Key:   &ast.Identifier{Name: "timeRangeStart"},
+								Value: &ast.DateTimeLiteral{Value: start},
+							},
+							{
+								Key:   &ast.Identifier{Name: "timeRangeStop"},
+								Value: &ast.DateTimeLiteral{Value: stop},
+							},
+							{
+								Key: &ast.Identifier{Name: "windowPeriod"},
+								Value: &ast.DurationLiteral{
+									Values: []ast.Duration{{Magnitude: int64(window), Unit: ast.MillisecondUnit}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// LimitedBuffer is a buffer failing writes exceeding its maximum size, which
+// collects the result of a query. The buffer is not embedded so that its
+// other write methods cannot bypass the limit.
+type LimitedBuffer struct {
+	buf bytes.Buffer
+	max int
+}
+
+// NewLimitedBuffer returns a buffer of at most max bytes, or of any size if
+// max is zero.
+func NewLimitedBuffer(max int) *LimitedBuffer {
+	return &LimitedBuffer{max: max}
+}
+
+func (b *LimitedBuffer) Write(p []byte) (int, error) {
+	if b.max > 0 && b.buf.Len()+len(p) > b.max {
+		return 0, fmt.Errorf("result exceeds the maximum size of %d bytes", b.max)
+	}
+	return b.buf.Write(p)
+}
+
+func (b *LimitedBuffer) String() string {
+	return b.buf.String()
+}
+
+// PageBounds returns the bounds of the page selected by opts of n items. The
+// page starts after the item opts.After if id returns the IDs of the items,
+// which are ordered by ID; opts.After is ignored if id is nil.
+func PageBounds(n int, opts influxdb.FindOptions, id func(i int) platform.ID) (int, int) {
+	lo := 0
+	if opts.After != nil && id != nil {
+		for lo < n && !isAfter(id(lo), *opts.After, opts.Descending) {
+			lo++
+		}
+	}
+	lo += opts.Offset
+	if lo > n {
+		lo = n
+	}
+	hi := n
+	if opts.Limit > 0 && lo+opts.Limit < hi {
+		hi = lo + opts.Limit
+	}
+	return lo, hi
+}
+
+func isAfter(id, after platform.ID, descending bool) bool {
+	if descending {
+		return id < after
+	}
+	return id > after
+}
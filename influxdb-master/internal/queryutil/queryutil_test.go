@@ -0,0 +1,68 @@
+package queryutil
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+)
+
+func TestPageBounds(t *testing.T) {
+	ids := []platform.ID{1, 2, 3, 4, 5}
+	id := func(i int) platform.ID { return ids[i] }
+	after := platform.ID(2)
+
+	for _, tt := range []struct {
+		name   string
+		opts   influxdb.FindOptions
+		id     func(i int) platform.ID
+		lo, hi int
+	}{
+		{name: "all", lo: 0, hi: 5},
+		{name: "offset and limit", opts: influxdb.FindOptions{Offset: 1, Limit: 2}, lo: 1, hi: 3},
+		{name: "offset past the end", opts: influxdb.FindOptions{Offset: 10, Limit: 2}, lo: 5, hi: 5},
+		{name: "after", opts: influxdb.FindOptions{After: &after, Limit: 2}, id: id, lo: 2, hi: 4},
+		{name: "after without ids", opts: influxdb.FindOptions{After: &after, Limit: 2}, lo: 0, hi: 2},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			lo, hi := PageBounds(len(ids), tt.opts, tt.id)
+			if lo != tt.lo || hi != tt.hi {
+				t.Fatalf("got [%d, %d), exp [%d, %d)", lo, hi, tt.lo, tt.hi)
+			}
+		})
+	}
+}
+
+func TestTimeRangeExtern(t *testing.T) {
+	stop := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		rng    time.Duration
+		window int64
+	}{
+		{rng: time.Hour, window: 10000},
+		{rng: time.Millisecond, window: 1},
+	} {
+		f := TimeRangeExtern(stop.Add(-tt.rng), stop)
+		props := f.Body[0].(*ast.OptionStatement).Assignment.(*ast.VariableAssignment).Init.(*ast.ObjectExpression).Properties
+		window := props[2].Value.(*ast.DurationLiteral).Values[0]
+		if window.Magnitude != tt.window || window.Unit != ast.MillisecondUnit {
+			t.Errorf("range %s: got a window period of %d%s, exp %dms", tt.rng, window.Magnitude, window.Unit, tt.window)
+		}
+	}
+}
+
+func TestLimitedBuffer(t *testing.T) {
+	b := NewLimitedBuffer(4)
+	if _, err := b.Write([]byte("abc")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Write([]byte("de")); err == nil || !strings.Contains(err.Error(), "maximum size of 4 bytes") {
+		t.Fatalf("expected the write to exceed the maximum, got %v", err)
+	}
+	if got := b.String(); got != "abc" {
+		t.Fatalf("got %q, exp %q", got, "abc")
+	}
+}
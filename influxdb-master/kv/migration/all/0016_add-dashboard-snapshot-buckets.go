@@ -0,0 +1,18 @@
+package all
+
+import "github.com/influxdata/influxdb/v2/kv/migration"
+
+var (
+	dashboardSnapshotBucket           = []byte("dashboardsnapshotsv1")
+	dashboardSnapshotShareTokenBucket = []byte("dashboardsnapshotsharetokensv1")
+	dashboardReportBucket             = []byte("dashboardreportsv1")
+)
+
+// Migration0016_AddDashboardSnapshotBuckets creates the buckets necessary for
+// the dashboard snapshot service to operate.
+var Migration0016_AddDashboardSnapshotBuckets = migration.CreateBuckets(
+	"create dashboard snapshot buckets",
+	dashboardSnapshotBucket,
+	dashboardSnapshotShareTokenBucket,
+	dashboardReportBucket,
+)
@@ -0,0 +1,16 @@
+package all
+
+import "github.com/influxdata/influxdb/v2/kv/migration"
+
+var (
+	annotationBucket             = []byte("annotationsv1")
+	annotationByOrgAndTimeBucket = []byte("annotationsbyorgtimev1")
+)
+
+// Migration0017_AddAnnotationBuckets creates the buckets necessary for the
+// annotation service to operate.
+var Migration0017_AddAnnotationBuckets = migration.CreateBuckets(
+	"create annotation buckets",
+	annotationBucket,
+	annotationByOrgAndTimeBucket,
+)
@@ -0,0 +1,22 @@
+package all
+
+import "github.com/influxdata/influxdb/v2/kv/migration"
+
+var (
+	notebookBucket          = []byte("notebooksv1")
+	notebookOrgIndexBucket  = []byte("notebooksbyorgindexv1")
+	notebookRevisionBucket  = []byte("notebookrevisionsv1")
+	notebookRunBucket       = []byte("notebookrunsv1")
+	notebookRunOutputBucket = []byte("notebookrunoutputsv1")
+)
+
+// Migration0018_AddNotebookBuckets creates the buckets necessary for the
+// notebook service to operate.
+var Migration0018_AddNotebookBuckets = migration.CreateBuckets(
+	"create notebook buckets",
+	notebookBucket,
+	notebookOrgIndexBucket,
+	notebookRevisionBucket,
+	notebookRunBucket,
+	notebookRunOutputBucket,
+)
@@ -37,5 +37,11 @@ var Migrations = [...]migration.Spec{
 	Migration0014_ReindexDBRPs,
 	// record shard group durations in bucket metadata
 	Migration0015_RecordShardGroupDurationsInBucketMetadata,
+	// add dashboard snapshot buckets
+	Migration0016_AddDashboardSnapshotBuckets,
+	// add annotation buckets
+	Migration0017_AddAnnotationBuckets,
+	// add notebook buckets
+	Migration0018_AddNotebookBuckets,
 	// {{ do_not_edit . }}
 }
@@ -0,0 +1,206 @@
+package listener
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Protocols of listeners.
+const (
+	UDP      = "udp"
+	MQTT     = "mqtt"
+	MQTTS    = "mqtts"
+	Graphite = "graphite"
+	OpenTSDB = "opentsdb"
+)
+
+// Defaults of the options of listeners.
+const (
+	DefaultBatchSize    = 5000
+	DefaultBatchTimeout = time.Second
+	DefaultPrecision    = "ns"
+	DefaultMQTTQoS      = 0
+)
+
+// Config is the configuration of a listener, parsed from a spec of the form
+//
+//	<protocol>://[<user>:<password>@]<address>?org=<org>&bucket=<bucket>&token=<token>[&<option>=<value>...]
+//
+// For udp, graphite and opentsdb the address is the address to listen on.
+// For mqtt and mqtts the address is that of the broker, and the user and
+// password are the credentials to connect to it with. As the options are URL
+// query parameters, the # and + wildcards of MQTT topics are escaped as %23
+// and %2B.
+type Config struct {
+	// Name identifies the listener in logs and metrics. It defaults to the
+	// protocol and address of the listener.
+	Name     string
+	Protocol string
+	Address  string
+
+	// Org and Bucket are the names of the bucket points are written to, with
+	// the authorization of Token.
+	Org    string
+	Bucket string
+	Token  string
+
+	// BatchSize is the number of points written at once, and BatchTimeout
+	// the longest points are held before they are written.
+	BatchSize    int
+	BatchTimeout time.Duration
+
+	// Precision is the precision of the timestamps of line protocol, for the
+	// udp and mqtt protocols.
+	Precision string
+
+	// MQTT options.
+	Topic    string
+	QoS      byte
+	ClientID string
+	Username string
+	Password string
+	// TopicTag is the tag key points are tagged with the topic of their
+	// message by, if set.
+	TopicTag string
+
+	// Graphite options.
+	Separator string
+	Templates []string
+}
+
+// ParseConfigs parses listener specs.
+func ParseConfigs(specs []string) ([]Config, error) {
+	configs := make([]Config, 0, len(specs))
+	names := make(map[string]struct{}, len(specs))
+	for _, spec := range specs {
+		c, err := ParseConfig(spec)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := names[c.Name]; ok {
+			return nil, fmt.Errorf("listener %q is configured more than once", c.Name)
+		}
+		names[c.Name] = struct{}{}
+		configs = append(configs, c)
+	}
+	return configs, nil
+}
+
+// ParseConfig parses a listener spec.
+func ParseConfig(spec string) (Config, error) {
+	u, err := url.Parse(spec)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid listener %q: %v", spec, err)
+	}
+
+	c := Config{
+		Protocol:     u.Scheme,
+		Address:      u.Host,
+		BatchSize:    DefaultBatchSize,
+		BatchTimeout: DefaultBatchTimeout,
+		Precision:    DefaultPrecision,
+		QoS:          DefaultMQTTQoS,
+		Separator:    ".",
+	}
+	invalid := func(format string, args ...interface{}) (Config, error) {
+		return Config{}, fmt.Errorf("invalid %s listener %q: %s", c.Protocol, c.Address, fmt.Sprintf(format, args...))
+	}
+
+	switch c.Protocol {
+	case UDP, Graphite, OpenTSDB:
+		if u.User != nil {
+			return invalid("credentials are only supported by mqtt")
+		}
+	case MQTT, MQTTS:
+		if u.User != nil {
+			c.Username = u.User.Username()
+			c.Password, _ = u.User.Password()
+		}
+	default:
+		return Config{}, fmt.Errorf("invalid listener %q: unknown protocol %q, expected %s, %s, %s, %s or %s", spec, c.Protocol, UDP, MQTT, MQTTS, Graphite, OpenTSDB)
+	}
+	if c.Address == "" {
+		return invalid("address is required")
+	}
+
+	q := u.Query()
+	c.Name = q.Get("name")
+	if c.Name == "" {
+		c.Name = c.Protocol + "://" + c.Address
+	}
+	c.Org, c.Bucket, c.Token = q.Get("org"), q.Get("bucket"), q.Get("token")
+	if c.Org == "" || c.Bucket == "" || c.Token == "" {
+		return invalid("org, bucket and token are required")
+	}
+
+	for key, values := range q {
+		v := values[len(values)-1]
+		switch key {
+		case "name", "org", "bucket", "token":
+		case "batch-size":
+			if c.BatchSize, err = strconv.Atoi(v); err != nil || c.BatchSize <= 0 {
+				return invalid("batch-size must be a positive integer")
+			}
+		case "batch-timeout":
+			if c.BatchTimeout, err = time.ParseDuration(v); err != nil || c.BatchTimeout <= 0 {
+				return invalid("batch-timeout must be a positive duration")
+			}
+		case "precision":
+			switch v {
+			case "ns", "us", "ms", "s":
+				c.Precision = v
+			default:
+				return invalid("precision must be one of ns, us, ms or s")
+			}
+		case "topic", "qos", "client-id", "topic-tag":
+			if c.Protocol != MQTT && c.Protocol != MQTTS {
+				return invalid("%s is only supported by mqtt", key)
+			}
+			switch key {
+			case "topic":
+				c.Topic = v
+			case "qos":
+				qos, err := strconv.Atoi(v)
+				if err != nil || qos < 0 || qos > 1 {
+					return invalid("qos must be 0 or 1")
+				}
+				c.QoS = byte(qos)
+			case "client-id":
+				c.ClientID = v
+			case "topic-tag":
+				c.TopicTag = v
+			}
+		case "separator", "template":
+			if c.Protocol != Graphite {
+				return invalid("%s is only supported by graphite", key)
+			}
+			if key == "separator" {
+				c.Separator = v
+			} else {
+				c.Templates = values
+			}
+		default:
+			return invalid("unknown option %q", key)
+		}
+	}
+
+	switch c.Protocol {
+	case MQTT, MQTTS:
+		if c.Topic == "" {
+			return invalid("topic is required")
+		}
+		if c.ClientID == "" {
+			h := fnv.New32a()
+			h.Write([]byte(c.Name))
+			c.ClientID = fmt.Sprintf("influxd-%08x", h.Sum32())
+		}
+	case Graphite:
+		if _, err := newGraphiteParser(c.Separator, c.Templates); err != nil {
+			return invalid("%v", err)
+		}
+	}
+	return c, nil
+}
@@ -0,0 +1,190 @@
+package listener
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/models"
+)
+
+// graphiteParser parses the lines of the Graphite plaintext protocol,
+//
+//	<path> <value> [<timestamp>]
+//
+// mapping the parts of the path to the measurement, field and tags of a
+// point with the first template that matches it. A template is of the form
+//
+//	[<filter> ]<template>[ <tag>=<value>[,<tag>=<value>...]]
+//
+// The filter is a path, of which a * part matches any part. Each part of the
+// template names what the matching part of the path is: "measurement" or
+// "field", which are joined by the separator if there are many, a tag key,
+// or nothing if empty. A last part of "measurement*" or "field*" takes the
+// rest of the path. The field defaults to "value", and the template to
+// "measurement*", which makes the whole path the measurement.
+type graphiteParser struct {
+	separator string
+	templates []graphiteTemplate
+}
+
+type graphiteTemplate struct {
+	filter []string
+	parts  []string
+	tags   models.Tags
+}
+
+func newGraphiteParser(separator string, templates []string) (*graphiteParser, error) {
+	if separator == "" {
+		return nil, fmt.Errorf("graphite separator must not be empty")
+	}
+
+	p := &graphiteParser{separator: separator}
+	for _, s := range templates {
+		t, err := parseGraphiteTemplate(s)
+		if err != nil {
+			return nil, err
+		}
+		p.templates = append(p.templates, t)
+	}
+	p.templates = append(p.templates, graphiteTemplate{parts: []string{"measurement*"}})
+	return p, nil
+}
+
+func parseGraphiteTemplate(s string) (graphiteTemplate, error) {
+	var t graphiteTemplate
+	fields := strings.Fields(s)
+	if n := len(fields); n > 1 && strings.Contains(fields[n-1], "=") {
+		for _, kv := range strings.Split(fields[n-1], ",") {
+			i := strings.IndexByte(kv, '=')
+			if i <= 0 || i == len(kv)-1 {
+				return t, fmt.Errorf("invalid tag %q of graphite template %q", kv, s)
+			}
+			t.tags = append(t.tags, models.NewTag([]byte(kv[:i]), []byte(kv[i+1:])))
+		}
+		fields = fields[:n-1]
+	}
+
+	switch len(fields) {
+	case 1:
+		t.parts = strings.Split(fields[0], ".")
+	case 2:
+		t.filter = strings.Split(fields[0], ".")
+		t.parts = strings.Split(fields[1], ".")
+	default:
+		return t, fmt.Errorf("invalid graphite template %q", s)
+	}
+
+	var hasMeasurement bool
+	for i, part := range t.parts {
+		switch part {
+		case "measurement*", "field*":
+			if i != len(t.parts)-1 {
+				return t, fmt.Errorf("%s must be the last part of graphite template %q", part, s)
+			}
+			hasMeasurement = hasMeasurement || part == "measurement*"
+		case "measurement":
+			hasMeasurement = true
+		}
+	}
+	if !hasMeasurement {
+		return t, fmt.Errorf("graphite template %q has no measurement", s)
+	}
+	return t, nil
+}
+
+// matches returns true if the filter of the template matches the parts of a
+// path.
+func (t *graphiteTemplate) matches(parts []string) bool {
+	if len(t.filter) > len(parts) {
+		return false
+	}
+	for i, f := range t.filter {
+		if f != "*" && f != parts[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// apply returns the measurement, field and tags of the parts of a path.
+func (t *graphiteTemplate) apply(parts []string, separator string) (string, string, map[string]string) {
+	var (
+		measurement, field []string
+		tags               = make(map[string]string, len(t.tags))
+	)
+	for _, tag := range t.tags {
+		tags[string(tag.Key)] = string(tag.Value)
+	}
+
+	for i, part := range t.parts {
+		if i >= len(parts) {
+			break
+		}
+		switch part {
+		case "":
+		case "measurement":
+			measurement = append(measurement, parts[i])
+		case "field":
+			field = append(field, parts[i])
+		case "measurement*":
+			measurement = append(measurement, parts[i:]...)
+		case "field*":
+			field = append(field, parts[i:]...)
+		default:
+			if v, ok := tags[part]; ok && v != "" {
+				tags[part] = v + separator + parts[i]
+			} else {
+				tags[part] = parts[i]
+			}
+		}
+	}
+
+	f := strings.Join(field, separator)
+	if f == "" {
+		f = "value"
+	}
+	return strings.Join(measurement, separator), f, tags
+}
+
+func (p *graphiteParser) parse(line string, now time.Time) (models.Point, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil, nil
+	} else if len(fields) != 2 && len(fields) != 3 {
+		return nil, fmt.Errorf("invalid graphite line %q", line)
+	}
+
+	value, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value of graphite line %q", line)
+	} else if math.IsNaN(value) || math.IsInf(value, 0) {
+		return nil, nil // unsupported by storage
+	}
+
+	ts := now
+	if len(fields) == 3 {
+		sec, err := strconv.ParseFloat(fields[2], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timestamp of graphite line %q", line)
+		}
+		if sec >= 0 {
+			ts = time.Unix(0, int64(sec*float64(time.Second))).UTC()
+		}
+	}
+
+	parts := strings.Split(fields[0], ".")
+	for _, t := range p.templates {
+		if !t.matches(parts) {
+			continue
+		}
+		measurement, field, tags := t.apply(parts, p.separator)
+		if measurement == "" {
+			return nil, fmt.Errorf("no measurement in graphite path %q", fields[0])
+		}
+		return models.NewPoint(measurement, models.NewTags(tags), models.Fields{field: value}, ts)
+	}
+	return nil, nil // unreachable, as the default template matches any path
+}
@@ -0,0 +1,130 @@
+package listener
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGraphiteParser(t *testing.T) {
+	now := time.Unix(100, 0).UTC()
+	tests := []struct {
+		name      string
+		separator string
+		templates []string
+		line      string
+		exp       string
+		err       bool
+	}{
+		{
+			name: "default template",
+			line: "servers.gs1.cpu.load 0.5 1600000000",
+			exp:  "servers.gs1.cpu.load value=0.5 1600000000000000000",
+		},
+		{
+			name: "no timestamp",
+			line: "cpu 1",
+			exp:  "cpu value=1 100000000000",
+		},
+		{
+			name:      "template",
+			templates: []string{"servers.* .host.measurement.field*"},
+			line:      "servers.gs1.cpu.load.avg 0.5 1600000000",
+			exp:       "cpu,host=gs1 load.avg=0.5 1600000000000000000",
+		},
+		{
+			name:      "template with tags and separator",
+			separator: "_",
+			templates: []string{"region.measurement* site=ground"},
+			line:      "eu.antenna.temp 21 1600000000",
+			exp:       "antenna_temp,region=eu,site=ground value=21 1600000000000000000",
+		},
+		{
+			name:      "unmatched filter",
+			templates: []string{"servers.* .host.measurement*"},
+			line:      "other.gs1.cpu 1 1600000000",
+			exp:       "other.gs1.cpu value=1 1600000000000000000",
+		},
+		{
+			name: "empty line",
+			line: "  ",
+		},
+		{
+			name: "invalid value",
+			line: "cpu one 1600000000",
+			err:  true,
+		},
+		{
+			name: "invalid line",
+			line: "cpu",
+			err:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			separator := tt.separator
+			if separator == "" {
+				separator = "."
+			}
+			p, err := newGraphiteParser(separator, tt.templates)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			pt, err := p.parse(tt.line, now)
+			if tt.err {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			var got string
+			if pt != nil {
+				got = pt.String()
+			}
+			if got != tt.exp {
+				t.Fatalf("unexpected point:\ngot %s\nexp %s", got, tt.exp)
+			}
+		})
+	}
+}
+
+func TestParseOpenTSDB(t *testing.T) {
+	tests := []struct {
+		line string
+		exp  string
+		err  bool
+	}{
+		{line: "put sys.cpu 1600000000 0.5 host=gs1 cpu=0", exp: "sys.cpu,cpu=0,host=gs1 value=0.5 1600000000000000000"},
+		{line: "put sys.cpu 1600000000123 1", exp: "sys.cpu value=1 1600000000123000000"},
+		{line: "version"},
+		{line: "put sys.cpu 1600000000", err: true},
+		{line: "put sys.cpu now 1", err: true},
+		{line: "put sys.cpu 1600000000 1 host", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			pt, err := parseOpenTSDB(tt.line, time.Time{})
+			if tt.err {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			var got string
+			if pt != nil {
+				got = pt.String()
+			}
+			if got != tt.exp {
+				t.Fatalf("unexpected point:\ngot %s\nexp %s", got, tt.exp)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+// Package listener implements listeners that write the points they receive
+// over UDP line protocol, MQTT, Graphite or OpenTSDB telnet to a bucket.
+package listener
+
+import (
+	"fmt"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// Listener receives points and passes them to a batcher.
+type Listener interface {
+	// Open starts receiving points.
+	Open() error
+	// Close stops receiving points.
+	Close() error
+	// Addr returns the address the listener listens on, or the address of
+	// the broker it is subscribed to.
+	Addr() string
+}
+
+// Service runs the listeners of a set of configs.
+type Service struct {
+	log     *zap.Logger
+	metrics *metrics
+
+	configs   []Config
+	listeners []Listener
+	batchers  []*batcher
+}
+
+// NewService returns a service that runs listeners of configs, writing to
+// writer with the authorizations of their tokens.
+func NewService(
+	log *zap.Logger,
+	configs []Config,
+	writer storage.PointsWriter,
+	auths influxdb.AuthorizationService,
+	orgs influxdb.OrganizationService,
+	buckets influxdb.BucketService,
+) *Service {
+	s := &Service{
+		log:     log,
+		metrics: newMetrics(),
+		configs: configs,
+	}
+	for _, c := range configs {
+		m := s.metrics.listener(c)
+		t := &target{config: c, auths: auths, orgs: orgs, buckets: buckets}
+		b := newBatcher(c, t, writer, m, log.With(zap.String("listener", c.Name)))
+		s.batchers = append(s.batchers, b)
+	}
+	return s
+}
+
+// Open starts the listeners.
+func (s *Service) Open() error {
+	for i, c := range s.configs {
+		b := s.batchers[i]
+		log := b.log
+
+		var l Listener
+		switch c.Protocol {
+		case UDP:
+			l = newUDPListener(c, b, log)
+		case MQTT, MQTTS:
+			l = newMQTTListener(c, b, log)
+		case Graphite:
+			p, err := newGraphiteParser(c.Separator, c.Templates)
+			if err != nil {
+				s.Close()
+				return err
+			}
+			l = newTCPListener(c, b, log, p.parse)
+		case OpenTSDB:
+			l = newTCPListener(c, b, log, parseOpenTSDB)
+		default:
+			s.Close()
+			return fmt.Errorf("unknown listener protocol %q", c.Protocol)
+		}
+
+		go b.run()
+		if err := l.Open(); err != nil {
+			b.close()
+			s.Close()
+			return fmt.Errorf("unable to open listener %q: %v", c.Name, err)
+		}
+		s.listeners = append(s.listeners, l)
+		log.Info("Opened listener", zap.String("protocol", c.Protocol), zap.String("addr", l.Addr()))
+	}
+	return nil
+}
+
+// Close stops the listeners, and writes the points they hold.
+func (s *Service) Close() error {
+	var firstErr error
+	for i, l := range s.listeners {
+		if err := l.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		s.batchers[i].close()
+	}
+	s.listeners = nil
+	return firstErr
+}
+
+// Listeners returns the open listeners, in the order of their configs.
+func (s *Service) Listeners() []Listener {
+	return s.listeners
+}
+
+// PrometheusCollectors returns the metrics of the listeners.
+func (s *Service) PrometheusCollectors() []prometheus.Collector {
+	return s.metrics.PrometheusCollectors()
+}
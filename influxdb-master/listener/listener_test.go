@@ -0,0 +1,361 @@
+package listener
+
+import (
+	"context"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eclipse/paho.mqtt.golang/packets"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/models"
+	"go.uber.org/zap/zaptest"
+)
+
+var (
+	orgID    = platform.ID(0x1000)
+	bucketID = platform.ID(0x2000)
+)
+
+// newTestService returns an open service of the listener spec, writing to
+// bucketID of orgID with a token that has the permissions perms.
+func newTestService(t *testing.T, spec string, perms []influxdb.Permission) (*Service, *testWriter) {
+	t.Helper()
+
+	c, err := ParseConfig(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	auths := &mock.AuthorizationService{
+		FindAuthorizationByTokenFn: func(_ context.Context, token string) (*influxdb.Authorization, error) {
+			if token != "secret" {
+				return nil, &errors.Error{Code: errors.ENotFound, Msg: "authorization not found"}
+			}
+			return &influxdb.Authorization{Status: influxdb.Active, OrgID: orgID, Permissions: perms}, nil
+		},
+	}
+	orgs := &mock.OrganizationService{
+		FindOrganizationF: func(_ context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+			return &influxdb.Organization{ID: orgID, Name: *filter.Name}, nil
+		},
+	}
+	buckets := &mock.BucketService{
+		FindBucketFn: func(_ context.Context, filter influxdb.BucketFilter) (*influxdb.Bucket, error) {
+			return &influxdb.Bucket{ID: bucketID, OrgID: *filter.OrganizationID, Name: *filter.Name}, nil
+		},
+	}
+
+	w := &testWriter{}
+	s := NewService(zaptest.NewLogger(t), []Config{c}, w, auths, orgs, buckets)
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s, w
+}
+
+func writePermissions(t *testing.T) []influxdb.Permission {
+	t.Helper()
+	p, err := influxdb.NewPermissionAtID(bucketID, influxdb.WriteAction, influxdb.BucketsResourceType, orgID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []influxdb.Permission{*p}
+}
+
+// testWriter holds the points written to bucketID of orgID.
+type testWriter struct {
+	mu     sync.Mutex
+	writes int
+	points []string
+}
+
+func (w *testWriter) WritePoints(_ context.Context, org, bucket platform.ID, points []models.Point) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if org != orgID || bucket != bucketID {
+		return &errors.Error{Code: errors.ENotFound, Msg: "bucket not found"}
+	}
+	w.writes++
+	for _, p := range points {
+		w.points = append(w.points, p.String())
+	}
+	return nil
+}
+
+// waitPoints waits for the writer to hold n points, and returns them sorted.
+func (w *testWriter) waitPoints(t *testing.T, n int) []string {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		w.mu.Lock()
+		got := append([]string(nil), w.points...)
+		w.mu.Unlock()
+
+		if len(got) >= n {
+			sort.Strings(got)
+			return got
+		} else if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %d points, got %d: %v", n, len(got), got)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func assertPoints(t *testing.T, got, exp []string) {
+	t.Helper()
+	if strings.Join(got, "\n") != strings.Join(exp, "\n") {
+		t.Fatalf("unexpected points:\ngot %v\nexp %v", got, exp)
+	}
+}
+
+func TestService_UDP(t *testing.T) {
+	s, w := newTestService(t, "udp://127.0.0.1:0?org=ground&bucket=telemetry&token=secret&precision=s&batch-timeout=10ms", writePermissions(t))
+
+	conn, err := net.Dial("udp", s.Listeners()[0].Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte("cpu,host=gs1 value=1 1600000000\ncpu,host=gs2 value=2 1600000000\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	assertPoints(t, w.waitPoints(t, 2), []string{
+		"cpu,host=gs1 value=1 1600000000000000000",
+		"cpu,host=gs2 value=2 1600000000000000000",
+	})
+}
+
+func TestService_Graphite(t *testing.T) {
+	s, w := newTestService(t, "graphite://127.0.0.1:0?org=ground&bucket=telemetry&token=secret&batch-timeout=10ms&template=servers.*+.host.measurement*", writePermissions(t))
+
+	conn, err := net.Dial("tcp", s.Listeners()[0].Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte("servers.gs1.cpu 1 1600000000\nbad\nservers.gs2.cpu 2 1600000000\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	assertPoints(t, w.waitPoints(t, 2), []string{
+		"cpu,host=gs1 value=1 1600000000000000000",
+		"cpu,host=gs2 value=2 1600000000000000000",
+	})
+}
+
+func TestService_OpenTSDB(t *testing.T) {
+	s, w := newTestService(t, "opentsdb://127.0.0.1:0?org=ground&bucket=telemetry&token=secret&batch-timeout=10ms", writePermissions(t))
+
+	conn, err := net.Dial("tcp", s.Listeners()[0].Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte("put sys.cpu 1600000000 0.5 host=gs1\nversion\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	assertPoints(t, w.waitPoints(t, 1), []string{
+		"sys.cpu,host=gs1 value=0.5 1600000000000000000",
+	})
+}
+
+func TestService_Unauthorized(t *testing.T) {
+	s, w := newTestService(t, "udp://127.0.0.1:0?org=ground&bucket=telemetry&token=secret&batch-timeout=10ms", nil)
+
+	conn, err := net.Dial("udp", s.Listeners()[0].Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte("cpu value=1\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Closing writes any points held by the batcher.
+	time.Sleep(50 * time.Millisecond)
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if n := w.writes; n != 0 {
+		t.Fatalf("unexpected writes: %d", n)
+	}
+}
+
+func TestService_MQTT(t *testing.T) {
+	b := newTestBroker(t)
+	s, w := newTestService(t, "mqtt://"+b.addr()+"?org=ground&bucket=telemetry&token=secret&topic=telemetry/%23&topic-tag=topic&precision=s&batch-timeout=10ms", writePermissions(t))
+	if got, exp := s.Listeners()[0].Addr(), b.addr(); got != exp {
+		t.Fatalf("unexpected addr: got=%s exp=%s", got, exp)
+	}
+
+	b.waitSubscribed(t)
+	b.publish(t, "telemetry/gs1", "antenna azimuth=120 1600000000\n")
+	b.publish(t, "other/gs1", "antenna azimuth=180 1600000000\n")
+	b.publish(t, "telemetry/gs2", "antenna azimuth=240 1600000000\n")
+
+	assertPoints(t, w.waitPoints(t, 2), []string{
+		"antenna,topic=telemetry/gs1 azimuth=120 1600000000000000000",
+		"antenna,topic=telemetry/gs2 azimuth=240 1600000000000000000",
+	})
+}
+
+func TestService_AddWhileClosing(t *testing.T) {
+	s, _ := newTestService(t, "udp://127.0.0.1:0?org=ground&bucket=telemetry&token=secret", writePermissions(t))
+	b := s.batchers[0]
+
+	points, err := models.ParsePointsString("cpu value=1 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A listener may pass on points while the service closes, and after.
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				b.add(points)
+			}
+		}()
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+	b.add(points)
+}
+
+// testBroker is a minimal MQTT broker, which accepts any client and
+// publishes messages to the clients subscribed to a matching topic.
+type testBroker struct {
+	ln net.Listener
+
+	mu         sync.Mutex
+	subs       map[net.Conn][]string
+	subscribed chan struct{}
+}
+
+func newTestBroker(t *testing.T) *testBroker {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &testBroker{
+		ln:         ln,
+		subs:       make(map[net.Conn][]string),
+		subscribed: make(chan struct{}, 1),
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go b.serve(conn)
+		}
+	}()
+	return b
+}
+
+func (b *testBroker) addr() string {
+	return b.ln.Addr().String()
+}
+
+func (b *testBroker) serve(conn net.Conn) {
+	defer func() {
+		b.mu.Lock()
+		delete(b.subs, conn)
+		b.mu.Unlock()
+		conn.Close()
+	}()
+
+	for {
+		cp, err := packets.ReadPacket(conn)
+		if err != nil {
+			return
+		}
+
+		var reply packets.ControlPacket
+		switch p := cp.(type) {
+		case *packets.ConnectPacket:
+			reply = packets.NewControlPacket(packets.Connack)
+		case *packets.SubscribePacket:
+			ack := packets.NewControlPacket(packets.Suback).(*packets.SubackPacket)
+			ack.MessageID = p.MessageID
+			ack.ReturnCodes = p.Qoss
+			reply = ack
+
+			b.mu.Lock()
+			b.subs[conn] = append(b.subs[conn], p.Topics...)
+			b.mu.Unlock()
+		case *packets.UnsubscribePacket:
+			ack := packets.NewControlPacket(packets.Unsuback).(*packets.UnsubackPacket)
+			ack.MessageID = p.MessageID
+			reply = ack
+		case *packets.PingreqPacket:
+			reply = packets.NewControlPacket(packets.Pingresp)
+		case *packets.DisconnectPacket:
+			return
+		}
+
+		if reply != nil {
+			b.mu.Lock()
+			err := reply.Write(conn)
+			b.mu.Unlock()
+			if err != nil {
+				return
+			}
+		}
+		if _, ok := cp.(*packets.SubscribePacket); ok {
+			select {
+			case b.subscribed <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+func (b *testBroker) waitSubscribed(t *testing.T) {
+	t.Helper()
+	select {
+	case <-b.subscribed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for subscription")
+	}
+}
+
+// publish writes a message to the clients subscribed to the topic, of which
+// only a trailing # wildcard is supported.
+func (b *testBroker) publish(t *testing.T, topic, payload string) {
+	t.Helper()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for conn, filters := range b.subs {
+		for _, f := range filters {
+			if f != topic && !(strings.HasSuffix(f, "#") && strings.HasPrefix(topic, strings.TrimSuffix(f, "#"))) {
+				continue
+			}
+			p := packets.NewControlPacket(packets.Publish).(*packets.PublishPacket)
+			p.TopicName = topic
+			p.Payload = []byte(payload)
+			if err := p.Write(conn); err != nil {
+				t.Fatal(err)
+			}
+			break
+		}
+	}
+}
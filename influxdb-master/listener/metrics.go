@@ -0,0 +1,88 @@
+package listener
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// metrics are the metrics of listeners, labelled by listener name and
+// protocol.
+type metrics struct {
+	pointsReceived *prometheus.CounterVec
+	pointsWritten  *prometheus.CounterVec
+	parseErrors    *prometheus.CounterVec
+	writeErrors    *prometheus.CounterVec
+	batchesWritten *prometheus.CounterVec
+	connections    *prometheus.GaugeVec
+}
+
+func newMetrics() *metrics {
+	const namespace = "listener"
+	labels := []string{"listener", "protocol"}
+
+	return &metrics{
+		pointsReceived: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "points_received_total",
+			Help:      "Number of points received by a listener.",
+		}, labels),
+		pointsWritten: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "points_written_total",
+			Help:      "Number of points written to storage by a listener.",
+		}, labels),
+		parseErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "parse_errors_total",
+			Help:      "Number of lines or messages a listener failed to parse.",
+		}, labels),
+		writeErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "write_errors_total",
+			Help:      "Number of batches of points a listener failed to write to storage.",
+		}, labels),
+		batchesWritten: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "batches_written_total",
+			Help:      "Number of batches of points written to storage by a listener.",
+		}, labels),
+		connections: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "connections",
+			Help:      "Number of open connections of a TCP listener, or 1 if an MQTT listener is connected to its broker.",
+		}, labels),
+	}
+}
+
+// PrometheusCollectors returns the collectors of the metrics.
+func (m *metrics) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.pointsReceived,
+		m.pointsWritten,
+		m.parseErrors,
+		m.writeErrors,
+		m.batchesWritten,
+		m.connections,
+	}
+}
+
+// listenerMetrics are the metrics of a single listener.
+type listenerMetrics struct {
+	pointsReceived prometheus.Counter
+	pointsWritten  prometheus.Counter
+	parseErrors    prometheus.Counter
+	writeErrors    prometheus.Counter
+	batchesWritten prometheus.Counter
+	connections    prometheus.Gauge
+}
+
+func (m *metrics) listener(c Config) *listenerMetrics {
+	labels := prometheus.Labels{"listener": c.Name, "protocol": c.Protocol}
+	return &listenerMetrics{
+		pointsReceived: m.pointsReceived.With(labels),
+		pointsWritten:  m.pointsWritten.With(labels),
+		parseErrors:    m.parseErrors.With(labels),
+		writeErrors:    m.writeErrors.With(labels),
+		batchesWritten: m.batchesWritten.With(labels),
+		connections:    m.connections.With(labels),
+	}
+}
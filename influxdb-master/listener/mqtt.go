@@ -0,0 +1,96 @@
+package listener
+
+import (
+	"crypto/tls"
+	"fmt"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/influxdata/influxdb/v2/models"
+	"go.uber.org/zap"
+)
+
+// mqttListener subscribes to a topic of an MQTT broker, and receives line
+// protocol in the payload of its messages.
+type mqttListener struct {
+	config  Config
+	batcher *batcher
+	log     *zap.Logger
+
+	client mqtt.Client
+}
+
+func newMQTTListener(c Config, b *batcher, log *zap.Logger) *mqttListener {
+	return &mqttListener{config: c, batcher: b, log: log}
+}
+
+func (l *mqttListener) Open() error {
+	scheme := "tcp"
+	if l.config.Protocol == MQTTS {
+		scheme = "ssl"
+	}
+
+	opts := mqtt.NewClientOptions().
+		AddBroker(fmt.Sprintf("%s://%s", scheme, l.config.Address)).
+		SetClientID(l.config.ClientID).
+		SetUsername(l.config.Username).
+		SetPassword(l.config.Password).
+		SetCleanSession(true).
+		SetAutoReconnect(true).
+		SetMaxReconnectInterval(30 * time.Second).
+		SetConnectTimeout(10 * time.Second).
+		SetOnConnectHandler(l.onConnect).
+		SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+			l.batcher.metrics.connections.Set(0)
+			l.log.Warn("Lost connection to MQTT broker", zap.Error(err))
+		})
+	if l.config.Protocol == MQTTS {
+		opts.SetTLSConfig(&tls.Config{MinVersion: tls.VersionTLS12})
+	}
+
+	l.client = mqtt.NewClient(opts)
+	if token := l.client.Connect(); token.Wait() && token.Error() != nil {
+		return token.Error()
+	}
+	return nil
+}
+
+// onConnect subscribes to the topic, on connecting and reconnecting to the
+// broker with a clean session.
+func (l *mqttListener) onConnect(c mqtt.Client) {
+	l.batcher.metrics.connections.Set(1)
+	token := c.Subscribe(l.config.Topic, l.config.QoS, l.onMessage)
+	go func() {
+		if token.Wait() && token.Error() != nil {
+			l.log.Error("Failed to subscribe to MQTT topic", zap.String("topic", l.config.Topic), zap.Error(token.Error()))
+		}
+	}()
+}
+
+func (l *mqttListener) onMessage(_ mqtt.Client, msg mqtt.Message) {
+	points, err := models.ParsePointsWithPrecision(msg.Payload(), time.Now().UTC(), l.config.Precision)
+	if err != nil {
+		l.batcher.metrics.parseErrors.Inc()
+		l.log.Debug("Failed to parse points", zap.String("topic", msg.Topic()), zap.Error(err))
+	}
+	if l.config.TopicTag != "" {
+		for _, p := range points {
+			p.AddTag(l.config.TopicTag, msg.Topic())
+		}
+	}
+	l.batcher.add(points)
+}
+
+func (l *mqttListener) Close() error {
+	if l.client.IsConnected() {
+		token := l.client.Unsubscribe(l.config.Topic)
+		token.WaitTimeout(time.Second)
+	}
+	l.client.Disconnect(250)
+	l.batcher.metrics.connections.Set(0)
+	return nil
+}
+
+func (l *mqttListener) Addr() string {
+	return l.config.Address
+}
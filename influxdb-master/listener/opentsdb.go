@@ -0,0 +1,54 @@
+package listener
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/models"
+)
+
+// parseOpenTSDB parses a put command of the OpenTSDB telnet protocol,
+//
+//	put <metric> <timestamp> <value> <tagk>=<tagv> [<tagk>=<tagv>...]
+//
+// into a point of the metric as measurement with a "value" field. The
+// timestamp is in seconds, or milliseconds if it has more than 10 digits.
+// Other commands are ignored.
+func parseOpenTSDB(line string, _ time.Time) (models.Point, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 || fields[0] != "put" {
+		return nil, nil
+	} else if len(fields) < 4 {
+		return nil, fmt.Errorf("invalid opentsdb put %q", line)
+	}
+
+	ts, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil || ts < 0 {
+		return nil, fmt.Errorf("invalid timestamp of opentsdb put %q", line)
+	}
+	unit := time.Second
+	if len(fields[2]) > 10 {
+		unit = time.Millisecond
+	}
+
+	value, err := strconv.ParseFloat(fields[3], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value of opentsdb put %q", line)
+	} else if math.IsNaN(value) || math.IsInf(value, 0) {
+		return nil, nil // unsupported by storage
+	}
+
+	tags := make(map[string]string, len(fields)-4)
+	for _, kv := range fields[4:] {
+		i := strings.IndexByte(kv, '=')
+		if i <= 0 || i == len(kv)-1 {
+			return nil, fmt.Errorf("invalid tag %q of opentsdb put %q", kv, line)
+		}
+		tags[kv[:i]] = kv[i+1:]
+	}
+
+	return models.NewPoint(fields[1], models.NewTags(tags), models.Fields{"value": value}, time.Unix(0, ts*int64(unit)).UTC())
+}
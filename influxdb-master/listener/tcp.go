@@ -0,0 +1,145 @@
+package listener
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/models"
+	"go.uber.org/zap"
+)
+
+// readBufferSize is the size of the read buffer of a TCP connection.
+const readBufferSize = 64 * 1024
+
+// parseLineFunc parses a line of a line based protocol into a point, or
+// returns a nil point for a line that holds none.
+type parseLineFunc func(line string, now time.Time) (models.Point, error)
+
+// tcpListener receives points in the lines of TCP connections, as the
+// Graphite plaintext and OpenTSDB telnet protocols send them.
+type tcpListener struct {
+	config  Config
+	batcher *batcher
+	log     *zap.Logger
+	parse   parseLineFunc
+
+	ln      net.Listener
+	mu      sync.Mutex
+	closing bool
+	conns   map[net.Conn]struct{}
+	wg      sync.WaitGroup
+}
+
+func newTCPListener(c Config, b *batcher, log *zap.Logger, parse parseLineFunc) *tcpListener {
+	return &tcpListener{
+		config:  c,
+		batcher: b,
+		log:     log,
+		parse:   parse,
+		conns:   make(map[net.Conn]struct{}),
+	}
+}
+
+func (l *tcpListener) Open() error {
+	ln, err := net.Listen("tcp", l.config.Address)
+	if err != nil {
+		return err
+	}
+	l.ln = ln
+
+	l.wg.Add(1)
+	go l.accept()
+	return nil
+}
+
+func (l *tcpListener) accept() {
+	defer l.wg.Done()
+	for {
+		conn, err := l.ln.Accept()
+		if err != nil {
+			if ne, ok := err.(net.Error); ok && ne.Temporary() {
+				continue
+			}
+			return // closed
+		}
+
+		// A connection accepted while closing is not served, as Close may
+		// already have closed the connections it knows of.
+		l.mu.Lock()
+		if l.closing {
+			l.mu.Unlock()
+			conn.Close()
+			return
+		}
+		l.conns[conn] = struct{}{}
+		l.wg.Add(1)
+		l.mu.Unlock()
+		l.batcher.metrics.connections.Inc()
+
+		go l.serve(conn)
+	}
+}
+
+func (l *tcpListener) serve(conn net.Conn) {
+	defer l.wg.Done()
+	defer func() {
+		conn.Close()
+		l.mu.Lock()
+		delete(l.conns, conn)
+		l.mu.Unlock()
+		l.batcher.metrics.connections.Dec()
+	}()
+
+	var (
+		r      = bufio.NewReaderSize(conn, readBufferSize)
+		points []models.Point
+	)
+	for {
+		line, err := r.ReadString('\n')
+		if len(line) > 0 {
+			if p, err := l.parse(strings.TrimSpace(line), time.Now().UTC()); err != nil {
+				l.batcher.metrics.parseErrors.Inc()
+				l.log.Debug("Failed to parse line", zap.Error(err))
+			} else if p != nil {
+				points = append(points, p)
+			}
+		}
+
+		// Pass on the points of the lines read at once.
+		if err != nil || r.Buffered() == 0 || len(points) >= l.config.BatchSize {
+			l.batcher.add(points)
+			points = nil
+		}
+		if err != nil {
+			if err != io.EOF {
+				l.log.Debug("Failed to read connection", zap.String("remote_addr", conn.RemoteAddr().String()), zap.Error(err))
+			}
+			return
+		}
+	}
+}
+
+func (l *tcpListener) Close() error {
+	l.mu.Lock()
+	l.closing = true
+	l.mu.Unlock()
+
+	err := l.ln.Close()
+
+	l.mu.Lock()
+	for conn := range l.conns {
+		conn.Close()
+	}
+	l.mu.Unlock()
+
+	l.wg.Wait()
+	return err
+}
+
+func (l *tcpListener) Addr() string {
+	return l.ln.Addr().String()
+}
@@ -0,0 +1,73 @@
+package listener
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/models"
+	"go.uber.org/zap"
+)
+
+// maxUDPPayload is the largest UDP payload.
+const maxUDPPayload = 64 * 1024
+
+// udpListener receives line protocol in UDP packets.
+type udpListener struct {
+	config  Config
+	batcher *batcher
+	log     *zap.Logger
+
+	conn *net.UDPConn
+	wg   sync.WaitGroup
+}
+
+func newUDPListener(c Config, b *batcher, log *zap.Logger) *udpListener {
+	return &udpListener{config: c, batcher: b, log: log}
+}
+
+func (l *udpListener) Open() error {
+	addr, err := net.ResolveUDPAddr("udp", l.config.Address)
+	if err != nil {
+		return err
+	}
+	if l.conn, err = net.ListenUDP("udp", addr); err != nil {
+		return err
+	}
+
+	l.wg.Add(1)
+	go l.serve()
+	return nil
+}
+
+func (l *udpListener) serve() {
+	defer l.wg.Done()
+
+	buf := make([]byte, maxUDPPayload)
+	for {
+		n, _, err := l.conn.ReadFromUDP(buf)
+		if err != nil {
+			if ne, ok := err.(net.Error); ok && ne.Temporary() {
+				continue
+			}
+			return // closed
+		}
+
+		points, err := models.ParsePointsWithPrecision(buf[:n], time.Now().UTC(), l.config.Precision)
+		if err != nil {
+			l.batcher.metrics.parseErrors.Inc()
+			l.log.Debug("Failed to parse points", zap.Error(err))
+		}
+		l.batcher.add(points)
+	}
+}
+
+func (l *udpListener) Close() error {
+	err := l.conn.Close()
+	l.wg.Wait()
+	return err
+}
+
+func (l *udpListener) Addr() string {
+	return l.conn.LocalAddr().String()
+}
@@ -0,0 +1,193 @@
+package listener
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/storage"
+	"go.uber.org/zap"
+)
+
+// targetTTL is how long the bucket and authorization of a listener are
+// cached, after which changes to them, such as a revoked token, apply.
+const targetTTL = time.Minute
+
+// target resolves the bucket a listener writes to, and checks that the token
+// of the listener is allowed to write to it.
+type target struct {
+	config  Config
+	auths   influxdb.AuthorizationService
+	orgs    influxdb.OrganizationService
+	buckets influxdb.BucketService
+
+	mu       sync.Mutex
+	orgID    platform.ID
+	bucketID platform.ID
+	expires  time.Time
+}
+
+// resolve returns the IDs of the org and bucket of the listener.
+func (t *target) resolve(ctx context.Context) (orgID, bucketID platform.ID, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	if now.Before(t.expires) {
+		return t.orgID, t.bucketID, nil
+	}
+
+	auth, err := t.auths.FindAuthorizationByToken(ctx, t.config.Token)
+	if err != nil {
+		return 0, 0, fmt.Errorf("unable to find authorization of token: %v", err)
+	}
+	org, err := t.orgs.FindOrganization(ctx, influxdb.OrganizationFilter{Name: &t.config.Org})
+	if err != nil {
+		return 0, 0, fmt.Errorf("unable to find org %q: %v", t.config.Org, err)
+	}
+	bucket, err := t.buckets.FindBucket(ctx, influxdb.BucketFilter{OrganizationID: &org.ID, Name: &t.config.Bucket})
+	if err != nil {
+		return 0, 0, fmt.Errorf("unable to find bucket %q: %v", t.config.Bucket, err)
+	}
+
+	p, err := influxdb.NewPermissionAtID(bucket.ID, influxdb.WriteAction, influxdb.BucketsResourceType, org.ID)
+	if err != nil {
+		return 0, 0, err
+	}
+	if pset, err := auth.PermissionSet(); err != nil || !pset.Allowed(*p) {
+		return 0, 0, fmt.Errorf("token does not have permission to write to bucket %q", t.config.Bucket)
+	}
+
+	t.orgID, t.bucketID, t.expires = org.ID, bucket.ID, now.Add(targetTTL)
+	return t.orgID, t.bucketID, nil
+}
+
+// batcher batches the points received by a listener, and writes them to its
+// bucket once BatchSize points are held or the oldest is held for
+// BatchTimeout.
+type batcher struct {
+	config  Config
+	target  *target
+	writer  storage.PointsWriter
+	metrics *listenerMetrics
+	log     *zap.Logger
+
+	// mu guards closed, so that add does not send on in once it is closed,
+	// as a listener may still pass on points while it is closing.
+	mu     sync.RWMutex
+	closed bool
+	in     chan []models.Point
+	done   chan struct{}
+}
+
+func newBatcher(c Config, t *target, w storage.PointsWriter, m *listenerMetrics, log *zap.Logger) *batcher {
+	return &batcher{
+		config:  c,
+		target:  t,
+		writer:  w,
+		metrics: m,
+		log:     log,
+		in:      make(chan []models.Point, 64),
+		done:    make(chan struct{}),
+	}
+}
+
+// add adds points to the batch. The points added after close are dropped.
+func (b *batcher) add(points []models.Point) {
+	if len(points) == 0 {
+		return
+	}
+	b.metrics.pointsReceived.Add(float64(len(points)))
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		b.log.Debug("Dropped points received after closing", zap.Int("points", len(points)))
+		return
+	}
+	b.in <- points
+}
+
+// run writes the batches until close is called, then writes the points left.
+func (b *batcher) run() {
+	defer close(b.done)
+
+	var (
+		ctx     = context.Background()
+		batch   = make([]models.Point, 0, b.config.BatchSize)
+		timer   = time.NewTimer(b.config.BatchTimeout)
+		running = false
+	)
+	timer.Stop()
+
+	flush := func() {
+		if len(batch) > 0 {
+			b.write(ctx, batch)
+			batch = make([]models.Point, 0, b.config.BatchSize)
+		}
+	}
+
+	for {
+		select {
+		case points, ok := <-b.in:
+			if !ok {
+				flush()
+				return
+			}
+			for len(points) > 0 {
+				n := b.config.BatchSize - len(batch)
+				if n > len(points) {
+					n = len(points)
+				}
+				batch = append(batch, points[:n]...)
+				points = points[n:]
+				if len(batch) == b.config.BatchSize {
+					flush()
+				}
+			}
+
+			// The timer runs while points are held.
+			if len(batch) == 0 && running {
+				if !timer.Stop() {
+					<-timer.C
+				}
+				running = false
+			} else if len(batch) > 0 && !running {
+				timer.Reset(b.config.BatchTimeout)
+				running = true
+			}
+		case <-timer.C:
+			running = false
+			flush()
+		}
+	}
+}
+
+// close stops accepting points, and waits for the points held to be written.
+func (b *batcher) close() {
+	b.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		close(b.in)
+	}
+	b.mu.Unlock()
+	<-b.done
+}
+
+func (b *batcher) write(ctx context.Context, points []models.Point) {
+	orgID, bucketID, err := b.target.resolve(ctx)
+	if err == nil {
+		err = b.writer.WritePoints(ctx, orgID, bucketID, points)
+	}
+	if err != nil {
+		b.metrics.writeErrors.Inc()
+		b.log.Error("Failed to write points", zap.Int("points", len(points)), zap.Error(err))
+		return
+	}
+	b.metrics.batchesWritten.Inc()
+	b.metrics.pointsWritten.Add(float64(len(points)))
+}
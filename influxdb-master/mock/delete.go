@@ -12,7 +12,8 @@ var _ influxdb.DeleteService = &DeleteService{}
 
 // DeleteService is a mock delete server.
 type DeleteService struct {
-	DeleteBucketRangePredicateF func(tx context.Context, orgID, bucketID platform.ID, min, max int64, pred influxdb.Predicate) error
+	DeleteBucketRangePredicateF       func(tx context.Context, orgID, bucketID platform.ID, min, max int64, pred influxdb.Predicate) error
+	DeleteBucketRangePredicateDryRunF func(tx context.Context, orgID, bucketID platform.ID, min, max int64, pred influxdb.Predicate, limit int) (*influxdb.DeleteDryRun, error)
 }
 
 // NewDeleteService returns a mock DeleteService where its methods will return
@@ -22,6 +23,9 @@ func NewDeleteService() DeleteService {
 		DeleteBucketRangePredicateF: func(tx context.Context, orgID, bucketID platform.ID, min, max int64, pred influxdb.Predicate) error {
 			return nil
 		},
+		DeleteBucketRangePredicateDryRunF: func(tx context.Context, orgID, bucketID platform.ID, min, max int64, pred influxdb.Predicate, limit int) (*influxdb.DeleteDryRun, error) {
+			return &influxdb.DeleteDryRun{}, nil
+		},
 	}
 }
 
@@ -29,3 +33,8 @@ func NewDeleteService() DeleteService {
 func (s DeleteService) DeleteBucketRangePredicate(ctx context.Context, orgID, bucketID platform.ID, min, max int64, pred influxdb.Predicate) error {
 	return s.DeleteBucketRangePredicateF(ctx, orgID, bucketID, min, max, pred)
 }
+
+// DeleteBucketRangePredicateDryRun calls DeleteBucketRangePredicateDryRunF.
+func (s DeleteService) DeleteBucketRangePredicateDryRun(ctx context.Context, orgID, bucketID platform.ID, min, max int64, pred influxdb.Predicate, limit int) (*influxdb.DeleteDryRun, error) {
+	return s.DeleteBucketRangePredicateDryRunF(ctx, orgID, bucketID, min, max, pred, limit)
+}
@@ -0,0 +1,24 @@
+package notebooks
+
+import (
+	"encoding/json"
+
+	"github.com/influxdata/influxdb/v2/kv"
+	nbsvc "github.com/influxdata/influxdb/v2/notebooks/service"
+)
+
+var (
+	// ByOrgIDIndexMapping is the mapping definition for fetching notebooks
+	// by organization ID.
+	ByOrgIDIndexMapping = kv.NewIndexMapping(
+		notebookBucket,
+		notebookOrgIndexBucket,
+		func(v []byte) ([]byte, error) {
+			var n nbsvc.Notebook
+			if err := json.Unmarshal(v, &n); err != nil {
+				return nil, err
+			}
+			return n.OrgID.Encode()
+		},
+	)
+)
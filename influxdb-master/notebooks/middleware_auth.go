@@ -0,0 +1,132 @@
+package notebooks
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	nbsvc "github.com/influxdata/influxdb/v2/notebooks/service"
+)
+
+var (
+	_ nbsvc.NotebookService = (*AuthorizedService)(nil)
+	_ nbsvc.RevisionService = (*AuthorizedService)(nil)
+	_ nbsvc.RunService      = (*AuthorizedRunService)(nil)
+)
+
+// AuthorizedService is a NotebookService and RevisionService authorizing
+// access to notebooks and their revisions with the notebooks resource type.
+// Listing notebooks requires read access to all the notebooks of the
+// organization, and reverting a notebook requires write access to it.
+type AuthorizedService struct {
+	nbsvc.NotebookService
+	nbsvc.RevisionService
+}
+
+// NewAuthorizedService returns an AuthorizedService authorizing access to the
+// notebooks of notebooks and the revisions of revisions.
+func NewAuthorizedService(notebooks nbsvc.NotebookService, revisions nbsvc.RevisionService) *AuthorizedService {
+	return &AuthorizedService{
+		NotebookService: notebooks,
+		RevisionService: revisions,
+	}
+}
+
+func (s *AuthorizedService) GetNotebook(ctx context.Context, orgID platform.ID, id platform.ID) (*nbsvc.Notebook, error) {
+	if _, _, err := authorizer.AuthorizeRead(ctx, influxdb.NotebooksResourceType, id, orgID); err != nil {
+		return nil, err
+	}
+	return s.NotebookService.GetNotebook(ctx, orgID, id)
+}
+
+func (s *AuthorizedService) CreateNotebook(ctx context.Context, create nbsvc.NotebookCreate) (*nbsvc.Notebook, error) {
+	if _, _, err := authorizer.AuthorizeCreate(ctx, influxdb.NotebooksResourceType, create.OrgID); err != nil {
+		return nil, err
+	}
+	return s.NotebookService.CreateNotebook(ctx, create)
+}
+
+func (s *AuthorizedService) UpdateNotebook(ctx context.Context, orgID platform.ID, id platform.ID, update nbsvc.NotebookUpdate) (*nbsvc.Notebook, error) {
+	if _, _, err := authorizer.AuthorizeWrite(ctx, influxdb.NotebooksResourceType, id, orgID); err != nil {
+		return nil, err
+	}
+	return s.NotebookService.UpdateNotebook(ctx, orgID, id, update)
+}
+
+func (s *AuthorizedService) DeleteNotebook(ctx context.Context, orgID platform.ID, id platform.ID) error {
+	if _, _, err := authorizer.AuthorizeWrite(ctx, influxdb.NotebooksResourceType, id, orgID); err != nil {
+		return err
+	}
+	return s.NotebookService.DeleteNotebook(ctx, orgID, id)
+}
+
+func (s *AuthorizedService) ListNotebooks(ctx context.Context, filter nbsvc.NotebookListFilter) ([]*nbsvc.Notebook, error) {
+	if _, _, err := authorizer.AuthorizeOrgReadResource(ctx, influxdb.NotebooksResourceType, filter.OrgID); err != nil {
+		return nil, err
+	}
+	return s.NotebookService.ListNotebooks(ctx, filter)
+}
+
+func (s *AuthorizedService) ListRevisions(ctx context.Context, orgID platform.ID, id platform.ID, page nbsvc.Page) ([]*nbsvc.Revision, error) {
+	if _, _, err := authorizer.AuthorizeRead(ctx, influxdb.NotebooksResourceType, id, orgID); err != nil {
+		return nil, err
+	}
+	return s.RevisionService.ListRevisions(ctx, orgID, id, page)
+}
+
+func (s *AuthorizedService) GetRevision(ctx context.Context, orgID platform.ID, id platform.ID, revision int) (*nbsvc.Revision, error) {
+	if _, _, err := authorizer.AuthorizeRead(ctx, influxdb.NotebooksResourceType, id, orgID); err != nil {
+		return nil, err
+	}
+	return s.RevisionService.GetRevision(ctx, orgID, id, revision)
+}
+
+func (s *AuthorizedService) DiffRevisions(ctx context.Context, orgID platform.ID, id platform.ID, from, to int) (*nbsvc.RevisionDiff, error) {
+	if _, _, err := authorizer.AuthorizeRead(ctx, influxdb.NotebooksResourceType, id, orgID); err != nil {
+		return nil, err
+	}
+	return s.RevisionService.DiffRevisions(ctx, orgID, id, from, to)
+}
+
+func (s *AuthorizedService) RevertNotebook(ctx context.Context, orgID platform.ID, id platform.ID, revision int) (*nbsvc.Notebook, error) {
+	if _, _, err := authorizer.AuthorizeWrite(ctx, influxdb.NotebooksResourceType, id, orgID); err != nil {
+		return nil, err
+	}
+	return s.RevisionService.RevertNotebook(ctx, orgID, id, revision)
+}
+
+// AuthorizedRunService is a RunService authorizing access to the runs of
+// notebooks with the notebooks resource type: running a notebook requires
+// write access to it, and reading its runs requires read access. The queries
+// of a run are executed with the authorization of the caller.
+type AuthorizedRunService struct {
+	nbsvc.RunService
+}
+
+// NewAuthorizedRunService returns an AuthorizedRunService authorizing access
+// to the runs of s.
+func NewAuthorizedRunService(s nbsvc.RunService) *AuthorizedRunService {
+	return &AuthorizedRunService{RunService: s}
+}
+
+func (s *AuthorizedRunService) RunNotebook(ctx context.Context, orgID platform.ID, id platform.ID, req nbsvc.RunRequest) (*nbsvc.Run, error) {
+	if _, _, err := authorizer.AuthorizeWrite(ctx, influxdb.NotebooksResourceType, id, orgID); err != nil {
+		return nil, err
+	}
+	return s.RunService.RunNotebook(ctx, orgID, id, req)
+}
+
+func (s *AuthorizedRunService) ListRuns(ctx context.Context, orgID platform.ID, id platform.ID, page nbsvc.Page) ([]*nbsvc.Run, error) {
+	if _, _, err := authorizer.AuthorizeRead(ctx, influxdb.NotebooksResourceType, id, orgID); err != nil {
+		return nil, err
+	}
+	return s.RunService.ListRuns(ctx, orgID, id, page)
+}
+
+func (s *AuthorizedRunService) GetRun(ctx context.Context, orgID platform.ID, id platform.ID, runID platform.ID) (*nbsvc.Run, error) {
+	if _, _, err := authorizer.AuthorizeRead(ctx, influxdb.NotebooksResourceType, id, orgID); err != nil {
+		return nil, err
+	}
+	return s.RunService.GetRun(ctx, orgID, id, runID)
+}
@@ -0,0 +1,212 @@
+package notebooks
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/influxdata/flux/csv"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/internal/queryutil"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	nbsvc "github.com/influxdata/influxdb/v2/notebooks/service"
+	"github.com/influxdata/influxdb/v2/query"
+)
+
+const (
+	// DefaultMaxResultBytes is the default limit of the size of the annotated
+	// CSV of the result of a cell of a run.
+	DefaultMaxResultBytes = 10 << 20
+
+	// DefaultRange is the time range of a run of a notebook whose spec has
+	// no time range.
+	DefaultRange = time.Hour
+)
+
+// RunStore stores the runs of notebooks.
+type RunStore interface {
+	CreateRun(ctx context.Context, run *nbsvc.Run) error
+	ListRuns(ctx context.Context, orgID platform.ID, id platform.ID, page nbsvc.Page) ([]*nbsvc.Run, error)
+	GetRun(ctx context.Context, orgID platform.ID, id platform.ID, runID platform.ID) (*nbsvc.Run, error)
+}
+
+var _ nbsvc.RunService = (*Runner)(nil)
+
+// Runner is a RunService executing the query cells of notebooks with the
+// authorization of the context it is given, and storing their runs.
+type Runner struct {
+	RunStore
+
+	RevisionService nbsvc.RevisionService
+	QueryService    query.ProxyQueryService
+	TimeGenerator   influxdb.TimeGenerator
+
+	// MaxResultBytes limits the size of the annotated CSV of the result of a
+	// cell. A result exceeding it is replaced with an error.
+	MaxResultBytes int
+}
+
+// NewRunner returns a Runner reading revisions of notebooks from revisions,
+// executing their queries with qs, and storing their runs in runs.
+func NewRunner(revisions nbsvc.RevisionService, runs RunStore, qs query.ProxyQueryService) *Runner {
+	return &Runner{
+		RunStore:        runs,
+		RevisionService: revisions,
+		QueryService:    qs,
+		TimeGenerator:   influxdb.RealTimeGenerator{},
+		MaxResultBytes:  DefaultMaxResultBytes,
+	}
+}
+
+// RunNotebook executes the query cells of a revision of a notebook in order
+// and stores the run. The time range of the run is that of the request, else
+// that of the notebook spec, else the DefaultRange until now.
+func (r *Runner) RunNotebook(ctx context.Context, orgID platform.ID, id platform.ID, req nbsvc.RunRequest) (*nbsvc.Run, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	revision := req.Revision
+	if revision == 0 {
+		revs, err := r.RevisionService.ListRevisions(ctx, orgID, id, nbsvc.Page{Limit: 1})
+		if err != nil {
+			return nil, err
+		}
+		if len(revs) == 0 {
+			return nil, nbsvc.ErrRevisionNotFound
+		}
+		revision = revs[0].Revision
+	}
+	rev, err := r.RevisionService.GetRevision(ctx, orgID, id, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	auth, err := queryutil.Authorization(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := r.TimeGenerator.Now().UTC()
+	start, stop := specRange(rev.Spec, now)
+	if req.Start != nil {
+		start = req.Start.UTC()
+	}
+	if req.Stop != nil {
+		stop = req.Stop.UTC()
+	}
+	if !start.Before(stop) {
+		return nil, nbsvc.ErrInvalidTimeRange
+	}
+
+	run := &nbsvc.Run{
+		OrgID:      orgID,
+		NotebookID: id,
+		Revision:   rev.Revision,
+		Start:      start,
+		Stop:       stop,
+		Status:     nbsvc.RunSuccess,
+		StartedAt:  now,
+		Cells:      []nbsvc.RunCell{},
+	}
+
+	extern, err := json.Marshal(queryutil.TimeRangeExtern(start, stop))
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range queryCells(rev.Spec) {
+		r.execute(ctx, auth, run, extern, &c)
+		if c.Error != "" {
+			run.Status = nbsvc.RunFailed
+		}
+		run.Cells = append(run.Cells, c)
+	}
+	run.FinishedAt = r.TimeGenerator.Now().UTC()
+
+	if err := r.CreateRun(ctx, run); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// execute executes the query of a cell of a run, recording its result in the
+// cell.
+func (r *Runner) execute(ctx context.Context, auth *influxdb.Authorization, run *nbsvc.Run, extern json.RawMessage, c *nbsvc.RunCell) {
+	req := &query.ProxyRequest{
+		Request: query.Request{
+			Authorization:  auth,
+			OrganizationID: run.OrgID,
+			Compiler: lang.FluxCompiler{
+				Now:    run.Stop,
+				Extern: extern,
+				Query:  c.Query,
+			},
+			Source: "notebooks",
+		},
+		Dialect: csv.Dialect{ResultEncoderConfig: csv.DefaultEncoderConfig()},
+	}
+
+	w := queryutil.NewLimitedBuffer(r.MaxResultBytes)
+	if _, err := r.QueryService.Query(icontext.SetAuthorizer(ctx, auth), w, req); err != nil {
+		c.Error = err.Error()
+		return
+	}
+	c.CSV = w.String()
+}
+
+// queryCells returns the cells of the query pipes of a notebook spec, with
+// the text of their active query. Pipes without query text are skipped.
+func queryCells(spec nbsvc.NotebookSpec) []nbsvc.RunCell {
+	pipes, _ := spec["pipes"].([]interface{})
+
+	var cells []nbsvc.RunCell
+	for i, p := range pipes {
+		pipe, ok := p.(map[string]interface{})
+		if !ok || pipe["type"] != "query" {
+			continue
+		}
+		queries, _ := pipe["queries"].([]interface{})
+		active := 0
+		if a, ok := pipe["activeQuery"].(float64); ok {
+			active = int(a)
+		}
+		if active < 0 || active >= len(queries) {
+			continue
+		}
+		q, _ := queries[active].(map[string]interface{})
+		text, _ := q["text"].(string)
+		if text == "" {
+			continue
+		}
+		title, _ := pipe["title"].(string)
+		cells = append(cells, nbsvc.RunCell{Pipe: i, Title: title, Query: text})
+	}
+	return cells
+}
+
+// specRange returns the time range of a notebook spec. A range of the
+// selectable-duration type ends at now, and a range of the custom type has
+// RFC3339 lower and upper times. The range defaults to the DefaultRange until
+// now.
+func specRange(spec nbsvc.NotebookSpec, now time.Time) (time.Time, time.Time) {
+	start, stop := now.Add(-DefaultRange), now
+
+	rng, _ := spec["range"].(map[string]interface{})
+	switch rng["type"] {
+	case "selectable-duration":
+		if s, ok := rng["seconds"].(float64); ok && s > 0 {
+			start = now.Add(-time.Duration(s) * time.Second)
+		}
+	case "custom":
+		lower, _ := rng["lower"].(string)
+		upper, _ := rng["upper"].(string)
+		l, lerr := time.Parse(time.RFC3339Nano, lower)
+		u, uerr := time.Parse(time.RFC3339Nano, upper)
+		if lerr == nil && uerr == nil {
+			start, stop = l.UTC(), u.UTC()
+		}
+	}
+	return start, stop
+}
@@ -0,0 +1,102 @@
+package notebooks_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/notebooks"
+	nbsvc "github.com/influxdata/influxdb/v2/notebooks/service"
+	"github.com/influxdata/influxdb/v2/query"
+	querymock "github.com/influxdata/influxdb/v2/query/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunner_RunNotebook(t *testing.T) {
+	svc := newTestService(t)
+	ctx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{ID: 5, OrgID: 1, UserID: 6, Status: influxdb.Active})
+
+	spec := nbsvc.NotebookSpec{
+		"pipes": []interface{}{
+			map[string]interface{}{
+				"type":        "query",
+				"title":       "Altitude",
+				"activeQuery": 1,
+				"queries": []interface{}{
+					map[string]interface{}{"text": "inactive"},
+					map[string]interface{}{"text": "from(bucket: \"orbits\") |> range(start: v.timeRangeStart)"},
+				},
+			},
+			map[string]interface{}{"type": "markdown", "text": "notes"},
+			map[string]interface{}{
+				"type":    "query",
+				"queries": []interface{}{map[string]interface{}{"text": "fail"}},
+			},
+		},
+		"range": map[string]interface{}{"type": "selectable-duration", "seconds": 7200},
+	}
+	n, err := svc.CreateNotebook(ctx, nbsvc.NotebookCreate{OrgID: 1, Name: "anomaly", Spec: spec})
+	require.NoError(t, err)
+	_, err = svc.UpdateNotebook(ctx, 1, n.ID, nbsvc.NotebookUpdate{Name: "anomaly", Spec: querySpec("latest")})
+	require.NoError(t, err)
+
+	var queries []string
+	qs := &querymock.ProxyQueryService{
+		QueryF: func(ctx context.Context, w io.Writer, req *query.ProxyRequest) (flux.Statistics, error) {
+			c := req.Request.Compiler.(lang.FluxCompiler)
+			queries = append(queries, c.Query)
+			if c.Query == "fail" {
+				return flux.Statistics{}, errors.New("compilation failed")
+			}
+			_, err := io.WriteString(w, "#datatype,string\n,result\n,_result\n")
+			return flux.Statistics{}, err
+		},
+	}
+	runner := notebooks.NewRunner(svc, svc, qs)
+	runner.TimeGenerator = mock.TimeGenerator{FakeValue: now}
+
+	run, err := runner.RunNotebook(ctx, 1, n.ID, nbsvc.RunRequest{Revision: 1})
+	require.NoError(t, err)
+	require.Equal(t, []string{"from(bucket: \"orbits\") |> range(start: v.timeRangeStart)", "fail"}, queries)
+	require.Equal(t, nbsvc.RunFailed, run.Status)
+	require.Equal(t, 1, run.Revision)
+	require.Equal(t, now.Add(-2*time.Hour), run.Start)
+	require.Equal(t, now, run.Stop)
+	require.Len(t, run.Cells, 2)
+	require.Equal(t, 0, run.Cells[0].Pipe)
+	require.Equal(t, "Altitude", run.Cells[0].Title)
+	require.NotEmpty(t, run.Cells[0].CSV)
+	require.Equal(t, 2, run.Cells[1].Pipe)
+	require.Equal(t, "compilation failed", run.Cells[1].Error)
+
+	stored, err := svc.GetRun(ctx, 1, n.ID, run.ID)
+	require.NoError(t, err)
+	require.Equal(t, run.Cells, stored.Cells)
+
+	// The current revision runs by default, with the requested time range.
+	queries = nil
+	start, stop := now.Add(-24*time.Hour), now.Add(-12*time.Hour)
+	run, err = runner.RunNotebook(ctx, 1, n.ID, nbsvc.RunRequest{Start: &start, Stop: &stop})
+	require.NoError(t, err)
+	require.Equal(t, []string{"latest"}, queries)
+	require.Equal(t, nbsvc.RunSuccess, run.Status)
+	require.Equal(t, 2, run.Revision)
+	require.Equal(t, start, run.Start)
+	require.Equal(t, stop, run.Stop)
+
+	runs, err := runner.ListRuns(ctx, 1, n.ID, nbsvc.Page{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	require.Equal(t, run.ID, runs[0].ID)
+	require.Nil(t, runs[0].Cells)
+
+	_, err = runner.RunNotebook(ctx, 1, n.ID, nbsvc.RunRequest{Start: &stop, Stop: &start})
+	require.Equal(t, nbsvc.ErrInvalidTimeRange, err)
+}
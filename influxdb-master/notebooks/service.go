@@ -1 +1,667 @@
 package notebooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/internal/queryutil"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/kv"
+	nbsvc "github.com/influxdata/influxdb/v2/notebooks/service"
+	"github.com/influxdata/influxdb/v2/snowflake"
+)
+
+var (
+	notebookBucket         = []byte("notebooksv1")
+	notebookOrgIndexBucket = []byte("notebooksbyorgindexv1")
+	revisionBucket         = []byte("notebookrevisionsv1")
+	runBucket              = []byte("notebookrunsv1")
+	runOutputBucket        = []byte("notebookrunoutputsv1")
+)
+
+// DefaultMaxRuns is the default number of runs kept per notebook.
+const DefaultMaxRuns = 100
+
+// InternalNotebookServiceError is used when the error comes from an internal
+// system.
+func InternalNotebookServiceError(err error) *errors.Error {
+	return &errors.Error{
+		Code: errors.EInternal,
+		Msg:  fmt.Sprintf("Unknown internal notebook data error; Err: %v", err),
+		Op:   "kv/notebook",
+	}
+}
+
+var (
+	_ nbsvc.NotebookService = (*Service)(nil)
+	_ nbsvc.RevisionService = (*Service)(nil)
+	_ RunStore              = (*Service)(nil)
+)
+
+// Service is a NotebookService and RevisionService storing notebooks, their
+// revisions and their runs in a kv store. Revisions and runs are keyed by the
+// ID of their notebook, so that those of a notebook are read with a prefix
+// scan. The outputs of the cells of runs are stored apart from the runs, so
+// that listing runs does not read them.
+type Service struct {
+	kv kv.Store
+
+	byOrgIndex *kv.Index
+
+	IDGenerator   platform.IDGenerator
+	TimeGenerator influxdb.TimeGenerator
+
+	// MaxRuns is the number of runs kept per notebook. The oldest runs of a
+	// notebook are deleted when a run exceeds it. Runs are kept forever if
+	// it is zero.
+	MaxRuns int
+}
+
+// NewService constructs and configures a new notebook service.
+func NewService(store kv.Store) *Service {
+	return &Service{
+		kv:            store,
+		byOrgIndex:    kv.NewIndex(ByOrgIDIndexMapping, kv.WithIndexReadPathEnabled),
+		IDGenerator:   snowflake.NewIDGenerator(),
+		TimeGenerator: influxdb.RealTimeGenerator{},
+		MaxRuns:       DefaultMaxRuns,
+	}
+}
+
+// GetNotebook returns a single notebook of an organization by ID.
+func (s *Service) GetNotebook(ctx context.Context, orgID platform.ID, id platform.ID) (*nbsvc.Notebook, error) {
+	var n *nbsvc.Notebook
+	err := s.kv.View(ctx, func(tx kv.Tx) error {
+		var err error
+		n, err = findNotebook(tx, orgID, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// CreateNotebook creates a notebook and records its first revision.
+func (s *Service) CreateNotebook(ctx context.Context, create nbsvc.NotebookCreate) (*nbsvc.Notebook, error) {
+	if err := create.Validate(); err != nil {
+		return nil, err
+	}
+	now := s.TimeGenerator.Now()
+	n := &nbsvc.Notebook{
+		OrgID:     create.OrgID,
+		ID:        s.IDGenerator.ID(),
+		Name:      create.Name,
+		Spec:      create.Spec,
+		Revision:  1,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if n.Spec == nil {
+		n.Spec = nbsvc.NotebookSpec{}
+	}
+
+	err := s.kv.Update(ctx, func(tx kv.Tx) error {
+		if err := putNotebook(tx, n); err != nil {
+			return err
+		}
+		orgKey, key, err := notebookIndexKeys(n)
+		if err != nil {
+			return err
+		}
+		if err := s.byOrgIndex.Insert(tx, orgKey, key); err != nil {
+			return InternalNotebookServiceError(err)
+		}
+		return putRevision(tx, &nbsvc.Revision{
+			NotebookID: n.ID,
+			Revision:   n.Revision,
+			Name:       n.Name,
+			Spec:       n.Spec,
+			CreatedAt:  now,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// UpdateNotebook updates the name and spec of a notebook, recording a new
+// revision when they change.
+func (s *Service) UpdateNotebook(ctx context.Context, orgID platform.ID, id platform.ID, update nbsvc.NotebookUpdate) (*nbsvc.Notebook, error) {
+	if err := update.Validate(); err != nil {
+		return nil, err
+	}
+	return s.update(ctx, orgID, id, func(kv.Tx, *nbsvc.Notebook) (*nbsvc.Revision, error) {
+		return &nbsvc.Revision{Name: update.Name, Spec: update.Spec}, nil
+	})
+}
+
+// RevertNotebook sets the name and spec of a notebook to those of one of its
+// revisions, recording a new revision when they change.
+func (s *Service) RevertNotebook(ctx context.Context, orgID platform.ID, id platform.ID, revision int) (*nbsvc.Notebook, error) {
+	return s.update(ctx, orgID, id, func(tx kv.Tx, n *nbsvc.Notebook) (*nbsvc.Revision, error) {
+		r, err := findRevision(tx, n.ID, revision)
+		if err != nil {
+			return nil, err
+		}
+		return &nbsvc.Revision{Name: r.Name, Spec: r.Spec, RevertedFrom: r.Revision}, nil
+	})
+}
+
+// update updates a notebook with the name and spec of the revision returned
+// by next, recording it as the next revision of the notebook unless nothing
+// changed.
+func (s *Service) update(ctx context.Context, orgID, id platform.ID, next func(kv.Tx, *nbsvc.Notebook) (*nbsvc.Revision, error)) (*nbsvc.Notebook, error) {
+	var n *nbsvc.Notebook
+	err := s.kv.Update(ctx, func(tx kv.Tx) error {
+		var err error
+		if n, err = findNotebook(tx, orgID, id); err != nil {
+			return err
+		}
+		r, err := next(tx, n)
+		if err != nil {
+			return err
+		}
+
+		changed, err := revisionChanged(n, r)
+		if err != nil || !changed {
+			return err
+		}
+		now := s.TimeGenerator.Now()
+		n.Name, n.Spec = r.Name, r.Spec
+		n.Revision++
+		n.UpdatedAt = now
+		if err := putNotebook(tx, n); err != nil {
+			return err
+		}
+		r.NotebookID, r.Revision, r.CreatedAt = n.ID, n.Revision, now
+		return putRevision(tx, r)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// revisionChanged returns whether the name or spec of a revision differ from
+// those of a notebook.
+func revisionChanged(n *nbsvc.Notebook, r *nbsvc.Revision) (bool, error) {
+	d, err := nbsvc.Diff(&nbsvc.Revision{Name: n.Name, Spec: n.Spec}, r)
+	if err != nil {
+		return false, InternalNotebookServiceError(err)
+	}
+	return len(d.Changes) > 0, nil
+}
+
+// DeleteNotebook deletes a notebook with its revisions and runs.
+func (s *Service) DeleteNotebook(ctx context.Context, orgID platform.ID, id platform.ID) error {
+	return s.kv.Update(ctx, func(tx kv.Tx) error {
+		n, err := findNotebook(tx, orgID, id)
+		if err != nil {
+			return err
+		}
+		orgKey, key, err := notebookIndexKeys(n)
+		if err != nil {
+			return err
+		}
+		b, err := tx.Bucket(notebookBucket)
+		if err != nil {
+			return InternalNotebookServiceError(err)
+		}
+		if err := b.Delete(key); err != nil {
+			return InternalNotebookServiceError(err)
+		}
+		if err := s.byOrgIndex.Delete(tx, orgKey, key); err != nil {
+			return InternalNotebookServiceError(err)
+		}
+		for _, bucket := range [][]byte{revisionBucket, runBucket, runOutputBucket} {
+			if err := deletePrefix(tx, bucket, key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ListNotebooks returns a page of the notebooks of an organization, ordered
+// by ID. The notebooks are found with the index of the notebooks of the
+// organization, and only those of the page are decoded.
+func (s *Service) ListNotebooks(ctx context.Context, filter nbsvc.NotebookListFilter) ([]*nbsvc.Notebook, error) {
+	if !filter.OrgID.Valid() {
+		return nil, nbsvc.ErrOrgIDRequired
+	}
+	if err := filter.Page.Validate(); err != nil {
+		return nil, err
+	}
+	orgKey, err := filter.OrgID.Encode()
+	if err != nil {
+		return nil, &errors.Error{
+			Code: errors.EInvalid,
+			Err:  err,
+		}
+	}
+
+	ns := []*nbsvc.Notebook{}
+	err = s.kv.View(ctx, func(tx kv.Tx) error {
+		skip := filter.Page.Offset
+		return s.byOrgIndex.Walk(ctx, tx, orgKey, func(k, v []byte) (bool, error) {
+			if skip > 0 {
+				skip--
+				return true, nil
+			}
+			var n nbsvc.Notebook
+			if err := json.Unmarshal(v, &n); err != nil {
+				return false, InternalNotebookServiceError(err)
+			}
+			ns = append(ns, &n)
+			return len(ns) < filter.Page.Limit, nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ns, nil
+}
+
+// ListRevisions returns a page of the revisions of a notebook without their
+// specs, the latest first.
+func (s *Service) ListRevisions(ctx context.Context, orgID platform.ID, id platform.ID, page nbsvc.Page) ([]*nbsvc.Revision, error) {
+	if err := page.Validate(); err != nil {
+		return nil, err
+	}
+
+	var rs []*nbsvc.Revision
+	err := s.kv.View(ctx, func(tx kv.Tx) error {
+		if _, err := findNotebook(tx, orgID, id); err != nil {
+			return err
+		}
+		prefix, err := id.Encode()
+		if err != nil {
+			return InternalNotebookServiceError(err)
+		}
+		return forEach(tx, revisionBucket, prefix, func(v []byte) error {
+			var r nbsvc.Revision
+			if err := json.Unmarshal(v, &r); err != nil {
+				return InternalNotebookServiceError(err)
+			}
+			r.Spec = nil
+			rs = append(rs, &r)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(rs, func(i, j int) bool { return rs[i].Revision > rs[j].Revision })
+	lo, hi := queryutil.PageBounds(len(rs), influxdb.FindOptions{Offset: page.Offset, Limit: page.Limit}, nil)
+	return rs[lo:hi], nil
+}
+
+// GetRevision returns a single revision of a notebook.
+func (s *Service) GetRevision(ctx context.Context, orgID platform.ID, id platform.ID, revision int) (*nbsvc.Revision, error) {
+	var r *nbsvc.Revision
+	err := s.kv.View(ctx, func(tx kv.Tx) error {
+		if _, err := findNotebook(tx, orgID, id); err != nil {
+			return err
+		}
+		var err error
+		r, err = findRevision(tx, id, revision)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// DiffRevisions returns the changes from a revision of a notebook to another.
+func (s *Service) DiffRevisions(ctx context.Context, orgID platform.ID, id platform.ID, from, to int) (*nbsvc.RevisionDiff, error) {
+	var a, b *nbsvc.Revision
+	err := s.kv.View(ctx, func(tx kv.Tx) error {
+		if _, err := findNotebook(tx, orgID, id); err != nil {
+			return err
+		}
+		var err error
+		if a, err = findRevision(tx, id, from); err != nil {
+			return err
+		}
+		b, err = findRevision(tx, id, to)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := nbsvc.Diff(a, b)
+	if err != nil {
+		return nil, InternalNotebookServiceError(err)
+	}
+	return d, nil
+}
+
+// CreateRun stores a run of a notebook, setting its ID, and deletes the
+// oldest runs of the notebook exceeding MaxRuns.
+func (s *Service) CreateRun(ctx context.Context, run *nbsvc.Run) error {
+	run.ID = s.IDGenerator.ID()
+	return s.kv.Update(ctx, func(tx kv.Tx) error {
+		if _, err := findNotebook(tx, run.OrgID, run.NotebookID); err != nil {
+			return err
+		}
+		key, err := runKey(run.NotebookID, run.ID)
+		if err != nil {
+			return err
+		}
+		meta := *run
+		meta.Cells = nil
+		if err := put(tx, runBucket, key, &meta); err != nil {
+			return err
+		}
+		cells := run.Cells
+		if cells == nil {
+			cells = []nbsvc.RunCell{}
+		}
+		if err := put(tx, runOutputBucket, key, cells); err != nil {
+			return err
+		}
+		return s.deleteOldRuns(tx, run.NotebookID)
+	})
+}
+
+// deleteOldRuns deletes the oldest runs of a notebook, with their outputs,
+// until it has MaxRuns runs.
+func (s *Service) deleteOldRuns(tx kv.Tx, id platform.ID) error {
+	if s.MaxRuns <= 0 {
+		return nil
+	}
+	prefix, err := id.Encode()
+	if err != nil {
+		return InternalNotebookServiceError(err)
+	}
+	// The IDs of runs increase with time, as do their keys.
+	keys, err := prefixKeys(tx, runBucket, prefix)
+	if err != nil || len(keys) <= s.MaxRuns {
+		return err
+	}
+	return deleteKeys(tx, keys[:len(keys)-s.MaxRuns], runBucket, runOutputBucket)
+}
+
+// ListRuns returns a page of the runs of a notebook without their cells, the
+// latest first.
+func (s *Service) ListRuns(ctx context.Context, orgID platform.ID, id platform.ID, page nbsvc.Page) ([]*nbsvc.Run, error) {
+	if err := page.Validate(); err != nil {
+		return nil, err
+	}
+
+	var runs []*nbsvc.Run
+	err := s.kv.View(ctx, func(tx kv.Tx) error {
+		if _, err := findNotebook(tx, orgID, id); err != nil {
+			return err
+		}
+		prefix, err := id.Encode()
+		if err != nil {
+			return InternalNotebookServiceError(err)
+		}
+		return forEach(tx, runBucket, prefix, func(v []byte) error {
+			var run nbsvc.Run
+			if err := json.Unmarshal(v, &run); err != nil {
+				return InternalNotebookServiceError(err)
+			}
+			runs = append(runs, &run)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The IDs of runs increase with time, as do their keys.
+	for i, j := 0, len(runs)-1; i < j; i, j = i+1, j-1 {
+		runs[i], runs[j] = runs[j], runs[i]
+	}
+	lo, hi := queryutil.PageBounds(len(runs), influxdb.FindOptions{Offset: page.Offset, Limit: page.Limit}, nil)
+	return runs[lo:hi], nil
+}
+
+// GetRun returns a single run of a notebook.
+func (s *Service) GetRun(ctx context.Context, orgID platform.ID, id platform.ID, runID platform.ID) (*nbsvc.Run, error) {
+	var run nbsvc.Run
+	err := s.kv.View(ctx, func(tx kv.Tx) error {
+		if _, err := findNotebook(tx, orgID, id); err != nil {
+			return err
+		}
+		key, err := runKey(id, runID)
+		if err != nil {
+			return err
+		}
+		if err := get(tx, runBucket, key, &run, nbsvc.ErrRunNotFound); err != nil {
+			return err
+		}
+		return get(tx, runOutputBucket, key, &run.Cells, nbsvc.ErrRunNotFound)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// findNotebook returns a notebook by ID. A notebook of another organization
+// is not found.
+func findNotebook(tx kv.Tx, orgID, id platform.ID) (*nbsvc.Notebook, error) {
+	key, err := id.Encode()
+	if err != nil {
+		return nil, &errors.Error{
+			Code: errors.EInvalid,
+			Err:  err,
+		}
+	}
+	var n nbsvc.Notebook
+	if err := get(tx, notebookBucket, key, &n, nbsvc.ErrNotebookNotFound); err != nil {
+		return nil, err
+	}
+	if n.OrgID != orgID {
+		return nil, nbsvc.ErrNotebookNotFound
+	}
+	return &n, nil
+}
+
+func putNotebook(tx kv.Tx, n *nbsvc.Notebook) error {
+	key, err := n.ID.Encode()
+	if err != nil {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Err:  err,
+		}
+	}
+	return put(tx, notebookBucket, key, n)
+}
+
+// notebookIndexKeys returns the keys of a notebook in the index of the
+// notebooks of its organization: the ID of the organization and its ID.
+func notebookIndexKeys(n *nbsvc.Notebook) ([]byte, []byte, error) {
+	orgKey, err := n.OrgID.Encode()
+	if err != nil {
+		return nil, nil, InternalNotebookServiceError(err)
+	}
+	key, err := n.ID.Encode()
+	if err != nil {
+		return nil, nil, InternalNotebookServiceError(err)
+	}
+	return orgKey, key, nil
+}
+
+func findRevision(tx kv.Tx, id platform.ID, revision int) (*nbsvc.Revision, error) {
+	if revision <= 0 {
+		return nil, nbsvc.ErrRevisionNotFound
+	}
+	key, err := revisionKey(id, revision)
+	if err != nil {
+		return nil, err
+	}
+	var r nbsvc.Revision
+	if err := get(tx, revisionBucket, key, &r, nbsvc.ErrRevisionNotFound); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func putRevision(tx kv.Tx, r *nbsvc.Revision) error {
+	key, err := revisionKey(r.NotebookID, r.Revision)
+	if err != nil {
+		return err
+	}
+	return put(tx, revisionBucket, key, r)
+}
+
+// revisionKey returns the key of a revision: the ID of its notebook and its
+// number, so that the revisions of a notebook sort in order.
+func revisionKey(id platform.ID, revision int) ([]byte, error) {
+	key, err := id.Encode()
+	if err != nil {
+		return nil, &errors.Error{
+			Code: errors.EInvalid,
+			Err:  err,
+		}
+	}
+	return append(key, uint32Bytes(uint32(revision))...), nil
+}
+
+// runKey returns the key of a run: the ID of its notebook and its ID.
+func runKey(id, runID platform.ID) ([]byte, error) {
+	key, err := id.Encode()
+	if err != nil {
+		return nil, &errors.Error{
+			Code: errors.EInvalid,
+			Err:  err,
+		}
+	}
+	rid, err := runID.Encode()
+	if err != nil {
+		return nil, &errors.Error{
+			Code: errors.EInvalid,
+			Err:  err,
+		}
+	}
+	return append(key, rid...), nil
+}
+
+func uint32Bytes(v uint32) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, v)
+	return b
+}
+
+func get(tx kv.Tx, bucket, key []byte, v interface{}, notFound error) error {
+	b, err := tx.Bucket(bucket)
+	if err != nil {
+		return InternalNotebookServiceError(err)
+	}
+	data, err := b.Get(key)
+	if kv.IsNotFound(err) {
+		return notFound
+	}
+	if err != nil {
+		return InternalNotebookServiceError(err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return InternalNotebookServiceError(err)
+	}
+	return nil
+}
+
+func put(tx kv.Tx, bucket, key []byte, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return InternalNotebookServiceError(err)
+	}
+	b, err := tx.Bucket(bucket)
+	if err != nil {
+		return InternalNotebookServiceError(err)
+	}
+	if err := b.Put(key, data); err != nil {
+		return InternalNotebookServiceError(err)
+	}
+	return nil
+}
+
+// forEach calls fn with the values of the keys of bucket starting with
+// prefix, in key order.
+func forEach(tx kv.Tx, bucket, prefix []byte, fn func(v []byte) error) error {
+	b, err := tx.Bucket(bucket)
+	if err != nil {
+		return InternalNotebookServiceError(err)
+	}
+	var opts []kv.CursorOption
+	if prefix != nil {
+		opts = append(opts, kv.WithCursorPrefix(prefix))
+	}
+	cur, err := b.ForwardCursor(prefix, opts...)
+	if err != nil {
+		return InternalNotebookServiceError(err)
+	}
+	defer cur.Close()
+
+	for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+		if err := fn(v); err != nil {
+			return err
+		}
+	}
+	return cur.Err()
+}
+
+// prefixKeys returns the keys of bucket starting with prefix, in key order.
+func prefixKeys(tx kv.Tx, bucket, prefix []byte) ([][]byte, error) {
+	b, err := tx.Bucket(bucket)
+	if err != nil {
+		return nil, InternalNotebookServiceError(err)
+	}
+	cur, err := b.ForwardCursor(prefix, kv.WithCursorPrefix(prefix))
+	if err != nil {
+		return nil, InternalNotebookServiceError(err)
+	}
+	defer cur.Close()
+
+	var keys [][]byte
+	for k, _ := cur.Next(); k != nil; k, _ = cur.Next() {
+		if !bytes.HasPrefix(k, prefix) {
+			break
+		}
+		keys = append(keys, append([]byte(nil), k...))
+	}
+	if err := cur.Err(); err != nil {
+		return nil, InternalNotebookServiceError(err)
+	}
+	return keys, nil
+}
+
+// deletePrefix deletes the keys of bucket starting with prefix.
+func deletePrefix(tx kv.Tx, bucket, prefix []byte) error {
+	keys, err := prefixKeys(tx, bucket, prefix)
+	if err != nil {
+		return err
+	}
+	return deleteKeys(tx, keys, bucket)
+}
+
+// deleteKeys deletes keys from each of buckets.
+func deleteKeys(tx kv.Tx, keys [][]byte, buckets ...[]byte) error {
+	for _, bucket := range buckets {
+		b, err := tx.Bucket(bucket)
+		if err != nil {
+			return InternalNotebookServiceError(err)
+		}
+		for _, k := range keys {
+			if err := b.Delete(k); err != nil {
+				return InternalNotebookServiceError(err)
+			}
+		}
+	}
+	return nil
+}
@@ -18,6 +18,22 @@ var (
 		Code: errors.EInvalid,
 		Msg:  "limit cannot be less-than or equal-to zero",
 	}
+	ErrNotebookNotFound = &errors.Error{
+		Code: errors.ENotFound,
+		Msg:  "notebook not found",
+	}
+	ErrRevisionNotFound = &errors.Error{
+		Code: errors.ENotFound,
+		Msg:  "notebook revision not found",
+	}
+	ErrRunNotFound = &errors.Error{
+		Code: errors.ENotFound,
+		Msg:  "notebook run not found",
+	}
+	ErrInvalidTimeRange = &errors.Error{
+		Code: errors.EInvalid,
+		Msg:  "start must be before stop",
+	}
 )
 
 func fieldRequiredError(field string) error {
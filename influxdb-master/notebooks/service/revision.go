@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/kit/platform"
+)
+
+// Revision is a version of the name and spec of a notebook. A revision is
+// recorded when a notebook is created and whenever an update changes it, and
+// revisions are numbered from 1 in the order they are recorded.
+type Revision struct {
+	NotebookID platform.ID  `json:"notebookID"`
+	Revision   int          `json:"revision"`
+	Name       string       `json:"name"`
+	Spec       NotebookSpec `json:"spec,omitempty"`
+	// RevertedFrom is the revision the notebook was reverted to when the
+	// revision was recorded by a revert.
+	RevertedFrom int       `json:"revertedFrom,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// RevisionService is the service contract for the revision history of
+// notebooks.
+type RevisionService interface {
+	// ListRevisions returns the revisions of a notebook without their
+	// specs, the latest first.
+	ListRevisions(ctx context.Context, orgID platform.ID, id platform.ID, page Page) ([]*Revision, error)
+	GetRevision(ctx context.Context, orgID platform.ID, id platform.ID, revision int) (*Revision, error)
+	DiffRevisions(ctx context.Context, orgID platform.ID, id platform.ID, from, to int) (*RevisionDiff, error)
+	// RevertNotebook sets the name and spec of a notebook to those of a
+	// revision, recording a new revision.
+	RevertNotebook(ctx context.Context, orgID platform.ID, id platform.ID, revision int) (*Notebook, error)
+}
+
+// Kinds of changes of a revision diff.
+const (
+	ChangeAdd     = "add"
+	ChangeRemove  = "remove"
+	ChangeReplace = "replace"
+)
+
+// RevisionDiff is the list of changes between two revisions of a notebook.
+type RevisionDiff struct {
+	NotebookID platform.ID `json:"notebookID"`
+	From       int         `json:"from"`
+	To         int         `json:"to"`
+	Changes    []Change    `json:"changes"`
+}
+
+// Change is the change of a value of a notebook. Path is the JSON pointer of
+// the value in the notebook, such as /name or /spec/pipes/0/queries/0/text.
+type Change struct {
+	Op   string      `json:"op"`
+	Path string      `json:"path"`
+	From interface{} `json:"from,omitempty"`
+	To   interface{} `json:"to,omitempty"`
+}
+
+// Diff returns the changes from a revision to another, ordered by path. The
+// elements of arrays are compared by index.
+func Diff(from, to *Revision) (*RevisionDiff, error) {
+	a, err := revisionValue(from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := revisionValue(to)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &RevisionDiff{
+		NotebookID: to.NotebookID,
+		From:       from.Revision,
+		To:         to.Revision,
+		Changes:    []Change{},
+	}
+	diffValues(&d.Changes, "", a, b)
+	return d, nil
+}
+
+// revisionValue returns the JSON value of the name and spec of a revision,
+// so that values of specs built in Go compare equal to those decoded from
+// JSON.
+func revisionValue(r *Revision) (interface{}, error) {
+	b, err := json.Marshal(map[string]interface{}{
+		"name": r.Name,
+		"spec": r.Spec,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func diffValues(changes *[]Change, path string, a, b interface{}) {
+	switch a := a.(type) {
+	case map[string]interface{}:
+		if b, ok := b.(map[string]interface{}); ok {
+			keys := make([]string, 0, len(a)+len(b))
+			for k := range a {
+				keys = append(keys, k)
+			}
+			for k := range b {
+				if _, ok := a[k]; !ok {
+					keys = append(keys, k)
+				}
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				p := path + "/" + escapePointer(k)
+				av, inA := a[k]
+				bv, inB := b[k]
+				switch {
+				case !inA:
+					*changes = append(*changes, Change{Op: ChangeAdd, Path: p, To: bv})
+				case !inB:
+					*changes = append(*changes, Change{Op: ChangeRemove, Path: p, From: av})
+				default:
+					diffValues(changes, p, av, bv)
+				}
+			}
+			return
+		}
+	case []interface{}:
+		if b, ok := b.([]interface{}); ok {
+			for i := 0; i < len(a) || i < len(b); i++ {
+				p := fmt.Sprintf("%s/%d", path, i)
+				switch {
+				case i >= len(a):
+					*changes = append(*changes, Change{Op: ChangeAdd, Path: p, To: b[i]})
+				case i >= len(b):
+					*changes = append(*changes, Change{Op: ChangeRemove, Path: p, From: a[i]})
+				default:
+					diffValues(changes, p, a[i], b[i])
+				}
+			}
+			return
+		}
+	}
+	if !reflect.DeepEqual(a, b) {
+		*changes = append(*changes, Change{Op: ChangeReplace, Path: path, From: a, To: b})
+	}
+}
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// escapePointer escapes a key for a JSON pointer, as per RFC 6901.
+func escapePointer(k string) string {
+	return pointerEscaper.Replace(k)
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	from := &Revision{
+		NotebookID: 1,
+		Revision:   1,
+		Name:       "orbit",
+		Spec: NotebookSpec{
+			"pipes": []interface{}{
+				map[string]interface{}{"type": "query", "title": "altitude"},
+				map[string]interface{}{"type": "markdown"},
+			},
+			"range":    map[string]interface{}{"seconds": 3600},
+			"readOnly": false,
+		},
+	}
+	to := &Revision{
+		NotebookID: 1,
+		Revision:   3,
+		Name:       "orbit decay",
+		Spec: NotebookSpec{
+			"pipes": []interface{}{
+				map[string]interface{}{"type": "query", "title": "perigee"},
+			},
+			"range": map[string]interface{}{"seconds": 3600},
+			"a/b~":  true,
+		},
+	}
+
+	d, err := Diff(from, to)
+	require.NoError(t, err)
+	require.Equal(t, 1, d.From)
+	require.Equal(t, 3, d.To)
+	require.Equal(t, []Change{
+		{Op: ChangeReplace, Path: "/name", From: "orbit", To: "orbit decay"},
+		{Op: ChangeAdd, Path: "/spec/a~1b~0", To: true},
+		{Op: ChangeReplace, Path: "/spec/pipes/0/title", From: "altitude", To: "perigee"},
+		{Op: ChangeRemove, Path: "/spec/pipes/1", From: map[string]interface{}{"type": "markdown"}},
+		{Op: ChangeRemove, Path: "/spec/readOnly", From: false},
+	}, d.Changes)
+
+	d, err = Diff(from, from)
+	require.NoError(t, err)
+	require.Empty(t, d.Changes)
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/kit/platform"
+)
+
+// Run statuses.
+const (
+	RunSuccess = "success"
+	RunFailed  = "failed"
+)
+
+// Run is an execution of the query cells of a revision of a notebook on the
+// server, with the outputs of its cells. The time range of a run is
+// recorded as absolute times, so that a run can be repeated with the same
+// revision and time range.
+type Run struct {
+	ID         platform.ID `json:"id"`
+	OrgID      platform.ID `json:"orgID"`
+	NotebookID platform.ID `json:"notebookID"`
+	Revision   int         `json:"revision"`
+	Start      time.Time   `json:"start"`
+	Stop       time.Time   `json:"stop"`
+	// Status is failed when the query of any cell failed.
+	Status     string    `json:"status"`
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt"`
+	Cells      []RunCell `json:"cells,omitempty"`
+}
+
+// RunCell is the output of a query cell of a run.
+type RunCell struct {
+	// Pipe is the index of the cell in the pipes of the notebook spec.
+	Pipe  int    `json:"pipe"`
+	Title string `json:"title,omitempty"`
+	Query string `json:"query"`
+	// CSV is the annotated CSV of the result of the query.
+	CSV   string `json:"csv,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// RunRequest is a request to run a notebook.
+type RunRequest struct {
+	// Revision is the revision to run, the current revision when zero.
+	Revision int `json:"revision,omitempty"`
+	// Start and Stop override the time range of the notebook spec.
+	Start *time.Time `json:"start,omitempty"`
+	Stop  *time.Time `json:"stop,omitempty"`
+}
+
+// Validate validates the run request.
+func (r RunRequest) Validate() error {
+	if r.Revision < 0 {
+		return ErrRevisionNotFound
+	}
+	if r.Start != nil && r.Stop != nil && !r.Start.Before(*r.Stop) {
+		return ErrInvalidTimeRange
+	}
+	return nil
+}
+
+// RunService is the service contract for running notebooks on the server.
+type RunService interface {
+	// RunNotebook executes the query cells of a notebook in order, and
+	// records their outputs in a run. The failure of a query is recorded in
+	// its cell rather than failing the run.
+	RunNotebook(ctx context.Context, orgID platform.ID, id platform.ID, req RunRequest) (*Run, error)
+	// ListRuns returns the runs of a notebook without their cells, the
+	// latest first.
+	ListRuns(ctx context.Context, orgID platform.ID, id platform.ID, page Page) ([]*Run, error)
+	GetRun(ctx context.Context, orgID platform.ID, id platform.ID, runID platform.ID) (*Run, error)
+}
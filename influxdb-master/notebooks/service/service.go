@@ -13,6 +13,7 @@ type Notebook struct {
 	ID        platform.ID  `json:"id"`
 	Name      string       `json:"name"`
 	Spec      NotebookSpec `json:"spec"`
+	Revision  int          `json:"revision"`
 	CreatedAt time.Time    `json:"createdAt"`
 	UpdatedAt time.Time    `json:"updatedAt"`
 }
@@ -0,0 +1,214 @@
+package notebooks_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/inmem"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/kv/migration/all"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/notebooks"
+	nbsvc "github.com/influxdata/influxdb/v2/notebooks/service"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+var now = time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestService(t *testing.T) *notebooks.Service {
+	t.Helper()
+
+	s := inmem.NewKVStore()
+	require.NoError(t, all.Up(context.Background(), zaptest.NewLogger(t), s))
+
+	svc := notebooks.NewService(s)
+	svc.IDGenerator = mock.NewMockIDGenerator()
+	svc.TimeGenerator = mock.TimeGenerator{FakeValue: now}
+	return svc
+}
+
+func querySpec(text string) nbsvc.NotebookSpec {
+	return nbsvc.NotebookSpec{
+		"pipes": []interface{}{
+			map[string]interface{}{
+				"type":        "query",
+				"title":       "Query",
+				"activeQuery": 0,
+				"queries":     []interface{}{map[string]interface{}{"text": text}},
+			},
+		},
+	}
+}
+
+func TestService_Revisions(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t)
+
+	n, err := svc.CreateNotebook(ctx, nbsvc.NotebookCreate{OrgID: 1, Name: "anomaly", Spec: querySpec("a")})
+	require.NoError(t, err)
+	require.Equal(t, 1, n.Revision)
+
+	// An update changing nothing records no revision.
+	n, err = svc.UpdateNotebook(ctx, 1, n.ID, nbsvc.NotebookUpdate{Name: "anomaly", Spec: querySpec("a")})
+	require.NoError(t, err)
+	require.Equal(t, 1, n.Revision)
+
+	n, err = svc.UpdateNotebook(ctx, 1, n.ID, nbsvc.NotebookUpdate{Name: "anomaly", Spec: querySpec("b")})
+	require.NoError(t, err)
+	require.Equal(t, 2, n.Revision)
+
+	revs, err := svc.ListRevisions(ctx, 1, n.ID, nbsvc.Page{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, revs, 2)
+	require.Equal(t, 2, revs[0].Revision)
+	require.Nil(t, revs[0].Spec)
+
+	d, err := svc.DiffRevisions(ctx, 1, n.ID, 1, 2)
+	require.NoError(t, err)
+	require.Equal(t, []nbsvc.Change{
+		{Op: nbsvc.ChangeReplace, Path: "/spec/pipes/0/queries/0/text", From: "a", To: "b"},
+	}, d.Changes)
+
+	n, err = svc.RevertNotebook(ctx, 1, n.ID, 1)
+	require.NoError(t, err)
+	require.Equal(t, 3, n.Revision)
+	rev, err := svc.GetRevision(ctx, 1, n.ID, 3)
+	require.NoError(t, err)
+	require.Equal(t, 1, rev.RevertedFrom)
+	d, err = svc.DiffRevisions(ctx, 1, n.ID, 1, 3)
+	require.NoError(t, err)
+	require.Empty(t, d.Changes)
+
+	_, err = svc.GetRevision(ctx, 1, n.ID, 4)
+	require.Equal(t, errors.ENotFound, errors.ErrorCode(err))
+	_, err = svc.ListRevisions(ctx, 2, n.ID, nbsvc.Page{Limit: 10})
+	require.Equal(t, errors.ENotFound, errors.ErrorCode(err), "revisions of a notebook of another organization")
+
+	require.NoError(t, svc.CreateRun(ctx, &nbsvc.Run{OrgID: 1, NotebookID: n.ID, Revision: 3}))
+	require.NoError(t, svc.DeleteNotebook(ctx, 1, n.ID))
+	_, err = svc.GetNotebook(ctx, 1, n.ID)
+	require.Equal(t, errors.ENotFound, errors.ErrorCode(err))
+
+	// The revisions and runs of the deleted notebook are gone with it.
+	other, err := svc.CreateNotebook(ctx, nbsvc.NotebookCreate{OrgID: 1, Name: "other"})
+	require.NoError(t, err)
+	revs, err = svc.ListRevisions(ctx, 1, other.ID, nbsvc.Page{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, revs, 1)
+}
+
+func TestService_ListNotebooks(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t)
+
+	var ids []platform.ID
+	for _, create := range []nbsvc.NotebookCreate{
+		{OrgID: 1, Name: "a"},
+		{OrgID: 2, Name: "b"},
+		{OrgID: 1, Name: "c"},
+		{OrgID: 1, Name: "d"},
+	} {
+		n, err := svc.CreateNotebook(ctx, create)
+		require.NoError(t, err)
+		if n.OrgID == 1 {
+			ids = append(ids, n.ID)
+		}
+	}
+
+	names := func(ns []*nbsvc.Notebook) []string {
+		var names []string
+		for _, n := range ns {
+			names = append(names, n.Name)
+		}
+		return names
+	}
+	ns, err := svc.ListNotebooks(ctx, nbsvc.NotebookListFilter{OrgID: 1, Page: nbsvc.Page{Limit: 10}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "c", "d"}, names(ns))
+	ns, err = svc.ListNotebooks(ctx, nbsvc.NotebookListFilter{OrgID: 1, Page: nbsvc.Page{Offset: 1, Limit: 1}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"c"}, names(ns))
+
+	// A deleted notebook is removed from the index of its organization.
+	require.NoError(t, svc.DeleteNotebook(ctx, 1, ids[1]))
+	ns, err = svc.ListNotebooks(ctx, nbsvc.NotebookListFilter{OrgID: 1, Page: nbsvc.Page{Limit: 10}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "d"}, names(ns))
+	ns, err = svc.ListNotebooks(ctx, nbsvc.NotebookListFilter{OrgID: 3, Page: nbsvc.Page{Limit: 10}})
+	require.NoError(t, err)
+	require.Empty(t, ns)
+}
+
+func TestService_Runs(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t)
+	svc.MaxRuns = 2
+
+	n, err := svc.CreateNotebook(ctx, nbsvc.NotebookCreate{OrgID: 1, Name: "anomaly", Spec: querySpec("a")})
+	require.NoError(t, err)
+
+	var runs []*nbsvc.Run
+	for i := 0; i < 3; i++ {
+		run := &nbsvc.Run{
+			OrgID:      1,
+			NotebookID: n.ID,
+			Revision:   1,
+			Cells:      []nbsvc.RunCell{{Query: "a", CSV: fmt.Sprintf("#run %d", i)}},
+		}
+		require.NoError(t, svc.CreateRun(ctx, run))
+		require.Len(t, run.Cells, 1, "the cells of the created run are kept")
+		runs = append(runs, run)
+	}
+
+	// The oldest run exceeding MaxRuns is deleted with its outputs.
+	_, err = svc.GetRun(ctx, 1, n.ID, runs[0].ID)
+	require.Equal(t, errors.ENotFound, errors.ErrorCode(err))
+
+	listed, err := svc.ListRuns(ctx, 1, n.ID, nbsvc.Page{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, listed, 2)
+	require.Equal(t, runs[2].ID, listed[0].ID)
+	require.Equal(t, runs[1].ID, listed[1].ID)
+	require.Nil(t, listed[0].Cells)
+
+	got, err := svc.GetRun(ctx, 1, n.ID, runs[2].ID)
+	require.NoError(t, err)
+	require.Equal(t, []nbsvc.RunCell{{Query: "a", CSV: "#run 2"}}, got.Cells)
+}
+
+func TestAuthorizedService(t *testing.T) {
+	store := newTestService(t)
+	svc := notebooks.NewAuthorizedService(store, store)
+	runs := notebooks.NewAuthorizedRunService(notebooks.NewRunner(store, store, nil))
+
+	orgID := platform.ID(1)
+	readOnly := icontext.SetAuthorizer(context.Background(), mock.NewMockAuthorizer(false, []influxdb.Permission{
+		{Action: influxdb.ReadAction, Resource: influxdb.Resource{Type: influxdb.NotebooksResourceType, OrgID: &orgID}},
+	}))
+	readWrite := icontext.SetAuthorizer(context.Background(), mock.NewMockAuthorizer(false, []influxdb.Permission{
+		{Action: influxdb.ReadAction, Resource: influxdb.Resource{Type: influxdb.NotebooksResourceType, OrgID: &orgID}},
+		{Action: influxdb.WriteAction, Resource: influxdb.Resource{Type: influxdb.NotebooksResourceType, OrgID: &orgID}},
+	}))
+
+	_, err := svc.CreateNotebook(readOnly, nbsvc.NotebookCreate{OrgID: orgID, Name: "anomaly"})
+	require.Equal(t, errors.EUnauthorized, errors.ErrorCode(err))
+	n, err := svc.CreateNotebook(readWrite, nbsvc.NotebookCreate{OrgID: orgID, Name: "anomaly"})
+	require.NoError(t, err)
+
+	_, err = svc.ListRevisions(readOnly, orgID, n.ID, nbsvc.Page{Limit: 10})
+	require.NoError(t, err)
+	_, err = svc.RevertNotebook(readOnly, orgID, n.ID, 1)
+	require.Equal(t, errors.EUnauthorized, errors.ErrorCode(err))
+	_, err = runs.RunNotebook(readOnly, orgID, n.ID, nbsvc.RunRequest{})
+	require.Equal(t, errors.EUnauthorized, errors.ErrorCode(err))
+	_, err = runs.ListRuns(readOnly, orgID, n.ID, nbsvc.Page{Limit: 10})
+	require.NoError(t, err)
+	_, err = svc.ListNotebooks(readOnly, nbsvc.NotebookListFilter{OrgID: 2, Page: nbsvc.Page{Limit: 10}})
+	require.Equal(t, errors.EUnauthorized, errors.ErrorCode(err))
+}
@@ -1,12 +1,15 @@
 package transport
 
 import (
+	"bytes"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"strconv"
 
 	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
+	"github.com/influxdata/influxdb/v2"
 	feature "github.com/influxdata/influxdb/v2/kit/feature"
 	"github.com/influxdata/influxdb/v2/kit/platform"
 	"github.com/influxdata/influxdb/v2/kit/platform/errors"
@@ -27,12 +30,19 @@ type NotebookHandler struct {
 
 	api *kithttp.API
 	log *zap.Logger
+
+	notebookService notebooks.NotebookService
+	revisionService notebooks.RevisionService
+	runService      notebooks.RunService
 }
 
-func NewNotebookHandler(log *zap.Logger) *NotebookHandler {
+func NewNotebookHandler(log *zap.Logger, notebookService notebooks.NotebookService, revisionService notebooks.RevisionService, runService notebooks.RunService) *NotebookHandler {
 	h := &NotebookHandler{
-		log: log,
-		api: kithttp.NewAPI(kithttp.WithLog(log)),
+		log:             log,
+		api:             kithttp.NewAPI(kithttp.WithLog(log)),
+		notebookService: notebookService,
+		revisionService: revisionService,
+		runService:      runService,
 	}
 
 	r := chi.NewRouter()
@@ -51,6 +61,19 @@ func NewNotebookHandler(log *zap.Logger) *NotebookHandler {
 			r.Get("/", h.handleGetNotebook)
 			r.Patch("/", h.handlePatchNotebook)
 			r.Delete("/", h.handleDeleteNotebook)
+
+			r.Get("/diff", h.handleDiffRevisions)
+			r.Route("/revisions", func(r chi.Router) {
+				r.Get("/", h.handleGetRevisions)
+				r.Get("/{revision}", h.handleGetRevision)
+				r.Post("/{revision}/revert", h.handleRevertNotebook)
+			})
+			r.Route("/runs", func(r chi.Router) {
+				r.Get("/", h.handleGetRuns)
+				r.Post("/", h.handleRunNotebook)
+				r.Get("/{runID}", h.handleGetRun)
+				r.Post("/{runID}/rerun", h.handleRerunNotebook)
+			})
 		})
 	})
 
@@ -80,6 +103,12 @@ func (h *NotebookHandler) notebookFlag(next http.Handler) http.Handler {
 	return http.HandlerFunc(fn)
 }
 
+// notebookRequest is the body of requests creating or updating a notebook.
+type notebookRequest struct {
+	Name string                 `json:"name"`
+	Spec notebooks.NotebookSpec `json:"spec"`
+}
+
 // get a list of all notebooks for an org
 func (h *NotebookHandler) handleGetNotebooks(w http.ResponseWriter, r *http.Request) {
 	orgID, err := getIDfromReq(r, "orgID")
@@ -87,12 +116,25 @@ func (h *NotebookHandler) handleGetNotebooks(w http.ResponseWriter, r *http.Requ
 		h.api.Err(w, r, err)
 		return
 	}
+	page, err := getPageFromReq(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
 
-	// Demo data - for development purposes.
-	d := map[string][]notebooks.Notebook{}
-	d["flows"] = demoNotebooks(3, *orgID)
+	ns, err := h.notebookService.ListNotebooks(r.Context(), notebooks.NotebookListFilter{
+		OrgID: *orgID,
+		Page:  page,
+	})
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	if ns == nil {
+		ns = []*notebooks.Notebook{}
+	}
 
-	h.api.Respond(w, r, http.StatusOK, d)
+	h.api.Respond(w, r, http.StatusOK, map[string][]*notebooks.Notebook{"flows": ns})
 }
 
 // create a single notebook
@@ -103,76 +145,77 @@ func (h *NotebookHandler) handleCreateNotebook(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	// Demo data - just return the body from the request with a generated ID
-	b := notebooks.Notebook{}
+	var b notebookRequest
 	if err := h.api.DecodeJSON(r.Body, &b); err != nil {
 		h.api.Err(w, r, err)
 		return
 	}
-	b.OrgID = *orgID                                                   // this isn't necessary with the demo data, but keeping it here for future
-	id, _ := platform.IDFromString(strconv.Itoa(1000000000000000 + 1)) // give it an ID from the getNotebooks list so that the UI doesn't break
-	b.ID = *id
 
-	h.api.Respond(w, r, http.StatusOK, b)
+	n, err := h.notebookService.CreateNotebook(r.Context(), notebooks.NotebookCreate{
+		OrgID: *orgID,
+		Name:  b.Name,
+		Spec:  b.Spec,
+	})
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	h.api.Respond(w, r, http.StatusOK, n)
 }
 
 // get a single notebook
 func (h *NotebookHandler) handleGetNotebook(w http.ResponseWriter, r *http.Request) {
-	orgID, err := getIDfromReq(r, "orgID")
+	orgID, notebookID, err := getNotebookIDsFromReq(r)
 	if err != nil {
 		h.api.Err(w, r, err)
 		return
 	}
 
-	notebookID, err := getIDfromReq(r, "id")
+	n, err := h.notebookService.GetNotebook(r.Context(), orgID, notebookID)
 	if err != nil {
 		h.api.Err(w, r, err)
 		return
 	}
 
-	// Demo data - for development purposes.
-	d := demoNotebook(*orgID, *notebookID)
-
-	h.api.Respond(w, r, http.StatusOK, d)
+	h.api.Respond(w, r, http.StatusOK, n)
 }
 
 // update a single notebook
 func (h *NotebookHandler) handlePatchNotebook(w http.ResponseWriter, r *http.Request) {
-	orgID, err := getIDfromReq(r, "orgID")
+	orgID, notebookID, err := getNotebookIDsFromReq(r)
 	if err != nil {
 		h.api.Err(w, r, err)
 		return
 	}
 
-	id, err := getIDfromReq(r, "id")
-	if err != nil {
+	var b notebookRequest
+	if err := h.api.DecodeJSON(r.Body, &b); err != nil {
 		h.api.Err(w, r, err)
 		return
 	}
 
-	// Demo data - just return the body from the request with a generated ID
-	b := notebooks.Notebook{}
-	if err := h.api.DecodeJSON(r.Body, &b); err != nil {
+	n, err := h.notebookService.UpdateNotebook(r.Context(), orgID, notebookID, notebooks.NotebookUpdate{
+		Name: b.Name,
+		Spec: b.Spec,
+	})
+	if err != nil {
 		h.api.Err(w, r, err)
 		return
 	}
-	b.OrgID = *orgID // this isn't necessary with the demo data, but keeping it here for future
-	b.ID = *id       // ditto
 
-	h.api.Respond(w, r, http.StatusOK, b)
+	h.api.Respond(w, r, http.StatusOK, n)
 }
 
-// delete a single notebook
-// for now, just respond with 200 unless there is a problem with the orgID or notebook ID
+// delete a single notebook with its revisions and runs
 func (h *NotebookHandler) handleDeleteNotebook(w http.ResponseWriter, r *http.Request) {
-	_, err := getIDfromReq(r, "orgID")
+	orgID, notebookID, err := getNotebookIDsFromReq(r)
 	if err != nil {
 		h.api.Err(w, r, err)
 		return
 	}
 
-	_, err = getIDfromReq(r, "id")
-	if err != nil {
+	if err := h.notebookService.DeleteNotebook(r.Context(), orgID, notebookID); err != nil {
 		h.api.Err(w, r, err)
 		return
 	}
@@ -180,6 +223,226 @@ func (h *NotebookHandler) handleDeleteNotebook(w http.ResponseWriter, r *http.Re
 	h.api.Respond(w, r, http.StatusOK, nil)
 }
 
+// get a list of the revisions of a notebook, the latest first
+func (h *NotebookHandler) handleGetRevisions(w http.ResponseWriter, r *http.Request) {
+	orgID, notebookID, err := getNotebookIDsFromReq(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	page, err := getPageFromReq(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	revs, err := h.revisionService.ListRevisions(r.Context(), orgID, notebookID, page)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	if revs == nil {
+		revs = []*notebooks.Revision{}
+	}
+
+	h.api.Respond(w, r, http.StatusOK, map[string][]*notebooks.Revision{"revisions": revs})
+}
+
+// get a single revision of a notebook
+func (h *NotebookHandler) handleGetRevision(w http.ResponseWriter, r *http.Request) {
+	orgID, notebookID, err := getNotebookIDsFromReq(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	revision, err := getRevisionFromParam(chi.URLParam(r, "revision"), "revision")
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	rev, err := h.revisionService.GetRevision(r.Context(), orgID, notebookID, revision)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	h.api.Respond(w, r, http.StatusOK, rev)
+}
+
+// revert a notebook to one of its revisions, recording a new revision
+func (h *NotebookHandler) handleRevertNotebook(w http.ResponseWriter, r *http.Request) {
+	orgID, notebookID, err := getNotebookIDsFromReq(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	revision, err := getRevisionFromParam(chi.URLParam(r, "revision"), "revision")
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	n, err := h.revisionService.RevertNotebook(r.Context(), orgID, notebookID, revision)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	h.api.Respond(w, r, http.StatusOK, n)
+}
+
+// get the changes from a revision of a notebook to another, the current
+// revision when the to parameter is missing
+func (h *NotebookHandler) handleDiffRevisions(w http.ResponseWriter, r *http.Request) {
+	orgID, notebookID, err := getNotebookIDsFromReq(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	qp := r.URL.Query()
+	from, err := getRevisionFromParam(qp.Get("from"), "from")
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	var to int
+	if qp.Get("to") != "" {
+		if to, err = getRevisionFromParam(qp.Get("to"), "to"); err != nil {
+			h.api.Err(w, r, err)
+			return
+		}
+	} else {
+		n, err := h.notebookService.GetNotebook(r.Context(), orgID, notebookID)
+		if err != nil {
+			h.api.Err(w, r, err)
+			return
+		}
+		to = n.Revision
+	}
+
+	d, err := h.revisionService.DiffRevisions(r.Context(), orgID, notebookID, from, to)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	h.api.Respond(w, r, http.StatusOK, d)
+}
+
+// get a list of the runs of a notebook without their outputs, the latest first
+func (h *NotebookHandler) handleGetRuns(w http.ResponseWriter, r *http.Request) {
+	orgID, notebookID, err := getNotebookIDsFromReq(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	page, err := getPageFromReq(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	runs, err := h.runService.ListRuns(r.Context(), orgID, notebookID, page)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	if runs == nil {
+		runs = []*notebooks.Run{}
+	}
+
+	h.api.Respond(w, r, http.StatusOK, map[string][]*notebooks.Run{"runs": runs})
+}
+
+// run the query cells of a notebook on the server. The body is optional, and
+// selects the revision and overrides the time range of the run.
+func (h *NotebookHandler) handleRunNotebook(w http.ResponseWriter, r *http.Request) {
+	orgID, notebookID, err := getNotebookIDsFromReq(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		h.api.Err(w, r, &errors.Error{
+			Code: errors.EInvalid,
+			Err:  err,
+		})
+		return
+	}
+	var req notebooks.RunRequest
+	if len(bytes.TrimSpace(body)) > 0 {
+		if err := h.api.DecodeJSON(bytes.NewReader(body), &req); err != nil {
+			h.api.Err(w, r, err)
+			return
+		}
+	}
+
+	run, err := h.runService.RunNotebook(r.Context(), orgID, notebookID, req)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	h.api.Respond(w, r, http.StatusCreated, run)
+}
+
+// get a single run of a notebook with the outputs of its cells
+func (h *NotebookHandler) handleGetRun(w http.ResponseWriter, r *http.Request) {
+	orgID, notebookID, err := getNotebookIDsFromReq(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	runID, err := getIDfromReq(r, "runID")
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	run, err := h.runService.GetRun(r.Context(), orgID, notebookID, *runID)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	h.api.Respond(w, r, http.StatusOK, run)
+}
+
+// run a notebook again with the revision and time range of one of its runs
+func (h *NotebookHandler) handleRerunNotebook(w http.ResponseWriter, r *http.Request) {
+	orgID, notebookID, err := getNotebookIDsFromReq(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	runID, err := getIDfromReq(r, "runID")
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	prev, err := h.runService.GetRun(r.Context(), orgID, notebookID, *runID)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	run, err := h.runService.RunNotebook(r.Context(), orgID, notebookID, notebooks.RunRequest{
+		Revision: prev.Revision,
+		Start:    &prev.Start,
+		Stop:     &prev.Stop,
+	})
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	h.api.Respond(w, r, http.StatusCreated, run)
+}
+
 func getIDfromReq(r *http.Request, param string) (*platform.ID, error) {
 	id := chi.URLParam(r, param)
 	if id == "" {
@@ -199,3 +462,43 @@ func getIDfromReq(r *http.Request, param string) (*platform.ID, error) {
 
 	return &i, nil
 }
+
+func getNotebookIDsFromReq(r *http.Request) (platform.ID, platform.ID, error) {
+	orgID, err := getIDfromReq(r, "orgID")
+	if err != nil {
+		return 0, 0, err
+	}
+	id, err := getIDfromReq(r, "id")
+	if err != nil {
+		return 0, 0, err
+	}
+	return *orgID, *id, nil
+}
+
+func getRevisionFromParam(v, param string) (int, error) {
+	if v == "" {
+		return 0, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  fmt.Sprintf(errMissingParam, param),
+		}
+	}
+	revision, err := strconv.Atoi(v)
+	if err != nil || revision <= 0 {
+		return 0, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  fmt.Sprintf(errInvalidParam, param),
+		}
+	}
+	return revision, nil
+}
+
+func getPageFromReq(r *http.Request) (notebooks.Page, error) {
+	opts, err := influxdb.DecodeFindOptions(r)
+	if err != nil {
+		return notebooks.Page{}, err
+	}
+	return notebooks.Page{
+		Offset: opts.Offset,
+		Limit:  opts.Limit,
+	}, nil
+}
@@ -0,0 +1,525 @@
+package check
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/notification"
+	"github.com/influxdata/influxdb/v2/notification/flux"
+	"github.com/influxdata/influxdb/v2/query"
+	"github.com/influxdata/influxdb/v2/query/fluxlang"
+)
+
+var _ influxdb.Check = (*Anomaly)(nil)
+
+// Methods of the anomaly check to learn the baseline of a window.
+const (
+	// AnomalyZScore scores a window by the number of standard deviations
+	// it is from the mean of the windows of the baseline.
+	AnomalyZScore = "zscore"
+	// AnomalyMAD scores a window by the number of median absolute deviations
+	// it is from the median of the windows at the same point of the previous
+	// periods of the baseline.
+	AnomalyMAD = "mad"
+	// AnomalyPeriod scores a window by its difference from the window one
+	// period earlier.
+	AnomalyPeriod = "period"
+)
+
+// Anomaly is the anomaly check. Each run scores the latest window of the
+// query against a baseline learned from the windows preceding it, and
+// records the level of the thresholds exceeded by the absolute score.
+type Anomaly struct {
+	Base
+	Method string `json:"method"`
+	// Baseline is the duration of the windows learned by the zscore and mad methods.
+	Baseline *notification.Duration `json:"baseline,omitempty"`
+	// Period is the duration of a season for the mad and period methods.
+	Period     *notification.Duration `json:"period,omitempty"`
+	Thresholds []AnomalyThreshold     `json:"thresholds"`
+}
+
+// AnomalyThreshold is the level of a window whose absolute score is greater
+// than Value.
+type AnomalyThreshold struct {
+	Level notification.CheckLevel `json:"level"`
+	Value float64                 `json:"value"`
+}
+
+// Type returns the type of the check.
+func (c Anomaly) Type() string {
+	return "anomaly"
+}
+
+// Valid returns error if something is invalid.
+func (c Anomaly) Valid(lang fluxlang.FluxLanguageService) error {
+	if err := c.Base.Valid(lang); err != nil {
+		return err
+	}
+	switch c.Method {
+	case AnomalyZScore, AnomalyMAD, AnomalyPeriod:
+	default:
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  fmt.Sprintf("Anomaly Method must be 1 of [%s, %s, %s]; got %q", AnomalyZScore, AnomalyMAD, AnomalyPeriod, c.Method),
+		}
+	}
+
+	every := c.Every.TimeDuration()
+	if c.Method != AnomalyPeriod {
+		if c.Baseline == nil || len(c.Baseline.Values) == 0 {
+			return &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  fmt.Sprintf("Anomaly Baseline must exist for the %s method", c.Method),
+			}
+		}
+		if c.Baseline.TimeDuration() <= every {
+			return &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "Anomaly Baseline should be greater than the interval",
+			}
+		}
+	}
+	if c.Method != AnomalyZScore {
+		if c.Period == nil || len(c.Period.Values) == 0 {
+			return &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  fmt.Sprintf("Anomaly Period must exist for the %s method", c.Method),
+			}
+		}
+		if c.Period.TimeDuration() <= every {
+			return &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "Anomaly Period should be greater than the interval",
+			}
+		}
+	}
+	if c.Method == AnomalyMAD && c.Baseline.TimeDuration() < c.Period.TimeDuration() {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "Anomaly Baseline should not be less than the period",
+		}
+	}
+
+	if len(c.Thresholds) == 0 {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "Anomaly check must have at least one threshold",
+		}
+	}
+	levels := make(map[notification.CheckLevel]bool, len(c.Thresholds))
+	for _, th := range c.Thresholds {
+		if th.Level == notification.Unknown {
+			return &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "Anomaly threshold level must be 1 of [CRIT, WARN, INFO, OK]",
+			}
+		}
+		if levels[th.Level] {
+			return &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  fmt.Sprintf("Anomaly threshold level %s is duplicated", th.Level),
+			}
+		}
+		levels[th.Level] = true
+		if th.Value < 0 {
+			return &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "Anomaly threshold value can't be negative",
+			}
+		}
+	}
+	return nil
+}
+
+// GenerateFlux returns a flux script for the anomaly check provided. If there
+// are any errors in the flux that the user provided the function will return
+// an error for each error found when the script is parsed.
+func (c Anomaly) GenerateFlux(lang fluxlang.FluxLanguageService) (string, error) {
+	p, err := c.GenerateFluxAST(lang)
+	if err != nil {
+		return "", err
+	}
+
+	return ast.Format(p), nil
+}
+
+// GenerateFluxAST returns a flux AST for the anomaly check provided. The
+// query is ranged over the baseline, or the period for the period method,
+// and the window scored. If there are any errors in the flux that the user
+// provided the function will return an error for each error found when the
+// script is parsed.
+func (c Anomaly) GenerateFluxAST(lang fluxlang.FluxLanguageService) (*ast.Package, error) {
+	if err := c.validDurations(); err != nil {
+		return nil, err
+	}
+
+	p, err := query.Parse(lang, c.Query.Text)
+	if p == nil {
+		return nil, err
+	}
+	replaceDurationsWithEvery(p, c.Every)
+	replaceStartWithLookback(p, c.lookback())
+	removeStopFromRange(p)
+	addCreateEmptyFalseToAggregateWindow(p)
+
+	if errs := ast.GetErrors(p); len(errs) != 0 {
+		return nil, multiError(errs)
+	}
+
+	// TODO(desa): this is a hack that we had to do as a result of https://github.com/influxdata/flux/issues/1701
+	// when it is fixed we should use a separate file and not manipulate the existing one.
+	if len(p.Files) != 1 {
+		return nil, fmt.Errorf("expect a single file to be returned from query parsing got %d", len(p.Files))
+	}
+
+	fields := getFields(p)
+	if len(fields) != 1 {
+		return nil, fmt.Errorf("expected a single field but got: %s", fields)
+	}
+
+	f := p.Files[0]
+	assignPipelineToData(f)
+
+	f.Imports = append(f.Imports, flux.Imports("influxdata/influxdb/monitor", "experimental", "influxdata/influxdb/v1", "math")...)
+	f.Body = append(f.Body, c.generateFluxASTBody(fields[0])...)
+
+	return p, nil
+}
+
+// validDurations returns an error if the baseline or the period the method
+// of the check learns from is missing or not positive, which would make the
+// script score a window against itself.
+func (c Anomaly) validDurations() error {
+	positive := func(d *notification.Duration) bool {
+		return d != nil && len(d.Values) > 0 && d.TimeDuration() > 0
+	}
+	if c.Method != AnomalyPeriod && !positive(c.Baseline) {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  fmt.Sprintf("Anomaly Baseline must be a positive duration for the %s method", c.Method),
+		}
+	}
+	if c.Method != AnomalyZScore && !positive(c.Period) {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  fmt.Sprintf("Anomaly Period must be a positive duration for the %s method", c.Method),
+		}
+	}
+	return nil
+}
+
+// lookback returns the duration queried by each run, the baseline learned
+// followed by the window scored.
+func (c Anomaly) lookback() *ast.DurationLiteral {
+	d := c.Baseline
+	if c.Method == AnomalyPeriod {
+		d = c.Period
+	}
+	values := append(append([]ast.Duration{}, d.Values...), c.Every.Values...)
+	return &ast.DurationLiteral{Values: values}
+}
+
+func replaceStartWithLookback(pkg *ast.Package, lookback *ast.DurationLiteral) {
+	ast.Visit(pkg, func(n ast.Node) {
+		if e, ok := n.(*ast.Property); ok && e.Key.Key() == "start" {
+			e.Value = flux.Negative(lookback)
+		}
+	})
+}
+
+func (c Anomaly) generateFluxASTBody(field string) []ast.Statement {
+	var statements []ast.Statement
+	statements = append(statements, c.generateTaskOption())
+	statements = append(statements, c.generateFluxASTCheckDefinition("anomaly"))
+	statements = append(statements, c.generateFluxASTLevelFunctions()...)
+	statements = append(statements, c.generateFluxASTMessageFunction())
+	statements = append(statements, flux.DefineVariable("cutoff", subDurationFromNow((*ast.DurationLiteral)(c.Every))))
+	switch c.Method {
+	case AnomalyMAD:
+		statements = append(statements, c.generateFluxASTMAD(field)...)
+	case AnomalyPeriod:
+		statements = append(statements, flux.DefineVariable("previous", subDurationFromNow((*ast.DurationLiteral)(c.Period))))
+		statements = append(statements, c.generateFluxASTPeriod(field))
+	default:
+		statements = append(statements, c.generateFluxASTZScore(field))
+	}
+	return statements
+}
+
+func (c Anomaly) generateFluxASTLevelFunctions() []ast.Statement {
+	score := flux.Call(flux.Member("math", "abs"), flux.Object(flux.Property("x", flux.Member("r", "_score"))))
+
+	statements := make([]ast.Statement, len(c.Thresholds))
+	for i, th := range c.Thresholds {
+		fn := flux.Function(flux.FunctionParams("r"), flux.GreaterThan(score, flux.Float(th.Value)))
+		statements[i] = flux.DefineVariable(strings.ToLower(th.Level.String()), fn)
+	}
+	return statements
+}
+
+// generateFluxASTZScore reduces the windows of each series to the latest
+// window after the cutoff, and the mean and variance of the windows before
+// it computed with Welford's algorithm.
+func (c Anomaly) generateFluxASTZScore(field string) ast.Statement {
+	value := flux.Member("r", field)
+	mean := flux.Member("accumulator", "_mean")
+
+	reduceFn := flux.FuncBlock(flux.FunctionParams("r", "accumulator"),
+		flux.DefineVariable("n", flux.Add(flux.Member("accumulator", "_n"), flux.Float(1))),
+		flux.DefineVariable("delta", flux.Subtract(value, mean)),
+		flux.DefineVariable("mean", flux.Add(mean, flux.Divide(flux.Identifier("delta"), flux.Identifier("n")))),
+		flux.Return(flux.If(
+			flux.GreaterThan(flux.Member("r", "_time"), flux.Identifier("cutoff")),
+			latestWindow(field),
+			flux.ObjectWith("accumulator",
+				flux.Property("_n", flux.Identifier("n")),
+				flux.Property("_mean", flux.Identifier("mean")),
+				flux.Property("_m2", flux.Add(
+					flux.Member("accumulator", "_m2"),
+					flux.Multiply(flux.Identifier("delta"), flux.Subtract(value, flux.Identifier("mean"))),
+				)),
+			),
+		)),
+	)
+	stddev := flux.Call(flux.Member("math", "sqrt"), flux.Object(flux.Property("x", flux.Divide(
+		flux.Member("r", "_m2"),
+		flux.Subtract(flux.Member("r", "_n"), flux.Float(1)),
+	))))
+
+	return flux.ExpressionStatement(flux.Pipe(
+		flux.Identifier("data"),
+		flux.Call(flux.Member("v1", "fieldsAsCols"), flux.Object()),
+		reduce(reduceIdentity(field,
+			flux.Property("_n", flux.Float(0)),
+			flux.Property("_mean", flux.Float(0)),
+			flux.Property("_m2", flux.Float(0)),
+		), reduceFn),
+		filter(flux.And(flux.Member("r", "_current"), flux.GreaterThan(flux.Member("r", "_n"), flux.Float(1)))),
+		mapWith(
+			flux.Property("_baseline", flux.Member("r", "_mean")),
+			flux.Property("_deviation", stddev),
+		),
+		filter(flux.GreaterThan(flux.Member("r", "_deviation"), flux.Float(0))),
+		mapWith(flux.Property("_score", flux.Divide(
+			flux.Subtract(value, flux.Member("r", "_baseline")),
+			flux.Member("r", "_deviation"),
+		))),
+		dropColumns("_current", "_n", "_mean", "_m2"),
+		c.generateFluxASTChecksCall(),
+	))
+}
+
+// generateFluxASTPeriod reduces the windows of each series to the latest
+// window after the cutoff, and the latest window one period before now.
+func (c Anomaly) generateFluxASTPeriod(field string) ast.Statement {
+	reduceFn := flux.Function(flux.FunctionParams("r", "accumulator"), flux.If(
+		flux.GreaterThan(flux.Member("r", "_time"), flux.Identifier("cutoff")),
+		latestWindow(field),
+		flux.If(
+			flux.LessThanEqual(flux.Member("r", "_time"), flux.Identifier("previous")),
+			flux.ObjectWith("accumulator",
+				flux.Property("_previous", flux.Bool(true)),
+				flux.Property("_baseline", flux.Member("r", field)),
+			),
+			flux.Identifier("accumulator"),
+		),
+	))
+
+	return flux.ExpressionStatement(flux.Pipe(
+		flux.Identifier("data"),
+		flux.Call(flux.Member("v1", "fieldsAsCols"), flux.Object()),
+		reduce(reduceIdentity(field,
+			flux.Property("_previous", flux.Bool(false)),
+			flux.Property("_baseline", flux.Float(0)),
+		), reduceFn),
+		filter(flux.And(flux.Member("r", "_current"), flux.Member("r", "_previous"))),
+		mapWith(flux.Property("_score", flux.Subtract(flux.Member("r", field), flux.Member("r", "_baseline")))),
+		dropColumns("_current", "_previous"),
+		c.generateFluxASTChecksCall(),
+	))
+}
+
+// generateFluxASTMAD computes the median of the windows at the same point of
+// the previous periods of each series, and the median of their absolute
+// deviations from it. The medians are attached to the latest window by
+// reducing the union of the three streams, which share the group keys of
+// the series.
+func (c Anomaly) generateFluxASTMAD(field string) []ast.Statement {
+	value := flux.Member("r", field)
+	toInt := func(e ast.Expression) ast.Expression {
+		return flux.Call(flux.Identifier("int"), flux.Object(flux.Property("v", e)))
+	}
+	// (int(v: now()) - int(v: r._time)) % int(v: period) < int(v: every)
+	season := flux.LessThan(
+		flux.Modulo(
+			flux.Subtract(toInt(flux.Call(flux.Identifier("now"), flux.Object())), toInt(flux.Member("r", "_time"))),
+			toInt((*ast.DurationLiteral)(c.Period)),
+		),
+		toInt((*ast.DurationLiteral)(c.Every)),
+	)
+	median := func(column string) *ast.CallExpression {
+		return flux.Call(flux.Identifier("median"), flux.Object(
+			flux.Property("column", flux.String(column)),
+			flux.Property("method", flux.String("exact_mean")),
+		))
+	}
+	union := func(tables ...string) *ast.CallExpression {
+		var es []ast.Expression
+		for _, t := range tables {
+			es = append(es, flux.Identifier(t))
+		}
+		return flux.Call(flux.Identifier("union"), flux.Object(flux.Property("tables", flux.Array(es...))))
+	}
+	latest := func(column string) ast.Expression {
+		return flux.If(
+			flux.Exists(flux.Member("r", column)),
+			flux.Member("r", column),
+			flux.Member("accumulator", column),
+		)
+	}
+
+	reduceFn := flux.Function(flux.FunctionParams("r", "accumulator"), flux.Object(
+		flux.Property("_time", flux.If(flux.Exists(value), flux.Member("r", "_time"), flux.Member("accumulator", "_time"))),
+		flux.Dictionary(field, latest(field)),
+		flux.Property("_current", flux.Or(flux.Member("accumulator", "_current"), flux.Exists(value))),
+		flux.Property("_baseline", latest("_baseline")),
+		flux.Property("_deviation", latest("_deviation")),
+	))
+
+	return []ast.Statement{
+		flux.DefineVariable("windows", flux.Pipe(
+			flux.Identifier("data"),
+			flux.Call(flux.Member("v1", "fieldsAsCols"), flux.Object()),
+		)),
+		flux.DefineVariable("current", flux.Pipe(
+			flux.Identifier("windows"),
+			filter(flux.GreaterThan(flux.Member("r", "_time"), flux.Identifier("cutoff"))),
+			flux.Call(flux.Identifier("last"), flux.Object(flux.Property("column", flux.String(field)))),
+		)),
+		flux.DefineVariable("seasons", flux.Pipe(
+			flux.Identifier("windows"),
+			filter(flux.And(flux.LessThanEqual(flux.Member("r", "_time"), flux.Identifier("cutoff")), season)),
+		)),
+		flux.DefineVariable("medians", flux.Pipe(
+			flux.Identifier("seasons"),
+			median(field),
+			mapWith(
+				flux.Property("_time", flux.Call(flux.Identifier("time"), flux.Object(flux.Property("v", flux.Integer(0))))),
+				flux.Property("_baseline", value),
+			),
+			dropColumns(field),
+		)),
+		flux.DefineVariable("deviations", flux.Pipe(
+			union("seasons", "medians"),
+			flux.Call(flux.Identifier("sort"), flux.Object(flux.Property("columns", flux.Array(flux.String("_time"))))),
+			flux.Call(flux.Identifier("fill"), flux.Object(
+				flux.Property("column", flux.String("_baseline")),
+				flux.Property("usePrevious", flux.Bool(true)),
+			)),
+			filter(flux.Exists(value)),
+			mapWith(flux.Property("_deviation", flux.Call(flux.Member("math", "abs"), flux.Object(
+				flux.Property("x", flux.Subtract(value, flux.Member("r", "_baseline"))),
+			)))),
+			median("_deviation"),
+		)),
+		flux.ExpressionStatement(flux.Pipe(
+			union("current", "medians", "deviations"),
+			reduce(reduceIdentity(field,
+				flux.Property("_baseline", flux.Float(0)),
+				flux.Property("_deviation", flux.Float(0)),
+			), reduceFn),
+			filter(flux.And(flux.Member("r", "_current"), flux.GreaterThan(flux.Member("r", "_deviation"), flux.Float(0)))),
+			// 0.6745 scales the median absolute deviation to the standard
+			// deviation of normally distributed values.
+			mapWith(flux.Property("_score", flux.Divide(
+				flux.Multiply(flux.Float(0.6745), flux.Subtract(value, flux.Member("r", "_baseline"))),
+				flux.Member("r", "_deviation"),
+			))),
+			dropColumns("_current"),
+			c.generateFluxASTChecksCall(),
+		)),
+	}
+}
+
+func (c Anomaly) generateFluxASTChecksCall() *ast.CallExpression {
+	objectProps := append(([]*ast.Property)(nil), flux.Property("data", flux.Identifier("check")))
+	objectProps = append(objectProps, flux.Property("messageFn", flux.Identifier("messageFn")))
+
+	for _, th := range c.Thresholds {
+		lvl := strings.ToLower(th.Level.String())
+		objectProps = append(objectProps, flux.Property(lvl, flux.Identifier(lvl)))
+	}
+
+	return flux.Call(flux.Member("monitor", "check"), flux.Object(objectProps...))
+}
+
+func subDurationFromNow(d *ast.DurationLiteral) *ast.CallExpression {
+	now := flux.Call(flux.Identifier("now"), flux.Object())
+	return flux.Call(flux.Member("experimental", "subDuration"), flux.Object(flux.Property("d", d), flux.Property("from", now)))
+}
+
+// reduceIdentity returns the identity of the reducers of the anomaly check,
+// holding the latest window of the series followed by props.
+func reduceIdentity(field string, props ...*ast.Property) *ast.ObjectExpression {
+	identity := []*ast.Property{
+		flux.Property("_time", flux.Call(flux.Identifier("time"), flux.Object(flux.Property("v", flux.Integer(0))))),
+		flux.Dictionary(field, flux.Float(0)),
+		flux.Property("_current", flux.Bool(false)),
+	}
+	return flux.Object(append(identity, props...)...)
+}
+
+// latestWindow returns the accumulator with the window of r as the latest.
+func latestWindow(field string) *ast.ObjectExpression {
+	return flux.ObjectWith("accumulator",
+		flux.Property("_time", flux.Member("r", "_time")),
+		flux.Dictionary(field, flux.Member("r", field)),
+		flux.Property("_current", flux.Bool(true)),
+	)
+}
+
+func reduce(identity *ast.ObjectExpression, fn *ast.FunctionExpression) *ast.CallExpression {
+	return flux.Call(flux.Identifier("reduce"), flux.Object(
+		flux.Property("identity", identity),
+		flux.Property("fn", fn),
+	))
+}
+
+func filter(predicate ast.Expression) *ast.CallExpression {
+	return flux.Call(flux.Identifier("filter"), flux.Object(
+		flux.Property("fn", flux.Function(flux.FunctionParams("r"), predicate)),
+	))
+}
+
+func mapWith(props ...*ast.Property) *ast.CallExpression {
+	return flux.Call(flux.Identifier("map"), flux.Object(
+		flux.Property("fn", flux.Function(flux.FunctionParams("r"), flux.ObjectWith("r", props...))),
+	))
+}
+
+func dropColumns(columns ...string) *ast.CallExpression {
+	var es []ast.Expression
+	for _, c := range columns {
+		es = append(es, flux.String(c))
+	}
+	return flux.Call(flux.Identifier("drop"), flux.Object(flux.Property("columns", flux.Array(es...))))
+}
+
+type anomalyAlias Anomaly
+
+// MarshalJSON implement json.Marshaler interface.
+func (c Anomaly) MarshalJSON() ([]byte, error) {
+	return json.Marshal(
+		struct {
+			anomalyAlias
+			Type string `json:"type"`
+		}{
+			anomalyAlias: anomalyAlias(c),
+			Type:         c.Type(),
+		})
+}
@@ -0,0 +1,341 @@
+package check_test
+
+import (
+	"testing"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/parser"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/notification"
+	"github.com/influxdata/influxdb/v2/notification/check"
+	"github.com/influxdata/influxdb/v2/query/fluxlang"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnomaly_GenerateFlux(t *testing.T) {
+	base := check.Base{
+		ID:   10,
+		Name: "orbit",
+		Tags: []influxdb.Tag{
+			{Key: "aaa", Value: "vaaa"},
+		},
+		Every:                 mustDuration("1m"),
+		StatusMessageTemplate: "score ${r._score}",
+		Query: influxdb.DashboardQuery{
+			Text: `from(bucket: "orbits") |> range(start: -1h, stop: now()) |> filter(fn: (r) => r._field == "altitude") |> aggregateWindow(every: 5m, fn: mean) |> yield()`,
+		},
+	}
+	thresholds := []check.AnomalyThreshold{
+		{Level: notification.Critical, Value: 4},
+		{Level: notification.Warn, Value: 3},
+	}
+
+	tests := []struct {
+		name    string
+		anomaly check.Anomaly
+		script  string
+	}{
+		{
+			name: "rolling z-score",
+			anomaly: check.Anomaly{
+				Base:       base,
+				Method:     check.AnomalyZScore,
+				Baseline:   mustDuration("1d"),
+				Thresholds: thresholds,
+			},
+			script: `package main
+import "influxdata/influxdb/monitor"
+import "experimental"
+import "influxdata/influxdb/v1"
+import "math"
+
+data = from(bucket: "orbits")
+	|> range(start: -1d1m)
+	|> filter(fn: (r) =>
+		(r._field == "altitude"))
+	|> aggregateWindow(every: 1m, fn: mean, createEmpty: false)
+
+option task = {name: "orbit", every: 1m}
+
+check = {
+	_check_id: "000000000000000a",
+	_check_name: "orbit",
+	_type: "anomaly",
+	tags: {aaa: "vaaa"},
+}
+crit = (r) =>
+	(math["abs"](x: r["_score"]) > 4.0)
+warn = (r) =>
+	(math["abs"](x: r["_score"]) > 3.0)
+messageFn = (r) =>
+	("score ${r._score}")
+cutoff = experimental["subDuration"](d: 1m, from: now())
+
+data
+	|> v1["fieldsAsCols"]()
+	|> reduce(identity: {
+		_time: time(v: 0),
+		"altitude": 0.0,
+		_current: false,
+		_n: 0.0,
+		_mean: 0.0,
+		_m2: 0.0,
+	}, fn: (r, accumulator) => {
+		n = accumulator["_n"] + 1.0
+		delta = r["altitude"] - accumulator["_mean"]
+		mean = accumulator["_mean"] + delta / n
+
+		return if r["_time"] > cutoff then {accumulator with _time: r["_time"], "altitude": r["altitude"], _current: true} else {accumulator with _n: n, _mean: mean, _m2: accumulator["_m2"] + delta * (r["altitude"] - mean)}
+	})
+	|> filter(fn: (r) =>
+		(r["_current"] and r["_n"] > 1.0))
+	|> map(fn: (r) =>
+		({r with _baseline: r["_mean"], _deviation: math["sqrt"](x: r["_m2"] / (r["_n"] - 1.0))}))
+	|> filter(fn: (r) =>
+		(r["_deviation"] > 0.0))
+	|> map(fn: (r) =>
+		({r with _score: (r["altitude"] - r["_baseline"]) / r["_deviation"]}))
+	|> drop(columns: ["_current", "_n", "_mean", "_m2"])
+	|> monitor["check"](
+		data: check,
+		messageFn: messageFn,
+		crit: crit,
+		warn: warn,
+	)`,
+		},
+		{
+			name: "previous period",
+			anomaly: check.Anomaly{
+				Base:       base,
+				Method:     check.AnomalyPeriod,
+				Period:     mustDuration("90m"),
+				Thresholds: thresholds,
+			},
+			script: `package main
+import "influxdata/influxdb/monitor"
+import "experimental"
+import "influxdata/influxdb/v1"
+import "math"
+
+data = from(bucket: "orbits")
+	|> range(start: -90m1m)
+	|> filter(fn: (r) =>
+		(r._field == "altitude"))
+	|> aggregateWindow(every: 1m, fn: mean, createEmpty: false)
+
+option task = {name: "orbit", every: 1m}
+
+check = {
+	_check_id: "000000000000000a",
+	_check_name: "orbit",
+	_type: "anomaly",
+	tags: {aaa: "vaaa"},
+}
+crit = (r) =>
+	(math["abs"](x: r["_score"]) > 4.0)
+warn = (r) =>
+	(math["abs"](x: r["_score"]) > 3.0)
+messageFn = (r) =>
+	("score ${r._score}")
+cutoff = experimental["subDuration"](d: 1m, from: now())
+previous = experimental["subDuration"](d: 90m, from: now())
+
+data
+	|> v1["fieldsAsCols"]()
+	|> reduce(identity: {
+		_time: time(v: 0),
+		"altitude": 0.0,
+		_current: false,
+		_previous: false,
+		_baseline: 0.0,
+	}, fn: (r, accumulator) =>
+		(if r["_time"] > cutoff then {accumulator with _time: r["_time"], "altitude": r["altitude"], _current: true} else if r["_time"] <= previous then {accumulator with _previous: true, _baseline: r["altitude"]} else accumulator))
+	|> filter(fn: (r) =>
+		(r["_current"] and r["_previous"]))
+	|> map(fn: (r) =>
+		({r with _score: r["altitude"] - r["_baseline"]}))
+	|> drop(columns: ["_current", "_previous"])
+	|> monitor["check"](
+		data: check,
+		messageFn: messageFn,
+		crit: crit,
+		warn: warn,
+	)`,
+		},
+		{
+			name: "seasonal median absolute deviation",
+			anomaly: check.Anomaly{
+				Base:       base,
+				Method:     check.AnomalyMAD,
+				Baseline:   mustDuration("1d"),
+				Period:     mustDuration("90m"),
+				Thresholds: thresholds,
+			},
+			script: `package main
+import "influxdata/influxdb/monitor"
+import "experimental"
+import "influxdata/influxdb/v1"
+import "math"
+
+data = from(bucket: "orbits")
+	|> range(start: -1d1m)
+	|> filter(fn: (r) =>
+		(r._field == "altitude"))
+	|> aggregateWindow(every: 1m, fn: mean, createEmpty: false)
+
+option task = {name: "orbit", every: 1m}
+
+check = {
+	_check_id: "000000000000000a",
+	_check_name: "orbit",
+	_type: "anomaly",
+	tags: {aaa: "vaaa"},
+}
+crit = (r) =>
+	(math["abs"](x: r["_score"]) > 4.0)
+warn = (r) =>
+	(math["abs"](x: r["_score"]) > 3.0)
+messageFn = (r) =>
+	("score ${r._score}")
+cutoff = experimental["subDuration"](d: 1m, from: now())
+windows = data
+	|> v1["fieldsAsCols"]()
+current = windows
+	|> filter(fn: (r) =>
+		(r["_time"] > cutoff))
+	|> last(column: "altitude")
+seasons = windows
+	|> filter(fn: (r) =>
+		(r["_time"] <= cutoff and (int(v: now()) - int(v: r["_time"])) % int(v: 90m) < int(v: 1m)))
+medians = seasons
+	|> median(column: "altitude", method: "exact_mean")
+	|> map(fn: (r) =>
+		({r with _time: time(v: 0), _baseline: r["altitude"]}))
+	|> drop(columns: ["altitude"])
+deviations = union(tables: [seasons, medians])
+	|> sort(columns: ["_time"])
+	|> fill(column: "_baseline", usePrevious: true)
+	|> filter(fn: (r) =>
+		(exists r["altitude"]))
+	|> map(fn: (r) =>
+		({r with _deviation: math["abs"](x: r["altitude"] - r["_baseline"])}))
+	|> median(column: "_deviation", method: "exact_mean")
+
+union(tables: [current, medians, deviations])
+	|> reduce(identity: {
+		_time: time(v: 0),
+		"altitude": 0.0,
+		_current: false,
+		_baseline: 0.0,
+		_deviation: 0.0,
+	}, fn: (r, accumulator) =>
+		({
+			_time: if exists r["altitude"] then r["_time"] else accumulator["_time"],
+			"altitude": if exists r["altitude"] then r["altitude"] else accumulator["altitude"],
+			_current: accumulator["_current"] or exists r["altitude"],
+			_baseline: if exists r["_baseline"] then r["_baseline"] else accumulator["_baseline"],
+			_deviation: if exists r["_deviation"] then r["_deviation"] else accumulator["_deviation"],
+		}))
+	|> filter(fn: (r) =>
+		(r["_current"] and r["_deviation"] > 0.0))
+	|> map(fn: (r) =>
+		({r with _score: 0.6745 * (r["altitude"] - r["_baseline"]) / r["_deviation"]}))
+	|> drop(columns: ["_current"])
+	|> monitor["check"](
+		data: check,
+		messageFn: messageFn,
+		crit: crit,
+		warn: warn,
+	)`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := tt.anomaly.GenerateFluxAST(fluxlang.DefaultService)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			script := ast.Format(p)
+			assert.Equal(t, tt.script, script)
+
+			// The generated script parses back to the same AST.
+			parsed := parser.ParseSource(script)
+			if errs := ast.GetErrors(parsed); len(errs) != 0 {
+				t.Fatalf("generated script does not parse: %v", errs)
+			}
+			assert.Equal(t, script, ast.Format(parsed))
+		})
+	}
+}
+
+func TestAnomaly_GenerateFluxInvalidDurations(t *testing.T) {
+	base := check.Base{
+		ID:    10,
+		Name:  "orbit",
+		Every: mustDuration("1m"),
+		Query: influxdb.DashboardQuery{
+			Text: `from(bucket: "orbits") |> range(start: -1h) |> filter(fn: (r) => r._field == "altitude")`,
+		},
+	}
+	thresholds := []check.AnomalyThreshold{{Level: notification.Critical, Value: 4}}
+
+	tests := []struct {
+		name    string
+		anomaly check.Anomaly
+		msg     string
+	}{
+		{
+			name: "empty baseline",
+			anomaly: check.Anomaly{
+				Base:       base,
+				Method:     check.AnomalyZScore,
+				Baseline:   &notification.Duration{},
+				Thresholds: thresholds,
+			},
+			msg: "Anomaly Baseline must be a positive duration for the zscore method",
+		},
+		{
+			name: "missing baseline",
+			anomaly: check.Anomaly{
+				Base:       base,
+				Method:     check.AnomalyMAD,
+				Period:     mustDuration("90m"),
+				Thresholds: thresholds,
+			},
+			msg: "Anomaly Baseline must be a positive duration for the mad method",
+		},
+		{
+			name: "period of 0",
+			anomaly: check.Anomaly{
+				Base:       base,
+				Method:     check.AnomalyPeriod,
+				Period:     mustDuration("0s"),
+				Thresholds: thresholds,
+			},
+			msg: "Anomaly Period must be a positive duration for the period method",
+		},
+		{
+			name: "missing period",
+			anomaly: check.Anomaly{
+				Base:       base,
+				Method:     check.AnomalyPeriod,
+				Thresholds: thresholds,
+			},
+			msg: "Anomaly Period must be a positive duration for the period method",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.anomaly.GenerateFluxAST(fluxlang.DefaultService)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			assert.Equal(t, errors.EInvalid, errors.ErrorCode(err))
+			assert.Equal(t, tt.msg, err.Error())
+		})
+	}
+}
@@ -208,6 +208,7 @@ var typeToCheck = map[string](func() influxdb.Check){
 	"deadman":   func() influxdb.Check { return &Deadman{} },
 	"threshold": func() influxdb.Check { return &Threshold{} },
 	"custom":    func() influxdb.Check { return &Custom{} },
+	"anomaly":   func() influxdb.Check { return &Anomaly{} },
 }
 
 // UnmarshalJSON will convert
@@ -154,6 +154,49 @@ func TestValidCheck(t *testing.T) {
 				Msg:  "range threshold min can't be larger than max",
 			},
 		},
+		{
+			name: "anomaly without baseline",
+			src: &check.Anomaly{
+				Base:       goodBase,
+				Method:     check.AnomalyMAD,
+				Period:     mustDuration("90m"),
+				Thresholds: []check.AnomalyThreshold{{Level: notification.Critical, Value: 3}},
+			},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "Anomaly Baseline must exist for the mad method",
+			},
+		},
+		{
+			name: "anomaly baseline shorter than the period",
+			src: &check.Anomaly{
+				Base:       goodBase,
+				Method:     check.AnomalyMAD,
+				Baseline:   mustDuration("1h"),
+				Period:     mustDuration("90m"),
+				Thresholds: []check.AnomalyThreshold{{Level: notification.Critical, Value: 3}},
+			},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "Anomaly Baseline should not be less than the period",
+			},
+		},
+		{
+			name: "anomaly duplicated threshold level",
+			src: &check.Anomaly{
+				Base:     goodBase,
+				Method:   check.AnomalyZScore,
+				Baseline: mustDuration("1d"),
+				Thresholds: []check.AnomalyThreshold{
+					{Level: notification.Critical, Value: 4},
+					{Level: notification.Critical, Value: 3},
+				},
+			},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "Anomaly threshold level CRIT is duplicated",
+			},
+		},
 	}
 	for _, c := range cases {
 		got := c.src.Valid(fluxlang.DefaultService)
@@ -264,6 +307,43 @@ func TestJSON(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "simple anomaly",
+			src: &check.Anomaly{
+				Base: check.Base{
+					ID:      influxTesting.MustIDBase16(id1),
+					Name:    "name1",
+					OwnerID: influxTesting.MustIDBase16(id2),
+					OrgID:   influxTesting.MustIDBase16(id3),
+					Every:   mustDuration("1m"),
+					Query: influxdb.DashboardQuery{
+						BuilderConfig: influxdb.BuilderConfig{
+							Buckets: []string{},
+							Tags: []struct {
+								Key                   string   `json:"key"`
+								Values                []string `json:"values"`
+								AggregateFunctionType string   `json:"aggregateFunctionType"`
+							}{},
+							Functions: []struct {
+								Name string `json:"name"`
+							}{},
+						},
+					},
+					Tags: []influxdb.Tag{},
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				Method:   check.AnomalyMAD,
+				Baseline: mustDuration("7d"),
+				Period:   mustDuration("90m"),
+				Thresholds: []check.AnomalyThreshold{
+					{Level: notification.Critical, Value: 5},
+					{Level: notification.Warn, Value: 3.5},
+				},
+			},
+		},
 	}
 	for _, c := range cases {
 		fn := func(t *testing.T) {
@@ -56,6 +56,50 @@ func Add(lhs, rhs ast.Expression) *ast.BinaryExpression {
 	}
 }
 
+// LessThanEqual returns a less than or equal to *ast.BinaryExpression.
+func LessThanEqual(lhs, rhs ast.Expression) *ast.BinaryExpression {
+	return &ast.BinaryExpression{
+		Operator: ast.LessThanEqualOperator,
+		Left:     lhs,
+		Right:    rhs,
+	}
+}
+
+// Multiply returns a multiplication *ast.BinaryExpression.
+func Multiply(lhs, rhs ast.Expression) *ast.BinaryExpression {
+	return &ast.BinaryExpression{
+		Operator: ast.MultiplicationOperator,
+		Left:     lhs,
+		Right:    rhs,
+	}
+}
+
+// Divide returns a division *ast.BinaryExpression.
+func Divide(lhs, rhs ast.Expression) *ast.BinaryExpression {
+	return &ast.BinaryExpression{
+		Operator: ast.DivisionOperator,
+		Left:     lhs,
+		Right:    rhs,
+	}
+}
+
+// Modulo returns a modulo *ast.BinaryExpression.
+func Modulo(lhs, rhs ast.Expression) *ast.BinaryExpression {
+	return &ast.BinaryExpression{
+		Operator: ast.ModuloOperator,
+		Left:     lhs,
+		Right:    rhs,
+	}
+}
+
+// Exists returns an exists *ast.UnaryExpression of e.
+func Exists(e ast.Expression) *ast.UnaryExpression {
+	return &ast.UnaryExpression{
+		Operator: ast.ExistsOperator,
+		Argument: e,
+	}
+}
+
 // Member returns an *ast.MemberExpression where the key is p and the values is c.
 func Member(p, c string) *ast.MemberExpression {
 	return &ast.MemberExpression{
@@ -146,6 +190,11 @@ func FuncBlock(params []*ast.Property, stms ...ast.Statement) *ast.FunctionExpre
 	}
 }
 
+// Return returns an *ast.ReturnStatement of e.
+func Return(e ast.Expression) *ast.ReturnStatement {
+	return &ast.ReturnStatement{Argument: e}
+}
+
 // String returns an *ast.StringLiteral of s.
 func String(s string) *ast.StringLiteral {
 	return &ast.StringLiteral{
@@ -0,0 +1,276 @@
+package ccsds
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/models"
+)
+
+// PrimaryHeaderSize is the size of a space packet primary header in bytes
+const PrimaryHeaderSize = 6
+
+// PrimaryHeader is the primary header of a space packet
+type PrimaryHeader struct {
+	Version         uint8
+	Type            uint8
+	SecondaryHeader bool
+	APID            uint16
+	SequenceFlags   uint8
+	SequenceCount   uint16
+	// DataLength is the length of the packet data field in bytes
+	DataLength int
+}
+
+// ParsePrimaryHeader parses the first PrimaryHeaderSize bytes of b
+func ParsePrimaryHeader(b []byte) PrimaryHeader {
+	id := binary.BigEndian.Uint16(b[0:2])
+	seq := binary.BigEndian.Uint16(b[2:4])
+	return PrimaryHeader{
+		Version:         uint8(id >> 13),
+		Type:            uint8(id>>12) & 0x1,
+		SecondaryHeader: id&0x0800 != 0,
+		APID:            id & MaxAPID,
+		SequenceFlags:   uint8(seq >> 14),
+		SequenceCount:   seq & 0x3FFF,
+		// the packet data length field is one less than the length of the data field
+		DataLength: int(binary.BigEndian.Uint16(b[4:6])) + 1,
+	}
+}
+
+// PacketError is returned for packet decoding errors
+type PacketError struct {
+	// Packet is the number of the packet in the stream, 1 is the first packet
+	Packet int
+	// Offset is the byte offset of the packet in the stream
+	Offset int64
+	APID   uint16
+	Err    error
+}
+
+func (e PacketError) Error() string {
+	return fmt.Sprintf("packet %d (apid %d, offset %d): %v", e.Packet, e.APID, e.Offset, e.Err)
+}
+
+func (e PacketError) Unwrap() error {
+	return e.Err
+}
+
+// Decoder reads space packets from a stream and converts them to points
+type Decoder struct {
+	r   *bufio.Reader
+	def *Definition
+
+	// Now returns the time of packets that have no time in their definition
+	Now func() time.Time
+	// PacketSkipped is called for every packet without a definition, idle packets
+	// are skipped silently
+	PacketSkipped func(header PrimaryHeader, offset int64)
+
+	// PacketCount is the number of packets read
+	PacketCount int
+	// BytesRead is the number of bytes read
+	BytesRead int64
+
+	header [PrimaryHeaderSize]byte
+	data   []byte
+}
+
+// NewDecoder creates a decoder of the space packets in r, the definition must be validated
+func NewDecoder(r io.Reader, def *Definition) *Decoder {
+	return &Decoder{
+		r:   bufio.NewReader(r),
+		def: def,
+		Now: time.Now,
+	}
+}
+
+// Next returns the point of the next defined packet in the stream, io.EOF is
+// returned when the stream ends at a packet boundary
+func (d *Decoder) Next() (models.Point, error) {
+	for {
+		offset := d.BytesRead
+		n, err := io.ReadFull(d.r, d.header[:])
+		d.BytesRead += int64(n)
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		d.PacketCount++
+		if err != nil {
+			return nil, PacketError{Packet: d.PacketCount, Offset: offset, Err: fmt.Errorf("truncated primary header: %w", err)}
+		}
+
+		h := ParsePrimaryHeader(d.header[:])
+		if h.Version != 0 {
+			return nil, PacketError{Packet: d.PacketCount, Offset: offset, APID: h.APID, Err: fmt.Errorf("unsupported packet version %d", h.Version)}
+		}
+
+		if cap(d.data) < h.DataLength {
+			d.data = make([]byte, h.DataLength)
+		}
+		d.data = d.data[:h.DataLength]
+		n, err = io.ReadFull(d.r, d.data)
+		d.BytesRead += int64(n)
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, PacketError{Packet: d.PacketCount, Offset: offset, APID: h.APID, Err: fmt.Errorf("truncated packet data field: %w", err)}
+		}
+
+		if h.APID == IdleAPID {
+			continue
+		}
+		pd := d.def.Lookup(h.APID)
+		if pd == nil {
+			if d.PacketSkipped != nil {
+				d.PacketSkipped(h, offset)
+			}
+			continue
+		}
+
+		pt, err := d.toPoint(pd, d.data)
+		if err != nil {
+			return nil, PacketError{Packet: d.PacketCount, Offset: offset, APID: h.APID, Err: err}
+		}
+		return pt, nil
+	}
+}
+
+// DecodeAll reads all packets of the stream and returns their points
+func (d *Decoder) DecodeAll() (models.Points, error) {
+	var pts models.Points
+	for {
+		pt, err := d.Next()
+		if err == io.EOF {
+			return pts, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		pts = append(pts, pt)
+	}
+}
+
+func (d *Decoder) toPoint(pd *PacketDef, data []byte) (models.Point, error) {
+	ts := d.Now()
+	if pd.Time != nil {
+		t, err := decodeTime(pd.Time, data)
+		if err != nil {
+			return nil, err
+		}
+		ts = t
+	}
+
+	fields := make(models.Fields, len(pd.Fields))
+	for _, f := range pd.Fields {
+		v, err := decodeField(f, data)
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %w", f.Name, err)
+		}
+		fields[f.Name] = v
+	}
+
+	return models.NewPoint(pd.Measurement, models.NewTags(pd.Tags), fields, ts)
+}
+
+func decodeTime(td *TimeDef, data []byte) (time.Time, error) {
+	if td.Offset+td.size() > len(data) {
+		return time.Time{}, fmt.Errorf("time code at offset %d exceeds the packet data field of %d bytes", td.Offset, len(data))
+	}
+
+	var coarse uint64
+	for _, b := range data[td.Offset : td.Offset+td.CoarseBytes] {
+		coarse = coarse<<8 | uint64(b)
+	}
+	var fine uint64
+	for _, b := range data[td.Offset+td.CoarseBytes : td.Offset+td.size()] {
+		fine = fine<<8 | uint64(b)
+	}
+
+	epoch := CCSDSEpoch
+	if td.Epoch != nil {
+		epoch = *td.Epoch
+	}
+	nanos := int64(0)
+	if td.FineBytes > 0 {
+		nanos = int64(fine * uint64(time.Second) >> (8 * uint(td.FineBytes)))
+	}
+	return epoch.Add(time.Duration(coarse) * time.Second).Add(time.Duration(nanos)), nil
+}
+
+func decodeField(f *FieldDef, data []byte) (interface{}, error) {
+	if f.Offset+f.size() > len(data) {
+		return nil, fmt.Errorf("value at offset %d exceeds the packet data field of %d bytes", f.Offset, len(data))
+	}
+
+	raw := extractBits(data[f.Offset:f.Offset+f.size()], f.BitOffset, f.Bits, f.Endianness == LittleEndian)
+
+	var v interface{}
+	switch f.Type {
+	case TypeBool:
+		return raw != 0, nil
+	case TypeFloat:
+		var fv float64
+		if f.Bits == 32 {
+			fv = float64(math.Float32frombits(uint32(raw)))
+		} else {
+			fv = math.Float64frombits(raw)
+		}
+		if math.IsNaN(fv) || math.IsInf(fv, 0) {
+			return nil, errors.New("value is not a finite number")
+		}
+		v = fv
+	case TypeInt:
+		// sign extend the two's complement value
+		shift := uint(64 - f.Bits)
+		v = int64(raw<<shift) >> shift
+	default:
+		v = raw
+	}
+
+	if f.Scale == nil && f.Bias == nil {
+		return v, nil
+	}
+
+	var fv float64
+	switch vv := v.(type) {
+	case float64:
+		fv = vv
+	case int64:
+		fv = float64(vv)
+	case uint64:
+		fv = float64(vv)
+	}
+	if f.Scale != nil {
+		fv *= *f.Scale
+	}
+	if f.Bias != nil {
+		fv += *f.Bias
+	}
+	return fv, nil
+}
+
+// extractBits returns the bits of width starting at bitOffset of b, the most
+// significant bit of b[0] being bit 0
+func extractBits(b []byte, bitOffset, width int, littleEndian bool) uint64 {
+	if littleEndian {
+		var v uint64
+		for i := len(b) - 1; i >= 0; i-- {
+			v = v<<8 | uint64(b[i])
+		}
+		return v
+	}
+
+	var v uint64
+	for i := 0; i < width; i++ {
+		bit := bitOffset + i
+		v = v<<1 | uint64(b[bit/8]>>(7-uint(bit%8))&1)
+	}
+	return v
+}
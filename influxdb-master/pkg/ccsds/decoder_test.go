@@ -0,0 +1,214 @@
+package ccsds
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/stretchr/testify/require"
+)
+
+// newPacket creates a telemetry space packet with the supplied data field
+func newPacket(apid uint16, seq uint16, data []byte) []byte {
+	b := make([]byte, PrimaryHeaderSize, PrimaryHeaderSize+len(data))
+	binary.BigEndian.PutUint16(b[0:2], 0x0800|apid) // version 0, telemetry, secondary header
+	binary.BigEndian.PutUint16(b[2:4], 0xC000|seq)  // unsegmented
+	binary.BigEndian.PutUint16(b[4:6], uint16(len(data)-1))
+	return append(b, data...)
+}
+
+func mustParseDefinition(t *testing.T, def string) *Definition {
+	t.Helper()
+	d, err := ParseDefinition(strings.NewReader(def))
+	require.NoError(t, err)
+	return d
+}
+
+const testDefinition = `
+packets:
+  - apid: 100
+    measurement: eps
+    tags:
+      spacecraft: sat-7
+    time:
+      offset: 0
+      fineBytes: 2
+      epoch: 1970-01-01T00:00:00Z
+    fields:
+      - {name: battery_voltage, offset: 6, bits: 16, scale: 0.001}
+      - {name: mode, offset: 8, bitOffset: 0, bits: 4}
+      - {name: heater_on, offset: 8, bitOffset: 4, bits: 1, type: bool}
+      - {name: temperature, offset: 9, bits: 12, bitOffset: 0, type: int}
+      - {name: current, offset: 11, bits: 32, type: float, endianness: little}
+      - {name: counter, offset: 15, bits: 16, endianness: little}
+  - apid: 200
+    measurement: adcs
+    fields:
+      - {name: rate, offset: 0, bits: 64, type: float}
+`
+
+func Test_ParsePrimaryHeader(t *testing.T) {
+	h := ParsePrimaryHeader(newPacket(0x123, 0x1ABC, make([]byte, 10)))
+	require.Equal(t, PrimaryHeader{
+		Version:         0,
+		Type:            0,
+		SecondaryHeader: true,
+		APID:            0x123,
+		SequenceFlags:   3,
+		SequenceCount:   0x1ABC,
+		DataLength:      10,
+	}, h)
+}
+
+func Test_Decoder(t *testing.T) {
+	def := mustParseDefinition(t, testDefinition)
+
+	data := make([]byte, 17)
+	binary.BigEndian.PutUint32(data[0:4], 1600000000) // coarse seconds
+	binary.BigEndian.PutUint16(data[4:6], 0x8000)     // half a second
+	binary.BigEndian.PutUint16(data[6:8], 28123)      // 28.123 V
+	data[8] = 0xA8                                    // mode 10, heater on
+	data[9], data[10] = 0xFF, 0x60                    // temperature -10, 12 bits
+	binary.LittleEndian.PutUint32(data[11:15], math.Float32bits(1.5))
+	binary.LittleEndian.PutUint16(data[15:17], 513)
+
+	rate := make([]byte, 8)
+	binary.BigEndian.PutUint64(rate, math.Float64bits(-0.25))
+
+	var stream bytes.Buffer
+	stream.Write(newPacket(100, 1, data))
+	stream.Write(newPacket(IdleAPID, 2, []byte{0}))
+	stream.Write(newPacket(300, 3, []byte{1, 2, 3}))
+	stream.Write(newPacket(200, 4, rate))
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	dec := NewDecoder(&stream, def)
+	dec.Now = func() time.Time { return now }
+	var skipped []uint16
+	dec.PacketSkipped = func(h PrimaryHeader, offset int64) {
+		skipped = append(skipped, h.APID)
+	}
+
+	pts, err := dec.DecodeAll()
+	require.NoError(t, err)
+	require.Len(t, pts, 2)
+	require.Equal(t, []uint16{300}, skipped)
+	require.Equal(t, 4, dec.PacketCount)
+
+	eps := pts[0]
+	require.Equal(t, "eps", string(eps.Name()))
+	require.Equal(t, models.NewTags(map[string]string{"spacecraft": "sat-7"}), eps.Tags())
+	require.Equal(t, time.Unix(1600000000, int64(time.Second/2)).UTC(), eps.Time().UTC())
+	fields, err := eps.Fields()
+	require.NoError(t, err)
+	require.InDelta(t, 28.123, fields["battery_voltage"], 1e-9)
+	require.Equal(t, uint64(10), fields["mode"])
+	require.Equal(t, true, fields["heater_on"])
+	require.Equal(t, int64(-10), fields["temperature"])
+	require.Equal(t, 1.5, fields["current"])
+	require.Equal(t, uint64(513), fields["counter"])
+
+	adcs := pts[1]
+	require.Equal(t, "adcs", string(adcs.Name()))
+	require.Equal(t, now, adcs.Time().UTC())
+	fields, err = adcs.Fields()
+	require.NoError(t, err)
+	require.Equal(t, -0.25, fields["rate"])
+}
+
+func Test_Decoder_CCSDSEpoch(t *testing.T) {
+	def := mustParseDefinition(t, `
+packets:
+  - apid: 1
+    measurement: m
+    time: {offset: 0, coarseBytes: 2}
+    fields: [{name: a, offset: 2, bits: 8}]
+`)
+	pt, err := NewDecoder(bytes.NewReader(newPacket(1, 0, []byte{0, 60, 7})), def).Next()
+	require.NoError(t, err)
+	require.Equal(t, CCSDSEpoch.Add(time.Minute), pt.Time().UTC())
+}
+
+func Test_Decoder_errors(t *testing.T) {
+	def := mustParseDefinition(t, testDefinition)
+
+	var tests = []struct {
+		name   string
+		stream []byte
+		err    string
+	}{
+		{
+			name:   "truncated header",
+			stream: []byte{0x08, 0x64, 0xC0},
+			err:    "truncated primary header",
+		},
+		{
+			name:   "truncated data field",
+			stream: newPacket(200, 0, make([]byte, 8))[:10],
+			err:    "truncated packet data field",
+		},
+		{
+			name:   "field exceeds data field",
+			stream: newPacket(200, 0, make([]byte, 4)),
+			err:    `field "rate"`,
+		},
+		{
+			name:   "time exceeds data field",
+			stream: newPacket(100, 0, make([]byte, 3)),
+			err:    "time code",
+		},
+		{
+			name:   "unsupported version",
+			stream: append([]byte{0x20, 0x64}, newPacket(100, 0, make([]byte, 17))[2:]...),
+			err:    "unsupported packet version",
+		},
+		{
+			name:   "not a number",
+			stream: newPacket(200, 0, []byte{0x7F, 0xF8, 0, 0, 0, 0, 0, 1}),
+			err:    "not a finite number",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewDecoder(bytes.NewReader(tt.stream), def).Next()
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.err)
+
+			var pErr PacketError
+			require.True(t, errors.As(err, &pErr))
+			require.Equal(t, 1, pErr.Packet)
+		})
+	}
+
+	t.Run("empty stream", func(t *testing.T) {
+		_, err := NewDecoder(bytes.NewReader(nil), def).Next()
+		require.Equal(t, io.EOF, err)
+	})
+}
+
+func Test_extractBits(t *testing.T) {
+	var tests = []struct {
+		b         []byte
+		bitOffset int
+		width     int
+		little    bool
+		expected  uint64
+	}{
+		{[]byte{0xAB}, 0, 8, false, 0xAB},
+		{[]byte{0xAB}, 0, 4, false, 0xA},
+		{[]byte{0xAB}, 4, 4, false, 0xB},
+		{[]byte{0xAB, 0xCD}, 4, 8, false, 0xBC},
+		{[]byte{0x80}, 0, 1, false, 1},
+		{[]byte{0x01, 0x02}, 0, 16, true, 0x0201},
+		{[]byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08}, 0, 64, false, 0x0102030405060708},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.expected, extractBits(tt.b, tt.bitOffset, tt.width, tt.little))
+	}
+}
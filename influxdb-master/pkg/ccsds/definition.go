@@ -0,0 +1,236 @@
+// Package ccsds decodes CCSDS space packets to InfluxDB points
+package ccsds
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// MaxAPID is the largest application process identifier of a packet
+	MaxAPID = 0x7FF
+	// IdleAPID is the application process identifier reserved for idle packets
+	IdleAPID = MaxAPID
+)
+
+// Field data types
+const (
+	TypeUint  = "uint"
+	TypeInt   = "int"
+	TypeFloat = "float"
+	TypeBool  = "bool"
+)
+
+// Byte orders of a field
+const (
+	BigEndian    = "big"
+	LittleEndian = "little"
+)
+
+// CCSDSEpoch is the default epoch of CCSDS unsegmented time codes, 1958-01-01 TAI
+var CCSDSEpoch = time.Date(1958, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Definition describes how packets of every application process identifier (APID)
+// are converted to points
+type Definition struct {
+	Packets []*PacketDef `yaml:"packets" json:"packets"`
+
+	byAPID map[uint16]*PacketDef
+}
+
+// PacketDef describes the conversion of packets with the same APID to a point
+type PacketDef struct {
+	APID        uint16            `yaml:"apid" json:"apid"`
+	Measurement string            `yaml:"measurement" json:"measurement"`
+	Tags        map[string]string `yaml:"tags,omitempty" json:"tags,omitempty"`
+	// Time describes the packet time, packets without a time are timestamped on arrival
+	Time   *TimeDef    `yaml:"time,omitempty" json:"time,omitempty"`
+	Fields []*FieldDef `yaml:"fields" json:"fields"`
+}
+
+// TimeDef describes a CCSDS unsegmented time code (CUC) in the packet data field
+type TimeDef struct {
+	// Offset is the byte offset of the time code in the packet data field
+	Offset int `yaml:"offset" json:"offset"`
+	// CoarseBytes is the number of bytes of whole seconds, 1 to 4, defaults to 4
+	CoarseBytes int `yaml:"coarseBytes,omitempty" json:"coarseBytes,omitempty"`
+	// FineBytes is the number of bytes of binary fractions of a second, 0 to 3
+	FineBytes int `yaml:"fineBytes,omitempty" json:"fineBytes,omitempty"`
+	// Epoch of the time code, defaults to CCSDSEpoch. No leap second correction is applied.
+	Epoch *time.Time `yaml:"epoch,omitempty" json:"epoch,omitempty"`
+}
+
+// FieldDef describes a value in the packet data field
+type FieldDef struct {
+	Name string `yaml:"name" json:"name"`
+	// Offset is the byte offset of the value in the packet data field, the packet
+	// data field includes the secondary header
+	Offset int `yaml:"offset" json:"offset"`
+	// BitOffset is the offset of the first bit of the value in the byte at Offset,
+	// 0 being the most significant bit
+	BitOffset int `yaml:"bitOffset,omitempty" json:"bitOffset,omitempty"`
+	// Bits is the width of the value, 1 to 64
+	Bits int `yaml:"bits" json:"bits"`
+	// Type is one of uint, int, float or bool, defaults to uint
+	Type string `yaml:"type,omitempty" json:"type,omitempty"`
+	// Endianness is the byte order of the value, big (default) or little.
+	// Little endian values must be byte aligned.
+	Endianness string `yaml:"endianness,omitempty" json:"endianness,omitempty"`
+	// Scale and Bias convert a raw value to an engineering value (raw * scale + bias),
+	// a field with either of them set is always written as a float
+	Scale *float64 `yaml:"scale,omitempty" json:"scale,omitempty"`
+	Bias  *float64 `yaml:"bias,omitempty" json:"bias,omitempty"`
+}
+
+// ParseDefinition reads a packet definition file, both YAML and JSON are supported
+func ParseDefinition(r io.Reader) (*Definition, error) {
+	var def Definition
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	if err := dec.Decode(&def); err != nil {
+		if err == io.EOF {
+			return nil, errors.New("packet definition is empty")
+		}
+		return nil, fmt.Errorf("failed to parse packet definition: %w", err)
+	}
+	if err := def.Validate(); err != nil {
+		return nil, err
+	}
+	return &def, nil
+}
+
+// Validate checks the definition and indexes packets by their APID
+func (d *Definition) Validate() error {
+	if len(d.Packets) == 0 {
+		return errors.New("packet definition requires at least one packet")
+	}
+
+	byAPID := make(map[uint16]*PacketDef, len(d.Packets))
+	for i, p := range d.Packets {
+		if p == nil {
+			return fmt.Errorf("packets[%d]: packet is empty", i)
+		}
+		if err := p.validate(); err != nil {
+			return fmt.Errorf("packets[%d]: %w", i, err)
+		}
+		if _, ok := byAPID[p.APID]; ok {
+			return fmt.Errorf("packets[%d]: duplicate apid %d", i, p.APID)
+		}
+		byAPID[p.APID] = p
+	}
+	d.byAPID = byAPID
+	return nil
+}
+
+// Lookup returns the packet definition of the APID, or nil when there is none
+func (d *Definition) Lookup(apid uint16) *PacketDef {
+	return d.byAPID[apid]
+}
+
+func (p *PacketDef) validate() error {
+	if p.APID >= IdleAPID {
+		return fmt.Errorf("apid %d is out of range [0, %d)", p.APID, IdleAPID)
+	}
+	if p.Measurement == "" {
+		return errors.New("measurement is required")
+	}
+	if len(p.Fields) == 0 {
+		return errors.New("at least one field is required")
+	}
+	if p.Time != nil {
+		if err := p.Time.validate(); err != nil {
+			return fmt.Errorf("time: %w", err)
+		}
+	}
+
+	names := make(map[string]bool, len(p.Fields))
+	for i, f := range p.Fields {
+		if f == nil {
+			return fmt.Errorf("fields[%d]: field is empty", i)
+		}
+		if err := f.validate(); err != nil {
+			return fmt.Errorf("fields[%d]: %w", i, err)
+		}
+		if names[f.Name] {
+			return fmt.Errorf("fields[%d]: duplicate field name %q", i, f.Name)
+		}
+		names[f.Name] = true
+	}
+	return nil
+}
+
+func (t *TimeDef) validate() error {
+	if t.Offset < 0 {
+		return errors.New("offset must not be negative")
+	}
+	if t.CoarseBytes == 0 {
+		t.CoarseBytes = 4
+	}
+	if t.CoarseBytes < 1 || t.CoarseBytes > 4 {
+		return fmt.Errorf("coarseBytes %d is out of range [1, 4]", t.CoarseBytes)
+	}
+	if t.FineBytes < 0 || t.FineBytes > 3 {
+		return fmt.Errorf("fineBytes %d is out of range [0, 3]", t.FineBytes)
+	}
+	return nil
+}
+
+// size is the number of bytes of the time code
+func (t *TimeDef) size() int {
+	return t.CoarseBytes + t.FineBytes
+}
+
+func (f *FieldDef) validate() error {
+	if f.Name == "" {
+		return errors.New("name is required")
+	}
+	if f.Offset < 0 {
+		return errors.New("offset must not be negative")
+	}
+	if f.BitOffset < 0 || f.BitOffset > 7 {
+		return fmt.Errorf("bitOffset %d is out of range [0, 7]", f.BitOffset)
+	}
+	if f.Bits < 1 || f.Bits > 64 {
+		return fmt.Errorf("bits %d is out of range [1, 64]", f.Bits)
+	}
+
+	if f.Type == "" {
+		f.Type = TypeUint
+	}
+	switch f.Type {
+	case TypeUint, TypeInt, TypeBool:
+	case TypeFloat:
+		if f.Bits != 32 && f.Bits != 64 {
+			return fmt.Errorf("float requires 32 or 64 bits, got %d", f.Bits)
+		}
+	default:
+		return fmt.Errorf("unsupported type %q", f.Type)
+	}
+
+	if f.Endianness == "" {
+		f.Endianness = BigEndian
+	}
+	switch f.Endianness {
+	case BigEndian:
+	case LittleEndian:
+		if f.BitOffset != 0 || f.Bits%8 != 0 {
+			return errors.New("little endian values must be byte aligned")
+		}
+	default:
+		return fmt.Errorf("unsupported endianness %q", f.Endianness)
+	}
+
+	if f.Type == TypeBool && (f.Scale != nil || f.Bias != nil) {
+		return errors.New("bool values cannot be scaled")
+	}
+	return nil
+}
+
+// size is the number of bytes spanned by the value
+func (f *FieldDef) size() int {
+	return (f.BitOffset + f.Bits + 7) / 8
+}
@@ -0,0 +1,105 @@
+package ccsds
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ParseDefinition(t *testing.T) {
+	yamlDef := `
+packets:
+  - apid: 100
+    measurement: eps
+    tags:
+      spacecraft: sat-7
+    time:
+      offset: 0
+      fineBytes: 2
+      epoch: 1970-01-01T00:00:00Z
+    fields:
+      - name: battery_voltage
+        offset: 6
+        bits: 16
+        scale: 0.001
+      - name: mode
+        offset: 8
+        bitOffset: 4
+        bits: 4
+`
+	jsonDef := `{
+  "packets": [{
+    "apid": 100,
+    "measurement": "eps",
+    "tags": {"spacecraft": "sat-7"},
+    "time": {"offset": 0, "fineBytes": 2, "epoch": "1970-01-01T00:00:00Z"},
+    "fields": [
+      {"name": "battery_voltage", "offset": 6, "bits": 16, "scale": 0.001},
+      {"name": "mode", "offset": 8, "bitOffset": 4, "bits": 4}
+    ]
+  }]
+}`
+
+	for _, tt := range []struct {
+		name string
+		def  string
+	}{
+		{"yaml", yamlDef},
+		{"json", jsonDef},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			def, err := ParseDefinition(strings.NewReader(tt.def))
+			require.NoError(t, err)
+
+			pd := def.Lookup(100)
+			require.NotNil(t, pd)
+			require.Equal(t, "eps", pd.Measurement)
+			require.Equal(t, map[string]string{"spacecraft": "sat-7"}, pd.Tags)
+
+			require.NotNil(t, pd.Time)
+			require.Equal(t, 4, pd.Time.CoarseBytes)
+			require.Equal(t, 2, pd.Time.FineBytes)
+			require.Equal(t, time.Unix(0, 0).UTC(), pd.Time.Epoch.UTC())
+
+			require.Len(t, pd.Fields, 2)
+			require.Equal(t, TypeUint, pd.Fields[0].Type)
+			require.Equal(t, BigEndian, pd.Fields[0].Endianness)
+			require.Equal(t, 0.001, *pd.Fields[0].Scale)
+			require.Equal(t, 4, pd.Fields[1].BitOffset)
+
+			require.Nil(t, def.Lookup(101))
+		})
+	}
+}
+
+func Test_ParseDefinition_invalid(t *testing.T) {
+	var tests = []struct {
+		name string
+		def  string
+		err  string
+	}{
+		{"empty", "", "empty"},
+		{"no packets", "packets: []", "at least one packet"},
+		{"unknown key", "packets:\n  - apid: 1\n    unknown: 1", "unknown"},
+		{"idle apid", "packets:\n  - apid: 2047\n    measurement: m\n    fields: [{name: a, bits: 8}]", "out of range"},
+		{"no measurement", "packets:\n  - apid: 1\n    fields: [{name: a, bits: 8}]", "measurement is required"},
+		{"no fields", "packets:\n  - apid: 1\n    measurement: m", "at least one field"},
+		{"duplicate apid", "packets:\n  - {apid: 1, measurement: m, fields: [{name: a, bits: 8}]}\n  - {apid: 1, measurement: n, fields: [{name: a, bits: 8}]}", "duplicate apid"},
+		{"duplicate field", "packets:\n  - {apid: 1, measurement: m, fields: [{name: a, bits: 8}, {name: a, bits: 8}]}", "duplicate field"},
+		{"too wide", "packets:\n  - {apid: 1, measurement: m, fields: [{name: a, bits: 65}]}", "bits 65"},
+		{"float bits", "packets:\n  - {apid: 1, measurement: m, fields: [{name: a, bits: 16, type: float}]}", "32 or 64"},
+		{"unknown type", "packets:\n  - {apid: 1, measurement: m, fields: [{name: a, bits: 8, type: string}]}", "unsupported type"},
+		{"unaligned little endian", "packets:\n  - {apid: 1, measurement: m, fields: [{name: a, bits: 12, endianness: little}]}", "byte aligned"},
+		{"scaled bool", "packets:\n  - {apid: 1, measurement: m, fields: [{name: a, bits: 1, type: bool, scale: 2}]}", "cannot be scaled"},
+		{"fine bytes", "packets:\n  - {apid: 1, measurement: m, time: {fineBytes: 4}, fields: [{name: a, bits: 8}]}", "fineBytes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseDefinition(strings.NewReader(tt.def))
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
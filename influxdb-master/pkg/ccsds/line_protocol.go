@@ -0,0 +1,49 @@
+package ccsds
+
+import (
+	"io"
+)
+
+// LineProtocolReader converts a stream of space packets to line protocol
+type LineProtocolReader struct {
+	// Decoder decodes the packets
+	Decoder *Decoder
+
+	precision string
+	buffer    []byte
+	index     int
+	finished  error
+}
+
+// ToLineProtocol returns a reader of the line protocol of the space packets in r,
+// the timestamps of the lines have the supplied precision (ns when empty)
+func ToLineProtocol(r io.Reader, def *Definition, precision string) *LineProtocolReader {
+	if precision == "" {
+		precision = "ns"
+	}
+	return &LineProtocolReader{
+		Decoder:   NewDecoder(r, def),
+		precision: precision,
+	}
+}
+
+// Read implements io.Reader that returns protocol lines
+func (state *LineProtocolReader) Read(p []byte) (int, error) {
+	for state.index >= len(state.buffer) {
+		if state.finished != nil {
+			return 0, state.finished
+		}
+		pt, err := state.Decoder.Next()
+		if err != nil {
+			state.finished = err
+			continue
+		}
+		state.buffer = append(state.buffer[:0], pt.PrecisionString(state.precision)...)
+		state.buffer = append(state.buffer, '\n')
+		state.index = 0
+	}
+
+	n := copy(p, state.buffer[state.index:])
+	state.index += n
+	return n, nil
+}
@@ -0,0 +1,68 @@
+package ccsds
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ToLineProtocol(t *testing.T) {
+	def := mustParseDefinition(t, `
+packets:
+  - apid: 5
+    measurement: obc
+    tags: {spacecraft: sat-7}
+    time: {offset: 0}
+    fields:
+      - {name: cpu, offset: 4, bits: 8}
+      - {name: uptime, offset: 5, bits: 16, scale: 0.5}
+`)
+
+	var stream bytes.Buffer
+	for i := 0; i < 3; i++ {
+		data := make([]byte, 7)
+		binary.BigEndian.PutUint32(data[0:4], uint32(i))
+		data[4] = byte(10 + i)
+		binary.BigEndian.PutUint16(data[5:7], uint16(100*i))
+		stream.Write(newPacket(5, uint16(i), data))
+	}
+
+	var tests = []struct {
+		precision string
+		expected  string
+	}{
+		{
+			"s",
+			"obc,spacecraft=sat-7 cpu=10u,uptime=0 -378691200\n" +
+				"obc,spacecraft=sat-7 cpu=11u,uptime=50 -378691199\n" +
+				"obc,spacecraft=sat-7 cpu=12u,uptime=100 -378691198\n",
+		},
+		{
+			"",
+			"obc,spacecraft=sat-7 cpu=10u,uptime=0 -378691200000000000\n" +
+				"obc,spacecraft=sat-7 cpu=11u,uptime=50 -378691199000000000\n" +
+				"obc,spacecraft=sat-7 cpu=12u,uptime=100 -378691198000000000\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run("precision "+tt.precision, func(t *testing.T) {
+			// read one byte at a time to exercise partial buffer copies
+			r := iotest.OneByteReader(ToLineProtocol(bytes.NewReader(stream.Bytes()), def, tt.precision))
+			lines, err := ioutil.ReadAll(r)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, string(lines))
+		})
+	}
+
+	t.Run("errors are returned once the lines are read", func(t *testing.T) {
+		truncated := stream.Bytes()[:stream.Len()-1]
+		lines, err := ioutil.ReadAll(ToLineProtocol(bytes.NewReader(truncated), def, "s"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "packet 3")
+		require.Equal(t, "obc,spacecraft=sat-7 cpu=10u,uptime=0 -378691200\nobc,spacecraft=sat-7 cpu=11u,uptime=50 -378691199\n", string(lines))
+	})
+}
@@ -0,0 +1,208 @@
+// Package jsonpath implements a subset of JSONPath to select values of
+// decoded JSON documents.
+//
+// A path starts at the document root ($) or the current value (@), and is
+// followed by any number of steps:
+//
+//	.name       the member name of an object
+//	['name']    the member name of an object, which may contain any character
+//	[n]         the element n of an array, counting from the end if negative
+//	.* or [*]   all members of an object or elements of an array
+//
+// A path without a wildcard step selects at most one value.
+package jsonpath
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type stepKind int
+
+const (
+	memberStep stepKind = iota
+	indexStep
+	wildcardStep
+)
+
+type step struct {
+	kind  stepKind
+	name  string
+	index int
+}
+
+// Path is a parsed path.
+type Path struct {
+	raw      string
+	relative bool
+	steps    []step
+}
+
+// Parse parses a path.
+func Parse(s string) (*Path, error) {
+	p := &Path{raw: s}
+	switch {
+	case strings.HasPrefix(s, "$"):
+	case strings.HasPrefix(s, "@"):
+		p.relative = true
+	default:
+		return nil, fmt.Errorf("path %q must start with $ or @", s)
+	}
+
+	for i := 1; i < len(s); {
+		switch s[i] {
+		case '.':
+			j := i + 1
+			for j < len(s) && s[j] != '.' && s[j] != '[' {
+				j++
+			}
+			name := s[i+1 : j]
+			if name == "" {
+				return nil, fmt.Errorf("path %q has an empty member name at offset %d", s, i)
+			} else if name == "*" {
+				p.steps = append(p.steps, step{kind: wildcardStep})
+			} else {
+				p.steps = append(p.steps, step{kind: memberStep, name: name})
+			}
+			i = j
+		case '[':
+			st, n, err := parseBracket(s[i:])
+			if err != nil {
+				return nil, fmt.Errorf("path %q: %v at offset %d", s, err, i)
+			}
+			p.steps = append(p.steps, st)
+			i += n
+		default:
+			return nil, fmt.Errorf("path %q has an unexpected %q at offset %d", s, s[i], i)
+		}
+	}
+	return p, nil
+}
+
+// parseBracket parses the bracket step at the start of s, and returns its
+// length.
+func parseBracket(s string) (step, int, error) {
+	if len(s) > 1 && (s[1] == '\'' || s[1] == '"') {
+		quote := s[1]
+		var name strings.Builder
+		for i := 2; i < len(s); i++ {
+			switch c := s[i]; {
+			case c == '\\' && i+1 < len(s):
+				i++
+				name.WriteByte(s[i])
+			case c == quote:
+				if i+1 >= len(s) || s[i+1] != ']' {
+					return step{}, 0, fmt.Errorf("expected ] after quoted name")
+				}
+				return step{kind: memberStep, name: name.String()}, i + 2, nil
+			default:
+				name.WriteByte(c)
+			}
+		}
+		return step{}, 0, fmt.Errorf("unterminated quoted name")
+	}
+
+	end := strings.IndexByte(s, ']')
+	if end < 0 {
+		return step{}, 0, fmt.Errorf("unterminated [")
+	}
+	v := s[1:end]
+	if v == "*" {
+		return step{kind: wildcardStep}, end + 1, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return step{}, 0, fmt.Errorf("invalid index %q", v)
+	}
+	return step{kind: indexStep, index: n}, end + 1, nil
+}
+
+// String returns the path as it was parsed.
+func (p *Path) String() string {
+	return p.raw
+}
+
+// Relative returns true if the path starts at the current value.
+func (p *Path) Relative() bool {
+	return p.relative
+}
+
+// Wildcard returns true if the path may select more than one value.
+func (p *Path) Wildcard() bool {
+	for _, st := range p.steps {
+		if st.kind == wildcardStep {
+			return true
+		}
+	}
+	return false
+}
+
+// Select returns the values the path selects from the document root or the
+// current value, which are decoded by encoding/json into interface{}. The
+// members of an object selected by a wildcard are in the order of their
+// names.
+func (p *Path) Select(root, current interface{}) []interface{} {
+	v := root
+	if p.relative {
+		v = current
+	}
+	values := []interface{}{v}
+	for _, st := range p.steps {
+		var next []interface{}
+		for _, v := range values {
+			next = st.apply(v, next)
+		}
+		if len(next) == 0 {
+			return nil
+		}
+		values = next
+	}
+	return values
+}
+
+// Get returns the value the path selects, and whether it selects any.
+func (p *Path) Get(root, current interface{}) (interface{}, bool) {
+	values := p.Select(root, current)
+	if len(values) == 0 {
+		return nil, false
+	}
+	return values[0], true
+}
+
+func (st step) apply(v interface{}, dst []interface{}) []interface{} {
+	switch st.kind {
+	case memberStep:
+		if obj, ok := v.(map[string]interface{}); ok {
+			if m, ok := obj[st.name]; ok {
+				dst = append(dst, m)
+			}
+		}
+	case indexStep:
+		if arr, ok := v.([]interface{}); ok {
+			i := st.index
+			if i < 0 {
+				i += len(arr)
+			}
+			if i >= 0 && i < len(arr) {
+				dst = append(dst, arr[i])
+			}
+		}
+	case wildcardStep:
+		switch v := v.(type) {
+		case []interface{}:
+			dst = append(dst, v...)
+		case map[string]interface{}:
+			names := make([]string, 0, len(v))
+			for name := range v {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				dst = append(dst, v[name])
+			}
+		}
+	}
+	return dst
+}
@@ -0,0 +1,99 @@
+package jsonpath_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2/pkg/jsonpath"
+)
+
+const doc = `{
+	"station": "gs1",
+	"antennas": [
+		{"name": "a1", "azimuth": 120.5, "status": {"locked": true}},
+		{"name": "a2", "azimuth": 240, "status": {"locked": false}}
+	],
+	"links": {"uplink": {"rate": 2}, "downlink": {"rate": 10}},
+	"odd.name": 1
+}`
+
+func TestPath_Select(t *testing.T) {
+	var root interface{}
+	if err := json.Unmarshal([]byte(doc), &root); err != nil {
+		t.Fatal(err)
+	}
+	current := root.(map[string]interface{})["antennas"].([]interface{})[1]
+
+	tests := []struct {
+		path string
+		exp  []interface{}
+	}{
+		{path: "$.station", exp: []interface{}{"gs1"}},
+		{path: "$.antennas[0].azimuth", exp: []interface{}{120.5}},
+		{path: "$.antennas[-1].name", exp: []interface{}{"a2"}},
+		{path: "$.antennas[*].name", exp: []interface{}{"a1", "a2"}},
+		{path: "$.antennas.*.status.locked", exp: []interface{}{true, false}},
+		{path: "$.links.*.rate", exp: []interface{}{10.0, 2.0}},
+		{path: "$['odd.name']", exp: []interface{}{1.0}},
+		{path: `$["antennas"][1]['status'].locked`, exp: []interface{}{false}},
+		{path: "@.name", exp: []interface{}{"a2"}},
+		{path: "@", exp: []interface{}{current}},
+		{path: "$.missing"},
+		{path: "$.antennas[2]"},
+		{path: "$.station.name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			p, err := jsonpath.Parse(tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := p.Select(root, current); !reflect.DeepEqual(got, tt.exp) {
+				t.Fatalf("unexpected values: got=%v exp=%v", got, tt.exp)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		path     string
+		wildcard bool
+		err      bool
+	}{
+		{path: "$"},
+		{path: "@.a.b[1]"},
+		{path: "$.a[*].b", wildcard: true},
+		{path: "$.*", wildcard: true},
+		{path: "a.b", err: true},
+		{path: "$.", err: true},
+		{path: "$..a", err: true},
+		{path: "$[a]", err: true},
+		{path: "$[1", err: true},
+		{path: "$['a'", err: true},
+		{path: "$['a'x]", err: true},
+		{path: "$a", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			p, err := jsonpath.Parse(tt.path)
+			if tt.err {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if got := p.Wildcard(); got != tt.wildcard {
+				t.Fatalf("unexpected wildcard: got=%v exp=%v", got, tt.wildcard)
+			}
+			if got := p.String(); got != tt.path {
+				t.Fatalf("unexpected string: got=%s exp=%s", got, tt.path)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+// Package orbital converts spacecraft positions between reference frames and
+// computes the geometry of a ground station's view of them.
+//
+// Positions are in meters and angles in degrees. Geodetic coordinates use the
+// WGS 84 ellipsoid. The inertial frame is rotated into the Earth-fixed frame by
+// the Greenwich mean sidereal time (IAU 1982) of the position's timestamp, with
+// UTC standing in for UT1; precession, nutation and polar motion are ignored,
+// which keeps the conversion accurate to a few hundred meters in low Earth orbit.
+package orbital
+
+import (
+	"math"
+	"time"
+)
+
+// WGS 84 ellipsoid
+const (
+	// EquatorialRadius is the semi-major axis of the WGS 84 ellipsoid in meters
+	EquatorialRadius = 6378137.0
+	// Flattening of the WGS 84 ellipsoid
+	Flattening = 1 / 298.257223563
+	// MeanRadius is the mean radius of the Earth in meters, used for great-circle distances
+	MeanRadius = 6371008.8
+
+	eccentricity2 = Flattening * (2 - Flattening)
+	polarRadius   = EquatorialRadius * (1 - Flattening)
+)
+
+const (
+	deg2rad = math.Pi / 180
+	rad2deg = 180 / math.Pi
+
+	// julianDateUnixEpoch is the Julian date of 1970-01-01T00:00:00Z
+	julianDateUnixEpoch = 2440587.5
+	// julianDateJ2000 is the Julian date of the J2000 epoch, 2000-01-01T12:00:00Z
+	julianDateJ2000 = 2451545.0
+	secondsPerDay   = 86400.0
+)
+
+// Vector is a cartesian position in meters
+type Vector struct {
+	X, Y, Z float64
+}
+
+// Geodetic is a position above the WGS 84 ellipsoid
+type Geodetic struct {
+	// Lat is the geodetic latitude in degrees
+	Lat float64
+	// Lon is the longitude in degrees, in the range [-180, 180]
+	Lon float64
+	// Alt is the height above the ellipsoid in meters
+	Alt float64
+}
+
+// LookAngles is the direction of a position as seen from a ground station
+type LookAngles struct {
+	// Elevation is the angle above the local horizon in degrees
+	Elevation float64
+	// Azimuth is the angle clockwise from true north in degrees, in the range [0, 360)
+	Azimuth float64
+	// Range is the distance between the station and the position in meters
+	Range float64
+}
+
+// GMST returns the Greenwich mean sidereal time of t in radians, in the range [0, 2π)
+func GMST(t time.Time) float64 {
+	jd := float64(t.UnixNano())/1e9/secondsPerDay + julianDateUnixEpoch
+	c := (jd - julianDateJ2000) / 36525
+
+	// IAU 1982 model, in seconds of time
+	s := 67310.54841 + (876600*3600+8640184.812866)*c + 0.093104*c*c - 6.2e-6*c*c*c
+	s = math.Mod(s, secondsPerDay)
+	if s < 0 {
+		s += secondsPerDay
+	}
+	return s * 2 * math.Pi / secondsPerDay
+}
+
+// ECIToECEF rotates an inertial position at time t into the Earth-fixed frame
+func ECIToECEF(v Vector, t time.Time) Vector {
+	sin, cos := math.Sincos(GMST(t))
+	return Vector{
+		X: cos*v.X + sin*v.Y,
+		Y: -sin*v.X + cos*v.Y,
+		Z: v.Z,
+	}
+}
+
+// ECEFToECI rotates an Earth-fixed position at time t into the inertial frame
+func ECEFToECI(v Vector, t time.Time) Vector {
+	sin, cos := math.Sincos(GMST(t))
+	return Vector{
+		X: cos*v.X - sin*v.Y,
+		Y: sin*v.X + cos*v.Y,
+		Z: v.Z,
+	}
+}
+
+// ECEFToGeodetic converts an Earth-fixed position to geodetic coordinates
+func ECEFToGeodetic(v Vector) Geodetic {
+	p := math.Hypot(v.X, v.Y)
+	lon := math.Atan2(v.Y, v.X)
+
+	// Bowring's approximation as the starting point, refined iteratively. The
+	// height is computed in a form that is stable at the poles.
+	beta := math.Atan2(v.Z*EquatorialRadius, p*polarRadius)
+	sinB, cosB := math.Sincos(beta)
+	ep2 := eccentricity2 / (1 - eccentricity2)
+	lat := math.Atan2(v.Z+ep2*polarRadius*sinB*sinB*sinB, p-eccentricity2*EquatorialRadius*cosB*cosB*cosB)
+
+	var alt float64
+	for i := 0; i < 3; i++ {
+		sinLat, cosLat := math.Sincos(lat)
+		n := EquatorialRadius / math.Sqrt(1-eccentricity2*sinLat*sinLat)
+		alt = p*cosLat + v.Z*sinLat - EquatorialRadius*EquatorialRadius/n
+		lat = math.Atan2(v.Z, p*(1-eccentricity2*n/(n+alt)))
+	}
+
+	return Geodetic{Lat: lat * rad2deg, Lon: lon * rad2deg, Alt: alt}
+}
+
+// GeodeticToECEF converts geodetic coordinates to an Earth-fixed position
+func GeodeticToECEF(g Geodetic) Vector {
+	sinLat, cosLat := math.Sincos(g.Lat * deg2rad)
+	sinLon, cosLon := math.Sincos(g.Lon * deg2rad)
+	n := EquatorialRadius / math.Sqrt(1-eccentricity2*sinLat*sinLat)
+	return Vector{
+		X: (n + g.Alt) * cosLat * cosLon,
+		Y: (n + g.Alt) * cosLat * sinLon,
+		Z: (n*(1-eccentricity2) + g.Alt) * sinLat,
+	}
+}
+
+// GroundDistance returns the great-circle distance in meters between two
+// points given by their latitude and longitude in degrees
+func GroundDistance(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1, phi2 := lat1*deg2rad, lat2*deg2rad
+	dPhi := phi2 - phi1
+	dLambda := (lon2 - lon1) * deg2rad
+
+	h := math.Sin(dPhi/2)*math.Sin(dPhi/2) + math.Cos(phi1)*math.Cos(phi2)*math.Sin(dLambda/2)*math.Sin(dLambda/2)
+	return 2 * MeanRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// Look returns the look angles of the Earth-fixed position v from a ground station
+func Look(station Geodetic, v Vector) LookAngles {
+	s := GeodeticToECEF(station)
+	dx, dy, dz := v.X-s.X, v.Y-s.Y, v.Z-s.Z
+
+	sinLat, cosLat := math.Sincos(station.Lat * deg2rad)
+	sinLon, cosLon := math.Sincos(station.Lon * deg2rad)
+
+	// topocentric east, north, up
+	e := -sinLon*dx + cosLon*dy
+	n := -sinLat*cosLon*dx - sinLat*sinLon*dy + cosLat*dz
+	u := cosLat*cosLon*dx + cosLat*sinLon*dy + sinLat*dz
+
+	r := math.Sqrt(dx*dx + dy*dy + dz*dz)
+	if r == 0 {
+		return LookAngles{Elevation: 90}
+	}
+
+	az := math.Atan2(e, n) * rad2deg
+	if az < 0 {
+		az += 360
+	}
+	return LookAngles{
+		Elevation: math.Asin(u/r) * rad2deg,
+		Azimuth:   az,
+		Range:     r,
+	}
+}
@@ -0,0 +1,121 @@
+package orbital_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/pkg/orbital"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGMST(t *testing.T) {
+	tests := []struct {
+		name string
+		t    time.Time
+		// degrees
+		want float64
+	}{
+		{
+			name: "J2000 epoch",
+			t:    time.Date(2000, 1, 1, 12, 0, 0, 0, time.UTC),
+			want: 280.46061837,
+		},
+		{
+			// Vallado, Fundamentals of Astrodynamics and Applications, example 3-5
+			name: "Vallado example",
+			t:    time.Date(1992, 8, 20, 12, 14, 0, 0, time.UTC),
+			want: 152.578787810,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.want, orbital.GMST(tt.t)*180/math.Pi, 1e-6)
+		})
+	}
+}
+
+func TestECIToECEF(t *testing.T) {
+	ts := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+	eci := orbital.Vector{X: 6524834, Y: 6862875, Z: 6448296}
+
+	ecef := orbital.ECIToECEF(eci, ts)
+	assert.InDelta(t, eci.Z, ecef.Z, 1e-9)
+	assert.InDelta(t, math.Hypot(eci.X, eci.Y), math.Hypot(ecef.X, ecef.Y), 1e-6)
+
+	// the rotation is by the sidereal angle
+	gmst := orbital.GMST(ts)
+	assert.InDelta(t, math.Mod(math.Atan2(eci.Y, eci.X)-gmst+4*math.Pi, 2*math.Pi), math.Mod(math.Atan2(ecef.Y, ecef.X)+2*math.Pi, 2*math.Pi), 1e-9)
+
+	back := orbital.ECEFToECI(ecef, ts)
+	assert.InDelta(t, eci.X, back.X, 1e-6)
+	assert.InDelta(t, eci.Y, back.Y, 1e-6)
+	assert.InDelta(t, eci.Z, back.Z, 1e-6)
+}
+
+func TestGeodetic(t *testing.T) {
+	t.Run("reference points", func(t *testing.T) {
+		v := orbital.GeodeticToECEF(orbital.Geodetic{})
+		assert.InDelta(t, orbital.EquatorialRadius, v.X, 1e-6)
+		assert.InDelta(t, 0, v.Y, 1e-6)
+		assert.InDelta(t, 0, v.Z, 1e-6)
+
+		v = orbital.GeodeticToECEF(orbital.Geodetic{Lat: 90})
+		assert.InDelta(t, 6356752.314245, v.Z, 1e-6)
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		positions := []orbital.Geodetic{
+			{Lat: 0, Lon: 0, Alt: 0},
+			{Lat: 51.4779, Lon: -0.0015, Alt: 46},
+			{Lat: -33.8688, Lon: 151.2093, Alt: 58},
+			{Lat: 28.5729, Lon: -80.649, Alt: 408000},
+			{Lat: 89.9999, Lon: 45, Alt: 800000},
+			{Lat: -90, Lon: 0, Alt: 1000},
+			{Lat: 0.1, Lon: 179.9, Alt: 35786000},
+		}
+		for _, want := range positions {
+			got := orbital.ECEFToGeodetic(orbital.GeodeticToECEF(want))
+			assert.InDelta(t, want.Lat, got.Lat, 1e-9, "lat of %+v", want)
+			assert.InDelta(t, want.Alt, got.Alt, 1e-3, "alt of %+v", want)
+			if math.Abs(want.Lat) < 90 {
+				assert.InDelta(t, want.Lon, got.Lon, 1e-9, "lon of %+v", want)
+			}
+		}
+	})
+}
+
+func TestGroundDistance(t *testing.T) {
+	assert.InDelta(t, 0, orbital.GroundDistance(12, 34, 12, 34), 1e-9)
+	assert.InDelta(t, math.Pi/2*orbital.MeanRadius, orbital.GroundDistance(0, 0, 90, 0), 1e-6)
+	assert.InDelta(t, math.Pi*orbital.MeanRadius, orbital.GroundDistance(0, 0, 0, 180), 1e-6)
+	// London to Paris
+	assert.InDelta(t, 343.56e3, orbital.GroundDistance(51.5074, -0.1278, 48.8566, 2.3522), 100)
+}
+
+func TestLook(t *testing.T) {
+	station := orbital.Geodetic{Lat: 0, Lon: 0}
+
+	t.Run("zenith", func(t *testing.T) {
+		la := orbital.Look(station, orbital.Vector{X: orbital.EquatorialRadius + 500e3})
+		assert.InDelta(t, 90, la.Elevation, 1e-9)
+		assert.InDelta(t, 500e3, la.Range, 1e-6)
+	})
+
+	t.Run("north", func(t *testing.T) {
+		la := orbital.Look(station, orbital.GeodeticToECEF(orbital.Geodetic{Lat: 5, Lon: 0, Alt: 500e3}))
+		assert.InDelta(t, 0, la.Azimuth, 1e-9)
+		assert.True(t, la.Elevation > 0 && la.Elevation < 90)
+	})
+
+	t.Run("east", func(t *testing.T) {
+		la := orbital.Look(station, orbital.GeodeticToECEF(orbital.Geodetic{Lat: 0, Lon: 5, Alt: 500e3}))
+		assert.InDelta(t, 90, la.Azimuth, 1e-9)
+	})
+
+	t.Run("below the horizon", func(t *testing.T) {
+		la := orbital.Look(station, orbital.GeodeticToECEF(orbital.Geodetic{Lat: 0, Lon: -90, Alt: 500e3}))
+		assert.InDelta(t, 270, la.Azimuth, 1e-9)
+		assert.True(t, la.Elevation < 0)
+	})
+}
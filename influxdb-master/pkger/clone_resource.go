@@ -11,6 +11,7 @@ import (
 	"github.com/influxdata/influxdb/v2"
 	ierrors "github.com/influxdata/influxdb/v2/kit/errors"
 	"github.com/influxdata/influxdb/v2/kit/platform"
+	nbsvc "github.com/influxdata/influxdb/v2/notebooks/service"
 	"github.com/influxdata/influxdb/v2/notification"
 	icheck "github.com/influxdata/influxdb/v2/notification/check"
 	"github.com/influxdata/influxdb/v2/notification/endpoint"
@@ -35,6 +36,11 @@ type ResourceToClone struct {
 	// issues to account for when exposing this to the outside world. Not something I'm keen
 	// to accommodate at this time.
 	MetaName string `json:"-"`
+
+	// orgID is only known when the resource is cloned as part of an organization.
+	// It is required to look up resources that are scoped to an organization,
+	// such as notebooks, and to resolve scrapers by name.
+	orgID platform.ID
 }
 
 // OK validates a resource clone is viable.
@@ -54,15 +60,20 @@ var kindPriorities = map[Kind]int{
 	KindCheck:                         3,
 	KindCheckDeadman:                  4,
 	KindCheckThreshold:                5,
-	KindNotificationEndpoint:          6,
-	KindNotificationEndpointHTTP:      7,
-	KindNotificationEndpointPagerDuty: 8,
-	KindNotificationEndpointSlack:     9,
-	KindNotificationRule:              10,
-	KindTask:                          11,
-	KindVariable:                      12,
-	KindDashboard:                     13,
-	KindTelegraf:                      14,
+	KindCheckAnomaly:                  6,
+	KindNotificationEndpoint:          7,
+	KindNotificationEndpointHTTP:      8,
+	KindNotificationEndpointPagerDuty: 9,
+	KindNotificationEndpointSlack:     10,
+	KindNotificationRule:              11,
+	KindTask:                          12,
+	KindVariable:                      13,
+	KindDashboard:                     14,
+	KindTelegraf:                      15,
+	KindDBRPMapping:                   16,
+	KindNotebook:                      17,
+	KindScraper:                       18,
+	KindV1Authorization:               19,
 }
 
 type exportKey struct {
@@ -87,11 +98,15 @@ type resourceExporter struct {
 	bucketSVC   influxdb.BucketService
 	checkSVC    influxdb.CheckService
 	dashSVC     influxdb.DashboardService
+	dbrpSVC     influxdb.DBRPMappingServiceV2
 	labelSVC    influxdb.LabelService
 	endpointSVC influxdb.NotificationEndpointService
+	notebookSVC nbsvc.NotebookService
 	ruleSVC     influxdb.NotificationRuleStore
+	scraperSVC  influxdb.ScraperTargetStoreService
 	taskSVC     taskmodel.TaskService
 	teleSVC     influxdb.TelegrafConfigStore
+	v1AuthSVC   influxdb.AuthorizationService
 	varSVC      influxdb.VariableService
 
 	mObjects        map[exportKey]Object
@@ -105,11 +120,15 @@ func newResourceExporter(svc *Service) *resourceExporter {
 		bucketSVC:       svc.bucketSVC,
 		checkSVC:        svc.checkSVC,
 		dashSVC:         svc.dashSVC,
+		dbrpSVC:         svc.dbrpSVC,
 		labelSVC:        svc.labelSVC,
 		endpointSVC:     svc.endpointSVC,
+		notebookSVC:     svc.notebookSVC,
 		ruleSVC:         svc.ruleSVC,
+		scraperSVC:      svc.scraperSVC,
 		taskSVC:         svc.taskSVC,
 		teleSVC:         svc.teleSVC,
+		v1AuthSVC:       svc.v1AuthSVC,
 		varSVC:          svc.varSVC,
 		mObjects:        make(map[exportKey]Object),
 		mPkgNames:       make(map[string]bool),
@@ -240,7 +259,7 @@ func (ex *resourceExporter) resourceCloneToKind(ctx context.Context, r ResourceT
 		for _, bkt := range bkts {
 			mapResource(bkt.OrgID, bkt.ID, KindBucket, BucketToObject(r.Name, *bkt))
 		}
-	case r.Kind.is(KindCheck), r.Kind.is(KindCheckDeadman), r.Kind.is(KindCheckThreshold):
+	case r.Kind.is(KindCheck), r.Kind.is(KindCheckDeadman), r.Kind.is(KindCheckThreshold), r.Kind.is(KindCheckAnomaly):
 		filter := influxdb.CheckFilter{}
 		if r.ID != platform.ID(0) {
 			filter.ID = &r.ID
@@ -295,6 +314,22 @@ func (ex *resourceExporter) resourceCloneToKind(ctx context.Context, r ResourceT
 		if !mapped {
 			return errors.New("no dashboards found")
 		}
+	case r.Kind.is(KindDBRPMapping):
+		mappings, _, err := ex.dbrpSVC.FindMany(ctx, influxdb.DBRPMappingFilterV2{ID: &r.ID})
+		if err != nil {
+			return err
+		}
+		if len(mappings) < 1 {
+			return errors.New("no dbrp mappings found")
+		}
+
+		for _, m := range mappings {
+			bktMetaName, err := ex.bucketMetaName(ctx, m.BucketID)
+			if err != nil {
+				return err
+			}
+			mapResource(m.OrganizationID, m.ID, KindDBRPMapping, DBRPMappingToObject(bktMetaName, *m))
+		}
 	case r.Kind.is(KindLabel):
 		switch {
 		case r.ID != platform.ID(0):
@@ -314,6 +349,18 @@ func (ex *resourceExporter) resourceCloneToKind(ctx context.Context, r ResourceT
 				mapResource(l.OrgID, uniqByNameResID, KindLabel, LabelToObject(r.Name, *l))
 			}
 		}
+	case r.Kind.is(KindNotebook):
+		if ex.notebookSVC == nil {
+			return errors.New("notebooks are not supported by this instance")
+		}
+		if !r.orgID.Valid() {
+			return errors.New("notebooks can only be exported as part of an organization")
+		}
+		n, err := ex.notebookSVC.GetNotebook(ctx, r.orgID, r.ID)
+		if err != nil {
+			return err
+		}
+		mapResource(n.OrgID, n.ID, KindNotebook, NotebookToObject(r.Name, *n))
 	case r.Kind.is(KindNotificationEndpoint),
 		r.Kind.is(KindNotificationEndpointHTTP),
 		r.Kind.is(KindNotificationEndpointPagerDuty),
@@ -392,6 +439,41 @@ func (ex *resourceExporter) resourceCloneToKind(ctx context.Context, r ResourceT
 
 			mapResource(rule.GetOrgID(), rule.GetID(), KindNotificationRule, NotificationRuleToObject(r.Name, endpointObjectName, rule))
 		}
+	case r.Kind.is(KindScraper):
+		switch {
+		case r.ID != platform.ID(0):
+			t, err := ex.scraperSVC.GetTargetByID(ctx, r.ID)
+			if err != nil {
+				return err
+			}
+			bktMetaName, err := ex.bucketMetaName(ctx, t.BucketID)
+			if err != nil {
+				return err
+			}
+			mapResource(t.OrgID, t.ID, KindScraper, ScraperToObject(r.Name, bktMetaName, *t))
+		case len(r.Name) > 0:
+			if !r.orgID.Valid() {
+				return errors.New("scrapers can only be exported by name as part of an organization")
+			}
+			targets, err := ex.scraperSVC.ListTargets(ctx, influxdb.ScraperTargetFilter{
+				Name:  &r.Name,
+				OrgID: &r.orgID,
+			})
+			if err != nil {
+				return err
+			}
+			if len(targets) < 1 {
+				return errors.New("no scrapers found")
+			}
+
+			for _, t := range targets {
+				bktMetaName, err := ex.bucketMetaName(ctx, t.BucketID)
+				if err != nil {
+					return err
+				}
+				mapResource(t.OrgID, t.ID, KindScraper, ScraperToObject(r.Name, bktMetaName, t))
+			}
+		}
 	case r.Kind.is(KindTask):
 		switch {
 		case r.ID != platform.ID(0):
@@ -441,6 +523,28 @@ func (ex *resourceExporter) resourceCloneToKind(ctx context.Context, r ResourceT
 			}
 
 		}
+	case r.Kind.is(KindV1Authorization):
+		a, err := ex.v1AuthSVC.FindAuthorizationByID(ctx, r.ID)
+		if err != nil {
+			return err
+		}
+
+		bktMetaNames := make(map[platform.ID]string)
+		for _, p := range a.Permissions {
+			if p.Resource.Type != influxdb.BucketsResourceType || p.Resource.ID == nil {
+				continue
+			}
+			bktMetaName, err := ex.bucketMetaName(ctx, *p.Resource.ID)
+			if err != nil {
+				return err
+			}
+			bktMetaNames[*p.Resource.ID] = bktMetaName
+		}
+		if len(bktMetaNames) == 0 {
+			// only bucket permissions are supported by v1 authorizations
+			return nil
+		}
+		mapResource(a.OrgID, a.ID, KindV1Authorization, V1AuthorizationToObject(bktMetaNames, *a))
 	case r.Kind.is(KindVariable):
 		switch {
 		case r.ID != platform.ID(0):
@@ -500,6 +604,11 @@ func (ex *resourceExporter) resourceCloneAssociationsGen(ctx context.Context, la
 			return nil, false, nil
 		}
 
+		if r.Kind.is(KindDBRPMapping, KindNotebook, KindScraper, KindV1Authorization) {
+			// these resources do not support label associations
+			return nil, len(mLabelNames) > 0, nil
+		}
+
 		labels, err := ex.labelSVC.FindResourceLabels(ctx, influxdb.LabelMappingFilter{
 			ResourceID:   r.ID,
 			ResourceType: r.Kind.ResourceType(),
@@ -560,6 +669,32 @@ func (ex *resourceExporter) resourceCloneAssociationsGen(ctx context.Context, la
 	return cloneFn, nil
 }
 
+// bucketMetaName provides the metadata.name of the exported bucket matching the
+// provided id. When the bucket is not part of the export, it is added to it so
+// the resources depending on it can be applied from the resulting template.
+func (ex *resourceExporter) bucketMetaName(ctx context.Context, bktID platform.ID) (string, error) {
+	bkt, err := ex.bucketSVC.FindBucketByID(ctx, bktID)
+	if err != nil {
+		return "", err
+	}
+
+	key := newExportKey(bkt.OrgID, bkt.ID, KindBucket, bkt.Name)
+	if object, ok := ex.mObjects[key]; ok {
+		return object.Name(), nil
+	}
+
+	object := BucketToObject("", *bkt)
+	object.SetMetadataName(ex.uniqName())
+	ex.mObjects[key] = object
+	ex.mStackResources[key] = StackResource{
+		APIVersion: APIVersion,
+		ID:         bkt.ID,
+		MetaName:   object.Name(),
+		Kind:       KindBucket,
+	}
+	return object.Name(), nil
+}
+
 func (ex *resourceExporter) uniqName() string {
 	return uniqMetaName(ex.nameGen, idGenerator, ex.mPkgNames)
 }
@@ -669,6 +804,22 @@ func CheckToObject(name string, ch influxdb.Check) Object {
 			thresholds = append(thresholds, convertThreshold(th))
 		}
 		o.Spec[fieldCheckThresholds] = thresholds
+	case *icheck.Anomaly:
+		o.Kind = KindCheckAnomaly
+		assignBase(cT.Base)
+		o.Spec[fieldCheckMethod] = cT.Method
+		assignNonZeroFluxDurs(o.Spec, map[string]*notification.Duration{
+			fieldCheckBaseline: cT.Baseline,
+			fieldCheckPeriod:   cT.Period,
+		})
+		var thresholds []Resource
+		for _, th := range cT.Thresholds {
+			thresholds = append(thresholds, Resource{
+				fieldLevel: th.Level.String(),
+				fieldValue: th.Value,
+			})
+		}
+		o.Spec[fieldCheckThresholds] = thresholds
 	}
 	return o
 }
@@ -1379,6 +1530,79 @@ func TelegrafToObject(name string, t influxdb.TelegrafConfig) Object {
 	return o
 }
 
+// DBRPMappingToObject converts an influxdb.DBRPMappingV2 into an Object. The
+// bucket is referenced by its metadata.name within the template.
+func DBRPMappingToObject(bktMetaName string, m influxdb.DBRPMappingV2) Object {
+	o := newObject(KindDBRPMapping, "")
+	delete(o.Spec, fieldName)
+	o.Spec[fieldDBRPDatabase] = m.Database
+	o.Spec[fieldDBRPRetentionPolicy] = m.RetentionPolicy
+	o.Spec[fieldDBRPBucket] = bktMetaName
+	assignNonZeroBools(o.Spec, map[string]bool{fieldDefault: m.Default})
+	return o
+}
+
+// NotebookToObject converts a notebook into an Object.
+func NotebookToObject(name string, n nbsvc.Notebook) Object {
+	if name == "" {
+		name = n.Name
+	}
+
+	o := newObject(KindNotebook, name)
+	if len(n.Spec) > 0 {
+		o.Spec[fieldNotebookContent] = map[string]interface{}(n.Spec)
+	}
+	return o
+}
+
+// ScraperToObject converts an influxdb.ScraperTarget into an Object. The
+// bucket is referenced by its metadata.name within the template.
+func ScraperToObject(name, bktMetaName string, t influxdb.ScraperTarget) Object {
+	if name == "" {
+		name = t.Name
+	}
+
+	o := newObject(KindScraper, name)
+	o.Spec[fieldScraperURL] = t.URL
+	o.Spec[fieldScraperBucket] = bktMetaName
+	assignNonZeroStrings(o.Spec, map[string]string{fieldType: string(t.Type)})
+	assignNonZeroBools(o.Spec, map[string]bool{fieldScraperAllowInsecure: t.AllowInsecure})
+	return o
+}
+
+// V1AuthorizationToObject converts a legacy v1 influxdb.Authorization into an
+// Object. Only the bucket permissions are exported, with the buckets referenced
+// by their metadata.name within the template. Passwords are stored hashed and
+// are never exported.
+func V1AuthorizationToObject(bktMetaNames map[platform.ID]string, a influxdb.Authorization) Object {
+	o := newObject(KindV1Authorization, "")
+	delete(o.Spec, fieldName)
+	o.Spec[fieldV1AuthToken] = a.Token
+	assignNonZeroStrings(o.Spec, map[string]string{
+		fieldDescription: a.Description,
+		fieldStatus:      string(a.Status),
+	})
+
+	var perms []Resource
+	for _, p := range a.Permissions {
+		if p.Resource.ID == nil {
+			continue
+		}
+		bktMetaName, ok := bktMetaNames[*p.Resource.ID]
+		if !ok {
+			continue
+		}
+		perms = append(perms, Resource{
+			fieldV1AuthAction: string(p.Action),
+			fieldV1AuthBucket: bktMetaName,
+		})
+	}
+	if len(perms) > 0 {
+		o.Spec[fieldV1AuthPermissions] = perms
+	}
+	return o
+}
+
 // VariableToObject converts an influxdb.Variable to a pkger.Object.
 func VariableToObject(name string, v influxdb.Variable) Object {
 	if name == "" {
@@ -0,0 +1,297 @@
+package pkger
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// DriftStatus describes how a resource owned by a stack deviates from the
+// template(s) last applied to the stack.
+type DriftStatus string
+
+const (
+	// DriftStatusModified indicates the resource exists on the platform, but one or
+	// more of the fields managed by the template were changed outside of the template.
+	DriftStatusModified DriftStatus = "modified"
+	// DriftStatusMissing indicates the stack owns the resource, but it has been
+	// deleted from the platform.
+	DriftStatusMissing DriftStatus = "missing"
+	// DriftStatusUnapplied indicates the template declares the resource, but it has
+	// not been applied to the stack yet.
+	DriftStatusUnapplied DriftStatus = "unapplied"
+	// DriftStatusOrphaned indicates the stack owns the resource, but the template no
+	// longer declares it.
+	DriftStatusOrphaned DriftStatus = "orphaned"
+)
+
+// DriftResource is an individual resource that has drifted from its template.
+type DriftResource struct {
+	Kind     Kind        `json:"kind"`
+	MetaName string      `json:"templateMetaName"`
+	ID       SafeID      `json:"id"`
+	Status   DriftStatus `json:"status"`
+	// Fields are the top level fields that differ between the template and the
+	// platform. Only provided for modified resources.
+	Fields []string `json:"fields,omitempty"`
+}
+
+// DriftReport compares the live resources of a stack against the template(s)
+// associated with it.
+type DriftReport struct {
+	StackID   SafeID          `json:"stackID"`
+	Sources   []string        `json:"sources"`
+	CheckedAt time.Time       `json:"checkedAt"`
+	Resources []DriftResource `json:"resources"`
+}
+
+// HasDrift indicates if any resource has drifted from the template.
+func (r DriftReport) HasDrift() bool {
+	return len(r.Resources) > 0
+}
+
+// CountByStatus returns the number of drifted resources for each drift status.
+func (r DriftReport) CountByStatus() map[DriftStatus]int {
+	counts := map[DriftStatus]int{
+		DriftStatusModified:  0,
+		DriftStatusMissing:   0,
+		DriftStatusUnapplied: 0,
+		DriftStatusOrphaned:  0,
+	}
+	for _, res := range r.Resources {
+		counts[res.Status]++
+	}
+	return counts
+}
+
+// driftIgnoredFields are fields that are owned by the platform and never by a
+// template. Secret fields are ignored as well, since the platform only ever
+// returns a reference to the secret.
+var driftIgnoredFields = map[string]bool{
+	"createdAt":       true,
+	"id":              true,
+	"labels":          true,
+	"lastRunError":    true,
+	"lastRunStatus":   true,
+	"latestCompleted": true,
+	"latestScheduled": true,
+	"links":           true,
+	"orgID":           true,
+	"ownerID":         true,
+	"password":        true,
+	"routingKey":      true,
+	"taskID":          true,
+	"token":           true,
+	"updatedAt":       true,
+	"username":        true,
+}
+
+// NewDriftReport builds a drift report from the impact of a dry run of the stack's
+// template(s) against the stack. The stack should be read before the dry run, as
+// the latest stack event determines which resources the stack owns.
+func NewDriftReport(stack Stack, impact ImpactSummary) DriftReport {
+	owned := make(map[driftKey]bool)
+	for _, r := range stack.LatestEvent().Resources {
+		owned[newDriftKey(r.Kind, r.MetaName)] = true
+	}
+
+	report := DriftReport{
+		StackID:   SafeID(stack.ID),
+		Sources:   impact.Sources,
+		Resources: []DriftResource{},
+	}
+
+	kindsByKey := make(map[driftKey]Kind)
+	idxByKey := make(map[driftKey]int)
+	for _, e := range driftEntriesFromDiff(impact.Diff) {
+		key := newDriftKey(e.Kind, e.MetaName)
+		kindsByKey[key] = e.Kind
+
+		res := DriftResource{
+			Kind:     e.Kind,
+			MetaName: e.MetaName,
+			ID:       e.ID,
+		}
+		switch e.StateStatus {
+		case StateStatusNew:
+			res.Status = DriftStatusUnapplied
+			if owned[key] {
+				res.Status = DriftStatusMissing
+			}
+		case StateStatusRemove:
+			res.Status = DriftStatusOrphaned
+		default:
+			if e.old == nil {
+				continue
+			}
+			res.Fields = driftFields(e.new, e.old)
+			if len(res.Fields) == 0 {
+				continue
+			}
+			res.Status = DriftStatusModified
+		}
+		idxByKey[key] = len(report.Resources)
+		report.Resources = append(report.Resources, res)
+	}
+
+	// label mappings that are not in agreement with the platform, for a resource
+	// that otherwise exists, mark the resource as having modified labels.
+	for _, m := range impact.Diff.LabelMappings {
+		if m.StateStatus == StateStatusExists || m.ResID == 0 || m.LabelID == 0 {
+			continue
+		}
+		key := driftKey{resType: string(m.ResType), metaName: m.ResMetaName}
+		if i, ok := idxByKey[key]; ok {
+			if res := &report.Resources[i]; res.Status == DriftStatusModified && !containsStr(res.Fields, "labels") {
+				res.Fields = append(res.Fields, "labels")
+				sort.Strings(res.Fields)
+			}
+			continue
+		}
+		kind, ok := kindsByKey[key]
+		if !ok {
+			continue
+		}
+		idxByKey[key] = len(report.Resources)
+		report.Resources = append(report.Resources, DriftResource{
+			Kind:     kind,
+			MetaName: m.ResMetaName,
+			ID:       m.ResID,
+			Status:   DriftStatusModified,
+			Fields:   []string{"labels"},
+		})
+	}
+
+	sort.Slice(report.Resources, func(i, j int) bool {
+		ri, rj := report.Resources[i], report.Resources[j]
+		if ri.Kind != rj.Kind {
+			return ri.Kind < rj.Kind
+		}
+		return ri.MetaName < rj.MetaName
+	})
+
+	return report
+}
+
+type driftKey struct {
+	resType  string
+	metaName string
+}
+
+func newDriftKey(k Kind, metaName string) driftKey {
+	resType := string(k.ResourceType())
+	if resType == "" {
+		resType = string(k)
+	}
+	return driftKey{resType: resType, metaName: metaName}
+}
+
+type driftEntry struct {
+	DiffIdentifier
+	new, old interface{}
+}
+
+// driftEntriesFromDiff flattens the diffs of every kind of resource of d. The
+// diff of each kind embeds a DiffIdentifier next to its New values and its Old
+// values, which are nil for a resource that does not exist yet. The label
+// mappings are not resources of their own and are skipped.
+func driftEntriesFromDiff(d Diff) []driftEntry {
+	var entries []driftEntry
+	dv := reflect.ValueOf(d)
+	for i := 0; i < dv.NumField(); i++ {
+		if _, ok := dv.Type().Field(i).Type.Elem().FieldByName("DiffIdentifier"); !ok {
+			continue
+		}
+		diffs := dv.Field(i)
+		for j := 0; j < diffs.Len(); j++ {
+			v := diffs.Index(j)
+			e := driftEntry{
+				DiffIdentifier: v.FieldByName("DiffIdentifier").Interface().(DiffIdentifier),
+				new:            v.FieldByName("New").Interface(),
+			}
+			if old := v.FieldByName("Old"); !old.IsNil() {
+				e.old = old.Elem().Interface()
+			}
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// driftFields returns the sorted top level fields that differ between the two
+// values. The values are compared by their JSON representation, as that is the
+// representation the template is authored in.
+func driftFields(newVals, oldVals interface{}) []string {
+	newFields, newOK := toDriftFields(newVals)
+	oldFields, oldOK := toDriftFields(oldVals)
+	if !newOK || !oldOK {
+		// values that do not serialize to an object are not comparable field by
+		// field, in which case the resource is not reported as drifted.
+		return nil
+	}
+
+	var fields []string
+	for k, v := range newFields {
+		if driftIgnoredFields[k] {
+			continue
+		}
+		if !reflect.DeepEqual(v, oldFields[k]) {
+			fields = append(fields, k)
+		}
+	}
+	for k := range oldFields {
+		if _, ok := newFields[k]; ok || driftIgnoredFields[k] {
+			continue
+		}
+		fields = append(fields, k)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+func toDriftFields(v interface{}) (map[string]interface{}, bool) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, false
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(b, &m); err != nil || m == nil {
+		return nil, false
+	}
+	for k, v := range m {
+		if isZeroDriftValue(v) {
+			delete(m, k)
+		}
+	}
+	return m, true
+}
+
+// isZeroDriftValue treats empty values as absent, so that a template that omits
+// a field is not reported as drifted against a platform default of the same.
+func isZeroDriftValue(v interface{}) bool {
+	switch vv := v.(type) {
+	case nil:
+		return true
+	case string:
+		return vv == ""
+	case bool:
+		return !vv
+	case float64:
+		return vv == 0
+	case []interface{}:
+		return len(vv) == 0
+	case map[string]interface{}:
+		return len(vv) == 0
+	}
+	return false
+}
+
+func containsStr(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,236 @@
+package pkger_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/pkger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDriftReport(t *testing.T) {
+	stack := pkger.Stack{
+		ID: 1,
+		Events: []pkger.StackEvent{
+			{
+				Resources: []pkger.StackResource{
+					{Kind: pkger.KindBucket, MetaName: "bucket-1", ID: 10},
+					{Kind: pkger.KindBucket, MetaName: "bucket-deleted", ID: 11},
+					{Kind: pkger.KindLabel, MetaName: "label-1", ID: 20},
+					{Kind: pkger.KindLabel, MetaName: "label-orphan", ID: 21},
+					{Kind: pkger.KindCheck, MetaName: "check-1", ID: 30},
+				},
+			},
+		},
+	}
+
+	bktVals := pkger.DiffBucketValues{Name: "bucket-1", Description: "desc"}
+	impact := pkger.ImpactSummary{
+		Sources: []string{"file:///tmp/template.yml"},
+		Diff: pkger.Diff{
+			Buckets: []pkger.DiffBucket{
+				{
+					DiffIdentifier: pkger.DiffIdentifier{ID: 10, Kind: pkger.KindBucket, MetaName: "bucket-1", StateStatus: pkger.StateStatusExists},
+					New:            bktVals,
+					Old:            &pkger.DiffBucketValues{Name: "bucket-1", Description: "changed in the UI"},
+				},
+				{
+					DiffIdentifier: pkger.DiffIdentifier{Kind: pkger.KindBucket, MetaName: "bucket-deleted", StateStatus: pkger.StateStatusNew},
+					New:            pkger.DiffBucketValues{Name: "bucket-deleted"},
+				},
+				{
+					DiffIdentifier: pkger.DiffIdentifier{Kind: pkger.KindBucket, MetaName: "bucket-new", StateStatus: pkger.StateStatusNew},
+					New:            pkger.DiffBucketValues{Name: "bucket-new"},
+				},
+			},
+			Labels: []pkger.DiffLabel{
+				{
+					DiffIdentifier: pkger.DiffIdentifier{ID: 20, Kind: pkger.KindLabel, MetaName: "label-1", StateStatus: pkger.StateStatusExists},
+					New:            pkger.DiffLabelValues{Name: "label-1", Color: "#fff"},
+					Old:            &pkger.DiffLabelValues{Name: "label-1", Color: "#fff"},
+				},
+				{
+					DiffIdentifier: pkger.DiffIdentifier{ID: 21, Kind: pkger.KindLabel, MetaName: "label-orphan", StateStatus: pkger.StateStatusRemove},
+					New:            pkger.DiffLabelValues{Name: "label-orphan"},
+				},
+			},
+			LabelMappings: []pkger.DiffLabelMapping{
+				{
+					StateStatus: pkger.StateStatusNew,
+					ResType:     pkger.KindBucket.ResourceType(),
+					ResID:       10,
+					ResMetaName: "bucket-1",
+					LabelID:     20,
+					LabelName:   "label-1",
+				},
+			},
+		},
+	}
+
+	report := pkger.NewDriftReport(stack, impact)
+
+	assert.Equal(t, pkger.SafeID(1), report.StackID)
+	assert.Equal(t, impact.Sources, report.Sources)
+	assert.True(t, report.HasDrift())
+
+	expected := []pkger.DriftResource{
+		{Kind: pkger.KindBucket, MetaName: "bucket-1", ID: 10, Status: pkger.DriftStatusModified, Fields: []string{"description", "labels"}},
+		{Kind: pkger.KindBucket, MetaName: "bucket-deleted", Status: pkger.DriftStatusMissing},
+		{Kind: pkger.KindBucket, MetaName: "bucket-new", Status: pkger.DriftStatusUnapplied},
+		{Kind: pkger.KindLabel, MetaName: "label-orphan", ID: 21, Status: pkger.DriftStatusOrphaned},
+	}
+	assert.Equal(t, expected, report.Resources)
+
+	counts := report.CountByStatus()
+	assert.Equal(t, 1, counts[pkger.DriftStatusModified])
+	assert.Equal(t, 1, counts[pkger.DriftStatusMissing])
+	assert.Equal(t, 1, counts[pkger.DriftStatusUnapplied])
+	assert.Equal(t, 1, counts[pkger.DriftStatusOrphaned])
+}
+
+func TestNewDriftReport_NoDrift(t *testing.T) {
+	impact := pkger.ImpactSummary{
+		Diff: pkger.Diff{
+			Variables: []pkger.DiffVariable{
+				{
+					DiffIdentifier: pkger.DiffIdentifier{ID: 1, Kind: pkger.KindVariable, MetaName: "var-1", StateStatus: pkger.StateStatusExists},
+					New:            pkger.DiffVariableValues{Name: "var-1"},
+					// platform defaults that are empty do not count as drift
+					Old: &pkger.DiffVariableValues{Name: "var-1", Description: ""},
+				},
+			},
+		},
+	}
+
+	report := pkger.NewDriftReport(pkger.Stack{ID: 1}, impact)
+	assert.False(t, report.HasDrift())
+	assert.Empty(t, report.Resources)
+}
+
+func TestReconciler(t *testing.T) {
+	const (
+		orgID   = platform.ID(1)
+		stackID = platform.ID(2)
+	)
+
+	newStack := func(urls ...string) pkger.Stack {
+		return pkger.Stack{
+			ID:    stackID,
+			OrgID: orgID,
+			Events: []pkger.StackEvent{{
+				TemplateURLs: urls,
+				Resources: []pkger.StackResource{
+					{Kind: pkger.KindBucket, MetaName: "bucket-1", ID: 10},
+				},
+			}},
+		}
+	}
+
+	driftedImpact := pkger.ImpactSummary{
+		Diff: pkger.Diff{
+			Buckets: []pkger.DiffBucket{{
+				DiffIdentifier: pkger.DiffIdentifier{Kind: pkger.KindBucket, MetaName: "bucket-1", StateStatus: pkger.StateStatusNew},
+				New:            pkger.DiffBucketValues{Name: "bucket-1"},
+			}},
+		},
+	}
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("reapplies a drifted stack", func(t *testing.T) {
+		var applied int
+		svc := &fakeSVC{
+			readStackFn: func(ctx context.Context, id platform.ID) (pkger.Stack, error) {
+				return newStack("https://example.com/template.yml"), nil
+			},
+			dryRunFn: func(ctx context.Context, oID, userID platform.ID, opts ...pkger.ApplyOptFn) (pkger.ImpactSummary, error) {
+				return driftedImpact, nil
+			},
+			applyFn: func(ctx context.Context, oID, userID platform.ID, opts ...pkger.ApplyOptFn) (pkger.ImpactSummary, error) {
+				applied++
+				assert.Equal(t, orgID, oID)
+				return pkger.ImpactSummary{}, nil
+			},
+		}
+
+		var events []pkger.ReconcileEvent
+		r := pkger.NewReconciler(svc, orgID, stackID,
+			pkger.WithReconcileTimeGenerator(mock.TimeGenerator{FakeValue: now}),
+			pkger.WithReconcileNotifier(func(e pkger.ReconcileEvent) {
+				events = append(events, e)
+			}),
+		)
+
+		event, err := r.Reconcile(context.Background())
+		require.NoError(t, err)
+
+		assert.True(t, event.Applied)
+		assert.Equal(t, 1, applied)
+		assert.Equal(t, now, event.Report.CheckedAt)
+		require.Len(t, event.Report.Resources, 1)
+		assert.Equal(t, pkger.DriftStatusMissing, event.Report.Resources[0].Status)
+		assert.Equal(t, []pkger.ReconcileEvent{event}, events)
+	})
+
+	t.Run("dry run never applies", func(t *testing.T) {
+		svc := &fakeSVC{
+			readStackFn: func(ctx context.Context, id platform.ID) (pkger.Stack, error) {
+				return newStack(), nil
+			},
+			dryRunFn: func(ctx context.Context, oID, userID platform.ID, opts ...pkger.ApplyOptFn) (pkger.ImpactSummary, error) {
+				return driftedImpact, nil
+			},
+		}
+
+		r := pkger.NewReconciler(svc, orgID, stackID,
+			pkger.WithReconcileDryRun(true),
+			pkger.WithReconcileTemplates(func() ([]*pkger.Template, error) {
+				return []*pkger.Template{new(pkger.Template)}, nil
+			}),
+		)
+
+		event, err := r.Reconcile(context.Background())
+		require.NoError(t, err)
+		assert.False(t, event.Applied)
+		assert.True(t, event.Report.HasDrift())
+	})
+
+	t.Run("errors without any templates to reconcile against", func(t *testing.T) {
+		svc := &fakeSVC{
+			readStackFn: func(ctx context.Context, id platform.ID) (pkger.Stack, error) {
+				return newStack(), nil
+			},
+		}
+
+		var events []pkger.ReconcileEvent
+		r := pkger.NewReconciler(svc, orgID, stackID, pkger.WithReconcileNotifier(func(e pkger.ReconcileEvent) {
+			events = append(events, e)
+		}))
+
+		_, err := r.Reconcile(context.Background())
+		require.Error(t, err)
+		require.Len(t, events, 1)
+		assert.NotEmpty(t, events[0].Err)
+	})
+
+	t.Run("template read errors are surfaced", func(t *testing.T) {
+		svc := &fakeSVC{
+			readStackFn: func(ctx context.Context, id platform.ID) (pkger.Stack, error) {
+				return newStack(), nil
+			},
+		}
+
+		expectedErr := errors.New("failed to read template")
+		r := pkger.NewReconciler(svc, orgID, stackID, pkger.WithReconcileTemplates(func() ([]*pkger.Template, error) {
+			return nil, expectedErr
+		}))
+
+		_, err := r.Reconcile(context.Background())
+		assert.Equal(t, expectedErr, err)
+	})
+}
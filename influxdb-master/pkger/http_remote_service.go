@@ -206,6 +206,7 @@ func (s *HTTPRemoteService) apply(ctx context.Context, orgID platform.ID, dryRun
 		OrgID:       orgID.String(),
 		DryRun:      dryRun,
 		EnvRefs:     opt.EnvRefs,
+		Params:      opt.Params,
 		Secrets:     opt.MissingSecrets,
 		RawTemplate: rawTemplate,
 	}
@@ -418,12 +418,16 @@ func stackResLinks(r StackResource) RespStackResourceLinks {
 	switch r.Kind {
 	case KindBucket:
 		linkResource = "buckets"
-	case KindCheck, KindCheckDeadman, KindCheckThreshold:
+	case KindCheck, KindCheckDeadman, KindCheckThreshold, KindCheckAnomaly:
 		linkResource = "checks"
 	case KindDashboard:
 		linkResource = "dashboards"
+	case KindDBRPMapping:
+		linkResource = "dbrps"
 	case KindLabel:
 		linkResource = "labels"
+	case KindNotebook:
+		linkResource = "notebooks"
 	case KindNotificationEndpoint,
 		KindNotificationEndpointHTTP,
 		KindNotificationEndpointPagerDuty,
@@ -431,10 +435,14 @@ func stackResLinks(r StackResource) RespStackResourceLinks {
 		linkResource = "notificationEndpoints"
 	case KindNotificationRule:
 		linkResource = "notificationRules"
+	case KindScraper:
+		linkResource = "scrapers"
 	case KindTask:
 		linkResource = "tasks"
 	case KindTelegraf:
 		linkResource = "telegrafs"
+	case KindV1Authorization:
+		linkResource = "legacy/authorizations"
 	case KindVariable:
 		linkResource = "variables"
 	}
@@ -206,6 +206,7 @@ type ReqApply struct {
 	RawTemplate  ReqRawTemplate   `json:"template" yaml:"template"`
 
 	EnvRefs map[string]interface{} `json:"envRefs"`
+	Params  map[string]interface{} `json:"params"`
 	Secrets map[string]string      `json:"secrets"`
 
 	RawActions []ReqRawAction `json:"actions"`
@@ -361,6 +362,7 @@ func (s *HTTPServerTemplates) apply(w http.ResponseWriter, r *http.Request) {
 
 	applyOpts := []ApplyOptFn{
 		ApplyWithEnvRefs(reqBody.EnvRefs),
+		ApplyWithParams(reqBody.Params),
 		ApplyWithTemplate(parsedTemplate),
 		ApplyWithStackID(stackID),
 	}
@@ -434,12 +436,18 @@ func impactToRespApply(impact ImpactSummary, err error) RespApply {
 	if out.Diff.Dashboards == nil {
 		out.Diff.Dashboards = []DiffDashboard{}
 	}
+	if out.Diff.DBRPMappings == nil {
+		out.Diff.DBRPMappings = []DiffDBRPMapping{}
+	}
 	if out.Diff.Labels == nil {
 		out.Diff.Labels = []DiffLabel{}
 	}
 	if out.Diff.LabelMappings == nil {
 		out.Diff.LabelMappings = []DiffLabelMapping{}
 	}
+	if out.Diff.Notebooks == nil {
+		out.Diff.Notebooks = []DiffNotebook{}
+	}
 	if out.Diff.NotificationEndpoints == nil {
 		out.Diff.NotificationEndpoints = []DiffNotificationEndpoint{}
 	}
@@ -449,12 +457,18 @@ func impactToRespApply(impact ImpactSummary, err error) RespApply {
 	if out.Diff.NotificationRules == nil {
 		out.Diff.NotificationRules = []DiffNotificationRule{}
 	}
+	if out.Diff.Scrapers == nil {
+		out.Diff.Scrapers = []DiffScraper{}
+	}
 	if out.Diff.Tasks == nil {
 		out.Diff.Tasks = []DiffTask{}
 	}
 	if out.Diff.Telegrafs == nil {
 		out.Diff.Telegrafs = []DiffTelegraf{}
 	}
+	if out.Diff.V1Authorizations == nil {
+		out.Diff.V1Authorizations = []DiffV1Authorization{}
+	}
 	if out.Diff.Variables == nil {
 		out.Diff.Variables = []DiffVariable{}
 	}
@@ -468,12 +482,18 @@ func impactToRespApply(impact ImpactSummary, err error) RespApply {
 	if out.Summary.Dashboards == nil {
 		out.Summary.Dashboards = []SummaryDashboard{}
 	}
+	if out.Summary.DBRPMappings == nil {
+		out.Summary.DBRPMappings = []SummaryDBRPMapping{}
+	}
 	if out.Summary.Labels == nil {
 		out.Summary.Labels = []SummaryLabel{}
 	}
 	if out.Summary.LabelMappings == nil {
 		out.Summary.LabelMappings = []SummaryLabelMapping{}
 	}
+	if out.Summary.Notebooks == nil {
+		out.Summary.Notebooks = []SummaryNotebook{}
+	}
 	if out.Summary.NotificationEndpoints == nil {
 		out.Summary.NotificationEndpoints = []SummaryNotificationEndpoint{}
 	}
@@ -483,12 +503,18 @@ func impactToRespApply(impact ImpactSummary, err error) RespApply {
 	if out.Summary.NotificationRules == nil {
 		out.Summary.NotificationRules = []SummaryNotificationRule{}
 	}
+	if out.Summary.Scrapers == nil {
+		out.Summary.Scrapers = []SummaryScraper{}
+	}
 	if out.Summary.Tasks == nil {
 		out.Summary.Tasks = []SummaryTask{}
 	}
 	if out.Summary.TelegrafConfigs == nil {
 		out.Summary.TelegrafConfigs = []SummaryTelegraf{}
 	}
+	if out.Summary.V1Authorizations == nil {
+		out.Summary.V1Authorizations = []SummaryV1Authorization{}
+	}
 	if out.Summary.Variables == nil {
 		out.Summary.Variables = []SummaryVariable{}
 	}
@@ -21,16 +21,22 @@ const (
 	KindCheck                         Kind = "Check"
 	KindCheckDeadman                  Kind = "CheckDeadman"
 	KindCheckThreshold                Kind = "CheckThreshold"
+	KindCheckAnomaly                  Kind = "CheckAnomaly"
 	KindDashboard                     Kind = "Dashboard"
+	KindDBRPMapping                   Kind = "DBRPMapping"
 	KindLabel                         Kind = "Label"
+	KindNotebook                      Kind = "Notebook"
 	KindNotificationEndpoint          Kind = "NotificationEndpoint"
 	KindNotificationEndpointHTTP      Kind = "NotificationEndpointHTTP"
 	KindNotificationEndpointPagerDuty Kind = "NotificationEndpointPagerDuty"
 	KindNotificationEndpointSlack     Kind = "NotificationEndpointSlack"
 	KindNotificationRule              Kind = "NotificationRule"
 	KindPackage                       Kind = "Package"
+	KindParameter                     Kind = "Parameter"
+	KindScraper                       Kind = "Scraper"
 	KindTask                          Kind = "Task"
 	KindTelegraf                      Kind = "Telegraf"
+	KindV1Authorization               Kind = "V1Authorization"
 	KindVariable                      Kind = "Variable"
 )
 
@@ -48,15 +54,21 @@ var kinds = map[Kind]bool{
 	KindCheck:                         true,
 	KindCheckDeadman:                  true,
 	KindCheckThreshold:                true,
+	KindCheckAnomaly:                  true,
 	KindDashboard:                     true,
+	KindDBRPMapping:                   true,
 	KindLabel:                         true,
+	KindNotebook:                      true,
 	KindNotificationEndpoint:          true,
 	KindNotificationEndpointHTTP:      true,
 	KindNotificationEndpointPagerDuty: true,
 	KindNotificationEndpointSlack:     true,
 	KindNotificationRule:              true,
+	KindParameter:                     true,
+	KindScraper:                       true,
 	KindTask:                          true,
 	KindTelegraf:                      true,
+	KindV1Authorization:               true,
 	KindVariable:                      true,
 }
 
@@ -90,10 +102,12 @@ func (k Kind) ResourceType() influxdb.ResourceType {
 	switch k {
 	case KindBucket:
 		return influxdb.BucketsResourceType
-	case KindCheck, KindCheckDeadman, KindCheckThreshold:
+	case KindCheck, KindCheckDeadman, KindCheckThreshold, KindCheckAnomaly:
 		return influxdb.ChecksResourceType
 	case KindDashboard:
 		return influxdb.DashboardsResourceType
+	case KindDBRPMapping:
+		return influxdb.DBRPResourceType
 	case KindLabel:
 		return influxdb.LabelsResourceType
 	case KindNotificationEndpoint,
@@ -103,10 +117,14 @@ func (k Kind) ResourceType() influxdb.ResourceType {
 		return influxdb.NotificationEndpointResourceType
 	case KindNotificationRule:
 		return influxdb.NotificationRuleResourceType
+	case KindScraper:
+		return influxdb.ScraperResourceType
 	case KindTask:
 		return influxdb.TasksResourceType
 	case KindTelegraf:
 		return influxdb.TelegrafsResourceType
+	case KindV1Authorization:
+		return influxdb.AuthorizationsResourceType
 	case KindVariable:
 		return influxdb.VariablesResourceType
 	default:
@@ -159,12 +177,16 @@ type Diff struct {
 	Buckets               []DiffBucket               `json:"buckets"`
 	Checks                []DiffCheck                `json:"checks"`
 	Dashboards            []DiffDashboard            `json:"dashboards"`
+	DBRPMappings          []DiffDBRPMapping          `json:"dbrpMappings"`
 	Labels                []DiffLabel                `json:"labels"`
 	LabelMappings         []DiffLabelMapping         `json:"labelMappings"`
+	Notebooks             []DiffNotebook             `json:"notebooks"`
 	NotificationEndpoints []DiffNotificationEndpoint `json:"notificationEndpoints"`
 	NotificationRules     []DiffNotificationRule     `json:"notificationRules"`
+	Scrapers              []DiffScraper              `json:"scrapers"`
 	Tasks                 []DiffTask                 `json:"tasks"`
 	Telegrafs             []DiffTelegraf             `json:"telegrafConfigs"`
+	V1Authorizations      []DiffV1Authorization      `json:"v1Authorizations"`
 	Variables             []DiffVariable             `json:"variables"`
 }
 
@@ -277,6 +299,24 @@ func (d *DiffChart) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+type (
+	// DiffDBRPMapping is a diff of an individual dbrp mapping.
+	DiffDBRPMapping struct {
+		DiffIdentifier
+
+		New DiffDBRPMappingValues  `json:"new"`
+		Old *DiffDBRPMappingValues `json:"old"`
+	}
+
+	// DiffDBRPMappingValues are the varying values for a dbrp mapping.
+	DiffDBRPMappingValues struct {
+		Database        string `json:"database"`
+		RetentionPolicy string `json:"retentionPolicy"`
+		Default         bool   `json:"default"`
+		BucketID        SafeID `json:"bucketID"`
+	}
+)
+
 type (
 	// DiffLabel is a diff of an individual label.
 	DiffLabel struct {
@@ -327,6 +367,22 @@ type DiffLabelMapping struct {
 //	return d.StateStatus == StateStatusNew
 //}
 
+type (
+	// DiffNotebook is a diff of an individual notebook.
+	DiffNotebook struct {
+		DiffIdentifier
+
+		New DiffNotebookValues  `json:"new"`
+		Old *DiffNotebookValues `json:"old"`
+	}
+
+	// DiffNotebookValues are the varying values for a notebook.
+	DiffNotebookValues struct {
+		Name    string                 `json:"name"`
+		Content map[string]interface{} `json:"content"`
+	}
+)
+
 // DiffNotificationEndpointValues are the varying values for a notification endpoint.
 type DiffNotificationEndpointValues struct {
 	influxdb.NotificationEndpoint
@@ -384,6 +440,25 @@ type (
 	}
 )
 
+type (
+	// DiffScraper is a diff of an individual scraper target.
+	DiffScraper struct {
+		DiffIdentifier
+
+		New DiffScraperValues  `json:"new"`
+		Old *DiffScraperValues `json:"old"`
+	}
+
+	// DiffScraperValues are the varying values for a scraper target.
+	DiffScraperValues struct {
+		Name          string               `json:"name"`
+		Type          influxdb.ScraperType `json:"type"`
+		URL           string               `json:"url"`
+		BucketID      SafeID               `json:"bucketID"`
+		AllowInsecure bool                 `json:"allowInsecure"`
+	}
+)
+
 type (
 	// DiffTask is a diff of an individual task.
 	DiffTask struct {
@@ -413,6 +488,25 @@ type DiffTelegraf struct {
 	Old *influxdb.TelegrafConfig `json:"old"`
 }
 
+type (
+	// DiffV1Authorization is a diff of an individual v1 authorization. The
+	// password is never part of the diff.
+	DiffV1Authorization struct {
+		DiffIdentifier
+
+		New DiffV1AuthorizationValues  `json:"new"`
+		Old *DiffV1AuthorizationValues `json:"old"`
+	}
+
+	// DiffV1AuthorizationValues are the varying values for a v1 authorization.
+	DiffV1AuthorizationValues struct {
+		Token       string                `json:"token"`
+		Description string                `json:"description"`
+		Status      influxdb.Status       `json:"status"`
+		Permissions []SummaryV1Permission `json:"permissions"`
+	}
+)
+
 type (
 	// DiffVariable is a diff of an individual variable.
 	DiffVariable struct {
@@ -440,14 +534,20 @@ type Summary struct {
 	Buckets               []SummaryBucket               `json:"buckets"`
 	Checks                []SummaryCheck                `json:"checks"`
 	Dashboards            []SummaryDashboard            `json:"dashboards"`
+	DBRPMappings          []SummaryDBRPMapping          `json:"dbrpMappings"`
+	Notebooks             []SummaryNotebook             `json:"notebooks"`
 	NotificationEndpoints []SummaryNotificationEndpoint `json:"notificationEndpoints"`
 	NotificationRules     []SummaryNotificationRule     `json:"notificationRules"`
 	Labels                []SummaryLabel                `json:"labels"`
 	LabelMappings         []SummaryLabelMapping         `json:"labelMappings"`
 	MissingEnvs           []string                      `json:"missingEnvRefs"`
+	MissingParams         []string                      `json:"missingParams"`
 	MissingSecrets        []string                      `json:"missingSecrets"`
+	Parameters            []SummaryParameter            `json:"parameters"`
+	Scrapers              []SummaryScraper              `json:"scrapers"`
 	Tasks                 []SummaryTask                 `json:"summaryTask"`
 	TelegrafConfigs       []SummaryTelegraf             `json:"telegrafConfigs"`
+	V1Authorizations      []SummaryV1Authorization      `json:"v1Authorizations"`
 	Variables             []SummaryVariable             `json:"variables"`
 }
 
@@ -561,6 +661,29 @@ func (s *SummaryChart) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// SummaryDBRPMapping provides a summary of a pkg dbrp mapping.
+type SummaryDBRPMapping struct {
+	SummaryIdentifier
+	ID              SafeID `json:"id"`
+	OrgID           SafeID `json:"orgID"`
+	Database        string `json:"database"`
+	RetentionPolicy string `json:"retentionPolicy"`
+	Default         bool   `json:"default"`
+
+	// These fields represent the relationship of the mapping to the bucket.
+	BucketID   SafeID `json:"bucketID"`
+	BucketName string `json:"bucketName"`
+}
+
+// SummaryNotebook provides a summary of a pkg notebook.
+type SummaryNotebook struct {
+	SummaryIdentifier
+	ID      SafeID                 `json:"id"`
+	OrgID   SafeID                 `json:"orgID"`
+	Name    string                 `json:"name"`
+	Content map[string]interface{} `json:"content"`
+}
+
 // SummaryNotificationEndpoint provides a summary of a pkg notification endpoint.
 type SummaryNotificationEndpoint struct {
 	SummaryIdentifier
@@ -648,6 +771,18 @@ type SummaryLabelMapping struct {
 	LabelID          SafeID                `json:"labelID"`
 }
 
+// SummaryParameter provides a summary of a template parameter and the
+// value it resolved to.
+type SummaryParameter struct {
+	Kind        Kind        `json:"kind"`
+	MetaName    string      `json:"templateMetaName"`
+	Type        string      `json:"type"`
+	Description string      `json:"description"`
+	Required    bool        `json:"required"`
+	Default     interface{} `json:"defaultValue"`
+	Value       interface{} `json:"value"`
+}
+
 // SummaryReference informs the consumer of required references for
 // this resource.
 type SummaryReference struct {
@@ -658,6 +793,21 @@ type SummaryReference struct {
 	DefaultValue interface{} `json:"defaultValue"`
 }
 
+// SummaryScraper provides a summary of a pkg scraper target.
+type SummaryScraper struct {
+	SummaryIdentifier
+	ID            SafeID               `json:"id"`
+	OrgID         SafeID               `json:"orgID"`
+	Name          string               `json:"name"`
+	Type          influxdb.ScraperType `json:"type"`
+	URL           string               `json:"url"`
+	AllowInsecure bool                 `json:"allowInsecure"`
+
+	// These fields represent the relationship of the scraper to the bucket.
+	BucketID   SafeID `json:"bucketID"`
+	BucketName string `json:"bucketName"`
+}
+
 // SummaryTask provides a summary of a task.
 type SummaryTask struct {
 	SummaryIdentifier
@@ -681,6 +831,25 @@ type SummaryTelegraf struct {
 	LabelAssociations []SummaryLabel `json:"labelAssociations"`
 }
 
+// Summary types for V1Authorizations which provide a summary of a pkg v1 authorization.
+type (
+	SummaryV1Authorization struct {
+		SummaryIdentifier
+		ID          SafeID                `json:"id"`
+		OrgID       SafeID                `json:"orgID"`
+		Token       string                `json:"token"`
+		Description string                `json:"description"`
+		Status      influxdb.Status       `json:"status"`
+		Permissions []SummaryV1Permission `json:"permissions"`
+	}
+
+	SummaryV1Permission struct {
+		Action     influxdb.Action `json:"action"`
+		BucketID   SafeID          `json:"bucketID"`
+		BucketName string          `json:"bucketName"`
+	}
+)
+
 // SummaryVariable provides a summary of a pkg variable.
 type SummaryVariable struct {
 	SummaryIdentifier
@@ -352,16 +352,25 @@ type Template struct {
 	mBuckets               map[string]*bucket
 	mChecks                map[string]*check
 	mDashboards            map[string]*dashboard
+	mDBRPMappings          map[string]*dbrpMapping
+	mNotebooks             map[string]*notebook
 	mNotificationEndpoints map[string]*notificationEndpoint
 	mNotificationRules     map[string]*notificationRule
+	mScrapers              map[string]*scraper
 	mTasks                 map[string]*task
 	mTelegrafs             map[string]*telegraf
+	mV1Authorizations      map[string]*v1Authorization
 	mVariables             map[string]*variable
 
 	mEnv     map[string]bool
 	mEnvVals map[string]interface{}
 	mSecrets map[string]bool
 
+	mParams    map[string]*parameter
+	mParamVals map[string]interface{}
+	resolved   []Object // objects with parameters interpolated, indexed as Objects
+	skipped    []bool   // objects excluded by their condition, indexed as Objects
+
 	isParsed bool // indicates the pkg has been parsed and all resources graphed accordingly
 }
 
@@ -412,13 +421,19 @@ func (p *Template) Summary() Summary {
 		Buckets:               []SummaryBucket{},
 		Checks:                []SummaryCheck{},
 		Dashboards:            []SummaryDashboard{},
+		DBRPMappings:          []SummaryDBRPMapping{},
+		Notebooks:             []SummaryNotebook{},
 		NotificationEndpoints: []SummaryNotificationEndpoint{},
 		NotificationRules:     []SummaryNotificationRule{},
 		Labels:                []SummaryLabel{},
 		MissingEnvs:           p.missingEnvRefs(),
+		MissingParams:         p.missingParams(),
 		MissingSecrets:        p.missingSecrets(),
+		Parameters:            []SummaryParameter{},
+		Scrapers:              []SummaryScraper{},
 		Tasks:                 []SummaryTask{},
 		TelegrafConfigs:       []SummaryTelegraf{},
+		V1Authorizations:      []SummaryV1Authorization{},
 		Variables:             []SummaryVariable{},
 	}
 
@@ -434,12 +449,24 @@ func (p *Template) Summary() Summary {
 		sum.Dashboards = append(sum.Dashboards, d.summarize())
 	}
 
+	for _, d := range p.dbrpMappings() {
+		sum.DBRPMappings = append(sum.DBRPMappings, d.summarize())
+	}
+
 	for _, l := range p.labels() {
 		sum.Labels = append(sum.Labels, l.summarize())
 	}
 
 	sum.LabelMappings = p.labelMappings()
 
+	for _, param := range p.parameters() {
+		sum.Parameters = append(sum.Parameters, param.summarize())
+	}
+
+	for _, n := range p.notebooks() {
+		sum.Notebooks = append(sum.Notebooks, n.summarize())
+	}
+
 	for _, n := range p.notificationEndpoints() {
 		sum.NotificationEndpoints = append(sum.NotificationEndpoints, n.summarize())
 	}
@@ -448,6 +475,10 @@ func (p *Template) Summary() Summary {
 		sum.NotificationRules = append(sum.NotificationRules, r.summarize())
 	}
 
+	for _, s := range p.scrapers() {
+		sum.Scrapers = append(sum.Scrapers, s.summarize())
+	}
+
 	for _, t := range p.tasks() {
 		sum.Tasks = append(sum.Tasks, t.summarize())
 	}
@@ -456,6 +487,10 @@ func (p *Template) Summary() Summary {
 		sum.TelegrafConfigs = append(sum.TelegrafConfigs, t.summarize())
 	}
 
+	for _, a := range p.v1Authorizations() {
+		sum.V1Authorizations = append(sum.V1Authorizations, a.summarize())
+	}
+
 	for _, v := range p.variables() {
 		sum.Variables = append(sum.Variables, v.summarize())
 	}
@@ -492,12 +527,18 @@ func (p *Template) Contains(k Kind, pkgName string) bool {
 	case KindBucket:
 		_, ok := p.mBuckets[pkgName]
 		return ok
-	case KindCheck, KindCheckDeadman, KindCheckThreshold:
+	case KindCheck, KindCheckDeadman, KindCheckThreshold, KindCheckAnomaly:
 		_, ok := p.mChecks[pkgName]
 		return ok
+	case KindDBRPMapping:
+		_, ok := p.mDBRPMappings[pkgName]
+		return ok
 	case KindLabel:
 		_, ok := p.mLabels[pkgName]
 		return ok
+	case KindNotebook:
+		_, ok := p.mNotebooks[pkgName]
+		return ok
 	case KindNotificationEndpoint,
 		KindNotificationEndpointHTTP,
 		KindNotificationEndpointPagerDuty,
@@ -507,12 +548,21 @@ func (p *Template) Contains(k Kind, pkgName string) bool {
 	case KindNotificationRule:
 		_, ok := p.mNotificationRules[pkgName]
 		return ok
+	case KindParameter:
+		_, ok := p.mParams[pkgName]
+		return ok
+	case KindScraper:
+		_, ok := p.mScrapers[pkgName]
+		return ok
 	case KindTask:
 		_, ok := p.mTasks[pkgName]
 		return ok
 	case KindTelegraf:
 		_, ok := p.mTelegrafs[pkgName]
 		return ok
+	case KindV1Authorization:
+		_, ok := p.mV1Authorizations[pkgName]
+		return ok
 	case KindVariable:
 		_, ok := p.mVariables[pkgName]
 		return ok
@@ -637,6 +687,24 @@ func (p *Template) dashboards() []*dashboard {
 	return dashes
 }
 
+func (p *Template) dbrpMappings() []*dbrpMapping {
+	mappings := make([]*dbrpMapping, 0, len(p.mDBRPMappings))
+	for _, d := range p.mDBRPMappings {
+		mappings = append(mappings, d)
+	}
+	sort.Slice(mappings, func(i, j int) bool { return mappings[i].MetaName() < mappings[j].MetaName() })
+	return mappings
+}
+
+func (p *Template) notebooks() []*notebook {
+	notebooks := make([]*notebook, 0, len(p.mNotebooks))
+	for _, n := range p.mNotebooks {
+		notebooks = append(notebooks, n)
+	}
+	sort.Slice(notebooks, func(i, j int) bool { return notebooks[i].MetaName() < notebooks[j].MetaName() })
+	return notebooks
+}
+
 func (p *Template) notificationEndpoints() []*notificationEndpoint {
 	endpoints := make([]*notificationEndpoint, 0, len(p.mNotificationEndpoints))
 	for _, e := range p.mNotificationEndpoints {
@@ -661,6 +729,15 @@ func (p *Template) notificationRules() []*notificationRule {
 	return rules
 }
 
+func (p *Template) scrapers() []*scraper {
+	scrapers := make([]*scraper, 0, len(p.mScrapers))
+	for _, s := range p.mScrapers {
+		scrapers = append(scrapers, s)
+	}
+	sort.Slice(scrapers, func(i, j int) bool { return scrapers[i].MetaName() < scrapers[j].MetaName() })
+	return scrapers
+}
+
 func (p *Template) missingEnvRefs() []string {
 	envRefs := make([]string, 0)
 	for envRef, matching := range p.mEnv {
@@ -706,6 +783,17 @@ func (p *Template) telegrafs() []*telegraf {
 	return teles
 }
 
+func (p *Template) v1Authorizations() []*v1Authorization {
+	auths := make([]*v1Authorization, 0, len(p.mV1Authorizations))
+	for _, a := range p.mV1Authorizations {
+		auths = append(auths, a)
+	}
+
+	sort.Slice(auths, func(i, j int) bool { return auths[i].MetaName() < auths[j].MetaName() })
+
+	return auths
+}
+
 func (p *Template) variables() []*variable {
 	vars := make([]*variable, 0, len(p.mVariables))
 	for _, v := range p.mVariables {
@@ -770,6 +858,13 @@ func (p *Template) graphResources() error {
 	p.mEnv = make(map[string]bool)
 	p.mSecrets = make(map[string]bool)
 
+	// parameters are resolved before all else, as every other resource
+	// is graphed from the objects interpolated with their values.
+	var pErr parseErr
+	if err := p.graphParameters(); err != nil {
+		pErr.append(err.Resources...)
+	}
+
 	graphFns := []func() *parseErr{
 		// labels are first, this is to validate associations with other resources
 		p.graphLabels,
@@ -781,9 +876,12 @@ func (p *Template) graphResources() error {
 		p.graphNotificationRules,
 		p.graphTasks,
 		p.graphTelegrafs,
+		p.graphDBRPMappings,
+		p.graphNotebooks,
+		p.graphScrapers,
+		p.graphV1Authorizations,
 	}
 
-	var pErr parseErr
 	for _, fn := range graphFns {
 		if err := fn(); err != nil {
 			pErr.append(err.Resources...)
@@ -871,6 +969,7 @@ func (p *Template) graphChecks() *parseErr {
 	}{
 		{kind: KindCheckThreshold, checkKind: checkKindThreshold},
 		{kind: KindCheckDeadman, checkKind: checkKindDeadman},
+		{kind: KindCheckAnomaly, checkKind: checkKindAnomaly},
 	}
 	var pErr parseErr
 	for _, checkKind := range checkKinds {
@@ -883,10 +982,13 @@ func (p *Template) graphChecks() *parseErr {
 			ch := &check{
 				kind:          checkKind.checkKind,
 				identity:      ident,
+				baseline:      o.Spec.durationShort(fieldCheckBaseline),
 				description:   o.Spec.stringShort(fieldDescription),
 				every:         o.Spec.durationShort(fieldEvery),
 				level:         o.Spec.stringShort(fieldLevel),
+				method:        normStr(o.Spec.stringShort(fieldCheckMethod)),
 				offset:        o.Spec.durationShort(fieldOffset),
+				period:        o.Spec.durationShort(fieldCheckPeriod),
 				query:         strings.TrimSpace(o.Spec.stringShort(fieldQuery)),
 				reportZero:    o.Spec.boolShort(fieldCheckReportZero),
 				staleTime:     o.Spec.durationShort(fieldCheckStaleTime),
@@ -1204,6 +1306,118 @@ func (p *Template) graphTelegrafs() *parseErr {
 	})
 }
 
+func (p *Template) graphDBRPMappings() *parseErr {
+	p.mDBRPMappings = make(map[string]*dbrpMapping)
+	tracker := p.trackNames(false)
+	return p.eachResource(KindDBRPMapping, func(o Object) []validationErr {
+		ident, errs := tracker(o)
+		if len(errs) > 0 {
+			return errs
+		}
+
+		mapping := &dbrpMapping{
+			identity:        ident,
+			database:        o.Spec.stringShort(fieldDBRPDatabase),
+			retentionPolicy: o.Spec.stringShort(fieldDBRPRetentionPolicy),
+			isDefault:       o.Spec.boolShort(fieldDefault),
+			bucket:          p.getRefWithKnownEnvs(o.Spec, fieldDBRPBucket),
+		}
+
+		p.mDBRPMappings[mapping.MetaName()] = mapping
+		p.setRefs(mapping.name, mapping.displayName, mapping.bucket)
+
+		return mapping.valid()
+	})
+}
+
+func (p *Template) graphNotebooks() *parseErr {
+	p.mNotebooks = make(map[string]*notebook)
+	tracker := p.trackNames(false)
+	return p.eachResource(KindNotebook, func(o Object) []validationErr {
+		ident, errs := tracker(o)
+		if len(errs) > 0 {
+			return errs
+		}
+
+		nb := &notebook{identity: ident}
+		if content, ok := ifaceToJSONObject(o.Spec[fieldNotebookContent]).(map[string]interface{}); ok {
+			nb.content = content
+		}
+
+		p.mNotebooks[nb.MetaName()] = nb
+		p.setRefs(nb.name, nb.displayName)
+
+		return nb.valid()
+	})
+}
+
+func (p *Template) graphScrapers() *parseErr {
+	p.mScrapers = make(map[string]*scraper)
+	tracker := p.trackNames(false)
+	return p.eachResource(KindScraper, func(o Object) []validationErr {
+		ident, errs := tracker(o)
+		if len(errs) > 0 {
+			return errs
+		}
+
+		s := &scraper{
+			identity:      ident,
+			scraperType:   normStr(o.Spec.stringShort(fieldType)),
+			url:           o.Spec.stringShort(fieldScraperURL),
+			allowInsecure: o.Spec.boolShort(fieldScraperAllowInsecure),
+			bucket:        p.getRefWithKnownEnvs(o.Spec, fieldScraperBucket),
+		}
+
+		p.mScrapers[s.MetaName()] = s
+		p.setRefs(s.name, s.displayName, s.bucket)
+
+		return s.valid()
+	})
+}
+
+func (p *Template) graphV1Authorizations() *parseErr {
+	p.mV1Authorizations = make(map[string]*v1Authorization)
+	tracker := p.trackNames(false)
+	uniqTokens := make(map[string]bool)
+	return p.eachResource(KindV1Authorization, func(o Object) []validationErr {
+		ident, errs := tracker(o)
+		if len(errs) > 0 {
+			return errs
+		}
+
+		auth := &v1Authorization{
+			identity:    ident,
+			token:       o.Spec.stringShort(fieldV1AuthToken),
+			description: o.Spec.stringShort(fieldDescription),
+			status:      normStr(o.Spec.stringShort(fieldStatus)),
+			password:    o.Spec.references(fieldV1AuthPassword),
+		}
+		for _, perm := range o.Spec.slcResource(fieldV1AuthPermissions) {
+			auth.permissions = append(auth.permissions, v1Permission{
+				action: normStr(perm.stringShort(fieldV1AuthAction)),
+				bucket: perm.stringShort(fieldV1AuthBucket),
+			})
+		}
+
+		if uniqTokens[auth.token] {
+			return []validationErr{
+				objectValidationErr(fieldSpec, validationErr{
+					Field: fieldV1AuthToken,
+					Msg:   "duplicate token: " + auth.token,
+				}),
+			}
+		}
+		if auth.token != "" {
+			uniqTokens[auth.token] = true
+		}
+
+		p.mV1Authorizations[auth.MetaName()] = auth
+		p.setRefs(auth.name, auth.displayName, auth.password)
+
+		return auth.valid()
+	})
+}
+
 func (p *Template) graphVariables() *parseErr {
 	p.mVariables = make(map[string]*variable)
 	tracker := p.trackNames(true)
@@ -1245,8 +1459,16 @@ func (p *Template) graphVariables() *parseErr {
 }
 
 func (p *Template) eachResource(resourceKind Kind, fn func(o Object) []validationErr) *parseErr {
+	objects := p.Objects
+	if p.resolved != nil {
+		objects = p.resolved
+	}
+
 	var pErr parseErr
-	for i, k := range p.Objects {
+	for i, k := range objects {
+		if p.skipped != nil && p.skipped[i] {
+			continue
+		}
 		if err := k.Kind.OK(); err != nil {
 			pErr.append(resourceErr{
 				Kind: k.Kind.String(),
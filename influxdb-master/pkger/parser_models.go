@@ -191,10 +191,14 @@ type checkKind int
 const (
 	checkKindDeadman checkKind = iota + 1
 	checkKindThreshold
+	checkKindAnomaly
 )
 
 const (
 	fieldCheckAllValues             = "allValues"
+	fieldCheckBaseline              = "baseline"
+	fieldCheckMethod                = "method"
+	fieldCheckPeriod                = "period"
 	fieldCheckReportZero            = "reportZero"
 	fieldCheckStaleTime             = "staleTime"
 	fieldCheckStatusMessageTemplate = "statusMessageTemplate"
@@ -209,10 +213,13 @@ type check struct {
 	identity
 
 	kind          checkKind
+	baseline      time.Duration
 	description   string
 	every         time.Duration
 	level         string
+	method        string
 	offset        time.Duration
+	period        time.Duration
 	query         string
 	reportZero    bool
 	staleTime     time.Duration
@@ -278,6 +285,25 @@ func (c *check) summarize() SummaryCheck {
 			StaleTime:  toNotificationDuration(c.staleTime),
 			TimeSince:  toNotificationDuration(c.timeSince),
 		}
+	case checkKindAnomaly:
+		anomaly := &icheck.Anomaly{
+			Base:   base,
+			Method: c.method,
+		}
+		if c.baseline > 0 {
+			anomaly.Baseline = toNotificationDuration(c.baseline)
+		}
+		if c.period > 0 {
+			anomaly.Period = toNotificationDuration(c.period)
+		}
+		for _, th := range c.thresholds {
+			anomaly.Thresholds = append(anomaly.Thresholds, icheck.AnomalyThreshold{
+				Level: notification.ParseCheckLevel(th.level),
+				Value: th.val,
+			})
+		}
+		sum.Kind = KindCheckAnomaly
+		sum.Check = anomaly
 	}
 	return sum
 }
@@ -326,6 +352,8 @@ func (c *check) valid() []validationErr {
 				vErrs = append(vErrs, fail)
 			}
 		}
+	case checkKindAnomaly:
+		vErrs = append(vErrs, c.validAnomaly()...)
 	}
 
 	if len(vErrs) > 0 {
@@ -337,6 +365,69 @@ func (c *check) valid() []validationErr {
 	return nil
 }
 
+func (c *check) validAnomaly() []validationErr {
+	var vErrs []validationErr
+	switch c.method {
+	case icheck.AnomalyZScore, icheck.AnomalyMAD, icheck.AnomalyPeriod:
+	default:
+		vErrs = append(vErrs, validationErr{
+			Field: fieldCheckMethod,
+			Msg:   fmt.Sprintf("must be 1 in [zscore, mad, period]; got=%q", c.method),
+		})
+	}
+	if c.method != icheck.AnomalyPeriod && c.baseline <= c.every {
+		vErrs = append(vErrs, validationErr{
+			Field: fieldCheckBaseline,
+			Msg:   "duration value must be provided that is > every",
+		})
+	}
+	if c.method != icheck.AnomalyZScore && c.period <= c.every {
+		vErrs = append(vErrs, validationErr{
+			Field: fieldCheckPeriod,
+			Msg:   "duration value must be provided that is > every",
+		})
+	}
+	if c.method == icheck.AnomalyMAD && c.baseline < c.period {
+		vErrs = append(vErrs, validationErr{
+			Field: fieldCheckBaseline,
+			Msg:   "baseline must be >= period",
+		})
+	}
+
+	if len(c.thresholds) == 0 {
+		vErrs = append(vErrs, validationErr{
+			Field: fieldCheckThresholds,
+			Msg:   "must provide at least 1 threshold entry",
+		})
+	}
+	levels := make(map[string]bool)
+	for i, th := range c.thresholds {
+		switch {
+		case notification.ParseCheckLevel(th.level) == notification.Unknown:
+			vErrs = append(vErrs, validationErr{
+				Field: fieldLevel,
+				Index: intPtr(i),
+				Msg:   fmt.Sprintf("must be 1 in [CRIT, WARN, INFO, OK]; got=%q", th.level),
+			})
+		case levels[th.level]:
+			vErrs = append(vErrs, validationErr{
+				Field: fieldLevel,
+				Index: intPtr(i),
+				Msg:   fmt.Sprintf("must be provided by only 1 threshold; got=%q", th.level),
+			})
+		}
+		levels[th.level] = true
+		if th.val < 0 {
+			vErrs = append(vErrs, validationErr{
+				Field: fieldValue,
+				Index: intPtr(i),
+				Msg:   "must be >= 0",
+			})
+		}
+	}
+	return vErrs
+}
+
 type thresholdType string
 
 const (
@@ -1465,6 +1556,80 @@ func (l *associationMapping) setMapping(v interface {
 	l.mappings[k] = append(l.mappings[k], val)
 }
 
+const (
+	fieldDBRPBucket          = "bucket"
+	fieldDBRPDatabase        = "database"
+	fieldDBRPRetentionPolicy = "retentionPolicy"
+)
+
+type dbrpMapping struct {
+	identity
+
+	database        string
+	retentionPolicy string
+	isDefault       bool
+
+	// bucket is the metadata.name of a bucket within the template, or
+	// the name of a bucket that already exists within the platform.
+	bucket *references
+}
+
+func (d *dbrpMapping) ResourceType() influxdb.ResourceType {
+	return KindDBRPMapping.ResourceType()
+}
+
+func (d *dbrpMapping) bucketRef() string {
+	return d.bucket.String()
+}
+
+func (d *dbrpMapping) summarize() SummaryDBRPMapping {
+	refs := d.identity.summarizeReferences()
+	if d.bucket.hasEnvRef() {
+		refs = append(refs, convertRefToRefSummary("spec."+fieldDBRPBucket, d.bucket))
+	}
+	return SummaryDBRPMapping{
+		SummaryIdentifier: SummaryIdentifier{
+			Kind:          KindDBRPMapping,
+			MetaName:      d.MetaName(),
+			EnvReferences: refs,
+		},
+		Database:        d.database,
+		RetentionPolicy: d.retentionPolicy,
+		Default:         d.isDefault,
+		BucketName:      d.bucketRef(),
+	}
+}
+
+func (d *dbrpMapping) valid() []validationErr {
+	var vErrs []validationErr
+	if d.database == "" {
+		vErrs = append(vErrs, validationErr{
+			Field: fieldDBRPDatabase,
+			Msg:   "must provide a database",
+		})
+	}
+	if d.retentionPolicy == "" {
+		vErrs = append(vErrs, validationErr{
+			Field: fieldDBRPRetentionPolicy,
+			Msg:   "must provide a retention policy",
+		})
+	}
+	if d.bucketRef() == "" {
+		vErrs = append(vErrs, validationErr{
+			Field: fieldDBRPBucket,
+			Msg:   "must provide a bucket",
+		})
+	}
+
+	if len(vErrs) > 0 {
+		return []validationErr{
+			objectValidationErr(fieldSpec, vErrs...),
+		}
+	}
+
+	return nil
+}
+
 const (
 	fieldLabelColor = "color"
 )
@@ -1586,6 +1751,90 @@ func (n notificationEndpointKind) String() string {
 	return ""
 }
 
+const (
+	fieldNotebookContent = "content"
+)
+
+type notebook struct {
+	identity
+
+	content map[string]interface{}
+}
+
+func (n *notebook) ResourceType() influxdb.ResourceType {
+	return KindNotebook.ResourceType()
+}
+
+func (n *notebook) summarize() SummaryNotebook {
+	return SummaryNotebook{
+		SummaryIdentifier: SummaryIdentifier{
+			Kind:          KindNotebook,
+			MetaName:      n.MetaName(),
+			EnvReferences: n.identity.summarizeReferences(),
+		},
+		Name:    n.Name(),
+		Content: n.content,
+	}
+}
+
+func (n *notebook) valid() []validationErr {
+	var vErrs []validationErr
+	if err, ok := isValidName(n.Name(), 1); !ok {
+		vErrs = append(vErrs, err)
+	}
+	if n.content == nil {
+		vErrs = append(vErrs, validationErr{
+			Field: fieldNotebookContent,
+			Msg:   "no content provided",
+		})
+	}
+
+	if len(vErrs) > 0 {
+		return []validationErr{
+			objectValidationErr(fieldSpec, vErrs...),
+		}
+	}
+
+	return nil
+}
+
+// ifaceToJSONObject converts the decoded notebook content into a
+// value that can be stored as JSON. YAML decodes nested maps with
+// interface keys, which the json encoder rejects.
+func ifaceToJSONObject(i interface{}) interface{} {
+	switch v := i.(type) {
+	case Resource:
+		out := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			out[k] = ifaceToJSONObject(val)
+		}
+		return out
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			out[k] = ifaceToJSONObject(val)
+		}
+		return out
+	case map[interface{}]interface{}:
+		res, _ := ifaceToResource(v)
+		return ifaceToJSONObject(res)
+	case []Resource:
+		out := make([]interface{}, 0, len(v))
+		for _, r := range v {
+			out = append(out, ifaceToJSONObject(r))
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, 0, len(v))
+		for _, val := range v {
+			out = append(out, ifaceToJSONObject(val))
+		}
+		return out
+	default:
+		return v
+	}
+}
+
 const (
 	notificationHTTPAuthTypeBasic  = "basic"
 	notificationHTTPAuthTypeBearer = "bearer"
@@ -2037,6 +2286,95 @@ func toSummaryTagRules(tagRules []struct{ k, v, op string }) []SummaryTagRule {
 	return out
 }
 
+const (
+	fieldScraperAllowInsecure = "allowInsecure"
+	fieldScraperBucket        = "bucket"
+	fieldScraperURL           = "url"
+)
+
+type scraper struct {
+	identity
+
+	scraperType   string
+	url           string
+	allowInsecure bool
+
+	// bucket is the metadata.name of a bucket within the template, or
+	// the name of a bucket that already exists within the platform.
+	bucket *references
+}
+
+func (s *scraper) ResourceType() influxdb.ResourceType {
+	return KindScraper.ResourceType()
+}
+
+func (s *scraper) bucketRef() string {
+	return s.bucket.String()
+}
+
+func (s *scraper) Type() influxdb.ScraperType {
+	if s.scraperType == "" {
+		return influxdb.PrometheusScraperType
+	}
+	return influxdb.ScraperType(s.scraperType)
+}
+
+func (s *scraper) summarize() SummaryScraper {
+	refs := s.identity.summarizeReferences()
+	if s.bucket.hasEnvRef() {
+		refs = append(refs, convertRefToRefSummary("spec."+fieldScraperBucket, s.bucket))
+	}
+	return SummaryScraper{
+		SummaryIdentifier: SummaryIdentifier{
+			Kind:          KindScraper,
+			MetaName:      s.MetaName(),
+			EnvReferences: refs,
+		},
+		Name:          s.Name(),
+		Type:          s.Type(),
+		URL:           s.url,
+		BucketName:    s.bucketRef(),
+		AllowInsecure: s.allowInsecure,
+	}
+}
+
+func (s *scraper) valid() []validationErr {
+	var vErrs []validationErr
+	if err, ok := isValidName(s.Name(), 1); !ok {
+		vErrs = append(vErrs, err)
+	}
+	if s.Type() != influxdb.PrometheusScraperType {
+		vErrs = append(vErrs, validationErr{
+			Field: fieldType,
+			Msg:   fmt.Sprintf("type must be %q", influxdb.PrometheusScraperType),
+		})
+	}
+	if _, err := url.Parse(s.url); err != nil || s.url == "" {
+		msg := "must provide a url"
+		if err != nil {
+			msg = err.Error()
+		}
+		vErrs = append(vErrs, validationErr{
+			Field: fieldScraperURL,
+			Msg:   msg,
+		})
+	}
+	if s.bucketRef() == "" {
+		vErrs = append(vErrs, validationErr{
+			Field: fieldScraperBucket,
+			Msg:   "must provide a bucket",
+		})
+	}
+
+	if len(vErrs) > 0 {
+		return []validationErr{
+			objectValidationErr(fieldSpec, vErrs...),
+		}
+	}
+
+	return nil
+}
+
 const (
 	fieldTaskCron = "cron"
 	fieldTask     = "task"
@@ -2272,6 +2610,120 @@ func (t *telegraf) valid() []validationErr {
 	return nil
 }
 
+const (
+	fieldV1AuthPassword    = "password"
+	fieldV1AuthPermissions = "permissions"
+	fieldV1AuthToken       = "token"
+	fieldV1AuthAction      = "action"
+	fieldV1AuthBucket      = "bucket"
+)
+
+type v1Authorization struct {
+	identity
+
+	token       string
+	description string
+	status      string
+	password    *references
+	permissions []v1Permission
+}
+
+// v1Permission grants an action on a bucket. The bucket is the metadata.name
+// of a bucket within the template, or the name of a bucket that already
+// exists within the platform.
+type v1Permission struct {
+	action string
+	bucket string
+}
+
+func (a *v1Authorization) ResourceType() influxdb.ResourceType {
+	return KindV1Authorization.ResourceType()
+}
+
+func (a *v1Authorization) Status() influxdb.Status {
+	if a.status == "" {
+		return influxdb.Active
+	}
+	return influxdb.Status(a.status)
+}
+
+func (a *v1Authorization) summarize() SummaryV1Authorization {
+	perms := make([]SummaryV1Permission, 0, len(a.permissions))
+	for _, p := range a.permissions {
+		perms = append(perms, SummaryV1Permission{
+			Action:     influxdb.Action(p.action),
+			BucketName: p.bucket,
+		})
+	}
+	return SummaryV1Authorization{
+		SummaryIdentifier: SummaryIdentifier{
+			Kind:          KindV1Authorization,
+			MetaName:      a.MetaName(),
+			EnvReferences: a.identity.summarizeReferences(),
+		},
+		Token:       a.token,
+		Description: a.description,
+		Status:      a.Status(),
+		Permissions: perms,
+	}
+}
+
+func (a *v1Authorization) valid() []validationErr {
+	var vErrs []validationErr
+	if a.token == "" {
+		vErrs = append(vErrs, validationErr{
+			Field: fieldV1AuthToken,
+			Msg:   "must provide a token",
+		})
+	}
+	switch a.Status() {
+	case influxdb.Active, influxdb.Inactive:
+	default:
+		vErrs = append(vErrs, validationErr{
+			Field: fieldStatus,
+			Msg:   "must be 1 of [active, inactive]",
+		})
+	}
+	if len(a.permissions) == 0 {
+		vErrs = append(vErrs, validationErr{
+			Field: fieldV1AuthPermissions,
+			Msg:   "must provide at least 1 permission",
+		})
+	}
+	for i, p := range a.permissions {
+		var pErrs []validationErr
+		switch influxdb.Action(p.action) {
+		case influxdb.ReadAction, influxdb.WriteAction:
+		default:
+			pErrs = append(pErrs, validationErr{
+				Field: fieldV1AuthAction,
+				Msg:   "must be 1 of [read, write]",
+			})
+		}
+		if p.bucket == "" {
+			pErrs = append(pErrs, validationErr{
+				Field: fieldV1AuthBucket,
+				Msg:   "must provide a bucket",
+			})
+		}
+		if len(pErrs) > 0 {
+			vErrs = append(vErrs, validationErr{
+				Field:  fieldV1AuthPermissions,
+				Index:  intPtr(i),
+				Nested: pErrs,
+			})
+		}
+	}
+
+	if len(vErrs) > 0 {
+		return []validationErr{
+			objectValidationErr(fieldSpec, vErrs...),
+		}
+	}
+
+	return nil
+}
+
 const (
 	fieldArgTypeConstant  = "constant"
 	fieldArgTypeMap       = "map"
@@ -0,0 +1,619 @@
+package pkger
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/influxdb/v2/task/options"
+)
+
+const (
+	fieldParamPattern   = "pattern"
+	fieldParamRequired  = "required"
+	fieldMetadataIfCond = "condition"
+)
+
+// parameter types
+const (
+	paramTypeBool     = "bool"
+	paramTypeDuration = "duration"
+	paramTypeInt      = "int"
+	paramTypeList     = "list"
+	paramTypeString   = "string"
+)
+
+// paramExprRegexp matches a parameter expression, i.e. {{ spacecraft-id }} or
+// {{ spacecraft-id | upper }}. The double brace syntax is used to stay clear of
+// flux string interpolation (${ r._value }) which is valid inside queries and
+// status message templates.
+var paramExprRegexp = regexp.MustCompile(`{{\s*([a-z0-9]([-a-z0-9]*[a-z0-9])?)\s*((\|\s*[a-z]+\s*)*)}}`)
+
+type parameter struct {
+	name        string
+	Type        string
+	Description string
+	Required    bool
+	Pattern     string
+	Min         *int
+	Max         *int
+	Values      []string
+
+	defaultVal interface{}
+	val        interface{}
+}
+
+func (p *parameter) MetaName() string {
+	return p.name
+}
+
+// value returns the typed value of the parameter, preferring the provided
+// value over the default. A nil value indicates the parameter is unset.
+func (p *parameter) value() interface{} {
+	if p.val != nil {
+		return p.val
+	}
+	return p.defaultVal
+}
+
+// zero returns the value an unset parameter resolves to. A required
+// parameter without a value resolves to a placeholder, similar to the
+// default value of an env reference, so that a dry run can still graph
+// the resources referencing it.
+func (p *parameter) zero() interface{} {
+	if p.Required {
+		return "param-" + p.name
+	}
+	switch p.Type {
+	case paramTypeBool:
+		return false
+	case paramTypeDuration:
+		return "0s"
+	case paramTypeInt:
+		return 0
+	case paramTypeList:
+		return []string{}
+	default:
+		return ""
+	}
+}
+
+func (p *parameter) summarize() SummaryParameter {
+	return SummaryParameter{
+		Kind:        KindParameter,
+		MetaName:    p.MetaName(),
+		Type:        p.Type,
+		Description: p.Description,
+		Required:    p.Required,
+		Default:     p.defaultVal,
+		Value:       p.value(),
+	}
+}
+
+func (p *parameter) valid() []validationErr {
+	var vErrs []validationErr
+	switch p.Type {
+	case paramTypeBool, paramTypeDuration, paramTypeInt, paramTypeList, paramTypeString:
+	default:
+		vErrs = append(vErrs, validationErr{
+			Field: fieldType,
+			Msg: fmt.Sprintf(
+				"type %q is not supported; must be 1 in [%s]",
+				p.Type,
+				strings.Join([]string{paramTypeBool, paramTypeDuration, paramTypeInt, paramTypeList, paramTypeString}, ", "),
+			),
+		})
+		return []validationErr{objectValidationErr(fieldSpec, vErrs...)}
+	}
+
+	if p.Pattern != "" {
+		if _, err := regexp.Compile(p.Pattern); err != nil {
+			vErrs = append(vErrs, validationErr{
+				Field: fieldParamPattern,
+				Msg:   "invalid pattern: " + err.Error(),
+			})
+		}
+	}
+
+	if p.Min != nil && p.Max != nil && *p.Min > *p.Max {
+		vErrs = append(vErrs, validationErr{
+			Field: fieldMin,
+			Msg:   "min must be less than or equal to max",
+		})
+	}
+
+	if p.defaultVal != nil {
+		if err := p.validValue(p.defaultVal); err != nil {
+			vErrs = append(vErrs, validationErr{
+				Field: fieldDefault,
+				Msg:   err.Error(),
+			})
+		}
+	}
+
+	if p.val != nil {
+		if err := p.validValue(p.val); err != nil {
+			vErrs = append(vErrs, validationErr{
+				Field: fieldValue,
+				Msg:   err.Error(),
+			})
+		}
+	}
+
+	if len(vErrs) == 0 {
+		return nil
+	}
+	return []validationErr{objectValidationErr(fieldSpec, vErrs...)}
+}
+
+func (p *parameter) validValue(v interface{}) error {
+	inValues := func(s string) error {
+		if len(p.Values) == 0 {
+			return nil
+		}
+		for _, allowed := range p.Values {
+			if s == allowed {
+				return nil
+			}
+		}
+		return fmt.Errorf("value %q must be 1 in [%s]", s, strings.Join(p.Values, ", "))
+	}
+
+	switch p.Type {
+	case paramTypeInt:
+		i, ok := v.(int)
+		if !ok {
+			return fmt.Errorf("value %v is not an int", v)
+		}
+		if p.Min != nil && i < *p.Min {
+			return fmt.Errorf("value %d is less than min %d", i, *p.Min)
+		}
+		if p.Max != nil && i > *p.Max {
+			return fmt.Errorf("value %d is greater than max %d", i, *p.Max)
+		}
+		return inValues(strconv.Itoa(i))
+	case paramTypeList:
+		items, ok := v.([]string)
+		if !ok {
+			return fmt.Errorf("value %v is not a list", v)
+		}
+		for _, item := range items {
+			if err := inValues(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	case paramTypeString, paramTypeDuration:
+		s, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("value %v is not a %s", v, p.Type)
+		}
+		if p.Pattern != "" {
+			if re, err := regexp.Compile(p.Pattern); err == nil && !re.MatchString(s) {
+				return fmt.Errorf("value %q does not match pattern %q", s, p.Pattern)
+			}
+		}
+		return inValues(s)
+	}
+	return nil
+}
+
+// convertParamVal converts the raw value provided by a user (via the CLI,
+// the HTTP API or the template default) to the parameter's type.
+func convertParamVal(typ string, raw interface{}) (interface{}, error) {
+	if raw == nil {
+		return nil, nil
+	}
+
+	switch typ {
+	case paramTypeBool:
+		switch v := raw.(type) {
+		case bool:
+			return v, nil
+		case string:
+			b, err := strconv.ParseBool(strings.TrimSpace(v))
+			if err != nil {
+				return nil, fmt.Errorf("value %q is not a bool", v)
+			}
+			return b, nil
+		}
+	case paramTypeDuration:
+		s, ok := ifaceToStr(raw)
+		if !ok {
+			break
+		}
+		s = strings.TrimSpace(s)
+		if _, err := options.ParseSignedDuration(s); err != nil {
+			return nil, fmt.Errorf("value %q is not a duration", s)
+		}
+		return s, nil
+	case paramTypeInt:
+		switch v := raw.(type) {
+		case int:
+			return v, nil
+		case int64:
+			return int(v), nil
+		case float64:
+			if v != float64(int(v)) {
+				return nil, fmt.Errorf("value %v is not an int", v)
+			}
+			return int(v), nil
+		case string:
+			i, err := strconv.Atoi(strings.TrimSpace(v))
+			if err != nil {
+				return nil, fmt.Errorf("value %q is not an int", v)
+			}
+			return i, nil
+		}
+	case paramTypeList:
+		switch v := raw.(type) {
+		case []string:
+			return v, nil
+		case []interface{}:
+			out := make([]string, 0, len(v))
+			for _, item := range v {
+				s, ok := ifaceToStr(item)
+				if !ok {
+					return nil, fmt.Errorf("list item %v is not a string", item)
+				}
+				out = append(out, s)
+			}
+			return out, nil
+		case string:
+			out := make([]string, 0)
+			for _, item := range strings.Split(v, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					out = append(out, item)
+				}
+			}
+			return out, nil
+		}
+	default:
+		if s, ok := ifaceToStr(raw); ok {
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("value %v is not a valid %s", raw, typ)
+}
+
+func (p *Template) applyParams(params map[string]interface{}) error {
+	if len(params) == 0 {
+		return nil
+	}
+
+	if p.mParamVals == nil {
+		p.mParamVals = make(map[string]interface{})
+	}
+
+	for k, v := range params {
+		p.mParamVals[k] = v
+	}
+
+	return p.Validate()
+}
+
+func (p *Template) parameters() []*parameter {
+	params := make([]*parameter, 0, len(p.mParams))
+	for _, param := range p.mParams {
+		params = append(params, param)
+	}
+
+	sort.Slice(params, func(i, j int) bool { return params[i].MetaName() < params[j].MetaName() })
+
+	return params
+}
+
+func (p *Template) missingParams() []string {
+	missing := make([]string, 0)
+	for _, param := range p.parameters() {
+		if param.Required && param.value() == nil {
+			missing = append(missing, param.MetaName())
+		}
+	}
+	return missing
+}
+
+// graphParameters graphs the parameters declared in the template and then
+// resolves every other object against them. Resolution interpolates
+// parameter expressions and drops the objects whose condition evaluates
+// to false.
+func (p *Template) graphParameters() *parseErr {
+	p.mParams = make(map[string]*parameter)
+	p.resolved, p.skipped = nil, nil
+
+	pErr := p.eachResource(KindParameter, func(o Object) []validationErr {
+		name := o.Name()
+		if _, ok := p.mParams[name]; ok {
+			return []validationErr{
+				objectValidationErr(fieldMetadata, validationErr{
+					Field: fieldName,
+					Msg:   "duplicate name: " + name,
+				}),
+			}
+		}
+
+		param := &parameter{
+			name:        name,
+			Type:        normStr(o.Spec.stringShort(fieldType)),
+			Description: o.Spec.stringShort(fieldDescription),
+			Required:    o.Spec.boolShort(fieldParamRequired),
+			Pattern:     o.Spec.stringShort(fieldParamPattern),
+			Values:      o.Spec.slcStr(fieldValues),
+		}
+		if param.Type == "" {
+			param.Type = paramTypeString
+		}
+		if min, ok := o.Spec.int(fieldMin); ok {
+			param.Min = &min
+		}
+		if max, ok := o.Spec.int(fieldMax); ok {
+			param.Max = &max
+		}
+		p.mParams[name] = param
+
+		var failures []validationErr
+		var err error
+		if param.defaultVal, err = convertParamVal(param.Type, o.Spec[fieldDefault]); err != nil {
+			failures = append(failures, objectValidationErr(fieldSpec, validationErr{
+				Field: fieldDefault,
+				Msg:   err.Error(),
+			}))
+		}
+		if param.val, err = convertParamVal(param.Type, p.mParamVals[name]); err != nil {
+			failures = append(failures, objectValidationErr(fieldSpec, validationErr{
+				Field: fieldValue,
+				Msg:   err.Error(),
+			}))
+		}
+		if len(failures) > 0 {
+			return failures
+		}
+
+		return param.valid()
+	})
+
+	resolved := make([]Object, len(p.Objects))
+	skipped := make([]bool, len(p.Objects))
+	for i, o := range p.Objects {
+		if o.Kind == KindParameter {
+			resolved[i] = o
+			continue
+		}
+
+		// a condition referencing a required parameter without a value is
+		// considered true, the resource is reported in the dry run, and the
+		// apply is rejected for the missing parameter.
+		condMissingParam := p.refsMissingParam(o.Metadata[fieldMetadataIfCond])
+
+		o.Metadata, _ = p.interpolate(o.Metadata).(Resource)
+		o.Spec, _ = p.interpolate(o.Spec).(Resource)
+		resolved[i] = o
+		if condMissingParam {
+			continue
+		}
+
+		include, err := p.evalCondition(o.Metadata)
+		if err != nil {
+			if pErr == nil {
+				pErr = new(parseErr)
+			}
+			pErr.append(resourceErr{
+				Kind: o.Kind.String(),
+				Idx:  intPtr(i),
+				ValidationErrs: []validationErr{
+					objectValidationErr(fieldMetadata, validationErr{
+						Field: fieldMetadataIfCond,
+						Msg:   err.Error(),
+					}),
+				},
+			})
+			continue
+		}
+		skipped[i] = !include
+	}
+	p.resolved, p.skipped = resolved, skipped
+
+	return pErr
+}
+
+// interpolate walks the raw value and replaces parameter expressions within
+// every string found. A string that consists of a single expression takes
+// on the type of the parameter (i.e. an int parameter provides an int).
+// Expressions referencing unknown parameters are left untouched.
+func (p *Template) interpolate(v interface{}) interface{} {
+	switch t := v.(type) {
+	case string:
+		return p.interpolateStr(t)
+	case Resource:
+		out := make(Resource, len(t))
+		for k, val := range t {
+			out[k] = p.interpolate(val)
+		}
+		return out
+	case map[string]interface{}:
+		out := make(Resource, len(t))
+		for k, val := range t {
+			out[k] = p.interpolate(val)
+		}
+		return out
+	case map[interface{}]interface{}:
+		res, _ := ifaceToResource(t)
+		return p.interpolate(res)
+	case []Resource:
+		out := make([]Resource, 0, len(t))
+		for _, r := range t {
+			res, _ := p.interpolate(r).(Resource)
+			out = append(out, res)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, 0, len(t))
+		for _, val := range t {
+			out = append(out, p.interpolate(val))
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+func (p *Template) interpolateStr(s string) interface{} {
+	if !strings.Contains(s, "{{") {
+		return s
+	}
+
+	if loc := paramExprRegexp.FindStringSubmatchIndex(s); loc != nil && loc[0] == 0 && loc[1] == len(s) {
+		if v, ok := p.evalParamExpr(s[loc[2]:loc[3]], s[loc[6]:loc[7]]); ok {
+			return v
+		}
+		return s
+	}
+
+	return paramExprRegexp.ReplaceAllStringFunc(s, func(expr string) string {
+		m := paramExprRegexp.FindStringSubmatch(expr)
+		v, ok := p.evalParamExpr(m[1], m[3])
+		if !ok {
+			return expr
+		}
+		return paramValToStr(v)
+	})
+}
+
+// evalParamExpr evaluates the parameter with the pipeline of functions
+// applied to it in order. The supported functions are:
+//
+//	upper:   upper cases a string value
+//	lower:   lower cases a string value
+//	quote:   renders the value as a flux string literal
+//	seconds: converts a duration value to its number of seconds
+func (p *Template) evalParamExpr(name, pipeline string) (interface{}, bool) {
+	param, ok := p.mParams[name]
+	if !ok {
+		return nil, false
+	}
+	v := param.value()
+	if v == nil {
+		v = param.zero()
+	}
+
+	for _, fn := range strings.Split(pipeline, "|") {
+		switch strings.TrimSpace(fn) {
+		case "":
+		case "upper":
+			v = strings.ToUpper(paramValToStr(v))
+		case "lower":
+			v = strings.ToLower(paramValToStr(v))
+		case "quote":
+			v = strconv.Quote(paramValToStr(v))
+		case "seconds":
+			astDur, err := options.ParseSignedDuration(paramValToStr(v))
+			if err != nil {
+				return nil, false
+			}
+			dur, err := ast.DurationFrom(astDur, time.Time{})
+			if err != nil {
+				return nil, false
+			}
+			v = int(dur / time.Second)
+		default:
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// paramValToStr renders a parameter value for use within a string. Lists
+// are rendered as a flux array of strings so they can be used as is in
+// queries, i.e. contains(value: r.channel, set: {{ channels }}).
+func paramValToStr(v interface{}) string {
+	switch t := v.(type) {
+	case []string:
+		quoted := make([]string, 0, len(t))
+		for _, s := range t {
+			quoted = append(quoted, strconv.Quote(s))
+		}
+		return "[" + strings.Join(quoted, ", ") + "]"
+	case bool:
+		return strconv.FormatBool(t)
+	case int:
+		return strconv.Itoa(t)
+	case string:
+		return t
+	default:
+		return fmt.Sprint(t)
+	}
+}
+
+// evalCondition evaluates the condition of an object, once interpolated.
+// An object without a condition is always included. The supported
+// conditions are a bool literal, its negation (!) or an equality
+// comparison (==, !=) between two values.
+func (p *Template) evalCondition(metadata Resource) (bool, error) {
+	raw, ok := metadata[fieldMetadataIfCond]
+	if !ok || raw == nil {
+		return true, nil
+	}
+
+	var cond string
+	switch v := raw.(type) {
+	case bool:
+		return v, nil
+	case []string:
+		return len(v) > 0, nil
+	default:
+		cond = strings.TrimSpace(paramValToStr(v))
+	}
+
+	if loc := paramExprRegexp.FindStringIndex(cond); loc != nil {
+		return false, fmt.Errorf("condition %q references an unknown parameter", cond)
+	}
+
+	for _, op := range []string{"!=", "=="} {
+		parts := strings.SplitN(cond, op, 2)
+		if len(parts) != 2 {
+			continue
+		}
+		lhs, rhs := unquoteCondOperand(parts[0]), unquoteCondOperand(parts[1])
+		if op == "==" {
+			return lhs == rhs, nil
+		}
+		return lhs != rhs, nil
+	}
+
+	negate := false
+	for strings.HasPrefix(cond, "!") {
+		negate = !negate
+		cond = strings.TrimSpace(cond[1:])
+	}
+
+	b, err := strconv.ParseBool(cond)
+	if err != nil {
+		return false, fmt.Errorf("condition %q must evaluate to a bool", cond)
+	}
+	return b != negate, nil
+}
+
+func (p *Template) refsMissingParam(v interface{}) bool {
+	s, ok := v.(string)
+	if !ok {
+		return false
+	}
+	for _, m := range paramExprRegexp.FindAllStringSubmatch(s, -1) {
+		if param, ok := p.mParams[m[1]]; ok && param.Required && param.value() == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func unquoteCondOperand(s string) string {
+	s = strings.TrimSpace(s)
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		return unquoted
+	}
+	return s
+}
@@ -580,6 +580,31 @@ spec:
 			})
 		})
 
+		t.Run("with anomaly check", func(t *testing.T) {
+			testfileRunner(t, "testdata/checks_anomaly.yml", func(t *testing.T, template *Template) {
+				sum := template.Summary()
+				require.Len(t, sum.Checks, 1)
+
+				actual := sum.Checks[0]
+				assert.Equal(t, KindCheckAnomaly, actual.Kind)
+				anomalyCheck, ok := actual.Check.(*icheck.Anomaly)
+				require.Truef(t, ok, "got: %#v", actual)
+
+				assert.Equal(t, "check-0", anomalyCheck.Name)
+				assert.Equal(t, "perigee drift", anomalyCheck.Description)
+				assert.Equal(t, mustDuration(t, time.Minute), anomalyCheck.Every)
+				assert.Equal(t, icheck.AnomalyMAD, anomalyCheck.Method)
+				assert.Equal(t, mustDuration(t, 7*24*time.Hour), anomalyCheck.Baseline)
+				assert.Equal(t, mustDuration(t, 90*time.Minute), anomalyCheck.Period)
+				expectedThresholds := []icheck.AnomalyThreshold{
+					{Level: notification.Critical, Value: 5},
+					{Level: notification.Warn, Value: 3.5},
+				}
+				assert.Equal(t, expectedThresholds, anomalyCheck.Thresholds)
+				assert.Equal(t, influxdb.Active, actual.Status)
+			})
+		})
+
 		t.Run("with env refs should be successful", func(t *testing.T) {
 			testfileRunner(t, "testdata/checks_ref.yml", func(t *testing.T, template *Template) {
 				actual := template.Summary().Checks
@@ -903,6 +928,77 @@ spec:
       name: label-1
     - kind: Label
       name: label-1
+`,
+					},
+				},
+				{
+					kind: KindCheckAnomaly,
+					resErr: testTemplateResourceError{
+						name:           "anomaly missing baseline",
+						validationErrs: 1,
+						valFields:      []string{fieldSpec, fieldCheckBaseline},
+						templateStr: `apiVersion: influxdata.com/v2alpha1
+kind: CheckAnomaly
+metadata:
+  name: check-1
+spec:
+  every: 1m
+  query:  >
+    from(bucket: "rucket_1")
+  statusMessageTemplate: "Check: ${ r._check_name } is: ${ r._level }"
+  method: zscore
+  thresholds:
+    - level: CRIT
+      value: 3
+`,
+					},
+				},
+				{
+					kind: KindCheckAnomaly,
+					resErr: testTemplateResourceError{
+						name:           "anomaly invalid method",
+						validationErrs: 1,
+						valFields:      []string{fieldSpec, fieldCheckMethod},
+						templateStr: `apiVersion: influxdata.com/v2alpha1
+kind: CheckAnomaly
+metadata:
+  name: check-1
+spec:
+  every: 1m
+  query:  >
+    from(bucket: "rucket_1")
+  statusMessageTemplate: "Check: ${ r._check_name } is: ${ r._level }"
+  method: ewma
+  baseline: 1d
+  period: 90m
+  thresholds:
+    - level: CRIT
+      value: 3
+`,
+					},
+				},
+				{
+					kind: KindCheckAnomaly,
+					resErr: testTemplateResourceError{
+						name:           "anomaly duplicate threshold level",
+						validationErrs: 1,
+						valFields:      []string{fieldSpec, fieldLevel},
+						templateStr: `apiVersion: influxdata.com/v2alpha1
+kind: CheckAnomaly
+metadata:
+  name: check-1
+spec:
+  every: 1m
+  query:  >
+    from(bucket: "rucket_1")
+  statusMessageTemplate: "Check: ${ r._check_name } is: ${ r._level }"
+  method: period
+  period: 90m
+  thresholds:
+    - level: CRIT
+      value: 30
+    - level: crit
+      value: 20
 `,
 					},
 				},
@@ -3957,6 +4053,266 @@ spec:
 		})
 	})
 
+	t.Run("template with dbrp mappings", func(t *testing.T) {
+		t.Run("should be successful", func(t *testing.T) {
+			testfileRunner(t, "testdata/dbrp.yml", func(t *testing.T, template *Template) {
+				sum := template.Summary()
+				require.Len(t, sum.DBRPMappings, 2)
+
+				actual := sum.DBRPMappings[0]
+				assert.Equal(t, KindDBRPMapping, actual.Kind)
+				assert.Equal(t, "dbrp-1", actual.MetaName)
+				assert.Equal(t, "telegraf", actual.Database)
+				assert.Equal(t, "autogen", actual.RetentionPolicy)
+				assert.True(t, actual.Default)
+				assert.Equal(t, "rucket-1", actual.BucketName)
+
+				actual = sum.DBRPMappings[1]
+				assert.Equal(t, "dbrp-2", actual.MetaName)
+				assert.Equal(t, "weekly", actual.RetentionPolicy)
+				assert.False(t, actual.Default)
+				assert.Equal(t, "existing-bucket", actual.BucketName)
+			})
+		})
+
+		t.Run("handles bad config", func(t *testing.T) {
+			tests := []testTemplateResourceError{
+				{
+					name:           "missing database",
+					validationErrs: 1,
+					valFields:      []string{fieldSpec, fieldDBRPDatabase},
+					templateStr: `apiVersion: influxdata.com/v2alpha1
+kind: DBRPMapping
+metadata:
+  name: dbrp-1
+spec:
+  retentionPolicy: autogen
+  bucket: rucket-1
+`,
+				},
+				{
+					name:           "missing bucket",
+					validationErrs: 1,
+					valFields:      []string{fieldSpec, fieldDBRPBucket},
+					templateStr: `apiVersion: influxdata.com/v2alpha1
+kind: DBRPMapping
+metadata:
+  name: dbrp-1
+spec:
+  database: telegraf
+  retentionPolicy: autogen
+`,
+				},
+			}
+
+			for _, tt := range tests {
+				testTemplateErrors(t, KindDBRPMapping, tt)
+			}
+		})
+	})
+
+	t.Run("template with notebooks", func(t *testing.T) {
+		t.Run("should be successful", func(t *testing.T) {
+			testfileRunner(t, "testdata/notebook.yml", func(t *testing.T, template *Template) {
+				sum := template.Summary()
+				require.Len(t, sum.Notebooks, 1)
+
+				actual := sum.Notebooks[0]
+				assert.Equal(t, KindNotebook, actual.Kind)
+				assert.Equal(t, "notebook-1", actual.MetaName)
+				assert.Equal(t, "cpu exploration", actual.Name)
+
+				pipes, ok := actual.Content["pipes"].([]interface{})
+				require.True(t, ok)
+				require.Len(t, pipes, 2)
+				pipe, ok := pipes[0].(map[string]interface{})
+				require.True(t, ok)
+				assert.Equal(t, "queryEditor", pipe["type"])
+			})
+		})
+
+		t.Run("handles bad config", func(t *testing.T) {
+			tests := []testTemplateResourceError{
+				{
+					name:           "missing content",
+					validationErrs: 1,
+					valFields:      []string{fieldSpec, fieldNotebookContent},
+					templateStr: `apiVersion: influxdata.com/v2alpha1
+kind: Notebook
+metadata:
+  name: notebook-1
+spec:
+  name: empty
+`,
+				},
+			}
+
+			for _, tt := range tests {
+				testTemplateErrors(t, KindNotebook, tt)
+			}
+		})
+	})
+
+	t.Run("template with scrapers", func(t *testing.T) {
+		t.Run("should be successful", func(t *testing.T) {
+			testfileRunner(t, "testdata/scraper.yml", func(t *testing.T, template *Template) {
+				sum := template.Summary()
+				require.Len(t, sum.Scrapers, 2)
+
+				actual := sum.Scrapers[0]
+				assert.Equal(t, KindScraper, actual.Kind)
+				assert.Equal(t, "scraper-1", actual.MetaName)
+				assert.Equal(t, "local metrics", actual.Name)
+				assert.Equal(t, influxdb.PrometheusScraperType, actual.Type)
+				assert.Equal(t, "http://localhost:8086/metrics", actual.URL)
+				assert.False(t, actual.AllowInsecure)
+				assert.Equal(t, "rucket-1", actual.BucketName)
+
+				actual = sum.Scrapers[1]
+				assert.Equal(t, "scraper-2", actual.Name)
+				assert.True(t, actual.AllowInsecure)
+				assert.Equal(t, "existing-bucket", actual.BucketName)
+			})
+		})
+
+		t.Run("handles bad config", func(t *testing.T) {
+			tests := []testTemplateResourceError{
+				{
+					name:           "missing url",
+					validationErrs: 1,
+					valFields:      []string{fieldSpec, fieldScraperURL},
+					templateStr: `apiVersion: influxdata.com/v2alpha1
+kind: Scraper
+metadata:
+  name: scraper-1
+spec:
+  bucket: rucket-1
+`,
+				},
+				{
+					name:           "unsupported type",
+					validationErrs: 1,
+					valFields:      []string{fieldSpec, fieldType},
+					templateStr: `apiVersion: influxdata.com/v2alpha1
+kind: Scraper
+metadata:
+  name: scraper-1
+spec:
+  type: carbon
+  url: http://localhost:8086/metrics
+  bucket: rucket-1
+`,
+				},
+			}
+
+			for _, tt := range tests {
+				testTemplateErrors(t, KindScraper, tt)
+			}
+		})
+	})
+
+	t.Run("template with v1 authorizations", func(t *testing.T) {
+		t.Run("should be successful", func(t *testing.T) {
+			testfileRunner(t, "testdata/v1_authorization.yml", func(t *testing.T, template *Template) {
+				sum := template.Summary()
+				require.Len(t, sum.V1Authorizations, 2)
+
+				actual := sum.V1Authorizations[0]
+				assert.Equal(t, KindV1Authorization, actual.Kind)
+				assert.Equal(t, "v1-auth-1", actual.MetaName)
+				assert.Equal(t, "legacy-user", actual.Token)
+				assert.Equal(t, "legacy telegraf writer", actual.Description)
+				assert.Equal(t, influxdb.Active, actual.Status)
+				expectedPerms := []SummaryV1Permission{
+					{Action: influxdb.ReadAction, BucketName: "rucket-1"},
+					{Action: influxdb.WriteAction, BucketName: "rucket-1"},
+				}
+				assert.Equal(t, expectedPerms, actual.Permissions)
+
+				actual = sum.V1Authorizations[1]
+				assert.Equal(t, "legacy-reader", actual.Token)
+				assert.Equal(t, influxdb.Inactive, actual.Status)
+
+				assert.Equal(t, []string{"legacy-user-password"}, sum.MissingSecrets)
+			})
+		})
+
+		t.Run("handles bad config", func(t *testing.T) {
+			tests := []testTemplateResourceError{
+				{
+					name:           "missing token",
+					validationErrs: 1,
+					valFields:      []string{fieldSpec, fieldV1AuthToken},
+					templateStr: `apiVersion: influxdata.com/v2alpha1
+kind: V1Authorization
+metadata:
+  name: v1-auth-1
+spec:
+  permissions:
+    - action: read
+      bucket: rucket-1
+`,
+				},
+				{
+					name:           "missing permissions",
+					validationErrs: 1,
+					valFields:      []string{fieldSpec, fieldV1AuthPermissions},
+					templateStr: `apiVersion: influxdata.com/v2alpha1
+kind: V1Authorization
+metadata:
+  name: v1-auth-1
+spec:
+  token: legacy-user
+`,
+				},
+				{
+					name:           "invalid permission action",
+					validationErrs: 1,
+					valFields:      []string{fieldSpec, "permissions[0].action"},
+					templateStr: `apiVersion: influxdata.com/v2alpha1
+kind: V1Authorization
+metadata:
+  name: v1-auth-1
+spec:
+  token: legacy-user
+  permissions:
+    - action: delete
+      bucket: rucket-1
+`,
+				},
+				{
+					name:           "duplicate tokens",
+					validationErrs: 1,
+					valFields:      []string{fieldSpec, fieldV1AuthToken},
+					templateStr: `apiVersion: influxdata.com/v2alpha1
+kind: V1Authorization
+metadata:
+  name: v1-auth-1
+spec:
+  token: legacy-user
+  permissions:
+    - action: read
+      bucket: rucket-1
+---
+apiVersion: influxdata.com/v2alpha1
+kind: V1Authorization
+metadata:
+  name: v1-auth-2
+spec:
+  token: legacy-user
+  permissions:
+    - action: read
+      bucket: rucket-1
+`,
+				},
+			}
+
+			for _, tt := range tests {
+				testTemplateErrors(t, KindV1Authorization, tt)
+			}
+		})
+	})
+
 	t.Run("template with a variable", func(t *testing.T) {
 		t.Run("with valid fields should produce summary", func(t *testing.T) {
 			testfileRunner(t, "testdata/variables", func(t *testing.T, template *Template) {
@@ -4348,6 +4704,63 @@ spec:
 		})
 	})
 
+	t.Run("referencing parameters", func(t *testing.T) {
+		testfileRunner(t, "testdata/parameters.yml", func(t *testing.T, template *Template) {
+			sum := template.Summary()
+
+			assert.Equal(t, []string{"spacecraft-id"}, sum.MissingParams)
+			require.Len(t, sum.Parameters, 5)
+			assert.Equal(t, "archive", sum.Parameters[0].MetaName)
+			assert.Equal(t, false, sum.Parameters[0].Value)
+			assert.Equal(t, "retention", sum.Parameters[3].MetaName)
+			assert.Equal(t, "720h", sum.Parameters[3].Value)
+
+			t.Log("applying params should interpolate them and evaluate conditions")
+			{
+				err := template.applyParams(map[string]interface{}{
+					"spacecraft-id": "sat-7",
+					"max-temp":      "95",
+					"channels":      "pos_eci_x,pos_eci_z",
+				})
+				require.NoError(t, err)
+
+				sum := template.Summary()
+				assert.Empty(t, sum.MissingParams)
+
+				require.Len(t, sum.Buckets, 1)
+				assert.Equal(t, "sat-7-telemetry", sum.Buckets[0].Name)
+				assert.Equal(t, "telemetry for SAT-7", sum.Buckets[0].Description)
+				assert.Equal(t, 720*time.Hour, sum.Buckets[0].RetentionPeriod)
+
+				require.Len(t, sum.Tasks, 1)
+				assert.Equal(t, "sat-7-downsample", sum.Tasks[0].Name)
+				assert.Contains(t, sum.Tasks[0].Query, `from(bucket: "sat-7-telemetry")`)
+				assert.Contains(t, sum.Tasks[0].Query, `set: ["pos_eci_x", "pos_eci_z"]`)
+				assert.Contains(t, sum.Tasks[0].Query, `r._value < 95`)
+			}
+
+			t.Log("conditions should include resources once true")
+			{
+				require.NoError(t, template.applyParams(map[string]interface{}{"archive": true}))
+				assert.Len(t, template.Summary().Buckets, 2)
+			}
+
+			t.Log("invalid param values should fail validation")
+			{
+				err := template.applyParams(map[string]interface{}{"max-temp": 200})
+				require.Error(t, err)
+				assert.True(t, IsParseErr(err))
+
+				err = template.applyParams(map[string]interface{}{
+					"max-temp":      80,
+					"spacecraft-id": "voyager",
+				})
+				require.Error(t, err)
+				assert.True(t, IsParseErr(err))
+			}
+		})
+	})
+
 	t.Run("jsonnet support", func(t *testing.T) {
 		template := validParsedTemplateFromFile(t, "testdata/bucket_associates_labels.jsonnet", EncodingJsonnet)
 
@@ -0,0 +1,240 @@
+package pkger
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	errors2 "github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// ReconcileEvent is the outcome of a single reconciliation of a stack.
+type ReconcileEvent struct {
+	Report  DriftReport `json:"report"`
+	Applied bool        `json:"applied"`
+	Err     string      `json:"error,omitempty"`
+}
+
+// Reconciler compares a stack against its template(s) and, unless configured
+// for dry runs only, reapplies the template(s) when the stack has drifted. The
+// template(s) are the stack's template URLs, along with any templates provided
+// via WithReconcileTemplates, which are read anew on every reconciliation.
+type Reconciler struct {
+	svc     SVC
+	log     *zap.Logger
+	timeGen influxdb.TimeGenerator
+
+	orgID, userID, stackID platform.ID
+
+	templatesFn func() ([]*Template, error)
+	applyOpts   []ApplyOptFn
+	interval    time.Duration
+	dryRunOnly  bool
+	notifyFn    func(ReconcileEvent)
+
+	runs           *prometheus.CounterVec
+	applies        *prometheus.CounterVec
+	driftResources *prometheus.GaugeVec
+}
+
+// ReconcilerOptFn is a means of setting optional dependencies on the Reconciler type.
+type ReconcilerOptFn func(r *Reconciler)
+
+// WithReconcileLogger sets the logger for the reconciler.
+func WithReconcileLogger(log *zap.Logger) ReconcilerOptFn {
+	return func(r *Reconciler) {
+		r.log = log
+	}
+}
+
+// WithReconcileTimeGenerator sets the time generator for the reconciler.
+func WithReconcileTimeGenerator(timeGen influxdb.TimeGenerator) ReconcilerOptFn {
+	return func(r *Reconciler) {
+		r.timeGen = timeGen
+	}
+}
+
+// WithReconcileUserID sets the user the template(s) are applied on behalf of.
+func WithReconcileUserID(userID platform.ID) ReconcilerOptFn {
+	return func(r *Reconciler) {
+		r.userID = userID
+	}
+}
+
+// WithReconcileTemplates provides the templates to reconcile the stack against
+// in addition to the stack's template URLs. The func is called on every
+// reconciliation so changes to the underlying templates are picked up.
+func WithReconcileTemplates(fn func() ([]*Template, error)) ReconcilerOptFn {
+	return func(r *Reconciler) {
+		r.templatesFn = fn
+	}
+}
+
+// WithReconcileApplyOpts provides additional options for the dry run and
+// application of the template(s), e.g. env refs or secrets.
+func WithReconcileApplyOpts(opts ...ApplyOptFn) ReconcilerOptFn {
+	return func(r *Reconciler) {
+		r.applyOpts = append(r.applyOpts, opts...)
+	}
+}
+
+// WithReconcileInterval sets the interval between reconciliations when running
+// continuously. Defaults to 5m.
+func WithReconcileInterval(interval time.Duration) ReconcilerOptFn {
+	return func(r *Reconciler) {
+		r.interval = interval
+	}
+}
+
+// WithReconcileDryRun only reports drift, the template(s) are never applied.
+func WithReconcileDryRun(dryRunOnly bool) ReconcilerOptFn {
+	return func(r *Reconciler) {
+		r.dryRunOnly = dryRunOnly
+	}
+}
+
+// WithReconcileNotifier sets a func that is called with the outcome of every
+// reconciliation.
+func WithReconcileNotifier(fn func(ReconcileEvent)) ReconcilerOptFn {
+	return func(r *Reconciler) {
+		r.notifyFn = fn
+	}
+}
+
+// NewReconciler constructs a new stack reconciler.
+func NewReconciler(svc SVC, orgID, stackID platform.ID, opts ...ReconcilerOptFn) *Reconciler {
+	r := &Reconciler{
+		svc:      svc,
+		log:      zap.NewNop(),
+		timeGen:  influxdb.RealTimeGenerator{},
+		orgID:    orgID,
+		stackID:  stackID,
+		interval: 5 * time.Minute,
+		runs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "templates",
+			Subsystem: "reconcile",
+			Name:      "runs_total",
+			Help:      "Total number of stack reconciliations by result.",
+		}, []string{"stack_id", "result"}),
+		applies: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "templates",
+			Subsystem: "reconcile",
+			Name:      "applies_total",
+			Help:      "Total number of times a drifted stack had its template(s) reapplied.",
+		}, []string{"stack_id"}),
+		driftResources: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "templates",
+			Subsystem: "reconcile",
+			Name:      "drifted_resources",
+			Help:      "Number of resources that had drifted from the template(s) at the last reconciliation.",
+		}, []string{"stack_id", "status"}),
+	}
+	for _, o := range opts {
+		o(r)
+	}
+	return r
+}
+
+// PrometheusCollectors returns the metrics of the reconciler.
+func (r *Reconciler) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{r.runs, r.applies, r.driftResources}
+}
+
+// Run reconciles the stack immediately and then at every interval until the
+// context is canceled. Failed reconciliations are logged and retried at the
+// next interval.
+func (r *Reconciler) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.Reconcile(ctx); err != nil && ctx.Err() == nil {
+			r.log.Error("failed to reconcile stack", zap.Stringer("stack_id", r.stackID), zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Reconcile compares the stack against its template(s) a single time, and
+// reapplies the template(s) if the stack has drifted.
+func (r *Reconciler) Reconcile(ctx context.Context) (ReconcileEvent, error) {
+	event, err := r.reconcile(ctx)
+	stackID := r.stackID.String()
+	if err != nil {
+		event.Err = err.Error()
+		r.runs.WithLabelValues(stackID, "error").Inc()
+	} else {
+		r.runs.WithLabelValues(stackID, "success").Inc()
+		for status, n := range event.Report.CountByStatus() {
+			r.driftResources.WithLabelValues(stackID, string(status)).Set(float64(n))
+		}
+	}
+	if event.Applied {
+		r.applies.WithLabelValues(stackID).Inc()
+	}
+
+	if r.notifyFn != nil {
+		r.notifyFn(event)
+	}
+	return event, err
+}
+
+func (r *Reconciler) reconcile(ctx context.Context) (ReconcileEvent, error) {
+	stack, err := r.svc.ReadStack(ctx, r.stackID)
+	if err != nil {
+		return ReconcileEvent{}, err
+	}
+
+	var templates []*Template
+	if r.templatesFn != nil {
+		templates, err = r.templatesFn()
+		if err != nil {
+			return ReconcileEvent{}, err
+		}
+	}
+
+	// an empty template would mark every resource the stack owns for removal,
+	// which is never what a reconciliation intends.
+	if len(templates) == 0 && len(stack.LatestEvent().TemplateURLs) == 0 {
+		return ReconcileEvent{}, influxErr(errors2.EUnprocessableEntity, "stack has no templates to reconcile against")
+	}
+
+	opts := []ApplyOptFn{ApplyWithStackID(r.stackID)}
+	for _, t := range templates {
+		opts = append(opts, ApplyWithTemplate(t))
+	}
+	opts = append(opts, r.applyOpts...)
+
+	impact, err := r.svc.DryRun(ctx, r.orgID, r.userID, opts...)
+	if err != nil {
+		return ReconcileEvent{}, err
+	}
+
+	event := ReconcileEvent{Report: NewDriftReport(stack, impact)}
+	event.Report.CheckedAt = r.timeGen.Now()
+	if !event.Report.HasDrift() {
+		return event, nil
+	}
+
+	r.log.Info("stack has drifted from its template(s)",
+		zap.Stringer("stack_id", r.stackID),
+		zap.Int("drifted_resources", len(event.Report.Resources)),
+	)
+	if r.dryRunOnly {
+		return event, nil
+	}
+
+	if _, err := r.svc.Apply(ctx, r.orgID, r.userID, opts...); err != nil {
+		return event, err
+	}
+	event.Applied = true
+	return event, nil
+}
@@ -17,6 +17,7 @@ import (
 	ierrors "github.com/influxdata/influxdb/v2/kit/errors"
 	"github.com/influxdata/influxdb/v2/kit/platform"
 	errors2 "github.com/influxdata/influxdb/v2/kit/platform/errors"
+	nbsvc "github.com/influxdata/influxdb/v2/notebooks/service"
 	icheck "github.com/influxdata/influxdb/v2/notification/check"
 	"github.com/influxdata/influxdb/v2/notification/rule"
 	"github.com/influxdata/influxdb/v2/pkger/internal/wordplay"
@@ -154,17 +155,22 @@ type serviceOpt struct {
 	timeGen       influxdb.TimeGenerator
 	store         Store
 
-	bucketSVC   influxdb.BucketService
-	checkSVC    influxdb.CheckService
-	dashSVC     influxdb.DashboardService
-	labelSVC    influxdb.LabelService
-	endpointSVC influxdb.NotificationEndpointService
-	orgSVC      influxdb.OrganizationService
-	ruleSVC     influxdb.NotificationRuleStore
-	secretSVC   influxdb.SecretService
-	taskSVC     taskmodel.TaskService
-	teleSVC     influxdb.TelegrafConfigStore
-	varSVC      influxdb.VariableService
+	bucketSVC     influxdb.BucketService
+	checkSVC      influxdb.CheckService
+	dashSVC       influxdb.DashboardService
+	dbrpSVC       influxdb.DBRPMappingServiceV2
+	labelSVC      influxdb.LabelService
+	endpointSVC   influxdb.NotificationEndpointService
+	notebookSVC   nbsvc.NotebookService
+	orgSVC        influxdb.OrganizationService
+	ruleSVC       influxdb.NotificationRuleStore
+	scraperSVC    influxdb.ScraperTargetStoreService
+	secretSVC     influxdb.SecretService
+	taskSVC       taskmodel.TaskService
+	teleSVC       influxdb.TelegrafConfigStore
+	v1AuthSVC     influxdb.AuthorizationService
+	v1PasswordSVC V1PasswordService
+	varSVC        influxdb.VariableService
 }
 
 // ServiceSetterFn is a means of setting dependencies on the Service type.
@@ -219,6 +225,13 @@ func WithDashboardSVC(dashSVC influxdb.DashboardService) ServiceSetterFn {
 	}
 }
 
+// WithDBRPMappingSVC sets the dbrp mapping service.
+func WithDBRPMappingSVC(dbrpSVC influxdb.DBRPMappingServiceV2) ServiceSetterFn {
+	return func(opt *serviceOpt) {
+		opt.dbrpSVC = dbrpSVC
+	}
+}
+
 // WithLabelSVC sets the label service.
 func WithLabelSVC(labelSVC influxdb.LabelService) ServiceSetterFn {
 	return func(opt *serviceOpt) {
@@ -246,6 +259,14 @@ func WithNotificationRuleSVC(ruleSVC influxdb.NotificationRuleStore) ServiceSett
 	}
 }
 
+// WithNotebookSVC sets the notebook service. When not provided, templates
+// containing notebooks are rejected.
+func WithNotebookSVC(notebookSVC nbsvc.NotebookService) ServiceSetterFn {
+	return func(opt *serviceOpt) {
+		opt.notebookSVC = notebookSVC
+	}
+}
+
 // WithOrganizationService sets the organization service for the service.
 func WithOrganizationService(orgSVC influxdb.OrganizationService) ServiceSetterFn {
 	return func(opt *serviceOpt) {
@@ -253,6 +274,13 @@ func WithOrganizationService(orgSVC influxdb.OrganizationService) ServiceSetterF
 	}
 }
 
+// WithScraperTargetSVC sets the scraper target service.
+func WithScraperTargetSVC(scraperSVC influxdb.ScraperTargetStoreService) ServiceSetterFn {
+	return func(opt *serviceOpt) {
+		opt.scraperSVC = scraperSVC
+	}
+}
+
 // WithSecretSVC sets the secret service.
 func WithSecretSVC(secretSVC influxdb.SecretService) ServiceSetterFn {
 	return func(opt *serviceOpt) {
@@ -274,6 +302,20 @@ func WithTelegrafSVC(telegrafSVC influxdb.TelegrafConfigStore) ServiceSetterFn {
 	}
 }
 
+// WithV1AuthorizationSVC sets the legacy v1 authorization service.
+func WithV1AuthorizationSVC(authSVC influxdb.AuthorizationService) ServiceSetterFn {
+	return func(opt *serviceOpt) {
+		opt.v1AuthSVC = authSVC
+	}
+}
+
+// WithV1PasswordSVC sets the legacy v1 password service.
+func WithV1PasswordSVC(passwordSVC V1PasswordService) ServiceSetterFn {
+	return func(opt *serviceOpt) {
+		opt.v1PasswordSVC = passwordSVC
+	}
+}
+
 // WithVariableSVC sets the variable service.
 func WithVariableSVC(varSVC influxdb.VariableService) ServiceSetterFn {
 	return func(opt *serviceOpt) {
@@ -281,6 +323,11 @@ func WithVariableSVC(varSVC influxdb.VariableService) ServiceSetterFn {
 	}
 }
 
+// V1PasswordService sets the password of a legacy v1 authorization.
+type V1PasswordService interface {
+	SetPassword(ctx context.Context, authID platform.ID, password string) error
+}
+
 // Store is the storage behavior the Service depends on.
 type Store interface {
 	CreateStack(ctx context.Context, stack Stack) error
@@ -303,17 +350,22 @@ type Service struct {
 	timeGen       influxdb.TimeGenerator
 
 	// external service dependencies
-	bucketSVC   influxdb.BucketService
-	checkSVC    influxdb.CheckService
-	dashSVC     influxdb.DashboardService
-	labelSVC    influxdb.LabelService
-	endpointSVC influxdb.NotificationEndpointService
-	orgSVC      influxdb.OrganizationService
-	ruleSVC     influxdb.NotificationRuleStore
-	secretSVC   influxdb.SecretService
-	taskSVC     taskmodel.TaskService
-	teleSVC     influxdb.TelegrafConfigStore
-	varSVC      influxdb.VariableService
+	bucketSVC     influxdb.BucketService
+	checkSVC      influxdb.CheckService
+	dashSVC       influxdb.DashboardService
+	dbrpSVC       influxdb.DBRPMappingServiceV2
+	labelSVC      influxdb.LabelService
+	endpointSVC   influxdb.NotificationEndpointService
+	notebookSVC   nbsvc.NotebookService
+	orgSVC        influxdb.OrganizationService
+	ruleSVC       influxdb.NotificationRuleStore
+	scraperSVC    influxdb.ScraperTargetStoreService
+	secretSVC     influxdb.SecretService
+	taskSVC       taskmodel.TaskService
+	teleSVC       influxdb.TelegrafConfigStore
+	v1AuthSVC     influxdb.AuthorizationService
+	v1PasswordSVC V1PasswordService
+	varSVC        influxdb.VariableService
 }
 
 var _ SVC = (*Service)(nil)
@@ -340,17 +392,22 @@ func NewService(opts ...ServiceSetterFn) *Service {
 		store:         opt.store,
 		timeGen:       opt.timeGen,
 
-		bucketSVC:   opt.bucketSVC,
-		checkSVC:    opt.checkSVC,
-		labelSVC:    opt.labelSVC,
-		dashSVC:     opt.dashSVC,
-		endpointSVC: opt.endpointSVC,
-		orgSVC:      opt.orgSVC,
-		ruleSVC:     opt.ruleSVC,
-		secretSVC:   opt.secretSVC,
-		taskSVC:     opt.taskSVC,
-		teleSVC:     opt.teleSVC,
-		varSVC:      opt.varSVC,
+		bucketSVC:     opt.bucketSVC,
+		checkSVC:      opt.checkSVC,
+		labelSVC:      opt.labelSVC,
+		dashSVC:       opt.dashSVC,
+		dbrpSVC:       opt.dbrpSVC,
+		endpointSVC:   opt.endpointSVC,
+		notebookSVC:   opt.notebookSVC,
+		orgSVC:        opt.orgSVC,
+		ruleSVC:       opt.ruleSVC,
+		scraperSVC:    opt.scraperSVC,
+		secretSVC:     opt.secretSVC,
+		taskSVC:       opt.taskSVC,
+		teleSVC:       opt.teleSVC,
+		v1AuthSVC:     opt.v1AuthSVC,
+		v1PasswordSVC: opt.v1PasswordSVC,
+		varSVC:        opt.varSVC,
 	}
 }
 
@@ -637,6 +694,7 @@ func (s *Service) Export(ctx context.Context, setters ...ExportOptFn) (*Template
 				ID:       r.ID,
 				MetaName: r.MetaName,
 				Name:     r.Name,
+				orgID:    stack.OrgID,
 			}))
 		}
 
@@ -743,6 +801,24 @@ func (s *Service) cloneOrgDashboards(ctx context.Context, orgID platform.ID) ([]
 	return resources, nil
 }
 
+func (s *Service) cloneOrgDBRPMappings(ctx context.Context, orgID platform.ID) ([]ResourceToClone, error) {
+	mappings, _, err := s.dbrpSVC.FindMany(ctx, influxdb.DBRPMappingFilterV2{
+		OrgID: &orgID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]ResourceToClone, 0, len(mappings))
+	for _, m := range mappings {
+		resources = append(resources, ResourceToClone{
+			Kind: KindDBRPMapping,
+			ID:   m.ID,
+		})
+	}
+	return resources, nil
+}
+
 func (s *Service) cloneOrgLabels(ctx context.Context, orgID platform.ID) ([]ResourceToClone, error) {
 	filter := influxdb.LabelFilter{
 		OrgID: &orgID,
@@ -764,6 +840,37 @@ func (s *Service) cloneOrgLabels(ctx context.Context, orgID platform.ID) ([]Reso
 	return resources, nil
 }
 
+func (s *Service) cloneOrgNotebooks(ctx context.Context, orgID platform.ID) ([]ResourceToClone, error) {
+	if s.notebookSVC == nil {
+		return nil, nil
+	}
+
+	const pageSize = 100
+
+	var resources []ResourceToClone
+	for offset := 0; ; offset += pageSize {
+		notebooks, err := s.notebookSVC.ListNotebooks(ctx, nbsvc.NotebookListFilter{
+			OrgID: orgID,
+			Page:  nbsvc.Page{Offset: offset, Limit: pageSize},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, n := range notebooks {
+			resources = append(resources, ResourceToClone{
+				Kind:  KindNotebook,
+				ID:    n.ID,
+				Name:  n.Name,
+				orgID: orgID,
+			})
+		}
+		if len(notebooks) < pageSize {
+			return resources, nil
+		}
+	}
+}
+
 func (s *Service) cloneOrgNotificationEndpoints(ctx context.Context, orgID platform.ID) ([]ResourceToClone, error) {
 	endpoints, _, err := s.endpointSVC.FindNotificationEndpoints(ctx, influxdb.NotificationEndpointFilter{
 		OrgID: &orgID,
@@ -802,6 +909,26 @@ func (s *Service) cloneOrgNotificationRules(ctx context.Context, orgID platform.
 	return resources, nil
 }
 
+func (s *Service) cloneOrgScrapers(ctx context.Context, orgID platform.ID) ([]ResourceToClone, error) {
+	targets, err := s.scraperSVC.ListTargets(ctx, influxdb.ScraperTargetFilter{
+		OrgID: &orgID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]ResourceToClone, 0, len(targets))
+	for _, t := range targets {
+		resources = append(resources, ResourceToClone{
+			Kind:  KindScraper,
+			ID:    t.ID,
+			Name:  t.Name,
+			orgID: orgID,
+		})
+	}
+	return resources, nil
+}
+
 func (s *Service) cloneOrgTasks(ctx context.Context, orgID platform.ID) ([]ResourceToClone, error) {
 	tasks, err := s.getAllTasks(ctx, orgID)
 	if err != nil {
@@ -863,6 +990,24 @@ func (s *Service) cloneOrgTelegrafs(ctx context.Context, orgID platform.ID) ([]R
 	return resources, nil
 }
 
+func (s *Service) cloneOrgV1Authorizations(ctx context.Context, orgID platform.ID) ([]ResourceToClone, error) {
+	auths, _, err := s.v1AuthSVC.FindAuthorizations(ctx, influxdb.AuthorizationFilter{
+		OrgID: &orgID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]ResourceToClone, 0, len(auths))
+	for _, a := range auths {
+		resources = append(resources, ResourceToClone{
+			Kind: KindV1Authorization,
+			ID:   a.ID,
+		})
+	}
+	return resources, nil
+}
+
 func (s *Service) cloneOrgVariables(ctx context.Context, orgID platform.ID) ([]ResourceToClone, error) {
 	vars, err := s.varSVC.FindVariables(ctx, influxdb.VariableFilter{
 		OrganizationID: &orgID,
@@ -895,11 +1040,15 @@ func (s *Service) filterOrgResourceKinds(resourceKindFilters []Kind) []resClone
 		KindBucket:               s.cloneOrgBuckets,
 		KindCheck:                s.cloneOrgChecks,
 		KindDashboard:            s.cloneOrgDashboards,
+		KindDBRPMapping:          s.cloneOrgDBRPMappings,
 		KindLabel:                s.cloneOrgLabels,
+		KindNotebook:             s.cloneOrgNotebooks,
 		KindNotificationEndpoint: s.cloneOrgNotificationEndpoints,
 		KindNotificationRule:     s.cloneOrgNotificationRules,
+		KindScraper:              s.cloneOrgScrapers,
 		KindTask:                 s.cloneOrgTasks,
 		KindTelegraf:             s.cloneOrgTelegrafs,
+		KindV1Authorization:      s.cloneOrgV1Authorizations,
 		KindVariable:             s.cloneOrgVariables,
 	}
 
@@ -1000,6 +1149,14 @@ func (s *Service) dryRun(ctx context.Context, orgID platform.ID, template *Templ
 		parseErr = err
 	}
 
+	if len(opt.Params) > 0 {
+		err := template.applyParams(opt.Params)
+		if err != nil && !IsParseErr(err) {
+			return nil, internalErr(err)
+		}
+		parseErr = err
+	}
+
 	state := newStateCoordinator(template, resourceActions{
 		skipKinds:     opt.KindsToSkip,
 		skipResources: opt.ResourcesToSkip,
@@ -1033,6 +1190,22 @@ func (s *Service) dryRun(ctx context.Context, orgID platform.ID, template *Templ
 		return nil, err
 	}
 
+	if err := s.dryRunDBRPMappings(ctx, orgID, state.mDBRPMappings, state.mBuckets); err != nil {
+		return nil, err
+	}
+
+	if err := s.dryRunNotebooks(ctx, orgID, state.mNotebooks); err != nil {
+		return nil, err
+	}
+
+	if err := s.dryRunScrapers(ctx, orgID, state.mScrapers, state.mBuckets); err != nil {
+		return nil, err
+	}
+
+	if err := s.dryRunV1Authorizations(ctx, orgID, state.mV1Authorizations, state.mBuckets); err != nil {
+		return nil, err
+	}
+
 	stateLabelMappings, err := s.dryRunLabelMappings(ctx, state)
 	if err != nil {
 		return nil, err
@@ -1042,6 +1215,30 @@ func (s *Service) dryRun(ctx context.Context, orgID platform.ID, template *Templ
 	return state, parseErr
 }
 
+// dryRunBucketRef resolves the bucket a resource depends on. Buckets declared
+// within the template take precedence over buckets that already exist in the
+// platform.
+func (s *Service) dryRunBucketRef(ctx context.Context, orgID platform.ID, bkts map[string]*stateBucket, name string) (stateBucketRef, bool) {
+	ref := stateBucketRef{name: name}
+	if b, ok := bkts[name]; ok && !IsRemoval(b.stateStatus) {
+		ref.stateBkt = b
+		return ref, true
+	}
+	for _, b := range bkts {
+		if !IsRemoval(b.stateStatus) && b.parserBkt.Name() == name {
+			ref.stateBkt = b
+			return ref, true
+		}
+	}
+
+	existing, err := s.bucketSVC.FindBucketByName(ctx, orgID, name)
+	if err != nil || existing == nil {
+		return ref, false
+	}
+	ref.existing = existing
+	return ref, true
+}
+
 func (s *Service) dryRunBuckets(ctx context.Context, orgID platform.ID, bkts map[string]*stateBucket) {
 	for _, stateBkt := range bkts {
 		stateBkt.orgID = orgID
@@ -1093,6 +1290,46 @@ func (s *Service) dryRunDashboards(ctx context.Context, orgID platform.ID, dashs
 	}
 }
 
+func (s *Service) dryRunDBRPMappings(ctx context.Context, orgID platform.ID, mappings map[string]*stateDBRPMapping, bkts map[string]*stateBucket) error {
+	for _, stateDBRP := range mappings {
+		stateDBRP.orgID = orgID
+		var existing *influxdb.DBRPMappingV2
+		if stateDBRP.ID() != 0 {
+			existing, _ = s.dbrpSVC.FindByID(ctx, orgID, stateDBRP.ID())
+		} else {
+			db, rp := stateDBRP.parserDBRP.database, stateDBRP.parserDBRP.retentionPolicy
+			found, _, _ := s.dbrpSVC.FindMany(ctx, influxdb.DBRPMappingFilterV2{
+				OrgID:           &orgID,
+				Database:        &db,
+				RetentionPolicy: &rp,
+			})
+			if len(found) > 0 {
+				existing = found[0]
+			}
+		}
+		if IsNew(stateDBRP.stateStatus) && existing != nil {
+			stateDBRP.stateStatus = StateStatusExists
+		}
+		stateDBRP.existing = existing
+
+		if IsRemoval(stateDBRP.stateStatus) {
+			continue
+		}
+
+		bktName := stateDBRP.parserDBRP.bucketRef()
+		ref, ok := s.dryRunBucketRef(ctx, orgID, bkts, bktName)
+		if !ok {
+			err := fmt.Errorf("failed to find bucket %q dependency for dbrp mapping %q", bktName, stateDBRP.parserDBRP.MetaName())
+			return &errors2.Error{
+				Code: errors2.EUnprocessableEntity,
+				Err:  err,
+			}
+		}
+		stateDBRP.bucket = ref
+	}
+	return nil
+}
+
 func (s *Service) dryRunLabels(ctx context.Context, orgID platform.ID, labels map[string]*stateLabel) {
 	for _, l := range labels {
 		l.orgID = orgID
@@ -1104,6 +1341,45 @@ func (s *Service) dryRunLabels(ctx context.Context, orgID platform.ID, labels ma
 	}
 }
 
+func (s *Service) dryRunNotebooks(ctx context.Context, orgID platform.ID, notebooks map[string]*stateNotebook) error {
+	if len(notebooks) == 0 {
+		return nil
+	}
+	if s.notebookSVC == nil {
+		return &errors2.Error{
+			Code: errors2.EUnprocessableEntity,
+			Msg:  "notebooks are not supported by this instance",
+		}
+	}
+
+	var existingNotebooks []*nbsvc.Notebook
+	for _, stateNB := range notebooks {
+		stateNB.orgID = orgID
+		var existing *nbsvc.Notebook
+		if stateNB.ID() != 0 {
+			existing, _ = s.notebookSVC.GetNotebook(ctx, orgID, stateNB.ID())
+		} else {
+			if existingNotebooks == nil {
+				existingNotebooks, _ = s.notebookSVC.ListNotebooks(ctx, nbsvc.NotebookListFilter{
+					OrgID: orgID,
+					Page:  nbsvc.Page{Limit: 100},
+				})
+			}
+			for _, n := range existingNotebooks {
+				if n.Name == stateNB.parserNotebook.Name() {
+					existing = n
+					break
+				}
+			}
+		}
+		if IsNew(stateNB.stateStatus) && existing != nil {
+			stateNB.stateStatus = StateStatusExists
+		}
+		stateNB.existing = existing
+	}
+	return nil
+}
+
 func (s *Service) dryRunNotificationEndpoints(ctx context.Context, orgID platform.ID, endpoints map[string]*stateEndpoint) error {
 	existingEndpoints, _, err := s.endpointSVC.FindNotificationEndpoints(ctx, influxdb.NotificationEndpointFilter{
 		OrgID: &orgID,
@@ -1170,6 +1446,45 @@ func (s *Service) dryRunNotificationRules(ctx context.Context, orgID platform.ID
 	return nil
 }
 
+func (s *Service) dryRunScrapers(ctx context.Context, orgID platform.ID, scrapers map[string]*stateScraper, bkts map[string]*stateBucket) error {
+	for _, stateScraper := range scrapers {
+		stateScraper.orgID = orgID
+		var existing *influxdb.ScraperTarget
+		if stateScraper.ID() != 0 {
+			existing, _ = s.scraperSVC.GetTargetByID(ctx, stateScraper.ID())
+		} else {
+			name := stateScraper.parserScraper.Name()
+			targets, _ := s.scraperSVC.ListTargets(ctx, influxdb.ScraperTargetFilter{
+				OrgID: &orgID,
+				Name:  &name,
+			})
+			if len(targets) > 0 {
+				existing = &targets[0]
+			}
+		}
+		if IsNew(stateScraper.stateStatus) && existing != nil {
+			stateScraper.stateStatus = StateStatusExists
+		}
+		stateScraper.existing = existing
+
+		if IsRemoval(stateScraper.stateStatus) {
+			continue
+		}
+
+		bktName := stateScraper.parserScraper.bucketRef()
+		ref, ok := s.dryRunBucketRef(ctx, orgID, bkts, bktName)
+		if !ok {
+			err := fmt.Errorf("failed to find bucket %q dependency for scraper %q", bktName, stateScraper.parserScraper.MetaName())
+			return &errors2.Error{
+				Code: errors2.EUnprocessableEntity,
+				Err:  err,
+			}
+		}
+		stateScraper.bucket = ref
+	}
+	return nil
+}
+
 func (s *Service) dryRunSecrets(ctx context.Context, orgID platform.ID, template *Template) error {
 	templateSecrets := template.mSecrets
 	if len(templateSecrets) == 0 {
@@ -1216,6 +1531,47 @@ func (s *Service) dryRunTelegrafConfigs(ctx context.Context, orgID platform.ID,
 	}
 }
 
+func (s *Service) dryRunV1Authorizations(ctx context.Context, orgID platform.ID, auths map[string]*stateV1Authorization, bkts map[string]*stateBucket) error {
+	for _, stateAuth := range auths {
+		stateAuth.orgID = orgID
+		var existing *influxdb.Authorization
+		if stateAuth.ID() != 0 {
+			existing, _ = s.v1AuthSVC.FindAuthorizationByID(ctx, stateAuth.ID())
+		} else {
+			token := stateAuth.parserAuth.token
+			found, _, _ := s.v1AuthSVC.FindAuthorizations(ctx, influxdb.AuthorizationFilter{
+				Token: &token,
+			})
+			if len(found) > 0 && found[0].OrgID == orgID {
+				existing = found[0]
+			}
+		}
+		if IsNew(stateAuth.stateStatus) && existing != nil {
+			stateAuth.stateStatus = StateStatusExists
+		}
+		stateAuth.existing = existing
+
+		if IsRemoval(stateAuth.stateStatus) {
+			continue
+		}
+
+		stateAuth.buckets = make([]stateBucketRef, 0, len(stateAuth.parserAuth.permissions))
+		for _, p := range stateAuth.parserAuth.permissions {
+			bktName := p.bucket
+			ref, ok := s.dryRunBucketRef(ctx, orgID, bkts, bktName)
+			if !ok {
+				err := fmt.Errorf("failed to find bucket %q dependency for v1 authorization %q", bktName, stateAuth.parserAuth.MetaName())
+				return &errors2.Error{
+					Code: errors2.EUnprocessableEntity,
+					Err:  err,
+				}
+			}
+			stateAuth.buckets = append(stateAuth.buckets, ref)
+		}
+	}
+	return nil
+}
+
 func (s *Service) dryRunVariables(ctx context.Context, orgID platform.ID, vars map[string]*stateVariable) {
 	existingVars, _ := s.getAllPlatformVariables(ctx, orgID)
 
@@ -1413,6 +1769,7 @@ type (
 	ApplyOpt struct {
 		Templates       []*Template
 		EnvRefs         map[string]interface{}
+		Params          map[string]interface{}
 		MissingSecrets  map[string]string
 		StackID         platform.ID
 		ResourcesToSkip map[ActionSkipResource]bool
@@ -1443,6 +1800,13 @@ func ApplyWithEnvRefs(envRefs map[string]interface{}) ApplyOptFn {
 	}
 }
 
+// ApplyWithParams provides values for the parameters declared in the template.
+func ApplyWithParams(params map[string]interface{}) ApplyOptFn {
+	return func(o *ApplyOpt) {
+		o.Params = params
+	}
+}
+
 // ApplyWithTemplate provides a template to the application/dry run.
 func ApplyWithTemplate(template *Template) ApplyOptFn {
 	return func(opt *ApplyOpt) {
@@ -1457,7 +1821,7 @@ func ApplyWithResourceSkip(action ActionSkipResource) ApplyOptFn {
 			opt.ResourcesToSkip = make(map[ActionSkipResource]bool)
 		}
 		switch action.Kind {
-		case KindCheckDeadman, KindCheckThreshold:
+		case KindCheckDeadman, KindCheckThreshold, KindCheckAnomaly:
 			action.Kind = KindCheck
 		case KindNotificationEndpointHTTP,
 			KindNotificationEndpointPagerDuty,
@@ -1475,7 +1839,7 @@ func ApplyWithKindSkip(action ActionSkipKind) ApplyOptFn {
 			opt.KindsToSkip = make(map[Kind]bool)
 		}
 		switch action.Kind {
-		case KindCheckDeadman, KindCheckThreshold:
+		case KindCheckDeadman, KindCheckThreshold, KindCheckAnomaly:
 			action.Kind = KindCheck
 		case KindNotificationEndpointHTTP,
 			KindNotificationEndpointPagerDuty,
@@ -1527,6 +1891,14 @@ func (s *Service) Apply(ctx context.Context, orgID, userID platform.ID, opts ...
 		return ImpactSummary{}, failedValidationErr(err)
 	}
 
+	if err := template.applyParams(opt.Params); err != nil {
+		return ImpactSummary{}, failedValidationErr(err)
+	}
+
+	if missing := template.missingParams(); len(missing) > 0 {
+		return ImpactSummary{}, influxErr(errors2.EUnprocessableEntity, fmt.Sprintf("missing values for required parameters: %s", strings.Join(missing, ", ")))
+	}
+
 	state, err := s.dryRun(ctx, orgID, template, opt)
 	if err != nil {
 		return ImpactSummary{}, err
@@ -1608,10 +1980,18 @@ func (s *Service) applyState(ctx context.Context, coordinator *rollbackCoordinat
 			s.applyBuckets(ctx, state.buckets()),
 			s.applyChecks(ctx, state.checks()),
 			s.applyDashboards(ctx, state.dashboards()),
+			s.applyNotebooks(ctx, state.notebooks()),
 			endpointApp,
 			s.applyTasks(ctx, state.tasks()),
 			s.applyTelegrafs(ctx, userID, state.telegrafConfigs()),
 		},
+		{
+			// resources that reference buckets, the buckets must exist before these
+			// can be applied.
+			s.applyDBRPMappings(ctx, state.dbrpMappings()),
+			s.applyScrapers(ctx, userID, state.scrapers()),
+			s.applyV1Authorizations(ctx, state.v1Authorizations()),
+		},
 	}
 
 	for _, group := range appliers {
@@ -2014,6 +2394,111 @@ func convertChartsToCells(ch []*chart) []*influxdb.Cell {
 	return icells
 }
 
+func (s *Service) applyDBRPMappings(ctx context.Context, mappings []*stateDBRPMapping) applier {
+	const resource = "dbrp_mapping"
+
+	mutex := new(doMutex)
+	rollbackMappings := make([]*stateDBRPMapping, 0, len(mappings))
+
+	createFn := func(ctx context.Context, i int, orgID, userID platform.ID) *applyErrBody {
+		var d *stateDBRPMapping
+		mutex.Do(func() {
+			mappings[i].orgID = orgID
+			d = mappings[i]
+		})
+		if !d.shouldApply() {
+			return nil
+		}
+
+		influxMapping, err := s.applyDBRPMapping(ctx, d)
+		if err != nil {
+			return &applyErrBody{
+				name: d.parserDBRP.MetaName(),
+				msg:  err.Error(),
+			}
+		}
+
+		mutex.Do(func() {
+			mappings[i].id = influxMapping.ID
+			rollbackMappings = append(rollbackMappings, mappings[i])
+		})
+
+		return nil
+	}
+
+	return applier{
+		creater: creater{
+			entries: len(mappings),
+			fn:      createFn,
+		},
+		rollbacker: rollbacker{
+			resource: resource,
+			fn:       func(_ platform.ID) error { return s.rollbackDBRPMappings(ctx, rollbackMappings) },
+		},
+	}
+}
+
+func (s *Service) applyDBRPMapping(ctx context.Context, d *stateDBRPMapping) (influxdb.DBRPMappingV2, error) {
+	switch {
+	case IsRemoval(d.stateStatus):
+		if err := s.dbrpSVC.Delete(ctx, d.orgID, d.ID()); err != nil {
+			return influxdb.DBRPMappingV2{}, applyFailErr("delete", d.stateIdentity(), err)
+		}
+		if d.existing == nil {
+			return influxdb.DBRPMappingV2{}, nil
+		}
+		return *d.existing, nil
+	case IsExisting(d.stateStatus) && d.existing != nil:
+		mapping := d.toInfluxDBRP()
+		if mapping.BucketID != d.existing.BucketID {
+			return influxdb.DBRPMappingV2{}, applyFailErr("update", d.stateIdentity(), errors.New("the bucket of an existing dbrp mapping cannot be changed"))
+		}
+		if err := s.dbrpSVC.Update(ctx, &mapping); err != nil {
+			return influxdb.DBRPMappingV2{}, applyFailErr("update", d.stateIdentity(), err)
+		}
+		return mapping, nil
+	default:
+		mapping := d.toInfluxDBRP()
+		mapping.ID = 0
+		if err := s.dbrpSVC.Create(ctx, &mapping); err != nil {
+			return influxdb.DBRPMappingV2{}, applyFailErr("create", d.stateIdentity(), err)
+		}
+		return mapping, nil
+	}
+}
+
+func (s *Service) rollbackDBRPMappings(ctx context.Context, mappings []*stateDBRPMapping) error {
+	rollbackFn := func(d *stateDBRPMapping) error {
+		if !IsNew(d.stateStatus) && d.existing == nil {
+			return nil
+		}
+
+		var err error
+		switch d.stateStatus {
+		case StateStatusRemove:
+			err = ierrors.Wrap(s.dbrpSVC.Create(ctx, d.existing), "rolling back removed dbrp mapping")
+		case StateStatusExists:
+			err = ierrors.Wrap(s.dbrpSVC.Update(ctx, d.existing), "rolling back updated dbrp mapping")
+		default:
+			err = ierrors.Wrap(s.dbrpSVC.Delete(ctx, d.orgID, d.ID()), "rolling back created dbrp mapping")
+		}
+		return err
+	}
+
+	var errs []string
+	for _, d := range mappings {
+		if err := rollbackFn(d); err != nil {
+			errs = append(errs, fmt.Sprintf("error for dbrp mapping[%q]: %s", d.ID(), err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
 func (s *Service) applyLabels(ctx context.Context, labels []*stateLabel) applier {
 	const resource = "label"
 
@@ -2122,6 +2607,130 @@ func (s *Service) applyLabel(ctx context.Context, l *stateLabel) (influxdb.Label
 	return *influxLabel, nil
 }
 
+func (s *Service) applyNotebooks(ctx context.Context, notebooks []*stateNotebook) applier {
+	const resource = "notebook"
+
+	mutex := new(doMutex)
+	rollbackNotebooks := make([]*stateNotebook, 0, len(notebooks))
+
+	createFn := func(ctx context.Context, i int, orgID, userID platform.ID) *applyErrBody {
+		var n *stateNotebook
+		mutex.Do(func() {
+			notebooks[i].orgID = orgID
+			n = notebooks[i]
+		})
+		if !n.shouldApply() {
+			return nil
+		}
+
+		influxNotebook, err := s.applyNotebook(ctx, n)
+		if err != nil {
+			return &applyErrBody{
+				name: n.parserNotebook.MetaName(),
+				msg:  err.Error(),
+			}
+		}
+
+		mutex.Do(func() {
+			notebooks[i].id = influxNotebook.ID
+			rollbackNotebooks = append(rollbackNotebooks, notebooks[i])
+		})
+
+		return nil
+	}
+
+	return applier{
+		creater: creater{
+			entries: len(notebooks),
+			fn:      createFn,
+		},
+		rollbacker: rollbacker{
+			resource: resource,
+			fn:       func(_ platform.ID) error { return s.rollbackNotebooks(ctx, rollbackNotebooks) },
+		},
+	}
+}
+
+func (s *Service) applyNotebook(ctx context.Context, n *stateNotebook) (nbsvc.Notebook, error) {
+	switch {
+	case IsRemoval(n.stateStatus):
+		if err := s.notebookSVC.DeleteNotebook(ctx, n.orgID, n.ID()); err != nil {
+			if errors2.ErrorCode(err) == errors2.ENotFound {
+				return nbsvc.Notebook{}, nil
+			}
+			return nbsvc.Notebook{}, applyFailErr("delete", n.stateIdentity(), err)
+		}
+		if n.existing == nil {
+			return nbsvc.Notebook{}, nil
+		}
+		return *n.existing, nil
+	case IsExisting(n.stateStatus) && n.existing != nil:
+		updated, err := s.notebookSVC.UpdateNotebook(ctx, n.orgID, n.ID(), nbsvc.NotebookUpdate{
+			Name: n.parserNotebook.Name(),
+			Spec: n.parserNotebook.content,
+		})
+		if err != nil {
+			return nbsvc.Notebook{}, applyFailErr("update", n.stateIdentity(), err)
+		}
+		return *updated, nil
+	default:
+		created, err := s.notebookSVC.CreateNotebook(ctx, nbsvc.NotebookCreate{
+			OrgID: n.orgID,
+			Name:  n.parserNotebook.Name(),
+			Spec:  n.parserNotebook.content,
+		})
+		if err != nil {
+			return nbsvc.Notebook{}, applyFailErr("create", n.stateIdentity(), err)
+		}
+		return *created, nil
+	}
+}
+
+func (s *Service) rollbackNotebooks(ctx context.Context, notebooks []*stateNotebook) error {
+	rollbackFn := func(n *stateNotebook) error {
+		if !IsNew(n.stateStatus) && n.existing == nil {
+			return nil
+		}
+
+		var err error
+		switch n.stateStatus {
+		case StateStatusRemove:
+			var created *nbsvc.Notebook
+			created, err = s.notebookSVC.CreateNotebook(ctx, nbsvc.NotebookCreate{
+				OrgID: n.existing.OrgID,
+				Name:  n.existing.Name,
+				Spec:  n.existing.Spec,
+			})
+			if err == nil {
+				n.existing = created
+			}
+			err = ierrors.Wrap(err, "rolling back removed notebook")
+		case StateStatusExists:
+			_, err = s.notebookSVC.UpdateNotebook(ctx, n.orgID, n.ID(), nbsvc.NotebookUpdate{
+				Name: n.existing.Name,
+				Spec: n.existing.Spec,
+			})
+			err = ierrors.Wrap(err, "rolling back updated notebook")
+		default:
+			err = ierrors.Wrap(s.notebookSVC.DeleteNotebook(ctx, n.orgID, n.ID()), "rolling back created notebook")
+		}
+		return err
+	}
+
+	var errs []string
+	for _, n := range notebooks {
+		if err := rollbackFn(n); err != nil {
+			errs = append(errs, fmt.Sprintf("error for notebook[%q]: %s", n.ID(), err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
 func (s *Service) applyNotificationEndpoints(ctx context.Context, userID platform.ID, endpoints []*stateEndpoint) (applier, func(platform.ID) error) {
 	mutex := new(doMutex)
 	rollbackEndpoints := make([]*stateEndpoint, 0, len(endpoints))
@@ -2446,6 +3055,115 @@ func (s *Service) rollbackNotificationRules(ctx context.Context, userID platform
 	return nil
 }
 
+func (s *Service) applyScrapers(ctx context.Context, userID platform.ID, scrapers []*stateScraper) applier {
+	const resource = "scraper"
+
+	mutex := new(doMutex)
+	rollbackScrapers := make([]*stateScraper, 0, len(scrapers))
+
+	createFn := func(ctx context.Context, i int, orgID, userID platform.ID) *applyErrBody {
+		var sc *stateScraper
+		mutex.Do(func() {
+			scrapers[i].orgID = orgID
+			sc = scrapers[i]
+		})
+		if !sc.shouldApply() {
+			return nil
+		}
+
+		influxScraper, err := s.applyScraper(ctx, userID, sc)
+		if err != nil {
+			return &applyErrBody{
+				name: sc.parserScraper.MetaName(),
+				msg:  err.Error(),
+			}
+		}
+
+		mutex.Do(func() {
+			scrapers[i].id = influxScraper.ID
+			rollbackScrapers = append(rollbackScrapers, scrapers[i])
+		})
+
+		return nil
+	}
+
+	return applier{
+		creater: creater{
+			entries: len(scrapers),
+			fn:      createFn,
+		},
+		rollbacker: rollbacker{
+			resource: resource,
+			fn: func(_ platform.ID) error {
+				return s.rollbackScrapers(ctx, userID, rollbackScrapers)
+			},
+		},
+	}
+}
+
+func (s *Service) applyScraper(ctx context.Context, userID platform.ID, sc *stateScraper) (influxdb.ScraperTarget, error) {
+	switch {
+	case IsRemoval(sc.stateStatus):
+		if err := s.scraperSVC.RemoveTarget(ctx, sc.ID()); err != nil {
+			if errors2.ErrorCode(err) == errors2.ENotFound {
+				return influxdb.ScraperTarget{}, nil
+			}
+			return influxdb.ScraperTarget{}, applyFailErr("delete", sc.stateIdentity(), err)
+		}
+		if sc.existing == nil {
+			return influxdb.ScraperTarget{}, nil
+		}
+		return *sc.existing, nil
+	case IsExisting(sc.stateStatus) && sc.existing != nil:
+		target := sc.toInfluxScraper()
+		updated, err := s.scraperSVC.UpdateTarget(ctx, &target, userID)
+		if err != nil {
+			return influxdb.ScraperTarget{}, applyFailErr("update", sc.stateIdentity(), err)
+		}
+		return *updated, nil
+	default:
+		target := sc.toInfluxScraper()
+		target.ID = 0
+		if err := s.scraperSVC.AddTarget(ctx, &target, userID); err != nil {
+			return influxdb.ScraperTarget{}, applyFailErr("create", sc.stateIdentity(), err)
+		}
+		return target, nil
+	}
+}
+
+func (s *Service) rollbackScrapers(ctx context.Context, userID platform.ID, scrapers []*stateScraper) error {
+	rollbackFn := func(sc *stateScraper) error {
+		if !IsNew(sc.stateStatus) && sc.existing == nil {
+			return nil
+		}
+
+		var err error
+		switch sc.stateStatus {
+		case StateStatusRemove:
+			err = ierrors.Wrap(s.scraperSVC.AddTarget(ctx, sc.existing, userID), "rolling back removed scraper")
+		case StateStatusExists:
+			_, err = s.scraperSVC.UpdateTarget(ctx, sc.existing, userID)
+			err = ierrors.Wrap(err, "rolling back updated scraper")
+		default:
+			err = ierrors.Wrap(s.scraperSVC.RemoveTarget(ctx, sc.ID()), "rolling back created scraper")
+		}
+		return err
+	}
+
+	var errs []string
+	for _, sc := range scrapers {
+		if err := rollbackFn(sc); err != nil {
+			errs = append(errs, fmt.Sprintf("error for scraper[%q]: %s", sc.ID(), err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
 func (s *Service) applySecrets(secrets map[string]string) applier {
 	const resource = "secrets"
 
@@ -2756,6 +3474,155 @@ func (s *Service) rollbackTelegrafConfigs(ctx context.Context, userID platform.I
 	return nil
 }
 
+func (s *Service) applyV1Authorizations(ctx context.Context, auths []*stateV1Authorization) applier {
+	const resource = "v1_authorization"
+
+	mutex := new(doMutex)
+	rollbackAuths := make([]*stateV1Authorization, 0, len(auths))
+
+	createFn := func(ctx context.Context, i int, orgID, userID platform.ID) *applyErrBody {
+		var a *stateV1Authorization
+		mutex.Do(func() {
+			auths[i].orgID = orgID
+			a = auths[i]
+		})
+
+		influxAuth, err := s.applyV1Authorization(ctx, userID, a)
+		if err != nil {
+			return &applyErrBody{
+				name: a.parserAuth.MetaName(),
+				msg:  err.Error(),
+			}
+		}
+
+		mutex.Do(func() {
+			auths[i].id = influxAuth.ID
+			rollbackAuths = append(rollbackAuths, auths[i])
+		})
+
+		return nil
+	}
+
+	return applier{
+		creater: creater{
+			entries: len(auths),
+			fn:      createFn,
+		},
+		rollbacker: rollbacker{
+			resource: resource,
+			fn:       func(_ platform.ID) error { return s.rollbackV1Authorizations(ctx, rollbackAuths) },
+		},
+	}
+}
+
+func (s *Service) applyV1Authorization(ctx context.Context, userID platform.ID, a *stateV1Authorization) (influxdb.Authorization, error) {
+	var influxAuth influxdb.Authorization
+	switch {
+	case IsRemoval(a.stateStatus):
+		if err := s.v1AuthSVC.DeleteAuthorization(ctx, a.ID()); err != nil {
+			if errors2.ErrorCode(err) == errors2.ENotFound {
+				return influxdb.Authorization{}, nil
+			}
+			return influxdb.Authorization{}, applyFailErr("delete", a.stateIdentity(), err)
+		}
+		if a.existing == nil {
+			return influxdb.Authorization{}, nil
+		}
+		return *a.existing, nil
+	case IsExisting(a.stateStatus) && a.existing != nil:
+		if !a.shouldApply() {
+			influxAuth = *a.existing
+			break
+		}
+		status, desc := a.parserAuth.Status(), a.parserAuth.description
+		updated, err := s.v1AuthSVC.UpdateAuthorization(ctx, a.ID(), &influxdb.AuthorizationUpdate{
+			Status:      &status,
+			Description: &desc,
+		})
+		if err != nil {
+			return influxdb.Authorization{}, applyFailErr("update", a.stateIdentity(), err)
+		}
+		influxAuth = *updated
+	default:
+		perms, err := a.toInfluxPermissions()
+		if err != nil {
+			return influxdb.Authorization{}, applyFailErr("create", a.stateIdentity(), err)
+		}
+		influxAuth = influxdb.Authorization{
+			Token:       a.parserAuth.token,
+			Status:      a.parserAuth.Status(),
+			Description: a.parserAuth.description,
+			OrgID:       a.orgID,
+			UserID:      userID,
+			Permissions: perms,
+		}
+		if err := s.v1AuthSVC.CreateAuthorization(ctx, &influxAuth); err != nil {
+			return influxdb.Authorization{}, applyFailErr("create", a.stateIdentity(), err)
+		}
+	}
+
+	password, err := s.v1AuthPassword(ctx, a)
+	if err != nil {
+		return influxAuth, applyFailErr("set password for", a.stateIdentity(), err)
+	}
+	if password != "" {
+		if err := s.v1PasswordSVC.SetPassword(ctx, influxAuth.ID, password); err != nil {
+			return influxAuth, applyFailErr("set password for", a.stateIdentity(), err)
+		}
+	}
+
+	return influxAuth, nil
+}
+
+// v1AuthPassword resolves the password of the authorization, which is either
+// provided inline or as a reference to a secret.
+func (s *Service) v1AuthPassword(ctx context.Context, a *stateV1Authorization) (string, error) {
+	ref := a.parserAuth.password
+	if ref == nil {
+		return "", nil
+	}
+	if ref.Secret != "" {
+		return s.secretSVC.LoadSecret(ctx, a.orgID, ref.Secret)
+	}
+	return ref.String(), nil
+}
+
+func (s *Service) rollbackV1Authorizations(ctx context.Context, auths []*stateV1Authorization) error {
+	rollbackFn := func(a *stateV1Authorization) error {
+		if !IsNew(a.stateStatus) && a.existing == nil {
+			return nil
+		}
+
+		var err error
+		switch a.stateStatus {
+		case StateStatusRemove:
+			err = ierrors.Wrap(s.v1AuthSVC.CreateAuthorization(ctx, a.existing), "rolling back removed v1 authorization")
+		case StateStatusExists:
+			_, err = s.v1AuthSVC.UpdateAuthorization(ctx, a.ID(), &influxdb.AuthorizationUpdate{
+				Status:      &a.existing.Status,
+				Description: &a.existing.Description,
+			})
+			err = ierrors.Wrap(err, "rolling back updated v1 authorization")
+		default:
+			err = ierrors.Wrap(s.v1AuthSVC.DeleteAuthorization(ctx, a.ID()), "rolling back created v1 authorization")
+		}
+		return err
+	}
+
+	var errs []string
+	for _, a := range auths {
+		if err := rollbackFn(a); err != nil {
+			errs = append(errs, fmt.Sprintf("error for v1 authorization[%q]: %s", a.ID(), err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
 func (s *Service) applyVariables(ctx context.Context, vars []*stateVariable) applier {
 	const resource = "variable"
 
@@ -3127,6 +3994,29 @@ func (s *Service) updateStackAfterSuccess(ctx context.Context, stackID platform.
 			Associations: stateLabelsToStackAssociations(n.labels()),
 		})
 	}
+	for _, d := range state.mDBRPMappings {
+		if IsRemoval(d.stateStatus) {
+			continue
+		}
+		stackResources = append(stackResources, StackResource{
+			APIVersion:   APIVersion,
+			ID:           d.ID(),
+			Kind:         KindDBRPMapping,
+			MetaName:     d.parserDBRP.MetaName(),
+			Associations: d.bucketAssociations(),
+		})
+	}
+	for _, n := range state.mNotebooks {
+		if IsRemoval(n.stateStatus) {
+			continue
+		}
+		stackResources = append(stackResources, StackResource{
+			APIVersion: APIVersion,
+			ID:         n.ID(),
+			Kind:       KindNotebook,
+			MetaName:   n.parserNotebook.MetaName(),
+		})
+	}
 	for _, l := range state.mLabels {
 		if IsRemoval(l.stateStatus) {
 			continue
@@ -3153,6 +4043,18 @@ func (s *Service) updateStackAfterSuccess(ctx context.Context, stackID platform.
 			),
 		})
 	}
+	for _, sc := range state.mScrapers {
+		if IsRemoval(sc.stateStatus) {
+			continue
+		}
+		stackResources = append(stackResources, StackResource{
+			APIVersion:   APIVersion,
+			ID:           sc.ID(),
+			Kind:         KindScraper,
+			MetaName:     sc.parserScraper.MetaName(),
+			Associations: sc.bucketAssociations(),
+		})
+	}
 	for _, t := range state.mTasks {
 		if IsRemoval(t.stateStatus) || isRestrictedTask(t.existing) {
 			continue
@@ -3177,6 +4079,18 @@ func (s *Service) updateStackAfterSuccess(ctx context.Context, stackID platform.
 			Associations: stateLabelsToStackAssociations(t.labels()),
 		})
 	}
+	for _, a := range state.mV1Authorizations {
+		if IsRemoval(a.stateStatus) {
+			continue
+		}
+		stackResources = append(stackResources, StackResource{
+			APIVersion:   APIVersion,
+			ID:           a.ID(),
+			Kind:         KindV1Authorization,
+			MetaName:     a.parserAuth.MetaName(),
+			Associations: a.bucketAssociations(),
+		})
+	}
 	for _, v := range state.mVariables {
 		if IsRemoval(v.stateStatus) {
 			continue
@@ -3252,6 +4166,13 @@ func (s *Service) updateStackAfterRollback(ctx context.Context, stackID platform
 				res.ID = e.existing.GetID()
 			}
 		}
+		for _, d := range state.mDBRPMappings {
+			res, ok := existingResources[newKey(KindDBRPMapping, d.parserDBRP.MetaName())]
+			if ok && res.ID != d.ID() {
+				hasChanges = true
+				res.ID = d.existing.ID
+			}
+		}
 		for _, l := range state.mLabels {
 			res, ok := existingResources[newKey(KindLabel, l.parserLabel.MetaName())]
 			if ok && res.ID != l.ID() {
@@ -3259,6 +4180,13 @@ func (s *Service) updateStackAfterRollback(ctx context.Context, stackID platform
 				res.ID = l.existing.ID
 			}
 		}
+		for _, n := range state.mNotebooks {
+			res, ok := existingResources[newKey(KindNotebook, n.parserNotebook.MetaName())]
+			if ok && res.ID != n.ID() {
+				hasChanges = true
+				res.ID = n.existing.ID
+			}
+		}
 		for _, r := range state.mRules {
 			res, ok := existingResources[newKey(KindNotificationRule, r.parserRule.MetaName())]
 			if !ok {
@@ -3286,6 +4214,13 @@ func (s *Service) updateStackAfterRollback(ctx context.Context, stackID platform
 				res.Associations = newAss
 			}
 		}
+		for _, sc := range state.mScrapers {
+			res, ok := existingResources[newKey(KindScraper, sc.parserScraper.MetaName())]
+			if ok && res.ID != sc.ID() {
+				hasChanges = true
+				res.ID = sc.existing.ID
+			}
+		}
 		for _, t := range state.mTasks {
 			res, ok := existingResources[newKey(KindTask, t.parserTask.MetaName())]
 			if ok && res.ID != t.ID() {
@@ -3300,6 +4235,13 @@ func (s *Service) updateStackAfterRollback(ctx context.Context, stackID platform
 				res.ID = t.existing.ID
 			}
 		}
+		for _, a := range state.mV1Authorizations {
+			res, ok := existingResources[newKey(KindV1Authorization, a.parserAuth.MetaName())]
+			if ok && res.ID != a.ID() {
+				hasChanges = true
+				res.ID = a.existing.ID
+			}
+		}
 		for _, v := range state.mVariables {
 			res, ok := existingResources[newKey(KindVariable, v.parserVar.MetaName())]
 			if ok && res.ID != v.ID() {
@@ -3441,7 +4383,11 @@ func (s *Service) getAllTasks(ctx context.Context, orgID platform.ID) ([]*taskmo
 func newSummaryFromStateTemplate(state *stateCoordinator, template *Template) Summary {
 	stateSum := state.summary()
 	stateSum.MissingEnvs = template.missingEnvRefs()
+	stateSum.MissingParams = template.missingParams()
 	stateSum.MissingSecrets = template.missingSecrets()
+	for _, param := range template.parameters() {
+		stateSum.Parameters = append(stateSum.Parameters, param.summarize())
+	}
 	return stateSum
 }
 
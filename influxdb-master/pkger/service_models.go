@@ -6,20 +6,25 @@ import (
 
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/kit/platform"
+	nbsvc "github.com/influxdata/influxdb/v2/notebooks/service"
 	"github.com/influxdata/influxdb/v2/notification/rule"
 	"github.com/influxdata/influxdb/v2/task/taskmodel"
 )
 
 type stateCoordinator struct {
-	mBuckets    map[string]*stateBucket
-	mChecks     map[string]*stateCheck
-	mDashboards map[string]*stateDashboard
-	mEndpoints  map[string]*stateEndpoint
-	mLabels     map[string]*stateLabel
-	mRules      map[string]*stateRule
-	mTasks      map[string]*stateTask
-	mTelegrafs  map[string]*stateTelegraf
-	mVariables  map[string]*stateVariable
+	mBuckets          map[string]*stateBucket
+	mChecks           map[string]*stateCheck
+	mDashboards       map[string]*stateDashboard
+	mDBRPMappings     map[string]*stateDBRPMapping
+	mEndpoints        map[string]*stateEndpoint
+	mLabels           map[string]*stateLabel
+	mNotebooks        map[string]*stateNotebook
+	mRules            map[string]*stateRule
+	mScrapers         map[string]*stateScraper
+	mTasks            map[string]*stateTask
+	mTelegrafs        map[string]*stateTelegraf
+	mV1Authorizations map[string]*stateV1Authorization
+	mVariables        map[string]*stateVariable
 
 	labelMappings         []stateLabelMapping
 	labelMappingsToRemove []stateLabelMappingForRemoval
@@ -27,15 +32,19 @@ type stateCoordinator struct {
 
 func newStateCoordinator(template *Template, acts resourceActions) *stateCoordinator {
 	state := stateCoordinator{
-		mBuckets:    make(map[string]*stateBucket),
-		mChecks:     make(map[string]*stateCheck),
-		mDashboards: make(map[string]*stateDashboard),
-		mEndpoints:  make(map[string]*stateEndpoint),
-		mLabels:     make(map[string]*stateLabel),
-		mRules:      make(map[string]*stateRule),
-		mTasks:      make(map[string]*stateTask),
-		mTelegrafs:  make(map[string]*stateTelegraf),
-		mVariables:  make(map[string]*stateVariable),
+		mBuckets:          make(map[string]*stateBucket),
+		mChecks:           make(map[string]*stateCheck),
+		mDashboards:       make(map[string]*stateDashboard),
+		mDBRPMappings:     make(map[string]*stateDBRPMapping),
+		mEndpoints:        make(map[string]*stateEndpoint),
+		mLabels:           make(map[string]*stateLabel),
+		mNotebooks:        make(map[string]*stateNotebook),
+		mRules:            make(map[string]*stateRule),
+		mScrapers:         make(map[string]*stateScraper),
+		mTasks:            make(map[string]*stateTask),
+		mTelegrafs:        make(map[string]*stateTelegraf),
+		mV1Authorizations: make(map[string]*stateV1Authorization),
+		mVariables:        make(map[string]*stateVariable),
 	}
 
 	// labels are done first to validate dependencies are accounted for.
@@ -131,6 +140,42 @@ func newStateCoordinator(template *Template, acts resourceActions) *stateCoordin
 			labelAssociations: state.templateToStateLabels(v.labels),
 		}
 	}
+	for _, d := range template.dbrpMappings() {
+		if acts.skipResource(KindDBRPMapping, d.MetaName()) {
+			continue
+		}
+		state.mDBRPMappings[d.MetaName()] = &stateDBRPMapping{
+			parserDBRP:  d,
+			stateStatus: StateStatusNew,
+		}
+	}
+	for _, n := range template.notebooks() {
+		if acts.skipResource(KindNotebook, n.MetaName()) {
+			continue
+		}
+		state.mNotebooks[n.MetaName()] = &stateNotebook{
+			parserNotebook: n,
+			stateStatus:    StateStatusNew,
+		}
+	}
+	for _, sc := range template.scrapers() {
+		if acts.skipResource(KindScraper, sc.MetaName()) {
+			continue
+		}
+		state.mScrapers[sc.MetaName()] = &stateScraper{
+			parserScraper: sc,
+			stateStatus:   StateStatusNew,
+		}
+	}
+	for _, a := range template.v1Authorizations() {
+		if acts.skipResource(KindV1Authorization, a.MetaName()) {
+			continue
+		}
+		state.mV1Authorizations[a.MetaName()] = &stateV1Authorization{
+			parserAuth:  a,
+			stateStatus: StateStatusNew,
+		}
+	}
 
 	return &state
 }
@@ -159,6 +204,14 @@ func (s *stateCoordinator) dashboards() []*stateDashboard {
 	return out
 }
 
+func (s *stateCoordinator) dbrpMappings() []*stateDBRPMapping {
+	out := make([]*stateDBRPMapping, 0, len(s.mDBRPMappings))
+	for _, d := range s.mDBRPMappings {
+		out = append(out, d)
+	}
+	return out
+}
+
 func (s *stateCoordinator) endpoints() []*stateEndpoint {
 	out := make([]*stateEndpoint, 0, len(s.mEndpoints))
 	for _, e := range s.mEndpoints {
@@ -175,6 +228,14 @@ func (s *stateCoordinator) labels() []*stateLabel {
 	return out
 }
 
+func (s *stateCoordinator) notebooks() []*stateNotebook {
+	out := make([]*stateNotebook, 0, len(s.mNotebooks))
+	for _, n := range s.mNotebooks {
+		out = append(out, n)
+	}
+	return out
+}
+
 func (s *stateCoordinator) rules() []*stateRule {
 	out := make([]*stateRule, 0, len(s.mRules))
 	for _, r := range s.mRules {
@@ -183,6 +244,14 @@ func (s *stateCoordinator) rules() []*stateRule {
 	return out
 }
 
+func (s *stateCoordinator) scrapers() []*stateScraper {
+	out := make([]*stateScraper, 0, len(s.mScrapers))
+	for _, sc := range s.mScrapers {
+		out = append(out, sc)
+	}
+	return out
+}
+
 func (s *stateCoordinator) tasks() []*stateTask {
 	out := make([]*stateTask, 0, len(s.mTasks))
 	for _, t := range s.mTasks {
@@ -199,6 +268,14 @@ func (s *stateCoordinator) telegrafConfigs() []*stateTelegraf {
 	return out
 }
 
+func (s *stateCoordinator) v1Authorizations() []*stateV1Authorization {
+	out := make([]*stateV1Authorization, 0, len(s.mV1Authorizations))
+	for _, a := range s.mV1Authorizations {
+		out = append(out, a)
+	}
+	return out
+}
+
 func (s *stateCoordinator) variables() []*stateVariable {
 	out := make([]*stateVariable, 0, len(s.mVariables))
 	for _, v := range s.mVariables {
@@ -230,6 +307,13 @@ func (s *stateCoordinator) diff() Diff {
 		return diff.Dashboards[i].MetaName < diff.Dashboards[j].MetaName
 	})
 
+	for _, d := range s.mDBRPMappings {
+		diff.DBRPMappings = append(diff.DBRPMappings, d.diffDBRPMapping())
+	}
+	sort.Slice(diff.DBRPMappings, func(i, j int) bool {
+		return diff.DBRPMappings[i].MetaName < diff.DBRPMappings[j].MetaName
+	})
+
 	for _, e := range s.mEndpoints {
 		diff.NotificationEndpoints = append(diff.NotificationEndpoints, e.diffEndpoint())
 	}
@@ -244,6 +328,13 @@ func (s *stateCoordinator) diff() Diff {
 		return diff.Labels[i].MetaName < diff.Labels[j].MetaName
 	})
 
+	for _, n := range s.mNotebooks {
+		diff.Notebooks = append(diff.Notebooks, n.diffNotebook())
+	}
+	sort.Slice(diff.Notebooks, func(i, j int) bool {
+		return diff.Notebooks[i].MetaName < diff.Notebooks[j].MetaName
+	})
+
 	for _, r := range s.mRules {
 		diff.NotificationRules = append(diff.NotificationRules, r.diffRule())
 	}
@@ -251,6 +342,13 @@ func (s *stateCoordinator) diff() Diff {
 		return diff.NotificationRules[i].MetaName < diff.NotificationRules[j].MetaName
 	})
 
+	for _, sc := range s.mScrapers {
+		diff.Scrapers = append(diff.Scrapers, sc.diffScraper())
+	}
+	sort.Slice(diff.Scrapers, func(i, j int) bool {
+		return diff.Scrapers[i].MetaName < diff.Scrapers[j].MetaName
+	})
+
 	for _, t := range s.mTasks {
 		diff.Tasks = append(diff.Tasks, t.diffTask())
 	}
@@ -265,6 +363,13 @@ func (s *stateCoordinator) diff() Diff {
 		return diff.Telegrafs[i].MetaName < diff.Telegrafs[j].MetaName
 	})
 
+	for _, a := range s.mV1Authorizations {
+		diff.V1Authorizations = append(diff.V1Authorizations, a.diffV1Authorization())
+	}
+	sort.Slice(diff.V1Authorizations, func(i, j int) bool {
+		return diff.V1Authorizations[i].MetaName < diff.V1Authorizations[j].MetaName
+	})
+
 	for _, v := range s.mVariables {
 		diff.Variables = append(diff.Variables, v.diffVariable())
 	}
@@ -331,6 +436,16 @@ func (s *stateCoordinator) summary() Summary {
 		return sum.Dashboards[i].MetaName < sum.Dashboards[j].MetaName
 	})
 
+	for _, d := range s.mDBRPMappings {
+		if IsRemoval(d.stateStatus) {
+			continue
+		}
+		sum.DBRPMappings = append(sum.DBRPMappings, d.summarize())
+	}
+	sort.Slice(sum.DBRPMappings, func(i, j int) bool {
+		return sum.DBRPMappings[i].MetaName < sum.DBRPMappings[j].MetaName
+	})
+
 	for _, e := range s.mEndpoints {
 		if IsRemoval(e.stateStatus) {
 			continue
@@ -351,6 +466,16 @@ func (s *stateCoordinator) summary() Summary {
 		return sum.Labels[i].MetaName < sum.Labels[j].MetaName
 	})
 
+	for _, n := range s.mNotebooks {
+		if IsRemoval(n.stateStatus) {
+			continue
+		}
+		sum.Notebooks = append(sum.Notebooks, n.summarize())
+	}
+	sort.Slice(sum.Notebooks, func(i, j int) bool {
+		return sum.Notebooks[i].MetaName < sum.Notebooks[j].MetaName
+	})
+
 	for _, v := range s.mRules {
 		if IsRemoval(v.stateStatus) {
 			continue
@@ -361,6 +486,16 @@ func (s *stateCoordinator) summary() Summary {
 		return sum.NotificationRules[i].MetaName < sum.NotificationRules[j].MetaName
 	})
 
+	for _, sc := range s.mScrapers {
+		if IsRemoval(sc.stateStatus) {
+			continue
+		}
+		sum.Scrapers = append(sum.Scrapers, sc.summarize())
+	}
+	sort.Slice(sum.Scrapers, func(i, j int) bool {
+		return sum.Scrapers[i].MetaName < sum.Scrapers[j].MetaName
+	})
+
 	for _, t := range s.mTasks {
 		if IsRemoval(t.stateStatus) {
 			continue
@@ -381,6 +516,16 @@ func (s *stateCoordinator) summary() Summary {
 		return sum.TelegrafConfigs[i].MetaName < sum.TelegrafConfigs[j].MetaName
 	})
 
+	for _, a := range s.mV1Authorizations {
+		if IsRemoval(a.stateStatus) {
+			continue
+		}
+		sum.V1Authorizations = append(sum.V1Authorizations, a.summarize())
+	}
+	sort.Slice(sum.V1Authorizations, func(i, j int) bool {
+		return sum.V1Authorizations[i].MetaName < sum.V1Authorizations[j].MetaName
+	})
+
 	for _, v := range s.mVariables {
 		if IsRemoval(v.stateStatus) {
 			continue
@@ -510,15 +655,21 @@ func (s *stateCoordinator) get(k Kind, metaName string) (interface{}, bool) {
 	case KindBucket:
 		v, ok := s.mBuckets[metaName]
 		return v, ok
-	case KindCheck, KindCheckDeadman, KindCheckThreshold:
+	case KindCheck, KindCheckDeadman, KindCheckThreshold, KindCheckAnomaly:
 		v, ok := s.mChecks[metaName]
 		return v, ok
 	case KindDashboard:
 		v, ok := s.mDashboards[metaName]
 		return v, ok
+	case KindDBRPMapping:
+		v, ok := s.mDBRPMappings[metaName]
+		return v, ok
 	case KindLabel:
 		v, ok := s.mLabels[metaName]
 		return v, ok
+	case KindNotebook:
+		v, ok := s.mNotebooks[metaName]
+		return v, ok
 	case KindNotificationEndpoint,
 		KindNotificationEndpointHTTP,
 		KindNotificationEndpointPagerDuty,
@@ -528,12 +679,18 @@ func (s *stateCoordinator) get(k Kind, metaName string) (interface{}, bool) {
 	case KindNotificationRule:
 		v, ok := s.mRules[metaName]
 		return v, ok
+	case KindScraper:
+		v, ok := s.mScrapers[metaName]
+		return v, ok
 	case KindTask:
 		v, ok := s.mTasks[metaName]
 		return v, ok
 	case KindTelegraf:
 		v, ok := s.mTelegrafs[metaName]
 		return v, ok
+	case KindV1Authorization:
+		v, ok := s.mV1Authorizations[metaName]
+		return v, ok
 	case KindVariable:
 		v, ok := s.mVariables[metaName]
 		return v, ok
@@ -584,7 +741,7 @@ func (s *stateCoordinator) addObjectForRemoval(k Kind, metaName string, id platf
 			parserBkt:   &bucket{identity: newIdentity},
 			stateStatus: StateStatusRemove,
 		}
-	case KindCheck, KindCheckDeadman, KindCheckThreshold:
+	case KindCheck, KindCheckDeadman, KindCheckThreshold, KindCheckAnomaly:
 		s.mChecks[metaName] = &stateCheck{
 			id:          id,
 			parserCheck: &check{identity: newIdentity},
@@ -596,12 +753,24 @@ func (s *stateCoordinator) addObjectForRemoval(k Kind, metaName string, id platf
 			parserDash:  &dashboard{identity: newIdentity},
 			stateStatus: StateStatusRemove,
 		}
+	case KindDBRPMapping:
+		s.mDBRPMappings[metaName] = &stateDBRPMapping{
+			id:          id,
+			parserDBRP:  &dbrpMapping{identity: newIdentity},
+			stateStatus: StateStatusRemove,
+		}
 	case KindLabel:
 		s.mLabels[metaName] = &stateLabel{
 			id:          id,
 			parserLabel: &label{identity: newIdentity},
 			stateStatus: StateStatusRemove,
 		}
+	case KindNotebook:
+		s.mNotebooks[metaName] = &stateNotebook{
+			id:             id,
+			parserNotebook: &notebook{identity: newIdentity},
+			stateStatus:    StateStatusRemove,
+		}
 	case KindNotificationEndpoint,
 		KindNotificationEndpointHTTP,
 		KindNotificationEndpointPagerDuty,
@@ -617,6 +786,12 @@ func (s *stateCoordinator) addObjectForRemoval(k Kind, metaName string, id platf
 			parserRule:  &notificationRule{identity: newIdentity},
 			stateStatus: StateStatusRemove,
 		}
+	case KindScraper:
+		s.mScrapers[metaName] = &stateScraper{
+			id:            id,
+			parserScraper: &scraper{identity: newIdentity},
+			stateStatus:   StateStatusRemove,
+		}
 	case KindTask:
 		s.mTasks[metaName] = &stateTask{
 			id:          id,
@@ -629,6 +804,12 @@ func (s *stateCoordinator) addObjectForRemoval(k Kind, metaName string, id platf
 			parserTelegraf: &telegraf{identity: newIdentity},
 			stateStatus:    StateStatusRemove,
 		}
+	case KindV1Authorization:
+		s.mV1Authorizations[metaName] = &stateV1Authorization{
+			id:          id,
+			parserAuth:  &v1Authorization{identity: newIdentity},
+			stateStatus: StateStatusRemove,
+		}
 	case KindVariable:
 		s.mVariables[metaName] = &stateVariable{
 			id:          id,
@@ -646,7 +827,7 @@ func (s *stateCoordinator) getObjectIDSetter(k Kind, metaName string) (func(plat
 			r.id = id
 			r.stateStatus = StateStatusExists
 		}, ok
-	case KindCheck, KindCheckDeadman, KindCheckThreshold:
+	case KindCheck, KindCheckDeadman, KindCheckThreshold, KindCheckAnomaly:
 		r, ok := s.mChecks[metaName]
 		return func(id platform.ID) {
 			r.id = id
@@ -658,12 +839,24 @@ func (s *stateCoordinator) getObjectIDSetter(k Kind, metaName string) (func(plat
 			r.id = id
 			r.stateStatus = StateStatusExists
 		}, ok
+	case KindDBRPMapping:
+		r, ok := s.mDBRPMappings[metaName]
+		return func(id platform.ID) {
+			r.id = id
+			r.stateStatus = StateStatusExists
+		}, ok
 	case KindLabel:
 		r, ok := s.mLabels[metaName]
 		return func(id platform.ID) {
 			r.id = id
 			r.stateStatus = StateStatusExists
 		}, ok
+	case KindNotebook:
+		r, ok := s.mNotebooks[metaName]
+		return func(id platform.ID) {
+			r.id = id
+			r.stateStatus = StateStatusExists
+		}, ok
 	case KindNotificationEndpoint,
 		KindNotificationEndpointHTTP,
 		KindNotificationEndpointPagerDuty,
@@ -679,6 +872,12 @@ func (s *stateCoordinator) getObjectIDSetter(k Kind, metaName string) (func(plat
 			r.id = id
 			r.stateStatus = StateStatusExists
 		}, ok
+	case KindScraper:
+		r, ok := s.mScrapers[metaName]
+		return func(id platform.ID) {
+			r.id = id
+			r.stateStatus = StateStatusExists
+		}, ok
 	case KindTask:
 		r, ok := s.mTasks[metaName]
 		return func(id platform.ID) {
@@ -691,6 +890,12 @@ func (s *stateCoordinator) getObjectIDSetter(k Kind, metaName string) (func(plat
 			r.id = id
 			r.stateStatus = StateStatusExists
 		}, ok
+	case KindV1Authorization:
+		r, ok := s.mV1Authorizations[metaName]
+		return func(id platform.ID) {
+			r.id = id
+			r.stateStatus = StateStatusExists
+		}, ok
 	case KindVariable:
 		r, ok := s.mVariables[metaName]
 		return func(id platform.ID) {
@@ -1473,6 +1678,399 @@ func (t *stateTelegraf) summarize() SummaryTelegraf {
 	return sum
 }
 
+// stateBucketRef is the reference a resource holds to the bucket it depends on.
+// The bucket is either graphed from the template or already exists within the
+// platform.
+type stateBucketRef struct {
+	name     string
+	stateBkt *stateBucket
+	existing *influxdb.Bucket
+}
+
+func (r stateBucketRef) ID() platform.ID {
+	if r.stateBkt != nil {
+		return r.stateBkt.ID()
+	}
+	if r.existing != nil {
+		return r.existing.ID
+	}
+	return 0
+}
+
+func (r stateBucketRef) association() StackResourceAssociation {
+	if r.stateBkt == nil {
+		return StackResourceAssociation{}
+	}
+	return StackResourceAssociation{
+		Kind:     KindBucket,
+		MetaName: r.stateBkt.parserBkt.MetaName(),
+	}
+}
+
+func bucketRefsToStackAssociations(refs ...stateBucketRef) []StackResourceAssociation {
+	var out []StackResourceAssociation
+	seen := make(map[StackResourceAssociation]bool)
+	for _, r := range refs {
+		ass := r.association()
+		if ass.MetaName == "" || seen[ass] {
+			continue
+		}
+		seen[ass] = true
+		out = append(out, ass)
+	}
+	return out
+}
+
+type stateDBRPMapping struct {
+	id, orgID   platform.ID
+	stateStatus StateStatus
+
+	bucket stateBucketRef
+
+	parserDBRP *dbrpMapping
+	existing   *influxdb.DBRPMappingV2
+}
+
+func (d *stateDBRPMapping) ID() platform.ID {
+	if !IsNew(d.stateStatus) && d.existing != nil {
+		return d.existing.ID
+	}
+	return d.id
+}
+
+func (d *stateDBRPMapping) bucketAssociations() []StackResourceAssociation {
+	return bucketRefsToStackAssociations(d.bucket)
+}
+
+func (d *stateDBRPMapping) diffDBRPMapping() DiffDBRPMapping {
+	diff := DiffDBRPMapping{
+		DiffIdentifier: DiffIdentifier{
+			Kind:        KindDBRPMapping,
+			ID:          SafeID(d.ID()),
+			StateStatus: d.stateStatus,
+			MetaName:    d.parserDBRP.MetaName(),
+		},
+		New: DiffDBRPMappingValues{
+			Database:        d.parserDBRP.database,
+			RetentionPolicy: d.parserDBRP.retentionPolicy,
+			Default:         d.parserDBRP.isDefault,
+			BucketID:        SafeID(d.bucket.ID()),
+		},
+	}
+	if e := d.existing; e != nil {
+		diff.Old = &DiffDBRPMappingValues{
+			Database:        e.Database,
+			RetentionPolicy: e.RetentionPolicy,
+			Default:         e.Default,
+			BucketID:        SafeID(e.BucketID),
+		}
+	}
+	return diff
+}
+
+func (d *stateDBRPMapping) resourceType() influxdb.ResourceType {
+	return KindDBRPMapping.ResourceType()
+}
+
+func (d *stateDBRPMapping) shouldApply() bool {
+	return IsRemoval(d.stateStatus) ||
+		d.existing == nil ||
+		d.existing.Default != d.parserDBRP.isDefault ||
+		d.existing.BucketID != d.bucket.ID()
+}
+
+func (d *stateDBRPMapping) stateIdentity() stateIdentity {
+	return stateIdentity{
+		id:           d.ID(),
+		name:         d.parserDBRP.database + "/" + d.parserDBRP.retentionPolicy,
+		metaName:     d.parserDBRP.MetaName(),
+		resourceType: d.resourceType(),
+		stateStatus:  d.stateStatus,
+	}
+}
+
+func (d *stateDBRPMapping) summarize() SummaryDBRPMapping {
+	sum := d.parserDBRP.summarize()
+	sum.ID = SafeID(d.ID())
+	sum.OrgID = SafeID(d.orgID)
+	sum.BucketID = SafeID(d.bucket.ID())
+	return sum
+}
+
+func (d *stateDBRPMapping) toInfluxDBRP() influxdb.DBRPMappingV2 {
+	return influxdb.DBRPMappingV2{
+		ID:              d.ID(),
+		Database:        d.parserDBRP.database,
+		RetentionPolicy: d.parserDBRP.retentionPolicy,
+		Default:         d.parserDBRP.isDefault,
+		OrganizationID:  d.orgID,
+		BucketID:        d.bucket.ID(),
+	}
+}
+
+type stateNotebook struct {
+	id, orgID   platform.ID
+	stateStatus StateStatus
+
+	parserNotebook *notebook
+	existing       *nbsvc.Notebook
+}
+
+func (n *stateNotebook) ID() platform.ID {
+	if !IsNew(n.stateStatus) && n.existing != nil {
+		return n.existing.ID
+	}
+	return n.id
+}
+
+func (n *stateNotebook) diffNotebook() DiffNotebook {
+	diff := DiffNotebook{
+		DiffIdentifier: DiffIdentifier{
+			Kind:        KindNotebook,
+			ID:          SafeID(n.ID()),
+			StateStatus: n.stateStatus,
+			MetaName:    n.parserNotebook.MetaName(),
+		},
+		New: DiffNotebookValues{
+			Name:    n.parserNotebook.Name(),
+			Content: n.parserNotebook.content,
+		},
+	}
+	if e := n.existing; e != nil {
+		diff.Old = &DiffNotebookValues{
+			Name:    e.Name,
+			Content: e.Spec,
+		}
+	}
+	return diff
+}
+
+func (n *stateNotebook) resourceType() influxdb.ResourceType {
+	return KindNotebook.ResourceType()
+}
+
+func (n *stateNotebook) shouldApply() bool {
+	return IsRemoval(n.stateStatus) ||
+		n.existing == nil ||
+		n.existing.Name != n.parserNotebook.Name() ||
+		!reflect.DeepEqual(map[string]interface{}(n.existing.Spec), n.parserNotebook.content)
+}
+
+func (n *stateNotebook) stateIdentity() stateIdentity {
+	return stateIdentity{
+		id:           n.ID(),
+		name:         n.parserNotebook.Name(),
+		metaName:     n.parserNotebook.MetaName(),
+		resourceType: n.resourceType(),
+		stateStatus:  n.stateStatus,
+	}
+}
+
+func (n *stateNotebook) summarize() SummaryNotebook {
+	sum := n.parserNotebook.summarize()
+	sum.ID = SafeID(n.ID())
+	sum.OrgID = SafeID(n.orgID)
+	return sum
+}
+
+type stateScraper struct {
+	id, orgID   platform.ID
+	stateStatus StateStatus
+
+	bucket stateBucketRef
+
+	parserScraper *scraper
+	existing      *influxdb.ScraperTarget
+}
+
+func (s *stateScraper) ID() platform.ID {
+	if !IsNew(s.stateStatus) && s.existing != nil {
+		return s.existing.ID
+	}
+	return s.id
+}
+
+func (s *stateScraper) bucketAssociations() []StackResourceAssociation {
+	return bucketRefsToStackAssociations(s.bucket)
+}
+
+func (s *stateScraper) diffScraper() DiffScraper {
+	diff := DiffScraper{
+		DiffIdentifier: DiffIdentifier{
+			Kind:        KindScraper,
+			ID:          SafeID(s.ID()),
+			StateStatus: s.stateStatus,
+			MetaName:    s.parserScraper.MetaName(),
+		},
+		New: DiffScraperValues{
+			Name:          s.parserScraper.Name(),
+			Type:          s.parserScraper.Type(),
+			URL:           s.parserScraper.url,
+			BucketID:      SafeID(s.bucket.ID()),
+			AllowInsecure: s.parserScraper.allowInsecure,
+		},
+	}
+	if e := s.existing; e != nil {
+		diff.Old = &DiffScraperValues{
+			Name:          e.Name,
+			Type:          e.Type,
+			URL:           e.URL,
+			BucketID:      SafeID(e.BucketID),
+			AllowInsecure: e.AllowInsecure,
+		}
+	}
+	return diff
+}
+
+func (s *stateScraper) resourceType() influxdb.ResourceType {
+	return KindScraper.ResourceType()
+}
+
+func (s *stateScraper) shouldApply() bool {
+	return IsRemoval(s.stateStatus) ||
+		s.existing == nil ||
+		s.existing.Name != s.parserScraper.Name() ||
+		s.existing.URL != s.parserScraper.url ||
+		s.existing.Type != s.parserScraper.Type() ||
+		s.existing.AllowInsecure != s.parserScraper.allowInsecure ||
+		s.existing.BucketID != s.bucket.ID()
+}
+
+func (s *stateScraper) stateIdentity() stateIdentity {
+	return stateIdentity{
+		id:           s.ID(),
+		name:         s.parserScraper.Name(),
+		metaName:     s.parserScraper.MetaName(),
+		resourceType: s.resourceType(),
+		stateStatus:  s.stateStatus,
+	}
+}
+
+func (s *stateScraper) summarize() SummaryScraper {
+	sum := s.parserScraper.summarize()
+	sum.ID = SafeID(s.ID())
+	sum.OrgID = SafeID(s.orgID)
+	sum.BucketID = SafeID(s.bucket.ID())
+	return sum
+}
+
+func (s *stateScraper) toInfluxScraper() influxdb.ScraperTarget {
+	return influxdb.ScraperTarget{
+		ID:            s.ID(),
+		Name:          s.parserScraper.Name(),
+		Type:          s.parserScraper.Type(),
+		URL:           s.parserScraper.url,
+		OrgID:         s.orgID,
+		BucketID:      s.bucket.ID(),
+		AllowInsecure: s.parserScraper.allowInsecure,
+	}
+}
+
+type stateV1Authorization struct {
+	id, orgID   platform.ID
+	stateStatus StateStatus
+
+	// buckets are indexed as the permissions of the parser authorization.
+	buckets []stateBucketRef
+
+	parserAuth *v1Authorization
+	existing   *influxdb.Authorization
+}
+
+func (a *stateV1Authorization) ID() platform.ID {
+	if !IsNew(a.stateStatus) && a.existing != nil {
+		return a.existing.ID
+	}
+	return a.id
+}
+
+func (a *stateV1Authorization) bucketAssociations() []StackResourceAssociation {
+	return bucketRefsToStackAssociations(a.buckets...)
+}
+
+func (a *stateV1Authorization) diffV1Authorization() DiffV1Authorization {
+	diff := DiffV1Authorization{
+		DiffIdentifier: DiffIdentifier{
+			Kind:        KindV1Authorization,
+			ID:          SafeID(a.ID()),
+			StateStatus: a.stateStatus,
+			MetaName:    a.parserAuth.MetaName(),
+		},
+		New: DiffV1AuthorizationValues{
+			Token:       a.parserAuth.token,
+			Description: a.parserAuth.description,
+			Status:      a.parserAuth.Status(),
+			Permissions: a.summarize().Permissions,
+		},
+	}
+	if e := a.existing; e != nil {
+		diff.Old = &DiffV1AuthorizationValues{
+			Token:       e.Token,
+			Description: e.Description,
+			Status:      e.Status,
+			Permissions: influxToSummaryV1Permissions(e.Permissions),
+		}
+	}
+	return diff
+}
+
+func (a *stateV1Authorization) resourceType() influxdb.ResourceType {
+	return KindV1Authorization.ResourceType()
+}
+
+func (a *stateV1Authorization) shouldApply() bool {
+	return IsRemoval(a.stateStatus) ||
+		a.existing == nil ||
+		a.existing.Description != a.parserAuth.description ||
+		a.existing.Status != a.parserAuth.Status()
+}
+
+func (a *stateV1Authorization) stateIdentity() stateIdentity {
+	return stateIdentity{
+		id:           a.ID(),
+		name:         a.parserAuth.token,
+		metaName:     a.parserAuth.MetaName(),
+		resourceType: a.resourceType(),
+		stateStatus:  a.stateStatus,
+	}
+}
+
+func (a *stateV1Authorization) summarize() SummaryV1Authorization {
+	sum := a.parserAuth.summarize()
+	sum.ID = SafeID(a.ID())
+	sum.OrgID = SafeID(a.orgID)
+	for i := range sum.Permissions {
+		if i < len(a.buckets) {
+			sum.Permissions[i].BucketID = SafeID(a.buckets[i].ID())
+		}
+	}
+	return sum
+}
+
+func (a *stateV1Authorization) toInfluxPermissions() ([]influxdb.Permission, error) {
+	perms := make([]influxdb.Permission, 0, len(a.parserAuth.permissions))
+	for i, p := range a.parserAuth.permissions {
+		perm, err := influxdb.NewPermissionAtID(a.buckets[i].ID(), influxdb.Action(p.action), influxdb.BucketsResourceType, a.orgID)
+		if err != nil {
+			return nil, err
+		}
+		perms = append(perms, *perm)
+	}
+	return perms, nil
+}
+
+func influxToSummaryV1Permissions(perms []influxdb.Permission) []SummaryV1Permission {
+	out := make([]SummaryV1Permission, 0, len(perms))
+	for _, p := range perms {
+		sp := SummaryV1Permission{Action: p.Action}
+		if p.Resource.ID != nil {
+			sp.BucketID = SafeID(*p.Resource.ID)
+		}
+		out = append(out, sp)
+	}
+	return out
+}
+
 type stateVariable struct {
 	id, orgID         platform.ID
 	stateStatus       StateStatus
@@ -10,6 +10,7 @@ import (
 	"regexp"
 	"sort"
 	"strconv"
+	"sync"
 	"testing"
 	"time"
 
@@ -17,6 +18,7 @@ import (
 	"github.com/influxdata/influxdb/v2/kit/platform"
 	errors2 "github.com/influxdata/influxdb/v2/kit/platform/errors"
 	"github.com/influxdata/influxdb/v2/mock"
+	nbsvc "github.com/influxdata/influxdb/v2/notebooks/service"
 	"github.com/influxdata/influxdb/v2/notification"
 	icheck "github.com/influxdata/influxdb/v2/notification/check"
 	"github.com/influxdata/influxdb/v2/notification/endpoint"
@@ -33,10 +35,16 @@ func TestService(t *testing.T) {
 			bucketSVC:   mock.NewBucketService(),
 			checkSVC:    mock.NewCheckService(),
 			dashSVC:     mock.NewDashboardService(),
+			dbrpSVC:     &mock.DBRPMappingServiceV2{},
 			labelSVC:    mock.NewLabelService(),
 			endpointSVC: mock.NewNotificationEndpointService(),
 			orgSVC:      mock.NewOrganizationService(),
 			ruleSVC:     mock.NewNotificationRuleStore(),
+			scraperSVC: &mock.ScraperTargetStoreService{
+				ListTargetsF: func(ctx context.Context, filter influxdb.ScraperTargetFilter) ([]influxdb.ScraperTarget, error) {
+					return nil, nil
+				},
+			},
 			store: &fakeStore{
 				createFn: func(ctx context.Context, stack Stack) error {
 					return nil
@@ -51,9 +59,11 @@ func TestService(t *testing.T) {
 					return nil
 				},
 			},
-			taskSVC: mock.NewTaskService(),
-			teleSVC: mock.NewTelegrafConfigStore(),
-			varSVC:  mock.NewVariableService(),
+			taskSVC:       mock.NewTaskService(),
+			teleSVC:       mock.NewTelegrafConfigStore(),
+			v1AuthSVC:     mock.NewAuthorizationService(),
+			v1PasswordSVC: mock.NewPasswordsService(),
+			varSVC:        mock.NewVariableService(),
 		}
 		for _, o := range opts {
 			o(&opt)
@@ -64,13 +74,18 @@ func TestService(t *testing.T) {
 			WithBucketSVC(opt.bucketSVC),
 			WithCheckSVC(opt.checkSVC),
 			WithDashboardSVC(opt.dashSVC),
+			WithDBRPMappingSVC(opt.dbrpSVC),
 			WithLabelSVC(opt.labelSVC),
+			WithNotebookSVC(opt.notebookSVC),
 			WithNotificationEndpointSVC(opt.endpointSVC),
 			WithNotificationRuleSVC(opt.ruleSVC),
 			WithOrganizationService(opt.orgSVC),
+			WithScraperTargetSVC(opt.scraperSVC),
 			WithSecretSVC(opt.secretSVC),
 			WithTaskSVC(opt.taskSVC),
 			WithTelegrafSVC(opt.teleSVC),
+			WithV1AuthorizationSVC(opt.v1AuthSVC),
+			WithV1PasswordSVC(opt.v1PasswordSVC),
 			WithVariableSVC(opt.varSVC),
 		}
 		if opt.idGen != nil {
@@ -1661,6 +1676,248 @@ func TestService(t *testing.T) {
 			})
 		})
 
+		t.Run("dbrp mappings", func(t *testing.T) {
+			newFakeBktSVC := func() *mock.BucketService {
+				fakeBktSVC := mock.NewBucketService()
+				fakeBktSVC.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
+					b.ID = platform.ID(1)
+					return nil
+				}
+				fakeBktSVC.FindBucketByNameFn = func(_ context.Context, _ platform.ID, name string) (*influxdb.Bucket, error) {
+					if name != "existing-bucket" {
+						return nil, &errors2.Error{Code: errors2.ENotFound}
+					}
+					return &influxdb.Bucket{ID: platform.ID(2), Name: name}, nil
+				}
+				return fakeBktSVC
+			}
+
+			t.Run("successfully creates mappings referencing buckets", func(t *testing.T) {
+				testfileRunner(t, "testdata/dbrp.yml", func(t *testing.T, template *Template) {
+					fakeDBRPSVC := &mock.DBRPMappingServiceV2{}
+					var (
+						mu      sync.Mutex
+						created []influxdb.DBRPMappingV2
+					)
+					fakeDBRPSVC.CreateFn = func(_ context.Context, m *influxdb.DBRPMappingV2) error {
+						mu.Lock()
+						defer mu.Unlock()
+						m.ID = platform.ID(len(created) + 1)
+						created = append(created, *m)
+						return nil
+					}
+
+					svc := newTestService(WithBucketSVC(newFakeBktSVC()), WithDBRPMappingSVC(fakeDBRPSVC))
+
+					orgID := platform.ID(9000)
+
+					impact, err := svc.Apply(context.TODO(), orgID, 0, ApplyWithTemplate(template))
+					require.NoError(t, err)
+
+					require.Len(t, created, 2)
+					sort.Slice(created, func(i, j int) bool {
+						return created[i].RetentionPolicy < created[j].RetentionPolicy
+					})
+					assert.Equal(t, platform.ID(1), created[0].BucketID)
+					assert.True(t, created[0].Default)
+					assert.Equal(t, platform.ID(2), created[1].BucketID)
+					assert.Equal(t, orgID, created[1].OrganizationID)
+
+					sum := impact.Summary
+					require.Len(t, sum.DBRPMappings, 2)
+					assert.Equal(t, SafeID(1), sum.DBRPMappings[0].BucketID)
+					assert.Equal(t, SafeID(2), sum.DBRPMappings[1].BucketID)
+				})
+			})
+
+			t.Run("fails when the bucket dependency does not exist", func(t *testing.T) {
+				template := newParsedTemplate(t, FromString(`apiVersion: influxdata.com/v2alpha1
+kind: DBRPMapping
+metadata:
+  name: dbrp-1
+spec:
+  database: telegraf
+  retentionPolicy: autogen
+  bucket: not-a-bucket
+`), EncodingYAML)
+
+				svc := newTestService(WithBucketSVC(newFakeBktSVC()))
+
+				_, err := svc.DryRun(context.TODO(), platform.ID(9000), 0, ApplyWithTemplate(template))
+				require.Error(t, err)
+				assert.Equal(t, errors2.EUnprocessableEntity, errors2.ErrorCode(err))
+			})
+
+			t.Run("rolls back all created mappings on an error", func(t *testing.T) {
+				testfileRunner(t, "testdata/dbrp.yml", func(t *testing.T, template *Template) {
+					fakeDBRPSVC := &mock.DBRPMappingServiceV2{}
+					var (
+						mu          sync.Mutex
+						createCalls int
+						deleted     []platform.ID
+					)
+					fakeDBRPSVC.CreateFn = func(_ context.Context, m *influxdb.DBRPMappingV2) error {
+						mu.Lock()
+						defer mu.Unlock()
+						createCalls++
+						if createCalls == 2 {
+							return errors.New("limit hit")
+						}
+						m.ID = platform.ID(1)
+						return nil
+					}
+					fakeDBRPSVC.DeleteFn = func(_ context.Context, _, id platform.ID) error {
+						mu.Lock()
+						defer mu.Unlock()
+						deleted = append(deleted, id)
+						return nil
+					}
+
+					svc := newTestService(WithBucketSVC(newFakeBktSVC()), WithDBRPMappingSVC(fakeDBRPSVC))
+
+					_, err := svc.Apply(context.TODO(), platform.ID(9000), 0, ApplyWithTemplate(template))
+					require.Error(t, err)
+
+					assert.Equal(t, []platform.ID{1}, deleted)
+				})
+			})
+		})
+
+		t.Run("scrapers", func(t *testing.T) {
+			t.Run("successfully creates scrapers referencing buckets", func(t *testing.T) {
+				testfileRunner(t, "testdata/scraper.yml", func(t *testing.T, template *Template) {
+					fakeBktSVC := mock.NewBucketService()
+					fakeBktSVC.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
+						b.ID = platform.ID(1)
+						return nil
+					}
+					fakeBktSVC.FindBucketByNameFn = func(_ context.Context, _ platform.ID, name string) (*influxdb.Bucket, error) {
+						if name != "existing-bucket" {
+							return nil, &errors2.Error{Code: errors2.ENotFound}
+						}
+						return &influxdb.Bucket{ID: platform.ID(2), Name: name}, nil
+					}
+
+					var (
+						mu      sync.Mutex
+						created []influxdb.ScraperTarget
+					)
+					fakeScraperSVC := &mock.ScraperTargetStoreService{
+						ListTargetsF: func(ctx context.Context, filter influxdb.ScraperTargetFilter) ([]influxdb.ScraperTarget, error) {
+							return nil, nil
+						},
+						AddTargetF: func(_ context.Context, st *influxdb.ScraperTarget, _ platform.ID) error {
+							mu.Lock()
+							defer mu.Unlock()
+							st.ID = platform.ID(len(created) + 1)
+							created = append(created, *st)
+							return nil
+						},
+					}
+
+					svc := newTestService(WithBucketSVC(fakeBktSVC), WithScraperTargetSVC(fakeScraperSVC))
+
+					orgID := platform.ID(9000)
+
+					impact, err := svc.Apply(context.TODO(), orgID, 0, ApplyWithTemplate(template))
+					require.NoError(t, err)
+
+					require.Len(t, created, 2)
+					sort.Slice(created, func(i, j int) bool {
+						return created[i].Name < created[j].Name
+					})
+					assert.Equal(t, "local metrics", created[0].Name)
+					assert.Equal(t, influxdb.ScraperType(influxdb.PrometheusScraperType), created[0].Type)
+					assert.Equal(t, platform.ID(1), created[0].BucketID)
+					assert.Equal(t, orgID, created[0].OrgID)
+					assert.Equal(t, "scraper-2", created[1].Name)
+					assert.Equal(t, platform.ID(2), created[1].BucketID)
+					assert.True(t, created[1].AllowInsecure)
+
+					require.Len(t, impact.Summary.Scrapers, 2)
+				})
+			})
+		})
+
+		t.Run("v1 authorizations", func(t *testing.T) {
+			t.Run("successfully creates authorizations and sets passwords", func(t *testing.T) {
+				testfileRunner(t, "testdata/v1_authorization.yml", func(t *testing.T, template *Template) {
+					fakeBktSVC := mock.NewBucketService()
+					fakeBktSVC.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
+						b.ID = platform.ID(1)
+						return nil
+					}
+					fakeBktSVC.FindBucketByNameFn = func(_ context.Context, _ platform.ID, name string) (*influxdb.Bucket, error) {
+						if name != "existing-bucket" {
+							return nil, &errors2.Error{Code: errors2.ENotFound}
+						}
+						return &influxdb.Bucket{ID: platform.ID(2), Name: name}, nil
+					}
+
+					fakeSecretSVC := mock.NewSecretService()
+					fakeSecretSVC.GetSecretKeysFn = func(_ context.Context, _ platform.ID) ([]string, error) {
+						return []string{"legacy-user-password"}, nil
+					}
+					fakeSecretSVC.LoadSecretFn = func(_ context.Context, _ platform.ID, k string) (string, error) {
+						return "hunter2", nil
+					}
+
+					var mu sync.Mutex
+					created := make(map[string]influxdb.Authorization)
+					fakeAuthSVC := mock.NewAuthorizationService()
+					fakeAuthSVC.CreateAuthorizationFn = func(_ context.Context, a *influxdb.Authorization) error {
+						mu.Lock()
+						defer mu.Unlock()
+						a.ID = platform.ID(len(created) + 1)
+						created[a.Token] = *a
+						return nil
+					}
+
+					passwords := make(map[platform.ID]string)
+					fakePasswordSVC := mock.NewPasswordsService()
+					fakePasswordSVC.SetPasswordFn = func(_ context.Context, id platform.ID, password string) error {
+						mu.Lock()
+						defer mu.Unlock()
+						passwords[id] = password
+						return nil
+					}
+
+					svc := newTestService(
+						WithBucketSVC(fakeBktSVC),
+						WithSecretSVC(fakeSecretSVC),
+						WithV1AuthorizationSVC(fakeAuthSVC),
+						WithV1PasswordSVC(fakePasswordSVC),
+					)
+
+					orgID := platform.ID(9000)
+					userID := platform.ID(100)
+
+					impact, err := svc.Apply(context.TODO(), orgID, userID, ApplyWithTemplate(template))
+					require.NoError(t, err)
+
+					require.Len(t, created, 2)
+
+					writer := created["legacy-user"]
+					assert.Equal(t, orgID, writer.OrgID)
+					assert.Equal(t, userID, writer.UserID)
+					assert.Equal(t, influxdb.Active, writer.Status)
+					require.Len(t, writer.Permissions, 2)
+					assert.Equal(t, influxdb.ReadAction, writer.Permissions[0].Action)
+					assert.Equal(t, influxdb.WriteAction, writer.Permissions[1].Action)
+					assert.Equal(t, platform.ID(1), *writer.Permissions[0].Resource.ID)
+					assert.Equal(t, "hunter2", passwords[writer.ID])
+
+					reader := created["legacy-reader"]
+					assert.Equal(t, influxdb.Inactive, reader.Status)
+					require.Len(t, reader.Permissions, 1)
+					assert.Equal(t, platform.ID(2), *reader.Permissions[0].Resource.ID)
+					assert.NotContains(t, passwords, reader.ID)
+
+					require.Len(t, impact.Summary.V1Authorizations, 2)
+				})
+			})
+		})
+
 		t.Run("variables", func(t *testing.T) {
 			t.Run("successfully creates template of variables", func(t *testing.T) {
 				testfileRunner(t, "testdata/variables.yml", func(t *testing.T, template *Template) {
@@ -2056,6 +2313,19 @@ func TestService(t *testing.T) {
 							Level:      notification.Critical,
 						},
 					},
+					{
+						name: "anomaly",
+						expected: &icheck.Anomaly{
+							Base:     newThresholdBase(2),
+							Method:   icheck.AnomalyMAD,
+							Baseline: mustDuration(t, 7*24*time.Hour),
+							Period:   mustDuration(t, 90*time.Minute),
+							Thresholds: []icheck.AnomalyThreshold{
+								{Level: notification.Critical, Value: 5},
+								{Level: notification.Warn, Value: 3.5},
+							},
+						},
+					},
 				}
 
 				for _, tt := range tests {
@@ -4942,6 +5212,110 @@ func TestService(t *testing.T) {
 			require.Len(t, vars, 1)
 			assert.Equal(t, "variable", vars[0].Name)
 		})
+
+		t.Run("with org id pages through every notebook", func(t *testing.T) {
+			orgID := platform.ID(9000)
+
+			var notebooks []*nbsvc.Notebook
+			for i := 1; i <= 250; i++ {
+				notebooks = append(notebooks, &nbsvc.Notebook{
+					OrgID: orgID,
+					ID:    platform.ID(i),
+					Name:  fmt.Sprintf("notebook_%d", i),
+					Spec:  nbsvc.NotebookSpec{"pipes": []interface{}{}},
+				})
+			}
+			notebookSVC := &fakeNotebookSVC{
+				listFn: func(_ context.Context, f nbsvc.NotebookListFilter) ([]*nbsvc.Notebook, error) {
+					if f.OrgID != orgID {
+						return nil, errors.New("not suppose to get here")
+					}
+					lo, hi := f.Page.Offset, f.Page.Offset+f.Page.Limit
+					if lo > len(notebooks) {
+						lo = len(notebooks)
+					}
+					if hi > len(notebooks) {
+						hi = len(notebooks)
+					}
+					return notebooks[lo:hi], nil
+				},
+				getFn: func(_ context.Context, _, id platform.ID) (*nbsvc.Notebook, error) {
+					return notebooks[id-1], nil
+				},
+			}
+
+			svc := newTestService(WithNotebookSVC(notebookSVC))
+
+			template, err := svc.Export(
+				context.TODO(),
+				ExportWithAllOrgResources(ExportByOrgIDOpt{
+					OrgID:         orgID,
+					ResourceKinds: []Kind{KindNotebook},
+				}),
+			)
+			require.NoError(t, err)
+
+			assert.Equal(t, len(notebooks), len(template.Summary().Notebooks))
+		})
+
+		t.Run("scrapers exported by name are scoped to the organization", func(t *testing.T) {
+			orgID := platform.ID(9000)
+
+			bktSVC := mock.NewBucketService()
+			bktSVC.FindBucketByIDFn = func(_ context.Context, id platform.ID) (*influxdb.Bucket, error) {
+				return &influxdb.Bucket{ID: id, OrgID: orgID, Name: "bucket"}, nil
+			}
+			scraperSVC := &mock.ScraperTargetStoreService{
+				ListTargetsF: func(_ context.Context, f influxdb.ScraperTargetFilter) ([]influxdb.ScraperTarget, error) {
+					if f.OrgID == nil || *f.OrgID != orgID {
+						return nil, errors.New("scraper lookup is not scoped to the organization")
+					}
+					return []influxdb.ScraperTarget{{
+						ID:       3,
+						Name:     "scraper",
+						OrgID:    orgID,
+						BucketID: 1,
+						Type:     influxdb.PrometheusScraperType,
+						URL:      "http://localhost:8086/metrics",
+					}}, nil
+				},
+			}
+			stack := Stack{
+				ID:    1,
+				OrgID: orgID,
+				Events: []StackEvent{{
+					Resources: []StackResource{{
+						APIVersion: APIVersion,
+						Kind:       KindScraper,
+						MetaName:   "scraper",
+						Name:       "scraper",
+					}},
+				}},
+			}
+
+			svc := newTestService(
+				WithBucketSVC(bktSVC),
+				WithScraperTargetSVC(scraperSVC),
+				WithStore(&fakeStore{
+					readFn: func(ctx context.Context, id platform.ID) (Stack, error) {
+						return stack, nil
+					},
+				}),
+			)
+
+			template, err := svc.Export(context.TODO(), ExportWithStackID(stack.ID))
+			require.NoError(t, err)
+
+			scrapers := template.Summary().Scrapers
+			require.Len(t, scrapers, 1)
+			assert.Equal(t, "scraper", scrapers[0].Name)
+
+			_, err = svc.Export(context.TODO(), ExportWithExistingResources(ResourceToClone{
+				Kind: KindScraper,
+				Name: "scraper",
+			}))
+			require.Error(t, err)
+		})
 	})
 
 	t.Run("InitStack", func(t *testing.T) {
@@ -5358,3 +5732,18 @@ func parseURL(t *testing.T, rawAddr string) url.URL {
 	require.NoError(t, err)
 	return *u
 }
+
+type fakeNotebookSVC struct {
+	nbsvc.NotebookService
+
+	getFn  func(ctx context.Context, orgID, id platform.ID) (*nbsvc.Notebook, error)
+	listFn func(ctx context.Context, f nbsvc.NotebookListFilter) ([]*nbsvc.Notebook, error)
+}
+
+func (s *fakeNotebookSVC) GetNotebook(ctx context.Context, orgID, id platform.ID) (*nbsvc.Notebook, error) {
+	return s.getFn(ctx, orgID, id)
+}
+
+func (s *fakeNotebookSVC) ListNotebooks(ctx context.Context, f nbsvc.NotebookListFilter) ([]*nbsvc.Notebook, error) {
+	return s.listFn(ctx, f)
+}
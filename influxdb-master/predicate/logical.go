@@ -14,6 +14,7 @@ type LogicalOperator int
 // LogicalOperators
 var (
 	LogicalAnd LogicalOperator = 1
+	LogicalOr  LogicalOperator = 2
 )
 
 // Value returns the node logical type.
@@ -21,6 +22,8 @@ func (op LogicalOperator) Value() (datatypes.Node_Logical, error) {
 	switch op {
 	case LogicalAnd:
 		return datatypes.LogicalAnd, nil
+	case LogicalOr:
+		return datatypes.LogicalOr, nil
 	default:
 		return 0, &errors.Error{
 			Code: errors.EInvalid,
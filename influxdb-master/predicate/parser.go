@@ -2,6 +2,7 @@ package predicate
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/influxdata/influxdb/v2/kit/platform/errors"
@@ -18,9 +19,10 @@ type buffer [3]struct {
 }
 
 // parser of the predicate will convert
-// such a statement `(a = "a" or b!="b") and c ! =~/efg/`
+// such a statement `(a = "a" or b!="b") and c !~/efg/`
 // to the predicate node
 type parser struct {
+	src       string // the predicate statement
 	sc        *influxql.Scanner
 	i         int // buffer index
 	n         int // buffer size
@@ -28,6 +30,13 @@ type parser struct {
 	buf       buffer
 }
 
+func newParser(sts string) *parser {
+	return &parser{
+		src: sts,
+		sc:  influxql.NewScanner(strings.NewReader(sts)),
+	}
+}
+
 // scan returns the next token from the underlying scanner.
 // If a token has been unscanned then read that instead.
 func (p *parser) scan() (tok influxql.Token, pos influxql.Pos, lit string) {
@@ -64,94 +73,148 @@ func (p *parser) scanIgnoreWhitespace() (tok influxql.Token, pos influxql.Pos, l
 	return
 }
 
+// scanRegex scans the regular expression that follows the regex operator
+// at pos. The scanner only scans a regular expression from its current rune,
+// so the whitespace after the operator is scanned first.
+func (p *parser) scanRegex(pos influxql.Pos) (tok influxql.Token, _ influxql.Pos, lit string) {
+	switch p.runeAt(influxql.Pos{Line: pos.Line, Char: pos.Char + 2}) {
+	case ' ', '\t', '\n':
+		p.scan()
+	}
+	return p.sc.ScanRegex()
+}
+
+// runeAt returns the rune of the predicate statement at pos, or 0 at the end
+// of the statement.
+func (p *parser) runeAt(pos influxql.Pos) rune {
+	var line, char int
+	for _, r := range p.src {
+		if r == '\r' {
+			continue
+		}
+		if line == pos.Line && char == pos.Char {
+			return r
+		}
+		if r == '\n' {
+			line++
+			char = 0
+		} else {
+			char++
+		}
+	}
+	return 0
+}
+
 // Parse the predicate statement.
 func Parse(sts string) (n Node, err error) {
-	if sts == "" {
+	if strings.TrimSpace(sts) == "" {
 		return nil, nil
 	}
-	p := new(parser)
-	p.sc = influxql.NewScanner(strings.NewReader(sts))
-	return p.parseLogicalNode()
+	p := newParser(sts)
+	n, err = p.parseLogicalNode()
+	if err != nil {
+		return nil, err
+	}
+	switch tok, pos, _ := p.scanIgnoreWhitespace(); tok {
+	case influxql.EOF:
+		return n, nil
+	case influxql.RPAREN:
+		return nil, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "extra ) seen",
+		}
+	default:
+		return nil, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  fmt.Sprintf("bad logical expression, at position %d", pos.Char),
+		}
+	}
 }
 
+// parseLogicalNode parses expressions joined by OR, which binds less tightly
+// than AND.
 func (p *parser) parseLogicalNode() (Node, error) {
-	n := new(LogicalNode)
+	n, err := p.parseAndNode()
+	if err != nil {
+		return nil, err
+	}
 	for {
-		tok, pos, _ := p.scanIgnoreWhitespace()
-		switch tok {
-		case influxql.NUMBER, influxql.INTEGER, influxql.NAME, influxql.IDENT:
+		if tok, _, _ := p.scanIgnoreWhitespace(); tok != influxql.OR {
 			p.unscan()
-			tr, err := p.parseTagRuleNode()
-			if err != nil {
-				return *n, err
-			}
-			if n.Children[0] == nil {
-				n.Children[0] = tr
-			} else {
-				n.Children[1] = tr
-			}
-		case influxql.AND:
-			n.Operator = LogicalAnd
-			if n.Children[1] == nil {
-				continue
-			}
-			var n1 Node
-			var err error
-			if tokNext := p.peekTok(); tokNext == influxql.LPAREN {
-				n1, err = p.parseLogicalNode()
-			} else {
-				n1, err = p.parseTagRuleNode()
-			}
-			if err != nil {
-				return *n, err
-			}
-			n = &LogicalNode{
-				Children: [2]Node{*n, n1},
-				Operator: LogicalAnd,
-			}
-		case influxql.OR:
-			return *n, &errors.Error{
+			return n, nil
+		}
+		n1, err := p.parseAndNode()
+		if err != nil {
+			return nil, err
+		}
+		n = LogicalNode{
+			Children: [2]Node{n, n1},
+			Operator: LogicalOr,
+		}
+	}
+}
+
+// parseAndNode parses expressions joined by AND.
+func (p *parser) parseAndNode() (Node, error) {
+	n, err := p.parseOperandNode()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if tok, _, _ := p.scanIgnoreWhitespace(); tok != influxql.AND {
+			p.unscan()
+			return n, nil
+		}
+		n1, err := p.parseOperandNode()
+		if err != nil {
+			return nil, err
+		}
+		n = LogicalNode{
+			Children: [2]Node{n, n1},
+			Operator: LogicalAnd,
+		}
+	}
+}
+
+// parseOperandNode parses a tag rule or a parenthesized expression.
+func (p *parser) parseOperandNode() (Node, error) {
+	tok, pos, _ := p.scanIgnoreWhitespace()
+	switch tok {
+	case influxql.NUMBER, influxql.INTEGER, influxql.NAME, influxql.IDENT:
+		p.unscan()
+		return p.parseTagRuleNode()
+	case influxql.LPAREN:
+		p.openParen++
+		n, err := p.parseLogicalNode()
+		if err != nil {
+			return nil, err
+		}
+		if tok, pos, _ := p.scanIgnoreWhitespace(); tok == influxql.EOF {
+			return nil, &errors.Error{
 				Code: errors.EInvalid,
-				Msg:  fmt.Sprintf("the logical operator OR is not supported yet at position %d", pos.Char),
-			}
-		case influxql.LPAREN:
-			p.openParen++
-			currParen := p.openParen
-			n1, err := p.parseLogicalNode()
-			if err != nil {
-				return *n, err
-			}
-			if p.openParen != currParen-1 {
-				return *n, &errors.Error{
-					Code: errors.EInvalid,
-					Msg:  "extra ( seen",
-				}
-			}
-			if n.Children[0] == nil {
-				n.Children[0] = n1
-			} else {
-				n.Children[1] = n1
+				Msg:  "extra ( seen",
 			}
-		case influxql.RPAREN:
-			p.openParen--
-			fallthrough
-		case influxql.EOF:
-			if p.openParen < 0 {
-				return *n, &errors.Error{
-					Code: errors.EInvalid,
-					Msg:  "extra ) seen",
-				}
-			}
-			if n.Children[1] == nil {
-				return n.Children[0], nil
-			}
-			return *n, nil
-		default:
-			return *n, &errors.Error{
+		} else if tok != influxql.RPAREN {
+			return nil, &errors.Error{
 				Code: errors.EInvalid,
 				Msg:  fmt.Sprintf("bad logical expression, at position %d", pos.Char),
 			}
 		}
+		p.openParen--
+		return n, nil
+	case influxql.EOF:
+		if p.openParen > 0 {
+			return nil, &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "extra ( seen",
+			}
+		}
+		fallthrough
+	default:
+		return nil, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  fmt.Sprintf("bad logical expression, at position %d", pos.Char),
+		}
 	}
 }
 
@@ -179,13 +242,12 @@ func (p *parser) parseTagRuleNode() (TagRuleNode, error) {
 	case influxql.NEQ:
 		n.Operator = influxdb.NotEqual
 		goto scanRegularTagValue
-	case influxql.EQREGEX:
-		fallthrough
-	case influxql.NEQREGEX:
-		return *n, &errors.Error{
-			Code: errors.EInvalid,
-			Msg:  fmt.Sprintf("operator: %q at position: %d is not supported yet", tok.String(), pos.Char),
+	case influxql.EQREGEX, influxql.NEQREGEX:
+		n.Operator = influxdb.RegexEqual
+		if tok == influxql.NEQREGEX {
+			n.Operator = influxdb.NotRegexEqual
 		}
+		return p.parseRegexTagValue(n, pos)
 	default:
 		return *n, &errors.Error{
 			Code: errors.EInvalid,
@@ -222,12 +284,22 @@ scanRegularTagValue:
 	}
 }
 
-// peekRune returns the next rune that would be read by the scanner.
-func (p *parser) peekTok() influxql.Token {
-	tok, _, _ := p.scanIgnoreWhitespace()
-	if tok != influxql.EOF {
-		p.unscan()
+// parseRegexTagValue parses the regular expression matched by a tag rule
+// with the regex operator at pos.
+func (p *parser) parseRegexTagValue(n *TagRuleNode, pos influxql.Pos) (TagRuleNode, error) {
+	tok, _, lit := p.scanRegex(pos)
+	if tok != influxql.REGEX {
+		return *n, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  fmt.Sprintf("bad regex tag value, at position %d", pos.Char+2),
+		}
 	}
-
-	return tok
+	if _, err := regexp.Compile(lit); err != nil {
+		return *n, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  fmt.Sprintf("bad regex tag value /%s/, at position %d: %v", lit, pos.Char+2, err),
+		}
+	}
+	n.Value = lit
+	return *n, nil
 }
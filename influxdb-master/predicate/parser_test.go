@@ -1,7 +1,6 @@
 package predicate
 
 import (
-	"strings"
 	"testing"
 
 	"github.com/influxdata/influxdb/v2/kit/platform/errors"
@@ -9,7 +8,6 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/influxdata/influxdb/v2"
 	influxtesting "github.com/influxdata/influxdb/v2/testing"
-	"github.com/influxdata/influxql"
 )
 
 func TestParseNode(t *testing.T) {
@@ -41,9 +39,45 @@ func TestParseNode(t *testing.T) {
 		},
 		{
 			str: ` abc="opq" Or gender="male" OR temp=1123`,
+			node: LogicalNode{Operator: LogicalOr, Children: [2]Node{
+				LogicalNode{Operator: LogicalOr, Children: [2]Node{
+					TagRuleNode{Tag: influxdb.Tag{Key: "abc", Value: "opq"}},
+					TagRuleNode{Tag: influxdb.Tag{Key: "gender", Value: "male"}},
+				}},
+				TagRuleNode{Tag: influxdb.Tag{Key: "temp", Value: "1123"}},
+			}},
+		},
+		{
+			str: `a=1 or b=2 and c=3 or d=4`,
+			node: LogicalNode{Operator: LogicalOr, Children: [2]Node{
+				LogicalNode{Operator: LogicalOr, Children: [2]Node{
+					TagRuleNode{Tag: influxdb.Tag{Key: "a", Value: "1"}},
+					LogicalNode{Operator: LogicalAnd, Children: [2]Node{
+						TagRuleNode{Tag: influxdb.Tag{Key: "b", Value: "2"}},
+						TagRuleNode{Tag: influxdb.Tag{Key: "c", Value: "3"}},
+					}},
+				}},
+				TagRuleNode{Tag: influxdb.Tag{Key: "d", Value: "4"}},
+			}},
+		},
+		{
+			str: `_measurement="cpu" and (_field="temp" or _field =~ /^temp_/) and host!~/^test-/`,
+			node: LogicalNode{Operator: LogicalAnd, Children: [2]Node{
+				LogicalNode{Operator: LogicalAnd, Children: [2]Node{
+					TagRuleNode{Tag: influxdb.Tag{Key: "_measurement", Value: "cpu"}},
+					LogicalNode{Operator: LogicalOr, Children: [2]Node{
+						TagRuleNode{Tag: influxdb.Tag{Key: "_field", Value: "temp"}},
+						TagRuleNode{Tag: influxdb.Tag{Key: "_field", Value: "^temp_"}, Operator: influxdb.RegexEqual},
+					}},
+				}},
+				TagRuleNode{Tag: influxdb.Tag{Key: "host", Value: "^test-"}, Operator: influxdb.NotRegexEqual},
+			}},
+		},
+		{
+			str: `a=1 b=2`,
 			err: &errors.Error{
 				Code: errors.EInvalid,
-				Msg:  "the logical operator OR is not supported yet at position 11",
+				Msg:  "bad logical expression, at position 4",
 			},
 		},
 		{
@@ -134,17 +168,25 @@ func TestParseTagRule(t *testing.T) {
 			node: TagRuleNode{Tag: influxdb.Tag{Key: "abc", Value: "false"}, Operator: influxdb.Equal},
 		},
 		{
-			str: `abc!~/^payments\./`,
+			str:  `abc!~/^payments\./`,
+			node: TagRuleNode{Tag: influxdb.Tag{Key: "abc", Value: `^payments\.`}, Operator: influxdb.NotRegexEqual},
+		},
+		{
+			str:  `abc =~ /^pay\/ments/`,
+			node: TagRuleNode{Tag: influxdb.Tag{Key: "abc", Value: `^pay/ments`}, Operator: influxdb.RegexEqual},
+		},
+		{
+			str: `abc=~"payments"`,
 			err: &errors.Error{
 				Code: errors.EInvalid,
-				Msg:  `operator: "!~" at position: 3 is not supported yet`,
+				Msg:  `bad regex tag value, at position 5`,
 			},
 		},
 		{
-			str: `abc=~/^payments\./`,
+			str: `abc=~/(payments/`,
 			err: &errors.Error{
 				Code: errors.EInvalid,
-				Msg:  `operator: "=~" at position: 3 is not supported yet`,
+				Msg:  "bad regex tag value /(payments/, at position 5: error parsing regexp: missing closing ): `(payments`",
 			},
 		},
 		{
@@ -156,9 +198,7 @@ func TestParseTagRule(t *testing.T) {
 		},
 	}
 	for _, c := range cases {
-		p := new(parser)
-		p.sc = influxql.NewScanner(strings.NewReader(c.str))
-		tr, err := p.parseTagRuleNode()
+		tr, err := newParser(c.str).parseTagRuleNode()
 		influxtesting.ErrorsEqual(t, err, c.err)
 		if c.err == nil {
 			if diff := cmp.Diff(tr, c.node); diff != "" {
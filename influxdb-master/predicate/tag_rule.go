@@ -48,12 +48,9 @@ func NodeComparison(op influxdb.Operator) (datatypes.Node_Comparison, error) {
 	case influxdb.NotEqual:
 		return datatypes.ComparisonNotEqual, nil
 	case influxdb.RegexEqual:
-		fallthrough
+		return datatypes.ComparisonRegex, nil
 	case influxdb.NotRegexEqual:
-		return 0, &errors.Error{
-			Code: errors.EInvalid,
-			Msg:  fmt.Sprintf("Operator %s is not supported for delete predicate yet", op),
-		}
+		return datatypes.ComparisonNotRegex, nil
 	default:
 		return 0, &errors.Error{
 			Code: errors.EInvalid,
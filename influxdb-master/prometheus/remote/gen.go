@@ -0,0 +1,3 @@
+package remote
+
+//go:generate sh -c "protoc -I$(go list -f '{{ .Dir }}' -m github.com/gogo/protobuf) -I. --plugin ../../scripts/protoc-gen-gogofaster --gogofaster_out=. remote.proto"
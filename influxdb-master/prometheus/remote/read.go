@@ -0,0 +1,253 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/storage/reads"
+	"github.com/influxdata/influxdb/v2/storage/reads/datatypes"
+	"github.com/influxdata/influxdb/v2/tsdb/cursors"
+)
+
+// Reader answers remote read requests with the series of a bucket read from
+// a store.
+type Reader struct {
+	Store reads.Store
+}
+
+// NewReader returns a Reader reading series from store.
+func NewReader(store reads.Store) *Reader {
+	return &Reader{Store: store}
+}
+
+// Read answers each query of req with the series of the bucket matching it.
+func (r *Reader) Read(ctx context.Context, orgID, bucketID platform.ID, req *ReadRequest) (*ReadResponse, error) {
+	source, err := types.MarshalAny(r.Store.GetSource(uint64(orgID), uint64(bucketID)))
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &ReadResponse{Results: make([]*QueryResult, 0, len(req.Queries))}
+	for _, q := range req.Queries {
+		pred, err := PredicateFromMatchers(q.Matchers)
+		if err != nil {
+			return nil, err
+		}
+
+		rs, err := r.Store.ReadFilter(ctx, &datatypes.ReadFilterRequest{
+			ReadSource: source,
+			Range: datatypes.TimestampRange{
+				Start: nanos(q.StartTimestampMs),
+				End:   nanos(q.EndTimestampMs) + 1, // the end of a query is inclusive
+			},
+			Predicate: pred,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		res := &QueryResult{}
+		if rs != nil {
+			if res, err = QueryResultFromResultSet(rs); err != nil {
+				return nil, err
+			}
+		}
+		resp.Results = append(resp.Results, res)
+	}
+	return resp, nil
+}
+
+// nanos converts a timestamp in milliseconds to nanoseconds, limited to the
+// range of timestamps of points.
+func nanos(ms int64) int64 {
+	switch {
+	case ms <= models.MinNanoTime/int64(time.Millisecond):
+		return models.MinNanoTime
+	case ms >= models.MaxNanoTime/int64(time.Millisecond):
+		return models.MaxNanoTime
+	default:
+		return ms * int64(time.Millisecond)
+	}
+}
+
+// PredicateFromMatchers returns the predicate selecting the series of the
+// values of samples matching all matchers. As in Prometheus, regular
+// expressions must match the whole value of a label.
+func PredicateFromMatchers(matchers []*LabelMatcher) (*datatypes.Predicate, error) {
+	exprs := make([]*datatypes.Node, 0, len(matchers)+1)
+	for _, m := range matchers {
+		key := m.Name
+		if key == MetricNameLabel {
+			key = models.MeasurementTagKey
+		}
+
+		var (
+			op    datatypes.Node_Comparison
+			value *datatypes.Node
+		)
+		switch m.Type {
+		case LabelMatcher_EQ, LabelMatcher_NEQ:
+			op = datatypes.ComparisonEqual
+			if m.Type == LabelMatcher_NEQ {
+				op = datatypes.ComparisonNotEqual
+			}
+			value = &datatypes.Node{
+				NodeType: datatypes.NodeTypeLiteral,
+				Value:    &datatypes.Node_StringValue{StringValue: m.Value},
+			}
+		case LabelMatcher_RE, LabelMatcher_NRE:
+			op = datatypes.ComparisonRegex
+			if m.Type == LabelMatcher_NRE {
+				op = datatypes.ComparisonNotRegex
+			}
+			re := "^(?:" + m.Value + ")$"
+			if _, err := regexp.Compile(re); err != nil {
+				return nil, fmt.Errorf("invalid regular expression of label %q: %v", m.Name, err)
+			}
+			value = &datatypes.Node{
+				NodeType: datatypes.NodeTypeLiteral,
+				Value:    &datatypes.Node_RegexValue{RegexValue: re},
+			}
+		default:
+			return nil, fmt.Errorf("unknown type of matcher of label %q: %d", m.Name, m.Type)
+		}
+		exprs = append(exprs, comparison(op, key, value))
+	}
+
+	exprs = append(exprs, comparison(datatypes.ComparisonEqual, models.FieldKeyTagKey, &datatypes.Node{
+		NodeType: datatypes.NodeTypeLiteral,
+		Value:    &datatypes.Node_StringValue{StringValue: FieldKey},
+	}))
+
+	return &datatypes.Predicate{
+		Root: &datatypes.Node{
+			NodeType: datatypes.NodeTypeLogicalExpression,
+			Value:    &datatypes.Node_Logical_{Logical: datatypes.LogicalAnd},
+			Children: exprs,
+		},
+	}, nil
+}
+
+func comparison(op datatypes.Node_Comparison, key string, value *datatypes.Node) *datatypes.Node {
+	return &datatypes.Node{
+		NodeType: datatypes.NodeTypeComparisonExpression,
+		Value:    &datatypes.Node_Comparison_{Comparison: op},
+		Children: []*datatypes.Node{
+			{
+				NodeType: datatypes.NodeTypeTagRef,
+				Value:    &datatypes.Node_TagRefValue{TagRefValue: key},
+			},
+			value,
+		},
+	}
+}
+
+// QueryResultFromResultSet converts the series of rs to the series of a query
+// result, sorted by their labels. The measurement of a series is its metric
+// name and its tags are its other labels. Series with values that are not
+// numbers are skipped.
+func QueryResultFromResultSet(rs reads.ResultSet) (*QueryResult, error) {
+	defer rs.Close()
+
+	res := &QueryResult{}
+	for rs.Next() {
+		samples, err := samplesFromCursor(rs.Cursor())
+		if err != nil {
+			return nil, err
+		}
+		if len(samples) == 0 {
+			continue
+		}
+		res.Timeseries = append(res.Timeseries, &TimeSeries{
+			Labels:  labelsFromTags(rs.Tags()),
+			Samples: samples,
+		})
+	}
+	if err := rs.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(res.Timeseries, func(i, j int) bool {
+		return compareLabels(res.Timeseries[i].Labels, res.Timeseries[j].Labels) < 0
+	})
+	return res, nil
+}
+
+// labelsFromTags returns the labels, sorted by name, of a series with tags.
+func labelsFromTags(tags models.Tags) []Label {
+	labels := make([]Label, 0, len(tags))
+	for _, t := range tags {
+		switch {
+		case bytes.Equal(t.Key, models.MeasurementTagKeyBytes):
+			labels = append(labels, Label{Name: MetricNameLabel, Value: string(t.Value)})
+		case bytes.Equal(t.Key, models.FieldKeyTagKeyBytes):
+		default:
+			labels = append(labels, Label{Name: string(t.Key), Value: string(t.Value)})
+		}
+	}
+	sort.Slice(labels, func(i, j int) bool { return labels[i].Name < labels[j].Name })
+	return labels
+}
+
+func compareLabels(a, b []Label) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i].Name != b[i].Name {
+			if a[i].Name < b[i].Name {
+				return -1
+			}
+			return 1
+		}
+		if a[i].Value != b[i].Value {
+			if a[i].Value < b[i].Value {
+				return -1
+			}
+			return 1
+		}
+	}
+	return len(a) - len(b)
+}
+
+// samplesFromCursor reads the samples of the values of cur. It returns no
+// samples if the values are not numbers.
+func samplesFromCursor(cur cursors.Cursor) ([]Sample, error) {
+	if cur == nil {
+		return nil, nil
+	}
+	defer cur.Close()
+
+	var samples []Sample
+	switch cur := cur.(type) {
+	case cursors.FloatArrayCursor:
+		for a := cur.Next(); a.Len() > 0; a = cur.Next() {
+			for i := range a.Timestamps {
+				samples = append(samples, sample(a.Timestamps[i], a.Values[i]))
+			}
+		}
+	case cursors.IntegerArrayCursor:
+		for a := cur.Next(); a.Len() > 0; a = cur.Next() {
+			for i := range a.Timestamps {
+				samples = append(samples, sample(a.Timestamps[i], float64(a.Values[i])))
+			}
+		}
+	case cursors.UnsignedArrayCursor:
+		for a := cur.Next(); a.Len() > 0; a = cur.Next() {
+			for i := range a.Timestamps {
+				samples = append(samples, sample(a.Timestamps[i], float64(a.Values[i])))
+			}
+		}
+	default:
+		return nil, nil
+	}
+	return samples, cur.Err()
+}
+
+func sample(ns int64, v float64) Sample {
+	return Sample{Value: v, Timestamp: ns / int64(time.Millisecond)}
+}
@@ -0,0 +1,94 @@
+package remote_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/pkg/data/gen"
+	"github.com/influxdata/influxdb/v2/prometheus/remote"
+	"github.com/influxdata/influxdb/v2/storage/reads"
+	"github.com/influxdata/influxdb/v2/storage/reads/datatypes"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPredicateFromMatchers(t *testing.T) {
+	pred, err := remote.PredicateFromMatchers([]*remote.LabelMatcher{
+		{Type: remote.LabelMatcher_EQ, Name: "__name__", Value: "up"},
+		{Type: remote.LabelMatcher_NEQ, Name: "job", Value: "node"},
+		{Type: remote.LabelMatcher_RE, Name: "instance", Value: "host1|host2"},
+		{Type: remote.LabelMatcher_NRE, Name: "env", Value: "dev.*"},
+	})
+	require.NoError(t, err)
+	require.Equal(t,
+		"'\x00' = \"up\" AND 'job' != \"node\" AND 'instance' =~ /^(?:host1|host2)$/ AND 'env' !~ /^(?:dev.*)$/ AND '\xff' = \"value\"",
+		reads.PredicateToExprString(pred))
+
+	_, err = remote.PredicateFromMatchers([]*remote.LabelMatcher{
+		{Type: remote.LabelMatcher_RE, Name: "job", Value: "("},
+	})
+	require.Error(t, err)
+}
+
+type store struct {
+	reads.Store
+	rs  reads.ResultSet
+	req *datatypes.ReadFilterRequest
+}
+
+func (s *store) ReadFilter(_ context.Context, req *datatypes.ReadFilterRequest) (reads.ResultSet, error) {
+	s.req = req
+	return s.rs, nil
+}
+
+func (s *store) GetSource(orgID, bucketID uint64) proto.Message {
+	return &datatypes.Tag{Key: []byte("bucket"), Value: []byte(platform.ID(bucketID).String())}
+}
+
+func TestReader_Read(t *testing.T) {
+	spec, err := gen.NewSpecFromToml(`
+[[measurements]]
+name = "up"
+sample = 1.0
+tags = [
+	{ name = "instance", source = { type = "sequence", start = 0, count = 2 } },
+]
+fields = [
+	{ name = "value", count = 2, source = 1.0 },
+]`)
+	require.NoError(t, err)
+	sg := gen.NewSeriesGeneratorFromSpec(spec, gen.TimeRange{
+		Start: time.Unix(1000, 0),
+		End:   time.Unix(1030, 0),
+	})
+	s := &store{rs: mock.NewResultSetFromSeriesGenerator(sg)}
+
+	resp, err := remote.NewReader(s).Read(context.Background(), 1, 2, &remote.ReadRequest{
+		Queries: []*remote.Query{{
+			StartTimestampMs: 1000000,
+			EndTimestampMs:   1030000,
+			Matchers:         []*remote.LabelMatcher{{Type: remote.LabelMatcher_EQ, Name: "__name__", Value: "up"}},
+		}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, datatypes.TimestampRange{Start: 1000000000000, End: 1030000000001}, s.req.Range)
+
+	samples := []remote.Sample{{Value: 1, Timestamp: 1000000}, {Value: 1, Timestamp: 1015000}}
+	require.Equal(t, &remote.ReadResponse{
+		Results: []*remote.QueryResult{{
+			Timeseries: []*remote.TimeSeries{
+				{
+					Labels:  []remote.Label{{Name: "__name__", Value: "up"}, {Name: "instance", Value: "value0"}},
+					Samples: samples,
+				},
+				{
+					Labels:  []remote.Label{{Name: "__name__", Value: "up"}, {Name: "instance", Value: "value1"}},
+					Samples: samples,
+				},
+			},
+		}},
+	}, resp)
+}
@@ -0,0 +1,81 @@
+package remote
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/storage/reads/datatypes"
+)
+
+const (
+	// MetricNameLabel is the label holding the name of the metric of a series,
+	// which is the measurement of its points.
+	MetricNameLabel = "__name__"
+
+	// DefaultMeasurement is the measurement of the points of a series without
+	// a metric name.
+	DefaultMeasurement = "prom_metric_not_specified"
+
+	// FieldKey is the field of the points holding the values of the samples.
+	FieldKey = "value"
+)
+
+// PointsFromWriteRequest converts the samples of the series of a remote write
+// request to points. The metric name of a series is the measurement of its
+// points and its other labels are their tags.
+//
+// Samples with NaN or infinite values cannot be stored, and are dropped,
+// including the NaN markers Prometheus writes when series go stale. The
+// number of dropped samples is returned with the points.
+func PointsFromWriteRequest(req *WriteRequest) ([]models.Point, int, error) {
+	var (
+		points  []models.Point
+		dropped int
+	)
+	for _, ts := range req.Timeseries {
+		name, tags, err := seriesKey(ts.Labels)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		for _, s := range ts.Samples {
+			if math.IsNaN(s.Value) || math.IsInf(s.Value, 0) {
+				dropped++
+				continue
+			}
+
+			pt, err := models.NewPoint(name, tags, models.Fields{FieldKey: s.Value}, time.Unix(0, s.Timestamp*int64(time.Millisecond)))
+			if err != nil {
+				return nil, 0, err
+			}
+			points = append(points, pt)
+		}
+	}
+	return points, dropped, nil
+}
+
+// seriesKey returns the measurement and tags of the points of a series with
+// labels. Labels with empty values are equivalent to missing labels in
+// Prometheus, so they are not tags.
+func seriesKey(labels []Label) (string, models.Tags, error) {
+	name := DefaultMeasurement
+	tags := make(models.Tags, 0, len(labels))
+	for _, l := range labels {
+		switch {
+		case l.Name == MetricNameLabel:
+			if l.Value != "" {
+				name = l.Value
+			}
+		case l.Value == "":
+		case l.Name == datatypes.MeasurementKey || l.Name == datatypes.FieldKey:
+			return "", nil, fmt.Errorf("label %q is reserved", l.Name)
+		default:
+			tags = append(tags, models.NewTag([]byte(l.Name), []byte(l.Value)))
+		}
+	}
+	sort.Sort(tags)
+	return name, tags, nil
+}
@@ -0,0 +1,61 @@
+package remote_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2/prometheus/remote"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPointsFromWriteRequest(t *testing.T) {
+	req := &remote.WriteRequest{
+		Timeseries: []remote.TimeSeries{
+			{
+				Labels: []remote.Label{
+					{Name: "__name__", Value: "http_requests_total"},
+					{Name: "code", Value: "200"},
+					{Name: "handler", Value: "/api"},
+					{Name: "instance", Value: ""},
+				},
+				Samples: []remote.Sample{
+					{Value: 10, Timestamp: 1600000000000},
+					{Value: math.NaN(), Timestamp: 1600000015000},
+					{Value: 12.5, Timestamp: 1600000030000},
+				},
+			},
+			{
+				Labels:  []remote.Label{{Name: "job", Value: "node"}},
+				Samples: []remote.Sample{{Value: math.Inf(1), Timestamp: 1600000000000}, {Value: 1, Timestamp: 1600000000000}},
+			},
+		},
+	}
+
+	points, dropped, err := remote.PointsFromWriteRequest(req)
+	require.NoError(t, err)
+	require.Equal(t, 2, dropped)
+
+	lines := make([]string, 0, len(points))
+	for _, pt := range points {
+		lines = append(lines, pt.String())
+	}
+	require.Equal(t, []string{
+		"http_requests_total,code=200,handler=/api value=10 1600000000000000000",
+		"http_requests_total,code=200,handler=/api value=12.5 1600000030000000000",
+		"prom_metric_not_specified,job=node value=1 1600000000000000000",
+	}, lines)
+}
+
+func TestPointsFromWriteRequest_ReservedLabel(t *testing.T) {
+	req := &remote.WriteRequest{
+		Timeseries: []remote.TimeSeries{
+			{
+				Labels:  []remote.Label{{Name: "__name__", Value: "up"}, {Name: "_field", Value: "x"}},
+				Samples: []remote.Sample{{Value: 1, Timestamp: 1600000000000}},
+			},
+		},
+	}
+
+	_, _, err := remote.PointsFromWriteRequest(req)
+	require.EqualError(t, err, `label "_field" is reserved`)
+}
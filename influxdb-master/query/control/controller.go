@@ -716,7 +716,9 @@ func (q *Query) Done() {
 		}
 
 		// Count query request.
-		if q.err != nil || len(q.runtimeErrs) > 0 {
+		if q.memoryLimitExceeded() {
+			q.c.countQueryRequest(q, labelMemoryError)
+		} else if q.err != nil || len(q.runtimeErrs) > 0 {
 			q.c.countQueryRequest(q, labelRuntimeError)
 		} else {
 			q.c.countQueryRequest(q, labelSuccess)
@@ -893,6 +895,23 @@ func (q *Query) addRuntimeError(e error) {
 	q.runtimeErrs = append(q.runtimeErrs, e)
 }
 
+// memoryLimitExceeded reports whether the query was aborted because
+// it exceeded its memory limit.
+func (q *Query) memoryLimitExceeded() bool {
+	q.stateMu.RLock()
+	defer q.stateMu.RUnlock()
+
+	if isMemoryLimitError(q.err) {
+		return true
+	}
+	for _, e := range q.runtimeErrs {
+		if isMemoryLimitError(e) {
+			return true
+		}
+	}
+	return false
+}
+
 // pump will read from the executing query results and pump the
 // results to our destination.
 // When there are no more results, then this will close our own
@@ -1104,6 +1123,9 @@ func handleFluxError(err error) error {
 		// Everything else is treated as an internal error
 		// which is set above.
 	}
+	if _, ok := werr.(memory.LimitExceededError); ok {
+		return memoryLimitError(werr)
+	}
 	return &errors2.Error{
 		Code: code,
 		Msg:  ferr.Msg,
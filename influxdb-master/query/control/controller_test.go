@@ -456,6 +456,8 @@ func TestController_LimitExceededError(t *testing.T) {
 	}
 	defer shutdown(t, ctrl)
 
+	reg := setupPromRegistry(ctrl)
+
 	compiler := &mock.Compiler{
 		CompileFn: func(ctx context.Context) (flux.Program, error) {
 			// Return a program that will allocate one more byte than is allowed.
@@ -506,7 +508,7 @@ func TestController_LimitExceededError(t *testing.T) {
 		t.Fatal("expected an error")
 	}
 
-	if !strings.Contains(err.Error(), "memory") {
+	if !strings.Contains(err.Error(), "query exceeded its memory limit") {
 		t.Fatalf("expected an error about memory limit exceeded, got %v", err)
 	}
 
@@ -518,6 +520,16 @@ func TestController_LimitExceededError(t *testing.T) {
 	if !strings.Contains(stats.RuntimeErrors[0], "memory") {
 		t.Fatalf("expected an error about memory limit exceeded, got %v", err)
 	}
+
+	validateRequestTotals(t, reg, 0, 0, 0, 0)
+	metrics, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := FindMetric(metrics, "qc_requests_total", map[string]string{"result": "memory_error", "org": ""})
+	if m == nil || int(*m.Counter.Value) != 1 {
+		t.Fatal("expected one request counted as a memory error")
+	}
 }
 
 func TestController_CompilePanic(t *testing.T) {
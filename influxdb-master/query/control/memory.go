@@ -6,6 +6,7 @@ import (
 	"sync/atomic"
 
 	"github.com/influxdata/flux/memory"
+	errors2 "github.com/influxdata/influxdb/v2/kit/platform/errors"
 )
 
 type memoryManager struct {
@@ -155,3 +156,29 @@ func (q *queryMemoryManager) Release() {
 	q.limit = q.m.initialBytesQuotaPerQuery
 	q.given = 0
 }
+
+// isMemoryLimitError reports whether err is caused by the allocator of
+// a query exceeding its memory limit.
+func isMemoryLimitError(err error) bool {
+	for err != nil {
+		switch e := err.(type) {
+		case memory.LimitExceededError:
+			return true
+		case *errors2.Error:
+			err = e.Err
+		default:
+			err = errors.Unwrap(err)
+		}
+	}
+	return false
+}
+
+// memoryLimitError wraps the error of a query that exceeded its memory
+// limit with a message that explains how to avoid it.
+func memoryLimitError(err error) error {
+	return &errors2.Error{
+		Code: errors2.EInvalid,
+		Msg:  "query exceeded its memory limit and was aborted; reduce the time range or the number of series it reads, or raise query-memory-bytes",
+		Err:  err,
+	}
+}
@@ -26,6 +26,7 @@ const (
 	labelCompileError = requestsLabel("compile_error")
 	labelRuntimeError = requestsLabel("runtime_error")
 	labelQueueError   = requestsLabel("queue_error")
+	labelMemoryError  = requestsLabel("memory_error")
 )
 
 func newControllerMetrics(labels []string) *controllerMetrics {
@@ -2,8 +2,16 @@ package influxdb
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/pkg/jsonpath"
 )
 
 // ErrScraperTargetNotFound is the error msg for a missing scraper target.
@@ -27,6 +35,235 @@ type ScraperTarget struct {
 	OrgID         platform.ID `json:"orgID,omitempty"`
 	BucketID      platform.ID `json:"bucketID,omitempty"`
 	AllowInsecure bool        `json:"allowInsecure,omitempty"`
+
+	// Interval is the time between scrapes of the target, or the
+	// interval of the scheduler if zero.
+	Interval Duration `json:"interval"`
+	// Timeout is the maximum duration of a scrape, or
+	// DefaultScraperTimeout if zero.
+	Timeout Duration `json:"timeout"`
+	// Headers are added to the requests of the target, such as an
+	// Authorization header.
+	Headers map[string]string `json:"headers,omitempty"`
+	// TLS configures the client certificate and the certificate authority
+	// of a target served over HTTPS.
+	TLS *ScraperTLSConfig `json:"tls,omitempty"`
+	// JSON configures how metrics are extracted from the response of a
+	// target of JSONScraperType.
+	JSON *ScraperJSONConfig `json:"json,omitempty"`
+	// WriteHealth writes the health of each scrape of the target to the
+	// ScraperHealthMeasurement of its bucket.
+	WriteHealth bool `json:"writeHealth,omitempty"`
+}
+
+// ScraperHealthMeasurement is the measurement the health of the scrapes of
+// a target is written to, tagged with the name and id of the target, with
+// the up, duration_seconds, samples and error fields.
+const ScraperHealthMeasurement = "scrape_health"
+
+// Scraper health statuses
+const (
+	ScraperHealthUnknown = "unknown"
+	ScraperHealthUp      = "up"
+	ScraperHealthDown    = "down"
+)
+
+// ScraperTargetHealth is the health of the last scrape of a target.
+type ScraperTargetHealth struct {
+	// Status is up if the last scrape succeeded, down if it failed, or
+	// unknown if the target was not scraped yet.
+	Status             string     `json:"status"`
+	LastScrape         *time.Time `json:"lastScrape,omitempty"`
+	LastScrapeDuration Duration   `json:"lastScrapeDuration"`
+	// Samples is the number of samples of the last scrape.
+	Samples     int        `json:"samples"`
+	LastError   string     `json:"lastError,omitempty"`
+	LastSuccess *time.Time `json:"lastSuccess,omitempty"`
+}
+
+// ScraperHealthService returns the health of the scrapes of targets.
+type ScraperHealthService interface {
+	ScraperTargetHealth(ctx context.Context, id platform.ID) (*ScraperTargetHealth, error)
+}
+
+// DefaultScraperTimeout is the timeout of a scrape of a target without one.
+const DefaultScraperTimeout = 10 * time.Second
+
+// ScraperTLSConfig is the TLS configuration of a scraper target.
+type ScraperTLSConfig struct {
+	// CACert is the PEM encoded certificate authority to verify the target
+	// with, instead of the certificate authorities of the host.
+	CACert string `json:"caCert,omitempty"`
+	// Cert and Key are the PEM encoded client certificate and key to
+	// authenticate to the target with.
+	Cert string `json:"cert,omitempty"`
+	Key  string `json:"key,omitempty"`
+	// ServerName overrides the host name the certificate of the target is
+	// verified against.
+	ServerName string `json:"serverName,omitempty"`
+}
+
+// ScraperJSONConfig maps the JSON response of a scraper target to metrics.
+// Selectors are JSONPath-like paths, as of package pkg/jsonpath, which start
+// at the document root ($) or at the object of the metric (@).
+type ScraperJSONConfig struct {
+	// Measurement is the measurement of the metrics, or the name of the
+	// target if empty.
+	Measurement string `json:"measurement,omitempty"`
+	// Path selects the objects that each become a metric, or the document
+	// root if empty.
+	Path string `json:"path,omitempty"`
+	// Tags maps tag keys to the selectors of their values.
+	Tags map[string]string `json:"tags,omitempty"`
+	// Fields maps field keys to the selectors of their values.
+	Fields map[string]string `json:"fields"`
+	// Time selects the timestamp of the metrics, which are timestamped with
+	// the time of the scrape if empty.
+	Time string `json:"time,omitempty"`
+	// TimeFormat is the format of the timestamp, which is either one of the
+	// ScraperTimeFormat constants or a Go time layout. It defaults to
+	// ScraperTimeFormatRFC3339.
+	TimeFormat string `json:"timeFormat,omitempty"`
+}
+
+// Scraper time formats
+const (
+	ScraperTimeFormatRFC3339 = "rfc3339"
+	ScraperTimeFormatUnix    = "unix"
+	ScraperTimeFormatUnixMs  = "unix_ms"
+	ScraperTimeFormatUnixUs  = "unix_us"
+	ScraperTimeFormatUnixNs  = "unix_ns"
+)
+
+// Valid returns an error if the options of the target are invalid.
+func (t *ScraperTarget) Valid() error {
+	if t.Type != "" && !ValidScraperType(string(t.Type)) {
+		return invalidScraperTarget("unknown scraper type %q", t.Type)
+	}
+	if t.Interval.Duration < 0 {
+		return invalidScraperTarget("interval must not be negative")
+	}
+	if t.Timeout.Duration < 0 {
+		return invalidScraperTarget("timeout must not be negative")
+	}
+	for k := range t.Headers {
+		if k == "" || strings.ContainsAny(k, " :\r\n") {
+			return invalidScraperTarget("invalid header %q", k)
+		}
+	}
+
+	if t.TLS != nil {
+		if t.TLS.CACert != "" && !x509.NewCertPool().AppendCertsFromPEM([]byte(t.TLS.CACert)) {
+			return invalidScraperTarget("invalid tls ca certificate")
+		}
+		if t.TLS.Cert != "" || t.TLS.Key != "" {
+			if _, err := tls.X509KeyPair([]byte(t.TLS.Cert), []byte(t.TLS.Key)); err != nil {
+				return invalidScraperTarget("invalid tls certificate: %v", err)
+			}
+		}
+		if u, err := url.Parse(t.URL); err == nil && u.Scheme == "http" {
+			return invalidScraperTarget("tls requires an https url")
+		}
+	}
+
+	if t.Type == JSONScraperType {
+		if t.JSON == nil {
+			return invalidScraperTarget("json scraper requires a json config")
+		}
+		if err := t.JSON.Valid(); err != nil {
+			return err
+		}
+	} else if t.JSON != nil {
+		return invalidScraperTarget("json config requires the json scraper type")
+	}
+	return nil
+}
+
+// ScraperRedactedValue replaces the values of the headers and the TLS key of
+// a target returned by the API, which may hold credentials.
+const ScraperRedactedValue = "<redacted>"
+
+// Redacted returns a copy of the target with the values of its headers and
+// its TLS key replaced by ScraperRedactedValue.
+func (t ScraperTarget) Redacted() ScraperTarget {
+	if len(t.Headers) > 0 {
+		headers := make(map[string]string, len(t.Headers))
+		for k := range t.Headers {
+			headers[k] = ScraperRedactedValue
+		}
+		t.Headers = headers
+	}
+	if t.TLS != nil && t.TLS.Key != "" {
+		tlsConfig := *t.TLS
+		tlsConfig.Key = ScraperRedactedValue
+		t.TLS = &tlsConfig
+	}
+	return t
+}
+
+// RestoreRedacted replaces the header values and the TLS key of an update
+// that are still ScraperRedactedValue by those of the target prev, so that a
+// target returned by the API can be sent back without losing its
+// credentials.
+func (t *ScraperTarget) RestoreRedacted(prev *ScraperTarget) {
+	for k, v := range t.Headers {
+		if v != ScraperRedactedValue {
+			continue
+		}
+		if pv, ok := prev.Headers[k]; ok {
+			t.Headers[k] = pv
+		}
+	}
+	if t.TLS != nil && t.TLS.Key == ScraperRedactedValue && prev.TLS != nil {
+		t.TLS.Key = prev.TLS.Key
+	}
+}
+
+// Valid returns an error if the selectors of the config are invalid.
+func (c *ScraperJSONConfig) Valid() error {
+	if c.Path != "" {
+		if _, err := jsonpath.Parse(c.Path); err != nil {
+			return invalidScraperTarget("invalid json path: %v", err)
+		}
+	}
+	if len(c.Fields) == 0 {
+		return invalidScraperTarget("json config requires at least one field")
+	}
+
+	single := func(kind, key, sel string) error {
+		p, err := jsonpath.Parse(sel)
+		if err != nil {
+			return invalidScraperTarget("invalid selector of %s %q: %v", kind, key, err)
+		} else if p.Wildcard() {
+			return invalidScraperTarget("selector of %s %q must select a single value", kind, key)
+		}
+		return nil
+	}
+	for k, sel := range c.Tags {
+		if err := single("tag", k, sel); err != nil {
+			return err
+		}
+	}
+	for k, sel := range c.Fields {
+		if err := single("field", k, sel); err != nil {
+			return err
+		}
+	}
+	if c.Time != "" {
+		if err := single("time", "time", c.Time); err != nil {
+			return err
+		}
+	} else if c.TimeFormat != "" {
+		return invalidScraperTarget("time format requires a time selector")
+	}
+	return nil
+}
+
+func invalidScraperTarget(format string, args ...interface{}) error {
+	return &errors.Error{
+		Code: errors.EInvalid,
+		Msg:  fmt.Sprintf("invalid scraper target: "+format, args...),
+	}
 }
 
 // ScraperTargetStoreService defines the crud service for ScraperTarget.
@@ -53,12 +290,17 @@ type ScraperType string
 const (
 	// PrometheusScraperType parses metrics from a prometheus endpoint.
 	PrometheusScraperType = "prometheus"
+	// OpenMetricsScraperType parses metrics from an OpenMetrics endpoint.
+	OpenMetricsScraperType = "openmetrics"
+	// JSONScraperType extracts metrics from the JSON response of an HTTP
+	// endpoint.
+	JSONScraperType = "json"
 )
 
 // ValidScraperType returns true is the type string is valid
 func ValidScraperType(s string) bool {
 	switch s {
-	case PrometheusScraperType:
+	case PrometheusScraperType, OpenMetricsScraperType, JSONScraperType:
 		return true
 	default:
 		return false
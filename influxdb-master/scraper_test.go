@@ -0,0 +1,62 @@
+package influxdb_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/v2"
+)
+
+func TestScraperTarget_Redacted(t *testing.T) {
+	target := influxdb.ScraperTarget{
+		Name:    "target",
+		Headers: map[string]string{"Authorization": "Bearer secret", "X-Scope": "scope"},
+		TLS:     &influxdb.ScraperTLSConfig{Cert: "cert", Key: "key"},
+	}
+
+	got := target.Redacted()
+	want := influxdb.ScraperTarget{
+		Name: "target",
+		Headers: map[string]string{
+			"Authorization": influxdb.ScraperRedactedValue,
+			"X-Scope":       influxdb.ScraperRedactedValue,
+		},
+		TLS: &influxdb.ScraperTLSConfig{Cert: "cert", Key: influxdb.ScraperRedactedValue},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("unexpected redacted target -want/+got:\n%s", diff)
+	}
+
+	// the target itself keeps its credentials
+	if target.Headers["Authorization"] != "Bearer secret" || target.TLS.Key != "key" {
+		t.Fatalf("Redacted modified the target: %+v", target)
+	}
+
+	if got := (influxdb.ScraperTarget{TLS: &influxdb.ScraperTLSConfig{Cert: "cert"}}).Redacted(); got.TLS.Key != "" {
+		t.Fatalf("expected an empty key to stay empty, got %q", got.TLS.Key)
+	}
+}
+
+func TestScraperTarget_RestoreRedacted(t *testing.T) {
+	prev := &influxdb.ScraperTarget{
+		Headers: map[string]string{"Authorization": "Bearer secret", "X-Scope": "scope"},
+		TLS:     &influxdb.ScraperTLSConfig{Cert: "cert", Key: "key"},
+	}
+
+	update := prev.Redacted()
+	update.Headers["X-Scope"] = "other"
+	update.Headers["X-New"] = influxdb.ScraperRedactedValue
+	update.RestoreRedacted(prev)
+
+	want := influxdb.ScraperTarget{
+		Headers: map[string]string{
+			"Authorization": "Bearer secret",
+			"X-Scope":       "other",
+			"X-New":         influxdb.ScraperRedactedValue,
+		},
+		TLS: &influxdb.ScraperTLSConfig{Cert: "cert", Key: "key"},
+	}
+	if diff := cmp.Diff(want, update); diff != "" {
+		t.Fatalf("unexpected restored target -want/+got:\n%s", diff)
+	}
+}
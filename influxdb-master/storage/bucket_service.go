@@ -15,6 +15,7 @@ type EngineSchema interface {
 	CreateBucket(context.Context, *influxdb.Bucket) error
 	UpdateBucketRetentionPolicy(context.Context, platform.ID, *influxdb.BucketUpdate) error
 	DeleteBucket(context.Context, platform.ID, platform.ID) error
+	SetBucketCardinalityLimits(context.Context, *influxdb.Bucket) error
 }
 
 // BucketService wraps an existing influxdb.BucketService implementation.
@@ -76,7 +77,45 @@ func (s *BucketService) UpdateBucket(ctx context.Context, id platform.ID, upd in
 		return nil, err
 	}
 
-	return s.BucketService.UpdateBucket(ctx, id, upd)
+	if b, err = s.BucketService.UpdateBucket(ctx, id, upd); err != nil {
+		return nil, err
+	}
+
+	if upd.MaxSeries != nil || upd.MaxValuesPerTag != nil {
+		if err = s.engine.SetBucketCardinalityLimits(ctx, b); err != nil {
+			return nil, err
+		}
+	}
+
+	return b, nil
+}
+
+// LoadCardinalityLimits sets the cardinality limits of all buckets in the
+// engine. It is called when the engine is opened, as the engine does not
+// persist the limits.
+func (s *BucketService) LoadCardinalityLimits(ctx context.Context) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	opts := influxdb.FindOptions{Limit: influxdb.MaxPageSize}
+	for {
+		buckets, _, err := s.BucketService.FindBuckets(ctx, influxdb.BucketFilter{}, opts)
+		if err != nil {
+			return err
+		}
+		for _, b := range buckets {
+			if b.MaxSeries == 0 && b.MaxValuesPerTag == 0 {
+				continue
+			}
+			if err := s.engine.SetBucketCardinalityLimits(ctx, b); err != nil {
+				return err
+			}
+		}
+		if len(buckets) < opts.Limit {
+			return nil
+		}
+		opts.Offset += len(buckets)
+	}
 }
 
 // DeleteBucket removes a bucket by ID.
@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// cardinalityByBucket returns the cardinality limits and usage of every
+// bucket.
+func (e *Engine) cardinalityByBucket() (map[string]tsdb.CardinalityLimits, map[string]tsdb.CardinalityUsage, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return nil, nil, ErrEngineClosed
+	}
+
+	limits := make(map[string]tsdb.CardinalityLimits)
+	usage := make(map[string]tsdb.CardinalityUsage)
+	for _, db := range e.tsdbStore.Databases() {
+		limits[db] = e.tsdbStore.CardinalityLimits(db)
+		usage[db] = e.tsdbStore.CardinalityUsage(db)
+	}
+	return limits, usage, nil
+}
+
+// cardinalityCollector exports the cardinality of the series of every bucket
+// with its limits.
+type cardinalityCollector struct {
+	engine *Engine
+
+	series          *prometheus.Desc
+	maxSeries       *prometheus.Desc
+	valuesPerTag    *prometheus.Desc
+	maxValuesPerTag *prometheus.Desc
+}
+
+func newCardinalityCollector(e *Engine) *cardinalityCollector {
+	labels := []string{"bucket"}
+	return &cardinalityCollector{
+		engine: e,
+		series: prometheus.NewDesc(
+			"storage_bucket_series",
+			"Number of series of a bucket",
+			labels, e.defaultMetricLabels,
+		),
+		maxSeries: prometheus.NewDesc(
+			"storage_bucket_max_series",
+			"Limit on the number of series of a bucket, 0 if unlimited",
+			labels, e.defaultMetricLabels,
+		),
+		valuesPerTag: prometheus.NewDesc(
+			"storage_bucket_values_per_tag",
+			"Largest number of values of a tag key of a measurement in a shard of a bucket, among the tag keys counted to enforce the limit",
+			labels, e.defaultMetricLabels,
+		),
+		maxValuesPerTag: prometheus.NewDesc(
+			"storage_bucket_max_values_per_tag",
+			"Limit on the number of values of a tag key of a measurement in a shard of a bucket, 0 if unlimited",
+			labels, e.defaultMetricLabels,
+		),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *cardinalityCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.series
+	ch <- c.maxSeries
+	ch <- c.valuesPerTag
+	ch <- c.maxValuesPerTag
+}
+
+// Collect implements prometheus.Collector.
+func (c *cardinalityCollector) Collect(ch chan<- prometheus.Metric) {
+	limits, usage, err := c.engine.cardinalityByBucket()
+	if err != nil {
+		return
+	}
+
+	for bucket, u := range usage {
+		l := limits[bucket]
+		ch <- prometheus.MustNewConstMetric(c.series, prometheus.GaugeValue, float64(u.SeriesN), bucket)
+		ch <- prometheus.MustNewConstMetric(c.maxSeries, prometheus.GaugeValue, float64(l.MaxSeries), bucket)
+		ch <- prometheus.MustNewConstMetric(c.valuesPerTag, prometheus.GaugeValue, float64(u.MaxValuesPerTag), bucket)
+		ch <- prometheus.MustNewConstMetric(c.maxValuesPerTag, prometheus.GaugeValue, float64(l.MaxValuesPerTag), bucket)
+	}
+}
@@ -0,0 +1,205 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	errors2 "github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var _ influxdb.CompactionService = (*Engine)(nil)
+
+// compactionLevels are the names of the compaction levels of tsdb.CompactionState.
+var compactionLevels = [4]string{"1", "2", "3", "full"}
+
+// FindShardCompactions returns the compaction state of the shards of a bucket,
+// or of every shard if bucketID is nil.
+func (e *Engine) FindShardCompactions(ctx context.Context, bucketID *platform.ID) ([]*influxdb.ShardCompaction, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return nil, ErrEngineClosed
+	}
+
+	var compactions []*influxdb.ShardCompaction
+	for _, sh := range e.tsdbStore.Shards(e.tsdbStore.ShardIDs()) {
+		if bucketID != nil && sh.Database() != bucketID.String() {
+			continue
+		}
+		c, err := shardCompaction(sh)
+		if err != nil {
+			continue // the shard is closed
+		}
+		compactions = append(compactions, c)
+	}
+	return compactions, nil
+}
+
+// PauseShardCompactions pauses the level and full compactions of a shard.
+func (e *Engine) PauseShardCompactions(ctx context.Context, shardID uint64) error {
+	return e.setShardCompactionsPaused(ctx, shardID, true)
+}
+
+// ResumeShardCompactions resumes the compactions of a shard.
+func (e *Engine) ResumeShardCompactions(ctx context.Context, shardID uint64) error {
+	return e.setShardCompactionsPaused(ctx, shardID, false)
+}
+
+func (e *Engine) setShardCompactionsPaused(ctx context.Context, shardID uint64, paused bool) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	sh, err := e.shard(shardID)
+	if err != nil {
+		return err
+	}
+	return sh.SetCompactionsPaused(paused)
+}
+
+// TriggerShardCompaction schedules a full compaction of a shard.
+func (e *Engine) TriggerShardCompaction(ctx context.Context, shardID uint64) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	sh, err := e.shard(shardID)
+	if err != nil {
+		return err
+	}
+	return sh.ScheduleFullCompaction()
+}
+
+func (e *Engine) shard(id uint64) (*tsdb.Shard, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return nil, ErrEngineClosed
+	}
+
+	sh := e.tsdbStore.Shard(id)
+	if sh == nil {
+		return nil, &errors2.Error{
+			Code: errors2.ENotFound,
+			Msg:  fmt.Sprintf("shard %d not found", id),
+		}
+	}
+	return sh, nil
+}
+
+func shardCompaction(sh *tsdb.Shard) (*influxdb.ShardCompaction, error) {
+	s, err := sh.CompactionState()
+	if err != nil {
+		return nil, err
+	}
+	bucketID, err := platform.IDFromString(sh.Database())
+	if err != nil {
+		return nil, err
+	}
+
+	c := &influxdb.ShardCompaction{
+		ShardID:        sh.ID(),
+		BucketID:       *bucketID,
+		Planner:        s.Planner,
+		Paused:         s.Paused,
+		FullyCompacted: s.FullyCompacted,
+		Files:          s.Files,
+		Levels:         make(map[string]influxdb.CompactionLevel, len(compactionLevels)),
+	}
+	for i, level := range compactionLevels {
+		c.Levels[level] = influxdb.CompactionLevel{
+			Active:      s.Active[i],
+			Queued:      s.Queued[i],
+			Compactions: s.Compactions[i],
+			Errors:      s.Errors[i],
+		}
+	}
+	return c, nil
+}
+
+// compactionCollector exports the compaction state of every shard.
+type compactionCollector struct {
+	engine *Engine
+
+	paused      *prometheus.Desc
+	files       *prometheus.Desc
+	active      *prometheus.Desc
+	queued      *prometheus.Desc
+	compactions *prometheus.Desc
+}
+
+func newCompactionCollector(e *Engine) *compactionCollector {
+	shardLabels := []string{"bucket", "shard", "planner"}
+	levelLabels := []string{"bucket", "shard", "level"}
+	return &compactionCollector{
+		engine: e,
+		paused: prometheus.NewDesc(
+			"storage_compactions_paused",
+			"Whether the compactions of a shard are paused",
+			shardLabels, e.defaultMetricLabels,
+		),
+		files: prometheus.NewDesc(
+			"storage_tsm_files",
+			"Number of TSM files of a shard",
+			shardLabels, e.defaultMetricLabels,
+		),
+		active: prometheus.NewDesc(
+			"storage_compactions_active",
+			"Number of running compactions of a shard by level",
+			levelLabels, e.defaultMetricLabels,
+		),
+		queued: prometheus.NewDesc(
+			"storage_compactions_queued",
+			"Number of planned compactions of a shard by level",
+			levelLabels, e.defaultMetricLabels,
+		),
+		compactions: prometheus.NewDesc(
+			"storage_compactions_total",
+			"Number of compactions of a shard by level and status since the shard was opened",
+			append(levelLabels, "status"), e.defaultMetricLabels,
+		),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *compactionCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.paused
+	ch <- c.files
+	ch <- c.active
+	ch <- c.queued
+	ch <- c.compactions
+}
+
+// Collect implements prometheus.Collector.
+func (c *compactionCollector) Collect(ch chan<- prometheus.Metric) {
+	compactions, err := c.engine.FindShardCompactions(context.Background(), nil)
+	if err != nil {
+		return
+	}
+
+	for _, sc := range compactions {
+		bucket, shard := sc.BucketID.String(), strconv.FormatUint(sc.ShardID, 10)
+
+		var paused float64
+		if sc.Paused {
+			paused = 1
+		}
+		ch <- prometheus.MustNewConstMetric(c.paused, prometheus.GaugeValue, paused, bucket, shard, sc.Planner)
+		ch <- prometheus.MustNewConstMetric(c.files, prometheus.GaugeValue, float64(sc.Files), bucket, shard, sc.Planner)
+
+		for _, level := range compactionLevels {
+			l := sc.Levels[level]
+			ch <- prometheus.MustNewConstMetric(c.active, prometheus.GaugeValue, float64(l.Active), bucket, shard, level)
+			ch <- prometheus.MustNewConstMetric(c.queued, prometheus.GaugeValue, float64(l.Queued), bucket, shard, level)
+			ch <- prometheus.MustNewConstMetric(c.compactions, prometheus.CounterValue, float64(l.Compactions), bucket, shard, level, "ok")
+			ch <- prometheus.MustNewConstMetric(c.compactions, prometheus.CounterValue, float64(l.Errors), bucket, shard, level, "error")
+		}
+	}
+}
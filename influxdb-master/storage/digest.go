@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	errors2 "github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/influxdata/influxdb/v2/tsdb/engine/tsm1"
+)
+
+var _ influxdb.DigestService = (*Engine)(nil)
+
+// BucketDigest writes the windowed digest of the points of a bucket to w. The
+// digests of the shards of the bucket are merged, so the digest does not
+// depend on how the points of the bucket are sharded.
+func (e *Engine) BucketDigest(ctx context.Context, bucketID platform.ID, opts influxdb.DigestOptions, w io.Writer) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if opts.Window <= 0 {
+		return &errors2.Error{
+			Code: errors2.EInvalid,
+			Msg:  "digest window must be positive",
+		}
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return ErrEngineClosed
+	}
+
+	// the digests of the shards are spooled to temporary files to be merged
+	var readers []*tsm1.DigestReader
+	var paths []string
+	defer func() {
+		for _, r := range readers {
+			r.Close()
+		}
+		for _, path := range paths {
+			os.Remove(path)
+		}
+	}()
+
+	tsdbOpts := tsdb.DigestOptions{MinTime: opts.Start, MaxTime: opts.Stop, Window: opts.Window}
+	for _, sh := range e.bucketShards(bucketID) {
+		f, err := ioutil.TempFile("", "bucket_digest")
+		if err != nil {
+			return err
+		}
+		paths = append(paths, f.Name())
+
+		if err := sh.WindowDigest(tsdbOpts, f); err != nil {
+			f.Close()
+			return err
+		}
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			f.Close()
+			return err
+		}
+
+		r, err := tsm1.NewDigestReader(f)
+		if err != nil {
+			f.Close()
+			return err
+		}
+		readers = append(readers, r)
+	}
+
+	return tsm1.MergeWindowDigests(w, readers...)
+}
+
+// ExportDigestRanges writes the points of the ranges of a bucket to w as line
+// protocol.
+func (e *Engine) ExportDigestRanges(ctx context.Context, bucketID platform.ID, ranges []influxdb.DigestRange, w io.Writer) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return ErrEngineClosed
+	}
+
+	keyRanges := make([]tsdb.KeyRange, 0, len(ranges))
+	for _, r := range ranges {
+		keyRanges = append(keyRanges, tsdb.KeyRange{Key: []byte(r.Key), Min: r.Start, Max: r.Stop})
+	}
+
+	for _, sh := range e.bucketShards(bucketID) {
+		if err := sh.ExportKeyRanges(keyRanges, w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteDigestRanges deletes the points of the ranges of a bucket, so that
+// the points of another copy of the bucket exported for the same ranges
+// replace them.
+func (e *Engine) DeleteDigestRanges(ctx context.Context, bucketID platform.ID, ranges []influxdb.DigestRange) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return ErrEngineClosed
+	}
+
+	keyRanges := make([]tsdb.KeyRange, 0, len(ranges))
+	for _, r := range ranges {
+		keyRanges = append(keyRanges, tsdb.KeyRange{Key: []byte(r.Key), Min: r.Start, Max: r.Stop})
+	}
+
+	for _, sh := range e.bucketShards(bucketID) {
+		if err := sh.DeleteKeyRanges(keyRanges); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bucketShards returns the shards of a bucket. The caller must hold e.mu.
+func (e *Engine) bucketShards(bucketID platform.ID) []*tsdb.Shard {
+	var shards []*tsdb.Shard
+	for _, sh := range e.tsdbStore.Shards(e.tsdbStore.ShardIDs()) {
+		if sh.Database() == bucketID.String() {
+			shards = append(shards, sh)
+		}
+	}
+	return shards
+}
@@ -159,7 +159,7 @@ func (e *Engine) WithLogger(log *zap.Logger) {
 // PrometheusCollectors returns all the prometheus collectors associated with
 // the engine and its components.
 func (e *Engine) PrometheusCollectors() []prometheus.Collector {
-	return nil
+	return []prometheus.Collector{newCompactionCollector(e), newUsageCollector(e), newCardinalityCollector(e)}
 }
 
 // Open opens the store and all underlying resources. It returns an error if
@@ -175,6 +175,18 @@ func (e *Engine) Open(ctx context.Context) (err error) {
 	span, _ := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
 
+	rules, err := tsdb.ParseFloatEncodingRules(e.config.Data.FloatEncodings)
+	if err != nil {
+		return err
+	}
+	e.tsdbStore.EngineOptions.FloatEncodingRules = rules
+
+	plannerRules, err := tsdb.ParseCompactionPlannerRules(e.config.Data.CompactionPlanners)
+	if err != nil {
+		return err
+	}
+	e.tsdbStore.EngineOptions.CompactionPlannerRules = plannerRules
+
 	if err := e.tsdbStore.Open(); err != nil {
 		return err
 	}
@@ -275,6 +287,19 @@ func (e *Engine) CreateBucket(ctx context.Context, b *influxdb.Bucket) (err erro
 		return err
 	}
 
+	return e.SetBucketCardinalityLimits(ctx, b)
+}
+
+// SetBucketCardinalityLimits sets the limits on the number of series and
+// values per tag of a bucket, which are enforced as points are written.
+func (e *Engine) SetBucketCardinalityLimits(ctx context.Context, b *influxdb.Bucket) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	e.tsdbStore.SetCardinalityLimits(b.ID.String(), tsdb.CardinalityLimits{
+		MaxSeries:       b.MaxSeries,
+		MaxValuesPerTag: b.MaxValuesPerTag,
+	})
 	return nil
 }
 
@@ -318,6 +343,38 @@ func (e *Engine) DeleteBucketRangePredicate(ctx context.Context, orgID, bucketID
 	return e.tsdbStore.DeleteSeriesWithPredicate(bucketID.String(), min, max, pred)
 }
 
+// DeleteBucketRangePredicateDryRun returns the series of a bucket, with at most
+// limit series listed, that DeleteBucketRangePredicate would delete points of.
+func (e *Engine) DeleteBucketRangePredicateDryRun(ctx context.Context, orgID, bucketID platform.ID, min, max int64, pred influxdb.Predicate, limit int) (*influxdb.DeleteDryRun, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return nil, ErrEngineClosed
+	}
+
+	series, n, err := e.tsdbStore.FindSeriesWithPredicate(bucketID.String(), min, max, pred, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	dr := &influxdb.DeleteDryRun{SeriesN: n, Series: make([]influxdb.DeleteDryRunSeries, 0, len(series))}
+	for _, s := range series {
+		tags := make(map[string]string, len(s.Tags))
+		for _, t := range s.Tags {
+			tags[string(t.Key)] = string(t.Value)
+		}
+		dr.Series = append(dr.Series, influxdb.DeleteDryRunSeries{
+			Measurement: string(s.Name),
+			Tags:        tags,
+			Fields:      s.Fields,
+		})
+	}
+	return dr, nil
+}
+
 func (e *Engine) BackupKVStore(ctx context.Context, w io.Writer) error {
 	span, _ := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
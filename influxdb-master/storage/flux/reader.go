@@ -65,7 +65,7 @@ func NewReader(s storage.Store) query.StorageReader {
 
 func (r *storeReader) ReadFilter(ctx context.Context, spec query.ReadFilterSpec, alloc *memory.Allocator) (query.TableIterator, error) {
 	return &filterIterator{
-		ctx:   ctx,
+		ctx:   storage.NewContextWithAllocator(ctx, alloc),
 		s:     r.s,
 		spec:  spec,
 		cache: newTagsCache(0),
@@ -75,7 +75,7 @@ func (r *storeReader) ReadFilter(ctx context.Context, spec query.ReadFilterSpec,
 
 func (r *storeReader) ReadGroup(ctx context.Context, spec query.ReadGroupSpec, alloc *memory.Allocator) (query.TableIterator, error) {
 	return &groupIterator{
-		ctx:   ctx,
+		ctx:   storage.NewContextWithAllocator(ctx, alloc),
 		s:     r.s,
 		spec:  spec,
 		cache: newTagsCache(0),
@@ -85,7 +85,7 @@ func (r *storeReader) ReadGroup(ctx context.Context, spec query.ReadGroupSpec, a
 
 func (r *storeReader) ReadWindowAggregate(ctx context.Context, spec query.ReadWindowAggregateSpec, alloc *memory.Allocator) (query.TableIterator, error) {
 	return &windowAggregateIterator{
-		ctx:   ctx,
+		ctx:   storage.NewContextWithAllocator(ctx, alloc),
 		s:     r.s,
 		spec:  spec,
 		cache: newTagsCache(0),
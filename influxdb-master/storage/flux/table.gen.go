@@ -84,8 +84,21 @@ func (t *floatTable) Do(f func(flux.ColReader) error) error {
 	return t.do(f, t.advance)
 }
 
-func (t *floatTable) advance() bool {
+// next returns the next array of the cursor. The error of the cursor, such
+// as exceeding the memory limit of the query, becomes the error of the table
+// when the cursor is exhausted.
+func (t *floatTable) next() *cursors.FloatArray {
 	a := t.cur.Next()
+	if a.Len() == 0 {
+		if err := t.cur.Err(); err != nil {
+			t.err = err
+		}
+	}
+	return a
+}
+
+func (t *floatTable) advance() bool {
+	a := t.next()
 	l := a.Len()
 	if l == 0 {
 		return false
@@ -259,7 +272,7 @@ func (t *floatWindowTable) nextBuffer() bool {
 
 	// Retrieve the next array cursor if needed.
 	if t.arr == nil {
-		arr := t.cur.Next()
+		arr := t.next()
 		if arr.Len() == 0 {
 			return false
 		}
@@ -354,7 +367,7 @@ func (t *floatWindowSelectorTable) Do(f func(flux.ColReader) error) error {
 }
 
 func (t *floatWindowSelectorTable) advance() bool {
-	arr := t.cur.Next()
+	arr := t.next()
 	if arr.Len() == 0 {
 		return false
 	}
@@ -444,13 +457,13 @@ func newFloatEmptyWindowSelectorTable(
 			table: newTable(done, bounds, key, cols, defs, cache, alloc),
 			cur:   cur,
 		},
-		arr:          cur.Next(),
 		rangeStart:   rangeStart,
 		rangeStop:    rangeStop,
 		windowBounds: window.GetLatestBounds(values.Time(rangeStart)),
 		window:       window,
 		timeColumn:   timeColumn,
 	}
+	t.arr = t.next()
 	t.readTags(tags)
 	t.init(t.advance)
 	return t
@@ -530,7 +543,7 @@ func (t *floatEmptyWindowSelectorTable) startTimes(builder *array.Float64Builder
 		// If the current array is non-empty and has
 		// been read in its entirety, call Next().
 		if t.arr.Len() > 0 && t.idx == t.arr.Len() {
-			t.arr = t.cur.Next()
+			t.arr = t.next()
 			t.idx = 0
 		}
 
@@ -577,7 +590,7 @@ func (t *floatEmptyWindowSelectorTable) stopTimes(builder *array.Float64Builder)
 		// If the current array is non-empty and has
 		// been read in its entirety, call Next().
 		if t.arr.Len() > 0 && t.idx == t.arr.Len() {
-			t.arr = t.cur.Next()
+			t.arr = t.next()
 			t.idx = 0
 		}
 
@@ -641,7 +654,7 @@ func (t *floatEmptyWindowSelectorTable) startStopTimes(builder *array.Float64Bui
 		// If the current array is non-empty and has
 		// been read in its entirety, call Next().
 		if t.arr.Len() > 0 && t.idx == t.arr.Len() {
-			t.arr = t.cur.Next()
+			t.arr = t.next()
 			t.idx = 0
 		}
 
@@ -755,6 +768,9 @@ func (t *floatGroupTable) advance() bool {
 			break
 		}
 	}
+	if t.err != nil {
+		return false
+	}
 	timestamp, value, tags := aggregate.Result()
 
 	colReader := t.allocateBuffer(1)
@@ -961,8 +977,13 @@ func selectorLastGroupsFloat(ts int64, v float64, timestamps []int64, values []f
 }
 
 func (t *floatGroupTable) advanceCursor() bool {
+	err := t.cur.Err()
 	t.cur.Close()
 	t.cur = nil
+	if err != nil {
+		t.err = err
+		return false
+	}
 	for t.gc.Next() {
 		cur := t.gc.Cursor()
 		if cur == nil {
@@ -1059,8 +1080,21 @@ func (t *integerTable) Do(f func(flux.ColReader) error) error {
 	return t.do(f, t.advance)
 }
 
-func (t *integerTable) advance() bool {
+// next returns the next array of the cursor. The error of the cursor, such
+// as exceeding the memory limit of the query, becomes the error of the table
+// when the cursor is exhausted.
+func (t *integerTable) next() *cursors.IntegerArray {
 	a := t.cur.Next()
+	if a.Len() == 0 {
+		if err := t.cur.Err(); err != nil {
+			t.err = err
+		}
+	}
+	return a
+}
+
+func (t *integerTable) advance() bool {
+	a := t.next()
 	l := a.Len()
 	if l == 0 {
 		return false
@@ -1236,7 +1270,7 @@ func (t *integerWindowTable) nextBuffer() bool {
 
 	// Retrieve the next array cursor if needed.
 	if t.arr == nil {
-		arr := t.cur.Next()
+		arr := t.next()
 		if arr.Len() == 0 {
 			return false
 		}
@@ -1331,7 +1365,7 @@ func (t *integerWindowSelectorTable) Do(f func(flux.ColReader) error) error {
 }
 
 func (t *integerWindowSelectorTable) advance() bool {
-	arr := t.cur.Next()
+	arr := t.next()
 	if arr.Len() == 0 {
 		return false
 	}
@@ -1421,13 +1455,13 @@ func newIntegerEmptyWindowSelectorTable(
 			table: newTable(done, bounds, key, cols, defs, cache, alloc),
 			cur:   cur,
 		},
-		arr:          cur.Next(),
 		rangeStart:   rangeStart,
 		rangeStop:    rangeStop,
 		windowBounds: window.GetLatestBounds(values.Time(rangeStart)),
 		window:       window,
 		timeColumn:   timeColumn,
 	}
+	t.arr = t.next()
 	t.readTags(tags)
 	t.init(t.advance)
 	return t
@@ -1507,7 +1541,7 @@ func (t *integerEmptyWindowSelectorTable) startTimes(builder *array.Int64Builder
 		// If the current array is non-empty and has
 		// been read in its entirety, call Next().
 		if t.arr.Len() > 0 && t.idx == t.arr.Len() {
-			t.arr = t.cur.Next()
+			t.arr = t.next()
 			t.idx = 0
 		}
 
@@ -1554,7 +1588,7 @@ func (t *integerEmptyWindowSelectorTable) stopTimes(builder *array.Int64Builder)
 		// If the current array is non-empty and has
 		// been read in its entirety, call Next().
 		if t.arr.Len() > 0 && t.idx == t.arr.Len() {
-			t.arr = t.cur.Next()
+			t.arr = t.next()
 			t.idx = 0
 		}
 
@@ -1618,7 +1652,7 @@ func (t *integerEmptyWindowSelectorTable) startStopTimes(builder *array.Int64Bui
 		// If the current array is non-empty and has
 		// been read in its entirety, call Next().
 		if t.arr.Len() > 0 && t.idx == t.arr.Len() {
-			t.arr = t.cur.Next()
+			t.arr = t.next()
 			t.idx = 0
 		}
 
@@ -1732,6 +1766,9 @@ func (t *integerGroupTable) advance() bool {
 			break
 		}
 	}
+	if t.err != nil {
+		return false
+	}
 	timestamp, value, tags := aggregate.Result()
 
 	colReader := t.allocateBuffer(1)
@@ -1939,8 +1976,13 @@ func selectorLastGroupsInteger(ts int64, v int64, timestamps []int64, values []i
 }
 
 func (t *integerGroupTable) advanceCursor() bool {
+	err := t.cur.Err()
 	t.cur.Close()
 	t.cur = nil
+	if err != nil {
+		t.err = err
+		return false
+	}
 	for t.gc.Next() {
 		cur := t.gc.Cursor()
 		if cur == nil {
@@ -2037,8 +2079,21 @@ func (t *unsignedTable) Do(f func(flux.ColReader) error) error {
 	return t.do(f, t.advance)
 }
 
-func (t *unsignedTable) advance() bool {
+// next returns the next array of the cursor. The error of the cursor, such
+// as exceeding the memory limit of the query, becomes the error of the table
+// when the cursor is exhausted.
+func (t *unsignedTable) next() *cursors.UnsignedArray {
 	a := t.cur.Next()
+	if a.Len() == 0 {
+		if err := t.cur.Err(); err != nil {
+			t.err = err
+		}
+	}
+	return a
+}
+
+func (t *unsignedTable) advance() bool {
+	a := t.next()
 	l := a.Len()
 	if l == 0 {
 		return false
@@ -2212,7 +2267,7 @@ func (t *unsignedWindowTable) nextBuffer() bool {
 
 	// Retrieve the next array cursor if needed.
 	if t.arr == nil {
-		arr := t.cur.Next()
+		arr := t.next()
 		if arr.Len() == 0 {
 			return false
 		}
@@ -2307,7 +2362,7 @@ func (t *unsignedWindowSelectorTable) Do(f func(flux.ColReader) error) error {
 }
 
 func (t *unsignedWindowSelectorTable) advance() bool {
-	arr := t.cur.Next()
+	arr := t.next()
 	if arr.Len() == 0 {
 		return false
 	}
@@ -2397,13 +2452,13 @@ func newUnsignedEmptyWindowSelectorTable(
 			table: newTable(done, bounds, key, cols, defs, cache, alloc),
 			cur:   cur,
 		},
-		arr:          cur.Next(),
 		rangeStart:   rangeStart,
 		rangeStop:    rangeStop,
 		windowBounds: window.GetLatestBounds(values.Time(rangeStart)),
 		window:       window,
 		timeColumn:   timeColumn,
 	}
+	t.arr = t.next()
 	t.readTags(tags)
 	t.init(t.advance)
 	return t
@@ -2483,7 +2538,7 @@ func (t *unsignedEmptyWindowSelectorTable) startTimes(builder *array.Uint64Build
 		// If the current array is non-empty and has
 		// been read in its entirety, call Next().
 		if t.arr.Len() > 0 && t.idx == t.arr.Len() {
-			t.arr = t.cur.Next()
+			t.arr = t.next()
 			t.idx = 0
 		}
 
@@ -2530,7 +2585,7 @@ func (t *unsignedEmptyWindowSelectorTable) stopTimes(builder *array.Uint64Builde
 		// If the current array is non-empty and has
 		// been read in its entirety, call Next().
 		if t.arr.Len() > 0 && t.idx == t.arr.Len() {
-			t.arr = t.cur.Next()
+			t.arr = t.next()
 			t.idx = 0
 		}
 
@@ -2594,7 +2649,7 @@ func (t *unsignedEmptyWindowSelectorTable) startStopTimes(builder *array.Uint64B
 		// If the current array is non-empty and has
 		// been read in its entirety, call Next().
 		if t.arr.Len() > 0 && t.idx == t.arr.Len() {
-			t.arr = t.cur.Next()
+			t.arr = t.next()
 			t.idx = 0
 		}
 
@@ -2708,6 +2763,9 @@ func (t *unsignedGroupTable) advance() bool {
 			break
 		}
 	}
+	if t.err != nil {
+		return false
+	}
 	timestamp, value, tags := aggregate.Result()
 
 	colReader := t.allocateBuffer(1)
@@ -2914,8 +2972,13 @@ func selectorLastGroupsUnsigned(ts int64, v uint64, timestamps []int64, values [
 }
 
 func (t *unsignedGroupTable) advanceCursor() bool {
+	err := t.cur.Err()
 	t.cur.Close()
 	t.cur = nil
+	if err != nil {
+		t.err = err
+		return false
+	}
 	for t.gc.Next() {
 		cur := t.gc.Cursor()
 		if cur == nil {
@@ -3012,8 +3075,21 @@ func (t *stringTable) Do(f func(flux.ColReader) error) error {
 	return t.do(f, t.advance)
 }
 
-func (t *stringTable) advance() bool {
+// next returns the next array of the cursor. The error of the cursor, such
+// as exceeding the memory limit of the query, becomes the error of the table
+// when the cursor is exhausted.
+func (t *stringTable) next() *cursors.StringArray {
 	a := t.cur.Next()
+	if a.Len() == 0 {
+		if err := t.cur.Err(); err != nil {
+			t.err = err
+		}
+	}
+	return a
+}
+
+func (t *stringTable) advance() bool {
+	a := t.next()
 	l := a.Len()
 	if l == 0 {
 		return false
@@ -3187,7 +3263,7 @@ func (t *stringWindowTable) nextBuffer() bool {
 
 	// Retrieve the next array cursor if needed.
 	if t.arr == nil {
-		arr := t.cur.Next()
+		arr := t.next()
 		if arr.Len() == 0 {
 			return false
 		}
@@ -3282,7 +3358,7 @@ func (t *stringWindowSelectorTable) Do(f func(flux.ColReader) error) error {
 }
 
 func (t *stringWindowSelectorTable) advance() bool {
-	arr := t.cur.Next()
+	arr := t.next()
 	if arr.Len() == 0 {
 		return false
 	}
@@ -3372,13 +3448,13 @@ func newStringEmptyWindowSelectorTable(
 			table: newTable(done, bounds, key, cols, defs, cache, alloc),
 			cur:   cur,
 		},
-		arr:          cur.Next(),
 		rangeStart:   rangeStart,
 		rangeStop:    rangeStop,
 		windowBounds: window.GetLatestBounds(values.Time(rangeStart)),
 		window:       window,
 		timeColumn:   timeColumn,
 	}
+	t.arr = t.next()
 	t.readTags(tags)
 	t.init(t.advance)
 	return t
@@ -3458,7 +3534,7 @@ func (t *stringEmptyWindowSelectorTable) startTimes(builder *array.BinaryBuilder
 		// If the current array is non-empty and has
 		// been read in its entirety, call Next().
 		if t.arr.Len() > 0 && t.idx == t.arr.Len() {
-			t.arr = t.cur.Next()
+			t.arr = t.next()
 			t.idx = 0
 		}
 
@@ -3505,7 +3581,7 @@ func (t *stringEmptyWindowSelectorTable) stopTimes(builder *array.BinaryBuilder)
 		// If the current array is non-empty and has
 		// been read in its entirety, call Next().
 		if t.arr.Len() > 0 && t.idx == t.arr.Len() {
-			t.arr = t.cur.Next()
+			t.arr = t.next()
 			t.idx = 0
 		}
 
@@ -3569,7 +3645,7 @@ func (t *stringEmptyWindowSelectorTable) startStopTimes(builder *array.BinaryBui
 		// If the current array is non-empty and has
 		// been read in its entirety, call Next().
 		if t.arr.Len() > 0 && t.idx == t.arr.Len() {
-			t.arr = t.cur.Next()
+			t.arr = t.next()
 			t.idx = 0
 		}
 
@@ -3683,6 +3759,9 @@ func (t *stringGroupTable) advance() bool {
 			break
 		}
 	}
+	if t.err != nil {
+		return false
+	}
 	timestamp, value, tags := aggregate.Result()
 
 	colReader := t.allocateBuffer(1)
@@ -3833,8 +3912,13 @@ func selectorLastGroupsString(ts int64, v string, timestamps []int64, values []s
 }
 
 func (t *stringGroupTable) advanceCursor() bool {
+	err := t.cur.Err()
 	t.cur.Close()
 	t.cur = nil
+	if err != nil {
+		t.err = err
+		return false
+	}
 	for t.gc.Next() {
 		cur := t.gc.Cursor()
 		if cur == nil {
@@ -3931,8 +4015,21 @@ func (t *booleanTable) Do(f func(flux.ColReader) error) error {
 	return t.do(f, t.advance)
 }
 
-func (t *booleanTable) advance() bool {
+// next returns the next array of the cursor. The error of the cursor, such
+// as exceeding the memory limit of the query, becomes the error of the table
+// when the cursor is exhausted.
+func (t *booleanTable) next() *cursors.BooleanArray {
 	a := t.cur.Next()
+	if a.Len() == 0 {
+		if err := t.cur.Err(); err != nil {
+			t.err = err
+		}
+	}
+	return a
+}
+
+func (t *booleanTable) advance() bool {
+	a := t.next()
 	l := a.Len()
 	if l == 0 {
 		return false
@@ -4106,7 +4203,7 @@ func (t *booleanWindowTable) nextBuffer() bool {
 
 	// Retrieve the next array cursor if needed.
 	if t.arr == nil {
-		arr := t.cur.Next()
+		arr := t.next()
 		if arr.Len() == 0 {
 			return false
 		}
@@ -4201,7 +4298,7 @@ func (t *booleanWindowSelectorTable) Do(f func(flux.ColReader) error) error {
 }
 
 func (t *booleanWindowSelectorTable) advance() bool {
-	arr := t.cur.Next()
+	arr := t.next()
 	if arr.Len() == 0 {
 		return false
 	}
@@ -4291,13 +4388,13 @@ func newBooleanEmptyWindowSelectorTable(
 			table: newTable(done, bounds, key, cols, defs, cache, alloc),
 			cur:   cur,
 		},
-		arr:          cur.Next(),
 		rangeStart:   rangeStart,
 		rangeStop:    rangeStop,
 		windowBounds: window.GetLatestBounds(values.Time(rangeStart)),
 		window:       window,
 		timeColumn:   timeColumn,
 	}
+	t.arr = t.next()
 	t.readTags(tags)
 	t.init(t.advance)
 	return t
@@ -4377,7 +4474,7 @@ func (t *booleanEmptyWindowSelectorTable) startTimes(builder *array.BooleanBuild
 		// If the current array is non-empty and has
 		// been read in its entirety, call Next().
 		if t.arr.Len() > 0 && t.idx == t.arr.Len() {
-			t.arr = t.cur.Next()
+			t.arr = t.next()
 			t.idx = 0
 		}
 
@@ -4424,7 +4521,7 @@ func (t *booleanEmptyWindowSelectorTable) stopTimes(builder *array.BooleanBuilde
 		// If the current array is non-empty and has
 		// been read in its entirety, call Next().
 		if t.arr.Len() > 0 && t.idx == t.arr.Len() {
-			t.arr = t.cur.Next()
+			t.arr = t.next()
 			t.idx = 0
 		}
 
@@ -4488,7 +4585,7 @@ func (t *booleanEmptyWindowSelectorTable) startStopTimes(builder *array.BooleanB
 		// If the current array is non-empty and has
 		// been read in its entirety, call Next().
 		if t.arr.Len() > 0 && t.idx == t.arr.Len() {
-			t.arr = t.cur.Next()
+			t.arr = t.next()
 			t.idx = 0
 		}
 
@@ -4602,6 +4699,9 @@ func (t *booleanGroupTable) advance() bool {
 			break
 		}
 	}
+	if t.err != nil {
+		return false
+	}
 	timestamp, value, tags := aggregate.Result()
 
 	colReader := t.allocateBuffer(1)
@@ -4752,8 +4852,13 @@ func selectorLastGroupsBoolean(ts int64, v bool, timestamps []int64, values []bo
 }
 
 func (t *booleanGroupTable) advanceCursor() bool {
+	err := t.cur.Err()
 	t.cur.Close()
 	t.cur = nil
+	if err != nil {
+		t.err = err
+		return false
+	}
 	for t.gc.Next() {
 		cur := t.gc.Cursor()
 		if cur == nil {
@@ -64,6 +64,20 @@ func (mr *MockEngineSchemaMockRecorder) DeleteBucket(arg0, arg1, arg2 interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBucket", reflect.TypeOf((*MockEngineSchema)(nil).DeleteBucket), arg0, arg1, arg2)
 }
 
+// SetBucketCardinalityLimits mocks base method.
+func (m *MockEngineSchema) SetBucketCardinalityLimits(arg0 context.Context, arg1 *influxdb.Bucket) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetBucketCardinalityLimits", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetBucketCardinalityLimits indicates an expected call of SetBucketCardinalityLimits.
+func (mr *MockEngineSchemaMockRecorder) SetBucketCardinalityLimits(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBucketCardinalityLimits", reflect.TypeOf((*MockEngineSchema)(nil).SetBucketCardinalityLimits), arg0, arg1)
+}
+
 // UpdateBucketRetentionPolicy mocks base method.
 func (m *MockEngineSchema) UpdateBucketRetentionPolicy(arg0 context.Context, arg1 platform.ID, arg2 *influxdb.BucketUpdate) error {
 	m.ctrl.T.Helper()
@@ -270,6 +270,7 @@ type floatMultiShardArrayCursor struct {
 	cursors.FloatArrayCursor
 	cursorContext
 	filter *floatArrayFilterCursor
+	mem    memoryAccount
 }
 
 func (c *floatMultiShardArrayCursor) reset(cur cursors.FloatArrayCursor, itrs cursors.CursorIterators, cond expression) {
@@ -284,6 +285,13 @@ func (c *floatMultiShardArrayCursor) reset(cur cursors.FloatArrayCursor, itrs cu
 	c.FloatArrayCursor = cur
 	c.itrs = itrs
 	c.err = nil
+	c.mem.release()
+	c.mem.alloc = c.alloc
+}
+
+func (c *floatMultiShardArrayCursor) Close() {
+	c.mem.release()
+	c.FloatArrayCursor.Close()
 }
 
 func (c *floatMultiShardArrayCursor) Err() error { return c.err }
@@ -293,6 +301,9 @@ func (c *floatMultiShardArrayCursor) Stats() cursors.CursorStats {
 }
 
 func (c *floatMultiShardArrayCursor) Next() *cursors.FloatArray {
+	if c.err != nil {
+		return &cursors.FloatArray{}
+	}
 	for {
 		a := c.FloatArrayCursor.Next()
 		if a.Len() == 0 {
@@ -300,6 +311,12 @@ func (c *floatMultiShardArrayCursor) Next() *cursors.FloatArray {
 				continue
 			}
 		}
+		// Account for the buffer of the current array, which the
+		// underlying cursor reuses for the next one.
+		if err := c.mem.resize(a.Size()); err != nil {
+			c.err = err
+			return &cursors.FloatArray{}
+		}
 		return a
 	}
 }
@@ -1149,6 +1166,7 @@ type integerMultiShardArrayCursor struct {
 	cursors.IntegerArrayCursor
 	cursorContext
 	filter *integerArrayFilterCursor
+	mem    memoryAccount
 }
 
 func (c *integerMultiShardArrayCursor) reset(cur cursors.IntegerArrayCursor, itrs cursors.CursorIterators, cond expression) {
@@ -1163,6 +1181,13 @@ func (c *integerMultiShardArrayCursor) reset(cur cursors.IntegerArrayCursor, itr
 	c.IntegerArrayCursor = cur
 	c.itrs = itrs
 	c.err = nil
+	c.mem.release()
+	c.mem.alloc = c.alloc
+}
+
+func (c *integerMultiShardArrayCursor) Close() {
+	c.mem.release()
+	c.IntegerArrayCursor.Close()
 }
 
 func (c *integerMultiShardArrayCursor) Err() error { return c.err }
@@ -1172,6 +1197,9 @@ func (c *integerMultiShardArrayCursor) Stats() cursors.CursorStats {
 }
 
 func (c *integerMultiShardArrayCursor) Next() *cursors.IntegerArray {
+	if c.err != nil {
+		return &cursors.IntegerArray{}
+	}
 	for {
 		a := c.IntegerArrayCursor.Next()
 		if a.Len() == 0 {
@@ -1179,6 +1207,12 @@ func (c *integerMultiShardArrayCursor) Next() *cursors.IntegerArray {
 				continue
 			}
 		}
+		// Account for the buffer of the current array, which the
+		// underlying cursor reuses for the next one.
+		if err := c.mem.resize(a.Size()); err != nil {
+			c.err = err
+			return &cursors.IntegerArray{}
+		}
 		return a
 	}
 }
@@ -2028,6 +2062,7 @@ type unsignedMultiShardArrayCursor struct {
 	cursors.UnsignedArrayCursor
 	cursorContext
 	filter *unsignedArrayFilterCursor
+	mem    memoryAccount
 }
 
 func (c *unsignedMultiShardArrayCursor) reset(cur cursors.UnsignedArrayCursor, itrs cursors.CursorIterators, cond expression) {
@@ -2042,6 +2077,13 @@ func (c *unsignedMultiShardArrayCursor) reset(cur cursors.UnsignedArrayCursor, i
 	c.UnsignedArrayCursor = cur
 	c.itrs = itrs
 	c.err = nil
+	c.mem.release()
+	c.mem.alloc = c.alloc
+}
+
+func (c *unsignedMultiShardArrayCursor) Close() {
+	c.mem.release()
+	c.UnsignedArrayCursor.Close()
 }
 
 func (c *unsignedMultiShardArrayCursor) Err() error { return c.err }
@@ -2051,6 +2093,9 @@ func (c *unsignedMultiShardArrayCursor) Stats() cursors.CursorStats {
 }
 
 func (c *unsignedMultiShardArrayCursor) Next() *cursors.UnsignedArray {
+	if c.err != nil {
+		return &cursors.UnsignedArray{}
+	}
 	for {
 		a := c.UnsignedArrayCursor.Next()
 		if a.Len() == 0 {
@@ -2058,6 +2103,12 @@ func (c *unsignedMultiShardArrayCursor) Next() *cursors.UnsignedArray {
 				continue
 			}
 		}
+		// Account for the buffer of the current array, which the
+		// underlying cursor reuses for the next one.
+		if err := c.mem.resize(a.Size()); err != nil {
+			c.err = err
+			return &cursors.UnsignedArray{}
+		}
 		return a
 	}
 }
@@ -2907,6 +2958,7 @@ type stringMultiShardArrayCursor struct {
 	cursors.StringArrayCursor
 	cursorContext
 	filter *stringArrayFilterCursor
+	mem    memoryAccount
 }
 
 func (c *stringMultiShardArrayCursor) reset(cur cursors.StringArrayCursor, itrs cursors.CursorIterators, cond expression) {
@@ -2921,6 +2973,13 @@ func (c *stringMultiShardArrayCursor) reset(cur cursors.StringArrayCursor, itrs
 	c.StringArrayCursor = cur
 	c.itrs = itrs
 	c.err = nil
+	c.mem.release()
+	c.mem.alloc = c.alloc
+}
+
+func (c *stringMultiShardArrayCursor) Close() {
+	c.mem.release()
+	c.StringArrayCursor.Close()
 }
 
 func (c *stringMultiShardArrayCursor) Err() error { return c.err }
@@ -2930,6 +2989,9 @@ func (c *stringMultiShardArrayCursor) Stats() cursors.CursorStats {
 }
 
 func (c *stringMultiShardArrayCursor) Next() *cursors.StringArray {
+	if c.err != nil {
+		return &cursors.StringArray{}
+	}
 	for {
 		a := c.StringArrayCursor.Next()
 		if a.Len() == 0 {
@@ -2937,6 +2999,12 @@ func (c *stringMultiShardArrayCursor) Next() *cursors.StringArray {
 				continue
 			}
 		}
+		// Account for the buffer of the current array, which the
+		// underlying cursor reuses for the next one.
+		if err := c.mem.resize(a.Size()); err != nil {
+			c.err = err
+			return &cursors.StringArray{}
+		}
 		return a
 	}
 }
@@ -3331,6 +3399,7 @@ type booleanMultiShardArrayCursor struct {
 	cursors.BooleanArrayCursor
 	cursorContext
 	filter *booleanArrayFilterCursor
+	mem    memoryAccount
 }
 
 func (c *booleanMultiShardArrayCursor) reset(cur cursors.BooleanArrayCursor, itrs cursors.CursorIterators, cond expression) {
@@ -3345,6 +3414,13 @@ func (c *booleanMultiShardArrayCursor) reset(cur cursors.BooleanArrayCursor, itr
 	c.BooleanArrayCursor = cur
 	c.itrs = itrs
 	c.err = nil
+	c.mem.release()
+	c.mem.alloc = c.alloc
+}
+
+func (c *booleanMultiShardArrayCursor) Close() {
+	c.mem.release()
+	c.BooleanArrayCursor.Close()
 }
 
 func (c *booleanMultiShardArrayCursor) Err() error { return c.err }
@@ -3354,6 +3430,9 @@ func (c *booleanMultiShardArrayCursor) Stats() cursors.CursorStats {
 }
 
 func (c *booleanMultiShardArrayCursor) Next() *cursors.BooleanArray {
+	if c.err != nil {
+		return &cursors.BooleanArray{}
+	}
 	for {
 		a := c.BooleanArrayCursor.Next()
 		if a.Len() == 0 {
@@ -3361,6 +3440,12 @@ func (c *booleanMultiShardArrayCursor) Next() *cursors.BooleanArray {
 				continue
 			}
 		}
+		// Account for the buffer of the current array, which the
+		// underlying cursor reuses for the next one.
+		if err := c.mem.resize(a.Size()); err != nil {
+			c.err = err
+			return &cursors.BooleanArray{}
+		}
 		return a
 	}
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/influxdata/flux/interval"
+	"github.com/influxdata/flux/memory"
 	"github.com/influxdata/influxdb/v2/storage/reads/datatypes"
 	"github.com/influxdata/influxdb/v2/tsdb/cursors"
 )
@@ -52,10 +53,11 @@ func newWindowAggregateArrayCursor(ctx context.Context, agg *datatypes.Aggregate
 }
 
 type cursorContext struct {
-	ctx  context.Context
-	req  *cursors.CursorRequest
-	itrs cursors.CursorIterators
-	err  error
+	ctx   context.Context
+	req   *cursors.CursorRequest
+	itrs  cursors.CursorIterators
+	err   error
+	alloc *memory.Allocator
 }
 
 type multiShardArrayCursors struct {
@@ -88,8 +90,9 @@ func newMultiShardArrayCursors(ctx context.Context, start, end int64, asc bool)
 	}
 
 	cc := cursorContext{
-		ctx: ctx,
-		req: &m.req,
+		ctx:   ctx,
+		req:   &m.req,
+		alloc: AllocatorFromContext(ctx),
 	}
 
 	m.cursors.i.cursorContext = cc
@@ -8,7 +8,10 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
 	"github.com/influxdata/flux/interval"
+	"github.com/influxdata/flux/memory"
 	"github.com/influxdata/flux/values"
 	"github.com/influxdata/influxdb/v2/models"
 	"github.com/influxdata/influxdb/v2/tsdb/cursors"
@@ -2242,6 +2245,41 @@ func TestMultiShardArrayCursor(t *testing.T) {
 		require.NotNil(t, ia)
 		require.Equal(t, 0, ia.Len())
 	})
+
+	t.Run("should account arrays against the query allocator", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		t.Cleanup(ctrl.Finish)
+
+		newRow := func(a *cursors.IntegerArray) SeriesRow {
+			mc := mock.NewMockIntegerArrayCursor(ctrl)
+			mc.EXPECT().Next().Return(a).AnyTimes()
+			mc.EXPECT().Close()
+
+			ci := mock.NewMockCursorIterator(ctrl)
+			ci.EXPECT().
+				Next(gomock.Any(), gomock.Any()).
+				Return(mc, nil)
+			return SeriesRow{Query: cursors.CursorIterators{ci}}
+		}
+
+		limit := int64(1024)
+		alloc := &memory.Allocator{Limit: &limit}
+		ctx := NewContextWithAllocator(context.Background(), alloc)
+		msac := newMultiShardArrayCursors(ctx, models.MinNanoTime, models.MaxNanoTime, true)
+
+		cur := msac.createCursor(newRow(cursors.NewIntegerArrayLen(10))).(cursors.IntegerArrayCursor)
+		require.Equal(t, 10, cur.Next().Len())
+		require.NoError(t, cur.Err())
+		require.Equal(t, int64(160), alloc.Allocated())
+		cur.Close()
+		require.Equal(t, int64(0), alloc.Allocated())
+
+		cur = msac.createCursor(newRow(cursors.NewIntegerArrayLen(MaxPointsPerBlock))).(cursors.IntegerArrayCursor)
+		require.Equal(t, 0, cur.Next().Len())
+		require.Equal(t, codes.ResourceExhausted, flux.ErrorCode(cur.Err()))
+		cur.Close()
+		require.Equal(t, int64(0), alloc.Allocated())
+	})
 }
 
 type MockExpression struct {
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"unsafe"
 
 	"github.com/influxdata/influxdb/v2/models"
 	"github.com/influxdata/influxdb/v2/storage/reads/datatypes"
@@ -27,6 +28,8 @@ type groupResultSet struct {
 	newSeriesCursorFn func() (SeriesCursor, error)
 	nextGroupFn       func(c *groupResultSet) GroupCursor
 
+	mem memoryAccount
+	err error
 	eof bool
 }
 
@@ -55,6 +58,7 @@ func NewGroupResultSet(ctx context.Context, req *datatypes.ReadGroupRequest, new
 		keys:              make([][]byte, len(req.GroupKeys)),
 		nilSort:           NilSortHi,
 		newSeriesCursorFn: newSeriesCursorFn,
+		mem:               memoryAccount{alloc: AllocatorFromContext(ctx)},
 	}
 
 	for _, o := range opts {
@@ -78,14 +82,18 @@ func NewGroupResultSet(ctx context.Context, req *datatypes.ReadGroupRequest, new
 			vals:         make([][]byte, len(req.GroupKeys)),
 		}
 
-		if n, err := g.groupBySort(); n == 0 || err != nil {
+		if n, err := g.groupBySort(); err != nil {
+			return g.withErr(err)
+		} else if n == 0 {
 			return nil
 		}
 
 	case datatypes.GroupNone:
 		g.nextGroupFn = groupNoneNextGroup
 
-		if n, err := g.groupNoneSort(); n == 0 || err != nil {
+		if n, err := g.groupNoneSort(); err != nil {
+			return g.withErr(err)
+		} else if n == 0 {
 			return nil
 		}
 
@@ -105,9 +113,22 @@ var (
 	NilSortHi = []byte{0xff}
 )
 
-func (g *groupResultSet) Err() error { return nil }
+// withErr returns the result set with no groups and the error that
+// occurred reading the series of the groups.
+func (g *groupResultSet) withErr(err error) GroupResultSet {
+	g.mem.release()
+	g.seriesRows = nil
+	g.err = err
+	g.eof = true
+	return g
+}
 
-func (g *groupResultSet) Close() {}
+func (g *groupResultSet) Err() error { return g.err }
+
+func (g *groupResultSet) Close() {
+	g.seriesRows = nil
+	g.mem.release()
+}
 
 func (g *groupResultSet) Next() GroupCursor {
 	if g.eof {
@@ -119,7 +140,7 @@ func (g *groupResultSet) Next() GroupCursor {
 
 // seriesHasPoints reads the first block of TSM data to verify the series has points for
 // the time range of the query.
-func (g *groupResultSet) seriesHasPoints(row *SeriesRow) bool {
+func (g *groupResultSet) seriesHasPoints(row *SeriesRow) (bool, error) {
 	// TODO(sgc): this is expensive. Storage engine must provide efficient time range queries of series keys.
 	cur := g.arrayCursors.createCursor(*row)
 	var ts []int64
@@ -140,12 +161,13 @@ func (g *groupResultSet) seriesHasPoints(row *SeriesRow) bool {
 		a := c.Next()
 		ts = a.Timestamps
 	case nil:
-		return false
+		return false, nil
 	default:
 		panic(fmt.Sprintf("unreachable: %T", c))
 	}
+	err := cur.Err()
 	cur.Close()
-	return len(ts) > 0
+	return len(ts) > 0, err
 }
 
 func groupNoneNextGroup(g *groupResultSet) GroupCursor {
@@ -174,20 +196,25 @@ func (g *groupResultSet) groupNoneSort() (int, error) {
 	} else if seriesCursor == nil {
 		return 0, nil
 	}
+	defer seriesCursor.Close()
 
 	allTime := g.req.Hints.HintSchemaAllTime()
 	g.km.Clear()
 	n := 0
 	seriesRow := seriesCursor.Next()
 	for seriesRow != nil {
-		if allTime || g.seriesHasPoints(seriesRow) {
+		ok := allTime
+		if !ok {
+			if ok, err = g.seriesHasPoints(seriesRow); err != nil {
+				return 0, err
+			}
+		}
+		if ok {
 			n++
 			g.km.MergeTagKeys(seriesRow.Tags)
 		}
 		seriesRow = seriesCursor.Next()
 	}
-
-	seriesCursor.Close()
 	return n, nil
 }
 
@@ -223,6 +250,7 @@ func (g *groupResultSet) groupBySort() (int, error) {
 	} else if seriesCursor == nil {
 		return 0, nil
 	}
+	defer seriesCursor.Close()
 
 	var seriesRows []*SeriesRow
 	vals := make([][]byte, len(g.keys))
@@ -231,7 +259,13 @@ func (g *groupResultSet) groupBySort() (int, error) {
 
 	seriesRow := seriesCursor.Next()
 	for seriesRow != nil {
-		if allTime || g.seriesHasPoints(seriesRow) {
+		ok := allTime
+		if !ok {
+			if ok, err = g.seriesHasPoints(seriesRow); err != nil {
+				return 0, err
+			}
+		}
+		if ok {
 			nr := *seriesRow
 			nr.SeriesTags = tagsBuf.copyTags(nr.SeriesTags)
 			nr.Tags = tagsBuf.copyTags(nr.Tags)
@@ -252,6 +286,10 @@ func (g *groupResultSet) groupBySort() (int, error) {
 				nr.SortKey = append(nr.SortKey, '\000')
 			}
 
+			// All the series rows are kept until the result set is closed.
+			if err := g.mem.grow(seriesRowSize(&nr)); err != nil {
+				return 0, err
+			}
 			seriesRows = append(seriesRows, &nr)
 		}
 		seriesRow = seriesCursor.Next()
@@ -262,11 +300,22 @@ func (g *groupResultSet) groupBySort() (int, error) {
 	})
 
 	g.seriesRows = seriesRows
-
-	seriesCursor.Close()
 	return len(seriesRows), nil
 }
 
+// seriesRowSize returns the approximate memory used by a series row, with
+// its own copy of its tags and sort key.
+func seriesRowSize(row *SeriesRow) int {
+	n := int(unsafe.Sizeof(*row)) + len(row.SortKey)
+	for _, tags := range []models.Tags{row.SeriesTags, row.Tags} {
+		n += len(tags) * int(unsafe.Sizeof(models.Tag{}))
+		for _, t := range tags {
+			n += len(t.Key) + len(t.Value)
+		}
+	}
+	return n
+}
+
 type groupNoneCursor struct {
 	ctx          context.Context
 	arrayCursors multiShardCursors
@@ -6,6 +6,9 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/memory"
 	"github.com/influxdata/influxdb/v2/models"
 	"github.com/influxdata/influxdb/v2/pkg/data/gen"
 	"github.com/influxdata/influxdb/v2/storage/reads"
@@ -308,6 +311,53 @@ func TestNewGroupResultSet_GroupBy_NoDataReturnsNil(t *testing.T) {
 	}
 }
 
+func TestNewGroupResultSet_GroupBy_MemoryLimit(t *testing.T) {
+	newCursor := func() (reads.SeriesCursor, error) {
+		return &sliceSeriesCursor{
+			rows: newSeriesRows(
+				"cpu,tag0=val00",
+				"cpu,tag0=val01",
+				"cpu,tag0=val02",
+				"cpu,tag0=val03",
+			)}, nil
+	}
+
+	var hints datatypes.HintFlags
+	hints.SetHintSchemaAllTime()
+	req := &datatypes.ReadGroupRequest{Group: datatypes.GroupBy, GroupKeys: []string{"tag0"}, Hints: hints}
+
+	limit := int64(256)
+	alloc := &memory.Allocator{Limit: &limit}
+	ctx := reads.NewContextWithAllocator(context.Background(), alloc)
+	rs := reads.NewGroupResultSet(ctx, req, newCursor)
+	if rs == nil {
+		t.Fatal("expected result set with an error")
+	}
+	if gc := rs.Next(); gc != nil {
+		t.Errorf("expected no groups")
+	}
+	if got, exp := flux.ErrorCode(rs.Err()), codes.ResourceExhausted; got != exp {
+		t.Errorf("unexpected error code; got %v, exp %v: %v", got, exp, rs.Err())
+	}
+	rs.Close()
+	if n := alloc.Allocated(); n != 0 {
+		t.Errorf("expected memory to be released, got %d bytes allocated", n)
+	}
+
+	limit = 64 * 1024
+	rs = reads.NewGroupResultSet(ctx, req, newCursor)
+	if rs == nil || rs.Err() != nil {
+		t.Fatal("expected result set without an error")
+	}
+	if alloc.Allocated() == 0 {
+		t.Errorf("expected series rows to be accounted")
+	}
+	rs.Close()
+	if n := alloc.Allocated(); n != 0 {
+		t.Errorf("expected memory to be released, got %d bytes allocated", n)
+	}
+}
+
 func TestNewGroupResultSet_SortOrder(t *testing.T) {
 	tests := []struct {
 		name string
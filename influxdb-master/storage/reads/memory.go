@@ -0,0 +1,58 @@
+package reads
+
+import (
+	"context"
+
+	"github.com/influxdata/flux/memory"
+)
+
+type allocatorContextKey struct{}
+
+// NewContextWithAllocator returns a new context with the memory allocator of
+// a query. The buffers of the cursors and result sets created with the
+// context are accounted against the allocator.
+func NewContextWithAllocator(ctx context.Context, alloc *memory.Allocator) context.Context {
+	if alloc == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, allocatorContextKey{}, alloc)
+}
+
+// AllocatorFromContext returns the memory allocator of the context, or nil if
+// the context has none.
+func AllocatorFromContext(ctx context.Context) *memory.Allocator {
+	alloc, _ := ctx.Value(allocatorContextKey{}).(*memory.Allocator)
+	return alloc
+}
+
+// memoryAccount tracks the memory used by a cursor or result set against
+// the allocator of a query. The zero value, or an account without an
+// allocator, accounts nothing.
+type memoryAccount struct {
+	alloc *memory.Allocator
+	size  int
+}
+
+// resize sets the memory used to size bytes. The error of the allocator,
+// with the code codes.ResourceExhausted, is returned if the query exceeds its
+// memory limit, in which case the memory used is left unchanged.
+func (m *memoryAccount) resize(size int) error {
+	if m.alloc == nil || size == m.size {
+		return nil
+	}
+	if err := m.alloc.Account(size - m.size); err != nil {
+		return err
+	}
+	m.size = size
+	return nil
+}
+
+// grow adds n bytes to the memory used.
+func (m *memoryAccount) grow(n int) error {
+	return m.resize(m.size + n)
+}
+
+// release returns the memory used to the allocator.
+func (m *memoryAccount) release() {
+	_ = m.resize(0)
+}
@@ -0,0 +1,138 @@
+package storage
+
+import (
+	"context"
+	"sort"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var _ influxdb.StorageUsageService = (*Engine)(nil)
+
+// FindBucketStorageUsage returns the disk space used by the TSM blocks of the
+// series of a bucket by measurement, and by value of tagKey if it is not
+// empty. The usage by the configured usage tag key is maintained as TSM files
+// are compacted, the usage by other tag keys is computed from the TSM indexes.
+func (e *Engine) FindBucketStorageUsage(ctx context.Context, bucketID platform.ID, tagKey string) (*influxdb.BucketStorageUsage, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return nil, ErrEngineClosed
+	}
+
+	usage := make(tsdb.StorageUsage)
+	for _, sh := range e.bucketShards(bucketID) {
+		u, err := sh.StorageUsage(tagKey)
+		if err != nil {
+			continue // the shard is closed
+		}
+		usage.Add(u)
+	}
+	return newBucketStorageUsage(bucketID, tagKey, usage), nil
+}
+
+// storageUsageByBucket returns the storage usage of every bucket by the
+// configured usage tag key.
+func (e *Engine) storageUsageByBucket() (map[string]tsdb.StorageUsage, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return nil, ErrEngineClosed
+	}
+
+	buckets := make(map[string]tsdb.StorageUsage)
+	for _, sh := range e.tsdbStore.Shards(e.tsdbStore.ShardIDs()) {
+		u, err := sh.StorageUsage(e.config.Data.UsageTagKey)
+		if err != nil {
+			continue // the shard is closed
+		}
+		if buckets[sh.Database()] == nil {
+			buckets[sh.Database()] = make(tsdb.StorageUsage)
+		}
+		buckets[sh.Database()].Add(u)
+	}
+	return buckets, nil
+}
+
+// newBucketStorageUsage returns the usage of a bucket, with the measurements
+// and tag values ordered by decreasing size.
+func newBucketStorageUsage(bucketID platform.ID, tagKey string, usage tsdb.StorageUsage) *influxdb.BucketStorageUsage {
+	bu := &influxdb.BucketStorageUsage{
+		BucketID:     bucketID,
+		TagKey:       tagKey,
+		Measurements: []*influxdb.MeasurementStorageUsage{},
+	}
+
+	measurements := make(map[string]*influxdb.MeasurementStorageUsage)
+	for k, n := range usage {
+		m := measurements[k.Measurement]
+		if m == nil {
+			m = &influxdb.MeasurementStorageUsage{Measurement: k.Measurement}
+			measurements[k.Measurement] = m
+			bu.Measurements = append(bu.Measurements, m)
+		}
+		m.Bytes += n
+		bu.Bytes += n
+		if tagKey != "" {
+			m.TagValues = append(m.TagValues, &influxdb.TagValueStorageUsage{TagValue: k.TagValue, Bytes: n})
+		}
+	}
+
+	sort.Slice(bu.Measurements, func(i, j int) bool {
+		a, b := bu.Measurements[i], bu.Measurements[j]
+		return a.Bytes > b.Bytes || (a.Bytes == b.Bytes && a.Measurement < b.Measurement)
+	})
+	for _, m := range bu.Measurements {
+		sort.Slice(m.TagValues, func(i, j int) bool {
+			a, b := m.TagValues[i], m.TagValues[j]
+			return a.Bytes > b.Bytes || (a.Bytes == b.Bytes && a.TagValue < b.TagValue)
+		})
+	}
+	return bu
+}
+
+// usageCollector exports the storage usage of every bucket by measurement and
+// value of the configured usage tag key.
+type usageCollector struct {
+	engine *Engine
+
+	bytes *prometheus.Desc
+}
+
+func newUsageCollector(e *Engine) *usageCollector {
+	return &usageCollector{
+		engine: e,
+		bytes: prometheus.NewDesc(
+			"storage_bucket_usage_bytes",
+			"Disk space used by the TSM blocks of the series of a bucket by measurement and tag value",
+			[]string{"bucket", "measurement", "tag_key", "tag_value"}, e.defaultMetricLabels,
+		),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *usageCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.bytes
+}
+
+// Collect implements prometheus.Collector.
+func (c *usageCollector) Collect(ch chan<- prometheus.Metric) {
+	buckets, err := c.engine.storageUsageByBucket()
+	if err != nil {
+		return
+	}
+
+	tagKey := c.engine.config.Data.UsageTagKey
+	for bucket, usage := range buckets {
+		for k, n := range usage {
+			ch <- prometheus.MustNewConstMetric(c.bytes, prometheus.GaugeValue, float64(n), bucket, k.Measurement, tagKey, k.TagValue)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package influxdb
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2/kit/platform"
+)
+
+// BucketStorageUsage is the disk space used by the TSM blocks of the series of
+// a bucket, by measurement and by value of a tag key. Points that are not yet
+// written to TSM files are not accounted.
+type BucketStorageUsage struct {
+	BucketID     platform.ID                `json:"bucketID"`
+	TagKey       string                     `json:"tagKey,omitempty"`
+	Bytes        int64                      `json:"bytes"`
+	Measurements []*MeasurementStorageUsage `json:"measurements"`
+}
+
+// MeasurementStorageUsage is the disk space used by the series of a
+// measurement. TagValues is the usage by value of the tag key of the bucket
+// usage, where the series without the tag key have an empty value.
+type MeasurementStorageUsage struct {
+	Measurement string                  `json:"measurement"`
+	Bytes       int64                   `json:"bytes"`
+	TagValues   []*TagValueStorageUsage `json:"tagValues,omitempty"`
+}
+
+// TagValueStorageUsage is the disk space used by the series of a measurement
+// with a tag value.
+type TagValueStorageUsage struct {
+	TagValue string `json:"tagValue"`
+	Bytes    int64  `json:"bytes"`
+}
+
+// StorageUsageService reports the disk space used by the series of buckets.
+type StorageUsageService interface {
+	// FindBucketStorageUsage returns the disk space used by the series of a
+	// bucket by measurement, and by value of tagKey if it is not empty.
+	FindBucketStorageUsage(ctx context.Context, bucketID platform.ID, tagKey string) (*BucketStorageUsage, error)
+}
@@ -69,6 +69,20 @@ type TelegrafConfig struct {
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`    // Metadata for the config.
 }
 
+// Valid checks that the config is valid TOML and that the options of its
+// known plugins have values of the type of the options.
+// The error points to the lines of the config that are invalid.
+func (tc *TelegrafConfig) Valid() error {
+	if err := plugins.ValidateConfig(tc.Config); err != nil {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "invalid telegraf config",
+			Err:  err,
+		}
+	}
+	return nil
+}
+
 var pluginCount = regexp.MustCompilePOSIX(`\[\[(inputs\..*|outputs\..*|aggregators\..*|processors\..*)\]\]`)
 
 // CountPlugins returns a map of the number of times each plugin is used.
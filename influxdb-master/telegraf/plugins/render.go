@@ -0,0 +1,233 @@
+package plugins
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PluginDefinition defines a plugin of a telegraf config with the values of
+// its options.
+type PluginDefinition struct {
+	Type   Type                   `json:"type"`             // Type of the plugin.
+	Name   string                 `json:"name"`             // Name of the plugin.
+	Alias  string                 `json:"alias,omitempty"`  // Alias of the instance of the plugin.
+	Config map[string]interface{} `json:"config,omitempty"` // Config contains the values of the options of the plugin, as decoded from JSON.
+}
+
+// Render renders the TOML config of plugins. The plugins must be available
+// and the values of their known options must have the type of the options.
+func Render(defs []PluginDefinition) (string, error) {
+	var (
+		b    strings.Builder
+		errs ConfigErrors
+	)
+	for i, def := range defs {
+		cfg, err := RenderPlugin(def)
+		if err != nil {
+			errs = append(errs, pluginErrors(i, err)...)
+			continue
+		}
+		b.WriteString(cfg)
+	}
+	if len(errs) > 0 {
+		return "", errs
+	}
+	return b.String(), nil
+}
+
+// pluginErrors prefixes the errors of the ith plugin with its index, dropping
+// the lines of its rendered config.
+func pluginErrors(i int, err error) ConfigErrors {
+	cerrs, ok := err.(ConfigErrors)
+	if !ok {
+		return ConfigErrors{{Msg: fmt.Sprintf("plugin %d: %v", i, err)}}
+	}
+	errs := make(ConfigErrors, 0, len(cerrs))
+	for _, e := range cerrs {
+		errs = append(errs, &ConfigError{Msg: fmt.Sprintf("plugin %d: %s", i, e.Msg)})
+	}
+	return errs
+}
+
+// RenderPlugin renders the TOML config of a plugin, as in Render.
+func RenderPlugin(def PluginDefinition) (string, error) {
+	schema, ok := GetSchema(def.Type, def.Name)
+	if !ok {
+		return "", fmt.Errorf("unsupported %s plugin %q", def.Type, def.Name)
+	}
+	plugin := def.Type.section() + "." + def.Name
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n[[%s]]\n", schema.Description, plugin)
+	if def.Alias != "" {
+		fmt.Fprintf(&b, "  alias = %s\n", quote(def.Alias))
+	}
+
+	var values, tables []string
+	for key, value := range def.Config {
+		if value == nil || (key == "alias" && def.Alias != "") {
+			continue
+		}
+		if opt, ok := schema.Option(key); ok && isTable(opt, value) {
+			tables = append(tables, key)
+			continue
+		}
+		values = append(values, key)
+	}
+	sort.Strings(values)
+	sort.Strings(tables)
+
+	for _, key := range values {
+		opt, _ := schema.Option(key)
+		fmt.Fprintf(&b, "  %s = %s\n", encodeKey(key), encodeValue(opt, def.Config[key]))
+	}
+	for _, key := range tables {
+		// Tables must be last, as the keys following their headers are theirs.
+		switch value := def.Config[key].(type) {
+		case map[string]interface{}:
+			fmt.Fprintf(&b, "  [%s.%s]\n", plugin, encodeKey(key))
+			encodeTable(&b, value)
+		case []interface{}:
+			for _, table := range value {
+				fmt.Fprintf(&b, "  [[%s.%s]]\n", plugin, encodeKey(key))
+				encodeTable(&b, table.(map[string]interface{}))
+			}
+		}
+	}
+
+	cfg := b.String()
+	if err := ValidateConfig(cfg); err != nil {
+		return "", err
+	}
+	return cfg, nil
+}
+
+// isTable returns whether value is rendered as the table, or the tables, of a
+// table option. Other values are rendered as the values of other options,
+// which the validation of the config rejects.
+func isTable(opt *Option, value interface{}) bool {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return opt.Type == OptionTable
+	case []interface{}:
+		if opt.Type != OptionTableArray {
+			return false
+		}
+		for _, table := range v {
+			if _, ok := table.(map[string]interface{}); !ok {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+func encodeTable(b *strings.Builder, table map[string]interface{}) {
+	keys := make([]string, 0, len(table))
+	for k, v := range table {
+		if v != nil {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(b, "    %s = %s\n", encodeKey(k), encodeValue(nil, table[k]))
+	}
+}
+
+var bareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+func encodeKey(key string) string {
+	if bareKey.MatchString(key) {
+		return key
+	}
+	return quote(key)
+}
+
+// encodeValue encodes a value decoded from JSON as TOML. Numbers are encoded
+// as integers if they are integral, unless opt is a float option.
+func encodeValue(opt *Option, value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return quote(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		float := opt != nil && (opt.Type == OptionFloat || opt.Items == OptionFloat)
+		if !float && v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return strconv.FormatInt(int64(v), 10)
+		}
+		s := strconv.FormatFloat(v, 'f', -1, 64)
+		if !strings.Contains(s, ".") {
+			s += ".0"
+		}
+		return s
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, encodeValue(opt, item))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case []string:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, quote(item))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		items := make([]string, 0, len(v))
+		for _, k := range keys {
+			items = append(items, encodeKey(k)+" = "+encodeValue(nil, v[k]))
+		}
+		return "{" + strings.Join(items, ", ") + "}"
+	default:
+		return quote(fmt.Sprint(v))
+	}
+}
+
+// quote quotes s as a TOML basic string.
+func quote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04x`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,92 @@
+package plugins
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	var defs []PluginDefinition
+	require.NoError(t, json.Unmarshal([]byte(`[
+		{
+			"type": "input",
+			"name": "cpu",
+			"config": {"percpu": false, "interval": "1m", "tags": {"rack": "1a"}}
+		},
+		{
+			"type": "input",
+			"name": "mqtt_consumer",
+			"alias": "sensors",
+			"config": {
+				"servers": ["tcp://127.0.0.1:1883"],
+				"topics": ["sensors/#"],
+				"qos": 1,
+				"data_format": "json",
+				"json_string_fields": ["state"],
+				"client_id": null
+			}
+		},
+		{
+			"type": "processor",
+			"name": "regex",
+			"config": {"tags": [{"key": "host", "pattern": "^(\\w+)\\.example\\.com$", "replacement": "${1}"}]}
+		},
+		{
+			"type": "aggregator",
+			"name": "basicstats",
+			"config": {"period": "30s", "stats": ["mean", "max"]}
+		},
+		{
+			"type": "output",
+			"name": "influxdb_v2",
+			"config": {"urls": ["http://localhost:8086"], "token": "$INFLUX_TOKEN", "bucket": "sensors \"raw\""}
+		}
+	]`), &defs))
+
+	cfg, err := Render(defs)
+	require.NoError(t, err)
+	require.Equal(t, `# Read metrics about cpu usage
+[[inputs.cpu]]
+  interval = "1m"
+  percpu = false
+  [inputs.cpu.tags]
+    rack = "1a"
+# Read metrics from MQTT topic(s)
+[[inputs.mqtt_consumer]]
+  alias = "sensors"
+  data_format = "json"
+  json_string_fields = ["state"]
+  qos = 1
+  servers = ["tcp://127.0.0.1:1883"]
+  topics = ["sensors/#"]
+# Transforms tag and field values with regex pattern
+[[processors.regex]]
+  [[processors.regex.tags]]
+    key = "host"
+    pattern = "^(\\w+)\\.example\\.com$"
+    replacement = "${1}"
+# Keep the aggregate basicstats of each metric passing through.
+[[aggregators.basicstats]]
+  period = "30s"
+  stats = ["mean", "max"]
+# Configuration for sending metrics to InfluxDB
+[[outputs.influxdb_v2]]
+  bucket = "sensors \"raw\""
+  token = "$INFLUX_TOKEN"
+  urls = ["http://localhost:8086"]
+`, cfg)
+	require.NoError(t, ValidateConfig(cfg))
+}
+
+func TestRender_Invalid(t *testing.T) {
+	_, err := Render([]PluginDefinition{
+		{Type: Input, Name: "cpu", Config: map[string]interface{}{"percpu": "yes"}},
+		{Type: Input, Name: "cpu_stats"},
+		{Type: Output, Name: "influxdb_v2", Config: map[string]interface{}{"timeout": 5.5, "http_headers": "none"}},
+	})
+	require.EqualError(t, err, `plugin 0: inputs.cpu: option "percpu" must be a bool, got a string; `+
+		`plugin 1: unsupported input plugin "cpu_stats"; `+
+		`plugin 2: outputs.influxdb_v2: option "http_headers" must be a table, got a string`)
+}
@@ -0,0 +1,189 @@
+package plugins
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// OptionType is the type of the value of a plugin option.
+type OptionType string
+
+// available option types.
+const (
+	OptionString     OptionType = "string"      // OptionString is a TOML string.
+	OptionBool       OptionType = "bool"        // OptionBool is a TOML boolean.
+	OptionInteger    OptionType = "integer"     // OptionInteger is a TOML integer.
+	OptionFloat      OptionType = "float"       // OptionFloat is a TOML float or integer.
+	OptionDuration   OptionType = "duration"    // OptionDuration is a duration string such as "10s", or a number of seconds.
+	OptionArray      OptionType = "array"       // OptionArray is a TOML array of values of the items type.
+	OptionTable      OptionType = "table"       // OptionTable is a TOML table.
+	OptionTableArray OptionType = "table_array" // OptionTableArray is a TOML array of tables.
+)
+
+// Option describes an option of a plugin.
+type Option struct {
+	Name        string      `json:"name"`                  // Name of the option.
+	Type        OptionType  `json:"type"`                  // Type of the value of the option.
+	Items       OptionType  `json:"items,omitempty"`       // Items is the type of the values of an array option.
+	Description string      `json:"description,omitempty"` // Description of the option.
+	Default     interface{} `json:"default,omitempty"`     // Default value of the option in the sample config, if any.
+}
+
+// Schema describes the options of a plugin.
+type Schema struct {
+	Type        Type     `json:"type"`                  // Type of the plugin.
+	Name        string   `json:"name"`                  // Name of the plugin.
+	Description string   `json:"description,omitempty"` // Description of the plugin.
+	Options     []Option `json:"options"`               // Options of the plugin, not including the options common to its type.
+}
+
+// Schemas defines a Telegraf version's collection of plugin schemas.
+type Schemas struct {
+	Version string   `json:"version,omitempty"` // Version of telegraf the schemas are for.
+	Plugins []Schema `json:"plugins"`           // Plugins this version of telegraf supports.
+}
+
+// Option returns the option of the plugin, or the option common to plugins
+// of its type, named name.
+func (s *Schema) Option(name string) (*Option, bool) {
+	for i := range s.Options {
+		if s.Options[i].Name == name {
+			return &s.Options[i], true
+		}
+	}
+	for i, opt := range commonOptions[s.Type] {
+		if opt.Name == name {
+			return &commonOptions[s.Type][i], true
+		}
+	}
+	return nil, false
+}
+
+// filterOptions are the metric filtering options accepted by every plugin.
+var filterOptions = []Option{
+	{Name: "namepass", Type: OptionArray, Items: OptionString, Description: "Metric names to keep"},
+	{Name: "namedrop", Type: OptionArray, Items: OptionString, Description: "Metric names to drop"},
+	{Name: "fieldpass", Type: OptionArray, Items: OptionString, Description: "Field keys to keep"},
+	{Name: "fielddrop", Type: OptionArray, Items: OptionString, Description: "Field keys to drop"},
+	{Name: "tagpass", Type: OptionTable, Description: "Tag values metrics must have to be kept"},
+	{Name: "tagdrop", Type: OptionTable, Description: "Tag values of metrics to drop"},
+	{Name: "taginclude", Type: OptionArray, Items: OptionString, Description: "Tag keys to keep"},
+	{Name: "tagexclude", Type: OptionArray, Items: OptionString, Description: "Tag keys to drop"},
+}
+
+// modifierOptions are the metric modifying options accepted by inputs and
+// aggregators.
+var modifierOptions = []Option{
+	{Name: "name_override", Type: OptionString, Description: "Override the name of the metrics"},
+	{Name: "name_prefix", Type: OptionString, Description: "Prefix of the name of the metrics"},
+	{Name: "name_suffix", Type: OptionString, Description: "Suffix of the name of the metrics"},
+	{Name: "tags", Type: OptionTable, Description: "Tags added to the metrics"},
+}
+
+func options(opts ...[]Option) []Option {
+	all := []Option{{Name: "alias", Type: OptionString, Description: "Name of an instance of the plugin"}}
+	for _, o := range opts {
+		all = append(all, o...)
+	}
+	return all
+}
+
+// commonOptions are the options accepted by every plugin of a type.
+var commonOptions = map[Type][]Option{
+	Input: options([]Option{
+		{Name: "interval", Type: OptionDuration, Description: "Collection interval of the plugin"},
+		{Name: "precision", Type: OptionDuration, Description: "Precision of the timestamps of the metrics"},
+		{Name: "collection_jitter", Type: OptionDuration, Description: "Maximum random delay of a collection"},
+	}, modifierOptions, filterOptions),
+	Output: options([]Option{
+		{Name: "flush_interval", Type: OptionDuration, Description: "Flush interval of the plugin"},
+		{Name: "flush_jitter", Type: OptionDuration, Description: "Maximum random delay of a flush"},
+		{Name: "metric_batch_size", Type: OptionInteger, Description: "Maximum number of metrics of a write"},
+		{Name: "metric_buffer_limit", Type: OptionInteger, Description: "Maximum number of buffered metrics"},
+	}, filterOptions),
+	Processor: options([]Option{
+		{Name: "order", Type: OptionInteger, Description: "Order in which the processor runs"},
+	}, filterOptions),
+	Aggregator: options([]Option{
+		{Name: "period", Type: OptionDuration, Description: "Period of the aggregation"},
+		{Name: "delay", Type: OptionDuration, Description: "Delay before each period is aggregated"},
+		{Name: "grace", Type: OptionDuration, Description: "Duration metrics outside of the period are still aggregated"},
+		{Name: "drop_original", Type: OptionBool, Description: "Drop the aggregated metrics"},
+	}, modifierOptions, filterOptions),
+}
+
+var (
+	schemasOnce sync.Once
+	schemas     *Schemas
+	schemasErr  error
+)
+
+// AvailableSchemas returns the schemas of the available plugins, grouped by
+// type and sorted by name.
+func AvailableSchemas() (*Schemas, error) {
+	schemasOnce.Do(func() {
+		s := &Schemas{}
+		if err := json.Unmarshal([]byte(availableSchemas), s); err != nil {
+			schemasErr = err
+			return
+		}
+		schemas = s
+	})
+	return schemas, schemasErr
+}
+
+// ListSchemas lists the schemas of the available plugins of a type, or of
+// all available plugins if t is empty.
+func ListSchemas(t string) (*Schemas, error) {
+	all, err := AvailableSchemas()
+	if err != nil {
+		return nil, err
+	}
+
+	switch Type(t) {
+	case "":
+		return all, nil
+	case Input, Output, Processor, Aggregator:
+	default:
+		return nil, fmt.Errorf("unknown plugin type '%s'", t)
+	}
+
+	s := &Schemas{Version: all.Version, Plugins: []Schema{}}
+	for _, p := range all.Plugins {
+		if p.Type == Type(t) {
+			s.Plugins = append(s.Plugins, p)
+		}
+	}
+	return s, nil
+}
+
+// GetSchema returns the schema of the plugin of type t named name, if available.
+func GetSchema(t Type, name string) (*Schema, bool) {
+	all, err := AvailableSchemas()
+	if err != nil {
+		return nil, false
+	}
+
+	for i := range all.Plugins {
+		if all.Plugins[i].Type == t && all.Plugins[i].Name == name {
+			return &all.Plugins[i], true
+		}
+	}
+	return nil, false
+}
+
+// section returns the name of the table of the plugins of type t in a config.
+func (t Type) section() string {
+	return string(t) + "s"
+}
+
+// typeOfSection returns the type of the plugins of a table of a config.
+func typeOfSection(section string) (Type, bool) {
+	for _, t := range []Type{Input, Output, Processor, Aggregator} {
+		if t.section() == section {
+			return t, true
+		}
+	}
+	return "", false
+}
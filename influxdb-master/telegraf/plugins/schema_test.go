@@ -0,0 +1,162 @@
+package plugins
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAvailableSchemas(t *testing.T) {
+	schemas, err := AvailableSchemas()
+	require.NoError(t, err)
+
+	available, err := AvailablePlugins()
+	require.NoError(t, err)
+	require.Equal(t, len(available.Plugins), len(schemas.Plugins))
+
+	// The sample config of every plugin is valid, but for the samples that
+	// telegraf itself rejects.
+	invalid := map[string]bool{
+		"jti_openconfig_telemetry": true, // defines sensors twice
+		"snmp_legacy":              true, // configures its hosts under inputs.snmp
+	}
+	for _, p := range available.Plugins {
+		_, ok := GetSchema(Type(p.Type), p.Name)
+		require.True(t, ok, "missing schema of %s %s", p.Type, p.Name)
+		if invalid[p.Name] {
+			continue
+		}
+		require.NoError(t, ValidateConfig(p.Config), "%s %s", p.Type, p.Name)
+	}
+	require.NoError(t, ValidateConfig(AgentConfig))
+}
+
+func TestListSchemas(t *testing.T) {
+	inputs, err := ListSchemas("input")
+	require.NoError(t, err)
+	require.Equal(t, 170, len(inputs.Plugins))
+	for _, p := range inputs.Plugins {
+		require.Equal(t, Input, p.Type)
+	}
+
+	_, err = ListSchemas("bundle")
+	require.Error(t, err)
+}
+
+func TestSchema_Option(t *testing.T) {
+	s, ok := GetSchema(Input, "cpu")
+	require.True(t, ok)
+
+	opt, ok := s.Option("percpu")
+	require.True(t, ok)
+	require.Equal(t, Option{
+		Name:        "percpu",
+		Type:        OptionBool,
+		Description: "Whether to report per-cpu stats or not",
+		Default:     true,
+	}, *opt)
+
+	opt, ok = s.Option("interval")
+	require.True(t, ok)
+	require.Equal(t, OptionDuration, opt.Type)
+
+	_, ok = s.Option("period")
+	require.False(t, ok)
+
+	_, ok = GetSchema(Output, "cpu")
+	require.False(t, ok)
+}
+
+func TestValidateConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  string
+		err  string
+	}{
+		{
+			name: "valid",
+			cfg: `[agent]
+  interval = "10s"
+[global_tags]
+  dc = "us-east-1"
+[[inputs.cpu]]
+  percpu = true
+  interval = "1m"
+  namepass = ["cpu"]
+  [inputs.cpu.tags]
+    rack = "1a"
+[[inputs.mqtt_consumer]]
+  servers = ["tcp://127.0.0.1:1883"]
+  qos = 1
+  connection_timeout = "${TIMEOUT}"
+  data_format = "json"
+  json_query = "sensors"
+[[processors.regex]]
+  [[processors.regex.tags]]
+    key = "host"
+[[aggregators.basicstats]]
+  period = 30
+  stats = ["mean"]
+[[outputs.influxdb_v2]]
+  urls = ["http://localhost:8086"]
+  timeout = "5s"
+`,
+		},
+		{
+			name: "empty",
+		},
+		{
+			name: "syntax error",
+			cfg:  "[[inputs.cpu]]\n  percpu = true\n  totalcpu = \"yes\n",
+			err:  "line 3: ",
+		},
+		{
+			name: "invalid options",
+			cfg: `[[inputs.cpu]]
+  percpu = "yes"
+[[inputs.http]]
+  urls = [80]
+  timeout = "5 seconds"
+  [inputs.http.headers]
+    Accept = "application/json"
+[[outputs.influxdb_v2]]
+  urls = "http://localhost:8086"
+`,
+			err: `line 2: inputs.cpu: option "percpu" must be a bool, got a string; ` +
+				`line 4: inputs.http: option "urls" must be an array of string values, got an integer at index 0; ` +
+				`line 5: inputs.http: option "timeout" must be a duration such as "10s", got "5 seconds"; ` +
+				`line 9: outputs.influxdb_v2: option "urls" must be an array`,
+		},
+		{
+			name: "unknown plugin",
+			cfg:  "[[inputs.cpu]]\n[[inputs.cpu_stats]]\n  percpu = \"yes\"\n",
+		},
+		{
+			name: "unknown table",
+			cfg:  "[[howdy]]\n",
+		},
+		{
+			name: "unknown plugin with invalid known plugin",
+			cfg:  "[[inputs.cpu_stats]]\n  percpu = \"yes\"\n[[inputs.cpu]]\n  percpu = \"yes\"\n",
+			err:  `line 4: inputs.cpu: option "percpu" must be a bool, got a string`,
+		},
+		{
+			name: "plugin table",
+			cfg:  "[agent]\n  interval = \"10s\"\n[inputs.cpu]\n  percpu = true\n",
+			err:  `line 3: inputs.cpu must be an array of tables, as in [[inputs.cpu]]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateConfig(tt.cfg)
+			if tt.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.err)
+			require.IsType(t, ConfigErrors{}, err)
+		})
+	}
+}
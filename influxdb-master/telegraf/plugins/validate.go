@@ -0,0 +1,223 @@
+package plugins
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml"
+)
+
+// ConfigError is an error at a line of a telegraf config.
+type ConfigError struct {
+	Line int    // Line of the config the error is at, or 0 if unknown.
+	Msg  string // Msg is the description of the error.
+}
+
+// Error implements the error interface.
+func (e *ConfigError) Error() string {
+	if e.Line == 0 {
+		return e.Msg
+	}
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+// ConfigErrors are the errors of a telegraf config, ordered by line.
+type ConfigErrors []*ConfigError
+
+// Error implements the error interface.
+func (e ConfigErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// globalTables are the tables of a config that are not plugins.
+var globalTables = map[string]bool{
+	"agent":       true,
+	"global_tags": true,
+	"tags":        true,
+}
+
+// syntaxError matches the position prefixed errors of the toml parser.
+var syntaxError = regexp.MustCompile(`^\((\d+), \d+\): (.*)$`)
+
+// ValidateConfig checks that a telegraf config is valid TOML and that the
+// values of the known options of its plugins have the type of the options.
+// Plugins and tables without a schema, such as external plugins or plugins of
+// a newer telegraf, are not validated. The returned errors are ConfigErrors,
+// which point to the lines of the config they are at.
+func ValidateConfig(cfg string) error {
+	tree, err := toml.Load(cfg)
+	if err != nil {
+		if m := syntaxError.FindStringSubmatch(err.Error()); m != nil {
+			line, _ := strconv.Atoi(m[1])
+			return ConfigErrors{{Line: line, Msg: m[2]}}
+		}
+		return ConfigErrors{{Msg: err.Error()}}
+	}
+
+	v := &validator{}
+	for _, key := range tree.Keys() {
+		pos := tree.GetPosition(key)
+		if globalTables[key] {
+			if _, ok := tree.GetPath([]string{key}).(*toml.Tree); !ok {
+				v.errorf(pos.Line, "%s must be a table", key)
+			}
+			continue
+		}
+
+		t, ok := typeOfSection(key)
+		if !ok {
+			continue
+		}
+		section, ok := tree.GetPath([]string{key}).(*toml.Tree)
+		if !ok {
+			v.errorf(pos.Line, "%s must be a table", key)
+			continue
+		}
+		v.section(t, section)
+	}
+
+	if len(v.errs) == 0 {
+		return nil
+	}
+	sort.SliceStable(v.errs, func(i, j int) bool {
+		return v.errs[i].Line < v.errs[j].Line
+	})
+	return v.errs
+}
+
+type validator struct {
+	errs ConfigErrors
+}
+
+func (v *validator) errorf(line int, format string, args ...interface{}) {
+	v.errs = append(v.errs, &ConfigError{Line: line, Msg: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) section(t Type, section *toml.Tree) {
+	for _, name := range section.Keys() {
+		pos := section.GetPosition(name)
+		plugin := t.section() + "." + name
+		schema, ok := GetSchema(t, name)
+		if !ok {
+			continue
+		}
+
+		instances, ok := section.GetPath([]string{name}).([]*toml.Tree)
+		if !ok {
+			v.errorf(pos.Line, "%s must be an array of tables, as in [[%s]]", plugin, plugin)
+			continue
+		}
+		for _, instance := range instances {
+			v.plugin(plugin, schema, instance)
+		}
+	}
+}
+
+func (v *validator) plugin(plugin string, schema *Schema, instance *toml.Tree) {
+	for _, key := range instance.Keys() {
+		opt, ok := schema.Option(key)
+		if !ok {
+			// The options of the data formats are not part of the schemas.
+			continue
+		}
+		value := instance.GetPath([]string{key})
+		if err := checkOption(opt, value); err != nil {
+			v.errorf(instance.GetPosition(key).Line, "%s: option %q %s", plugin, key, err)
+		}
+	}
+}
+
+// checkOption returns an error describing the expected type of the value of
+// opt if value does not have it.
+func checkOption(opt *Option, value interface{}) error {
+	if opt.Type == OptionArray {
+		values, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("must be an array")
+		}
+		if opt.Items == "" {
+			return nil
+		}
+		for i, item := range values {
+			if !checkValue(opt.Items, item) {
+				return fmt.Errorf("must be an array of %s values, got %s at index %d", opt.Items, typeName(item), i)
+			}
+		}
+		return nil
+	}
+
+	if !checkValue(opt.Type, value) {
+		return fmt.Errorf("must be %s, got %s", article(opt.Type), typeName(value))
+	}
+	if opt.Type == OptionDuration {
+		// Environment variables are replaced by telegraf before it parses a config.
+		if s, ok := value.(string); ok && !strings.Contains(s, "$") {
+			if _, err := time.ParseDuration(s); err != nil {
+				return fmt.Errorf("must be a duration such as \"10s\", got %q", s)
+			}
+		}
+	}
+	return nil
+}
+
+func checkValue(t OptionType, value interface{}) bool {
+	switch value.(type) {
+	case string:
+		return t == OptionString || t == OptionDuration
+	case bool:
+		return t == OptionBool
+	case int64:
+		return t == OptionInteger || t == OptionFloat || t == OptionDuration
+	case float64:
+		return t == OptionFloat || t == OptionDuration
+	case []interface{}:
+		return t == OptionArray
+	case *toml.Tree:
+		return t == OptionTable
+	case []*toml.Tree:
+		return t == OptionTableArray
+	default:
+		// Dates are not the value of any option.
+		return false
+	}
+}
+
+func typeName(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return "a string"
+	case bool:
+		return "a bool"
+	case int64:
+		return "an integer"
+	case float64:
+		return "a float"
+	case []interface{}:
+		return "an array"
+	case *toml.Tree:
+		return "a table"
+	case []*toml.Tree:
+		return "an array of tables"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func article(t OptionType) string {
+	switch t {
+	case OptionInteger, OptionArray:
+		return "an " + string(t)
+	case OptionTableArray:
+		return "an array of tables"
+	default:
+		return "a " + string(t)
+	}
+}
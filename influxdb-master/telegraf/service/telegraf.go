@@ -294,6 +294,10 @@ func (s *Service) CreateTelegrafConfig(ctx context.Context, tc *influxdb.Telegra
 }
 
 func (s *Service) createTelegrafConfig(ctx context.Context, tx kv.Tx, tc *influxdb.TelegrafConfig, userID platform.ID) error {
+	if err := tc.Valid(); err != nil {
+		return err
+	}
+
 	tc.ID = s.IDGenerator.ID()
 
 	return s.putTelegrafConfig(ctx, tx, tc)
@@ -316,6 +320,10 @@ func (s *Service) updateTelegrafConfig(ctx context.Context, tx kv.Tx, id platfor
 		return nil, err
 	}
 
+	if err := tc.Valid(); err != nil {
+		return nil, err
+	}
+
 	// ID and OrganizationID can not be updated
 	tc.ID = current.ID
 	tc.OrgID = current.OrgID
@@ -196,6 +196,44 @@ func CreateTelegrafConfig(
 				},
 			},
 		},
+		{
+			name: "create telegraf config with invalid config should error",
+			fields: TelegrafConfigFields{
+				IDGenerator: mock.NewStaticIDGenerator(twoID),
+				TelegrafConfigs: []*influxdb.TelegrafConfig{
+					{
+						ID:       oneID,
+						OrgID:    twoID,
+						Name:     "tc1",
+						Config:   "[[inputs.cpu]]\n",
+						Metadata: map[string]interface{}{"buckets": []interface{}{}},
+					},
+				},
+			},
+			args: args{
+				userID: threeID,
+				telegrafConfig: &influxdb.TelegrafConfig{
+					OrgID:  twoID,
+					Name:   "name2",
+					Config: "[[inputs.cpu]]\n  percpu = \"yes\"\n",
+				},
+			},
+			wants: wants{
+				err: &errors.Error{
+					Code: errors.EInvalid,
+					Msg:  "invalid telegraf config",
+				},
+				telegrafs: []*influxdb.TelegrafConfig{
+					{
+						ID:       oneID,
+						OrgID:    twoID,
+						Name:     "tc1",
+						Config:   "[[inputs.cpu]]\n",
+						Metadata: map[string]interface{}{"buckets": []interface{}{}},
+					},
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -726,6 +764,33 @@ func UpdateTelegrafConfig(
 				},
 			},
 		},
+		{
+			name: "invalid config update",
+			fields: TelegrafConfigFields{
+				TelegrafConfigs: []*influxdb.TelegrafConfig{
+					{
+						ID:     oneID,
+						OrgID:  oneID,
+						Name:   "tc1",
+						Config: "[[inputs.cpu]]\n",
+					},
+				},
+			},
+			args: args{
+				userID: fourID,
+				id:     oneID,
+				telegrafConfig: &influxdb.TelegrafConfig{
+					Name:   "tc1",
+					Config: "[[inputs.cpu]]\n  percpu = \"yes\"\n",
+				},
+			},
+			wants: wants{
+				err: &errors.Error{
+					Code: errors.EInvalid,
+					Msg:  "invalid telegraf config",
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -738,6 +803,9 @@ func UpdateTelegrafConfig(
 			if err != nil && tt.wants.err == nil {
 				t.Fatalf("expected errors to be nil got '%v'", err)
 			}
+			if err == nil && tt.wants.err != nil {
+				t.Fatalf("expected error '%v' got nil", tt.wants.err)
+			}
 			if err != nil && tt.wants.err != nil {
 				if errors.ErrorCode(err) != errors.ErrorCode(tt.wants.err) {
 					t.Fatalf("expected error '%v' got '%v'", tt.wants.err, err)
@@ -71,6 +71,12 @@ func (h *BucketHandler) Prefix() string {
 	return prefixBuckets
 }
 
+// MountUsageHandler mounts the handler of the storage usage of a bucket at
+// /api/v2/buckets/{id}/usage.
+func (h *BucketHandler) MountUsageHandler(usageHandler http.Handler) {
+	h.Router.With(kithttp.ValidResource(h.api, h.lookupOrgByBucketID)).Mount("/{id}/usage", usageHandler)
+}
+
 // bucket is used for serialization/deserialization with duration string syntax.
 type bucket struct {
 	ID                  platform.ID     `json:"id,omitempty"`
@@ -80,6 +86,8 @@ type bucket struct {
 	Name                string          `json:"name"`
 	RetentionPolicyName string          `json:"rp,omitempty"` // This to support v1 sources
 	RetentionRules      []retentionRule `json:"retentionRules"`
+	MaxSeries           int64           `json:"maxSeries,omitempty"`
+	MaxValuesPerTag     int64           `json:"maxValuesPerTag,omitempty"`
 	influxdb.CRUDLog
 }
 
@@ -113,6 +121,8 @@ func (b *bucket) toInfluxDB() *influxdb.Bucket {
 		RetentionPolicyName: b.RetentionPolicyName,
 		RetentionPeriod:     rpDuration,
 		ShardGroupDuration:  sgDuration,
+		MaxSeries:           b.MaxSeries,
+		MaxValuesPerTag:     b.MaxValuesPerTag,
 		CRUDLog:             b.CRUDLog,
 	}
 }
@@ -130,6 +140,8 @@ func newBucket(pb *influxdb.Bucket) *bucket {
 		Description:         pb.Description,
 		RetentionPolicyName: pb.RetentionPolicyName,
 		RetentionRules:      []retentionRule{},
+		MaxSeries:           pb.MaxSeries,
+		MaxValuesPerTag:     pb.MaxValuesPerTag,
 		CRUDLog:             pb.CRUDLog,
 	}
 
@@ -158,9 +170,11 @@ type retentionRuleUpdate struct {
 
 // bucketUpdate is used for serialization/deserialization with retention rules.
 type bucketUpdate struct {
-	Name           *string               `json:"name,omitempty"`
-	Description    *string               `json:"description,omitempty"`
-	RetentionRules []retentionRuleUpdate `json:"retentionRules,omitempty"`
+	Name            *string               `json:"name,omitempty"`
+	Description     *string               `json:"description,omitempty"`
+	RetentionRules  []retentionRuleUpdate `json:"retentionRules,omitempty"`
+	MaxSeries       *int64                `json:"maxSeries,omitempty"`
+	MaxValuesPerTag *int64                `json:"maxValuesPerTag,omitempty"`
 }
 
 func (b *bucketUpdate) OK() error {
@@ -187,6 +201,24 @@ func (b *bucketUpdate) OK() error {
 		}
 	}
 
+	return checkCardinalityLimits(b.MaxSeries, b.MaxValuesPerTag)
+}
+
+// checkCardinalityLimits checks that the cardinality limits of a bucket, if
+// set, are not negative.
+func checkCardinalityLimits(maxSeries, maxValuesPerTag *int64) error {
+	if maxSeries != nil && *maxSeries < 0 {
+		return &errors.Error{
+			Code: errors.EUnprocessableEntity,
+			Msg:  "max series cannot be negative",
+		}
+	}
+	if maxValuesPerTag != nil && *maxValuesPerTag < 0 {
+		return &errors.Error{
+			Code: errors.EUnprocessableEntity,
+			Msg:  "max values per tag cannot be negative",
+		}
+	}
 	return nil
 }
 
@@ -196,8 +228,10 @@ func (b *bucketUpdate) toInfluxDB() *influxdb.BucketUpdate {
 	}
 
 	upd := influxdb.BucketUpdate{
-		Name:        b.Name,
-		Description: b.Description,
+		Name:            b.Name,
+		Description:     b.Description,
+		MaxSeries:       b.MaxSeries,
+		MaxValuesPerTag: b.MaxValuesPerTag,
 	}
 
 	// For now, only use a single retention rule.
@@ -222,9 +256,11 @@ func newBucketUpdate(pb *influxdb.BucketUpdate) *bucketUpdate {
 	}
 
 	up := &bucketUpdate{
-		Name:           pb.Name,
-		Description:    pb.Description,
-		RetentionRules: []retentionRuleUpdate{},
+		Name:            pb.Name,
+		Description:     pb.Description,
+		RetentionRules:  []retentionRuleUpdate{},
+		MaxSeries:       pb.MaxSeries,
+		MaxValuesPerTag: pb.MaxValuesPerTag,
 	}
 
 	if pb.RetentionPeriod == nil && pb.ShardGroupDuration == nil {
@@ -321,6 +357,8 @@ type postBucketRequest struct {
 	Description         string          `json:"description"`
 	RetentionPolicyName string          `json:"rp,omitempty"` // This to support v1 sources
 	RetentionRules      []retentionRule `json:"retentionRules"`
+	MaxSeries           int64           `json:"maxSeries,omitempty"`
+	MaxValuesPerTag     int64           `json:"maxValuesPerTag,omitempty"`
 }
 
 func (b *postBucketRequest) OK() error {
@@ -355,7 +393,7 @@ func (b *postBucketRequest) OK() error {
 		}
 	}
 
-	return nil
+	return checkCardinalityLimits(&b.MaxSeries, &b.MaxValuesPerTag)
 }
 
 func (b postBucketRequest) toInfluxDB() *influxdb.Bucket {
@@ -376,6 +414,8 @@ func (b postBucketRequest) toInfluxDB() *influxdb.Bucket {
 		RetentionPolicyName: b.RetentionPolicyName,
 		RetentionPeriod:     rpDur,
 		ShardGroupDuration:  sgDur,
+		MaxSeries:           b.MaxSeries,
+		MaxValuesPerTag:     b.MaxValuesPerTag,
 	}
 }
 
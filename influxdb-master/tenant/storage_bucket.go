@@ -377,6 +377,12 @@ func (s *Store) UpdateBucket(ctx context.Context, tx kv.Tx, id platform.ID, upd
 	if upd.ShardGroupDuration != nil {
 		bucket.ShardGroupDuration = *upd.ShardGroupDuration
 	}
+	if upd.MaxSeries != nil {
+		bucket.MaxSeries = *upd.MaxSeries
+	}
+	if upd.MaxValuesPerTag != nil {
+		bucket.MaxValuesPerTag = *upd.MaxValuesPerTag
+	}
 
 	v, err := marshalBucket(bucket)
 	if err != nil {
@@ -715,9 +715,10 @@ func UpdateTarget(
 	t *testing.T,
 ) {
 	type args struct {
-		url    string
-		userID platform.ID
-		id     platform.ID
+		url     string
+		headers map[string]string
+		userID  platform.ID
+		id      platform.ID
 	}
 	type wants struct {
 		err    error
@@ -823,6 +824,38 @@ func UpdateTarget(
 				},
 			},
 		},
+		{
+			name: "update keeps redacted header values",
+			fields: TargetFields{
+				Organizations: []*influxdb.Organization{newOrg(platform.ID(1))},
+				Targets: []*influxdb.ScraperTarget{
+					{
+						ID:       MustIDBase16(targetOneID),
+						URL:      "url1",
+						OrgID:    idOne,
+						BucketID: idOne,
+						Headers:  map[string]string{"Authorization": "Bearer secret", "X-Scope": "scope"},
+					},
+				},
+			},
+			args: args{
+				id:  MustIDBase16(targetOneID),
+				url: "url1",
+				headers: map[string]string{
+					"Authorization": influxdb.ScraperRedactedValue,
+					"X-Scope":       "other",
+				},
+			},
+			wants: wants{
+				target: &influxdb.ScraperTarget{
+					ID:       MustIDBase16(targetOneID),
+					URL:      "url1",
+					OrgID:    idOne,
+					BucketID: idOne,
+					Headers:  map[string]string{"Authorization": "Bearer secret", "X-Scope": "other"},
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -832,8 +865,9 @@ func UpdateTarget(
 			ctx := context.Background()
 
 			upd := &influxdb.ScraperTarget{
-				ID:  tt.args.id,
-				URL: tt.args.url,
+				ID:      tt.args.id,
+				URL:     tt.args.url,
+				Headers: tt.args.headers,
 			}
 
 			target, err := s.UpdateTarget(ctx, upd, tt.args.userID)
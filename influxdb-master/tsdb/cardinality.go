@@ -0,0 +1,252 @@
+package tsdb
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/influxdata/influxdb/v2/models"
+)
+
+// CardinalityLimits are the limits on the cardinality of the series of a
+// database. A limit of zero is unlimited.
+type CardinalityLimits struct {
+	// MaxSeries is the maximum number of series in the series file of the
+	// database.
+	MaxSeries int64
+
+	// MaxValuesPerTag is the maximum number of values of a tag key of a
+	// measurement in the index of a shard of the database.
+	MaxValuesPerTag int64
+}
+
+// Unlimited returns true if no limit is set.
+func (l CardinalityLimits) Unlimited() bool {
+	return l.MaxSeries <= 0 && l.MaxValuesPerTag <= 0
+}
+
+// SetCardinalityLimits sets the cardinality limits of the series of a
+// database, which are enforced as points are written to its shards.
+func (s *Store) SetCardinalityLimits(database string, limits CardinalityLimits) {
+	s.limitsMu.Lock()
+	defer s.limitsMu.Unlock()
+	if limits.Unlimited() {
+		delete(s.limits, database)
+		return
+	}
+	s.limits[database] = limits
+}
+
+// CardinalityLimits returns the cardinality limits of the series of a
+// database.
+func (s *Store) CardinalityLimits(database string) CardinalityLimits {
+	s.limitsMu.RLock()
+	defer s.limitsMu.RUnlock()
+	return s.limits[database]
+}
+
+// CardinalityUsage is the cardinality of the series of a database, as
+// limited by CardinalityLimits.
+type CardinalityUsage struct {
+	// SeriesN is the number of series in the series file of the database.
+	SeriesN int64
+
+	// MaxValuesPerTag is the largest number of values of a tag key of a
+	// measurement in the index of a shard, among the tag keys counted to
+	// enforce the limit of values per tag.
+	MaxValuesPerTag int64
+}
+
+// CardinalityUsage returns the cardinality of the series of a database.
+func (s *Store) CardinalityUsage(database string) CardinalityUsage {
+	var usage CardinalityUsage
+	if sfile := s.seriesFile(database); sfile != nil {
+		usage.SeriesN = int64(sfile.SeriesCount())
+	}
+
+	s.mu.RLock()
+	shards := s.filterShards(byDatabase(database))
+	s.mu.RUnlock()
+	for _, sh := range shards {
+		if n := sh.tagValues.max(); n > usage.MaxValuesPerTag {
+			usage.MaxValuesPerTag = n
+		}
+	}
+	return usage
+}
+
+// tagValueCounts caches the number of values of the tag keys of the
+// measurements of a shard. The counts are incremented as new values are
+// written and may exceed the number of values in the index after series are
+// deleted or when concurrent writes create the same value, so a count that
+// reaches a limit is recounted from the index before a write is dropped.
+type tagValueCounts struct {
+	mu sync.Mutex
+	n  map[string]int64 // by measurement and tag key
+}
+
+func tagValueCountsKey(name, key []byte) string {
+	return string(name) + "\x00" + string(key)
+}
+
+// count returns the number of values of the tag key of a measurement,
+// counting them from the index if they are not cached or if exact is set.
+// The pending values, which are being written but are not yet in the index,
+// are added to the values counted from the index.
+func (c *tagValueCounts) count(idx Index, name, key []byte, pending int64, exact bool) (int64, error) {
+	k := tagValueCountsKey(name, key)
+	c.mu.Lock()
+	n, ok := c.n[k]
+	c.mu.Unlock()
+	if ok && !exact {
+		return n, nil
+	}
+
+	itr, err := idx.TagValueIterator(name, key)
+	if err != nil {
+		return 0, err
+	}
+	n = pending
+	if itr != nil {
+		defer itr.Close()
+		for {
+			v, err := itr.Next()
+			if err != nil {
+				return 0, err
+			} else if v == nil {
+				break
+			}
+			n++
+		}
+	}
+
+	c.mu.Lock()
+	if c.n == nil {
+		c.n = make(map[string]int64)
+	}
+	c.n[k] = n
+	c.mu.Unlock()
+	return n, nil
+}
+
+// add increments the number of values of the tag key of a measurement.
+func (c *tagValueCounts) add(name, key []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.n == nil {
+		c.n = make(map[string]int64)
+	}
+	c.n[tagValueCountsKey(name, key)]++
+}
+
+// max returns the largest cached number of values of a tag key.
+func (c *tagValueCounts) max() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var max int64
+	for _, n := range c.n {
+		if n > max {
+			max = n
+		}
+	}
+	return max
+}
+
+// limitCardinality drops the points that would exceed the cardinality limits
+// of the database of the shard, moving the points that may be written, with
+// their keys, names and tags, to the front of the slices. It returns the
+// number of points that may be written, the number of points dropped and the
+// reason the first point was dropped.
+func (s *Shard) limitCardinality(limits CardinalityLimits, points []models.Point, keys, names [][]byte, tagsSlice []models.Tags) (int, int, string, error) {
+	var (
+		seriesN   = int64(s.sfile.SeriesCount())
+		newSeries = make(map[string]struct{})
+		values    = make(map[string]struct{}) // tag values known to be in the index
+		pending   = make(map[string]int64)    // new tag values by measurement and tag key
+		newValues [][2][]byte
+		dropped   int
+		reason    string
+	)
+
+	j := 0
+	for i := range points {
+		name, tags := names[i], tagsSlice[i]
+
+		isNew := false
+		if limits.MaxSeries > 0 {
+			if _, ok := newSeries[string(keys[i])]; !ok {
+				isNew = !s.sfile.HasSeries(name, tags, nil)
+			}
+		}
+		if isNew && seriesN >= limits.MaxSeries {
+			dropped++
+			if reason == "" {
+				reason = fmt.Sprintf("max-series-per-bucket limit exceeded (%d/%d): series=%q", seriesN, limits.MaxSeries, keys[i])
+			}
+			continue
+		}
+
+		newValues = newValues[:0]
+		if limits.MaxValuesPerTag > 0 {
+			why, err := s.checkTagValues(limits.MaxValuesPerTag, name, tags, values, pending, &newValues)
+			if err != nil {
+				return 0, 0, "", err
+			} else if why != "" {
+				dropped++
+				if reason == "" {
+					reason = why
+				}
+				continue
+			}
+		}
+
+		for _, v := range newValues {
+			s.tagValues.add(name, v[0])
+			pending[tagValueCountsKey(name, v[0])]++
+			values[string(name)+"\x00"+string(v[0])+"\x00"+string(v[1])] = struct{}{}
+		}
+		if isNew {
+			seriesN++
+			newSeries[string(keys[i])] = struct{}{}
+		}
+
+		points[j], keys[j], names[j], tagsSlice[j] = points[i], keys[i], names[i], tagsSlice[i]
+		j++
+	}
+	return j, dropped, reason, nil
+}
+
+// checkTagValues checks that the values of the tags of a point not yet in the
+// index of the shard do not exceed max values per tag key, including the
+// pending values of the points being written. The new values are appended to
+// newValues. A reason is returned if the point must be dropped.
+func (s *Shard) checkTagValues(max int64, name []byte, tags models.Tags, values map[string]struct{}, pending map[string]int64, newValues *[][2][]byte) (string, error) {
+	for _, t := range tags {
+		k := string(name) + "\x00" + string(t.Key) + "\x00" + string(t.Value)
+		if _, ok := values[k]; ok {
+			continue
+		}
+		if ok, err := s.index.HasTagValue(name, t.Key, t.Value); err != nil {
+			return "", err
+		} else if ok {
+			values[k] = struct{}{}
+			continue
+		}
+
+		p := pending[tagValueCountsKey(name, t.Key)]
+		n, err := s.tagValues.count(s.index, name, t.Key, p, false)
+		if err != nil {
+			return "", err
+		}
+		if n >= max {
+			if n, err = s.tagValues.count(s.index, name, t.Key, p, true); err != nil {
+				return "", err
+			}
+		}
+		if n >= max {
+			return fmt.Sprintf("max-values-per-tag limit exceeded (%d/%d): measurement=%q tag=%q value=%q",
+				n, max, name, t.Key, t.Value), nil
+		}
+		*newValues = append(*newValues, [2][]byte{t.Key, t.Value})
+	}
+	return "", nil
+}
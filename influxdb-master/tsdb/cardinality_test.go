@@ -0,0 +1,92 @@
+package tsdb_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tsdb"
+)
+
+func TestStore_CardinalityLimits(t *testing.T) {
+	write := func(t *testing.T, s *Store, data string) (int, string) {
+		t.Helper()
+		points, err := models.ParsePointsString(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = s.WriteToShard(1, points)
+		if err == nil {
+			return 0, ""
+		}
+		perr, ok := err.(tsdb.PartialWriteError)
+		if !ok {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return perr.Dropped, perr.Reason
+	}
+
+	test := func(t *testing.T, index string) {
+		t.Run("max series", func(t *testing.T) {
+			s := MustOpenStore(t, index)
+			defer s.Close()
+
+			if err := s.CreateShard("db0", "rp0", 1, true); err != nil {
+				t.Fatal(err)
+			}
+			s.SetCardinalityLimits("db0", tsdb.CardinalityLimits{MaxSeries: 2})
+
+			dropped, reason := write(t, s, "cpu,host=a v=1 1\ncpu,host=b v=1 1\ncpu,host=a v=2 2\ncpu,host=c v=1 1")
+			if got, exp := dropped, 1; got != exp {
+				t.Fatalf("unexpected dropped points: got=%d exp=%d", got, exp)
+			}
+			if !strings.HasPrefix(reason, "max-series-per-bucket limit exceeded (2/2)") {
+				t.Fatalf("unexpected reason: %s", reason)
+			}
+
+			// Points of existing series are still written.
+			if dropped, _ := write(t, s, "cpu,host=b v=2 2"); dropped != 0 {
+				t.Fatalf("unexpected dropped points: %d", dropped)
+			}
+			if got, exp := s.CardinalityUsage("db0").SeriesN, int64(2); got != exp {
+				t.Fatalf("unexpected series: got=%d exp=%d", got, exp)
+			}
+
+			// Removing the limit allows new series.
+			s.SetCardinalityLimits("db0", tsdb.CardinalityLimits{})
+			if dropped, _ := write(t, s, "cpu,host=c v=1 1"); dropped != 0 {
+				t.Fatalf("unexpected dropped points: %d", dropped)
+			}
+		})
+
+		t.Run("max values per tag", func(t *testing.T) {
+			s := MustOpenStore(t, index)
+			defer s.Close()
+
+			if err := s.CreateShard("db0", "rp0", 1, true); err != nil {
+				t.Fatal(err)
+			}
+			s.SetCardinalityLimits("db0", tsdb.CardinalityLimits{MaxValuesPerTag: 2})
+
+			dropped, reason := write(t, s, "cpu,host=a v=1 1\ncpu,host=b v=1 1\ncpu,host=c v=1 1\nmem,host=c v=1 1")
+			if got, exp := dropped, 1; got != exp {
+				t.Fatalf("unexpected dropped points: got=%d exp=%d", got, exp)
+			}
+			if !strings.HasPrefix(reason, `max-values-per-tag limit exceeded (2/2): measurement="cpu" tag="host" value="c"`) {
+				t.Fatalf("unexpected reason: %s", reason)
+			}
+
+			// New series with existing tag values are still written.
+			if dropped, _ := write(t, s, "cpu,host=a,region=west v=1 1"); dropped != 0 {
+				t.Fatalf("unexpected dropped points: %d", dropped)
+			}
+			if got, exp := s.CardinalityUsage("db0").MaxValuesPerTag, int64(2); got != exp {
+				t.Fatalf("unexpected values per tag: got=%d exp=%d", got, exp)
+			}
+		})
+	}
+
+	for _, index := range tsdb.RegisteredIndexes() {
+		t.Run(index, func(t *testing.T) { test(t, index) })
+	}
+}
@@ -0,0 +1,143 @@
+package tsdb
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Compaction planners of the TSM files of a shard.
+const (
+	// CompactionPlannerLeveled is the default planner, which rolls up TSM files
+	// into larger files in levels and fully compacts shards that are cold for
+	// compact-full-write-cold-duration.
+	CompactionPlannerLeveled = "leveled"
+
+	// CompactionPlannerTimeWindow is a planner for append-only series, which
+	// compacts the higher level TSM files by time window of their points and
+	// never rewrites the files of a closed window together with others.
+	CompactionPlannerTimeWindow = "time-window"
+
+	// CompactionPlannerAggressiveOptimize is a planner for rarely written
+	// buckets, which fully compacts shards as soon as they are cold.
+	CompactionPlannerAggressiveOptimize = "aggressive-optimize"
+)
+
+// DefaultAggressiveOptimizeColdDuration is the duration without writes after
+// which the aggressive-optimize planner fully compacts a shard.
+const DefaultAggressiveOptimizeColdDuration = time.Minute
+
+// CompactionPlannerRule selects the compaction planner of the shards of a
+// bucket. An empty Bucket matches any.
+type CompactionPlannerRule struct {
+	Bucket  string
+	Planner string
+
+	// Window is the time window of CompactionPlannerTimeWindow.
+	Window time.Duration
+
+	// ColdDuration is the duration without writes after which
+	// CompactionPlannerLeveled and CompactionPlannerAggressiveOptimize fully
+	// compact a shard. Zero is the default of the planner.
+	ColdDuration time.Duration
+}
+
+// ParseCompactionPlannerRule parses a rule of the form
+//
+//	<bucket-id>=<planner>
+//
+// where the bucket ID may be *, and the planner is leveled[:<cold-duration>],
+// time-window:<window> or aggressive-optimize[:<cold-duration>]. For example
+// `*=time-window:1h` compacts the TSM files of every bucket by hour.
+func ParseCompactionPlannerRule(s string) (CompactionPlannerRule, error) {
+	var r CompactionPlannerRule
+
+	i := strings.Index(s, "=")
+	if i <= 0 {
+		return r, fmt.Errorf("invalid compaction planner %q: expected <bucket-id>=<planner>", s)
+	}
+	if bucket := s[:i]; bucket != "*" {
+		r.Bucket = bucket
+	}
+
+	planner, arg := s[i+1:], ""
+	if j := strings.Index(planner, ":"); j >= 0 {
+		planner, arg = planner[:j], planner[j+1:]
+	}
+
+	var d time.Duration
+	if arg != "" {
+		var err error
+		if d, err = time.ParseDuration(arg); err != nil || d <= 0 {
+			return r, fmt.Errorf("invalid compaction planner %q: %q is not a positive duration", s, arg)
+		}
+	}
+
+	switch planner {
+	case CompactionPlannerLeveled, CompactionPlannerAggressiveOptimize:
+		r.Planner, r.ColdDuration = planner, d
+	case CompactionPlannerTimeWindow:
+		if d == 0 {
+			return r, fmt.Errorf("invalid compaction planner %q: %s requires a window, e.g. %s:1h", s, planner, planner)
+		}
+		r.Planner, r.Window = planner, d
+	default:
+		return r, fmt.Errorf("invalid compaction planner %q: planner must be %s, %s or %s", s, CompactionPlannerLeveled, CompactionPlannerTimeWindow, CompactionPlannerAggressiveOptimize)
+	}
+	return r, nil
+}
+
+// ParseCompactionPlannerRules parses rules with ParseCompactionPlannerRule.
+func ParseCompactionPlannerRules(ss []string) ([]CompactionPlannerRule, error) {
+	rules := make([]CompactionPlannerRule, 0, len(ss))
+	for _, s := range ss {
+		r, err := ParseCompactionPlannerRule(s)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// MatchCompactionPlannerRule returns the rule of a bucket: the first rule of
+// the bucket, or else the first rule matching any bucket.
+func MatchCompactionPlannerRule(rules []CompactionPlannerRule, bucket string) (CompactionPlannerRule, bool) {
+	best, found := CompactionPlannerRule{}, false
+	for _, r := range rules {
+		if r.Bucket == bucket {
+			return r, true
+		}
+		if r.Bucket == "" && !found {
+			best, found = r, true
+		}
+	}
+	return best, found
+}
+
+// CompactionState describes the compactions of the TSM files of a shard. The
+// arrays are indexed by compaction level: 1 to 3 are the level compactions,
+// 4 the full and optimize compactions.
+type CompactionState struct {
+	// Planner is the name of the compaction planner of the shard.
+	Planner string
+
+	// Paused is true if the level and full compactions of the shard are paused.
+	Paused bool
+
+	// FullyCompacted is true if the shard has a single generation of TSM files
+	// and no tombstones.
+	FullyCompacted bool
+
+	// Files is the number of TSM files of the shard.
+	Files int
+
+	// Active and Queued are the number of running and planned compactions.
+	Active [4]int64
+	Queued [4]int64
+
+	// Compactions and Errors are the number of successful and failed
+	// compactions since the shard was opened.
+	Compactions [4]int64
+	Errors      [4]int64
+}
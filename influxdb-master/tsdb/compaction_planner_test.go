@@ -0,0 +1,68 @@
+package tsdb_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/tsdb"
+)
+
+func TestParseCompactionPlannerRule(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		want tsdb.CompactionPlannerRule
+	}{
+		{in: "*=leveled", want: tsdb.CompactionPlannerRule{Planner: "leveled"}},
+		{in: "*=leveled:30m", want: tsdb.CompactionPlannerRule{Planner: "leveled", ColdDuration: 30 * time.Minute}},
+		{in: "0000000000000001=time-window:1h", want: tsdb.CompactionPlannerRule{Bucket: "0000000000000001", Planner: "time-window", Window: time.Hour}},
+		{in: "0000000000000002=aggressive-optimize", want: tsdb.CompactionPlannerRule{Bucket: "0000000000000002", Planner: "aggressive-optimize"}},
+		{in: "0000000000000002=aggressive-optimize:5m", want: tsdb.CompactionPlannerRule{Bucket: "0000000000000002", Planner: "aggressive-optimize", ColdDuration: 5 * time.Minute}},
+	} {
+		got, err := tsdb.ParseCompactionPlannerRule(tt.in)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.in, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Fatalf("%s: got %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseCompactionPlannerRule_Error(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"leveled",
+		"=leveled",
+		"*=tiered",
+		"*=time-window",
+		"*=time-window:0s",
+		"*=time-window:-1h",
+		"*=aggressive-optimize:soon",
+	} {
+		if _, err := tsdb.ParseCompactionPlannerRule(in); err == nil {
+			t.Fatalf("%q: expected an error", in)
+		}
+	}
+}
+
+func TestMatchCompactionPlannerRule(t *testing.T) {
+	rules, err := tsdb.ParseCompactionPlannerRules([]string{
+		"*=time-window:1h",
+		"0000000000000001=aggressive-optimize",
+		"*=leveled",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r, ok := tsdb.MatchCompactionPlannerRule(rules, "0000000000000001"); !ok || r.Planner != tsdb.CompactionPlannerAggressiveOptimize {
+		t.Fatalf("unexpected rule %+v", r)
+	}
+	if r, ok := tsdb.MatchCompactionPlannerRule(rules, "0000000000000002"); !ok || r.Planner != tsdb.CompactionPlannerTimeWindow {
+		t.Fatalf("unexpected rule %+v", r)
+	}
+	if _, ok := tsdb.MatchCompactionPlannerRule(rules[1:2], "0000000000000002"); ok {
+		t.Fatal("expected no matching rule")
+	}
+}
@@ -32,6 +32,10 @@ const (
 	// the shard hasn't received writes or deletes
 	DefaultCacheSnapshotWriteColdDuration = time.Duration(10 * time.Minute)
 
+	// DefaultCacheSnapshotInterval is the maximum age of a write held by the
+	// cache before the engine snapshots the cache, when the WAL is disabled.
+	DefaultCacheSnapshotInterval = time.Duration(10 * time.Second)
+
 	// DefaultCompactFullWriteColdDuration is the duration at which the engine
 	// will compact all TSM files in a shard if it hasn't received a write or delete
 	DefaultCompactFullWriteColdDuration = time.Duration(4 * time.Hour)
@@ -85,6 +89,16 @@ type Config struct {
 	// disks or when WAL write contention is seen.  A value of 0 fsyncs every write to the WAL.
 	WALFsyncDelay toml.Duration `toml:"wal-fsync-delay"`
 
+	// WALDisabledBuckets are the IDs of the buckets whose shards do not write
+	// a WAL, where * matches any bucket. Writes to them are then only held by
+	// the cache until it is snapshotted to a TSM file, and the writes received
+	// since the last snapshot are lost if the process crashes. The durability
+	// window is bounded by CacheSnapshotInterval plus the time taken to write a
+	// snapshot, and the writes lost by a crash are reported when the engine is
+	// reopened. Intended for buckets of bulk loads that can be replayed, such as
+	// backfills.
+	WALDisabledBuckets []string `toml:"wal-disabled-buckets"`
+
 	// Enables unicode validation on series keys on write.
 	ValidateKeys bool `toml:"validate-keys"`
 
@@ -95,10 +109,27 @@ type Config struct {
 	CacheMaxMemorySize             toml.Size     `toml:"cache-max-memory-size"`
 	CacheSnapshotMemorySize        toml.Size     `toml:"cache-snapshot-memory-size"`
 	CacheSnapshotWriteColdDuration toml.Duration `toml:"cache-snapshot-write-cold-duration"`
+	CacheSnapshotInterval          toml.Duration `toml:"cache-snapshot-interval"`
 	CompactFullWriteColdDuration   toml.Duration `toml:"compact-full-write-cold-duration"`
 	CompactThroughput              toml.Size     `toml:"compact-throughput"`
 	CompactThroughputBurst         toml.Size     `toml:"compact-throughput-burst"`
 
+	// FloatEncodings selects the compression of the values of float fields by
+	// bucket, measurement and field, see ParseFloatEncodingRule. Blocks are
+	// encoded with the selected compression when the cache is snapshotted and
+	// when TSM files are compacted, so existing blocks converge to it over time.
+	FloatEncodings []string `toml:"float-encodings"`
+
+	// CompactionPlanners selects the compaction planner of the shards of a
+	// bucket, see ParseCompactionPlannerRule. Shards of other buckets use the
+	// leveled planner.
+	CompactionPlanners []string `toml:"compaction-planners"`
+
+	// UsageTagKey is the tag key by which the storage usage of the series of a
+	// measurement is accounted as TSM files are written and compacted. The
+	// usage by other tag keys is computed on request.
+	UsageTagKey string `toml:"usage-tag-key"`
+
 	// Limits
 
 	// MaxConcurrentCompactions is the maximum number of concurrent level and full compactions
@@ -145,6 +176,7 @@ func NewConfig() Config {
 		CacheMaxMemorySize:             toml.Size(DefaultCacheMaxMemorySize),
 		CacheSnapshotMemorySize:        toml.Size(DefaultCacheSnapshotMemorySize),
 		CacheSnapshotWriteColdDuration: toml.Duration(DefaultCacheSnapshotWriteColdDuration),
+		CacheSnapshotInterval:          toml.Duration(DefaultCacheSnapshotInterval),
 		CompactFullWriteColdDuration:   toml.Duration(DefaultCompactFullWriteColdDuration),
 		CompactThroughput:              toml.Size(DefaultCompactThroughput),
 		CompactThroughputBurst:         toml.Size(DefaultCompactThroughputBurst),
@@ -173,6 +205,24 @@ func (c *Config) Validate() error {
 		return errors.New("max-concurrent-compactions must be non-negative")
 	}
 
+	if c.CacheSnapshotInterval < 0 {
+		return errors.New("cache-snapshot-interval must be non-negative")
+	}
+
+	for _, b := range c.WALDisabledBuckets {
+		if b == "" {
+			return errors.New("wal-disabled-buckets must not contain an empty bucket")
+		}
+	}
+
+	if _, err := ParseFloatEncodingRules(c.FloatEncodings); err != nil {
+		return err
+	}
+
+	if _, err := ParseCompactionPlannerRules(c.CompactionPlanners); err != nil {
+		return err
+	}
+
 	if c.SeriesIDSetCacheSize < 0 {
 		return errors.New("series-id-set-cache-size must be non-negative")
 	}
@@ -205,3 +255,13 @@ func (c *Config) Validate() error {
 
 	return nil
 }
+
+// WALDisabled returns true if the shards of bucket do not write a WAL.
+func (c Config) WALDisabled(bucket string) bool {
+	for _, b := range c.WALDisabledBuckets {
+		if b == "*" || b == bucket {
+			return true
+		}
+	}
+	return false
+}
@@ -70,6 +70,32 @@ func TestConfig_Validate_Error(t *testing.T) {
 	if err := c.Validate(); err == nil || err.Error() != "series-id-set-cache-size must be non-negative" {
 		t.Errorf("unexpected error: %s", err)
 	}
+
+	c.SeriesIDSetCacheSize = 0
+	c.WALDisabledBuckets = []string{""}
+	if err := c.Validate(); err == nil || err.Error() != "wal-disabled-buckets must not contain an empty bucket" {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func TestConfig_WALDisabled(t *testing.T) {
+	c := tsdb.NewConfig()
+	if c.WALDisabled("0000000000000001") {
+		t.Error("expected the WAL to be enabled by default")
+	}
+
+	c.WALDisabledBuckets = []string{"0000000000000001"}
+	if !c.WALDisabled("0000000000000001") {
+		t.Error("expected the WAL of the listed bucket to be disabled")
+	}
+	if c.WALDisabled("0000000000000002") {
+		t.Error("expected the WAL of another bucket to be enabled")
+	}
+
+	c.WALDisabledBuckets = []string{"*"}
+	if !c.WALDisabled("0000000000000002") {
+		t.Error("expected * to disable the WAL of any bucket")
+	}
 }
 
 func TestConfig_ByteSizes(t *testing.T) {
@@ -0,0 +1,266 @@
+package tsdb
+
+import (
+	"sort"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/models"
+)
+
+// seriesFieldSeparator separates the key of a series and the key of a field
+// in the composite keys of the TSM engine.
+const seriesFieldSeparator = "#!~#"
+
+// appendSeriesFieldKey appends the composite key of a series and field to dst.
+func appendSeriesFieldKey(dst, seriesKey []byte, field string) []byte {
+	dst = append(dst, seriesKey...)
+	dst = append(dst, seriesFieldSeparator...)
+	return append(dst, field...)
+}
+
+// fieldPredicate is implemented by predicates that may compare the field key
+// of series, which then only match the composite keys of series and fields.
+type fieldPredicate interface {
+	HasFieldRef() bool
+}
+
+// predicateHasFieldRef returns true if pred compares the field key.
+func predicateHasFieldRef(pred influxdb.Predicate) bool {
+	p, ok := pred.(fieldPredicate)
+	return ok && p.HasFieldRef()
+}
+
+// predicateFields returns the keys of the fields of a measurement of a shard
+// to match against pred, or nil if pred does not compare the field key.
+func predicateFields(sh *Shard, name []byte, pred influxdb.Predicate) []string {
+	if !predicateHasFieldRef(pred) {
+		return nil
+	}
+	mf := sh.MeasurementFields(name)
+	if mf == nil {
+		return nil
+	}
+	return mf.FieldKeys()
+}
+
+// seriesPredicateMatcher matches series against a delete predicate. Series
+// are matched with their measurement as the measurement tag key, and fields
+// as the field tag key.
+type seriesPredicateMatcher struct {
+	pred influxdb.Predicate
+	buf  []byte
+	tags models.Tags
+}
+
+// match returns true if pred matches every field of the series. Otherwise it
+// calls fn with each of fields that pred matches.
+func (m *seriesPredicateMatcher) match(name []byte, tags models.Tags, fields []string, fn func(field string)) bool {
+	m.tags = append(m.tags[:0], models.Tag{Key: models.MeasurementTagKeyBytes, Value: name})
+	m.tags = append(m.tags, tags...)
+	m.buf = models.AppendMakeKey(m.buf[:0], name, m.tags)
+	if m.pred.Matches(m.buf) {
+		return true
+	}
+
+	n := len(m.buf)
+	for _, field := range fields {
+		m.buf = append(m.buf[:n], seriesFieldSeparator...)
+		m.buf = append(m.buf, field...)
+		if m.pred.Matches(m.buf) {
+			fn(field)
+		}
+	}
+	return false
+}
+
+// fieldPredicateSeriesIDIterator iterates the series of which a predicate
+// that compares the field key matches every field, and collects the
+// composite keys of the fields it matches of the other series.
+type fieldPredicateSeriesIDIterator struct {
+	itr     SeriesIDIterator
+	sfile   *SeriesFile
+	matcher seriesPredicateMatcher
+	fields  []string
+
+	// keys are the composite keys of the series and fields matched.
+	keys [][]byte
+}
+
+func newFieldPredicateSeriesIDIterator(itr SeriesIDIterator, sfile *SeriesFile, pred influxdb.Predicate, fields []string) *fieldPredicateSeriesIDIterator {
+	return &fieldPredicateSeriesIDIterator{
+		itr:     itr,
+		sfile:   sfile,
+		matcher: seriesPredicateMatcher{pred: pred},
+		fields:  fields,
+	}
+}
+
+func (itr *fieldPredicateSeriesIDIterator) Close() error { return itr.itr.Close() }
+
+func (itr *fieldPredicateSeriesIDIterator) Next() (SeriesIDElem, error) {
+	for {
+		elem, err := itr.itr.Next()
+		if elem.SeriesID == 0 || err != nil {
+			return elem, err
+		}
+
+		// Skip if this key has been tombstoned.
+		seriesKey := itr.sfile.SeriesKey(elem.SeriesID)
+		if len(seriesKey) == 0 {
+			continue
+		}
+
+		name, tags := ParseSeriesKey(seriesKey)
+		var key []byte
+		if itr.matcher.match(name, tags, itr.fields, func(field string) {
+			if key == nil {
+				key = models.MakeKey(name, tags)
+			}
+			itr.keys = append(itr.keys, appendSeriesFieldKey(nil, key, field))
+		}) {
+			return elem, nil
+		}
+	}
+}
+
+// SeriesFields is a series with some of its fields.
+type SeriesFields struct {
+	Name   []byte
+	Tags   models.Tags
+	Fields []string
+}
+
+// FindSeriesWithPredicate returns the series of a database with points
+// between min and max (inclusive) that DeleteSeriesWithPredicate would
+// delete, with the fields of each, sorted by series key. At most limit
+// series are returned, along with the total number of series.
+func (s *Store) FindSeriesWithPredicate(database string, min, max int64, pred influxdb.Predicate, limit int) ([]SeriesFields, int, error) {
+	s.mu.RLock()
+	sfile := s.sfiles[database]
+	if sfile == nil {
+		s.mu.RUnlock()
+		return nil, 0, nil
+	}
+	shards := s.filterShards(byDatabase(database))
+	s.mu.RUnlock()
+
+	// The fields with points in the range by series key.
+	series := make(map[string]map[string]struct{})
+	for _, sh := range shards {
+		engine, err := sh.Engine()
+		if err != nil {
+			continue // the shard is closed
+		}
+		index, err := sh.Index()
+		if err != nil {
+			continue
+		}
+
+		if err := func() error {
+			mitr, err := index.MeasurementIterator()
+			if err != nil {
+				return err
+			} else if mitr == nil {
+				return nil
+			}
+			defer mitr.Close()
+
+			for {
+				name, err := mitr.Next()
+				if err != nil {
+					return err
+				} else if name == nil {
+					return nil
+				}
+
+				if err := findMeasurementSeriesWithPredicate(sh, engine, index, sfile, name, min, max, pred, series); err != nil {
+					return err
+				}
+			}
+		}(); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	keys := make([]string, 0, len(series))
+	for k := range series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	if limit > 0 && len(keys) > limit {
+		keys = keys[:limit]
+	}
+
+	a := make([]SeriesFields, 0, len(keys))
+	for _, k := range keys {
+		name, tags := models.ParseKeyBytes([]byte(k))
+		sf := SeriesFields{Name: name, Tags: tags, Fields: make([]string, 0, len(series[k]))}
+		for f := range series[k] {
+			sf.Fields = append(sf.Fields, f)
+		}
+		sort.Strings(sf.Fields)
+		a = append(a, sf)
+	}
+	return a, len(series), nil
+}
+
+// findMeasurementSeriesWithPredicate adds the fields with points between min
+// and max of the series of a measurement of a shard that pred matches to
+// series, by series key.
+func findMeasurementSeriesWithPredicate(sh *Shard, engine Engine, index Index, sfile *SeriesFile, name []byte, min, max int64, pred influxdb.Predicate, series map[string]map[string]struct{}) error {
+	mf := sh.MeasurementFields(name)
+	if mf == nil {
+		return nil
+	}
+	fields := mf.FieldKeys()
+
+	sitr, err := index.MeasurementSeriesIDIterator(name)
+	if err != nil {
+		return err
+	} else if sitr == nil {
+		return nil
+	}
+	defer sitr.Close()
+
+	var (
+		matcher = seriesPredicateMatcher{pred: pred}
+		matched []string
+		buf     []byte
+	)
+	for {
+		elem, err := sitr.Next()
+		if err != nil {
+			return err
+		} else if elem.SeriesID == 0 {
+			return nil
+		}
+
+		seriesKey := sfile.SeriesKey(elem.SeriesID)
+		if len(seriesKey) == 0 {
+			continue
+		}
+		name, tags := ParseSeriesKey(seriesKey)
+
+		matched = matched[:0]
+		if matcher.match(name, tags, fields, func(field string) { matched = append(matched, field) }) {
+			matched = append(matched, fields...)
+		}
+		if len(matched) == 0 {
+			continue
+		}
+
+		key := models.MakeKey(name, tags)
+		for _, field := range matched {
+			buf = appendSeriesFieldKey(buf[:0], key, field)
+			if !engine.HasPointsInRange(buf, min, max) {
+				continue
+			}
+			f := series[string(key)]
+			if f == nil {
+				f = make(map[string]struct{})
+				series[string(key)] = f
+			}
+			f[field] = struct{}{}
+		}
+	}
+}
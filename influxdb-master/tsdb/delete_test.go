@@ -0,0 +1,124 @@
+package tsdb_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2/influxql/query"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/predicate"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/influxdata/influxql"
+)
+
+func TestStore_DeleteSeriesWithPredicate_Fields(t *testing.T) {
+	test := func(t *testing.T, index string) {
+		s := MustOpenStore(t, index)
+		defer s.Close()
+
+		if err := s.CreateShard("db0", "rp0", 1, true); err != nil {
+			t.Fatal(err)
+		}
+		points, err := models.ParsePointsString(
+			"cpu,host=a,region=west temp=1,load=1 10\n" +
+				"cpu,host=b,region=west temp=2,load=2 10\n" +
+				"cpu,host=c,region=east temp=3,load=3 10\n" +
+				"mem,host=a free=1 10")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.WriteToShard(1, points); err != nil {
+			t.Fatal(err)
+		}
+
+		node, err := predicate.Parse(`_measurement="cpu" and _field="temp" and (host=~/^[ab]$/ or region!="west")`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pred, err := predicate.New(node)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		series, n, err := s.FindSeriesWithPredicate("db0", 0, 100, pred, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, exp := n, 3; got != exp {
+			t.Fatalf("unexpected series: got=%d exp=%d", got, exp)
+		}
+		if got, exp := len(series), 2; got != exp {
+			t.Fatalf("unexpected listed series: got=%d exp=%d", got, exp)
+		}
+		if got, exp := series[0].Fields, []string{"temp"}; !reflect.DeepEqual(got, exp) {
+			t.Fatalf("unexpected fields: got=%v exp=%v", got, exp)
+		}
+		if got, exp := string(models.MakeKey(series[0].Name, series[0].Tags)), "cpu,host=a,region=west"; got != exp {
+			t.Fatalf("unexpected series: got=%s exp=%s", got, exp)
+		}
+
+		// A dry run outside of the range of the points finds nothing.
+		if _, n, err := s.FindSeriesWithPredicate("db0", 20, 100, pred, 0); err != nil {
+			t.Fatal(err)
+		} else if n != 0 {
+			t.Fatalf("unexpected series: %d", n)
+		}
+
+		if err := s.DeleteSeriesWithPredicate("db0", 0, 100, pred); err != nil {
+			t.Fatal(err)
+		}
+
+		// The temp field is deleted, but the series are left with the load field.
+		for _, field := range []string{"temp", "load", "free"} {
+			measurement := "cpu"
+			if field == "free" {
+				measurement = "mem"
+			}
+			itr, err := s.Shard(1).CreateIterator(
+				context.Background(),
+				&influxql.Measurement{Name: measurement},
+				query.IteratorOptions{
+					Expr:      influxql.MustParseExpr(field),
+					Ascending: true,
+					StartTime: influxql.MinTime,
+					EndTime:   influxql.MaxTime,
+				},
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var count int
+			fitr := itr.(query.FloatIterator)
+			for {
+				p, err := fitr.Next()
+				if err != nil {
+					t.Fatal(err)
+				} else if p == nil {
+					break
+				}
+				count++
+			}
+			itr.Close()
+
+			exp := 3
+			switch field {
+			case "temp":
+				exp = 0
+			case "free":
+				exp = 1
+			}
+			if count != exp {
+				t.Fatalf("unexpected points of %s: got=%d exp=%d", field, count, exp)
+			}
+		}
+
+		if got, exp := s.Shard(1).SeriesN(), int64(4); got != exp {
+			t.Fatalf("unexpected series: got=%d exp=%d", got, exp)
+		}
+	}
+
+	for _, index := range tsdb.RegisteredIndexes() {
+		t.Run(index, func(t *testing.T) { test(t, index) })
+	}
+}
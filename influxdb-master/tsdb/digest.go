@@ -0,0 +1,20 @@
+package tsdb
+
+import "time"
+
+// DigestOptions are the options of a windowed digest of the series of a shard.
+type DigestOptions struct {
+	// MinTime and MaxTime bound the timestamps of the points digested.
+	MinTime, MaxTime int64
+
+	// Window is the duration of the time windows of a series that are
+	// digested independently, so that digests locate the points that differ.
+	Window time.Duration
+}
+
+// KeyRange is a time range of the points of a series field, identified by its
+// TSM key.
+type KeyRange struct {
+	Key      []byte
+	Min, Max int64
+}
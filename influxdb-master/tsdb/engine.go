@@ -36,6 +36,9 @@ type Engine interface {
 	SetEnabled(enabled bool)
 	SetCompactionsEnabled(enabled bool)
 	ScheduleFullCompaction() error
+	SetCompactionsPaused(paused bool)
+	CompactionState() CompactionState
+	StorageUsage(tagKey string) StorageUsage
 
 	WithLogger(*zap.Logger)
 
@@ -47,6 +50,9 @@ type Engine interface {
 	Restore(r io.Reader, basePath string) error
 	Import(r io.Reader, basePath string) error
 	Digest() (io.ReadCloser, int64, error)
+	WindowDigest(opts DigestOptions, w io.Writer) error
+	ExportKeyRanges(ranges []KeyRange, w io.Writer) error
+	DeleteKeyRanges(ranges []KeyRange) error
 
 	CreateIterator(ctx context.Context, measurement string, opt query.IteratorOptions) (query.Iterator, error)
 	CreateCursorIterator(ctx context.Context) (CursorIterator, error)
@@ -57,6 +63,8 @@ type Engine interface {
 	CreateSeriesListIfNotExists(keys, names [][]byte, tags []models.Tags) error
 	DeleteSeriesRange(itr SeriesIterator, min, max int64) error
 	DeleteSeriesRangeWithPredicate(itr SeriesIterator, predicate func(name []byte, tags models.Tags) (int64, int64, bool)) error
+	DeleteSeriesFieldsRange(keys [][]byte, min, max int64) error
+	HasPointsInRange(key []byte, min, max int64) bool
 
 	MeasurementsSketches() (estimator.Sketch, estimator.Sketch, error)
 	SeriesSketches() (estimator.Sketch, estimator.Sketch, error)
@@ -187,6 +195,16 @@ type EngineOptions struct {
 	SeriesIDSets   SeriesIDSets
 	FieldValidator FieldValidator
 
+	// CardinalityLimits returns the cardinality limits of the database of
+	// the shard. If no function is set, the cardinality is unlimited.
+	CardinalityLimits func() CardinalityLimits
+
+	// FloatEncodingRules are the parsed Config.FloatEncodings.
+	FloatEncodingRules []FloatEncodingRule
+
+	// CompactionPlannerRules are the parsed Config.CompactionPlanners.
+	CompactionPlannerRules []CompactionPlannerRule
+
 	OnNewEngine func(Engine)
 
 	FileStoreObserver FileStoreObserver
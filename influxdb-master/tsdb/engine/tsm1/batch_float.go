@@ -275,7 +275,18 @@ func init() {
 	}
 }
 
+// FloatArrayDecodeAll decodes the values encoded by FloatArrayEncodeAll or
+// FloatArrayEncode into buf, returning buf and any error encountered.
 func FloatArrayDecodeAll(b []byte, buf []float64) ([]float64, error) {
+	if len(b) > 0 {
+		switch b[0] >> 4 {
+		case floatCompressedByteStreamSplit:
+			return floatArrayDecodeByteStreamSplit(b, buf)
+		case floatCompressedQuantized:
+			return floatArrayDecodeQuantized(b, buf)
+		}
+	}
+
 	if len(b) < 9 {
 		return []float64{}, nil
 	}
@@ -286,7 +297,7 @@ func FloatArrayDecodeAll(b []byte, buf []float64) ([]float64, error) {
 		meaningfulN uint8  = 64 // meaningful bit count
 	)
 
-	// first byte is the compression type; always Gorilla
+	// first byte is the compression type; Gorilla from here on
 	b = b[1:]
 
 	val = binary.BigEndian.Uint64(b)
@@ -0,0 +1,216 @@
+package tsm1
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/pkg/file"
+)
+
+const (
+	// CacheStateFilename is the name of the file in which an engine without a WAL
+	// records the writes that are only held by its cache.
+	CacheStateFilename = "cache.state"
+)
+
+// UnpersistedWrites describes writes that are held by the cache of an engine
+// without a WAL and are not yet in a TSM file. These writes are lost if the
+// process stops before the cache is snapshotted.
+type UnpersistedWrites struct {
+	// FirstWrite and LastWrite are the wall clock times of the first and the
+	// last write that are not yet in a TSM file.
+	FirstWrite time.Time `json:"firstWrite"`
+	LastWrite  time.Time `json:"lastWrite"`
+
+	// MinTime and MaxTime are the minimum and maximum timestamps, in
+	// nanoseconds, of the points of these writes.
+	MinTime int64 `json:"minTime"`
+	MaxTime int64 `json:"maxTime"`
+
+	// Points is the number of points of these writes.
+	Points int64 `json:"points"`
+}
+
+// Empty returns true if w describes no writes.
+func (w UnpersistedWrites) Empty() bool {
+	return w.Points == 0
+}
+
+func (w UnpersistedWrites) merge(o UnpersistedWrites) UnpersistedWrites {
+	if w.Empty() {
+		return o
+	} else if o.Empty() {
+		return w
+	}
+	if o.FirstWrite.Before(w.FirstWrite) {
+		w.FirstWrite = o.FirstWrite
+	}
+	if o.LastWrite.After(w.LastWrite) {
+		w.LastWrite = o.LastWrite
+	}
+	if o.MinTime < w.MinTime {
+		w.MinTime = o.MinTime
+	}
+	if o.MaxTime > w.MaxTime {
+		w.MaxTime = o.MaxTime
+	}
+	w.Points += o.Points
+	return w
+}
+
+// cacheState tracks the writes held only by the cache of an engine without a
+// WAL, and records them in the shard directory so that the writes lost by a
+// crash can be reported when the engine is reopened.
+//
+// The state file is rewritten at most once per call to sync, so its accuracy
+// is bounded by the interval at which the engine calls sync. Writes never wait
+// for the state file to be synced.
+type cacheState struct {
+	mu   sync.Mutex
+	path string
+
+	// current are the writes since the last cache snapshot began, snapshot
+	// the writes of the cache snapshots that are not yet committed.
+	current  UnpersistedWrites
+	snapshot UnpersistedWrites
+
+	// gen is incremented by every change, synced is the gen the state file reflects.
+	gen, synced uint64
+
+	// syncMu serializes the writes of the state file.
+	syncMu sync.Mutex
+}
+
+func newCacheState(dir string) *cacheState {
+	return &cacheState{path: filepath.Join(dir, CacheStateFilename)}
+}
+
+// add records a write of values to the cache.
+func (s *cacheState) add(values map[string][]Value, now time.Time) {
+	w := UnpersistedWrites{FirstWrite: now, LastWrite: now, MinTime: math.MaxInt64, MaxTime: math.MinInt64}
+	for _, vs := range values {
+		for _, v := range vs {
+			ts := v.UnixNano()
+			if ts < w.MinTime {
+				w.MinTime = ts
+			}
+			if ts > w.MaxTime {
+				w.MaxTime = ts
+			}
+		}
+		w.Points += int64(len(vs))
+	}
+	if w.Empty() {
+		return
+	}
+
+	s.mu.Lock()
+	s.current = s.current.merge(w)
+	s.gen++
+	s.mu.Unlock()
+}
+
+// oldest returns the time of the oldest write not yet in a TSM file.
+func (s *cacheState) oldest() (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w := s.current.merge(s.snapshot)
+	return w.FirstWrite, !w.Empty()
+}
+
+// beginSnapshot is called when the cache is snapshotted.
+func (s *cacheState) beginSnapshot() {
+	s.mu.Lock()
+	// the values of a failed snapshot are part of the next snapshot
+	s.snapshot = s.snapshot.merge(s.current)
+	s.current = UnpersistedWrites{}
+	s.mu.Unlock()
+}
+
+// commitSnapshot is called once the cache snapshot is in TSM files.
+func (s *cacheState) commitSnapshot() {
+	s.mu.Lock()
+	s.snapshot = UnpersistedWrites{}
+	s.gen++
+	s.mu.Unlock()
+}
+
+// sync writes the state file if it is out of date, or removes it if all the
+// writes are in TSM files.
+func (s *cacheState) sync() error {
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+
+	s.mu.Lock()
+	gen := s.gen
+	w := s.current.merge(s.snapshot)
+	s.mu.Unlock()
+	if gen == s.synced {
+		return nil
+	}
+
+	if err := s.write(w); err != nil {
+		return err
+	}
+	s.synced = gen
+	return nil
+}
+
+func (s *cacheState) write(w UnpersistedWrites) error {
+	if w.Empty() {
+		if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	b, err := json.Marshal(w)
+	if err != nil {
+		return err
+	}
+	tmp := s.path + "." + TmpTSMFileExtension
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := file.RenameFile(tmp, s.path); err != nil {
+		return err
+	}
+	return file.SyncDir(filepath.Dir(s.path))
+}
+
+// recover returns the writes that the previous run of the engine did not
+// persist to TSM files, and removes the state file. These writes are lost.
+func (s *cacheState) recover() (UnpersistedWrites, error) {
+	b, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return UnpersistedWrites{}, nil
+	} else if err != nil {
+		return UnpersistedWrites{}, err
+	}
+
+	var w UnpersistedWrites
+	if err := json.Unmarshal(b, &w); err != nil {
+		return UnpersistedWrites{}, err
+	}
+	if err := os.Remove(s.path); err != nil {
+		return UnpersistedWrites{}, err
+	}
+	return w, nil
+}
@@ -0,0 +1,135 @@
+package tsm1
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheState(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cache_state")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	s := newCacheState(dir)
+	path := filepath.Join(dir, CacheStateFilename)
+	t0 := time.Unix(1000, 0)
+
+	_, ok := s.oldest()
+	require.False(t, ok)
+
+	s.add(map[string][]Value{
+		"cpu,host=a#!~#value": {NewValue(10, 1.0), NewValue(30, 2.0)},
+		"cpu,host=b#!~#value": {NewValue(20, 1.0)},
+	}, t0)
+	oldest, ok := s.oldest()
+	require.True(t, ok)
+	require.Equal(t, t0, oldest)
+
+	require.NoError(t, s.sync())
+	require.FileExists(t, path)
+
+	// writes received during a snapshot remain once it is committed
+	s.beginSnapshot()
+	s.add(map[string][]Value{"cpu,host=a#!~#value": {NewValue(40, 1.0)}}, t0.Add(time.Second))
+	s.commitSnapshot()
+	require.NoError(t, s.sync())
+
+	w, err := s.recover()
+	require.NoError(t, err)
+	require.True(t, w.FirstWrite.Equal(t0.Add(time.Second)))
+	require.True(t, w.LastWrite.Equal(t0.Add(time.Second)))
+	require.Equal(t, int64(40), w.MinTime)
+	require.Equal(t, int64(40), w.MaxTime)
+	require.Equal(t, int64(1), w.Points)
+	require.NoFileExists(t, path)
+
+	// the state file is removed once all the writes are in TSM files
+	require.NoError(t, s.sync())
+	s.beginSnapshot()
+	s.commitSnapshot()
+	require.NoError(t, s.sync())
+	require.NoFileExists(t, path)
+
+	w, err = s.recover()
+	require.NoError(t, err)
+	require.True(t, w.Empty())
+}
+
+func TestEngine_WALDisabled(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "shard_test")
+	require.NoError(t, err, "error creating temporary directory")
+	defer os.RemoveAll(tmpDir)
+
+	tmpShard := filepath.Join(tmpDir, "data", "db0", "autogen", "1")
+	tmpWal := filepath.Join(tmpDir, "wal", "db0", "autogen", "1")
+
+	sfile := NewSeriesFile(t, tmpDir)
+	defer sfile.Close()
+
+	opts := tsdb.NewEngineOptions()
+	opts.Config.WALDir = tmpWal
+	opts.Config.WALDisabledBuckets = []string{"db0"}
+	opts.SeriesIDSets = seriesIDSets([]*tsdb.SeriesIDSet{})
+
+	openEngine := func() (*tsdb.Shard, *Engine) {
+		sh := tsdb.NewShard(1, tmpShard, tmpWal, sfile, opts)
+		require.NoError(t, sh.Open(), "error opening shard")
+		e, err := sh.Engine()
+		require.NoError(t, err)
+		return sh, e.(*Engine)
+	}
+
+	// the shards of other buckets keep their WAL
+	other := tsdb.NewShard(2, filepath.Join(tmpDir, "data", "db1", "autogen", "2"), filepath.Join(tmpDir, "wal", "db1", "autogen", "2"), sfile, opts)
+	require.NoError(t, other.Open(), "error opening shard")
+	oe, err := other.Engine()
+	require.NoError(t, err)
+	require.NotNil(t, oe.(*Engine).WAL)
+	require.NoError(t, other.Close())
+
+	sh, e := openEngine()
+	points := make([]models.Point, 0, 100)
+	for i := 0; i < cap(points); i++ {
+		points = append(points, models.MustNewPoint(
+			"cpu",
+			models.NewTags(map[string]string{"host": "server"}),
+			map[string]interface{}{"value": 1.0},
+			time.Unix(int64(i), 0),
+		))
+	}
+	require.NoError(t, sh.WritePoints(points))
+	require.Nil(t, e.WAL)
+	require.NoError(t, e.cacheState.sync())
+	require.FileExists(t, filepath.Join(tmpShard, CacheStateFilename))
+
+	// closing the engine snapshots the cache
+	require.NoError(t, sh.Close())
+	require.NoFileExists(t, filepath.Join(tmpShard, CacheStateFilename))
+	_, err = os.Stat(tmpWal)
+	require.True(t, os.IsNotExist(err), "WAL directory must not be created")
+
+	sh, e = openEngine()
+	require.True(t, e.LostWrites().Empty())
+	require.Equal(t, 1, e.FileStore.Count())
+	require.NoError(t, sh.Close())
+
+	// simulate a crash of an engine holding writes in its cache
+	s := newCacheState(tmpShard)
+	s.add(map[string][]Value{"cpu,host=server#!~#value": {NewValue(100, 1.0), NewValue(200, 2.0)}}, time.Now())
+	require.NoError(t, s.sync())
+
+	sh, e = openEngine()
+	defer sh.Close()
+	lost := e.LostWrites()
+	require.Equal(t, int64(2), lost.Points)
+	require.Equal(t, int64(100), lost.MinTime)
+	require.Equal(t, int64(200), lost.MaxTime)
+	require.NoFileExists(t, filepath.Join(tmpShard, CacheStateFilename))
+}
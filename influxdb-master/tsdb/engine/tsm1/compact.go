@@ -131,6 +131,10 @@ type DefaultPlanner struct {
 	// filesInUse is the set of files that have been returned as part of a plan and might
 	// be being compacted.  Two plans should not return the same file at any given time.
 	filesInUse map[string]struct{}
+
+	// optimizeMinGenerations is the minimum number of generations of an optimize
+	// plan without tombstones, 4 if zero.
+	optimizeMinGenerations int
 }
 
 type fileStore interface {
@@ -387,10 +391,15 @@ func (c *DefaultPlanner) PlanOptimize() []CompactionGroup {
 		}
 	}
 
+	minGenerations := c.optimizeMinGenerations
+	if minGenerations == 0 {
+		minGenerations = 4
+	}
+
 	var cGroups []CompactionGroup
 	for _, group := range levelGroups {
 		// Skip the group if it's not worthwhile to optimize it
-		if len(group) < 4 && !group.hasTombstones() {
+		if len(group) < minGenerations && !group.hasTombstones() {
 			continue
 		}
 
@@ -705,6 +714,11 @@ type Compactor struct {
 	// RateLimit is the limit for disk writes for all concurrent compactions.
 	RateLimit limiter.Rate
 
+	// FloatEncoding selects the encoding of the float values of a key. Float
+	// blocks are re-encoded when they are written if their encoding differs.
+	// If nil, blocks are written as they are encoded.
+	FloatEncoding FloatEncodingFunc
+
 	formatFileName FormatFileNameFunc
 	parseFileName  ParseFileNameFunc
 
@@ -1158,6 +1172,13 @@ func (c *Compactor) write(path string, iter KeyIterator, throttle bool) (err err
 			return fmt.Errorf("invalid index entry for block. min=%d, max=%d", minTime, maxTime)
 		}
 
+		// Encode float values with the compression selected for the key.
+		if c.FloatEncoding != nil && len(block) > 0 && block[0] == BlockFloat64 {
+			if block, err = reencodeFloatBlock(block, c.FloatEncoding(key)); err != nil {
+				return err
+			}
+		}
+
 		// Write the key and value
 		if err := w.WriteBlock(key, minTime, maxTime, block); err == ErrMaxBlocksExceeded {
 			if err := w.WriteIndex(); err != nil {
@@ -0,0 +1,171 @@
+package tsm1
+
+import (
+	"sort"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/tsdb"
+)
+
+// NewCompactionPlanner returns the CompactionPlanner of rule. writeColdDuration
+// is the default duration without writes after which the leveled planner fully
+// compacts a shard.
+func NewCompactionPlanner(fs fileStore, rule tsdb.CompactionPlannerRule, writeColdDuration time.Duration) CompactionPlanner {
+	switch rule.Planner {
+	case tsdb.CompactionPlannerTimeWindow:
+		return NewTimeWindowPlanner(fs, rule.Window)
+	case tsdb.CompactionPlannerAggressiveOptimize:
+		coldDuration := rule.ColdDuration
+		if coldDuration == 0 {
+			coldDuration = tsdb.DefaultAggressiveOptimizeColdDuration
+		}
+		return NewAggressiveOptimizePlanner(fs, coldDuration)
+	default:
+		if rule.ColdDuration > 0 {
+			writeColdDuration = rule.ColdDuration
+		}
+		return NewDefaultPlanner(fs, writeColdDuration)
+	}
+}
+
+// NewAggressiveOptimizePlanner returns a DefaultPlanner for rarely written
+// shards. It fully compacts a shard once it has not been written for
+// coldDuration, and optimizes as few as 2 generations of higher level files.
+func NewAggressiveOptimizePlanner(fs fileStore, coldDuration time.Duration) *DefaultPlanner {
+	p := NewDefaultPlanner(fs, coldDuration)
+	p.optimizeMinGenerations = 2
+	return p
+}
+
+// TimeWindowPlanner implements CompactionPlanner for shards of append-only
+// series. Level compactions are planned as by the DefaultPlanner, but the
+// higher level generations are compacted by time window: consecutive
+// generations whose newest points are in the same window are compacted
+// together, and generations of different windows never are. Once a window is
+// closed, i.e. the current time is past its end, its generations are compacted
+// into a single generation which is not rewritten again.
+//
+// Shards are only fully compacted by ForceFull, not when they are cold.
+type TimeWindowPlanner struct {
+	*DefaultPlanner
+
+	window time.Duration
+	now    func() time.Time
+}
+
+// NewTimeWindowPlanner returns a TimeWindowPlanner compacting by window.
+func NewTimeWindowPlanner(fs fileStore, window time.Duration) *TimeWindowPlanner {
+	return &TimeWindowPlanner{
+		DefaultPlanner: NewDefaultPlanner(fs, 0),
+		window:         window,
+		now:            time.Now,
+	}
+}
+
+// Plan returns the generations of the closed windows that are not yet
+// compacted into a single generation, or a full plan if ForceFull was called.
+func (c *TimeWindowPlanner) Plan(lastWrite time.Time) []CompactionGroup {
+	c.mu.RLock()
+	forceFull := c.forceFull
+	c.mu.RUnlock()
+
+	if forceFull {
+		return c.DefaultPlanner.Plan(lastWrite)
+	}
+	return c.planWindows(true, 2)
+}
+
+// PlanOptimize returns runs of at least 4 generations of the same window,
+// bounding the number of files of the windows that are not closed yet.
+func (c *TimeWindowPlanner) PlanOptimize() []CompactionGroup {
+	c.mu.RLock()
+	forceFull := c.forceFull
+	c.mu.RUnlock()
+
+	if forceFull {
+		return nil
+	}
+	return c.planWindows(false, 4)
+}
+
+// windowOf returns the window of the newest point of a generation.
+func (c *TimeWindowPlanner) windowOf(g *tsmGeneration) int64 {
+	max := g.files[0].MaxTime
+	for _, f := range g.files[1:] {
+		if f.MaxTime > max {
+			max = f.MaxTime
+		}
+	}
+	return floorDiv(max, int64(c.window))
+}
+
+func (c *TimeWindowPlanner) planWindows(closedOnly bool, minGenerations int) []CompactionGroup {
+	generations := c.findGenerations(true)
+	if len(generations) <= 1 && !generations.hasTombstones() {
+		return nil
+	}
+
+	open := floorDiv(c.now().UnixNano(), int64(c.window))
+
+	var (
+		runs      []tsmGenerations
+		run       tsmGenerations
+		runWindow int64
+	)
+	endRun := func() {
+		if len(run) >= minGenerations || run.hasTombstones() {
+			runs = append(runs, run)
+		}
+		run = nil
+	}
+
+	for _, g := range generations {
+		// Lower level generations are left to the level planners. They end a
+		// run, as only consecutive generations may be compacted together.
+		if g.level() <= 3 {
+			endRun()
+			continue
+		}
+
+		w := c.windowOf(g)
+		if closedOnly && w >= open {
+			endRun()
+			continue
+		}
+		if len(run) > 0 && w != runWindow {
+			endRun()
+		}
+		run, runWindow = append(run, g), w
+	}
+	endRun()
+
+	if len(runs) == 0 {
+		return nil
+	}
+
+	groups := make([]CompactionGroup, 0, len(runs))
+	for _, run := range runs {
+		var group CompactionGroup
+		for _, g := range run {
+			for _, f := range g.files {
+				group = append(group, f.Path)
+			}
+		}
+		sort.Strings(group)
+		groups = append(groups, group)
+	}
+
+	if !c.acquire(groups) {
+		return nil
+	}
+	return groups
+}
+
+// floorDiv returns x divided by y rounded towards negative infinity.
+func floorDiv(x, y int64) int64 {
+	q := x / y
+	if x%y != 0 && (x < 0) != (y < 0) {
+		q--
+	}
+	return q
+}
@@ -0,0 +1,120 @@
+package tsm1_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/influxdata/influxdb/v2/tsdb/engine/tsm1"
+)
+
+func TestTimeWindowPlanner_Plan(t *testing.T) {
+	h0 := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) int64 { return h0.Add(d).UnixNano() }
+
+	data := []tsm1.FileStat{
+		{Path: "01-04.tsm1", MinTime: at(0), MaxTime: at(10 * time.Minute)},
+		{Path: "02-04.tsm1", MinTime: at(10 * time.Minute), MaxTime: at(50 * time.Minute)},
+		// straddles two windows, belongs to the window of its newest point
+		{Path: "03-04.tsm1", MinTime: at(50 * time.Minute), MaxTime: at(65 * time.Minute)},
+		{Path: "04-04.tsm1", MinTime: at(65 * time.Minute), MaxTime: at(80 * time.Minute)},
+		{Path: "05-04.tsm1", MinTime: at(80 * time.Minute), MaxTime: at(90 * time.Minute)},
+		// left to the level planners
+		{Path: "06-01.tsm1", MinTime: at(90 * time.Minute), MaxTime: at(100 * time.Minute)},
+		{Path: "07-04.tsm1", MinTime: at(100 * time.Minute), MaxTime: at(110 * time.Minute)},
+		// the window is not closed yet
+		{Path: "08-04.tsm1", MinTime: time.Now().UnixNano(), MaxTime: time.Now().UnixNano()},
+		{Path: "09-04.tsm1", MinTime: time.Now().UnixNano(), MaxTime: time.Now().UnixNano()},
+	}
+
+	cp := tsm1.NewTimeWindowPlanner(&fakeFileStore{
+		PathsFn: func() []tsm1.FileStat { return data },
+	}, time.Hour)
+
+	tsm := cp.Plan(time.Now())
+	exp := []tsm1.CompactionGroup{
+		{"01-04.tsm1", "02-04.tsm1"},
+		{"03-04.tsm1", "04-04.tsm1", "05-04.tsm1"},
+	}
+	if !reflect.DeepEqual(tsm, exp) {
+		t.Fatalf("unexpected plan: got %v, exp %v", tsm, exp)
+	}
+
+	// planned files are not planned again until they are released
+	if tsm := cp.Plan(time.Now()); len(tsm) != 0 {
+		t.Fatalf("unexpected plan: %v", tsm)
+	}
+	cp.Release(exp)
+
+	// too few generations of the open window to optimize
+	if tsm := cp.PlanOptimize(); len(tsm) != 0 {
+		t.Fatalf("unexpected optimize plan: %v", tsm)
+	}
+
+	cp.ForceFull()
+	if tsm := cp.Plan(time.Now()); len(tsm) != 1 || len(tsm[0]) != len(data) {
+		t.Fatalf("expected a full plan, got %v", tsm)
+	}
+}
+
+func TestTimeWindowPlanner_Plan_Tombstones(t *testing.T) {
+	at := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC).UnixNano()
+	data := []tsm1.FileStat{
+		{Path: "01-04.tsm1", MinTime: at, MaxTime: at, HasTombstone: true},
+		{Path: "02-04.tsm1", MinTime: at + int64(2*time.Hour), MaxTime: at + int64(2*time.Hour)},
+	}
+
+	cp := tsm1.NewTimeWindowPlanner(&fakeFileStore{
+		PathsFn: func() []tsm1.FileStat { return data },
+	}, time.Hour)
+
+	exp := []tsm1.CompactionGroup{{"01-04.tsm1"}}
+	if tsm := cp.Plan(time.Now()); !reflect.DeepEqual(tsm, exp) {
+		t.Fatalf("unexpected plan: got %v, exp %v", tsm, exp)
+	}
+}
+
+func TestAggressiveOptimizePlanner_PlanOptimize(t *testing.T) {
+	data := []tsm1.FileStat{
+		{Path: "01-04.tsm1", Size: 1024 * 1024},
+		{Path: "02-04.tsm1", Size: 1024 * 1024},
+	}
+	fs := &fakeFileStore{
+		PathsFn: func() []tsm1.FileStat { return data },
+	}
+
+	if tsm := tsm1.NewDefaultPlanner(fs, tsdb.DefaultCompactFullWriteColdDuration).PlanOptimize(); len(tsm) != 0 {
+		t.Fatalf("unexpected default optimize plan: %v", tsm)
+	}
+
+	cp := tsm1.NewAggressiveOptimizePlanner(fs, time.Minute)
+	exp := []tsm1.CompactionGroup{{"01-04.tsm1", "02-04.tsm1"}}
+	if tsm := cp.PlanOptimize(); !reflect.DeepEqual(tsm, exp) {
+		t.Fatalf("unexpected optimize plan: got %v, exp %v", tsm, exp)
+	}
+	cp.Release(exp)
+
+	// fully compacted once cold
+	if tsm := cp.Plan(time.Now().Add(-2 * time.Minute)); !reflect.DeepEqual(tsm, exp) {
+		t.Fatalf("unexpected plan: got %v, exp %v", tsm, exp)
+	}
+}
+
+func TestNewCompactionPlanner(t *testing.T) {
+	fs := &fakeFileStore{PathsFn: func() []tsm1.FileStat { return nil }}
+
+	for _, tt := range []struct {
+		rule tsdb.CompactionPlannerRule
+		want interface{}
+	}{
+		{rule: tsdb.CompactionPlannerRule{Planner: tsdb.CompactionPlannerLeveled}, want: &tsm1.DefaultPlanner{}},
+		{rule: tsdb.CompactionPlannerRule{Planner: tsdb.CompactionPlannerTimeWindow, Window: time.Hour}, want: &tsm1.TimeWindowPlanner{}},
+		{rule: tsdb.CompactionPlannerRule{Planner: tsdb.CompactionPlannerAggressiveOptimize}, want: &tsm1.DefaultPlanner{}},
+	} {
+		got := tsm1.NewCompactionPlanner(fs, tt.rule, tsdb.DefaultCompactFullWriteColdDuration)
+		if reflect.TypeOf(got) != reflect.TypeOf(tt.want) {
+			t.Fatalf("%s: got %T, want %T", tt.rule.Planner, got, tt.want)
+		}
+	}
+}
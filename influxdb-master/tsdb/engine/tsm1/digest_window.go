@@ -0,0 +1,398 @@
+package tsm1
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/pkg/escape"
+	"github.com/influxdata/influxdb/v2/tsdb"
+)
+
+// A windowed digest describes the points of each TSM key by fixed time
+// windows aligned to the Unix epoch, rather than by TSM block as Digest does,
+// so it only depends on the points and not on how they are compacted.
+//
+// It is written with a DigestWriter: every DigestTimeRange of a key is a window
+// holding at least one point, with Min and Max the first and last nanosecond of
+// the window, N the number of points, saturated at math.MaxUint16, and CRC the
+// sum of the CRC-32 checksums of the points. As the sum does not depend on the
+// order of the points, the digests of the shards of a bucket can be merged
+// with MergeWindowDigests.
+
+// WindowDigest writes a windowed digest of the points of readers to w. The
+// readers must be ordered by generation, as the points of the later readers
+// replace the points of the earlier readers with the same timestamp.
+func WindowDigest(readers []*TSMReader, opts tsdb.DigestOptions, w io.Writer) error {
+	if opts.Window <= 0 {
+		return errors.New("digest window must be positive")
+	}
+
+	manifest := &DigestManifest{Entries: make(DigestManifestEntries, 0, len(readers))}
+	files := make([]TSMFile, 0, len(readers))
+	for _, r := range readers {
+		manifest.Entries = append(manifest.Entries, NewDigestManifestEntry(r.Path(), int64(r.Size())))
+		files = append(files, r)
+	}
+
+	dw, err := NewDigestWriter(nopWriteCloser{w})
+	if err != nil {
+		return err
+	}
+	if err := dw.WriteManifest(manifest); err != nil {
+		return err
+	}
+
+	ki := newMergeKeyIterator(files, nil)
+	for ki.Next() {
+		key, _ := ki.Read()
+		values, err := ReadKeyValues(readers, key, opts.MinTime, opts.MaxTime)
+		if err != nil {
+			return err
+		}
+		if len(values) == 0 {
+			continue
+		}
+		if err := dw.WriteTimeSpan(string(key), digestWindows(values, int64(opts.Window))); err != nil {
+			return err
+		}
+	}
+	return dw.Close()
+}
+
+// ReadKeyValues returns the points of key between min and max, inclusive, of
+// readers ordered by generation, with their tombstones applied.
+func ReadKeyValues(readers []*TSMReader, key []byte, min, max int64) (Values, error) {
+	var values Values
+	for _, r := range readers {
+		v, err := r.ReadAll(key)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v...)
+	}
+	return values.Deduplicate().Include(min, max), nil
+}
+
+// digestWindows returns the windows of values, which must be sorted.
+func digestWindows(values Values, window int64) *DigestTimeSpan {
+	ts := &DigestTimeSpan{}
+	var buf []byte
+	for _, v := range values {
+		start := floorDiv(v.UnixNano(), window) * window
+		if n := len(ts.Ranges); n == 0 || ts.Ranges[n-1].Min != start {
+			end := int64(math.MaxInt64)
+			if start <= math.MaxInt64-window {
+				end = start + window - 1
+			}
+			ts.Ranges = append(ts.Ranges, DigestTimeRange{Min: start, Max: end})
+		}
+
+		r := &ts.Ranges[len(ts.Ranges)-1]
+		buf = appendDigestValue(buf[:0], v)
+		r.CRC += crc32.ChecksumIEEE(buf)
+		r.N = addDigestCount(r.N, 1)
+	}
+	return ts
+}
+
+// appendDigestValue appends the timestamp, type and value of v to buf.
+func appendDigestValue(buf []byte, v Value) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], uint64(v.UnixNano()))
+	buf = append(buf, b[:]...)
+
+	switch v := v.Value().(type) {
+	case float64:
+		binary.BigEndian.PutUint64(b[:], math.Float64bits(v))
+		buf = append(append(buf, BlockFloat64), b[:]...)
+	case int64:
+		binary.BigEndian.PutUint64(b[:], uint64(v))
+		buf = append(append(buf, BlockInteger), b[:]...)
+	case uint64:
+		binary.BigEndian.PutUint64(b[:], v)
+		buf = append(append(buf, BlockUnsigned), b[:]...)
+	case bool:
+		buf = append(buf, BlockBoolean)
+		if v {
+			buf = append(buf, 1)
+		} else {
+			buf = append(buf, 0)
+		}
+	case string:
+		buf = append(append(buf, BlockString), v...)
+	}
+	return buf
+}
+
+// addDigestCount adds n to the point count of a window, which saturates at
+// the largest count a digest stores.
+func addDigestCount(count, n int) int {
+	if count += n; count > math.MaxUint16 {
+		count = math.MaxUint16
+	}
+	return count
+}
+
+// MergeWindowDigests merges the windowed digests of readers, typically of the
+// shards of a bucket, into a single windowed digest written to w.
+func MergeWindowDigests(w io.Writer, readers ...*DigestReader) error {
+	heads := make([]digestHead, 0, len(readers))
+	for _, r := range readers {
+		h := digestHead{r: r}
+		if err := h.next(); err != nil {
+			return err
+		}
+		if h.ts != nil {
+			heads = append(heads, h)
+		}
+	}
+
+	dw, err := NewDigestWriter(nopWriteCloser{w})
+	if err != nil {
+		return err
+	}
+	if err := dw.WriteManifest(&DigestManifest{Entries: DigestManifestEntries{}}); err != nil {
+		return err
+	}
+
+	for len(heads) > 0 {
+		key := heads[0].key
+		for _, h := range heads[1:] {
+			if h.key < key {
+				key = h.key
+			}
+		}
+
+		var ts *DigestTimeSpan
+		for i := 0; i < len(heads); {
+			h := &heads[i]
+			if h.key != key {
+				i++
+				continue
+			}
+			ts = mergeDigestTimeSpans(ts, h.ts)
+			if err := h.next(); err != nil {
+				return err
+			}
+			if h.ts == nil {
+				heads = append(heads[:i], heads[i+1:]...)
+				continue
+			}
+			i++
+		}
+
+		if err := dw.WriteTimeSpan(key, ts); err != nil {
+			return err
+		}
+	}
+	return dw.Close()
+}
+
+// mergeDigestTimeSpans merges the windows of two windowed digests of a key.
+func mergeDigestTimeSpans(a, b *DigestTimeSpan) *DigestTimeSpan {
+	if a == nil {
+		return b
+	}
+
+	ts := &DigestTimeSpan{Ranges: make([]DigestTimeRange, 0, len(a.Ranges)+len(b.Ranges))}
+	i, j := 0, 0
+	for i < len(a.Ranges) || j < len(b.Ranges) {
+		switch {
+		case j == len(b.Ranges) || (i < len(a.Ranges) && a.Ranges[i].Min < b.Ranges[j].Min):
+			ts.Ranges = append(ts.Ranges, a.Ranges[i])
+			i++
+		case i == len(a.Ranges) || b.Ranges[j].Min < a.Ranges[i].Min:
+			ts.Ranges = append(ts.Ranges, b.Ranges[j])
+			j++
+		default:
+			r := a.Ranges[i]
+			r.N = addDigestCount(r.N, b.Ranges[j].N)
+			r.CRC += b.Ranges[j].CRC
+			ts.Ranges = append(ts.Ranges, r)
+			i++
+			j++
+		}
+	}
+	return ts
+}
+
+// DigestDiff is a time range of a TSM key whose points differ between two
+// windowed digests. SourceN and TargetN are the number of points of the range
+// in each digest.
+type DigestDiff struct {
+	Key              string
+	Min, Max         int64
+	SourceN, TargetN int
+}
+
+// DiffWindowDigests compares two windowed digests of the same window and
+// calls fn with the time ranges of the keys that differ, in key order. The
+// consecutive windows of a key that differ are reported as a single range.
+func DiffWindowDigests(source, target *DigestReader, fn func(DigestDiff) error) error {
+	s, t := digestHead{r: source}, digestHead{r: target}
+	if err := s.next(); err != nil {
+		return err
+	}
+	if err := t.next(); err != nil {
+		return err
+	}
+
+	for s.ts != nil || t.ts != nil {
+		var (
+			key    string
+			sr, tr []DigestTimeRange
+		)
+		switch {
+		case t.ts == nil || (s.ts != nil && s.key < t.key):
+			key, sr = s.key, s.ts.Ranges
+			if err := s.next(); err != nil {
+				return err
+			}
+		case s.ts == nil || t.key < s.key:
+			key, tr = t.key, t.ts.Ranges
+			if err := t.next(); err != nil {
+				return err
+			}
+		default:
+			key, sr, tr = s.key, s.ts.Ranges, t.ts.Ranges
+			if err := s.next(); err != nil {
+				return err
+			}
+			if err := t.next(); err != nil {
+				return err
+			}
+		}
+
+		if err := diffDigestRanges(key, sr, tr, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// diffDigestRanges calls fn with the ranges of windows of a key that differ.
+func diffDigestRanges(key string, source, target []DigestTimeRange, fn func(DigestDiff) error) error {
+	var (
+		diff    DigestDiff
+		pending bool
+	)
+	add := func(min, max int64, sourceN, targetN int) {
+		if !pending {
+			diff, pending = DigestDiff{Key: key, Min: min}, true
+		}
+		diff.Max = max
+		diff.SourceN = addDigestCount(diff.SourceN, sourceN)
+		diff.TargetN = addDigestCount(diff.TargetN, targetN)
+	}
+	flush := func() error {
+		if !pending {
+			return nil
+		}
+		pending = false
+		return fn(diff)
+	}
+
+	i, j := 0, 0
+	for i < len(source) || j < len(target) {
+		switch {
+		case j == len(target) || (i < len(source) && source[i].Min < target[j].Min):
+			add(source[i].Min, source[i].Max, source[i].N, 0)
+			i++
+		case i == len(source) || target[j].Min < source[i].Min:
+			add(target[j].Min, target[j].Max, 0, target[j].N)
+			j++
+		default:
+			if source[i].N != target[j].N || source[i].CRC != target[j].CRC {
+				add(source[i].Min, source[i].Max, source[i].N, target[j].N)
+			} else if err := flush(); err != nil {
+				return err
+			}
+			i++
+			j++
+		}
+	}
+	return flush()
+}
+
+// digestHead is the current key of a DigestReader.
+type digestHead struct {
+	r   *DigestReader
+	key string
+	ts  *DigestTimeSpan
+}
+
+// next reads the next key, setting ts to nil at the end of the digest.
+func (h *digestHead) next() error {
+	key, ts, err := h.r.ReadTimeSpan()
+	if err == io.EOF {
+		h.key, h.ts = "", nil
+		return nil
+	} else if err != nil {
+		return err
+	}
+	h.key, h.ts = key, ts
+	return nil
+}
+
+// ExportKeyRanges writes the points of the key ranges of readers, ordered by
+// generation, to w as line protocol.
+func ExportKeyRanges(readers []*TSMReader, ranges []tsdb.KeyRange, w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	var buf []byte
+	for _, r := range ranges {
+		values, err := ReadKeyValues(readers, r.Key, r.Min, r.Max)
+		if err != nil {
+			return err
+		}
+		for _, v := range values {
+			buf = AppendLineProtocol(buf[:0], r.Key, v)
+			if _, err := bw.Write(buf); err != nil {
+				return err
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// AppendLineProtocol appends the line protocol of a point of a TSM key to buf.
+func AppendLineProtocol(buf []byte, key []byte, v Value) []byte {
+	seriesKey, field := SeriesAndFieldFromCompositeKey(key)
+	buf = append(buf, seriesKey...)
+	buf = append(buf, ' ')
+	buf = append(buf, escape.Bytes(field)...)
+	buf = append(buf, '=')
+
+	switch v := v.Value().(type) {
+	case float64:
+		buf = strconv.AppendFloat(buf, v, 'g', -1, 64)
+	case int64:
+		buf = strconv.AppendInt(buf, v, 10)
+		buf = append(buf, 'i')
+	case uint64:
+		buf = strconv.AppendUint(buf, v, 10)
+		buf = append(buf, 'u')
+	case bool:
+		buf = strconv.AppendBool(buf, v)
+	case string:
+		buf = append(buf, '"')
+		buf = append(buf, models.EscapeStringField(v)...)
+		buf = append(buf, '"')
+	}
+
+	buf = append(buf, ' ')
+	buf = strconv.AppendInt(buf, v.UnixNano(), 10)
+	return append(buf, '\n')
+}
+
+// nopWriteCloser does not close the writer of a DigestWriter.
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
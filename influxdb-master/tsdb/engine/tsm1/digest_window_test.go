@@ -0,0 +1,204 @@
+package tsm1_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/influxdata/influxdb/v2/tsdb/engine/tsm1"
+)
+
+func mustWindowDigest(t *testing.T, readers []*tsm1.TSMReader, opts tsdb.DigestOptions) *tsm1.DigestReader {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := tsm1.WindowDigest(readers, opts, &buf); err != nil {
+		t.Fatalf("WindowDigest error: %v", err)
+	}
+	r, err := tsm1.NewDigestReader(ioutil.NopCloser(&buf))
+	if err != nil {
+		t.Fatalf("NewDigestReader error: %v", err)
+	}
+	return r
+}
+
+func TestWindowDigest_Diff(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	opts := tsdb.DigestOptions{MinTime: 0, MaxTime: 100, Window: 10 * time.Nanosecond}
+
+	// the source is sharded and compacted differently than the target
+	shard1 := MustTSMReader(dir, 1, map[string][]tsm1.Value{
+		"cpu,host=A#!~#value": {tsm1.NewValue(1, 1.0), tsm1.NewValue(12, 2.0)},
+		"cpu,host=B#!~#value": {tsm1.NewValue(5, int64(1))},
+	})
+	defer shard1.Close()
+	shard2a := MustTSMReader(dir, 2, map[string][]tsm1.Value{
+		"cpu,host=A#!~#value": {tsm1.NewValue(55, 3.0)},
+	})
+	defer shard2a.Close()
+	shard2b := MustTSMReader(dir, 3, map[string][]tsm1.Value{
+		"cpu,host=A#!~#value": {tsm1.NewValue(55, 5.0), tsm1.NewValue(58, 4.0)},
+	})
+	defer shard2b.Close()
+
+	target := MustTSMReader(dir, 4, map[string][]tsm1.Value{
+		"cpu,host=A#!~#value": {tsm1.NewValue(1, 1.0), tsm1.NewValue(12, 2.5), tsm1.NewValue(55, 5.0), tsm1.NewValue(58, 4.0)},
+		"cpu,host=C#!~#value": {tsm1.NewValue(99, true)},
+	})
+	defer target.Close()
+
+	var merged bytes.Buffer
+	d1 := mustWindowDigest(t, []*tsm1.TSMReader{shard1}, opts)
+	d2 := mustWindowDigest(t, []*tsm1.TSMReader{shard2a, shard2b}, opts)
+	if err := tsm1.MergeWindowDigests(&merged, d1, d2); err != nil {
+		t.Fatalf("MergeWindowDigests error: %v", err)
+	}
+	source, err := tsm1.NewDigestReader(ioutil.NopCloser(&merged))
+	if err != nil {
+		t.Fatalf("NewDigestReader error: %v", err)
+	}
+
+	var got []tsm1.DigestDiff
+	if err := tsm1.DiffWindowDigests(source, mustWindowDigest(t, []*tsm1.TSMReader{target}, opts), func(d tsm1.DigestDiff) error {
+		got = append(got, d)
+		return nil
+	}); err != nil {
+		t.Fatalf("DiffWindowDigests error: %v", err)
+	}
+
+	exp := []tsm1.DigestDiff{
+		{Key: "cpu,host=A#!~#value", Min: 10, Max: 19, SourceN: 1, TargetN: 1},
+		{Key: "cpu,host=B#!~#value", Min: 0, Max: 9, SourceN: 1, TargetN: 0},
+		{Key: "cpu,host=C#!~#value", Min: 90, Max: 99, SourceN: 0, TargetN: 1},
+	}
+	if !reflect.DeepEqual(got, exp) {
+		t.Fatalf("diff mismatch:\ngot %+v\nexp %+v", got, exp)
+	}
+}
+
+func TestExportKeyRanges(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	r1 := MustTSMReader(dir, 1, map[string][]tsm1.Value{
+		"cpu,host=A#!~#value":     {tsm1.NewValue(1, 1.5), tsm1.NewValue(12, 2.0)},
+		"cpu,host=A#!~#msg":       {tsm1.NewValue(3, `say "hi"`)},
+		"cpu,host=A#!~#the count": {tsm1.NewValue(4, int64(7))},
+	})
+	defer r1.Close()
+	r2 := MustTSMReader(dir, 2, map[string][]tsm1.Value{
+		"cpu,host=A#!~#value": {tsm1.NewValue(1, 3.0)},
+	})
+	defer r2.Close()
+
+	var buf bytes.Buffer
+	if err := tsm1.ExportKeyRanges([]*tsm1.TSMReader{r1, r2}, []tsdb.KeyRange{
+		{Key: []byte("cpu,host=A#!~#value"), Min: 0, Max: 9},
+		{Key: []byte("cpu,host=A#!~#msg"), Min: 0, Max: 9},
+		{Key: []byte("cpu,host=A#!~#the count"), Min: 0, Max: 9},
+		{Key: []byte("cpu,host=B#!~#value"), Min: 0, Max: 9},
+	}, &buf); err != nil {
+		t.Fatalf("ExportKeyRanges error: %v", err)
+	}
+
+	exp := "cpu,host=A value=3 1\n" +
+		"cpu,host=A msg=\"say \\\"hi\\\"\" 3\n" +
+		"cpu,host=A the\\ count=7i 4\n"
+	if got := buf.String(); got != exp {
+		t.Fatalf("export mismatch:\ngot %q\nexp %q", got, exp)
+	}
+}
+
+// Ensure that deleting the ranges that differ from the target and writing the
+// points exported from the source for the same ranges makes the copies match.
+func TestEngine_RepairDigestRanges(t *testing.T) {
+	for _, index := range tsdb.RegisteredIndexes() {
+		t.Run(index, func(t *testing.T) {
+			source := MustOpenEngine(t, index)
+			defer source.Close()
+			target := MustOpenEngine(t, index)
+			defer target.Close()
+
+			if err := source.WritePointsString(
+				"cpu,host=A value=1 1",
+				"cpu,host=A value=2 12",
+				"cpu,host=A value=4 58",
+				"cpu,host=B value=1 5",
+			); err != nil {
+				t.Fatal(err)
+			}
+			// the target misses a series, and has a point with another value
+			// and points that are not in the source
+			if err := target.WritePointsString(
+				"cpu,host=A value=1 1",
+				"cpu,host=A value=2.5 12",
+				"cpu,host=A value=3 15",
+				"cpu,host=A value=4 58",
+				"cpu,host=C value=9 99",
+			); err != nil {
+				t.Fatal(err)
+			}
+
+			opts := tsdb.DigestOptions{MinTime: 0, MaxTime: 100, Window: 10 * time.Nanosecond}
+			diff := func() []tsm1.DigestDiff {
+				t.Helper()
+
+				digest := func(e *Engine) *tsm1.DigestReader {
+					var buf bytes.Buffer
+					if err := e.WindowDigest(opts, &buf); err != nil {
+						t.Fatalf("WindowDigest error: %v", err)
+					}
+					r, err := tsm1.NewDigestReader(ioutil.NopCloser(&buf))
+					if err != nil {
+						t.Fatalf("NewDigestReader error: %v", err)
+					}
+					return r
+				}
+
+				var diffs []tsm1.DigestDiff
+				if err := tsm1.DiffWindowDigests(digest(source), digest(target), func(d tsm1.DigestDiff) error {
+					diffs = append(diffs, d)
+					return nil
+				}); err != nil {
+					t.Fatalf("DiffWindowDigests error: %v", err)
+				}
+				return diffs
+			}
+
+			diffs := diff()
+			exp := []tsm1.DigestDiff{
+				{Key: "cpu,host=A#!~#value", Min: 10, Max: 19, SourceN: 1, TargetN: 2},
+				{Key: "cpu,host=B#!~#value", Min: 0, Max: 9, SourceN: 1, TargetN: 0},
+				{Key: "cpu,host=C#!~#value", Min: 90, Max: 99, SourceN: 0, TargetN: 1},
+			}
+			if !reflect.DeepEqual(diffs, exp) {
+				t.Fatalf("diff mismatch:\ngot %+v\nexp %+v", diffs, exp)
+			}
+
+			ranges := make([]tsdb.KeyRange, 0, len(diffs))
+			for _, d := range diffs {
+				ranges = append(ranges, tsdb.KeyRange{Key: []byte(d.Key), Min: d.Min, Max: d.Max})
+			}
+			var lp bytes.Buffer
+			if err := source.ExportKeyRanges(ranges, &lp); err != nil {
+				t.Fatalf("ExportKeyRanges error: %v", err)
+			}
+			if err := target.DeleteKeyRanges(ranges); err != nil {
+				t.Fatalf("DeleteKeyRanges error: %v", err)
+			}
+			if err := target.WritePointsString(lp.String()); err != nil {
+				t.Fatal(err)
+			}
+
+			if diffs := diff(); len(diffs) != 0 {
+				t.Fatalf("the digests still differ after the repair: %+v", diffs)
+			}
+		})
+	}
+}
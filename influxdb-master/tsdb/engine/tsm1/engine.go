@@ -167,6 +167,12 @@ type Engine struct {
 	CompactionPlan CompactionPlanner
 	FileStore      *FileStore
 
+	// plannerName is the name of the compaction planner, reported by CompactionState.
+	plannerName string
+
+	// compactionsPaused is non-zero while level and full compactions are paused.
+	compactionsPaused int32
+
 	MaxPointsPerBlock int
 
 	// CacheFlushMemorySizeThreshold specifies the minimum size threshold for
@@ -182,6 +188,21 @@ type Engine struct {
 	// writes will only exist in the cache and can be lost if a snapshot has not occurred.
 	WALEnabled bool
 
+	// CacheSnapshotInterval specifies, when the WAL is disabled, the maximum
+	// age of a write held by the cache before the engine writes a snapshot
+	// of the cache to a TSM file. It bounds the writes lost by a crash.
+	CacheSnapshotInterval time.Duration
+
+	// walPath is the path of the WAL, used to replay segments left over by
+	// a previous run with the WAL enabled.
+	walPath string
+
+	// cacheState tracks the writes held only by the cache when the WAL is disabled.
+	cacheState *cacheState
+
+	// lostWrites are the writes lost by the previous run of the engine when the WAL is disabled.
+	lostWrites UnpersistedWrites
+
 	// Invoked when creating a backup file "as new".
 	formatFileName FormatFileNameFunc
 
@@ -207,8 +228,10 @@ type Engine struct {
 
 // NewEngine returns a new instance of Engine.
 func NewEngine(id uint64, idx tsdb.Index, path string, walPath string, sfile *tsdb.SeriesFile, opt tsdb.EngineOptions) tsdb.Engine {
+	walEnabled := opt.WALEnabled && !opt.Config.WALDisabled(idx.Database())
+
 	var wal *WAL
-	if opt.WALEnabled {
+	if walEnabled {
 		wal = NewWAL(walPath)
 		wal.syncDelay = time.Duration(opt.Config.WALFsyncDelay)
 	}
@@ -219,6 +242,7 @@ func NewEngine(id uint64, idx tsdb.Index, path string, walPath string, sfile *ts
 		fs.WithObserver(opt.FileStoreObserver)
 	}
 	fs.tsmMMAPWillNeed = opt.Config.TSMWillNeed
+	fs.WithUsageTagKey(opt.Config.UsageTagKey)
 
 	cache := NewCache(uint64(opt.Config.CacheMaxMemorySize))
 
@@ -226,11 +250,17 @@ func NewEngine(id uint64, idx tsdb.Index, path string, walPath string, sfile *ts
 	c.Dir = path
 	c.FileStore = fs
 	c.RateLimit = opt.CompactionThroughputLimiter
+	c.FloatEncoding = NewFloatEncodingFunc(opt.FloatEncodingRules, idx.Database())
 
 	var planner CompactionPlanner = NewDefaultPlanner(fs, time.Duration(opt.Config.CompactFullWriteColdDuration))
+	plannerName := tsdb.CompactionPlannerLeveled
 	if opt.CompactionPlannerCreator != nil {
 		planner = opt.CompactionPlannerCreator(opt.Config).(CompactionPlanner)
 		planner.SetFileStore(fs)
+		plannerName = "custom"
+	} else if rule, ok := tsdb.MatchCompactionPlannerRule(opt.CompactionPlannerRules, idx.Database()); ok {
+		planner = NewCompactionPlanner(fs, rule, time.Duration(opt.Config.CompactFullWriteColdDuration))
+		plannerName = rule.Planner
 	}
 
 	stats := &EngineStatistics{}
@@ -249,11 +279,14 @@ func NewEngine(id uint64, idx tsdb.Index, path string, walPath string, sfile *ts
 		FileStore:      fs,
 		Compactor:      c,
 		CompactionPlan: planner,
+		plannerName:    plannerName,
 
 		CacheFlushMemorySizeThreshold: uint64(opt.Config.CacheSnapshotMemorySize),
 		CacheFlushWriteColdDuration:   time.Duration(opt.Config.CacheSnapshotWriteColdDuration),
+		CacheSnapshotInterval:         time.Duration(opt.Config.CacheSnapshotInterval),
 		enableCompactionsOnOpen:       true,
-		WALEnabled:                    opt.WALEnabled,
+		WALEnabled:                    walEnabled,
+		walPath:                       walPath,
 		formatFileName:                DefaultFormatFileName,
 		stats:                         stats,
 		compactionLimiter:             opt.CompactionLimiter,
@@ -261,6 +294,10 @@ func NewEngine(id uint64, idx tsdb.Index, path string, walPath string, sfile *ts
 		seriesIDSets:                  opt.SeriesIDSets,
 	}
 
+	if !e.WALEnabled {
+		e.cacheState = newCacheState(path)
+	}
+
 	// Feature flag to enable per-series type checking, by default this is off and
 	// e.seriesTypeMap will be nil.
 	if os.Getenv("INFLUXDB_SERIES_TYPE_CHECK_ENABLED") != "" {
@@ -375,6 +412,66 @@ func (e *Engine) Digest() (io.ReadCloser, int64, error) {
 	return f, fi.Size(), nil
 }
 
+// WindowDigest writes a windowed digest of the points of the engine to w. The
+// cache is snapshotted first, so the digest covers every point written.
+func (e *Engine) WindowDigest(opts tsdb.DigestOptions, w io.Writer) error {
+	if err := e.WriteSnapshot(); err != nil {
+		return err
+	}
+
+	readers := e.FileStore.TSMReaders()
+	defer func() {
+		for _, r := range readers {
+			r.Unref()
+		}
+	}()
+	return WindowDigest(readers, opts, w)
+}
+
+// ExportKeyRanges writes the points of the key ranges to w as line protocol.
+// The cache is snapshotted first, so every point written is exported.
+func (e *Engine) ExportKeyRanges(ranges []tsdb.KeyRange, w io.Writer) error {
+	if err := e.WriteSnapshot(); err != nil {
+		return err
+	}
+
+	readers := e.FileStore.TSMReaders()
+	defer func() {
+		for _, r := range readers {
+			r.Unref()
+		}
+	}()
+	return ExportKeyRanges(readers, ranges, w)
+}
+
+// DeleteKeyRanges deletes the points of the key ranges. Unlike
+// DeleteSeriesRange, it deletes the points of single fields and leaves the
+// series in the index, so that the points of a range can be replaced.
+func (e *Engine) DeleteKeyRanges(ranges []tsdb.KeyRange) error {
+	if len(ranges) == 0 {
+		return nil
+	}
+
+	// Level compactions are disabled so that they do not remove the new
+	// tombstones, as for DeleteSeriesRange.
+	e.disableLevelCompactions(true)
+	defer e.enableLevelCompactions(true)
+
+	for _, r := range ranges {
+		keys := [][]byte{r.Key}
+		if err := e.FileStore.DeleteRange(keys, r.Min, r.Max); err != nil {
+			return err
+		}
+		e.Cache.DeleteRange(keys, r.Min, r.Max)
+		if e.WALEnabled {
+			if _, err := e.WAL.DeleteRange(keys, r.Min, r.Max); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // SetEnabled sets whether the engine is enabled.
 func (e *Engine) SetEnabled(enabled bool) {
 	e.enableCompactionsOnOpen = enabled
@@ -565,6 +662,53 @@ func (e *Engine) ScheduleFullCompaction() error {
 	return nil
 }
 
+// SetCompactionsPaused pauses or resumes the planning of level and full
+// compactions. Running compactions complete and cache snapshots continue, and
+// a full compaction scheduled while paused runs once compactions resume.
+// Unlike SetCompactionsEnabled, pausing is not undone by the store.
+func (e *Engine) SetCompactionsPaused(paused bool) {
+	var v int32
+	if paused {
+		v = 1
+	}
+	if atomic.SwapInt32(&e.compactionsPaused, v) != v {
+		e.logger.Info("Compactions paused", zap.Bool("paused", paused), logger.Shard(e.id))
+	}
+}
+
+// CompactionsPaused returns true if level and full compactions are paused.
+func (e *Engine) CompactionsPaused() bool {
+	return atomic.LoadInt32(&e.compactionsPaused) != 0
+}
+
+// CompactionState returns the state of the compactions of the engine.
+func (e *Engine) CompactionState() tsdb.CompactionState {
+	s := tsdb.CompactionState{
+		Planner:        e.plannerName,
+		Paused:         e.CompactionsPaused(),
+		FullyCompacted: e.CompactionPlan.FullyCompacted(),
+		Files:          e.FileStore.Count(),
+	}
+	for i := 0; i < 3; i++ {
+		s.Active[i] = atomic.LoadInt64(&e.stats.TSMCompactionsActive[i])
+		s.Queued[i] = atomic.LoadInt64(&e.stats.TSMCompactionsQueue[i])
+		s.Compactions[i] = atomic.LoadInt64(&e.stats.TSMCompactions[i])
+		s.Errors[i] = atomic.LoadInt64(&e.stats.TSMCompactionErrors[i])
+	}
+	s.Active[3] = atomic.LoadInt64(&e.stats.TSMFullCompactionsActive) + atomic.LoadInt64(&e.stats.TSMOptimizeCompactionsActive)
+	s.Queued[3] = atomic.LoadInt64(&e.stats.TSMFullCompactionsQueue) + atomic.LoadInt64(&e.stats.TSMOptimizeCompactionsQueue)
+	s.Compactions[3] = atomic.LoadInt64(&e.stats.TSMFullCompactions) + atomic.LoadInt64(&e.stats.TSMOptimizeCompactions)
+	s.Errors[3] = atomic.LoadInt64(&e.stats.TSMFullCompactionErrors) + atomic.LoadInt64(&e.stats.TSMOptimizeCompactionErrors)
+	return s
+}
+
+// StorageUsage returns the size of the TSM blocks of the series of the
+// engine, by measurement and value of tagKey. Points in the cache are not
+// accounted until they are snapshotted.
+func (e *Engine) StorageUsage(tagKey string) tsdb.StorageUsage {
+	return e.FileStore.StorageUsage(tagKey)
+}
+
 // Path returns the path the engine was opened with.
 func (e *Engine) Path() string { return e.path }
 
@@ -625,6 +769,11 @@ func (e *Engine) LastModified() time.Time {
 		return e.WAL.LastWriteTime()
 	}
 
+	// Without a WAL, the most recent writes are only held by the cache.
+	if !e.WALEnabled && e.Cache.LastWriteTime().After(fsTime) {
+		return e.Cache.LastWriteTime()
+	}
+
 	return fsTime
 }
 
@@ -747,14 +896,35 @@ func (e *Engine) Open() error {
 		return err
 	}
 
+	var leftoverWAL []string
 	if e.WALEnabled {
 		if err := e.reloadCache(); err != nil {
 			return err
 		}
+	} else {
+		if err := e.recoverCacheState(); err != nil {
+			return err
+		}
+		if leftoverWAL, err = e.reloadLeftoverWAL(); err != nil {
+			return err
+		}
 	}
 
 	e.Compactor.Open()
 
+	// Persist the segments left over by a run with the WAL enabled, as they
+	// are no longer replayed once removed.
+	if len(leftoverWAL) > 0 {
+		if err := e.WriteSnapshot(); err != nil {
+			return err
+		}
+		for _, fn := range leftoverWAL {
+			if err := os.Remove(fn); err != nil {
+				return err
+			}
+		}
+	}
+
 	if e.enableCompactionsOnOpen {
 		e.SetCompactionsEnabled(true)
 	}
@@ -762,8 +932,71 @@ func (e *Engine) Open() error {
 	return nil
 }
 
+// recoverCacheState reports the writes that the previous run of an engine
+// without a WAL did not persist to TSM files.
+func (e *Engine) recoverCacheState() error {
+	lost, err := e.cacheState.recover()
+	if err != nil {
+		e.logger.Warn("Unable to read cache state, writes may have been lost", logger.Shard(e.id), zap.Error(err))
+		return nil
+	}
+	if lost.Empty() {
+		return nil
+	}
+
+	e.lostWrites = lost
+	e.logger.Warn("Writes were lost: the engine stopped before they were written to a TSM file and the WAL is disabled",
+		logger.Shard(e.id),
+		zap.String("path", e.path),
+		zap.Time("first_write", lost.FirstWrite),
+		zap.Time("last_write", lost.LastWrite),
+		zap.Time("min_time", time.Unix(0, lost.MinTime).UTC()),
+		zap.Time("max_time", time.Unix(0, lost.MaxTime).UTC()),
+		zap.Int64("points", lost.Points))
+	return nil
+}
+
+// LostWrites returns the writes that the previous run of the engine did not
+// persist to TSM files, when the WAL is disabled. The writes received up to
+// one sync of the cache state before the engine stopped may not be included.
+func (e *Engine) LostWrites() UnpersistedWrites {
+	return e.lostWrites
+}
+
+// reloadLeftoverWAL loads the WAL segments left over by a run with the WAL
+// enabled into the cache, and returns their paths.
+func (e *Engine) reloadLeftoverWAL() ([]string, error) {
+	files, err := segmentFileNames(e.walPath)
+	if err != nil || len(files) == 0 {
+		return nil, err
+	}
+
+	e.logger.Info("Reloading WAL segments left over with the WAL disabled", logger.Shard(e.id), zap.String("path", e.walPath))
+
+	limit := e.Cache.MaxSize()
+	defer e.Cache.SetMaxSize(limit)
+	e.Cache.SetMaxSize(0)
+
+	loader := NewCacheLoader(files)
+	loader.WithLogger(e.logger)
+	if err := loader.Load(e.Cache); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
 // Close closes the engine. Subsequent calls to Close are a nop.
 func (e *Engine) Close() error {
+	if !e.WALEnabled {
+		// Without a WAL, the cache is only persisted by a snapshot.
+		if err := e.WriteSnapshot(); err != nil {
+			e.logger.Warn("Unable to snapshot the cache, writes will be lost", logger.Shard(e.id), zap.Error(err))
+		}
+		if err := e.cacheState.sync(); err != nil {
+			e.logger.Warn("Unable to write cache state", logger.Shard(e.id), zap.Error(err))
+		}
+	}
+
 	e.SetCompactionsEnabled(false)
 
 	// Lock now and close everything else down.
@@ -1339,6 +1572,8 @@ func (e *Engine) WritePoints(points []models.Point) error {
 		if _, err := e.WAL.WriteMulti(values); err != nil {
 			return err
 		}
+	} else {
+		e.cacheState.add(values, time.Now())
 	}
 	return seriesErr
 }
@@ -1434,7 +1669,7 @@ func (e *Engine) DeleteSeriesRangeWithPredicate(itr tsdb.SeriesIterator, predica
 
 		if sz >= deleteFlushThreshold || flushBatch {
 			// Delete all matching batch.
-			if err := e.deleteSeriesRange(batch, min, max); err != nil {
+			if err := e.deleteSeriesRange(batch, nil, min, max); err != nil {
 				return err
 			}
 			batch = batch[:0]
@@ -1453,7 +1688,7 @@ func (e *Engine) DeleteSeriesRangeWithPredicate(itr tsdb.SeriesIterator, predica
 
 	if len(batch) > 0 {
 		// Delete all matching batch.
-		if err := e.deleteSeriesRange(batch, min, max); err != nil {
+		if err := e.deleteSeriesRange(batch, nil, min, max); err != nil {
 			return err
 		}
 	}
@@ -1461,10 +1696,129 @@ func (e *Engine) DeleteSeriesRangeWithPredicate(itr tsdb.SeriesIterator, predica
 	return nil
 }
 
-// deleteSeriesRange removes the values between min and max (inclusive) from all series.  This
-// does not update the index or disable compactions.  This should mainly be called by DeleteSeriesRange
-// and not directly.
-func (e *Engine) deleteSeriesRange(seriesKeys [][]byte, min, max int64) error {
+// DeleteSeriesFieldsRange removes the values between min and max (inclusive)
+// of the fields of series, given the composite keys of the series and fields.
+// A series is removed from the index once no values of any of its fields are
+// left.
+func (e *Engine) DeleteSeriesFieldsRange(keys [][]byte, min, max int64) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	// Ensure that the index does not compact away the series we're going to
+	// delete before we're done with them.
+	if tsiIndex, ok := e.index.(*tsi1.Index); ok {
+		tsiIndex.DisableCompactions()
+		defer tsiIndex.EnableCompactions()
+		tsiIndex.Wait()
+
+		fs, err := tsiIndex.RetainFileSet()
+		if err != nil {
+			return err
+		}
+		defer fs.Release()
+	}
+
+	// See DeleteSeriesRangeWithPredicate.
+	e.disableLevelCompactions(true)
+	defer e.enableLevelCompactions(true)
+
+	e.sfile.DisableCompactions()
+	defer e.sfile.EnableCompactions()
+	e.sfile.Wait()
+
+	var (
+		seriesKeys = make([][]byte, 0, len(keys))
+		fields     = make(seriesFields)
+		sz         int
+	)
+	for _, key := range keys {
+		seriesKey, field := SeriesAndFieldFromCompositeKey(key)
+		f := fields[string(seriesKey)]
+		if f == nil {
+			if sz >= deleteFlushThreshold {
+				if err := e.deleteSeriesRange(seriesKeys, fields, min, max); err != nil {
+					return err
+				}
+				seriesKeys, fields, sz = seriesKeys[:0], make(seriesFields), 0
+			}
+			f = make(map[string]struct{})
+			fields[string(seriesKey)] = f
+			seriesKeys = append(seriesKeys, seriesKey)
+			sz += len(seriesKey)
+		}
+		f[string(field)] = struct{}{}
+	}
+	return e.deleteSeriesRange(seriesKeys, fields, min, max)
+}
+
+// seriesFields are the fields of series, by series key, whose values are
+// deleted. A nil seriesFields deletes the values of all fields.
+type seriesFields map[string]map[string]struct{}
+
+// has returns true if the values of the field of the series are deleted.
+func (f seriesFields) has(seriesKey, field []byte) bool {
+	if f == nil {
+		return true
+	}
+	_, ok := f[string(seriesKey)][string(field)]
+	return ok
+}
+
+// HasPointsInRange returns true if the cache or the TSM files hold values of
+// the composite key of a series and field between min and max (inclusive).
+// TSM blocks are not decoded, so a block that overlaps the range but holds
+// no values within it is counted, unless the overlap is tombstoned.
+func (e *Engine) HasPointsInRange(key []byte, min, max int64) bool {
+	for _, v := range e.Cache.Values(key) {
+		if t := v.UnixNano(); t >= min && t <= max {
+			return true
+		}
+	}
+
+	// Apply runs the function concurrently.
+	var found int32
+	_ = e.FileStore.Apply(func(r TSMFile) error {
+		if !r.OverlapsTimeRange(min, max) {
+			return nil
+		}
+		var entries []IndexEntry
+		tombstones := r.TombstoneRange(key)
+		for _, entry := range r.ReadEntries(key, &entries) {
+			if !entry.OverlapsTimeRange(min, max) {
+				continue
+			}
+			tmin, tmax := entry.MinTime, entry.MaxTime
+			if tmin < min {
+				tmin = min
+			}
+			if tmax > max {
+				tmax = max
+			}
+			if !timeRangeCovered(tombstones, tmin, tmax) {
+				atomic.StoreInt32(&found, 1)
+				return nil
+			}
+		}
+		return nil
+	})
+	return atomic.LoadInt32(&found) == 1
+}
+
+// timeRangeCovered returns true if a single time range covers min to max.
+func timeRangeCovered(ranges []TimeRange, min, max int64) bool {
+	for _, r := range ranges {
+		if r.Min <= min && r.Max >= max {
+			return true
+		}
+	}
+	return false
+}
+
+// deleteSeriesRange removes the values between min and max (inclusive) from all series, or from
+// the fields of series set in fields.  This does not update the index or disable compactions.
+// This should mainly be called by DeleteSeriesRange and not directly.
+func (e *Engine) deleteSeriesRange(seriesKeys [][]byte, fields seriesFields, min, max int64) error {
 	if len(seriesKeys) == 0 {
 		return nil
 	}
@@ -1521,7 +1875,7 @@ func (e *Engine) deleteSeriesRange(seriesKeys [][]byte, min, max int64) error {
 		var j int
 		for i := r.Seek(minKey); i < n; i++ {
 			indexKey, _ := r.KeyAt(i)
-			seriesKey, _ := SeriesAndFieldFromCompositeKey(indexKey)
+			seriesKey, field := SeriesAndFieldFromCompositeKey(indexKey)
 
 			for j < len(seriesKeys) && bytes.Compare(seriesKeys[j], seriesKey) < 0 {
 				j++
@@ -1530,7 +1884,7 @@ func (e *Engine) deleteSeriesRange(seriesKeys [][]byte, min, max int64) error {
 			if j >= len(seriesKeys) {
 				break
 			}
-			if bytes.Equal(seriesKeys[j], seriesKey) {
+			if bytes.Equal(seriesKeys[j], seriesKey) && fields.has(seriesKey, field) {
 				if err := batch.DeleteRange([][]byte{indexKey}, min, max); err != nil {
 					batch.Rollback()
 					return err
@@ -1548,12 +1902,12 @@ func (e *Engine) deleteSeriesRange(seriesKeys [][]byte, min, max int64) error {
 
 	// ApplySerialEntryFn cannot return an error in this invocation.
 	_ = e.Cache.ApplyEntryFn(func(k []byte, _ *entry) error {
-		seriesKey, _ := SeriesAndFieldFromCompositeKey([]byte(k))
+		seriesKey, field := SeriesAndFieldFromCompositeKey([]byte(k))
 
 		// Cache does not walk keys in sorted order, so search the sorted
 		// series we need to delete to see if any of the cache keys match.
 		i := bytesutil.SearchBytes(seriesKeys, seriesKey)
-		if i < len(seriesKeys) && bytes.Equal(seriesKey, seriesKeys[i]) {
+		if i < len(seriesKeys) && bytes.Equal(seriesKey, seriesKeys[i]) && fields.has(seriesKey, field) {
 			// k is the measurement + tags + sep + field
 			deleteKeys = append(deleteKeys, k)
 		}
@@ -1835,6 +2189,9 @@ func (e *Engine) WriteSnapshot() (err error) {
 			return
 		}
 
+		if !e.WALEnabled {
+			e.cacheState.beginSnapshot()
+		}
 		return
 	}()
 
@@ -1844,6 +2201,9 @@ func (e *Engine) WriteSnapshot() (err error) {
 
 	if snapshot.Size() == 0 {
 		e.Cache.ClearSnapshot(true)
+		if !e.WALEnabled {
+			e.cacheState.commitSnapshot()
+		}
 		return nil
 	}
 
@@ -1925,6 +2285,11 @@ func (e *Engine) writeSnapshotAndCommit(log *zap.Logger, closedFiles []string, s
 		if err := e.WAL.Remove(closedFiles); err != nil {
 			log.Info("Error removing closed WAL segments", zap.Error(err))
 		}
+	} else {
+		e.cacheState.commitSnapshot()
+		if err := e.cacheState.sync(); err != nil {
+			log.Info("Error writing cache state", zap.Error(err))
+		}
 	}
 
 	return nil
@@ -1945,6 +2310,11 @@ func (e *Engine) compactCache() {
 
 		case <-t.C:
 			e.Cache.UpdateAge()
+			if !e.WALEnabled {
+				if err := e.cacheState.sync(); err != nil {
+					e.logger.Info("Error writing cache state", zap.Error(err))
+				}
+			}
 			if e.ShouldCompactCache(time.Now()) {
 				start := time.Now()
 				e.traceLogger.Info("Compacting cache", zap.String("path", e.path))
@@ -1963,6 +2333,8 @@ func (e *Engine) compactCache() {
 
 // ShouldCompactCache returns true if the Cache is over its flush threshold
 // or if the passed in lastWriteTime is older than the write cold threshold.
+// When the WAL is disabled, it also returns true if the oldest write held by
+// the cache is older than the snapshot interval.
 func (e *Engine) ShouldCompactCache(t time.Time) bool {
 	sz := e.Cache.Size()
 
@@ -1974,6 +2346,12 @@ func (e *Engine) ShouldCompactCache(t time.Time) bool {
 		return true
 	}
 
+	if !e.WALEnabled && e.CacheSnapshotInterval > 0 {
+		if oldest, ok := e.cacheState.oldest(); ok && t.Sub(oldest) >= e.CacheSnapshotInterval {
+			return true
+		}
+	}
+
 	return t.Sub(e.Cache.LastWriteTime()) > e.CacheFlushWriteColdDuration
 }
 
@@ -1991,6 +2369,13 @@ func (e *Engine) compact(wg *sync.WaitGroup) {
 			return
 
 		case <-t.C:
+			if e.CompactionsPaused() {
+				for i := range e.stats.TSMCompactionsQueue {
+					atomic.StoreInt64(&e.stats.TSMCompactionsQueue[i], 0)
+				}
+				atomic.StoreInt64(&e.stats.TSMOptimizeCompactionsQueue, 0)
+				continue
+			}
 
 			// Find our compaction plans
 			level1Groups := e.CompactionPlan.PlanLevel(1)
@@ -193,6 +193,12 @@ type FileStore struct {
 	parseFileName ParseFileNameFunc
 
 	obs tsdb.FileStoreObserver
+
+	// usage is the storage usage of the TSM files by path, accounted by
+	// usageTagKey.
+	usageTagKey []byte
+	usageMu     sync.Mutex
+	usage       map[string]tsdb.StorageUsage
 }
 
 // FileStat holds information about a TSM file on disk.
@@ -244,6 +250,7 @@ func NewFileStore(dir string) *FileStore {
 		},
 		obs:           noFileStoreObserver{},
 		parseFileName: DefaultParseFileName,
+		usage:         make(map[string]tsdb.StorageUsage),
 	}
 	fs.purger.fileStore = fs
 	return fs
@@ -679,6 +686,19 @@ func (f *FileStore) TSMReader(path string) *TSMReader {
 	return nil
 }
 
+// TSMReaders returns the readers of the TSM files of the FileStore, ordered
+// by generation. The readers are referenced and must be released with Unref.
+func (f *FileStore) TSMReaders() []*TSMReader {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	readers := make([]*TSMReader, 0, len(f.files))
+	for _, r := range f.files {
+		r.Ref()
+		readers = append(readers, r.(*TSMReader))
+	}
+	return readers
+}
+
 // KeyCursor returns a KeyCursor for key and t across the files in the FileStore.
 func (f *FileStore) KeyCursor(ctx context.Context, key []byte, t int64, ascending bool) *KeyCursor {
 	f.mu.RLock()
@@ -797,6 +817,9 @@ func (f *FileStore) replace(oldFiles, newFiles []string, updatedFn func(r []TSMF
 		updatedFn(updated)
 	}
 
+	// Account the storage usage of the new files before they are visible.
+	f.addUsage(updated)
+
 	f.mu.Lock()
 	defer f.mu.Unlock()
 
@@ -908,6 +931,8 @@ func (f *FileStore) replace(oldFiles, newFiles []string, updatedFn func(r []TSMF
 	}
 	atomic.StoreInt64(&f.stats.DiskBytes, totalSize)
 
+	f.removeUsage(oldFiles)
+
 	return nil
 }
 
@@ -42,6 +42,11 @@ type mergeKeyIterator struct {
 	itrs keyIterators
 	key  []byte
 	typ  byte
+
+	// merging is set if the keys of more than one file are merged, which
+	// remains so once all but one of the files are exhausted, as the last
+	// key read may still be the next key of the remaining file.
+	merging bool
 }
 
 func newMergeKeyIterator(files []TSMFile, seek []byte) *mergeKeyIterator {
@@ -53,14 +58,13 @@ func newMergeKeyIterator(files []TSMFile, seek []byte) *mergeKeyIterator {
 		}
 	}
 	m.itrs = itrs
+	m.merging = len(itrs) > 1
 	heap.Init(&m.itrs)
 
 	return m
 }
 
 func (m *mergeKeyIterator) Next() bool {
-	merging := len(m.itrs) > 1
-
 RETRY:
 	if len(m.itrs) == 0 {
 		return false
@@ -84,7 +88,7 @@ RETRY:
 		}
 	}
 
-	if merging && bytes.Equal(m.key, key) {
+	if m.merging && bytes.Equal(m.key, key) {
 		// same as previous key, keep iterating
 		goto RETRY
 	}
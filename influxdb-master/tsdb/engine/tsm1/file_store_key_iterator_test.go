@@ -70,6 +70,15 @@ func TestNewMergeKeyIterator(t *testing.T) {
 			exp: []string{"aaaa", "bbbb", "cccc", "dddd", "eeee"},
 		},
 
+		{
+			name: "last key of one file same as key of another",
+			files: newTSMFiles(
+				[]string{"aaaa"},
+				[]string{"aaaa", "bbbb"},
+			),
+			exp: []string{"aaaa", "bbbb"},
+		},
+
 		{
 			name: "seek skips all files",
 			seek: "eeee",
@@ -151,11 +151,31 @@ type FloatDecoder struct {
 	first    bool
 	finished bool
 
+	// decoded are the values of the compressions other than Gorilla, which
+	// are decoded at once by SetBytes, and pos the position of the current one.
+	decoded []float64
+	pos     int
+	batch   bool
+
 	err error
 }
 
 // SetBytes initializes the decoder with b. Must call before calling Next().
 func (it *FloatDecoder) SetBytes(b []byte) error {
+	it.batch = false
+	if len(b) > 0 && (b[0]>>4 == floatCompressedByteStreamSplit || b[0]>>4 == floatCompressedQuantized) {
+		decoded, err := FloatArrayDecodeAll(b, it.decoded[:0])
+		if err != nil {
+			return err
+		}
+		it.decoded = decoded
+		it.pos = -1
+		it.batch = true
+		it.b = b
+		it.err = nil
+		return nil
+	}
+
 	var v uint64
 	if len(b) == 0 {
 		v = uvnan
@@ -185,6 +205,15 @@ func (it *FloatDecoder) SetBytes(b []byte) error {
 
 // Next returns true if there are remaining values to read.
 func (it *FloatDecoder) Next() bool {
+	if it.batch {
+		if it.pos+1 >= len(it.decoded) {
+			return false
+		}
+		it.pos++
+		it.val = math.Float64bits(it.decoded[it.pos])
+		return true
+	}
+
 	if it.err != nil || it.finished {
 		return false
 	}
@@ -0,0 +1,263 @@
+package tsm1
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/golang/snappy"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tsdb"
+)
+
+const (
+	// floatCompressedByteStreamSplit is a lossless format that splits the bytes
+	// of the values into 8 streams, one per byte position, compressed with
+	// snappy. The streams of the sign, the exponent and the high bits of the
+	// mantissa of noisy values compress far better than their XOR with the
+	// previous value.
+	floatCompressedByteStreamSplit = 2
+
+	// floatCompressedQuantized is a lossy format that rounds the values to a
+	// decimal precision and encodes the resulting integers with the integer
+	// encoding, i.e. delta, zig zag and simple8b or run length encoding.
+	floatCompressedQuantized = 3
+)
+
+// FloatCompression is a compression scheme of the values of float blocks.
+type FloatCompression byte
+
+const (
+	// FloatCompressionGorilla is the XOR compression of Facebook's Gorilla, the default.
+	FloatCompressionGorilla FloatCompression = floatCompressedGorilla
+
+	// FloatCompressionByteStreamSplit is the lossless byte stream split compression.
+	FloatCompressionByteStreamSplit FloatCompression = floatCompressedByteStreamSplit
+
+	// FloatCompressionQuantized is the lossy delta-of-quantized compression.
+	FloatCompressionQuantized FloatCompression = floatCompressedQuantized
+)
+
+// maxQuantized is the largest magnitude of a quantized value, so that every
+// quantized value is exactly representable by a float64.
+const maxQuantized = 1 << 53
+
+// FloatEncoding configures the compression of the values of float blocks.
+type FloatEncoding struct {
+	Compression FloatCompression
+
+	// Precision is the number of decimal digits kept by FloatCompressionQuantized:
+	// values are rounded to the nearest multiple of 10^-Precision. A negative
+	// precision rounds to tens, hundreds and so on.
+	Precision int
+}
+
+func (e FloatEncoding) String() string {
+	switch e.Compression {
+	case FloatCompressionByteStreamSplit:
+		return tsdb.FloatCompressionByteStreamSplit
+	case FloatCompressionQuantized:
+		return fmt.Sprintf("%s:%d", tsdb.FloatCompressionQuantized, e.Precision)
+	default:
+		return tsdb.FloatCompressionGorilla
+	}
+}
+
+// FloatArrayEncode encodes src into b using the compression of enc, returning
+// b and any error encountered. Blocks with values that cannot be quantized at
+// the precision of enc, because they are not finite or too large, are encoded
+// with the byte stream split compression instead.
+func FloatArrayEncode(src []float64, enc FloatEncoding, b []byte) ([]byte, error) {
+	switch enc.Compression {
+	case FloatCompressionByteStreamSplit:
+		return floatArrayEncodeByteStreamSplit(src, b), nil
+	case FloatCompressionQuantized:
+		if vb, ok, err := floatArrayEncodeQuantized(src, enc.Precision, b); err != nil || ok {
+			return vb, err
+		}
+		return floatArrayEncodeByteStreamSplit(src, b), nil
+	default:
+		return FloatArrayEncodeAll(src, b)
+	}
+}
+
+// floatBlockEncoding returns the encoding of the encoded float values b.
+func floatBlockEncoding(b []byte) FloatEncoding {
+	if len(b) == 0 {
+		return FloatEncoding{Compression: FloatCompressionGorilla}
+	}
+	enc := FloatEncoding{Compression: FloatCompression(b[0] >> 4)}
+	if enc.Compression == FloatCompressionQuantized && len(b) > 1 {
+		enc.Precision = int(int8(b[1]))
+	}
+	return enc
+}
+
+func floatArrayEncodeByteStreamSplit(src []float64, b []byte) []byte {
+	n := len(src)
+	raw := make([]byte, 8*n)
+	for i, v := range src {
+		u := math.Float64bits(v)
+		for j := 0; j < 8; j++ {
+			raw[j*n+i] = byte(u >> (56 - 8*uint(j)))
+		}
+	}
+
+	var hdr [1 + binary.MaxVarintLen64]byte
+	hdr[0] = floatCompressedByteStreamSplit << 4
+	sz := 1 + binary.PutUvarint(hdr[1:], uint64(n))
+
+	need := sz + snappy.MaxEncodedLen(len(raw))
+	if cap(b) < need {
+		b = make([]byte, need)
+	} else {
+		b = b[:need]
+	}
+	copy(b, hdr[:sz])
+	enc := snappy.Encode(b[sz:], raw)
+	return b[:sz+len(enc)]
+}
+
+func floatArrayDecodeByteStreamSplit(b []byte, buf []float64) ([]float64, error) {
+	n, i := binary.Uvarint(b[1:])
+	if i <= 0 {
+		return nil, fmt.Errorf("FloatArrayDecodeAll: invalid byte stream split header")
+	}
+	raw, err := snappy.Decode(nil, b[1+i:])
+	if err != nil {
+		return nil, fmt.Errorf("FloatArrayDecodeAll: failed to decode byte stream split: %v", err)
+	}
+	if len(raw)%8 != 0 || uint64(len(raw)/8) != n {
+		return nil, fmt.Errorf("FloatArrayDecodeAll: byte stream split length mismatch, expected %d values, got %d bytes", n, len(raw))
+	}
+
+	sz := int(n)
+	if cap(buf) < sz {
+		buf = make([]float64, sz)
+	} else {
+		buf = buf[:sz]
+	}
+	for i := range buf {
+		var u uint64
+		for j := 0; j < 8; j++ {
+			u = u<<8 | uint64(raw[j*sz+i])
+		}
+		buf[i] = math.Float64frombits(u)
+	}
+	return buf, nil
+}
+
+// floatArrayEncodeQuantized returns false if a value of src cannot be quantized.
+func floatArrayEncodeQuantized(src []float64, precision int, b []byte) ([]byte, bool, error) {
+	if precision < tsdb.MinFloatPrecision || precision > tsdb.MaxFloatPrecision {
+		return nil, false, fmt.Errorf("invalid float precision %d, must be between %d and %d", precision, tsdb.MinFloatPrecision, tsdb.MaxFloatPrecision)
+	}
+
+	scale := math.Pow10(precision)
+	q := make([]int64, len(src))
+	for i, v := range src {
+		x := math.Round(v * scale)
+		if math.IsNaN(x) || math.Abs(x) > maxQuantized {
+			return nil, false, nil
+		}
+		q[i] = int64(x)
+	}
+
+	ib, err := IntegerArrayEncodeAll(q, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	b = append(b[:0], floatCompressedQuantized<<4, byte(int8(precision)))
+	return append(b, ib...), true, nil
+}
+
+func floatArrayDecodeQuantized(b []byte, buf []float64) ([]float64, error) {
+	if len(b) < 2 {
+		return nil, fmt.Errorf("FloatArrayDecodeAll: invalid quantized header")
+	}
+	precision := int(int8(b[1]))
+	if precision < tsdb.MinFloatPrecision || precision > tsdb.MaxFloatPrecision {
+		return nil, fmt.Errorf("FloatArrayDecodeAll: invalid quantized precision %d", precision)
+	}
+
+	q, err := IntegerArrayDecodeAll(b[2:], nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if cap(buf) < len(q) {
+		buf = make([]float64, len(q))
+	} else {
+		buf = buf[:len(q)]
+	}
+	// dividing by a power of ten, rather than multiplying by its inverse,
+	// returns the float64 nearest to the decimal value.
+	if precision >= 0 {
+		scale := math.Pow10(precision)
+		for i, v := range q {
+			buf[i] = float64(v) / scale
+		}
+	} else {
+		scale := math.Pow10(-precision)
+		for i, v := range q {
+			buf[i] = float64(v) * scale
+		}
+	}
+	return buf, nil
+}
+
+// FloatEncodingFunc returns the encoding of the float values of a series key,
+// which is a composite of a series and a field key.
+type FloatEncodingFunc func(key []byte) FloatEncoding
+
+// NewFloatEncodingFunc returns the FloatEncodingFunc of the rules applying to
+// the database, or nil if all its float values use the default encoding.
+func NewFloatEncodingFunc(rules []tsdb.FloatEncodingRule, database string) FloatEncodingFunc {
+	var matching []tsdb.FloatEncodingRule
+	for _, r := range rules {
+		if r.Bucket == "" || r.Bucket == database {
+			matching = append(matching, r)
+		}
+	}
+	if len(matching) == 0 {
+		return nil
+	}
+
+	return func(key []byte) FloatEncoding {
+		seriesKey, field := SeriesAndFieldFromCompositeKey(key)
+		r, ok := tsdb.MatchFloatEncodingRule(matching, database, string(models.ParseName(seriesKey)), string(field))
+		if !ok {
+			return FloatEncoding{Compression: FloatCompressionGorilla}
+		}
+		switch r.Compression {
+		case tsdb.FloatCompressionByteStreamSplit:
+			return FloatEncoding{Compression: FloatCompressionByteStreamSplit}
+		case tsdb.FloatCompressionQuantized:
+			return FloatEncoding{Compression: FloatCompressionQuantized, Precision: r.Precision}
+		default:
+			return FloatEncoding{Compression: FloatCompressionGorilla}
+		}
+	}
+}
+
+// reencodeFloatBlock re-encodes the values of a float block with enc, if they
+// are encoded differently. The timestamps are left untouched.
+func reencodeFloatBlock(block []byte, enc FloatEncoding) ([]byte, error) {
+	tb, vb, err := unpackBlock(block[1:])
+	if err != nil {
+		return nil, err
+	}
+	if floatBlockEncoding(vb) == enc {
+		return block, nil
+	}
+
+	values, err := FloatArrayDecodeAll(vb, nil)
+	if err != nil {
+		return nil, err
+	}
+	nvb, err := FloatArrayEncode(values, enc, nil)
+	if err != nil {
+		return nil, err
+	}
+	return packBlock(nil, BlockFloat64, tb, nvb), nil
+}
@@ -0,0 +1,173 @@
+package tsm1
+
+import (
+	"math"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2/tsdb"
+)
+
+func TestFloatArrayEncode_ByteStreamSplit(t *testing.T) {
+	src := []float64{1.23456789, -2.5, 6378137.123456, 0, math.Inf(1), math.NaN(), 1e-300}
+	b, err := FloatArrayEncode(src, FloatEncoding{Compression: FloatCompressionByteStreamSplit}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := floatBlockEncoding(b); got.Compression != FloatCompressionByteStreamSplit {
+		t.Fatalf("unexpected encoding %s", got)
+	}
+
+	got, err := FloatArrayDecodeAll(b, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(src) {
+		t.Fatalf("got %d values, want %d", len(got), len(src))
+	}
+	for i := range src {
+		if math.Float64bits(got[i]) != math.Float64bits(src[i]) {
+			t.Fatalf("value %d: got %v, want %v", i, got[i], src[i])
+		}
+	}
+
+	if _, err := FloatArrayDecodeAll(b[:len(b)-1], nil); err == nil {
+		t.Fatal("expected an error decoding a truncated block")
+	}
+}
+
+func TestFloatArrayEncode_Quantized(t *testing.T) {
+	src := []float64{1.23456789, -2.5, 6378137.123456, 0, 0.1, -0.0005}
+	for _, tt := range []struct {
+		precision int
+		want      []float64
+	}{
+		{precision: 3, want: []float64{1.235, -2.5, 6378137.123, 0, 0.1, -0.001}},
+		{precision: 0, want: []float64{1, -3, 6378137, 0, 0, -0}},
+		{precision: -2, want: []float64{0, -0, 6378100, 0, 0, -0}},
+	} {
+		enc := FloatEncoding{Compression: FloatCompressionQuantized, Precision: tt.precision}
+		b, err := FloatArrayEncode(src, enc, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := floatBlockEncoding(b); got != enc {
+			t.Fatalf("unexpected encoding %s, want %s", got, enc)
+		}
+
+		got, err := FloatArrayDecodeAll(b, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range tt.want {
+			if got[i] != tt.want[i] {
+				t.Fatalf("precision %d, value %d: got %v, want %v", tt.precision, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
+
+func TestFloatArrayEncode_QuantizedFallback(t *testing.T) {
+	for _, src := range [][]float64{
+		{1, math.NaN()},
+		{1, math.Inf(-1)},
+		{1, 1e300},
+	} {
+		b, err := FloatArrayEncode(src, FloatEncoding{Compression: FloatCompressionQuantized, Precision: 3}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := floatBlockEncoding(b); got.Compression != FloatCompressionByteStreamSplit {
+			t.Fatalf("%v: unexpected encoding %s", src, got)
+		}
+	}
+
+	if _, err := FloatArrayEncode([]float64{1}, FloatEncoding{Compression: FloatCompressionQuantized, Precision: 16}, nil); err == nil {
+		t.Fatal("expected an error for an invalid precision")
+	}
+}
+
+func TestReencodeFloatBlock(t *testing.T) {
+	values := []Value{NewValue(10, 1.0001), NewValue(20, 2.5), NewValue(30, -3.25)}
+	block, err := encodeFloatBlock(nil, values)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// blocks already using the encoding are left untouched
+	same, err := reencodeFloatBlock(block, FloatEncoding{Compression: FloatCompressionGorilla})
+	if err != nil {
+		t.Fatal(err)
+	} else if &same[0] != &block[0] {
+		t.Fatal("expected the block to be left untouched")
+	}
+
+	for _, enc := range []FloatEncoding{
+		{Compression: FloatCompressionByteStreamSplit},
+		{Compression: FloatCompressionQuantized, Precision: 2},
+	} {
+		b, err := reencodeFloatBlock(block, enc)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var a []FloatValue
+		decoded, err := DecodeFloatBlock(b, &a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(decoded) != len(values) {
+			t.Fatalf("%s: got %d values, want %d", enc, len(decoded), len(values))
+		}
+		for i, v := range decoded {
+			want := values[i].Value().(float64)
+			if enc.Compression == FloatCompressionQuantized {
+				want = math.Round(want*100) / 100
+			}
+			if v.UnixNano() != values[i].UnixNano() || v.value != want {
+				t.Fatalf("%s: value %d: got %v, want %v", enc, i, v, values[i])
+			}
+		}
+
+		// the iterator decoder reads the new encodings too
+		_, vb, err := unpackBlock(b[1:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		var dec FloatDecoder
+		if err := dec.SetBytes(vb); err != nil {
+			t.Fatal(err)
+		}
+		var n int
+		for dec.Next() {
+			if dec.Values() != decoded[n].value {
+				t.Fatalf("%s: iterator value %d: got %v, want %v", enc, n, dec.Values(), decoded[n].value)
+			}
+			n++
+		}
+		if dec.Error() != nil || n != len(values) {
+			t.Fatalf("%s: iterator read %d values: %v", enc, n, dec.Error())
+		}
+	}
+}
+
+func TestNewFloatEncodingFunc(t *testing.T) {
+	rules, err := tsdb.ParseFloatEncodingRules([]string{
+		"0000000000000001/orbit=quantized:3",
+		"0000000000000002=bss",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fn := NewFloatEncodingFunc(rules, "0000000000000003"); fn != nil {
+		t.Fatal("expected no encoding func for a bucket without rules")
+	}
+
+	fn := NewFloatEncodingFunc(rules, "0000000000000001")
+	if got := fn([]byte("orbit,sat=iss#!~#x")); got != (FloatEncoding{Compression: FloatCompressionQuantized, Precision: 3}) {
+		t.Fatalf("unexpected encoding %s", got)
+	}
+	if got := fn([]byte("cpu,host=a#!~#usage")); got.Compression != FloatCompressionGorilla {
+		t.Fatalf("unexpected encoding %s", got)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"regexp"
 
 	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/models"
 	"github.com/influxdata/influxdb/v2/storage/reads/datatypes"
 )
 
@@ -123,13 +124,21 @@ func (p *predicateMatcher) Clone() influxdb.Predicate {
 	}
 }
 
+// HasFieldRef returns true if the predicate compares the field key, in which
+// case it only matches the composite keys of series and fields.
+func (p *predicateMatcher) HasFieldRef() bool {
+	_, ok := p.state.locs[models.FieldKeyTagKey]
+	return ok
+}
+
 // Matches checks if the key matches the predicate by feeding individual tags into the
-// state and returning as soon as the root node has a definite answer.
+// state and returning as soon as the root node has a definite answer. The
+// field of a composite key is fed as the field key tag.
 func (p *predicateMatcher) Matches(key []byte) bool {
 	p.state.Reset()
 
-	// Extract the series from the composite key
-	key, _ = SeriesAndFieldFromCompositeKey(key)
+	// Extract the series and field from the composite key
+	key, field := SeriesAndFieldFromCompositeKey(key)
 
 	// Determine which popping algorithm to use. If there are no escape characters
 	// we can use the quicker method that only works in that case.
@@ -153,8 +162,24 @@ func (p *predicateMatcher) Matches(key []byte) bool {
 		}
 	}
 
+	if field != nil && p.state.Set(models.FieldKeyTagKeyBytes, field) {
+		resp := p.root.Update()
+		if resp == predicateResponse_true {
+			return true
+		} else if resp == predicateResponse_false {
+			return false
+		}
+	}
+
+	// Tags that are not present in the key compare as empty values, so that
+	// `tag1!=val1` matches a key without tag1. The field key is left unset, as
+	// a series key without a field never matches a predicate on the field.
+	if p.state.SetMissing(models.FieldKeyTagKey) {
+		return p.root.Update() == predicateResponse_true
+	}
+
 	// If it always needed more then it didn't match. For example, consider if
-	// the predicate matches `tag1=val1` but tag1 is not present in the key.
+	// the predicate compares the field key but the key has no field.
 	return false
 }
 
@@ -355,6 +380,19 @@ func (p *predicateState) Reset() {
 	}
 }
 
+// SetMissing sets the keys that are not set, other than except, to empty
+// values and returns true if any key was set.
+func (p *predicateState) SetMissing(except string) bool {
+	var set bool
+	for k, i := range p.locs {
+		if p.values[i] == nil && k != except {
+			p.values[i] = emptyBytes
+			set = true
+		}
+	}
+	return set
+}
+
 // Set sets the key to be the value and returns true if the key is part of the considered
 // set of keys.
 func (p *predicateState) Set(key, value []byte) bool {
@@ -228,6 +228,60 @@ func TestPredicate_Matches(t *testing.T) {
 			Matches: true,
 		},
 
+		{
+			Name: "Not Equal Missing Tag",
+			Predicate: predicate(
+				comparisonNode(datatypes.ComparisonNotEqual, tagNode("tag4"), stringNode("val4"))),
+			Key:     "bucketorg,tag3=val3",
+			Matches: true,
+		},
+
+		{
+			Name: "NotRegex Missing Tag",
+			Predicate: predicate(
+				comparisonNode(datatypes.ComparisonNotRegex, tagNode("tag4"), regexNode("^val"))),
+			Key:     "bucketorg,tag3=val3",
+			Matches: true,
+		},
+
+		{
+			Name: "Field Matching",
+			Predicate: predicate(
+				andNode(
+					comparisonNode(datatypes.ComparisonEqual, tagNode("tag3"), stringNode("val3")),
+					comparisonNode(datatypes.ComparisonRegex, tagNode("\xff"), regexNode("^temp_")))),
+			Key:     "bucketorg,tag3=val3#!~#temp_in",
+			Matches: true,
+		},
+
+		{
+			Name: "Field Unmatching",
+			Predicate: predicate(
+				andNode(
+					comparisonNode(datatypes.ComparisonEqual, tagNode("tag3"), stringNode("val3")),
+					comparisonNode(datatypes.ComparisonRegex, tagNode("\xff"), regexNode("^temp_")))),
+			Key:     "bucketorg,tag3=val3#!~#humidity",
+			Matches: false,
+		},
+
+		{
+			Name: "Field Missing",
+			Predicate: predicate(
+				comparisonNode(datatypes.ComparisonNotEqual, tagNode("\xff"), stringNode("temp"))),
+			Key:     "bucketorg,tag3=val3",
+			Matches: false,
+		},
+
+		{
+			Name: "Field Or Tag Matching",
+			Predicate: predicate(
+				orNode(
+					comparisonNode(datatypes.ComparisonEqual, tagNode("\xff"), stringNode("temp")),
+					comparisonNode(datatypes.ComparisonEqual, tagNode("tag3"), stringNode("val3")))),
+			Key:     "bucketorg,tag3=val3",
+			Matches: true,
+		},
+
 		{
 			Name: "Escaping Matching",
 			Predicate: predicate(
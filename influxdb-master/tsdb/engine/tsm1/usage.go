@@ -0,0 +1,115 @@
+package tsm1
+
+import (
+	"bytes"
+
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tsdb"
+)
+
+// TSMFileUsage returns the size of the blocks of the series of a TSM file, by
+// measurement and value of tagKey. The index of the file is not accounted.
+func TSMFileUsage(r TSMFile, tagKey []byte) tsdb.StorageUsage {
+	usage := make(tsdb.StorageUsage)
+
+	var (
+		entries   []IndexEntry
+		seriesKey []byte
+		k         tsdb.StorageUsageKey
+	)
+	for i, n := 0, r.KeyCount(); i < n; i++ {
+		key, _ := r.KeyAt(i)
+
+		// the fields of a series are adjacent, so its key is parsed once
+		if sk, _ := SeriesAndFieldFromCompositeKey(key); !bytes.Equal(sk, seriesKey) {
+			seriesKey = append(seriesKey[:0], sk...)
+			name, tags := models.ParseKeyBytes(seriesKey)
+			k = tsdb.StorageUsageKey{Measurement: string(name)}
+			if len(tagKey) > 0 {
+				k.TagValue = string(tags.Get(tagKey))
+			}
+		}
+
+		var size int64
+		for _, e := range r.ReadEntries(key, &entries) {
+			size += int64(e.Size)
+		}
+		usage[k] += size
+	}
+	return usage
+}
+
+// WithUsageTagKey sets the tag key by which the storage usage of the TSM files
+// is accounted as they are added to the file store.
+func (f *FileStore) WithUsageTagKey(tagKey string) {
+	f.usageTagKey = []byte(tagKey)
+}
+
+// StorageUsage returns the size of the blocks of the series of the TSM files,
+// by measurement and value of tagKey. The usage by the tag key of the file
+// store is maintained as files are replaced, the usage by other tag keys is
+// computed from the indexes of the files.
+func (f *FileStore) StorageUsage(tagKey string) tsdb.StorageUsage {
+	f.mu.RLock()
+	files := make([]TSMFile, len(f.files))
+	copy(files, f.files)
+	for _, r := range files {
+		r.Ref()
+	}
+	f.mu.RUnlock()
+	defer func() {
+		for _, r := range files {
+			r.Unref()
+		}
+	}()
+
+	usage := make(tsdb.StorageUsage)
+	if tagKey != string(f.usageTagKey) {
+		for _, r := range files {
+			usage.Add(TSMFileUsage(r, []byte(tagKey)))
+		}
+		return usage
+	}
+
+	active := make(map[string]tsdb.StorageUsage, len(files))
+	for _, r := range files {
+		f.usageMu.Lock()
+		u, ok := f.usage[r.Path()]
+		f.usageMu.Unlock()
+		if !ok {
+			u = TSMFileUsage(r, f.usageTagKey)
+		}
+		active[r.Path()] = u
+		usage.Add(u)
+	}
+
+	// drop the usage of the files that were replaced
+	f.usageMu.Lock()
+	f.usage = active
+	f.usageMu.Unlock()
+	return usage
+}
+
+// addUsage accounts the usage of TSM files added to the file store. Without a
+// usage tag key the files are not scanned as they are added, their usage is
+// computed when it is first requested.
+func (f *FileStore) addUsage(files []TSMFile) {
+	if len(f.usageTagKey) == 0 {
+		return
+	}
+	for _, r := range files {
+		u := TSMFileUsage(r, f.usageTagKey)
+		f.usageMu.Lock()
+		f.usage[r.Path()] = u
+		f.usageMu.Unlock()
+	}
+}
+
+// removeUsage drops the usage of TSM files removed from the file store.
+func (f *FileStore) removeUsage(paths []string) {
+	f.usageMu.Lock()
+	defer f.usageMu.Unlock()
+	for _, path := range paths {
+		delete(f.usage, path)
+	}
+}
@@ -0,0 +1,132 @@
+package tsm1_test
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/influxdata/influxdb/v2/tsdb/engine/tsm1"
+)
+
+// blockSize returns the size of the blocks of keys in the TSM file at path.
+func blockSize(t *testing.T, path string, keys ...string) int64 {
+	t.Helper()
+
+	r := MustOpenTSMReader(path)
+	defer r.Close()
+
+	var n int64
+	for _, key := range keys {
+		for _, e := range r.Entries([]byte(key)) {
+			n += int64(e.Size)
+		}
+	}
+	return n
+}
+
+func TestFileStore_StorageUsage(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	values := []tsm1.Value{tsm1.NewValue(0, 1.0), tsm1.NewValue(1, 2.0)}
+	f1 := MustWriteTSM(dir, 1, map[string][]tsm1.Value{
+		"cpu,craft=a#!~#value": values,
+		"cpu,craft=a#!~#temp":  values,
+		"cpu,craft=b#!~#value": values,
+	})
+	f2 := MustWriteTSM(dir, 2, map[string][]tsm1.Value{
+		"cpu,craft=a#!~#value": {tsm1.NewValue(2, 3.0)},
+		"mem#!~#value":         values,
+	})
+
+	fs := tsm1.NewFileStore(dir)
+	fs.WithUsageTagKey("craft")
+	if err := fs.Open(); err != nil {
+		t.Fatalf("open file store: %v", err)
+	}
+	defer fs.Close()
+
+	exp := tsdb.StorageUsage{
+		{Measurement: "cpu", TagValue: "a"}: blockSize(t, f1, "cpu,craft=a#!~#value", "cpu,craft=a#!~#temp") + blockSize(t, f2, "cpu,craft=a#!~#value"),
+		{Measurement: "cpu", TagValue: "b"}: blockSize(t, f1, "cpu,craft=b#!~#value"),
+		{Measurement: "mem"}:                blockSize(t, f2, "mem#!~#value"),
+	}
+	if got := fs.StorageUsage("craft"); !reflect.DeepEqual(got, exp) {
+		t.Fatalf("usage mismatch:\ngot %v\nexp %v", got, exp)
+	}
+
+	exp = tsdb.StorageUsage{
+		{Measurement: "cpu"}: exp[tsdb.StorageUsageKey{Measurement: "cpu", TagValue: "a"}] + exp[tsdb.StorageUsageKey{Measurement: "cpu", TagValue: "b"}],
+		{Measurement: "mem"}: exp[tsdb.StorageUsageKey{Measurement: "mem"}],
+	}
+	if got := fs.StorageUsage("host"); !reflect.DeepEqual(got, exp) {
+		t.Fatalf("usage by another tag key mismatch:\ngot %v\nexp %v", got, exp)
+	}
+
+	// compact the files
+	f3 := MustWriteTSM(dir, 3, map[string][]tsm1.Value{
+		"cpu,craft=a#!~#value": append(values, tsm1.NewValue(2, 3.0)),
+		"cpu,craft=b#!~#value": values,
+	})
+	tmp := fmt.Sprintf("%s.%s", f3, tsm1.TmpTSMFileExtension)
+	if err := os.Rename(f3, tmp); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Replace([]string{f1, f2}, []string{tmp}); err != nil {
+		t.Fatalf("replace: %v", err)
+	}
+
+	exp = tsdb.StorageUsage{
+		{Measurement: "cpu", TagValue: "a"}: blockSize(t, f3, "cpu,craft=a#!~#value"),
+		{Measurement: "cpu", TagValue: "b"}: blockSize(t, f3, "cpu,craft=b#!~#value"),
+	}
+	if got := fs.StorageUsage("craft"); !reflect.DeepEqual(got, exp) {
+		t.Fatalf("usage after replace mismatch:\ngot %v\nexp %v", got, exp)
+	}
+}
+
+func TestFileStore_StorageUsage_NoTagKey(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	values := []tsm1.Value{tsm1.NewValue(0, 1.0), tsm1.NewValue(1, 2.0)}
+	f1 := MustWriteTSM(dir, 1, map[string][]tsm1.Value{
+		"cpu,craft=a#!~#value": values,
+		"mem#!~#value":         values,
+	})
+
+	fs := tsm1.NewFileStore(dir)
+	if err := fs.Open(); err != nil {
+		t.Fatalf("open file store: %v", err)
+	}
+	defer fs.Close()
+
+	exp := tsdb.StorageUsage{
+		{Measurement: "cpu"}: blockSize(t, f1, "cpu,craft=a#!~#value"),
+		{Measurement: "mem"}: blockSize(t, f1, "mem#!~#value"),
+	}
+	if got := fs.StorageUsage(""); !reflect.DeepEqual(got, exp) {
+		t.Fatalf("usage mismatch:\ngot %v\nexp %v", got, exp)
+	}
+
+	// replace the file, its usage is computed when it is requested
+	f2 := MustWriteTSM(dir, 2, map[string][]tsm1.Value{
+		"cpu,craft=a#!~#value": append(values, tsm1.NewValue(2, 3.0)),
+	})
+	tmp := fmt.Sprintf("%s.%s", f2, tsm1.TmpTSMFileExtension)
+	if err := os.Rename(f2, tmp); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Replace([]string{f1}, []string{tmp}); err != nil {
+		t.Fatalf("replace: %v", err)
+	}
+
+	exp = tsdb.StorageUsage{
+		{Measurement: "cpu"}: blockSize(t, f2, "cpu,craft=a#!~#value"),
+	}
+	if got := fs.StorageUsage(""); !reflect.DeepEqual(got, exp) {
+		t.Fatalf("usage after replace mismatch:\ngot %v\nexp %v", got, exp)
+	}
+}
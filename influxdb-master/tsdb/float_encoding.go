@@ -0,0 +1,138 @@
+package tsdb
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Compressions of the values of float fields.
+const (
+	// FloatCompressionGorilla is the default, lossless, XOR compression.
+	FloatCompressionGorilla = "gorilla"
+
+	// FloatCompressionByteStreamSplit is a lossless compression better suited
+	// to noisy or high precision values.
+	FloatCompressionByteStreamSplit = "bss"
+
+	// FloatCompressionQuantized is a lossy compression that rounds values to a
+	// decimal precision.
+	FloatCompressionQuantized = "quantized"
+)
+
+const (
+	// MinFloatPrecision and MaxFloatPrecision bound the decimal precision of
+	// FloatCompressionQuantized.
+	MinFloatPrecision = -15
+	MaxFloatPrecision = 15
+)
+
+// FloatEncodingRule selects the compression of the values of the float fields
+// of a bucket, a measurement or a field. An empty Bucket, Measurement or Field
+// matches any.
+type FloatEncodingRule struct {
+	Bucket      string
+	Measurement string
+	Field       string
+
+	Compression string
+	// Precision is the number of decimal digits kept by FloatCompressionQuantized.
+	Precision int
+}
+
+// ParseFloatEncodingRule parses a rule of the form
+//
+//	<bucket-id>[/<measurement>[/<field>]]=<compression>
+//
+// where any of the bucket ID, the measurement and the field may be *, and the
+// compression is gorilla, bss or quantized:<precision>. For example
+// `*/eci_position=quantized:3` keeps the millimeters of every field of the
+// eci_position measurement of every bucket.
+func ParseFloatEncodingRule(s string) (FloatEncodingRule, error) {
+	var r FloatEncodingRule
+
+	i := strings.LastIndex(s, "=")
+	if i < 0 {
+		return r, fmt.Errorf("invalid float encoding %q: expected <bucket-id>[/<measurement>[/<field>]]=<compression>", s)
+	}
+	selector, compression := s[:i], s[i+1:]
+
+	parts := strings.SplitN(selector, "/", 3)
+	for i, p := range parts {
+		if p == "" {
+			return r, fmt.Errorf("invalid float encoding %q: empty selector", s)
+		}
+		if p == "*" {
+			continue
+		}
+		switch i {
+		case 0:
+			r.Bucket = p
+		case 1:
+			r.Measurement = p
+		case 2:
+			r.Field = p
+		}
+	}
+
+	switch {
+	case compression == FloatCompressionGorilla, compression == FloatCompressionByteStreamSplit:
+		r.Compression = compression
+	case strings.HasPrefix(compression, FloatCompressionQuantized+":"):
+		p, err := strconv.Atoi(strings.TrimPrefix(compression, FloatCompressionQuantized+":"))
+		if err != nil || p < MinFloatPrecision || p > MaxFloatPrecision {
+			return r, fmt.Errorf("invalid float encoding %q: precision must be an integer between %d and %d", s, MinFloatPrecision, MaxFloatPrecision)
+		}
+		r.Compression, r.Precision = FloatCompressionQuantized, p
+	default:
+		return r, fmt.Errorf("invalid float encoding %q: compression must be %s, %s or %s:<precision>", s, FloatCompressionGorilla, FloatCompressionByteStreamSplit, FloatCompressionQuantized)
+	}
+	return r, nil
+}
+
+// ParseFloatEncodingRules parses rules with ParseFloatEncodingRule.
+func ParseFloatEncodingRules(ss []string) ([]FloatEncodingRule, error) {
+	rules := make([]FloatEncodingRule, 0, len(ss))
+	for _, s := range ss {
+		r, err := ParseFloatEncodingRule(s)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// specificity ranks rules matching the same field: a field is more specific
+// than a measurement, which is more specific than a bucket.
+func (r FloatEncodingRule) specificity() int {
+	var n int
+	if r.Bucket != "" {
+		n |= 1
+	}
+	if r.Measurement != "" {
+		n |= 2
+	}
+	if r.Field != "" {
+		n |= 4
+	}
+	return n
+}
+
+func (r FloatEncodingRule) matches(bucket, measurement, field string) bool {
+	return (r.Bucket == "" || r.Bucket == bucket) &&
+		(r.Measurement == "" || r.Measurement == measurement) &&
+		(r.Field == "" || r.Field == field)
+}
+
+// MatchFloatEncodingRule returns the most specific rule matching a field, the
+// first one of equally specific rules.
+func MatchFloatEncodingRule(rules []FloatEncodingRule, bucket, measurement, field string) (FloatEncodingRule, bool) {
+	best, found := FloatEncodingRule{}, false
+	for _, r := range rules {
+		if r.matches(bucket, measurement, field) && (!found || r.specificity() > best.specificity()) {
+			best, found = r, true
+		}
+	}
+	return best, found
+}
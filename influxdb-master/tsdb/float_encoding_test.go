@@ -0,0 +1,80 @@
+package tsdb_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2/tsdb"
+)
+
+func TestParseFloatEncodingRule(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		want tsdb.FloatEncodingRule
+	}{
+		{in: "*=bss", want: tsdb.FloatEncodingRule{Compression: "bss"}},
+		{in: "0000000000000001=gorilla", want: tsdb.FloatEncodingRule{Bucket: "0000000000000001", Compression: "gorilla"}},
+		{in: "*/eci_position=quantized:3", want: tsdb.FloatEncodingRule{Measurement: "eci_position", Compression: "quantized", Precision: 3}},
+		{in: "*/cpu/usage=quantized:-2", want: tsdb.FloatEncodingRule{Measurement: "cpu", Field: "usage", Compression: "quantized", Precision: -2}},
+		{in: "*/*/a=b=bss", want: tsdb.FloatEncodingRule{Field: "a=b", Compression: "bss"}},
+	} {
+		got, err := tsdb.ParseFloatEncodingRule(tt.in)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.in, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Fatalf("%s: got %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseFloatEncodingRule_Error(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"bss",
+		"=bss",
+		"*//field=bss",
+		"*=zstd",
+		"*=quantized",
+		"*=quantized:x",
+		"*=quantized:16",
+	} {
+		if _, err := tsdb.ParseFloatEncodingRule(in); err == nil {
+			t.Fatalf("%q: expected an error", in)
+		}
+	}
+}
+
+func TestMatchFloatEncodingRule(t *testing.T) {
+	rules, err := tsdb.ParseFloatEncodingRules([]string{
+		"*/*/temp=quantized:1",
+		"*=bss",
+		"0000000000000001/orbit=quantized:3",
+		"0000000000000001=gorilla",
+		"*/orbit=quantized:6",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		bucket, measurement, field string
+		want                       string
+		precision                  int
+	}{
+		{"0000000000000002", "cpu", "usage", "bss", 0},
+		{"0000000000000001", "cpu", "usage", "gorilla", 0},
+		{"0000000000000001", "orbit", "x", "quantized", 3},
+		{"0000000000000002", "orbit", "x", "quantized", 6},
+		{"0000000000000001", "orbit", "temp", "quantized", 1},
+	} {
+		r, ok := tsdb.MatchFloatEncodingRule(rules, tt.bucket, tt.measurement, tt.field)
+		if !ok || r.Compression != tt.want || r.Precision != tt.precision {
+			t.Fatalf("%s/%s/%s: got %+v, want %s:%d", tt.bucket, tt.measurement, tt.field, r, tt.want, tt.precision)
+		}
+	}
+
+	if _, ok := tsdb.MatchFloatEncodingRule(rules[:1], "0000000000000001", "cpu", "usage"); ok {
+		t.Fatal("expected no matching rule")
+	}
+}
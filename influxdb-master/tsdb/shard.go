@@ -136,6 +136,10 @@ type Shard struct {
 	sfile   *SeriesFile
 	options EngineOptions
 
+	// tagValues caches the number of values of tag keys to enforce the
+	// cardinality limits of the database.
+	tagValues tagValueCounts
+
 	mu      sync.RWMutex
 	_engine Engine
 	index   Index
@@ -223,6 +227,37 @@ func (s *Shard) ScheduleFullCompaction() error {
 	return engine.ScheduleFullCompaction()
 }
 
+// SetCompactionsPaused pauses or resumes the level and full compactions of the
+// shard. Unlike SetCompactionsEnabled, it does not stop cache snapshots and
+// running compactions complete.
+func (s *Shard) SetCompactionsPaused(paused bool) error {
+	engine, err := s.Engine()
+	if err != nil {
+		return err
+	}
+	engine.SetCompactionsPaused(paused)
+	return nil
+}
+
+// CompactionState returns the state of the compactions of the shard.
+func (s *Shard) CompactionState() (CompactionState, error) {
+	engine, err := s.Engine()
+	if err != nil {
+		return CompactionState{}, err
+	}
+	return engine.CompactionState(), nil
+}
+
+// StorageUsage returns the size of the TSM blocks of the series of the shard,
+// by measurement and value of tagKey.
+func (s *Shard) StorageUsage(tagKey string) (StorageUsage, error) {
+	engine, err := s.Engine()
+	if err != nil {
+		return nil, err
+	}
+	return engine.StorageUsage(tagKey), nil
+}
+
 // ID returns the shards ID.
 func (s *Shard) ID() uint64 {
 	return s.id
@@ -598,6 +633,24 @@ func (s *Shard) validateSeriesAndFields(points []models.Point) ([]models.Point,
 		return nil, nil, err
 	}
 
+	// Drop the points of series that would exceed the cardinality limits.
+	if s.options.CardinalityLimits != nil {
+		if limits := s.options.CardinalityLimits(); !limits.Unlimited() {
+			n, d, r, err := s.limitCardinality(limits, points, keys, names, tagsSlice)
+			if err != nil {
+				return nil, nil, err
+			}
+			if d > 0 {
+				dropped += d
+				if reason == "" {
+					reason = r
+				}
+				atomic.AddInt64(&s.stats.WritePointsDropped, int64(d))
+			}
+			points, keys, names, tagsSlice = points[:n], keys[:n], names[:n], tagsSlice[:n]
+		}
+	}
+
 	// Add new series. Check for partial writes.
 	var droppedKeys [][]byte
 	if err := engine.CreateSeriesListIfNotExists(keys, names, tagsSlice); err != nil {
@@ -729,6 +782,20 @@ func (s *Shard) DeleteSeriesRange(itr SeriesIterator, min, max int64) error {
 	return engine.DeleteSeriesRange(itr, min, max)
 }
 
+// DeleteSeriesFieldsRange deletes all values between min and max (inclusive)
+// of keys, which are composite keys of series and fields. Series without
+// fields left are removed from the index.
+func (s *Shard) DeleteSeriesFieldsRange(keys [][]byte, min, max int64) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	engine, err := s.Engine()
+	if err != nil {
+		return err
+	}
+	return engine.DeleteSeriesFieldsRange(keys, min, max)
+}
+
 // DeleteSeriesRangeWithPredicate deletes all values from for seriesKeys between min and max (inclusive)
 // for which predicate() returns true. If predicate() is nil, then all values in range are deleted.
 func (s *Shard) DeleteSeriesRangeWithPredicate(itr SeriesIterator, predicate func(name []byte, tags models.Tags) (int64, int64, bool)) error {
@@ -1171,6 +1238,35 @@ func (s *Shard) Digest() (io.ReadCloser, int64, error) {
 	return engine.Digest()
 }
 
+// WindowDigest writes a windowed digest of the points of the shard to w.
+// Unlike Digest, the shard does not need to be idle.
+func (s *Shard) WindowDigest(opts DigestOptions, w io.Writer) error {
+	engine, err := s.Engine()
+	if err != nil {
+		return err
+	}
+	return engine.WindowDigest(opts, w)
+}
+
+// ExportKeyRanges writes the points of the key ranges of the shard to w as
+// line protocol.
+func (s *Shard) ExportKeyRanges(ranges []KeyRange, w io.Writer) error {
+	engine, err := s.Engine()
+	if err != nil {
+		return err
+	}
+	return engine.ExportKeyRanges(ranges, w)
+}
+
+// DeleteKeyRanges deletes the points of the key ranges of the shard.
+func (s *Shard) DeleteKeyRanges(ranges []KeyRange) error {
+	engine, err := s.Engine()
+	if err != nil {
+		return err
+	}
+	return engine.DeleteKeyRanges(ranges)
+}
+
 // engine safely (under an RLock) returns a reference to the shard's Engine, or
 // an error if the Engine is closed, or the shard is currently disabled.
 //
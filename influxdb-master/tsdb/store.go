@@ -94,6 +94,10 @@ type Store struct {
 	// is stored by shard.
 	epochs map[uint64]*epochTracker
 
+	// Cardinality limits by database.
+	limitsMu sync.RWMutex
+	limits   map[string]CardinalityLimits
+
 	EngineOptions EngineOptions
 
 	baseLogger *zap.Logger
@@ -113,6 +117,7 @@ func NewStore(path string) *Store {
 		sfiles:              make(map[string]*SeriesFile),
 		pendingShardDeletes: make(map[uint64]struct{}),
 		epochs:              make(map[uint64]*epochTracker),
+		limits:              make(map[string]CardinalityLimits),
 		EngineOptions:       NewEngineOptions(),
 		Logger:              zap.NewNop(),
 		baseLogger:          zap.NewNop(),
@@ -379,6 +384,7 @@ func (s *Store) loadShards() error {
 
 					// Provide an implementation of the ShardIDSets
 					opt.SeriesIDSets = shardSet{store: s, db: db}
+					opt.CardinalityLimits = func() CardinalityLimits { return s.CardinalityLimits(db) }
 
 					// Open engine.
 					shard := NewShard(shardID, path, walPath, sfile, opt)
@@ -602,6 +608,7 @@ func (s *Store) CreateShard(database, retentionPolicy string, shardID uint64, en
 	// Copy index options and pass in shared index.
 	opt := s.EngineOptions
 	opt.SeriesIDSets = shardSet{store: s, db: database}
+	opt.CardinalityLimits = func() CardinalityLimits { return s.CardinalityLimits(database) }
 
 	path := filepath.Join(s.path, database, retentionPolicy, strconv.FormatUint(shardID, 10))
 	shard := NewShard(shardID, path, walPath, sfile, opt)
@@ -799,6 +806,7 @@ func (s *Store) DeleteDatabase(name string) error {
 
 	// Remove database from store list of databases
 	delete(s.databases, name)
+	s.SetCardinalityLimits(name, CardinalityLimits{})
 
 	return nil
 }
@@ -1264,8 +1272,19 @@ func (s *Store) DeleteSeriesWithPredicate(database string, min, max int64, pred
 				}
 				defer sitr.Close()
 
-				itr := NewSeriesIteratorAdapter(sfile, NewPredicateSeriesIDIterator(sitr, sfile, pred))
-				return sh.DeleteSeriesRange(itr, min, max)
+				fields := predicateFields(sh, mm, pred)
+				if fields == nil {
+					itr := NewSeriesIteratorAdapter(sfile, NewPredicateSeriesIDIterator(sitr, sfile, pred))
+					return sh.DeleteSeriesRange(itr, min, max)
+				}
+
+				// The predicate compares field keys, so series of which it
+				// matches only some fields have those fields deleted.
+				fitr := newFieldPredicateSeriesIDIterator(sitr, sfile, pred, fields)
+				if err := sh.DeleteSeriesRange(NewSeriesIteratorAdapter(sfile, fitr), min, max); err != nil {
+					return err
+				}
+				return sh.DeleteSeriesFieldsRange(fitr.keys, min, max)
 			}(); err != nil {
 				return err
 			}
@@ -0,0 +1,19 @@
+package tsdb
+
+// StorageUsageKey groups the series of a measurement by the value of a tag
+// key. TagValue is empty for the series without the tag key.
+type StorageUsageKey struct {
+	Measurement string
+	TagValue    string
+}
+
+// StorageUsage is the size in bytes of the TSM blocks of the series of a
+// shard, by measurement and value of a tag key.
+type StorageUsage map[StorageUsageKey]int64
+
+// Add adds the usage of other to u.
+func (u StorageUsage) Add(other StorageUsage) {
+	for k, n := range other {
+		u[k] += n
+	}
+}
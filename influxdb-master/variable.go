@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/url"
 	"regexp"
+	"time"
 
 	"github.com/influxdata/influxdb/v2/kit/platform"
 )
@@ -44,6 +45,41 @@ type VariableService interface {
 	DeleteVariable(ctx context.Context, id platform.ID) error
 }
 
+// VariableValuesService evaluates the values of variables.
+type VariableValuesService interface {
+	// FindVariableValues evaluates the values of a variable, given the
+	// selected values of the variables it depends on. The variables it
+	// depends on without a selected value are evaluated in turn, and their
+	// default value used.
+	FindVariableValues(ctx context.Context, id platform.ID, req VariableValuesRequest) (*VariableValues, error)
+}
+
+// VariableValuesRequest is a request to evaluate the values of a variable.
+type VariableValuesRequest struct {
+	// Selected are the selected values of the variables the variable depends
+	// on, by their name.
+	Selected map[string]string `json:"selected"`
+
+	// Start and Stop are the time range to evaluate the variable for. A zero
+	// Stop is now, and a zero Start is the default range of the variable
+	// before Stop.
+	Start time.Time `json:"start,omitempty"`
+	Stop  time.Time `json:"stop,omitempty"`
+}
+
+// VariableValues are the values of a variable.
+type VariableValues struct {
+	Values []string `json:"values"`
+
+	// Selected is the default value of the variable: its own selected value
+	// if it is one of its values, or its first value otherwise.
+	Selected string `json:"selected,omitempty"`
+
+	// Dependencies are the values of the variables the variable depends on
+	// it was evaluated with, by their name.
+	Dependencies map[string]string `json:"dependencies,omitempty"`
+}
+
 // A Variable describes a keyword that can be expanded into several possible
 // values when used in an InfluxQL or Flux query
 type Variable struct {
@@ -96,8 +132,8 @@ type VariableUpdate struct {
 
 // A VariableArguments contains arguments used when expanding a Variable
 type VariableArguments struct {
-	Type   string      `json:"type"`   // "constant", "map", or "query"
-	Values interface{} `json:"values"` // either VariableQueryValues, VariableConstantValues, VariableMapValues
+	Type   string      `json:"type"`   // "constant", "map", "query" or "tagValues"
+	Values interface{} `json:"values"` // either VariableQueryValues, VariableConstantValues, VariableMapValues, VariableTagValuesValues
 }
 
 // VariableQueryValues contains a query used when expanding a query-based Variable
@@ -106,6 +142,56 @@ type VariableQueryValues struct {
 	Language string `json:"language"` // "influxql" or "flux"
 }
 
+// VariableTagValuesValues are the data for expanding a tag-values-based
+// Variable, whose values are the values of a tag key of a bucket.
+type VariableTagValuesValues struct {
+	BucketID    platform.ID `json:"bucketID"`
+	Measurement string      `json:"measurement,omitempty"`
+	TagKey      string      `json:"tagKey"`
+	// Range is the duration literal of the time range before now to find
+	// the tag values in, if the variable is evaluated without one.
+	Range   string              `json:"range,omitempty"`
+	Filters []VariableTagFilter `json:"filters,omitempty"`
+}
+
+// Variables returns the names of the variables the values depend on.
+func (v VariableTagValuesValues) Variables() []string {
+	var names []string
+	for _, f := range v.Filters {
+		if f.Variable != "" {
+			names = append(names, f.Variable)
+		}
+	}
+	return names
+}
+
+func (v VariableTagValuesValues) valid() error {
+	if !v.BucketID.Valid() {
+		return fmt.Errorf("tag values variable requires a valid bucket ID")
+	}
+	if v.TagKey == "" {
+		return fmt.Errorf("tag values variable requires a tag key")
+	}
+	for _, f := range v.Filters {
+		if f.Key == "" {
+			return fmt.Errorf("tag values variable filter requires a tag key")
+		}
+		if (f.Value == "") == (f.Variable == "") {
+			return fmt.Errorf("tag values variable filter requires exactly one of value or variable")
+		}
+	}
+	return nil
+}
+
+// VariableTagFilter restricts the values of a tag-values-based Variable to
+// those of the series whose tag Key is Value, or the selected value of the
+// variable named Variable.
+type VariableTagFilter struct {
+	Key      string `json:"key"`
+	Value    string `json:"value,omitempty"`
+	Variable string `json:"variable,omitempty"`
+}
+
 // VariableConstantValues are the data for expanding a constants-based Variable
 type VariableConstantValues []string
 
@@ -126,15 +212,22 @@ func (m *Variable) Valid() error {
 	}
 
 	validTypes := map[string]bool{
-		"constant": true,
-		"map":      true,
-		"query":    true,
+		"constant":  true,
+		"map":       true,
+		"query":     true,
+		"tagValues": true,
 	}
 
 	if m.Arguments == nil || !validTypes[m.Arguments.Type] {
 		return fmt.Errorf("invalid arguments type")
 	}
 
+	if values, ok := m.Arguments.Values.(VariableTagValuesValues); ok {
+		if err := values.valid(); err != nil {
+			return err
+		}
+	}
+
 	inValidNames := [11]string{"and", "import", "not", "return", "option", "test", "empty", "in", "or", "package", "builtin"}
 
 	for x := range inValidNames {
@@ -245,6 +338,19 @@ func (a *VariableArguments) UnmarshalJSON(data []byte) error {
 		variableValues.Query = query.(string)
 		variableValues.Language = language.(string)
 		a.Values = variableValues
+	case "tagValues":
+		if _, ok := aux.Values.(map[string]interface{}); !ok {
+			return fmt.Errorf("error parsing %v as VariableTagValuesArguments", aux.Values)
+		}
+
+		// Decode the values again, as a struct, now that their type is known.
+		raw := struct {
+			Values VariableTagValuesValues `json:"values"`
+		}{}
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return fmt.Errorf("error parsing VariableTagValuesArguments: %v", err)
+		}
+		a.Values = raw.Values
 	default:
 		return fmt.Errorf("unknown VariableArguments type %s", aux.Type)
 	}
@@ -0,0 +1,465 @@
+// Package variable evaluates the values of variables on the server, resolving
+// the variables they depend on. A tag-values variable is resolved with the tag
+// values of the storage engine rather than a Flux query, and can be filtered
+// by the selected value of another variable; a Flux query variable depends on
+// the variables it references as members of v, as dashboard queries do.
+package variable
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/types"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/flux/parser"
+	"github.com/influxdata/flux/values"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+	"github.com/influxdata/influxdb/v2/internal/queryutil"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/predicate"
+	"github.com/influxdata/influxdb/v2/query"
+	"github.com/influxdata/influxdb/v2/storage/reads/datatypes"
+	"github.com/influxdata/influxdb/v2/tsdb/cursors"
+)
+
+// DefaultRange is the time range before now variables are evaluated for when
+// neither the request nor the variable set one.
+const DefaultRange = 30 * 24 * time.Hour
+
+// builtinMembers are the members of v set for every query, rather than by
+// variables.
+var builtinMembers = map[string]bool{
+	"timeRangeStart": true,
+	"timeRangeStop":  true,
+	"windowPeriod":   true,
+}
+
+// TagValuesStore finds the values of a tag key in storage.
+type TagValuesStore interface {
+	TagValues(ctx context.Context, req *datatypes.TagValuesRequest) (cursors.StringIterator, error)
+	GetSource(orgID, bucketID uint64) proto.Message
+}
+
+var _ influxdb.VariableValuesService = (*ValuesService)(nil)
+
+// ValuesService evaluates the values of the variables of a VariableService.
+// Variables are found and their queries executed with the authorization of
+// the context, and the values of a tag-values variable require read access to
+// its bucket.
+type ValuesService struct {
+	variables influxdb.VariableService
+	tags      TagValuesStore
+	queries   query.QueryService
+
+	TimeGenerator influxdb.TimeGenerator
+}
+
+// NewValuesService returns a ValuesService evaluating the variables of
+// variables, with the tag values of tags and the query service queries.
+func NewValuesService(variables influxdb.VariableService, tags TagValuesStore, queries query.QueryService) *ValuesService {
+	return &ValuesService{
+		variables:     variables,
+		tags:          tags,
+		queries:       queries,
+		TimeGenerator: influxdb.RealTimeGenerator{},
+	}
+}
+
+// FindVariableValues evaluates the values of a variable.
+func (s *ValuesService) FindVariableValues(ctx context.Context, id platform.ID, req influxdb.VariableValuesRequest) (*influxdb.VariableValues, error) {
+	v, err := s.variables.FindVariableByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Stop.IsZero() {
+		req.Stop = s.TimeGenerator.Now()
+	}
+	if !req.Start.IsZero() && !req.Start.Before(req.Stop) {
+		return nil, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "start of time range must be before its stop",
+		}
+	}
+
+	e := &evaluation{
+		ValuesService: s,
+		req:           req,
+		orgID:         v.OrganizationID,
+		evaluated:     map[string]*influxdb.VariableValues{},
+	}
+	return e.evaluate(ctx, v)
+}
+
+// evaluation is the evaluation of a variable and the variables it depends on.
+type evaluation struct {
+	*ValuesService
+	req   influxdb.VariableValuesRequest
+	orgID platform.ID
+
+	// byName are the variables of the organization, found when a dependency
+	// is first resolved.
+	byName map[string]*influxdb.Variable
+	// evaluated are the values of the dependencies evaluated so far, and
+	// path the names of the variables being evaluated.
+	evaluated map[string]*influxdb.VariableValues
+	path      []string
+}
+
+func (e *evaluation) evaluate(ctx context.Context, v *influxdb.Variable) (*influxdb.VariableValues, error) {
+	for i, name := range e.path {
+		if name == v.Name {
+			return nil, &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  fmt.Sprintf("variables have a dependency cycle: %s", strings.Join(append(e.path[i:], v.Name), " -> ")),
+			}
+		}
+	}
+	e.path = append(e.path, v.Name)
+	defer func() { e.path = e.path[:len(e.path)-1] }()
+
+	if v.Arguments == nil {
+		return nil, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  fmt.Sprintf("variable %q has no arguments", v.Name),
+		}
+	}
+
+	names, err := Dependencies(v.Arguments)
+	if err != nil {
+		return nil, err
+	}
+	deps := make(map[string]string, len(names))
+	for _, name := range names {
+		if deps[name], err = e.dependency(ctx, v, name); err != nil {
+			return nil, err
+		}
+	}
+
+	var vals []string
+	switch args := v.Arguments.Values.(type) {
+	case influxdb.VariableConstantValues:
+		vals = append([]string{}, args...)
+	case influxdb.VariableMapValues:
+		vals = make([]string, 0, len(args))
+		for k := range args {
+			vals = append(vals, k)
+		}
+		sort.Strings(vals)
+	case influxdb.VariableQueryValues:
+		vals, err = e.queryValues(ctx, args, deps)
+	case influxdb.VariableTagValuesValues:
+		vals, err = e.tagValues(ctx, args, deps)
+	default:
+		return nil, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  fmt.Sprintf("variable %q of type %q cannot be evaluated", v.Name, v.Arguments.Type),
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res := &influxdb.VariableValues{Values: vals}
+	if len(deps) > 0 {
+		res.Dependencies = deps
+	}
+	if len(vals) > 0 {
+		res.Selected = vals[0]
+		if len(v.Selected) > 0 {
+			for _, val := range vals {
+				if val == v.Selected[0] {
+					res.Selected = val
+					break
+				}
+			}
+		}
+	}
+	return res, nil
+}
+
+// dependency returns the value of the variable named name v depends on: its
+// selected value in the request, or else its default value.
+func (e *evaluation) dependency(ctx context.Context, v *influxdb.Variable, name string) (string, error) {
+	if val, ok := e.req.Selected[name]; ok {
+		return val, nil
+	}
+	if res, ok := e.evaluated[name]; ok {
+		return res.Selected, nil
+	}
+
+	if e.byName == nil {
+		vars, err := e.variables.FindVariables(ctx, influxdb.VariableFilter{OrganizationID: &e.orgID})
+		if err != nil {
+			return "", err
+		}
+		e.byName = make(map[string]*influxdb.Variable, len(vars))
+		for _, dep := range vars {
+			e.byName[dep.Name] = dep
+		}
+	}
+	dep, ok := e.byName[name]
+	if !ok {
+		return "", &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  fmt.Sprintf("variable %q depends on unknown variable %q", v.Name, name),
+		}
+	}
+
+	res, err := e.evaluate(ctx, dep)
+	if err != nil {
+		return "", err
+	}
+	e.evaluated[name] = res
+	return res.Selected, nil
+}
+
+// timeRange returns the time range to evaluate a variable for, given the range
+// of the variable as a duration literal.
+func (e *evaluation) timeRange(rng string) (time.Time, time.Time, error) {
+	if !e.req.Start.IsZero() {
+		return e.req.Start, e.req.Stop, nil
+	}
+	if rng == "" {
+		return e.req.Stop.Add(-DefaultRange), e.req.Stop, nil
+	}
+
+	d, err := values.ParseDuration(rng)
+	if err != nil {
+		return time.Time{}, time.Time{}, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  fmt.Sprintf("invalid variable range %q", rng),
+			Err:  err,
+		}
+	}
+	start := values.ConvertTime(e.req.Stop).Add(d.Mul(-1)).Time()
+	return start, e.req.Stop, nil
+}
+
+// tagValues returns the values of a tag-values variable.
+func (e *evaluation) tagValues(ctx context.Context, args influxdb.VariableTagValuesValues, deps map[string]string) ([]string, error) {
+	if _, _, err := authorizer.AuthorizeRead(ctx, influxdb.BucketsResourceType, args.BucketID, e.orgID); err != nil {
+		return nil, err
+	}
+
+	start, stop, err := e.timeRange(args.Range)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]predicate.Node, 0, len(args.Filters)+1)
+	if args.Measurement != "" {
+		rules = append(rules, predicate.TagRuleNode{Tag: influxdb.Tag{Key: "_measurement", Value: args.Measurement}, Operator: influxdb.Equal})
+	}
+	for _, f := range args.Filters {
+		val := f.Value
+		if f.Variable != "" {
+			val = deps[f.Variable]
+		}
+		rules = append(rules, predicate.TagRuleNode{Tag: influxdb.Tag{Key: f.Key, Value: val}, Operator: influxdb.Equal})
+	}
+
+	src, err := types.MarshalAny(e.tags.GetSource(uint64(e.orgID), uint64(args.BucketID)))
+	if err != nil {
+		return nil, err
+	}
+	req := &datatypes.TagValuesRequest{
+		TagsSource: src,
+		TagKey:     args.TagKey,
+		Range: datatypes.TimestampRange{
+			Start: start.UnixNano(),
+			End:   stop.UnixNano(),
+		},
+	}
+	if len(rules) > 0 {
+		n := rules[0]
+		for _, r := range rules[1:] {
+			n = predicate.LogicalNode{Operator: predicate.LogicalAnd, Children: [2]predicate.Node{n, r}}
+		}
+		root, err := n.ToDataType()
+		if err != nil {
+			return nil, err
+		}
+		req.Predicate = &datatypes.Predicate{Root: root}
+	}
+
+	it, err := e.tags.TagValues(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	vals := []string{}
+	for it.Next() {
+		vals = append(vals, it.Value())
+	}
+	return vals, nil
+}
+
+// queryValues returns the values of a query variable: the distinct string
+// values of the _value column of its results.
+func (e *evaluation) queryValues(ctx context.Context, args influxdb.VariableQueryValues, deps map[string]string) ([]string, error) {
+	if args.Language != "flux" {
+		return nil, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  fmt.Sprintf("variables of %s queries cannot be evaluated", args.Language),
+		}
+	}
+
+	auth, err := queryutil.Authorization(ctx, e.orgID)
+	if err != nil {
+		return nil, err
+	}
+	start, stop, err := e.timeRange("")
+	if err != nil {
+		return nil, err
+	}
+	extern, err := json.Marshal(variablesExtern(start, stop, deps))
+	if err != nil {
+		return nil, err
+	}
+
+	it, err := e.queries.Query(ctx, &query.Request{
+		Authorization:  auth,
+		OrganizationID: e.orgID,
+		Compiler: lang.FluxCompiler{
+			Now:    stop,
+			Extern: extern,
+			Query:  args.Query,
+		},
+		Source: "variable",
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer it.Release()
+
+	vals := []string{}
+	seen := map[string]bool{}
+	for it.More() {
+		err := it.Next().Tables().Do(func(tbl flux.Table) error {
+			j := -1
+			for i, c := range tbl.Cols() {
+				if c.Label == "_value" && c.Type == flux.TString {
+					j = i
+				}
+			}
+			return tbl.Do(func(cr flux.ColReader) error {
+				if j < 0 {
+					return nil
+				}
+				col := cr.Strings(j)
+				for i := 0; i < cr.Len(); i++ {
+					if col.IsNull(i) {
+						continue
+					}
+					if val := col.ValueString(i); !seen[val] {
+						seen[val] = true
+						vals = append(vals, val)
+					}
+				}
+				return nil
+			})
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return vals, nil
+}
+
+// Dependencies returns the names of the variables variable arguments depend
+// on: the variables filtering a tag-values variable, or the members of v
+// referenced by a Flux query variable.
+func Dependencies(args *influxdb.VariableArguments) ([]string, error) {
+	switch a := args.Values.(type) {
+	case influxdb.VariableTagValuesValues:
+		return a.Variables(), nil
+	case influxdb.VariableQueryValues:
+		if a.Language != "flux" {
+			return nil, nil
+		}
+		pkg := parser.ParseSource(a.Query)
+		if ast.Check(pkg) > 0 {
+			return nil, &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "invalid variable query",
+				Err:  ast.GetError(pkg),
+			}
+		}
+
+		var names []string
+		seen := map[string]bool{}
+		ast.Visit(pkg, func(n ast.Node) {
+			m, ok := n.(*ast.MemberExpression)
+			if !ok {
+				return
+			}
+			if obj, ok := m.Object.(*ast.Identifier); !ok || obj.Name != "v" {
+				return
+			}
+			var name string
+			switch p := m.Property.(type) {
+			case *ast.Identifier:
+				name = p.Name
+			case *ast.StringLiteral:
+				name = p.Value
+			}
+			if name == "" || builtinMembers[name] || seen[name] {
+				return
+			}
+			seen[name] = true
+			names = append(names, name)
+		})
+		return names, nil
+	default:
+		return nil, nil
+	}
+}
+
+// variablesExtern returns the extern declaring the v option of a query, with
+// the time range and the values of the variables it depends on.
+func variablesExtern(start, stop time.Time, deps map[string]string) *ast.File {
+	names := make([]string, 0, len(deps))
+	for name := range deps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	props := []*ast.Property{
+		{
+			Key:   &ast.Identifier{Name: "timeRangeStart"},
+			Value: &ast.DateTimeLiteral{Value: start},
+		},
+		{
+			Key:   &ast.Identifier{Name: "timeRangeStop"},
+			Value: &ast.DateTimeLiteral{Value: stop},
+		},
+	}
+	for _, name := range names {
+		props = append(props, &ast.Property{
+			Key:   &ast.Identifier{Name: name},
+			Value: &ast.StringLiteral{Value: deps[name]},
+		})
+	}
+
+	return &ast.File{
+		Body: []ast.Statement{
+			&ast.OptionStatement{
+				Assignment: &ast.VariableAssignment{
+					ID:   &ast.Identifier{Name: "v"},
+					Init: &ast.ObjectExpression{Properties: props},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,236 @@
+package variable_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/types"
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/storage/reads/datatypes"
+	"github.com/influxdata/influxdb/v2/tsdb/cursors"
+	"github.com/influxdata/influxdb/v2/variable"
+)
+
+var now = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// tagValuesStore is a TagValuesStore returning the values of a tag key
+// filtered by the value of another tag key, recording its requests.
+type tagValuesStore struct {
+	values   map[string]map[string][]string
+	requests []*datatypes.TagValuesRequest
+}
+
+func (s *tagValuesStore) GetSource(orgID, bucketID uint64) proto.Message {
+	return &types.Empty{}
+}
+
+func (s *tagValuesStore) TagValues(ctx context.Context, req *datatypes.TagValuesRequest) (cursors.StringIterator, error) {
+	s.requests = append(s.requests, req)
+
+	filter := ""
+	if req.Predicate != nil {
+		filter = lastLiteral(req.Predicate.Root)
+	}
+	return cursors.NewStringSliceIterator(s.values[req.TagKey][filter]), nil
+}
+
+// lastLiteral returns the last string literal of a predicate.
+func lastLiteral(n *datatypes.Node) string {
+	if v, ok := n.Value.(*datatypes.Node_StringValue); ok {
+		return v.StringValue
+	}
+	lit := ""
+	for _, c := range n.Children {
+		if l := lastLiteral(c); l != "" {
+			lit = l
+		}
+	}
+	return lit
+}
+
+func newVariableService(vars ...*influxdb.Variable) *mock.VariableService {
+	svc := mock.NewVariableService()
+	svc.FindVariableByIDF = func(ctx context.Context, id platform.ID) (*influxdb.Variable, error) {
+		for _, v := range vars {
+			if v.ID == id {
+				return v, nil
+			}
+		}
+		return nil, &errors.Error{Code: errors.ENotFound, Msg: influxdb.ErrVariableNotFound}
+	}
+	svc.FindVariablesF = func(ctx context.Context, f influxdb.VariableFilter, opts ...influxdb.FindOptions) ([]*influxdb.Variable, error) {
+		return vars, nil
+	}
+	return svc
+}
+
+func tagValuesVariable(id platform.ID, name, key string, filters ...influxdb.VariableTagFilter) *influxdb.Variable {
+	return &influxdb.Variable{
+		ID:             id,
+		OrganizationID: 1,
+		Name:           name,
+		Arguments: &influxdb.VariableArguments{
+			Type: "tagValues",
+			Values: influxdb.VariableTagValuesValues{
+				BucketID:    2,
+				Measurement: "telemetry",
+				TagKey:      key,
+				Filters:     filters,
+			},
+		},
+	}
+}
+
+func TestValuesService_FindVariableValues(t *testing.T) {
+	spacecraft := tagValuesVariable(10, "spacecraft", "craft")
+	spacecraft.Selected = []string{"voyager2"}
+	channel := tagValuesVariable(11, "channel", "channel", influxdb.VariableTagFilter{Key: "craft", Variable: "spacecraft"})
+
+	store := &tagValuesStore{
+		values: map[string]map[string][]string{
+			"craft": {"telemetry": {"voyager1", "voyager2"}},
+			"channel": {
+				"voyager1": {"a", "b"},
+				"voyager2": {"c"},
+			},
+		},
+	}
+	svc := variable.NewValuesService(newVariableService(spacecraft, channel), store, nil)
+	svc.TimeGenerator = mock.TimeGenerator{FakeValue: now}
+	ctx := icontext.SetAuthorizer(context.Background(), mock.NewMockAuthorizer(true, nil))
+
+	tests := []struct {
+		name     string
+		id       platform.ID
+		selected map[string]string
+		want     *influxdb.VariableValues
+	}{
+		{
+			name: "without dependencies",
+			id:   spacecraft.ID,
+			want: &influxdb.VariableValues{
+				Values:   []string{"voyager1", "voyager2"},
+				Selected: "voyager2",
+			},
+		},
+		{
+			name: "with default value of dependency",
+			id:   channel.ID,
+			want: &influxdb.VariableValues{
+				Values:       []string{"c"},
+				Selected:     "c",
+				Dependencies: map[string]string{"spacecraft": "voyager2"},
+			},
+		},
+		{
+			name:     "with selected value of dependency",
+			id:       channel.ID,
+			selected: map[string]string{"spacecraft": "voyager1"},
+			want: &influxdb.VariableValues{
+				Values:       []string{"a", "b"},
+				Selected:     "a",
+				Dependencies: map[string]string{"spacecraft": "voyager1"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := svc.FindVariableValues(ctx, tt.id, influxdb.VariableValuesRequest{Selected: tt.selected})
+			if err != nil {
+				t.Fatalf("failed to find variable values: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	req := store.requests[len(store.requests)-1]
+	if req.Range.End != now.UnixNano() || req.Range.Start != now.Add(-variable.DefaultRange).UnixNano() {
+		t.Errorf("unexpected time range %+v", req.Range)
+	}
+}
+
+func TestValuesService_FindVariableValues_Cycle(t *testing.T) {
+	a := tagValuesVariable(10, "a", "a", influxdb.VariableTagFilter{Key: "b", Variable: "b"})
+	b := tagValuesVariable(11, "b", "b", influxdb.VariableTagFilter{Key: "a", Variable: "a"})
+
+	svc := variable.NewValuesService(newVariableService(a, b), &tagValuesStore{}, nil)
+	ctx := icontext.SetAuthorizer(context.Background(), mock.NewMockAuthorizer(true, nil))
+
+	_, err := svc.FindVariableValues(ctx, a.ID, influxdb.VariableValuesRequest{})
+	if errors.ErrorCode(err) != errors.EInvalid {
+		t.Fatalf("expected invalid error for dependency cycle, got %v", err)
+	}
+}
+
+func TestValuesService_FindVariableValues_Unauthorized(t *testing.T) {
+	v := tagValuesVariable(10, "a", "a")
+
+	svc := variable.NewValuesService(newVariableService(v), &tagValuesStore{}, nil)
+	ctx := icontext.SetAuthorizer(context.Background(), mock.NewMockAuthorizer(false, nil))
+
+	_, err := svc.FindVariableValues(ctx, v.ID, influxdb.VariableValuesRequest{})
+	if errors.ErrorCode(err) != errors.EUnauthorized {
+		t.Fatalf("expected unauthorized error, got %v", err)
+	}
+}
+
+func TestDependencies(t *testing.T) {
+	tests := []struct {
+		name string
+		args *influxdb.VariableArguments
+		want []string
+	}{
+		{
+			name: "flux query",
+			args: &influxdb.VariableArguments{
+				Type: "query",
+				Values: influxdb.VariableQueryValues{
+					Language: "flux",
+					Query: `import "influxdata/influxdb/schema"
+schema.tagValues(bucket: v.bucket, tag: "channel", predicate: (r) => r.craft == v.spacecraft and r.bucket == v["bucket"], start: v.timeRangeStart)`,
+				},
+			},
+			want: []string{"bucket", "spacecraft"},
+		},
+		{
+			name: "tag values",
+			args: &influxdb.VariableArguments{
+				Type: "tagValues",
+				Values: influxdb.VariableTagValuesValues{
+					Filters: []influxdb.VariableTagFilter{
+						{Key: "craft", Variable: "spacecraft"},
+						{Key: "env", Value: "prod"},
+					},
+				},
+			},
+			want: []string{"spacecraft"},
+		},
+		{
+			name: "constant",
+			args: &influxdb.VariableArguments{
+				Type:   "constant",
+				Values: influxdb.VariableConstantValues{"a"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := variable.Dependencies(tt.args)
+			if err != nil {
+				t.Fatalf("failed to find dependencies: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
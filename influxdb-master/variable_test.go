@@ -123,6 +123,43 @@ func TestVariable_UnmarshalJSON(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "with tag values arguments",
+			json: `
+{
+  "id": "debac1e0deadbeef",
+  "name": "channel",
+  "selected": [],
+  "arguments": {
+    "type": "tagValues",
+    "values": {
+      "bucketID": "deadbeefdeadbeef",
+      "measurement": "telemetry",
+      "tagKey": "channel",
+      "range": "7d",
+      "filters": [{"key": "craft", "variable": "spacecraft"}]
+    }
+  }
+}
+`,
+			want: platform.Variable{
+				ID:       platformtesting.MustIDBase16(variableTestID),
+				Name:     "channel",
+				Selected: make([]string, 0),
+				Arguments: &platform.VariableArguments{
+					Type: "tagValues",
+					Values: platform.VariableTagValuesValues{
+						BucketID:    platformtesting.MustIDBase16(variableTestOrgID),
+						Measurement: "telemetry",
+						TagKey:      "channel",
+						Range:       "7d",
+						Filters: []platform.VariableTagFilter{
+							{Key: "craft", Variable: "spacecraft"},
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {